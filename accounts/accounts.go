@@ -23,6 +23,7 @@ import (
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/event"
 )
 
@@ -126,6 +127,31 @@ type Wallet interface {
 	// It looks up the account specified either solely via its address contained within,
 	// or optionally with the aid of any location metadata from the embedded URL field.
 	SignTxWithPassphrase(account Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+
+	// SignRing requests the wallet to ring sign the given hash, hiding the account
+	// among the members of the ring. The key of the account is inserted into the
+	// ring at a random position unless it is already a member.
+	//
+	// As with SignHash, an AuthNeededError instance is returned if the wallet
+	// requires additional authentication, which may be retried by providing the
+	// needed details via SignRingWithPassphrase.
+	SignRing(account Account, hash common.Hash, members ring.Ring) (*ring.RingSign, error)
+
+	// SignRingWithPassphrase requests the wallet to ring sign the given hash with
+	// the given passphrase as extra authentication information.
+	SignRingWithPassphrase(account Account, passphrase string, hash common.Hash, members ring.Ring) (*ring.RingSign, error)
+
+	// DeriveStealthKeys requests the wallet to derive the stealth view and spend
+	// keys of the given account, with the given passphrase as extra authentication
+	// information, and returns the stealth address payments to the account are
+	// sent to. Wallets persisting keys store the derived keys with the account.
+	DeriveStealthKeys(account Account, passphrase string) (*ring.StealthAddress, error)
+
+	// ExportStealthKeys requests the wallet to export the stealth keys of the
+	// given account, with the given passphrase as extra authentication information.
+	// If watchOnly is set, the spend key is withheld, so the exported keys can
+	// only detect payments to the account.
+	ExportStealthKeys(account Account, passphrase string, watchOnly bool) (*ring.WalletKeys, error)
 }
 
 // Backend is a "wallet provider" that may contain a batch of accounts they can
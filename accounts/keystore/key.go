@@ -31,7 +31,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/pborman/uuid"
 )
 
@@ -46,6 +48,16 @@ type Key struct {
 	// we only store privkey as pubkey/address can be derived from it
 	// privkey in this struct is always in plaintext
 	PrivateKey *ecdsa.PrivateKey
+	// optional stealth keys and ring signing preferences of the account,
+	// stored encrypted alongside the private key
+	Stealth *ring.WalletKeys
+	Ring    *RingPreferences
+}
+
+// RingPreferences are the ring signing preferences of an account.
+type RingPreferences struct {
+	Size    int              `json:"size,omitempty"`    // preferred size of random rings
+	Members []common.Address `json:"members,omitempty"` // preferred ring members in order
 }
 
 type keyStore interface {
@@ -58,17 +70,26 @@ type keyStore interface {
 }
 
 type plainKeyJSON struct {
-	Address    string `json:"address"`
-	PrivateKey string `json:"privatekey"`
-	Id         string `json:"id"`
-	Version    int    `json:"version"`
+	Address    string       `json:"address"`
+	PrivateKey string       `json:"privatekey"`
+	Id         string       `json:"id"`
+	Version    int          `json:"version"`
+	Ring       *ringKeyJSON `json:"ring,omitempty"`
 }
 
 type encryptedKeyJSONV3 struct {
-	Address string     `json:"address"`
-	Crypto  CryptoJSON `json:"crypto"`
-	Id      string     `json:"id"`
-	Version int        `json:"version"`
+	Address string      `json:"address"`
+	Crypto  CryptoJSON  `json:"crypto"`
+	Id      string      `json:"id"`
+	Version int         `json:"version"`
+	Ring    *CryptoJSON `json:"ring,omitempty"`
+}
+
+// ringKeyJSON is the plaintext of the ring field of key files.
+type ringKeyJSON struct {
+	View        string           `json:"view,omitempty"`
+	Spend       string           `json:"spend,omitempty"`
+	Preferences *RingPreferences `json:"preferences,omitempty"`
 }
 
 type encryptedKeyJSONV1 struct {
@@ -97,6 +118,7 @@ func (k *Key) MarshalJSON() (j []byte, err error) {
 		hex.EncodeToString(crypto.FromECDSA(k.PrivateKey)),
 		k.Id.String(),
 		version,
+		k.ringJSON(),
 	}
 	j, err = json.Marshal(jStruct)
 	return j, err
@@ -124,9 +146,59 @@ func (k *Key) UnmarshalJSON(j []byte) (err error) {
 	k.Address = common.BytesToAddress(addr)
 	k.PrivateKey = privkey
 
+	if keyJSON.Ring != nil {
+		return k.setRingJSON(keyJSON.Ring)
+	}
+	return nil
+}
+
+// ringJSON returns the stealth keys and ring preferences of the key in their
+// JSON form, or nil if it has neither.
+func (k *Key) ringJSON() *ringKeyJSON {
+	if k.Stealth == nil && k.Ring == nil {
+		return nil
+	}
+	r := &ringKeyJSON{Preferences: k.Ring}
+	if k.Stealth != nil {
+		r.View = hex.EncodeToString(crypto.FromECDSA(k.Stealth.View))
+		r.Spend = hex.EncodeToString(crypto.FromECDSA(k.Stealth.Spend))
+	}
+	return r
+}
+
+// setRingJSON sets the stealth keys and ring preferences of the key from their
+// JSON form.
+func (k *Key) setRingJSON(r *ringKeyJSON) error {
+	if r.View != "" || r.Spend != "" {
+		view, err := crypto.HexToECDSA(r.View)
+		if err != nil {
+			return fmt.Errorf("invalid view key: %v", err)
+		}
+		spend, err := crypto.HexToECDSA(r.Spend)
+		if err != nil {
+			return fmt.Errorf("invalid spend key: %v", err)
+		}
+		k.Stealth = ring.NewWalletKeys(view, spend)
+	}
+	k.Ring = r.Preferences
 	return nil
 }
 
+// deriveStealthKeys derives the stealth view and spend keys of an account,
+// using its private key as the seed of the ring.StealthKeyPaths of account 0.
+// Backups of the private key thus also cover the stealth keys.
+func deriveStealthKeys(priv *ecdsa.PrivateKey) (*ring.WalletKeys, error) {
+	master, err := ring.NewMasterKey(math.PaddedBigBytes(priv.D, 32))
+	if err != nil {
+		return nil, err
+	}
+	view, spend, err := master.StealthKeys(0)
+	if err != nil {
+		return nil, err
+	}
+	return ring.NewWalletKeys(view, spend), nil
+}
+
 func newKeyFromECDSA(privateKeyECDSA *ecdsa.PrivateKey) *Key {
 	id := uuid.NewRandom()
 	key := &Key{
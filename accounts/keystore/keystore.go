@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/event"
 )
 
@@ -44,6 +45,10 @@ var (
 	ErrLocked  = accounts.NewAuthNeededError("password or unlock")
 	ErrNoMatch = errors.New("no key for given address or file")
 	ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+
+	// ErrNoStealthKeys is returned when exporting the stealth keys of an account
+	// that has none stored.
+	ErrNoStealthKeys = errors.New("no stealth keys for account")
 )
 
 // KeyStoreType is the reflect type of a keystore backend.
@@ -296,6 +301,92 @@ func (ks *KeyStore) SignHashWithPassphrase(a accounts.Account, passphrase string
 	return crypto.Sign(hash, key.PrivateKey)
 }
 
+// SignRing ring signs hash with the requested unlocked account, hiding it among
+// the given members. The key of the account is inserted into the ring at a
+// random position unless it is already a member.
+func (ks *KeyStore) SignRing(a accounts.Account, hash common.Hash, members ring.Ring) (*ring.RingSign, error) {
+	// Look up the key to sign with and abort if it cannot be found
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[a.Address]
+	if !found {
+		return nil, ErrLocked
+	}
+	return signRing(hash, members, unlockedKey.PrivateKey)
+}
+
+// SignRingWithPassphrase ring signs hash if the private key matching the given
+// address can be decrypted with the given passphrase.
+func (ks *KeyStore) SignRingWithPassphrase(a accounts.Account, passphrase string, hash common.Hash, members ring.Ring) (*ring.RingSign, error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key.PrivateKey)
+	return signRing(hash, members, key.PrivateKey)
+}
+
+// signRing ring signs hash with key, inserting its public key into the ring at
+// a random position if it isn't a member.
+func signRing(hash common.Hash, members ring.Ring, key *ecdsa.PrivateKey) (*ring.RingSign, error) {
+	s := members.Index(&key.PublicKey)
+	if s < 0 {
+		pos, err := crand.Int(crand.Reader, big.NewInt(int64(len(members)+1)))
+		if err != nil {
+			return nil, err
+		}
+		s = int(pos.Int64())
+		members = append(append(append(ring.Ring{}, members[:s]...), &key.PublicKey), members[s:]...)
+	}
+	return ring.Sign(hash, members, key, s)
+}
+
+// SignRingTx ring signs the ring transaction with the requested unlocked
+// account, whose key must be a member of the ring. The sender of the signed
+// transaction is the fingerprint of the ring, see types.RingFingerprint.
+func (ks *KeyStore) SignRingTx(a accounts.Account, tx *types.Transaction, members ring.Ring, chainID *big.Int) (*types.Transaction, error) {
+	// Look up the key to sign with and abort if it cannot be found
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[a.Address]
+	if !found {
+		return nil, ErrLocked
+	}
+	if !members.Contains(&unlockedKey.PrivateKey.PublicKey) {
+		return nil, errors.New("account is not a member of the ring")
+	}
+	return types.SignRingTx(tx, types.NewRingSigner(chainID), members, unlockedKey.PrivateKey)
+}
+
+// PublicKey returns the public key of the requested unlocked account, which
+// ring members need to know.
+func (ks *KeyStore) PublicKey(a accounts.Account) (*ecdsa.PublicKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[a.Address]
+	if !found {
+		return nil, ErrLocked
+	}
+	pub := unlockedKey.PrivateKey.PublicKey
+	return &pub, nil
+}
+
+// KeyImage returns the key image of the requested unlocked account, which
+// links all ring signatures made with it.
+func (ks *KeyStore) KeyImage(a accounts.Account) (*ecdsa.PublicKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[a.Address]
+	if !found {
+		return nil, ErrLocked
+	}
+	return ring.GenKeyImage(unlockedKey.PrivateKey), nil
+}
+
 // SignTxWithPassphrase signs the transaction if the private key matching the
 // given address can be decrypted with the given passphrase.
 func (ks *KeyStore) SignTxWithPassphrase(a accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
@@ -472,6 +563,71 @@ func (ks *KeyStore) Update(a accounts.Account, passphrase, newPassphrase string)
 	return ks.storage.StoreKey(a.URL.Path, key, newPassphrase)
 }
 
+// DeriveStealthKeys derives the stealth view and spend keys of an account,
+// stores them in its key file and returns the stealth address of the account.
+// The keys are derived from the private key of the account, so deriving them
+// again yields the same keys.
+func (ks *KeyStore) DeriveStealthKeys(a accounts.Account, passphrase string) (*ring.StealthAddress, error) {
+	a, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	if key.Stealth == nil {
+		if key.Stealth, err = deriveStealthKeys(key.PrivateKey); err != nil {
+			return nil, err
+		}
+		if err := ks.storage.StoreKey(a.URL.Path, key, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	return key.Stealth.Address(), nil
+}
+
+// ExportStealthKeys returns the stealth keys stored for an account. If
+// watchOnly is set, the spend key is withheld: the keys can detect payments to
+// the account, but not spend them.
+func (ks *KeyStore) ExportStealthKeys(a accounts.Account, passphrase string, watchOnly bool) (*ring.WalletKeys, error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	if key.Stealth == nil {
+		return nil, ErrNoStealthKeys
+	}
+	if watchOnly {
+		return key.Stealth.WatchOnlyKeys(), nil
+	}
+	return key.Stealth, nil
+}
+
+// RingPreferences returns the ring signing preferences stored for an account,
+// or nil if it has none.
+func (ks *KeyStore) RingPreferences(a accounts.Account, passphrase string) (*RingPreferences, error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key.PrivateKey)
+	return key.Ring, nil
+}
+
+// SetRingPreferences stores the ring signing preferences of an account in its
+// key file, removing them if prefs is nil.
+func (ks *KeyStore) SetRingPreferences(a accounts.Account, passphrase string, prefs *RingPreferences) error {
+	a, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	key.Ring = prefs
+	return ks.storage.StoreKey(a.URL.Path, key, passphrase)
+}
+
 // ImportPreSaleKey decrypts the given Ethereum presale wallet and stores
 // a key file in the key directory. The key file is encrypted with the same passphrase.
 func (ks *KeyStore) ImportPreSaleKey(keyJSON []byte, passphrase string) (accounts.Account, error) {
@@ -192,6 +192,21 @@ func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
 		cryptoStruct,
 		key.Id.String(),
 		version,
+		nil,
+	}
+	// Stealth keys and ring preferences are encrypted separately, so that key
+	// files without them stay readable by other clients and only cost a second
+	// key derivation if present.
+	if r := key.ringJSON(); r != nil {
+		plain, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		ringStruct, err := EncryptDataV3(plain, []byte(auth), scryptN, scryptP)
+		if err != nil {
+			return nil, err
+		}
+		encryptedKeyJSONV3.Ring = &ringStruct
 	}
 	return json.Marshal(encryptedKeyJSONV3)
 }
@@ -206,6 +221,7 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 	// Depending on the version try to parse one way or another
 	var (
 		keyBytes, keyId []byte
+		ringCrypto      *CryptoJSON
 		err             error
 	)
 	if version, ok := m["version"].(string); ok && version == "1" {
@@ -220,6 +236,7 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 			return nil, err
 		}
 		keyBytes, keyId, err = decryptKeyV3(k, auth)
+		ringCrypto = k.Ring
 	}
 	// Handle any decryption errors and return the key
 	if err != nil {
@@ -227,11 +244,25 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 	}
 	key := crypto.ToECDSAUnsafe(keyBytes)
 
-	return &Key{
+	k := &Key{
 		Id:         uuid.UUID(keyId),
 		Address:    crypto.PubkeyToAddress(key.PublicKey),
 		PrivateKey: key,
-	}, nil
+	}
+	if ringCrypto != nil {
+		plain, err := DecryptDataV3(*ringCrypto, auth)
+		if err != nil {
+			return nil, err
+		}
+		r := new(ringKeyJSON)
+		if err := json.Unmarshal(plain, r); err != nil {
+			return nil, err
+		}
+		if err := k.setRingJSON(r); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
 }
 func DecryptDataV3(cryptoJson CryptoJSON, auth string) ([]byte, error) {
 	if cryptoJson.Cipher != "aes-128-ctr" {
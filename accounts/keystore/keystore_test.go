@@ -17,9 +17,12 @@
 package keystore
 
 import (
+	"crypto/ecdsa"
 	"io/ioutil"
+	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"runtime"
 	"sort"
 	"strings"
@@ -28,6 +31,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/event"
 )
 
@@ -113,6 +119,148 @@ func TestSignWithPassphrase(t *testing.T) {
 	}
 }
 
+func TestSignRing(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	pass := "passwd"
+	acc, err := ks.NewAccount(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var members ring.Ring
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		members = append(members, &key.PublicKey)
+	}
+	hash := common.BytesToHash(testSigData)
+	if _, err := ks.SignRing(acc, hash, members); err != ErrLocked {
+		t.Fatalf("locked account: have %v, want %v", err, ErrLocked)
+	}
+	if _, err := ks.KeyImage(acc); err != ErrLocked {
+		t.Fatalf("locked key image: have %v, want %v", err, ErrLocked)
+	}
+	sig, err := ks.SignRingWithPassphrase(acc, pass, hash, members)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Size != len(members)+1 || !ring.Verify(sig) {
+		t.Fatalf("invalid signature over a ring of %d", sig.Size)
+	}
+	if err := ks.Unlock(acc, pass); err != nil {
+		t.Fatal(err)
+	}
+	// Signing over a ring containing the account keeps the ring unchanged
+	again, err := ks.SignRing(acc, hash, sig.Ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Size != sig.Size || !ring.Link(sig, again) {
+		t.Error("signatures of the same account don't link")
+	}
+	image, err := ks.KeyImage(acc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.X.Cmp(sig.I.X) != 0 || image.Y.Cmp(sig.I.Y) != 0 {
+		t.Error("key image mismatch")
+	}
+}
+
+func TestSignRingTx(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	acc, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(acc, ""); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ks.PublicKey(acc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoy, _ := crypto.GenerateKey()
+	members := ring.Ring{&decoy.PublicKey, pub}
+
+	chainID := big.NewInt(18)
+	tx := types.NewRingTransaction(chainID, 0, &common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	if _, err := ks.SignRingTx(acc, tx, members[:1], chainID); err == nil {
+		t.Fatal("signed over a ring without the account")
+	}
+	signed, err := ks.SignRingTx(acc, tx, members, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := types.Sender(types.NewRingSigner(chainID), signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := types.RingFingerprint(members); from != want {
+		t.Errorf("sender mismatch: have %x, want %x", from, want)
+	}
+}
+
+func TestStealthKeys(t *testing.T) {
+	for _, encrypted := range []bool{false, true} {
+		dir, ks := tmpKeyStore(t, encrypted)
+		defer os.RemoveAll(dir)
+
+		acc, err := ks.NewAccount("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ks.ExportStealthKeys(acc, "foo", false); err != ErrNoStealthKeys {
+			t.Fatalf("encrypted %v: export before derivation: have %v, want %v", encrypted, err, ErrNoStealthKeys)
+		}
+		addr, err := ks.DeriveStealthKeys(acc, "foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		prefs := &RingPreferences{Size: 5, Members: []common.Address{{1}, acc.Address}}
+		if err := ks.SetRingPreferences(acc, "foo", prefs); err != nil {
+			t.Fatal(err)
+		}
+		// Changing the passphrase must keep the stealth keys and preferences
+		if err := ks.Update(acc, "foo", "bar"); err != nil {
+			t.Fatal(err)
+		}
+		if again, err := ks.DeriveStealthKeys(acc, "bar"); err != nil || !samePoint(again.Spend, addr.Spend) {
+			t.Fatalf("encrypted %v: derivation not deterministic: %v", encrypted, err)
+		}
+		keys, err := ks.ExportStealthKeys(acc, "bar", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys.WatchOnly() || !samePoint(&keys.View.PublicKey, addr.View) || !samePoint(&keys.Spend.PublicKey, addr.Spend) {
+			t.Errorf("encrypted %v: exported keys don't match the stealth address", encrypted)
+		}
+		watch, err := ks.ExportStealthKeys(acc, "bar", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !watch.WatchOnly() || !samePoint(watch.Address().Spend, addr.Spend) {
+			t.Errorf("encrypted %v: watch-only export leaks or loses the spend key", encrypted)
+		}
+		have, err := ks.RingPreferences(acc, "bar")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, prefs) {
+			t.Errorf("encrypted %v: ring preferences mismatch: have %+v, want %+v", encrypted, have, prefs)
+		}
+		if _, err := ks.ExportStealthKeys(acc, "foo", false); encrypted && err == nil {
+			t.Error("exported with the old passphrase")
+		}
+	}
+}
+
+func samePoint(a, b *ecdsa.PublicKey) bool {
+	return a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0
+}
+
 func TestTimedUnlock(t *testing.T) {
 	dir, ks := tmpKeyStore(t, true)
 	defer os.RemoveAll(dir)
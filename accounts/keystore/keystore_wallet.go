@@ -21,7 +21,9 @@ import (
 
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 )
 
 // keystoreWallet implements the accounts.Wallet interface for the original
@@ -137,3 +139,60 @@ func (w *keystoreWallet) SignTxWithPassphrase(account accounts.Account, passphra
 	// Account seems valid, request the keystore to sign
 	return w.keystore.SignTxWithPassphrase(account, passphrase, tx, chainID)
 }
+
+// SignRing implements accounts.Wallet, attempting to ring sign the given hash
+// with the given account.
+func (w *keystoreWallet) SignRing(account accounts.Account, hash common.Hash, members ring.Ring) (*ring.RingSign, error) {
+	// Make sure the requested account is contained within
+	if account.Address != w.account.Address {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if account.URL != (accounts.URL{}) && account.URL != w.account.URL {
+		return nil, accounts.ErrUnknownAccount
+	}
+	// Account seems valid, request the keystore to sign
+	return w.keystore.SignRing(account, hash, members)
+}
+
+// SignRingWithPassphrase implements accounts.Wallet, attempting to ring sign
+// the given hash with the given account using passphrase as extra
+// authentication.
+func (w *keystoreWallet) SignRingWithPassphrase(account accounts.Account, passphrase string, hash common.Hash, members ring.Ring) (*ring.RingSign, error) {
+	// Make sure the requested account is contained within
+	if account.Address != w.account.Address {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if account.URL != (accounts.URL{}) && account.URL != w.account.URL {
+		return nil, accounts.ErrUnknownAccount
+	}
+	// Account seems valid, request the keystore to sign
+	return w.keystore.SignRingWithPassphrase(account, passphrase, hash, members)
+}
+
+// DeriveStealthKeys implements accounts.Wallet, deriving and storing the
+// stealth keys of the given account using passphrase as extra authentication.
+func (w *keystoreWallet) DeriveStealthKeys(account accounts.Account, passphrase string) (*ring.StealthAddress, error) {
+	// Make sure the requested account is contained within
+	if account.Address != w.account.Address {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if account.URL != (accounts.URL{}) && account.URL != w.account.URL {
+		return nil, accounts.ErrUnknownAccount
+	}
+	// Account seems valid, request the keystore to derive the keys
+	return w.keystore.DeriveStealthKeys(account, passphrase)
+}
+
+// ExportStealthKeys implements accounts.Wallet, returning the stealth keys of
+// the given account using passphrase as extra authentication.
+func (w *keystoreWallet) ExportStealthKeys(account accounts.Account, passphrase string, watchOnly bool) (*ring.WalletKeys, error) {
+	// Make sure the requested account is contained within
+	if account.Address != w.account.Address {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if account.URL != (accounts.URL{}) && account.URL != w.account.URL {
+		return nil, accounts.ErrUnknownAccount
+	}
+	// Account seems valid, request the keystore to export the keys
+	return w.keystore.ExportStealthKeys(account, passphrase, watchOnly)
+}
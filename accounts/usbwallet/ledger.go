@@ -21,6 +21,7 @@
 package usbwallet
 
 import (
+	"crypto/ecdsa"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
@@ -31,7 +32,10 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -51,6 +55,8 @@ const (
 	ledgerOpRetrieveAddress  ledgerOpcode = 0x02 // Returns the public key and Ethereum address for a given BIP 32 path
 	ledgerOpSignTransaction  ledgerOpcode = 0x04 // Signs an Ethereum transaction after having the user validate the parameters
 	ledgerOpGetConfiguration ledgerOpcode = 0x06 // Returns specific wallet application configuration
+	ledgerOpRingCommit       ledgerOpcode = 0x20 // Commits to a ring signing nonce after having the user validate the request
+	ledgerOpRingRespond      ledgerOpcode = 0x22 // Answers the challenge of the pending ring signature
 
 	ledgerP1DirectlyFetchAddress    ledgerParam1 = 0x00 // Return address directly from the wallet
 	ledgerP1InitTransactionData     ledgerParam1 = 0x00 // First transaction data block for signing
@@ -168,6 +174,26 @@ func (w *ledgerDriver) SignTx(path accounts.DerivationPath, tx *types.Transactio
 	return w.ledgerSign(path, tx, chainID)
 }
 
+// RingCommit implements usbwallet.driver, sending the ring signing request to
+// the Ledger and waiting for the user to confirm or deny it.
+func (w *ledgerDriver) RingCommit(path accounts.DerivationPath, hash common.Hash, size int) (*ring.ExternalCommitment, error) {
+	// If the Ethereum app doesn't run, abort
+	if w.offline() {
+		return nil, accounts.ErrWalletClosed
+	}
+	return w.ledgerRingCommit(path, hash, size)
+}
+
+// RingRespond implements usbwallet.driver, sending the challenge of the
+// confirmed ring signing request to the Ledger.
+func (w *ledgerDriver) RingRespond(challenge *big.Int) (*big.Int, error) {
+	// If the Ethereum app doesn't run, abort
+	if w.offline() {
+		return nil, accounts.ErrWalletClosed
+	}
+	return w.ledgerRingRespond(challenge)
+}
+
 // ledgerVersion retrieves the current version of the Ethereum wallet app running
 // on the Ledger wallet.
 //
@@ -363,6 +389,100 @@ func (w *ledgerDriver) ledgerSign(derivationPath []uint32, tx *types.Transaction
 	return sender, signed, nil
 }
 
+// ledgerRingCommit sends a ring signing request to the Ledger wallet, and waits
+// for the user to confirm or deny it. On confirmation the wallet picks a fresh
+// nonce u and commits to it; the private key x of the derivation path is only
+// used in the response to the challenge, see ledgerRingRespond.
+//
+// The ring commitment protocol is defined as follows:
+//
+//   CLA | INS | P1 | P2 | Lc  | Le
+//   ----+-----+----+----+-----+---
+//    E0 | 20  | 00 | 00 | var | 00
+//
+// Where the input data is:
+//
+//   Description                                      | Length
+//   -------------------------------------------------+----------
+//   Number of BIP 32 derivations to perform (max 10) | 1 byte
+//   First derivation index (big endian)              | 4 bytes
+//   ...                                              | 4 bytes
+//   Last derivation index (big endian)               | 4 bytes
+//   Message hash                                     | 32 bytes
+//   Ring size, shown to the user (big endian)        | 2 bytes
+//
+// And the output data is, with points as their 32 byte X and Y coordinates:
+//
+//   Description                   | Length
+//   ------------------------------+---------
+//   Public key P = x*G            | 64 bytes
+//   Key image I = x*H_p(P)        | 64 bytes
+//   Nonce commitment L = u*G      | 64 bytes
+//   Nonce commitment R = u*H_p(P) | 64 bytes
+//
+// A wallet keeps one pending commitment, replaced by every new request.
+func (w *ledgerDriver) ledgerRingCommit(derivationPath []uint32, hash common.Hash, size int) (*ring.ExternalCommitment, error) {
+	if size > 0xffff {
+		return nil, fmt.Errorf("ring of %d members too large for Ledger", size)
+	}
+	// Flatten the derivation path and request into the Ledger request
+	payload := make([]byte, 1+4*len(derivationPath), 1+4*len(derivationPath)+34)
+	payload[0] = byte(len(derivationPath))
+	for i, component := range derivationPath {
+		binary.BigEndian.PutUint32(payload[1+4*i:], component)
+	}
+	payload = append(payload, hash[:]...)
+	payload = append(payload, byte(size>>8), byte(size))
+
+	// Send the request and wait for the response
+	reply, err := w.ledgerExchange(ledgerOpRingCommit, 0, 0, payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) != 4*64 {
+		return nil, errors.New("reply lacks ring commitment, Ledger app may not support ring signing")
+	}
+	var points [4]*ecdsa.PublicKey
+	for i := range points {
+		if points[i], err = crypto.UnmarshalPubkey(append([]byte{0x04}, reply[64*i:64*(i+1)]...)); err != nil {
+			return nil, fmt.Errorf("invalid ring commitment: %v", err)
+		}
+	}
+	return &ring.ExternalCommitment{Public: points[0], Image: points[1], L: points[2], R: points[3]}, nil
+}
+
+// ledgerRingRespond sends the challenge of the pending ring signature to the
+// Ledger wallet, which answers it and discards the pending commitment.
+//
+// The ring response protocol is defined as follows:
+//
+//   CLA | INS | P1 | P2 | Lc | Le
+//   ----+-----+----+----+----+---
+//    E0 | 22  | 00 | 00 | 20 | 20
+//
+// Where the input data is:
+//
+//   Description                | Length
+//   ---------------------------+---------
+//   Challenge c (big endian)   | 32 bytes
+//
+// And the output data is:
+//
+//   Description                | Length
+//   ---------------------------+---------
+//   Response s = u - c*x mod N | 32 bytes
+func (w *ledgerDriver) ledgerRingRespond(challenge *big.Int) (*big.Int, error) {
+	// Send the request and wait for the response
+	reply, err := w.ledgerExchange(ledgerOpRingRespond, 0, 0, math.PaddedBigBytes(challenge, 32))
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) != 32 {
+		return nil, errors.New("reply lacks ring response")
+	}
+	return new(big.Int).SetBytes(reply), nil
+}
+
 // ledgerExchange performs a data exchange with the Ledger wallet, sending it a
 // message and retrieving the response.
 //
@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/golang/protobuf/proto"
 )
@@ -159,6 +160,18 @@ func (w *trezorDriver) SignTx(path accounts.DerivationPath, tx *types.Transactio
 	return w.trezorSign(path, tx, chainID)
 }
 
+// RingCommit implements usbwallet.driver, however ring signing is not supported
+// by the Trezor firmware, so this method will always return an error.
+func (w *trezorDriver) RingCommit(path accounts.DerivationPath, hash common.Hash, size int) (*ring.ExternalCommitment, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// RingRespond implements usbwallet.driver, however ring signing is not supported
+// by the Trezor firmware, so this method will always return an error.
+func (w *trezorDriver) RingRespond(challenge *big.Int) (*big.Int, error) {
+	return nil, accounts.ErrNotSupported
+}
+
 // trezorDerive sends a derivation request to the Trezor device and returns the
 // Ethereum address located on that path.
 func (w *trezorDriver) trezorDerive(derivationPath []uint32) (common.Address, error) {
@@ -19,6 +19,7 @@ package usbwallet
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"math/big"
@@ -29,6 +30,8 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/karalabe/hid"
 )
@@ -66,6 +69,15 @@ type driver interface {
 	// SignTx sends the transaction to the USB device and waits for the user to confirm
 	// or deny the transaction.
 	SignTx(path accounts.DerivationPath, tx *types.Transaction, chainID *big.Int) (common.Address, *types.Transaction, error)
+
+	// RingCommit sends a request to ring sign the hash over a ring of the given
+	// size to the USB device and waits for the user to confirm or deny it. The
+	// device returns its commitment to a fresh signing nonce.
+	RingCommit(path accounts.DerivationPath, hash common.Hash, size int) (*ring.ExternalCommitment, error)
+
+	// RingRespond sends the challenge of the last confirmed ring signing request
+	// to the USB device, which answers it and discards the nonce.
+	RingRespond(challenge *big.Int) (*big.Int, error)
 }
 
 // wallet represents the common functionality shared by all USB hardware
@@ -560,3 +572,94 @@ func (w *wallet) SignHashWithPassphrase(account accounts.Account, passphrase str
 func (w *wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
 	return w.SignTx(account, tx, chainID)
 }
+
+// SignRing implements accounts.Wallet. It sends the ring signing request over
+// to the device to request a confirmation from the user, after which the device
+// only answers the challenge of its position in the ring: the ring itself and
+// the challenge chain are handled on the host, the private key never leaves
+// the device.
+func (w *wallet) SignRing(account accounts.Account, hash common.Hash, members ring.Ring) (*ring.RingSign, error) {
+	w.stateLock.RLock() // Comms have own mutex, this is for the state fields
+	defer w.stateLock.RUnlock()
+
+	// If the wallet is closed, abort
+	if w.device == nil {
+		return nil, accounts.ErrWalletClosed
+	}
+	// Make sure the requested account is contained within
+	path, ok := w.paths[account.Address]
+	if !ok {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if err := members.Validate(); err != nil {
+		return nil, err
+	}
+	// Show the user the size of the final ring, including the account
+	size := len(members) + 1
+	for _, pub := range members {
+		if crypto.PubkeyToAddress(*pub) == account.Address {
+			size--
+			break
+		}
+	}
+	// All infos gathered and metadata checks out, request signing
+	<-w.commsLock
+	defer func() { w.commsLock <- struct{}{} }()
+
+	// Ensure the device isn't screwed with while user confirmation is pending
+	// TODO(karalabe): remove if hotplug lands on Windows
+	w.hub.commsLock.Lock()
+	w.hub.commsPend++
+	w.hub.commsLock.Unlock()
+
+	defer func() {
+		w.hub.commsLock.Lock()
+		w.hub.commsPend--
+		w.hub.commsLock.Unlock()
+	}()
+	commit, err := w.driver.RingCommit(path, hash, size)
+	if err != nil {
+		return nil, err
+	}
+	if signer := crypto.PubkeyToAddress(*commit.Public); signer != account.Address {
+		return nil, fmt.Errorf("signer mismatch: expected %s, got %s", account.Address.Hex(), signer.Hex())
+	}
+	// Insert the account into the ring at a random position if it isn't a member
+	if size > len(members) {
+		pos, err := rand.Int(rand.Reader, big.NewInt(int64(size)))
+		if err != nil {
+			return nil, err
+		}
+		s := int(pos.Int64())
+		members = append(append(append(ring.Ring{}, members[:s]...), commit.Public), members[s:]...)
+	}
+	signer, challenge, err := ring.NewExternalSigner(hash, members, commit)
+	if err != nil {
+		return nil, err
+	}
+	response, err := w.driver.RingRespond(challenge)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Finalize(response)
+}
+
+// SignRingWithPassphrase implements accounts.Wallet, attempting to ring sign
+// the given hash with the given account using passphrase as extra
+// authentication. Since USB wallets don't rely on passphrases, these are
+// silently ignored.
+func (w *wallet) SignRingWithPassphrase(account accounts.Account, passphrase string, hash common.Hash, members ring.Ring) (*ring.RingSign, error) {
+	return w.SignRing(account, hash, members)
+}
+
+// DeriveStealthKeys implements accounts.Wallet, however stealth keys can not be
+// derived on USB wallets, so this method will always return an error.
+func (w *wallet) DeriveStealthKeys(account accounts.Account, passphrase string) (*ring.StealthAddress, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// ExportStealthKeys implements accounts.Wallet, however stealth keys can not be
+// exported from USB wallets, so this method will always return an error.
+func (w *wallet) ExportStealthKeys(account accounts.Account, passphrase string, watchOnly bool) (*ring.WalletKeys, error) {
+	return nil, accounts.ErrNotSupported
+}
@@ -97,6 +97,13 @@ class StdIOHandler():
         """
         return {"approved": False, "password" : None}
 
+    @public
+    def ApproveSignRing(self, req):
+        """ Example request
+
+        """
+        return {"approved": False, "password" : None}
+
     @public
     def ApproveExport(self, req):
         """ Example request
@@ -125,6 +125,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolKeyImageFilterSizeFlag,
+			utils.TxPoolKeyImageFilterFPRateFlag,
 		},
 	},
 	{
@@ -150,6 +152,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RPCListenAddrFlag,
 			utils.RPCPortFlag,
 			utils.RPCApiFlag,
+			utils.RPCRingFlag,
+			utils.RPCRingMemberIndexFlag,
 			utils.WSEnabledFlag,
 			utils.WSListenAddrFlag,
 			utils.WSPortFlag,
@@ -0,0 +1,74 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputLink struct {
+	Linked    bool
+	KeyImages []string
+}
+
+var commandLink = cli.Command{
+	Name:      "link",
+	Usage:     "check whether ring signatures are linked",
+	ArgsUsage: "<signaturefile> <signaturefile>...",
+	Description: `
+Check whether the signatures in the signature files were made with the same
+key, that is whether they have the same key image. The signatures are not
+verified, use the verify command for that.
+`,
+	Flags: []cli.Flag{
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) < 2 {
+			utils.Fatalf("Invalid number of arguments: want at least 2, got %d", len(ctx.Args()))
+		}
+		sigs := make([]*ring.RingSign, len(ctx.Args()))
+		for i, file := range ctx.Args() {
+			sigs[i] = readSignature(file)
+		}
+
+		out := outputLink{Linked: true}
+		for _, sig := range sigs {
+			out.Linked = out.Linked && ring.Link(sigs[0], sig)
+			out.KeyImages = append(out.KeyImages, hexutil.Encode(crypto.CompressPubkey(sig.I)))
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			if out.Linked {
+				fmt.Println("Signatures are linked!")
+			} else {
+				fmt.Println("Signatures are not linked!")
+			}
+			for i, image := range out.KeyImages {
+				fmt.Printf("Key image %d: %s\n", i, image)
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/crypto/ring/chainkeys"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputRingBuild struct {
+	RingID  string
+	Members []string
+}
+
+var (
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "endpoint of the node to recover the public keys of addresses from",
+	}
+	fromBlockFlag = cli.Uint64Flag{
+		Name:  "fromblock",
+		Usage: "oldest block to scan for transactions of addresses",
+	}
+	toBlockFlag = cli.Uint64Flag{
+		Name:  "toblock",
+		Usage: "newest block to scan for transactions of addresses (default: head)",
+	}
+)
+
+var commandRingBuild = cli.Command{
+	Name:      "ring-build",
+	Usage:     "assemble a ring of public keys",
+	ArgsUsage: "<ringfile> <member> <member>...",
+	Description: `
+Assemble a ring from its members, in the order given, and write it to the ring
+file.
+
+A member is a public key in hex, an address or a file containing either. The
+public keys of keyfiles and addresses are recovered from transactions they sent,
+which are looked up on the node given by --rpc, newest block first.
+`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		binaryFlag,
+		rpcFlag,
+		fromBlockFlag,
+		toBlockFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) < 3 {
+			utils.Fatalf("Invalid number of arguments: want at least 3, got %d", len(ctx.Args()))
+		}
+		ringfilepath := ctx.Args().First()
+
+		var (
+			keys  = make([]*ecdsa.PublicKey, len(ctx.Args())-1)
+			addrs []common.Address
+			index []int // positions of addrs in keys
+		)
+		for i, arg := range ctx.Args()[1:] {
+			pub, addr, err := parseMember(arg)
+			if err != nil {
+				utils.Fatalf("Invalid ring member %s: %v", arg, err)
+			}
+			if pub != nil {
+				keys[i] = pub
+			} else {
+				addrs = append(addrs, addr)
+				index = append(index, i)
+			}
+		}
+		if len(addrs) > 0 {
+			found := recoverKeys(ctx, addrs)
+			for i, pub := range found {
+				keys[index[i]] = pub
+			}
+		}
+		members, err := ring.NewRing(keys...)
+		if err != nil {
+			utils.Fatalf("Invalid ring: %v", err)
+		}
+		writeRing(ringfilepath, ctx.Bool(binaryFlag.Name), members)
+
+		// Output some information.
+		id, err := ring.RingID(members)
+		if err != nil {
+			utils.Fatalf("Failed to compute ring ID: %v", err)
+		}
+		out := outputRingBuild{RingID: id.Hex()}
+		for _, pub := range members {
+			out.Members = append(out.Members, hexutil.Encode(crypto.CompressPubkey(pub)))
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Ring ID:", out.RingID)
+			for i, member := range out.Members {
+				fmt.Printf("Member %d: %s\n", i, member)
+			}
+		}
+		return nil
+	},
+}
+
+// parseMember parses a ring member given on the command line, returning
+// either its public key or its address. Files are read, keyfiles yield their
+// address.
+func parseMember(arg string) (*ecdsa.PublicKey, common.Address, error) {
+	if _, err := os.Stat(arg); err == nil {
+		content, err := ioutil.ReadFile(arg)
+		if err != nil {
+			return nil, common.Address{}, err
+		}
+		if isJSON(content) {
+			var keyfile struct {
+				Address string `json:"address"`
+			}
+			if err := json.Unmarshal(content, &keyfile); err != nil {
+				return nil, common.Address{}, err
+			}
+			if !common.IsHexAddress(keyfile.Address) {
+				return nil, common.Address{}, errors.New("keyfile without address")
+			}
+			return nil, common.HexToAddress(keyfile.Address), nil
+		}
+		arg = strings.TrimSpace(string(content))
+	}
+	if common.IsHexAddress(arg) {
+		return nil, common.HexToAddress(arg), nil
+	}
+	enc, err := hexutil.Decode(arg)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("neither address nor public key: %v", err)
+	}
+	var pub *ecdsa.PublicKey
+	if len(enc) == 33 {
+		pub, err = crypto.DecompressPubkey(enc)
+	} else {
+		pub, err = crypto.UnmarshalPubkey(enc)
+	}
+	return pub, common.Address{}, err
+}
+
+// recoverKeys recovers the public keys of addrs from the chain of the node
+// given by --rpc.
+func recoverKeys(ctx *cli.Context, addrs []common.Address) []*ecdsa.PublicKey {
+	if !ctx.IsSet(rpcFlag.Name) {
+		utils.Fatalf("Ring members given by address need --%s", rpcFlag.Name)
+	}
+	client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to connect to %s: %v", ctx.String(rpcFlag.Name), err)
+	}
+	defer client.Close()
+
+	to := ctx.Uint64(toBlockFlag.Name)
+	if !ctx.IsSet(toBlockFlag.Name) {
+		head, err := client.HeaderByNumber(context.Background(), nil)
+		if err != nil {
+			utils.Fatalf("Failed to retrieve head block: %v", err)
+		}
+		to = head.Number.Uint64()
+	}
+	keys, err := chainkeys.NewRingBuilder(client).PublicKeys(context.Background(), addrs, ctx.Uint64(fromBlockFlag.Name), to)
+	if err != nil {
+		utils.Fatalf("Failed to recover public keys: %v", err)
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const verifyOutput = `Message hash: 0x[0-9a-f]{64}\nKey image:    0x[0-9a-f]{66}\nRing ID:      0x[0-9a-f]{64}\n`
+
+func TestSignVerifyLink(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "ringsign-test")
+	if err != nil {
+		t.Fatal("Can't create temporary directory:", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	passfile := filepath.Join(tmpdir, "password")
+	if err := ioutil.WriteFile(passfile, []byte("foobar\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create the keys, the members of the ring.
+	var (
+		keyfiles = make([]string, 3)
+		pubkeys  = make([]string, 3)
+	)
+	for i := range keyfiles {
+		keyfiles[i] = filepath.Join(tmpdir, fmt.Sprintf("keyfile%d", i))
+		keygen := runRingsign(t, "keygen", "--passwordfile", passfile, keyfiles[i])
+		_, matches := keygen.ExpectRegexp(`Address:    0x[0-9a-fA-F]{40}\nPublic key: (0x[0-9a-f]{66})\nKey image:  0x[0-9a-f]{66}\n`)
+		pubkeys[i] = matches[1]
+		keygen.ExpectExit()
+	}
+
+	// Build the ring in both encodings.
+	ringfile := filepath.Join(tmpdir, "ring.json")
+	build := runRingsign(t, "ring-build", ringfile, pubkeys[0], pubkeys[1], pubkeys[2])
+	build.ExpectRegexp(`Ring ID: 0x[0-9a-f]{64}\n(Member [0-2]: 0x[0-9a-f]{66}\n){3}`)
+	build.ExpectExit()
+
+	binring := filepath.Join(tmpdir, "ring.bin")
+	build = runRingsign(t, "ring-build", "--binary", binring, pubkeys[0], pubkeys[1], pubkeys[2])
+	build.ExpectRegexp(`Ring ID: 0x[0-9a-f]{64}\n(Member [0-2]: 0x[0-9a-f]{66}\n){3}`)
+	build.ExpectExit()
+
+	// Sign two messages with the same key.
+	sigfile := filepath.Join(tmpdir, "sig.json")
+	sign := runRingsign(t, "sign", "--passwordfile", passfile, keyfiles[1], ringfile, sigfile, "test message")
+	_, matches := sign.ExpectRegexp(`Message hash: 0x[0-9a-f]{64}\nKey image:    (0x[0-9a-f]{66})\nRing size:    3\n`)
+	image := matches[1]
+	sign.ExpectExit()
+
+	binsig := filepath.Join(tmpdir, "sig.bin")
+	sign = runRingsign(t, "sign", "--passwordfile", passfile, "--binary", keyfiles[1], binring, binsig, "other message")
+	sign.ExpectRegexp(`Message hash: 0x[0-9a-f]{64}\nKey image:    ` + image + `\nRing size:    3\n`)
+	sign.ExpectExit()
+
+	// Verify the signatures.
+	verify := runRingsign(t, "verify", "--ring", binring, sigfile, "test message")
+	verify.ExpectRegexp(`Signature verification successful!\n` + verifyOutput)
+	verify.ExpectExit()
+
+	verify = runRingsign(t, "verify", binsig, "test message")
+	verify.ExpectRegexp(`Signature verification failed!\n` + verifyOutput)
+	verify.ExpectExit()
+	if !strings.Contains(verify.StderrText(), errVerifyFailed.Error()) {
+		t.Error("verification of signature over another message succeeded")
+	}
+
+	// Check that the signatures are linked.
+	link := runRingsign(t, "link", sigfile, binsig)
+	link.ExpectRegexp(`Signatures are linked!\nKey image 0: ` + image + `\nKey image 1: ` + image + `\n`)
+	link.ExpectExit()
+}
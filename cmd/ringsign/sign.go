@@ -0,0 +1,157 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputSign struct {
+	Message  string
+	KeyImage string
+	RingSize int
+}
+
+var commandSign = cli.Command{
+	Name:      "sign",
+	Usage:     "sign a message on behalf of a ring",
+	ArgsUsage: "<keyfile> <ringfile> <signaturefile> <message>",
+	Description: `
+Sign the Keccak256 hash of the message with a keyfile whose public key is a
+member of the ring, and write the signature to the signature file.
+
+To sign a message contained in a file, use the --msgfile flag.
+`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+		binaryFlag,
+		msgfileFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		message := getMessage(ctx, 3, false)
+		members := readRing(ctx.Args().Get(1))
+
+		key := loadKey(ctx, ctx.Args().First())
+		pair := ring.NewRingKeyPair(key.PrivateKey)
+		defer pair.Zero()
+
+		sig, err := pair.SignInRing(members, messageHash(message))
+		if err != nil {
+			utils.Fatalf("Failed to sign message: %v", err)
+		}
+		writeSignature(ctx.Args().Get(2), ctx.Bool(binaryFlag.Name), sig)
+
+		out := outputSign{
+			Message:  common.Hash(sig.M).Hex(),
+			KeyImage: hexutil.Encode(crypto.CompressPubkey(sig.I)),
+			RingSize: sig.Size,
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Message hash:", out.Message)
+			fmt.Println("Key image:   ", out.KeyImage)
+			fmt.Println("Ring size:   ", out.RingSize)
+		}
+		return nil
+	},
+}
+
+type outputVerify struct {
+	Success  bool
+	Message  string
+	KeyImage string
+	RingID   string
+}
+
+var errVerifyFailed = errors.New("signature verification failed")
+
+var commandVerify = cli.Command{
+	Name:      "verify",
+	Usage:     "verify a ring signature",
+	ArgsUsage: "<signaturefile> [ <message> ]",
+	Description: `
+Verify the signature in the signature file. If a message is given, the
+signature must be over its Keccak256 hash. If a ring file is given with --ring,
+the signature must be by a member of that ring.
+
+It is possible to refer to a file containing the message. The command fails if
+the signature is invalid.
+`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		msgfileFlag,
+		cli.StringFlag{
+			Name:  "ring",
+			Usage: "file containing the ring the signature must be made in",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		message := getMessage(ctx, 1, true)
+		sig := readSignature(ctx.Args().First())
+
+		success := ring.Verify(sig)
+		if message != nil && common.Hash(sig.M) != messageHash(message) {
+			success = false
+		}
+		if file := ctx.String("ring"); file != "" {
+			members := readRing(file)
+			if len(members) != len(sig.Ring) {
+				success = false
+			}
+			for i := 0; success && i < len(members); i++ {
+				success = ring.PublicKeyEqual(members[i], sig.Ring[i])
+			}
+		}
+		id, err := ring.RingID(sig.Ring)
+		if err != nil {
+			utils.Fatalf("Failed to compute ring ID: %v", err)
+		}
+
+		out := outputVerify{
+			Success:  success,
+			Message:  common.Hash(sig.M).Hex(),
+			KeyImage: hexutil.Encode(crypto.CompressPubkey(sig.I)),
+			RingID:   id.Hex(),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			if out.Success {
+				fmt.Println("Signature verification successful!")
+			} else {
+				fmt.Println("Signature verification failed!")
+			}
+			fmt.Println("Message hash:", out.Message)
+			fmt.Println("Key image:   ", out.KeyImage)
+			fmt.Println("Ring ID:     ", out.RingID)
+		}
+		if !out.Success {
+			return errVerifyFailed
+		}
+		return nil
+	},
+}
@@ -0,0 +1,226 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/console"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// promptPassphrase prompts the user for a passphrase.  Set confirmation to true
+// to require the user to confirm the passphrase.
+func promptPassphrase(confirmation bool) string {
+	passphrase, err := console.Stdin.PromptPassword("Passphrase: ")
+	if err != nil {
+		utils.Fatalf("Failed to read passphrase: %v", err)
+	}
+
+	if confirmation {
+		confirm, err := console.Stdin.PromptPassword("Repeat passphrase: ")
+		if err != nil {
+			utils.Fatalf("Failed to read passphrase confirmation: %v", err)
+		}
+		if passphrase != confirm {
+			utils.Fatalf("Passphrases do not match")
+		}
+	}
+
+	return passphrase
+}
+
+// getPassphrase obtains a passphrase given by the user.  It first checks the
+// --passwordfile command line flag and ultimately prompts the user for a
+// passphrase, which needs to be repeated if confirmation is set.
+func getPassphrase(ctx *cli.Context, confirmation bool) string {
+	// Look for the --passwordfile flag.
+	passphraseFile := ctx.String(passphraseFlag.Name)
+	if passphraseFile != "" {
+		content, err := ioutil.ReadFile(passphraseFile)
+		if err != nil {
+			utils.Fatalf("Failed to read passphrase file '%s': %v",
+				passphraseFile, err)
+		}
+		return strings.TrimRight(string(content), "\r\n")
+	}
+
+	// Otherwise prompt the user for the passphrase.
+	return promptPassphrase(confirmation)
+}
+
+// loadKey reads and decrypts the keyfile at the given path.
+func loadKey(ctx *cli.Context, keyfilepath string) *keystore.Key {
+	keyjson, err := ioutil.ReadFile(keyfilepath)
+	if err != nil {
+		utils.Fatalf("Failed to read the keyfile at '%s': %v", keyfilepath, err)
+	}
+	key, err := keystore.DecryptKey(keyjson, getPassphrase(ctx, false))
+	if err != nil {
+		utils.Fatalf("Error decrypting key: %v", err)
+	}
+	return key
+}
+
+// getMessage returns the message in the command line argument at position
+// msgarg or in the file given by --msgfile. The message is optional if
+// optional is set, getMessage returns nil then.
+func getMessage(ctx *cli.Context, msgarg int, optional bool) []byte {
+	if file := ctx.String(msgfileFlag.Name); file != "" {
+		if len(ctx.Args()) > msgarg {
+			utils.Fatalf("Can't use --msgfile and message argument at the same time.")
+		}
+		msg, err := ioutil.ReadFile(file)
+		if err != nil {
+			utils.Fatalf("Can't read message file: %v", err)
+		}
+		return msg
+	} else if len(ctx.Args()) == msgarg+1 {
+		return []byte(ctx.Args().Get(msgarg))
+	} else if optional && len(ctx.Args()) == msgarg {
+		return nil
+	}
+	utils.Fatalf("Invalid number of arguments: want %d, got %d", msgarg+1, len(ctx.Args()))
+	return nil
+}
+
+// messageHash returns the hash signed for a message, its Keccak256 hash.
+func messageHash(message []byte) common.Hash {
+	return crypto.Keccak256Hash(message)
+}
+
+// Ring and signature files hold either the JSON encoding below or the binary
+// encoding of the ring package, the compressed ring and the binary signature
+// format of the precompile. Readers accept both.
+
+type jsonRing struct {
+	Members []hexutil.Bytes `json:"members"`
+}
+
+type jsonSignature struct {
+	Message   common.Hash   `json:"message"`
+	KeyImage  hexutil.Bytes `json:"keyImage"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// isJSON reports whether the file content is a JSON object. Neither binary
+// encoding can start with '{'.
+func isJSON(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("{"))
+}
+
+// writeFile writes the binary encoding of an object if binary is set and the
+// indented JSON encoding of obj otherwise.
+func writeFile(path string, binary bool, enc []byte, obj interface{}) {
+	if !binary {
+		var err error
+		if enc, err = json.MarshalIndent(obj, "", "  "); err != nil {
+			utils.Fatalf("Failed to marshal JSON object: %v", err)
+		}
+		enc = append(enc, '\n')
+	}
+	if err := ioutil.WriteFile(path, enc, 0644); err != nil {
+		utils.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// writeRing writes the ring file at path.
+func writeRing(path string, binary bool, r ring.Ring) {
+	out := jsonRing{Members: make([]hexutil.Bytes, len(r))}
+	for i, pub := range r {
+		out.Members[i] = crypto.CompressPubkey(pub)
+	}
+	writeFile(path, binary, r.Compress(), out)
+}
+
+// readRing reads and validates the ring file at path.
+func readRing(path string) ring.Ring {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		utils.Fatalf("Failed to read ring file: %v", err)
+	}
+	var members ring.Ring
+	if isJSON(content) {
+		var in jsonRing
+		if err := json.Unmarshal(content, &in); err != nil {
+			utils.Fatalf("Invalid ring file: %v", err)
+		}
+		members = make(ring.Ring, len(in.Members))
+		for i, enc := range in.Members {
+			if members[i], err = crypto.DecompressPubkey(enc); err != nil {
+				utils.Fatalf("Invalid ring member %d: %v", i, err)
+			}
+		}
+	} else if members, err = ring.DecompressRing(crypto.S256(), content); err != nil {
+		utils.Fatalf("Invalid ring file: %v", err)
+	}
+	if err := members.Validate(); err != nil {
+		utils.Fatalf("Invalid ring: %v", err)
+	}
+	return members
+}
+
+// writeSignature writes the signature file at path.
+func writeSignature(path string, binary bool, sig *ring.RingSign) {
+	enc := sig.SerializeSignature()
+	out := jsonSignature{
+		Message:   sig.M,
+		KeyImage:  crypto.CompressPubkey(sig.I),
+		Signature: enc,
+	}
+	writeFile(path, binary, enc, out)
+}
+
+// readSignature reads the signature file at path.
+func readSignature(path string) *ring.RingSign {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		utils.Fatalf("Failed to read signature file: %v", err)
+	}
+	if isJSON(content) {
+		var in jsonSignature
+		if err := json.Unmarshal(content, &in); err != nil {
+			utils.Fatalf("Invalid signature file: %v", err)
+		}
+		content = in.Signature
+	}
+	sig, err := ring.DecodeStrict(ring.EncodingBinary, content)
+	if err != nil {
+		utils.Fatalf("Invalid signature in %s: %v", path, err)
+	}
+	return sig
+}
+
+// mustPrintJSON prints the JSON encoding of the given object and
+// exits the program with an error message when the marshaling fails.
+func mustPrintJSON(jsonObject interface{}) {
+	str, err := json.MarshalIndent(jsonObject, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to marshal JSON object: %v", err)
+	}
+	fmt.Println(string(str))
+}
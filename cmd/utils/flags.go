@@ -56,6 +56,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/ringmsg"
 	whisper "github.com/ethereum/go-ethereum/whisper/whisperv6"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -286,6 +287,16 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolKeyImageFilterSizeFlag = cli.Uint64Flag{
+		Name:  "txpool.keyimagefilter.size",
+		Usage: "Number of spent key images the key image filter is initially sized for",
+		Value: eth.DefaultConfig.TxPool.KeyImageFilterSize,
+	}
+	TxPoolKeyImageFilterFPRateFlag = cli.Float64Flag{
+		Name:  "txpool.keyimagefilter.fprate",
+		Usage: "False positive rate of the spent key image filter",
+		Value: eth.DefaultConfig.TxPool.KeyImageFilterFPRate,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -437,6 +448,14 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	RPCRingFlag = cli.BoolFlag{
+		Name:  "rpc.ring",
+		Usage: "Enable the ring signature API (ring), which must also be listed in --rpcapi or --wsapi to be served over HTTP or WebSocket",
+	}
+	RPCRingMemberIndexFlag = cli.BoolFlag{
+		Name:  "rpc.ring.memberindex",
+		Usage: "Index ring transactions by member public key, served by the ring API (full nodes only)",
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -572,6 +591,12 @@ var (
 		Usage: "Restrict connection between two whisper light clients",
 	}
 
+	// Ring messaging settings
+	RingMsgEnabledFlag = cli.BoolFlag{
+		Name:  "ringmsg",
+		Usage: "Enable anonymous messaging authenticated by ring signatures",
+	}
+
 	// Metrics flags
 	MetricsEnabledFlag = cli.BoolFlag{
 		Name:  metrics.MetricsEnabledFlag,
@@ -1045,6 +1070,12 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolKeyImageFilterSizeFlag.Name) {
+		cfg.KeyImageFilterSize = ctx.GlobalUint64(TxPoolKeyImageFilterSizeFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolKeyImageFilterFPRateFlag.Name) {
+		cfg.KeyImageFilterFPRate = ctx.GlobalFloat64(TxPoolKeyImageFilterFPRateFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
@@ -1198,6 +1229,13 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
 	}
 
+	if ctx.GlobalIsSet(RPCRingFlag.Name) {
+		cfg.RingRPC = ctx.GlobalBool(RPCRingFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCRingMemberIndexFlag.Name) {
+		cfg.RingMemberIndex = ctx.GlobalBool(RPCRingMemberIndexFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(EWASMInterpreterFlag.Name) {
 		cfg.EWASMInterpreter = ctx.GlobalString(EWASMInterpreterFlag.Name)
 	}
@@ -1296,6 +1334,16 @@ func RegisterShhService(stack *node.Node, cfg *whisper.Config) {
 	}
 }
 
+// RegisterRingMsgService configures the ring signed messaging service and adds
+// it to the given node.
+func RegisterRingMsgService(stack *node.Node, cfg *ringmsg.Config) {
+	if err := stack.Register(func(n *node.ServiceContext) (node.Service, error) {
+		return ringmsg.New(cfg), nil
+	}); err != nil {
+		Fatalf("Failed to register the ring messaging service: %v", err)
+	}
+}
+
 // RegisterEthStatsService configures the Ethereum Stats daemon and adds it to
 // the given node.
 func RegisterEthStatsService(stack *node.Node, url string) {
@@ -18,8 +18,11 @@ package clique
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -117,3 +120,64 @@ func (api *API) Discard(address common.Address) {
 
 	delete(api.clique.proposals, address)
 }
+
+// GetSignerRing retrieves the compressed public keys of the authorized signers
+// at the specified block, the ring attestations at the block are signed by.
+func (api *API) GetSignerRing(number *rpc.BlockNumber) ([]hexutil.Bytes, error) {
+	// Retrieve the requested block number (or current if none requested)
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	signers, err := api.clique.SignerRing(api.chain, header)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]hexutil.Bytes, len(signers))
+	for i, signer := range signers {
+		keys[i] = crypto.CompressPubkey(signer)
+	}
+	return keys, nil
+}
+
+// AttestationResult is the outcome of the verification of an attestation.
+type AttestationResult struct {
+	Number    uint64        `json:"number"`
+	Epoch     uint64        `json:"epoch"`
+	Topic     string        `json:"topic"`
+	Message   hexutil.Bytes `json:"message"`
+	Tag       common.Hash   `json:"tag"`
+	Duplicate bool          `json:"duplicate"`
+}
+
+// VerifyAttestation checks an RLP encoded attestation, returning its contents
+// if it was signed by one of the authorized signers. Attestations whose tag was
+// seen before, which were signed by the same signer on the same topic during
+// the same epoch, are flagged as duplicates.
+func (api *API) VerifyAttestation(raw hexutil.Bytes) (*AttestationResult, error) {
+	att := new(Attestation)
+	if err := rlp.DecodeBytes(raw, att); err != nil {
+		return nil, err
+	}
+	tag, err := api.clique.VerifyAttestation(api.chain, att)
+	if err != nil {
+		return nil, err
+	}
+	seen := api.clique.attestTags.Contains(tag)
+	if !seen {
+		api.clique.attestTags.Add(tag, struct{}{})
+	}
+	return &AttestationResult{
+		Number:    att.Number,
+		Epoch:     attestationEpoch(api.clique.config, att.Number),
+		Topic:     att.Topic,
+		Message:   att.Message,
+		Tag:       tag,
+		Duplicate: seen,
+	}, nil
+}
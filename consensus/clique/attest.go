@@ -0,0 +1,198 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Attestations are messages signed by one of the signers authorized at a block
+// without revealing which one, such as votes of confidence or whistleblower
+// reports. They are scoped ring signatures over the signer set, whose public
+// keys are recovered from the seals of the blocks the signers produced. The
+// key image of an attestation is scoped to its topic and epoch, so it links
+// all attestations of a signer on the same topic within an epoch: verifiers
+// accept one per signer, topic and epoch, which bounds the spam a signer can
+// send without identifying it.
+
+var (
+	// errAttestationTopic is returned if an attestation has no topic.
+	errAttestationTopic = errors.New("empty attestation topic")
+
+	// errInvalidAttestation is returned if the ring signature of an attestation
+	// is invalid.
+	errInvalidAttestation = errors.New("invalid attestation signature")
+
+	// errStaleAttestation is returned if an attestation is for a block older
+	// than the previous epoch or ahead of the chain.
+	errStaleAttestation = errors.New("attestation block outside of current epochs")
+
+	// errNotSigner is returned if an attestation is signed with a key not in the
+	// signer set.
+	errNotSigner = errors.New("attestation key not an authorized signer")
+)
+
+// Attestation is a message signed by one of the signers authorized at a block.
+type Attestation struct {
+	Number  uint64     // block whose signer set the attestation is signed by
+	Topic   string     // kind of attestation, one per signer per topic and epoch
+	Message []byte     // attested message
+	Image   []byte     // compressed key image of the signer for the topic and epoch
+	C       []*big.Int // ring signature challenges
+	S       []*big.Int // ring signature responses
+}
+
+// attestationEpoch returns the epoch of attestations for the given block.
+func attestationEpoch(config *params.CliqueConfig, number uint64) uint64 {
+	epoch := config.Epoch
+	if epoch == 0 {
+		epoch = epochLength
+	}
+	return number / epoch
+}
+
+// attestationScope returns the scope of the key images of attestations on the
+// topic during the epoch.
+func attestationScope(topic string, epoch uint64) []byte {
+	scope := make([]byte, 8, 8+len(topic))
+	binary.BigEndian.PutUint64(scope, epoch)
+	return append(scope, topic...)
+}
+
+// sigHash returns the hash signed by the attestation.
+func (a *Attestation) sigHash() [32]byte {
+	enc, _ := rlp.EncodeToBytes([]interface{}{a.Number, a.Topic, a.Message})
+	return crypto.Keccak256Hash(enc)
+}
+
+// SignAttestation creates an attestation of the message on the topic, signed
+// with the key of one of the signers, which must be the signer ring at the
+// given block.
+func SignAttestation(config *params.CliqueConfig, signers ring.Ring, number uint64, topic string, message []byte, key *ecdsa.PrivateKey) (*Attestation, error) {
+	if topic == "" {
+		return nil, errAttestationTopic
+	}
+	index := signers.Index(&key.PublicKey)
+	if index < 0 {
+		return nil, errNotSigner
+	}
+	att := &Attestation{
+		Number:  number,
+		Topic:   topic,
+		Message: message,
+	}
+	sig, err := ring.SignScoped(att.sigHash(), attestationScope(topic, attestationEpoch(config, number)), signers, key, index)
+	if err != nil {
+		return nil, err
+	}
+	att.Image = crypto.CompressPubkey(sig.Image)
+	att.C, att.S = sig.C, sig.S
+	return att, nil
+}
+
+// SignerRing returns the ring of the public keys of the signers authorized at
+// the given header, ordered by address. The keys are recovered from the seals
+// of previous blocks, so every signer must have sealed a block in the last
+// epoch.
+func (c *Clique) SignerRing(chain consensus.ChainReader, header *types.Header) (ring.Ring, error) {
+	snap, err := c.snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	signers := snap.signers()
+
+	c.keysLock.Lock()
+	defer c.keysLock.Unlock()
+
+	missing := func() int {
+		var count int
+		for _, signer := range signers {
+			if c.signerKeys[signer] == nil {
+				count++
+			}
+		}
+		return count
+	}
+	for i := uint64(0); i < c.config.Epoch && header != nil && header.Number.Sign() > 0 && missing() > 0; i++ {
+		if len(header.Extra) >= extraSeal {
+			signature := header.Extra[len(header.Extra)-extraSeal:]
+			if pub, err := crypto.SigToPub(sigHash(header).Bytes(), signature); err == nil {
+				c.signerKeys[crypto.PubkeyToAddress(*pub)] = pub
+			}
+		}
+		header = chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	if count := missing(); count > 0 {
+		return nil, fmt.Errorf("no sealed block of %d signer(s) in the last epoch", count)
+	}
+	keys := make(ring.Ring, len(signers))
+	for i, signer := range signers {
+		keys[i] = c.signerKeys[signer]
+	}
+	return keys, nil
+}
+
+// VerifyAttestation checks that the attestation was signed by one of the
+// signers authorized at its block, which must be in the current or previous
+// epoch of the chain, and returns its linkability tag. The tag is the same for
+// all attestations of a signer on the topic during the epoch.
+func (c *Clique) VerifyAttestation(chain consensus.ChainReader, att *Attestation) (common.Hash, error) {
+	if att.Topic == "" {
+		return common.Hash{}, errAttestationTopic
+	}
+	head := chain.CurrentHeader().Number.Uint64()
+	if epoch := attestationEpoch(c.config, head); att.Number > head || attestationEpoch(c.config, att.Number)+1 < epoch {
+		return common.Hash{}, errStaleAttestation
+	}
+	header := chain.GetHeaderByNumber(att.Number)
+	if header == nil {
+		return common.Hash{}, errUnknownBlock
+	}
+	signers, err := c.SignerRing(chain, header)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	image, err := crypto.DecompressPubkey(att.Image)
+	if err != nil {
+		return common.Hash{}, errInvalidAttestation
+	}
+	sig := &ring.ScopedSign{
+		M:     att.sigHash(),
+		Scope: attestationScope(att.Topic, attestationEpoch(c.config, att.Number)),
+		Ring:  signers,
+		Image: image,
+		C:     att.C,
+		S:     att.S,
+		Curve: crypto.S256(),
+	}
+	if !ring.VerifyScoped(sig) {
+		return common.Hash{}, errInvalidAttestation
+	}
+	return sig.ImageID(), nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Tests that attestations are verified against the signer ring recovered from
+// the chain, and that their tags link attestations per signer, topic and epoch.
+func TestAttestation(t *testing.T) {
+	// Create a chain of nine blocks sealed in turn by three signers
+	accounts := newTesterAccountPool()
+	names := []string{"A", "B", "C"}
+
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength*len(names)+extraSeal),
+	}
+	accounts.checkpoint(&types.Header{Extra: genesis.ExtraData}, names)
+
+	db := ethdb.NewMemDatabase()
+	genesis.Commit(db)
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 3}
+	engine := New(config.Clique, db)
+	engine.fakeDiff = true
+
+	blocks, _ := core.GenerateChain(&config, genesis.ToBlock(db), engine, db, 9, nil)
+	for i, block := range blocks {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		if header.Number.Uint64()%config.Clique.Epoch == 0 {
+			header.Extra = make([]byte, extraVanity+len(names)*common.AddressLength+extraSeal)
+			accounts.checkpoint(header, names)
+		}
+		header.Difficulty = diffInTurn
+		accounts.sign(header, names[i%len(names)])
+		blocks[i] = block.WithSeal(header)
+	}
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create test chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	signers, err := engine.SignerRing(chain, chain.CurrentHeader())
+	if err != nil {
+		t.Fatalf("failed to retrieve signer ring: %v", err)
+	}
+	if len(signers) != len(names) {
+		t.Fatalf("signer ring size mismatch: have %d, want %d", len(signers), len(names))
+	}
+	attest := func(number uint64, topic string, message, signer string) *Attestation {
+		att, err := SignAttestation(config.Clique, signers, number, topic, []byte(message), accounts.accounts[signer])
+		if err != nil {
+			t.Fatalf("failed to sign attestation: %v", err)
+		}
+		return att
+	}
+	verify := func(att *Attestation) common.Hash {
+		tag, err := engine.VerifyAttestation(chain, att)
+		if err != nil {
+			t.Fatalf("failed to verify attestation: %v", err)
+		}
+		return tag
+	}
+	// Attestations of a signer on a topic during an epoch share their tag
+	tag := verify(attest(9, "report", "first", "A"))
+	if other := verify(attest(9, "report", "second", "A")); other != tag {
+		t.Errorf("tag mismatch for same signer: have %x, want %x", other, tag)
+	}
+	if other := verify(attest(9, "report", "first", "B")); other == tag {
+		t.Errorf("tag shared by different signers")
+	}
+	if other := verify(attest(9, "vote", "first", "A")); other == tag {
+		t.Errorf("tag shared by different topics")
+	}
+	if other := verify(attest(8, "report", "first", "A")); other == tag {
+		t.Errorf("tag shared by different epochs")
+	}
+	// Attestations older than the previous epoch are rejected
+	if _, err := engine.VerifyAttestation(chain, attest(5, "report", "first", "A")); err != errStaleAttestation {
+		t.Errorf("stale attestation: have %v, want %v", err, errStaleAttestation)
+	}
+	// Tampered attestations and ones by outsiders are rejected
+	att := attest(9, "report", "first", "A")
+	att.Message = []byte("forged")
+	if _, err := engine.VerifyAttestation(chain, att); err != errInvalidAttestation {
+		t.Errorf("tampered attestation: have %v, want %v", err, errInvalidAttestation)
+	}
+	outsider, _ := crypto.GenerateKey()
+	if _, err := SignAttestation(config.Clique, signers, 9, "report", nil, outsider); err != errNotSigner {
+		t.Errorf("outsider attestation: have %v, want %v", err, errNotSigner)
+	}
+	forged := append(signers[:2:2], &outsider.PublicKey)
+	att, err = SignAttestation(config.Clique, forged, 9, "report", nil, outsider)
+	if err != nil {
+		t.Fatalf("failed to sign attestation: %v", err)
+	}
+	if _, err := engine.VerifyAttestation(chain, att); err != errInvalidAttestation {
+		t.Errorf("outsider ring attestation: have %v, want %v", err, errInvalidAttestation)
+	}
+	// The API flags repeated tags as duplicates
+	api := &API{chain: chain, clique: engine}
+
+	number := rpc.LatestBlockNumber
+	if keys, err := api.GetSignerRing(&number); err != nil || len(keys) != len(names) {
+		t.Fatalf("signer ring: have %d keys, %v, want %d", len(keys), err, len(names))
+	}
+	for i, want := range []bool{false, true} {
+		enc, _ := rlp.EncodeToBytes(attest(9, "api", "message", "C"))
+		res, err := api.VerifyAttestation(enc)
+		if err != nil {
+			t.Fatalf("attestation %d: failed to verify: %v", i, err)
+		}
+		if res.Duplicate != want || res.Epoch != 3 {
+			t.Errorf("attestation %d: duplicate %v, epoch %d, want %v, 3", i, res.Duplicate, res.Epoch, want)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package clique
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"errors"
 	"math/big"
 	"math/rand"
@@ -46,6 +47,7 @@ const (
 	checkpointInterval = 1024 // Number of blocks after which to save the vote snapshot to the database
 	inmemorySnapshots  = 128  // Number of recent vote snapshots to keep in memory
 	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+	inmemoryAttestTags = 4096 // Number of recent attestation tags to keep in memory
 
 	wiggleTime = 500 * time.Millisecond // Random delay (per signer) to allow concurrent signers
 )
@@ -204,6 +206,10 @@ type Clique struct {
 
 	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
+	attestTags *lru.ARCCache // Tags of recently verified attestations to reject duplicates
+
+	signerKeys map[common.Address]*ecdsa.PublicKey // Public keys of signers recovered from their seals
+	keysLock   sync.Mutex                          // Protects the signer keys
 
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
@@ -226,12 +232,15 @@ func New(config *params.CliqueConfig, db ethdb.Database) *Clique {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	signatures, _ := lru.NewARC(inmemorySignatures)
+	attestTags, _ := lru.NewARC(inmemoryAttestTags)
 
 	return &Clique{
 		config:     &conf,
 		db:         db,
 		recents:    recents,
 		signatures: signatures,
+		attestTags: attestTags,
+		signerKeys: make(map[common.Address]*ecdsa.PublicKey),
 		proposals:  make(map[common.Address]bool),
 	}
 }
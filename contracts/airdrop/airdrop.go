@@ -0,0 +1,150 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package airdrop builds anonymous airdrop claims, letting the holders of a
+// token at a snapshot block claim without revealing which holder they are.
+//
+// The eligible holders are partitioned into rings whose identifiers, see
+// ring.RingID, are registered with the airdrop contract. A holder claims by
+// ring signing the recipient with the key of its address; the contract
+// verifies the signature with the ring verification precompile, checks that
+// its ring is registered and uses its key image to allow one claim per
+// holder. The recipient should be a fresh account, as funding it from the
+// holder's address would link the two.
+package airdrop
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// AirdropABI is the interface of an airdrop contract. The signature passed to
+// claim is in the binary format of the ring verification precompile.
+const AirdropABI = `[
+	{"type":"function","name":"addRings","constant":false,
+	 "inputs":[{"name":"ringIds","type":"bytes32[]"}],"outputs":[]},
+	{"type":"function","name":"claim","constant":false,
+	 "inputs":[{"name":"recipient","type":"address"},{"name":"signature","type":"bytes"}],"outputs":[]},
+	{"type":"function","name":"claimed","stateMutability":"view","constant":true,
+	 "inputs":[{"name":"keyImage","type":"bytes32"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+var errNotClaimant = errors.New("key not in any ring")
+
+// airdropABI is the parsed AirdropABI.
+var airdropABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(AirdropABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Partition splits the claimants into rings of at least size members, and
+// fewer than twice as many. Rings are consecutive runs of the claimants in the
+// given order, so anyone holding the snapshot can rebuild them.
+func Partition(claimants []*Holder, size int) ([]ring.Ring, error) {
+	if size < 2 {
+		return nil, fmt.Errorf("ring size %d below minimum 2", size)
+	}
+	if len(claimants) < size {
+		return nil, fmt.Errorf("%d claimants can't fill a ring of %d", len(claimants), size)
+	}
+	var (
+		n     = len(claimants)
+		rings = make([]ring.Ring, n/size)
+	)
+	for i := range rings {
+		from, to := i*n/len(rings), (i+1)*n/len(rings)
+		for _, holder := range claimants[from:to] {
+			rings[i] = append(rings[i], holder.Key)
+		}
+		if err := rings[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rings, nil
+}
+
+// PackAddRings returns the calldata registering the rings with the airdrop
+// contract.
+func PackAddRings(rings []ring.Ring) ([]byte, error) {
+	ids := make([][32]byte, len(rings))
+	for i, r := range rings {
+		id, err := ring.RingID(r)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return airdropABI.Pack("addRings", ids)
+}
+
+// ClaimHash returns the message signed by claims from the airdrop, the hash
+// of the packed airdrop and recipient addresses.
+func ClaimHash(airdrop, recipient common.Address) common.Hash {
+	return crypto.Keccak256Hash(airdrop.Bytes(), recipient.Bytes())
+}
+
+// Claim is a claim of an airdrop on behalf of an unknown holder.
+type Claim struct {
+	Airdrop   common.Address // airdrop contract to claim from
+	Recipient common.Address // account receiving the airdrop
+	RingID    common.Hash    // identifier of the ring the holder hides in
+	KeyImage  common.Hash    // hash of the key image, marking the holder as claimed
+	Signature []byte         // binary ring signature over the claim hash
+}
+
+// BuildClaim signs a claim of the airdrop for the recipient, with the key of
+// a holder in one of the rings.
+func BuildClaim(airdrop common.Address, rings []ring.Ring, key *ecdsa.PrivateKey, recipient common.Address) (*Claim, error) {
+	for _, members := range rings {
+		for i, member := range members {
+			if member.X.Cmp(key.X) != 0 || member.Y.Cmp(key.Y) != 0 {
+				continue
+			}
+			sig, err := ring.Sign(ClaimHash(airdrop, recipient), members, key, i)
+			if err != nil {
+				return nil, err
+			}
+			id, err := ring.RingID(members)
+			if err != nil {
+				return nil, err
+			}
+			return &Claim{
+				Airdrop:   airdrop,
+				Recipient: recipient,
+				RingID:    id,
+				KeyImage:  crypto.Keccak256Hash(math.PaddedBigBytes(sig.I.X, 32), math.PaddedBigBytes(sig.I.Y, 32)),
+				Signature: sig.SerializeSignature(),
+			}, nil
+		}
+	}
+	return nil, errNotClaimant
+}
+
+// Calldata returns the calldata submitting the claim to the airdrop contract.
+func (c *Claim) Calldata() ([]byte, error) {
+	return airdropABI.Pack("claim", c.Recipient, c.Signature)
+}
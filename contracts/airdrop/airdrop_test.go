@@ -0,0 +1,278 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package airdrop
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+var (
+	testToken   = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+	testAirdrop = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+)
+
+// testChain is a chain of blocks and token transfer events.
+type testChain struct {
+	blocks map[uint64]*types.Block
+	logs   []types.Log
+}
+
+func newTestChain() *testChain {
+	return &testChain{blocks: make(map[uint64]*types.Block)}
+}
+
+func (c *testChain) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return c.blocks[number.Uint64()], nil
+}
+
+func (c *testChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, log := range c.logs {
+		if log.Address == q.Addresses[0] && log.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// transfer adds a transaction by sender to the block, emitting a transfer of
+// the token. Values are logged as ERC-20 transfers, token IDs as ERC-721 ones.
+func (c *testChain) transfer(number uint64, sender *ecdsa.PrivateKey, from, to common.Address, value, id *big.Int) {
+	block := c.blocks[number]
+	if block == nil {
+		block = types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(number)}, nil, nil, nil)
+	}
+	tx := types.NewTransaction(uint64(len(block.Transactions())), testToken, new(big.Int), 100000, new(big.Int), nil)
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, sender)
+	c.blocks[number] = types.NewBlock(block.Header(), append(block.Transactions(), tx), nil, nil)
+
+	log := types.Log{
+		Address:     testToken,
+		Topics:      []common.Hash{transferTopic, from.Hash(), to.Hash()},
+		BlockNumber: number,
+		TxHash:      tx.Hash(),
+	}
+	if id != nil {
+		log.Topics = append(log.Topics, common.BigToHash(id))
+	} else {
+		log.Data = common.BigToHash(value).Bytes()
+	}
+	c.logs = append(c.logs, log)
+}
+
+// Tests that snapshots replay ERC-20 transfers up to their block, and recover
+// the keys of the holders who sent transfers.
+func TestSnapshotERC20(t *testing.T) {
+	var (
+		chain    = newTestChain()
+		minter   = genKey()
+		keys     = make([]*ecdsa.PrivateKey, 7)
+		addrs    = make([]common.Address, len(keys))
+		zero     = common.Address{}
+		thousand = big.NewInt(1000)
+	)
+	for i := range keys {
+		keys[i] = genKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	for i := 0; i < 6; i++ {
+		chain.transfer(1, minter, zero, addrs[i], thousand, nil)
+	}
+	chain.transfer(2, keys[0], addrs[0], addrs[6], thousand, nil)
+	for i := 1; i < 5; i++ {
+		chain.transfer(3, keys[i], addrs[i], addrs[i+1], big.NewInt(int64(i)), nil)
+	}
+	chain.transfer(4, keys[5], addrs[5], zero, thousand, nil) // burn after the snapshot
+
+	snap, err := TakeSnapshot(context.Background(), chain, testToken, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[common.Address]int64{
+		addrs[1]: 999, addrs[2]: 999, addrs[3]: 999, addrs[4]: 999, addrs[5]: 1004, addrs[6]: 1000,
+	}
+	if len(snap.Holders) != len(want) {
+		t.Fatalf("holder count mismatch: have %d, want %d", len(snap.Holders), len(want))
+	}
+	for i, holder := range snap.Holders {
+		if i > 0 && bytes.Compare(snap.Holders[i-1].Address[:], holder.Address[:]) >= 0 {
+			t.Errorf("holder %d out of order", i)
+		}
+		if balance, ok := want[holder.Address]; !ok || holder.Balance.Cmp(big.NewInt(balance)) != 0 {
+			t.Errorf("holder %x balance mismatch: have %v, want %d", holder.Address, holder.Balance, balance)
+		}
+		if (holder.Key != nil) != (holder.Address != addrs[5] && holder.Address != addrs[6]) {
+			t.Errorf("holder %x key recovery mismatch: have %v", holder.Address, holder.Key != nil)
+		}
+	}
+	claimants, missing := snap.Eligible(big.NewInt(999))
+	if len(claimants) != 4 || len(missing) != 2 {
+		t.Fatalf("eligible holders mismatch: have %d claimants, %d missing, want 4, 2", len(claimants), len(missing))
+	}
+	if err := snap.SetKey(&keys[5].PublicKey); err != nil {
+		t.Fatalf("failed to set holder key: %v", err)
+	}
+	if err := snap.SetKey(&keys[0].PublicKey); err != errNotHolder {
+		t.Fatalf("former holder key error mismatch: have %v, want %v", err, errNotHolder)
+	}
+	if claimants, missing = snap.Eligible(thousand); len(claimants) != 1 || len(missing) != 1 || missing[0] != addrs[6] {
+		t.Fatalf("eligible holders mismatch: have %d claimants, missing %x, want 1, [%x]", len(claimants), missing, addrs[6])
+	}
+}
+
+// Tests that snapshots count the ERC-721 tokens of every holder.
+func TestSnapshotERC721(t *testing.T) {
+	var (
+		chain = newTestChain()
+		a, b  = genKey(), genKey()
+		addrA = crypto.PubkeyToAddress(a.PublicKey)
+		addrB = crypto.PubkeyToAddress(b.PublicKey)
+	)
+	for id := int64(1); id <= 3; id++ {
+		chain.transfer(1, a, common.Address{}, addrA, nil, big.NewInt(id))
+	}
+	chain.transfer(2, a, addrA, addrB, nil, big.NewInt(2))
+
+	snap, err := TakeSnapshot(context.Background(), chain, testToken, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, holder := range snap.Holders {
+		want := int64(2)
+		if holder.Address == addrB {
+			want = 1
+		}
+		if holder.Balance.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("holder %x token count mismatch: have %v, want %d", holder.Address, holder.Balance, want)
+		}
+	}
+	if len(snap.Holders) != 2 {
+		t.Fatalf("holder count mismatch: have %d, want 2", len(snap.Holders))
+	}
+}
+
+// testClaimants returns n claimants with their keys.
+func testClaimants(n int) ([]*Holder, []*ecdsa.PrivateKey) {
+	holders := make([]*Holder, n)
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range holders {
+		keys[i] = genKey()
+		holders[i] = &Holder{Address: crypto.PubkeyToAddress(keys[i].PublicKey), Balance: big.NewInt(1), Key: &keys[i].PublicKey}
+	}
+	return holders, keys
+}
+
+// Tests that partitioning puts every claimant in exactly one ring of the
+// requested size or a bit larger.
+func TestPartition(t *testing.T) {
+	for _, n := range []int{5, 9, 10, 23} {
+		claimants, _ := testClaimants(n)
+		rings, err := Partition(claimants, 5)
+		if err != nil {
+			t.Fatalf("%d claimants: %v", n, err)
+		}
+		if len(rings) != n/5 {
+			t.Errorf("%d claimants: ring count mismatch: have %d, want %d", n, len(rings), n/5)
+		}
+		total := 0
+		for i, r := range rings {
+			if len(r) < 5 || len(r) >= 10 {
+				t.Errorf("%d claimants: ring %d size %d out of bounds", n, i, len(r))
+			}
+			for _, member := range r {
+				if member != claimants[total].Key {
+					t.Fatalf("%d claimants: claimant %d misplaced", n, total)
+				}
+				total++
+			}
+		}
+		if total != n {
+			t.Errorf("%d claimants: partitioned %d", n, total)
+		}
+	}
+	claimants, _ := testClaimants(4)
+	if _, err := Partition(claimants, 5); err == nil {
+		t.Error("partitioned too few claimants")
+	}
+}
+
+// Tests that claims are ring signatures the precompile accepts, over the
+// registered ring of the claimant, packed into claim calldata.
+func TestBuildClaim(t *testing.T) {
+	claimants, keys := testClaimants(12)
+	rings, err := Partition(claimants, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := common.HexToAddress("0x01")
+	claim, err := BuildClaim(testAirdrop, rings, keys[6], recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := ring.RingID(rings[1])
+	if claim.RingID != id {
+		t.Errorf("ring ID mismatch: have %x, want %x", claim.RingID, id)
+	}
+	sig, err := ring.DeserializeSignature(claim.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.Hash(sig.M) != ClaimHash(testAirdrop, recipient) {
+		t.Error("signed message mismatch")
+	}
+	out, err := vm.PrecompiledContractsRing[vm.RingVerifyAddress].Run(append(make([]byte, 32), claim.Signature...))
+	if err != nil || !bytes.Equal(out, []byte{1}) {
+		t.Fatalf("precompile rejected claim: %x, %v", out, err)
+	}
+	// Claims of the same holder share their key image
+	again, err := BuildClaim(testAirdrop, rings, keys[6], common.HexToAddress("0x02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.KeyImage != claim.KeyImage {
+		t.Error("key images of the same holder differ")
+	}
+	data, err := claim.Calldata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[:4], airdropABI.Methods["claim"].Id()) {
+		t.Errorf("calldata selector mismatch: have %x", data[:4])
+	}
+	if _, err := PackAddRings(rings); err != nil {
+		t.Errorf("failed to pack rings: %v", err)
+	}
+	if _, err := BuildClaim(testAirdrop, rings, genKey(), recipient); err != errNotClaimant {
+		t.Errorf("non claimant error mismatch: have %v, want %v", err, errNotClaimant)
+	}
+}
+
+func genKey() *ecdsa.PrivateKey {
+	key, _ := crypto.GenerateKey()
+	return key
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package airdrop
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring/chainkeys"
+)
+
+// transferTopic is the topic of the Transfer events of ERC-20 and ERC-721
+// tokens, which only differ in ERC-721 indexing the token ID instead of
+// logging the value.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+var errNotHolder = errors.New("key of an address not holding the token")
+
+// ChainReader retrieves the blocks and logs snapshots are taken from. It is
+// implemented by ethclient.Client.
+type ChainReader interface {
+	chainkeys.BlockReader
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Holder is an account holding a token at the snapshot block.
+type Holder struct {
+	Address common.Address
+	Balance *big.Int         // amount of ERC-20 tokens or number of ERC-721 tokens held
+	Key     *ecdsa.PublicKey // public key, nil if it couldn't be recovered
+}
+
+// Snapshot lists the holders of a token at a block.
+type Snapshot struct {
+	Token   common.Address // ERC-20 or ERC-721 token contract
+	Block   uint64         // block the balances are taken at
+	Holders []*Holder      // holders with a positive balance, by address
+}
+
+// TakeSnapshot replays the Transfer events of the token up to and including
+// the block to find its holders and their balances. Public keys are recovered
+// from the transactions in the blocks of the transfers, which covers every
+// holder who ever sent the token; others can be added with SetKey.
+func TakeSnapshot(ctx context.Context, chain ChainReader, token common.Address, block uint64) (*Snapshot, error) {
+	logs, err := chain.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int),
+		ToBlock:   new(big.Int).SetUint64(block),
+		Addresses: []common.Address{token},
+		Topics:    [][]common.Hash{{transferTopic}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		balances = make(map[common.Address]*big.Int)
+		blocks   = make(map[uint64]bool)
+	)
+	credit := func(addr common.Address, amount *big.Int) {
+		if addr == (common.Address{}) {
+			return // mint or burn
+		}
+		if balances[addr] == nil {
+			balances[addr] = new(big.Int)
+		}
+		balances[addr].Add(balances[addr], amount)
+	}
+	for _, log := range logs {
+		if log.Removed || log.Address != token || log.BlockNumber > block {
+			continue
+		}
+		var amount *big.Int
+		switch {
+		case len(log.Topics) == 4 && len(log.Data) == 0:
+			amount = big.NewInt(1) // ERC-721, the token ID is the last topic
+		case len(log.Topics) == 3 && len(log.Data) == 32:
+			amount = new(big.Int).SetBytes(log.Data)
+		default:
+			return nil, fmt.Errorf("malformed transfer event in transaction %x", log.TxHash)
+		}
+		credit(common.BytesToAddress(log.Topics[1].Bytes()), new(big.Int).Neg(amount))
+		credit(common.BytesToAddress(log.Topics[2].Bytes()), amount)
+		blocks[log.BlockNumber] = true
+	}
+	keys := chainkeys.NewRingBuilder(chain)
+	for number := range blocks {
+		block, err := chain.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range block.Transactions() {
+			// a malformed transaction cannot hide keys of other senders
+			keys.AddTransaction(tx)
+		}
+	}
+	snap := &Snapshot{Token: token, Block: block}
+	for addr, balance := range balances {
+		if balance.Sign() > 0 {
+			key, _ := keys.PublicKey(addr)
+			snap.Holders = append(snap.Holders, &Holder{Address: addr, Balance: balance, Key: key})
+		}
+	}
+	sort.Slice(snap.Holders, func(i, j int) bool {
+		return bytes.Compare(snap.Holders[i].Address[:], snap.Holders[j].Address[:]) < 0
+	})
+	return snap, nil
+}
+
+// SetKey sets the public key of the holder it belongs to, for holders whose
+// key wasn't recovered from the transfers.
+func (s *Snapshot) SetKey(key *ecdsa.PublicKey) error {
+	addr := crypto.PubkeyToAddress(*key)
+	for _, holder := range s.Holders {
+		if holder.Address == addr {
+			holder.Key = key
+			return nil
+		}
+	}
+	return errNotHolder
+}
+
+// Eligible returns the holders with at least min tokens, split into those
+// whose key is known and can claim, and those missing a key.
+func (s *Snapshot) Eligible(min *big.Int) (claimants []*Holder, missing []common.Address) {
+	for _, holder := range s.Holders {
+		if holder.Balance.Cmp(min) < 0 {
+			continue
+		}
+		if holder.Key == nil {
+			missing = append(missing, holder.Address)
+		} else {
+			claimants = append(claimants, holder)
+		}
+	}
+	return claimants, missing
+}
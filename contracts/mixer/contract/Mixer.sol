@@ -0,0 +1,87 @@
+pragma solidity ^0.4.24;
+
+// Mixer is a fixed denomination mixer. A deposit pays the denomination for a
+// fresh public key, a withdrawal is a linkable ring signature by one of the
+// deposited keys, verified by the ring verification precompile at 0x09. The
+// signature hides which deposit is withdrawn, while its key image, which is
+// the same for every signature by a key, prevents withdrawing a deposit twice.
+contract Mixer {
+    // Offsets into the binary ring signature format of the precompile: an 8
+    // byte version and size, the message, the challenge, then a response and
+    // the coordinates of every member, then the key image.
+    uint256 constant MESSAGE = 8;
+    uint256 constant MEMBERS = 72;
+    uint256 constant MEMBER_SIZE = 96;
+
+    uint256 public denomination;
+    uint256 public depositCount;
+    mapping(bytes32 => bool) public deposited;
+    mapping(bytes32 => bool) public spent;
+
+    event Deposit(uint256 x, uint256 y, uint256 index);
+    event Withdrawal(address recipient, address relayer, uint256 fee, bytes32 keyImage);
+
+    constructor(uint256 _denomination) public {
+        require(_denomination > 0);
+        denomination = _denomination;
+    }
+
+    // deposit pays the denomination for the secp256k1 public key (x, y).
+    function deposit(uint256 x, uint256 y) public payable {
+        require(msg.value == denomination);
+        bytes32 key = keccak256(abi.encodePacked(x, y));
+        require(!deposited[key]);
+        deposited[key] = true;
+        emit Deposit(x, y, depositCount++);
+    }
+
+    // withdrawalHash is the message signed by withdrawals.
+    function withdrawalHash(address recipient, address relayer, uint256 fee) public view returns (bytes32) {
+        return keccak256(abi.encodePacked(address(this), recipient, relayer, fee));
+    }
+
+    // withdraw pays the denomination to the recipient, less the fee paid to
+    // the relayer, for a ring signature over deposited keys.
+    function withdraw(address recipient, address relayer, uint256 fee, bytes signature) public {
+        require(fee <= denomination);
+
+        uint256 size = word(signature, 0) / 2**192 & (2**56 - 1);
+        require(signature.length == MEMBERS + size * MEMBER_SIZE + 64);
+        require(bytes32(word(signature, MESSAGE)) == withdrawalHash(recipient, relayer, fee));
+
+        for (uint256 i = 0; i < size; i++) {
+            uint256 member = MEMBERS + i * MEMBER_SIZE;
+            require(deposited[keccak256(abi.encodePacked(word(signature, member + 32), word(signature, member + 64)))]);
+        }
+        uint256 image = MEMBERS + size * MEMBER_SIZE;
+        bytes32 keyImage = keccak256(abi.encodePacked(word(signature, image), word(signature, image + 32)));
+        require(!spent[keyImage]);
+        require(verify(signature));
+        spent[keyImage] = true;
+
+        recipient.transfer(denomination - fee);
+        if (fee > 0) {
+            relayer.transfer(fee);
+        }
+        emit Withdrawal(recipient, relayer, fee, keyImage);
+    }
+
+    // verify calls the ring verification precompile, whose input is a word
+    // it ignores followed by the signature.
+    function verify(bytes signature) internal view returns (bool) {
+        bytes memory input = abi.encodePacked(bytes32(0), signature);
+        bytes memory output = new bytes(1);
+        bool ok;
+        assembly {
+            ok := staticcall(gas, 0x09, add(input, 32), mload(input), add(output, 32), 1)
+        }
+        return ok && output[0] == 1;
+    }
+
+    // word returns the 32 bytes of b at offset.
+    function word(bytes b, uint256 offset) internal pure returns (uint256 w) {
+        assembly {
+            w := mload(add(add(b, 32), offset))
+        }
+    }
+}
@@ -0,0 +1,594 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// MixerABI is the input ABI used to generate the binding from.
+const MixerABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"denomination\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"depositCount\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"deposited\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"spent\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"x\",\"type\":\"uint256\"},{\"name\":\"y\",\"type\":\"uint256\"}],\"name\":\"deposit\",\"outputs\":[],\"payable\":true,\"stateMutability\":\"payable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"recipient\",\"type\":\"address\"},{\"name\":\"relayer\",\"type\":\"address\"},{\"name\":\"fee\",\"type\":\"uint256\"}],\"name\":\"withdrawalHash\",\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"recipient\",\"type\":\"address\"},{\"name\":\"relayer\",\"type\":\"address\"},{\"name\":\"fee\",\"type\":\"uint256\"},{\"name\":\"signature\",\"type\":\"bytes\"}],\"name\":\"withdraw\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"_denomination\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"name\":\"x\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"y\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"index\",\"type\":\"uint256\"}],\"name\":\"Deposit\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"name\":\"recipient\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"relayer\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"fee\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"keyImage\",\"type\":\"bytes32\"}],\"name\":\"Withdrawal\",\"type\":\"event\"}]"
+
+// Mixer is an auto generated Go binding around an Ethereum contract.
+type Mixer struct {
+	MixerCaller     // Read-only binding to the contract
+	MixerTransactor // Write-only binding to the contract
+	MixerFilterer   // Log filterer for contract events
+}
+
+// MixerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type MixerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MixerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type MixerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MixerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type MixerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MixerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type MixerSession struct {
+	Contract     *Mixer            // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// MixerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type MixerCallerSession struct {
+	Contract *MixerCaller  // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// MixerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type MixerTransactorSession struct {
+	Contract     *MixerTransactor  // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// MixerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type MixerRaw struct {
+	Contract *Mixer // Generic contract binding to access the raw methods on
+}
+
+// MixerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type MixerCallerRaw struct {
+	Contract *MixerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// MixerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type MixerTransactorRaw struct {
+	Contract *MixerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewMixer creates a new instance of Mixer, bound to a specific deployed contract.
+func NewMixer(address common.Address, backend bind.ContractBackend) (*Mixer, error) {
+	contract, err := bindMixer(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Mixer{MixerCaller: MixerCaller{contract: contract}, MixerTransactor: MixerTransactor{contract: contract}, MixerFilterer: MixerFilterer{contract: contract}}, nil
+}
+
+// NewMixerCaller creates a new read-only instance of Mixer, bound to a specific deployed contract.
+func NewMixerCaller(address common.Address, caller bind.ContractCaller) (*MixerCaller, error) {
+	contract, err := bindMixer(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MixerCaller{contract: contract}, nil
+}
+
+// NewMixerTransactor creates a new write-only instance of Mixer, bound to a specific deployed contract.
+func NewMixerTransactor(address common.Address, transactor bind.ContractTransactor) (*MixerTransactor, error) {
+	contract, err := bindMixer(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MixerTransactor{contract: contract}, nil
+}
+
+// NewMixerFilterer creates a new log filterer instance of Mixer, bound to a specific deployed contract.
+func NewMixerFilterer(address common.Address, filterer bind.ContractFilterer) (*MixerFilterer, error) {
+	contract, err := bindMixer(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &MixerFilterer{contract: contract}, nil
+}
+
+// bindMixer binds a generic wrapper to an already deployed contract.
+func bindMixer(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(MixerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Mixer *MixerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _Mixer.Contract.MixerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Mixer *MixerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Mixer.Contract.MixerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Mixer *MixerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Mixer.Contract.MixerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Mixer *MixerCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _Mixer.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Mixer *MixerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Mixer.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Mixer *MixerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Mixer.Contract.contract.Transact(opts, method, params...)
+}
+
+// Denomination is a free data retrieval call binding the contract method 0x8bca6d16.
+//
+// Solidity: function denomination() constant returns(uint256)
+func (_Mixer *MixerCaller) Denomination(opts *bind.CallOpts) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _Mixer.contract.Call(opts, out, "denomination")
+	return *ret0, err
+}
+
+// Denomination is a free data retrieval call binding the contract method 0x8bca6d16.
+//
+// Solidity: function denomination() constant returns(uint256)
+func (_Mixer *MixerSession) Denomination() (*big.Int, error) {
+	return _Mixer.Contract.Denomination(&_Mixer.CallOpts)
+}
+
+// Denomination is a free data retrieval call binding the contract method 0x8bca6d16.
+//
+// Solidity: function denomination() constant returns(uint256)
+func (_Mixer *MixerCallerSession) Denomination() (*big.Int, error) {
+	return _Mixer.Contract.Denomination(&_Mixer.CallOpts)
+}
+
+// DepositCount is a free data retrieval call binding the contract method 0x2dfdf0b5.
+//
+// Solidity: function depositCount() constant returns(uint256)
+func (_Mixer *MixerCaller) DepositCount(opts *bind.CallOpts) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _Mixer.contract.Call(opts, out, "depositCount")
+	return *ret0, err
+}
+
+// DepositCount is a free data retrieval call binding the contract method 0x2dfdf0b5.
+//
+// Solidity: function depositCount() constant returns(uint256)
+func (_Mixer *MixerSession) DepositCount() (*big.Int, error) {
+	return _Mixer.Contract.DepositCount(&_Mixer.CallOpts)
+}
+
+// DepositCount is a free data retrieval call binding the contract method 0x2dfdf0b5.
+//
+// Solidity: function depositCount() constant returns(uint256)
+func (_Mixer *MixerCallerSession) DepositCount() (*big.Int, error) {
+	return _Mixer.Contract.DepositCount(&_Mixer.CallOpts)
+}
+
+// Deposited is a free data retrieval call binding the contract method 0x1f36edcd.
+//
+// Solidity: function deposited( bytes32) constant returns(bool)
+func (_Mixer *MixerCaller) Deposited(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
+	var (
+		ret0 = new(bool)
+	)
+	out := ret0
+	err := _Mixer.contract.Call(opts, out, "deposited", arg0)
+	return *ret0, err
+}
+
+// Deposited is a free data retrieval call binding the contract method 0x1f36edcd.
+//
+// Solidity: function deposited( bytes32) constant returns(bool)
+func (_Mixer *MixerSession) Deposited(arg0 [32]byte) (bool, error) {
+	return _Mixer.Contract.Deposited(&_Mixer.CallOpts, arg0)
+}
+
+// Deposited is a free data retrieval call binding the contract method 0x1f36edcd.
+//
+// Solidity: function deposited( bytes32) constant returns(bool)
+func (_Mixer *MixerCallerSession) Deposited(arg0 [32]byte) (bool, error) {
+	return _Mixer.Contract.Deposited(&_Mixer.CallOpts, arg0)
+}
+
+// Spent is a free data retrieval call binding the contract method 0xae20bed3.
+//
+// Solidity: function spent( bytes32) constant returns(bool)
+func (_Mixer *MixerCaller) Spent(opts *bind.CallOpts, arg0 [32]byte) (bool, error) {
+	var (
+		ret0 = new(bool)
+	)
+	out := ret0
+	err := _Mixer.contract.Call(opts, out, "spent", arg0)
+	return *ret0, err
+}
+
+// Spent is a free data retrieval call binding the contract method 0xae20bed3.
+//
+// Solidity: function spent( bytes32) constant returns(bool)
+func (_Mixer *MixerSession) Spent(arg0 [32]byte) (bool, error) {
+	return _Mixer.Contract.Spent(&_Mixer.CallOpts, arg0)
+}
+
+// Spent is a free data retrieval call binding the contract method 0xae20bed3.
+//
+// Solidity: function spent( bytes32) constant returns(bool)
+func (_Mixer *MixerCallerSession) Spent(arg0 [32]byte) (bool, error) {
+	return _Mixer.Contract.Spent(&_Mixer.CallOpts, arg0)
+}
+
+// WithdrawalHash is a free data retrieval call binding the contract method 0x8367d5a8.
+//
+// Solidity: function withdrawalHash(recipient address, relayer address, fee uint256) constant returns(bytes32)
+func (_Mixer *MixerCaller) WithdrawalHash(opts *bind.CallOpts, recipient common.Address, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	var (
+		ret0 = new([32]byte)
+	)
+	out := ret0
+	err := _Mixer.contract.Call(opts, out, "withdrawalHash", recipient, relayer, fee)
+	return *ret0, err
+}
+
+// WithdrawalHash is a free data retrieval call binding the contract method 0x8367d5a8.
+//
+// Solidity: function withdrawalHash(recipient address, relayer address, fee uint256) constant returns(bytes32)
+func (_Mixer *MixerSession) WithdrawalHash(recipient common.Address, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	return _Mixer.Contract.WithdrawalHash(&_Mixer.CallOpts, recipient, relayer, fee)
+}
+
+// WithdrawalHash is a free data retrieval call binding the contract method 0x8367d5a8.
+//
+// Solidity: function withdrawalHash(recipient address, relayer address, fee uint256) constant returns(bytes32)
+func (_Mixer *MixerCallerSession) WithdrawalHash(recipient common.Address, relayer common.Address, fee *big.Int) ([32]byte, error) {
+	return _Mixer.Contract.WithdrawalHash(&_Mixer.CallOpts, recipient, relayer, fee)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0xe2bbb158.
+//
+// Solidity: function deposit(x uint256, y uint256) returns()
+func (_Mixer *MixerTransactor) Deposit(opts *bind.TransactOpts, x *big.Int, y *big.Int) (*types.Transaction, error) {
+	return _Mixer.contract.Transact(opts, "deposit", x, y)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0xe2bbb158.
+//
+// Solidity: function deposit(x uint256, y uint256) returns()
+func (_Mixer *MixerSession) Deposit(x *big.Int, y *big.Int) (*types.Transaction, error) {
+	return _Mixer.Contract.Deposit(&_Mixer.TransactOpts, x, y)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0xe2bbb158.
+//
+// Solidity: function deposit(x uint256, y uint256) returns()
+func (_Mixer *MixerTransactorSession) Deposit(x *big.Int, y *big.Int) (*types.Transaction, error) {
+	return _Mixer.Contract.Deposit(&_Mixer.TransactOpts, x, y)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf37e8d38.
+//
+// Solidity: function withdraw(recipient address, relayer address, fee uint256, signature bytes) returns()
+func (_Mixer *MixerTransactor) Withdraw(opts *bind.TransactOpts, recipient common.Address, relayer common.Address, fee *big.Int, signature []byte) (*types.Transaction, error) {
+	return _Mixer.contract.Transact(opts, "withdraw", recipient, relayer, fee, signature)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf37e8d38.
+//
+// Solidity: function withdraw(recipient address, relayer address, fee uint256, signature bytes) returns()
+func (_Mixer *MixerSession) Withdraw(recipient common.Address, relayer common.Address, fee *big.Int, signature []byte) (*types.Transaction, error) {
+	return _Mixer.Contract.Withdraw(&_Mixer.TransactOpts, recipient, relayer, fee, signature)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf37e8d38.
+//
+// Solidity: function withdraw(recipient address, relayer address, fee uint256, signature bytes) returns()
+func (_Mixer *MixerTransactorSession) Withdraw(recipient common.Address, relayer common.Address, fee *big.Int, signature []byte) (*types.Transaction, error) {
+	return _Mixer.Contract.Withdraw(&_Mixer.TransactOpts, recipient, relayer, fee, signature)
+}
+
+// MixerDepositIterator is returned from FilterDeposit and is used to iterate over the raw logs and unpacked data for Deposit events raised by the Mixer contract.
+type MixerDepositIterator struct {
+	Event *MixerDeposit // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *MixerDepositIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(MixerDeposit)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(MixerDeposit)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *MixerDepositIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *MixerDepositIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// MixerDeposit represents a Deposit event raised by the Mixer contract.
+type MixerDeposit struct {
+	X     *big.Int
+	Y     *big.Int
+	Index *big.Int
+	Raw   types.Log // Blockchain specific contextual infos
+}
+
+// FilterDeposit is a free log retrieval operation binding the contract event 0x33da4f9b82b3e18a281ca2cabbe2f076925692abb593b7ea3f850009e8ec9770.
+//
+// Solidity: e Deposit(x uint256, y uint256, index uint256)
+func (_Mixer *MixerFilterer) FilterDeposit(opts *bind.FilterOpts) (*MixerDepositIterator, error) {
+
+	logs, sub, err := _Mixer.contract.FilterLogs(opts, "Deposit")
+	if err != nil {
+		return nil, err
+	}
+	return &MixerDepositIterator{contract: _Mixer.contract, event: "Deposit", logs: logs, sub: sub}, nil
+}
+
+// WatchDeposit is a free log subscription operation binding the contract event 0x33da4f9b82b3e18a281ca2cabbe2f076925692abb593b7ea3f850009e8ec9770.
+//
+// Solidity: e Deposit(x uint256, y uint256, index uint256)
+func (_Mixer *MixerFilterer) WatchDeposit(opts *bind.WatchOpts, sink chan<- *MixerDeposit) (event.Subscription, error) {
+
+	logs, sub, err := _Mixer.contract.WatchLogs(opts, "Deposit")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(MixerDeposit)
+				if err := _Mixer.contract.UnpackLog(event, "Deposit", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// MixerWithdrawalIterator is returned from FilterWithdrawal and is used to iterate over the raw logs and unpacked data for Withdrawal events raised by the Mixer contract.
+type MixerWithdrawalIterator struct {
+	Event *MixerWithdrawal // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *MixerWithdrawalIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(MixerWithdrawal)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(MixerWithdrawal)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *MixerWithdrawalIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *MixerWithdrawalIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// MixerWithdrawal represents a Withdrawal event raised by the Mixer contract.
+type MixerWithdrawal struct {
+	Recipient common.Address
+	Relayer   common.Address
+	Fee       *big.Int
+	KeyImage  [32]byte
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterWithdrawal is a free log retrieval operation binding the contract event 0xcd8d53f936378bc7e6edd9cfca789e41a8960547d43f0d3a3ee11ff3aa4d29c9.
+//
+// Solidity: e Withdrawal(recipient address, relayer address, fee uint256, keyImage bytes32)
+func (_Mixer *MixerFilterer) FilterWithdrawal(opts *bind.FilterOpts) (*MixerWithdrawalIterator, error) {
+
+	logs, sub, err := _Mixer.contract.FilterLogs(opts, "Withdrawal")
+	if err != nil {
+		return nil, err
+	}
+	return &MixerWithdrawalIterator{contract: _Mixer.contract, event: "Withdrawal", logs: logs, sub: sub}, nil
+}
+
+// WatchWithdrawal is a free log subscription operation binding the contract event 0xcd8d53f936378bc7e6edd9cfca789e41a8960547d43f0d3a3ee11ff3aa4d29c9.
+//
+// Solidity: e Withdrawal(recipient address, relayer address, fee uint256, keyImage bytes32)
+func (_Mixer *MixerFilterer) WatchWithdrawal(opts *bind.WatchOpts, sink chan<- *MixerWithdrawal) (event.Subscription, error) {
+
+	logs, sub, err := _Mixer.contract.WatchLogs(opts, "Withdrawal")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(MixerWithdrawal)
+				if err := _Mixer.contract.UnpackLog(event, "Withdrawal", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
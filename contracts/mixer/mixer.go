@@ -0,0 +1,123 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package mixer is a client of fixed denomination mixer contracts, which
+// verify withdrawals with the ring signature verification precompile.
+package mixer
+
+//go:generate abigen --abi contract/Mixer.abi --pkg contract --type Mixer --out contract/mixer.go
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/mixer/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var errWrongMixer = errors.New("withdrawal for another mixer")
+
+// Mixer exposes the deposit and withdrawal operations of a mixer contract.
+type Mixer struct {
+	*contract.MixerSession
+	address common.Address
+}
+
+// NewMixer creates a client of the mixer contract at address.
+func NewMixer(transactOpts *bind.TransactOpts, address common.Address, contractBackend bind.ContractBackend) (*Mixer, error) {
+	mixer, err := contract.NewMixer(address, contractBackend)
+	if err != nil {
+		return nil, err
+	}
+	return &Mixer{
+		&contract.MixerSession{
+			Contract:     mixer,
+			TransactOpts: *transactOpts,
+		},
+		address,
+	}, nil
+}
+
+// Address returns the address of the mixer contract.
+func (m *Mixer) Address() common.Address {
+	return m.address
+}
+
+// NewNote creates a note for a new deposit into the mixer.
+func (m *Mixer) NewNote() (*Note, error) {
+	return GenerateNote(m.address)
+}
+
+// Deposit deposits the note, paying the denomination of the mixer.
+func (m *Mixer) Deposit(note *Note) (*types.Transaction, error) {
+	if note.Mixer != m.address {
+		return nil, errWrongMixer
+	}
+	denomination, err := m.Denomination()
+	if err != nil {
+		return nil, err
+	}
+	opts := m.TransactOpts
+	opts.Value = denomination
+	return m.Contract.Deposit(&opts, note.Key.X, note.Key.Y)
+}
+
+// Deposits returns the deposit set of the mixer in deposit order, the keys
+// withdrawals hide in. Deposited points off the curve can't be ring members
+// and are skipped.
+func (m *Mixer) Deposits(opts *bind.FilterOpts) ([]*ecdsa.PublicKey, error) {
+	it, err := m.Contract.FilterDeposit(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var deposits []*contract.MixerDeposit
+	for it.Next() {
+		if crypto.S256().IsOnCurve(it.Event.X, it.Event.Y) {
+			deposits = append(deposits, it.Event)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(deposits, func(i, j int) bool { return deposits[i].Index.Cmp(deposits[j].Index) < 0 })
+
+	keys := make([]*ecdsa.PublicKey, len(deposits))
+	for i, deposit := range deposits {
+		keys[i] = &ecdsa.PublicKey{Curve: crypto.S256(), X: deposit.X, Y: deposit.Y}
+	}
+	return keys, nil
+}
+
+// Withdrawn reports whether the note has been withdrawn.
+func (m *Mixer) Withdrawn(note *Note) (bool, error) {
+	return m.Spent(note.KeyImageHash())
+}
+
+// Withdraw submits the withdrawal from the account of the transact options.
+// That account pays for gas and is public, use a relayer to withdraw into a
+// fresh account.
+func (m *Mixer) Withdraw(w *Withdrawal) (*types.Transaction, error) {
+	if w.Mixer != m.address {
+		return nil, errWrongMixer
+	}
+	return m.MixerSession.Withdraw(w.Recipient, w.Relayer, w.Fee, w.Signature)
+}
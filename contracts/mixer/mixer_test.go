@@ -0,0 +1,214 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package mixer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var testMixer = common.HexToAddress("0x000000000000000000000000000000000000abcd")
+
+// Tests that notes survive their text encoding and malformed ones are rejected.
+func TestNoteEncoding(t *testing.T) {
+	note, err := GenerateNote(testMixer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseNote(note.String())
+	if err != nil {
+		t.Fatalf("failed to parse note: %v", err)
+	}
+	if parsed.Mixer != note.Mixer || parsed.Key.D.Cmp(note.Key.D) != 0 {
+		t.Fatalf("note mismatch: have %v, want %v", parsed, note)
+	}
+	if parsed.KeyImageHash() != note.KeyImageHash() {
+		t.Fatalf("key image hash mismatch")
+	}
+	for _, s := range []string{
+		"",
+		"ringmixer-" + testMixer.Hex(),
+		"mixer-" + testMixer.Hex() + "-01",
+		"ringmixer-0x1234-01",
+		"ringmixer-" + testMixer.Hex() + "-zz",
+	} {
+		if _, err := ParseNote(s); err == nil {
+			t.Errorf("note %q parsed", s)
+		}
+	}
+}
+
+// testDeposits returns a deposit set of n keys including the note's at index.
+func testDeposits(note *Note, n, index int) []*ecdsa.PublicKey {
+	deposits := make([]*ecdsa.PublicKey, n)
+	for i := range deposits {
+		key, _ := crypto.GenerateKey()
+		deposits[i] = &key.PublicKey
+	}
+	deposits[index] = note.Public()
+	return deposits
+}
+
+// Tests that withdrawals are ring signatures over deposited keys, in deposit
+// order, that the ring verification precompile accepts.
+func TestBuildWithdrawal(t *testing.T) {
+	note, _ := GenerateNote(testMixer)
+	deposits := testDeposits(note, 20, 7)
+
+	var (
+		recipient = common.HexToAddress("0x01")
+		relayer   = common.HexToAddress("0x02")
+		fee       = big.NewInt(1000)
+	)
+	w, err := BuildWithdrawal(note, deposits, 5, recipient, relayer, fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ring.DeserializeSignature(w.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Size != 5 {
+		t.Errorf("ring size mismatch: have %d, want 5", sig.Size)
+	}
+	if common.Hash(sig.M) != WithdrawalHash(testMixer, recipient, relayer, fee) {
+		t.Errorf("signed message mismatch")
+	}
+	position := make(map[string]int)
+	for i, key := range deposits {
+		position[string(crypto.FromECDSAPub(key))] = i
+	}
+	last, found := -1, false
+	for i, member := range sig.Ring {
+		index, ok := position[string(crypto.FromECDSAPub(member))]
+		if !ok {
+			t.Fatalf("ring member %d not deposited", i)
+		}
+		if index <= last {
+			t.Fatalf("ring member %d out of deposit order", i)
+		}
+		last, found = index, found || index == 7
+	}
+	if !found {
+		t.Fatal("note key missing from ring")
+	}
+	image := sig.KeyImage()
+	if crypto.Keccak256Hash(common.LeftPadBytes(image.X.Bytes(), 32), common.LeftPadBytes(image.Y.Bytes(), 32)) != note.KeyImageHash() {
+		t.Errorf("key image hash mismatch")
+	}
+	// The mixer contract passes the signature to the precompile after a word
+	out, err := vm.PrecompiledContractsRing[vm.RingVerifyAddress].Run(append(make([]byte, 32), w.Signature...))
+	if err != nil || !bytes.Equal(out, []byte{1}) {
+		t.Fatalf("precompile rejected withdrawal: %x, %v", out, err)
+	}
+	// The size field read by the contract is the ring size
+	if size := binary.BigEndian.Uint64(w.Signature) & (1<<56 - 1); size != 5 {
+		t.Errorf("encoded ring size mismatch: have %d, want 5", size)
+	}
+	if len(w.Signature) != 72+5*96+64 {
+		t.Errorf("signature length mismatch: have %d, want %d", len(w.Signature), 72+5*96+64)
+	}
+}
+
+// Tests that withdrawals can't be built for undeposited notes or rings that
+// the deposit set can't fill.
+func TestBuildWithdrawalErrors(t *testing.T) {
+	note, _ := GenerateNote(testMixer)
+	deposits := testDeposits(note, 4, 0)
+	fee := new(big.Int)
+
+	if _, err := BuildWithdrawal(note, deposits, 5, common.Address{}, common.Address{}, fee); err == nil {
+		t.Error("ring larger than deposit set built")
+	}
+	if _, err := BuildWithdrawal(note, deposits, 1, common.Address{}, common.Address{}, fee); err == nil {
+		t.Error("ring below minimum size built")
+	}
+	if _, err := BuildWithdrawal(note, deposits, 4, common.Address{}, common.Address{}, big.NewInt(-1)); err == nil {
+		t.Error("withdrawal with negative fee built")
+	}
+	other, _ := GenerateNote(testMixer)
+	if _, err := BuildWithdrawal(other, deposits, 4, common.Address{}, common.Address{}, fee); err != errNotDeposited {
+		t.Errorf("undeposited note error mismatch: have %v, want %v", err, errNotDeposited)
+	}
+	if _, err := BuildWithdrawal(note, deposits, 4, common.Address{}, common.Address{}, fee); err != nil {
+		t.Errorf("failed to build withdrawal over all deposits: %v", err)
+	}
+}
+
+// MockRelayer is a relayer service recording the withdrawals it is asked to
+// relay.
+type MockRelayer struct {
+	address common.Address
+	relayed []*Withdrawal
+}
+
+func (r *MockRelayer) Info(mixer common.Address) *RelayerInfo {
+	return &RelayerInfo{Address: r.address, Fee: big.NewInt(500)}
+}
+
+func (r *MockRelayer) Relay(w *Withdrawal) common.Hash {
+	r.relayed = append(r.relayed, w)
+	return common.HexToHash("0x1234")
+}
+
+// Tests that withdrawals built on the terms of a relayer reach it intact.
+func TestRelayer(t *testing.T) {
+	service := &MockRelayer{address: common.HexToAddress("0x02")}
+	server := rpc.NewServer()
+	if err := server.RegisterName("relayer", service); err != nil {
+		t.Fatal(err)
+	}
+	relayer := NewRelayer(rpc.DialInProc(server))
+	defer relayer.Close()
+
+	info, err := relayer.Info(context.Background(), testMixer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Address != service.address || info.Fee.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("relayer info mismatch: have %v/%v, want %v/500", info.Address, info.Fee, service.address)
+	}
+	note, _ := GenerateNote(testMixer)
+	w, err := BuildWithdrawal(note, testDeposits(note, 3, 1), 3, common.HexToAddress("0x01"), info.Address, info.Fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := relayer.Relay(context.Background(), w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != common.HexToHash("0x1234") {
+		t.Errorf("transaction hash mismatch: have %x", hash)
+	}
+	if len(service.relayed) != 1 {
+		t.Fatalf("relayed withdrawal count mismatch: have %d, want 1", len(service.relayed))
+	}
+	got := service.relayed[0]
+	if got.Mixer != w.Mixer || got.Recipient != w.Recipient || got.Relayer != w.Relayer || got.Fee.Cmp(w.Fee) != 0 || !bytes.Equal(got.Signature, w.Signature) {
+		t.Fatalf("relayed withdrawal mismatch: have %+v, want %+v", got, w)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package mixer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// notePrefix starts the text encoding of deposit notes.
+const notePrefix = "ringmixer"
+
+var errInvalidNote = errors.New("invalid deposit note")
+
+// Note is the secret of a deposit: a fresh key whose public half is deposited
+// into a mixer and whose ring signature later withdraws the deposit. Anyone
+// holding the note can withdraw it, so it must be kept as safe as a key.
+type Note struct {
+	Mixer common.Address    // mixer contract the note is deposited into
+	Key   *ecdsa.PrivateKey // deposit key
+}
+
+// GenerateNote creates a note with a fresh deposit key for the mixer.
+func GenerateNote(mixer common.Address) (*Note, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Note{Mixer: mixer, Key: key}, nil
+}
+
+// ParseNote decodes a note from its text encoding, see Note.String.
+func ParseNote(s string) (*Note, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 || parts[0] != notePrefix || !common.IsHexAddress(parts[1]) {
+		return nil, errInvalidNote
+	}
+	key, err := crypto.HexToECDSA(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid deposit note key: %v", err)
+	}
+	return &Note{Mixer: common.HexToAddress(parts[1]), Key: key}, nil
+}
+
+// String returns the text encoding of the note, containing the mixer address
+// and the deposit key.
+func (n *Note) String() string {
+	return fmt.Sprintf("%s-%s-%x", notePrefix, n.Mixer.Hex(), math.PaddedBigBytes(n.Key.D, 32))
+}
+
+// Public returns the deposited public key of the note.
+func (n *Note) Public() *ecdsa.PublicKey {
+	return &n.Key.PublicKey
+}
+
+// KeyImageHash returns the identifier under which the mixer marks the note as
+// withdrawn: the hash of the coordinates of its key image.
+func (n *Note) KeyImageHash() common.Hash {
+	image := ring.GenKeyImage(n.Key)
+	return crypto.Keccak256Hash(math.PaddedBigBytes(image.X, 32), math.PaddedBigBytes(image.Y, 32))
+}
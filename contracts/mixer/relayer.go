@@ -0,0 +1,109 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package mixer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// A relayer submits withdrawals from its own account for a fee, so that they
+// can be paid into fresh accounts with no funds for gas. Relayers serve the
+// "relayer" RPC namespace:
+//
+//   relayer_info(mixer)       returns the relayer's account and fee for a mixer
+//   relayer_relay(withdrawal) submits a withdrawal, returning its transaction hash
+
+var errMissingFee = errors.New("missing fee")
+
+// RelayerInfo holds the terms of a relayer for a mixer.
+type RelayerInfo struct {
+	Address common.Address // account the fee must be paid to
+	Fee     *big.Int       // fee charged per withdrawal
+}
+
+// relayerInfoJSON is the RPC format of RelayerInfo.
+type relayerInfoJSON struct {
+	Address common.Address `json:"address"`
+	Fee     *hexutil.Big   `json:"fee"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (info *RelayerInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&relayerInfoJSON{Address: info.Address, Fee: (*hexutil.Big)(info.Fee)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (info *RelayerInfo) UnmarshalJSON(input []byte) error {
+	var dec relayerInfoJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.Fee == nil {
+		return errMissingFee
+	}
+	*info = RelayerInfo{Address: dec.Address, Fee: (*big.Int)(dec.Fee)}
+	return nil
+}
+
+// Relayer is a client of a withdrawal relayer.
+type Relayer struct {
+	c *rpc.Client
+}
+
+// DialRelayer connects to the relayer at the given URL.
+func DialRelayer(rawurl string) (*Relayer, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewRelayer(c), nil
+}
+
+// NewRelayer creates a relayer client that uses the given RPC client.
+func NewRelayer(c *rpc.Client) *Relayer {
+	return &Relayer{c}
+}
+
+// Close closes the connection to the relayer.
+func (r *Relayer) Close() {
+	r.c.Close()
+}
+
+// Info retrieves the account and fee of the relayer for withdrawals from the
+// mixer, to be signed into the withdrawal.
+func (r *Relayer) Info(ctx context.Context, mixer common.Address) (*RelayerInfo, error) {
+	info := new(RelayerInfo)
+	if err := r.c.CallContext(ctx, info, "relayer_info", mixer); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Relay asks the relayer to submit the withdrawal and returns the hash of its
+// transaction.
+func (r *Relayer) Relay(ctx context.Context, w *Withdrawal) (common.Hash, error) {
+	var hash common.Hash
+	err := r.c.CallContext(ctx, &hash, "relayer_relay", w)
+	return hash, err
+}
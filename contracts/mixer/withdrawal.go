@@ -0,0 +1,163 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package mixer
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+const (
+	MinRingSize     = 2  // Smallest ring a withdrawal can hide in
+	DefaultRingSize = 11 // Ring size recommended for withdrawals
+)
+
+var errNotDeposited = errors.New("note key not among deposits")
+
+// Withdrawal is a withdrawal of a deposit, ready to be submitted to the mixer
+// directly or through a relayer. Its signature covers the recipient, relayer
+// and fee, so a relayer can't redirect the withdrawal or raise its fee.
+type Withdrawal struct {
+	Mixer     common.Address // mixer contract to withdraw from
+	Recipient common.Address // account receiving the denomination minus the fee
+	Relayer   common.Address // account receiving the fee
+	Fee       *big.Int       // fee paid to the relayer
+	Signature []byte         // binary ring signature over the withdrawal hash
+}
+
+// withdrawalJSON is the relayer RPC format of withdrawals.
+type withdrawalJSON struct {
+	Mixer     common.Address `json:"mixer"`
+	Recipient common.Address `json:"recipient"`
+	Relayer   common.Address `json:"relayer"`
+	Fee       *hexutil.Big   `json:"fee"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (w *Withdrawal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&withdrawalJSON{
+		Mixer:     w.Mixer,
+		Recipient: w.Recipient,
+		Relayer:   w.Relayer,
+		Fee:       (*hexutil.Big)(w.Fee),
+		Signature: w.Signature,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (w *Withdrawal) UnmarshalJSON(input []byte) error {
+	var dec withdrawalJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.Fee == nil {
+		return errMissingFee
+	}
+	*w = Withdrawal{
+		Mixer:     dec.Mixer,
+		Recipient: dec.Recipient,
+		Relayer:   dec.Relayer,
+		Fee:       (*big.Int)(dec.Fee),
+		Signature: dec.Signature,
+	}
+	return nil
+}
+
+// WithdrawalHash returns the message signed by withdrawals from mixer, the
+// hash of the packed mixer, recipient and relayer addresses and fee.
+func WithdrawalHash(mixer, recipient, relayer common.Address, fee *big.Int) common.Hash {
+	return crypto.Keccak256Hash(mixer.Bytes(), recipient.Bytes(), relayer.Bytes(), math.PaddedBigBytes(fee, 32))
+}
+
+// BuildWithdrawal signs a withdrawal of the note to the recipient, paying fee
+// to the relayer. The deposit key of the note hides in a ring of ringSize
+// deposits, the others picked at random from the deposit set of the mixer,
+// and ordered as they were deposited so that the position of the note's key
+// tells nothing.
+func BuildWithdrawal(note *Note, deposits []*ecdsa.PublicKey, ringSize int, recipient, relayer common.Address, fee *big.Int) (*Withdrawal, error) {
+	if ringSize < MinRingSize {
+		return nil, fmt.Errorf("ring size %d below minimum %d", ringSize, MinRingSize)
+	}
+	if ringSize > len(deposits) {
+		return nil, fmt.Errorf("ring size %d above deposit count %d", ringSize, len(deposits))
+	}
+	if fee == nil || fee.Sign() < 0 || fee.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid fee %v", fee)
+	}
+	own := -1
+	for i, key := range deposits {
+		if key.X.Cmp(note.Key.X) == 0 && key.Y.Cmp(note.Key.Y) == 0 {
+			own = i
+			break
+		}
+	}
+	if own < 0 {
+		return nil, errNotDeposited
+	}
+	// Pick the decoys with a partial Fisher-Yates shuffle of the other deposits
+	candidates := make([]int, 0, len(deposits)-1)
+	for i := range deposits {
+		if i != own {
+			candidates = append(candidates, i)
+		}
+	}
+	for i := 0; i < ringSize-1; i++ {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidates)-i)))
+		if err != nil {
+			return nil, err
+		}
+		k := i + int(j.Int64())
+		candidates[i], candidates[k] = candidates[k], candidates[i]
+	}
+	indices := append(candidates[:ringSize-1], own)
+	sort.Ints(indices)
+
+	var (
+		members = make(ring.Ring, ringSize)
+		signer  int
+	)
+	for i, index := range indices {
+		members[i] = deposits[index]
+		if index == own {
+			signer = i
+		}
+	}
+	hash := WithdrawalHash(note.Mixer, recipient, relayer, fee)
+	sig, err := ring.Sign(hash, members, note.Key, signer)
+	if err != nil {
+		return nil, err
+	}
+	return &Withdrawal{
+		Mixer:     note.Mixer,
+		Recipient: recipient,
+		Relayer:   relayer,
+		Fee:       new(big.Int).Set(fee),
+		Signature: sig.SerializeSignature(),
+	}, nil
+}
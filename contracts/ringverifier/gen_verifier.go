@@ -0,0 +1,46 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build none
+// +build none
+
+// This program generates contract/RingVerifier.sol and contract/RingVerifier.abi,
+// the source and interface of the ring verifier contract.
+package main
+
+import (
+	"io/ioutil"
+	"log"
+
+	"github.com/ethereum/go-ethereum/contracts/ringverifier"
+)
+
+func main() {
+	src, err := ringverifier.Source()
+	if err != nil {
+		log.Fatal(err)
+	}
+	abi, err := ringverifier.ABI()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("contract/RingVerifier.sol", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("contract/RingVerifier.abi", abi, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ringverifier is the canonical on-chain verifier of linkable ring
+// signatures. The Solidity source of the verifier is generated from the
+// signature encoding of the ring package and the Go bindings are generated
+// from ring.RingVerifierABI, so both stay in step with the Go implementation.
+package ringverifier
+
+//go:generate go run gen_verifier.go
+//go:generate abigen --abi contract/RingVerifier.abi --pkg contract --type RingVerifier --out contract/ringverifier.go
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/ringverifier/contract"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// Verifier exposes the operations of a ring verifier contract.
+type Verifier struct {
+	*contract.RingVerifierSession
+	address common.Address
+}
+
+// NewVerifier creates a client of the verifier contract at address.
+func NewVerifier(transactOpts *bind.TransactOpts, address common.Address, contractBackend bind.ContractBackend) (*Verifier, error) {
+	verifier, err := contract.NewRingVerifier(address, contractBackend)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{
+		&contract.RingVerifierSession{
+			Contract:     verifier,
+			TransactOpts: *transactOpts,
+		},
+		address,
+	}, nil
+}
+
+// Address returns the address of the verifier contract.
+func (v *Verifier) Address() common.Address {
+	return v.address
+}
+
+// Verify verifies sig with the contract, passing the ring along.
+func (v *Verifier) Verify(sig *ring.RingSign) (bool, error) {
+	if err := checkSignature(sig); err != nil {
+		return false, err
+	}
+	return v.RingVerifierSession.Verify(sig.M, toMembers(sig.Ring), sig.C, sig.S, toPoint(sig.I))
+}
+
+// VerifyWithRing verifies sig with the contract against the registered ring
+// of the signature.
+func (v *Verifier) VerifyWithRing(sig *ring.RingSign) (bool, error) {
+	if err := checkSignature(sig); err != nil {
+		return false, err
+	}
+	id, err := ring.RingID(sig.Ring)
+	if err != nil {
+		return false, err
+	}
+	return v.RingVerifierSession.VerifyWithRing(id, sig.M, sig.C, sig.S, toPoint(sig.I))
+}
+
+// RegisterRing registers r with the contract.
+func (v *Verifier) RegisterRing(r ring.Ring) (*types.Transaction, error) {
+	if _, err := ring.RingID(r); err != nil {
+		return nil, err
+	}
+	return v.RingVerifierSession.RegisterRing(toMembers(r))
+}
+
+// checkSignature rejects signatures the contract can't verify. Like
+// ring.PackVerify, it only accepts secp256k1 signatures with the original
+// transcript.
+func checkSignature(sig *ring.RingSign) error {
+	// PackVerify performs exactly the checks needed, the calldata is unused.
+	_, err := ring.PackVerify(sig)
+	return err
+}
+
+// toPoint converts p into its uint256[2] representation.
+func toPoint(p *ecdsa.PublicKey) [2]*big.Int {
+	return [2]*big.Int{p.X, p.Y}
+}
+
+// toMembers converts r into its uint256[2][] representation.
+func toMembers(r ring.Ring) [][2]*big.Int {
+	points := make([][2]*big.Int, len(r))
+	for i, pub := range r {
+		points[i] = toPoint(pub)
+	}
+	return points
+}
@@ -0,0 +1,103 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringverifier
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// Tests that the committed contract files are the output of the generator,
+// so that they follow changes to the signature encoding.
+func TestGeneratedContract(t *testing.T) {
+	src, err := Source()
+	if err != nil {
+		t.Fatal(err)
+	}
+	abi, err := ABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string][]byte{"contract/RingVerifier.sol": src, "contract/RingVerifier.abi": abi} {
+		have, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(have, want) {
+			t.Errorf("%s is out of date, run go generate", file)
+		}
+	}
+}
+
+// Tests that the committed bindings are the ones abigen generates from the
+// verifier interface.
+func TestGeneratedBindings(t *testing.T) {
+	abi, err := ABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := bind.Bind([]string{"RingVerifier"}, []string{string(abi)}, []string{""}, "contract", bind.LangGo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := ioutil.ReadFile("contract/ringverifier.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(have) != want {
+		t.Error("contract/ringverifier.go is out of date, run go generate")
+	}
+}
+
+// Tests that the precompile input built by the contract is the one of the Go
+// implementation and that the precompile accepts it.
+func TestPackInput(t *testing.T) {
+	for _, size := range []int{2, 3, 8} {
+		var (
+			keys    = make([]*ecdsa.PrivateKey, size)
+			members = make(ring.Ring, size)
+		)
+		for i := range keys {
+			keys[i], _ = crypto.GenerateKey()
+			members[i] = &keys[i].PublicKey
+		}
+		msg := crypto.Keccak256Hash([]byte{byte(size)})
+		sig, err := ring.SignWithOpts(msg, members, keys[size-1], size-1, &ring.SignOpts{Version: ring.TranscriptV1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ring.PrecompileInput(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		input := packInput(sig.M, toMembers(sig.Ring), sig.C, sig.S, toPoint(sig.I))
+		if !bytes.Equal(input, want) {
+			t.Fatalf("size %d: input mismatch:\nhave %x\nwant %x", size, input, want)
+		}
+		out, err := vm.PrecompiledContractsByzantium[vm.RingVerifyAddress].Run(input)
+		if err != nil || !bytes.Equal(out, []byte{1}) {
+			t.Errorf("size %d: precompile rejected input: %x, %v", size, out, err)
+		}
+	}
+}
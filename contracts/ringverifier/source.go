@@ -0,0 +1,194 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringverifier
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// The verifier contract does not verify signatures itself. Challenges are
+// SHA3-256 hashes, which the EVM has no instruction for, so the contract
+// rebuilds the binary signature encoding from its arguments and passes it to
+// the ring verification precompile, which runs ring.Verify. The layout below
+// is the one of ring.PrecompileInput; the contract source is generated from it
+// and packInput mirrors the generated code, so the tests catch any drift
+// between the two sides.
+const (
+	precompile = 0x09 // address of the ring verification precompile
+
+	prefixSize = 32 // word ignored by the precompile
+	sizeSize   = 8  // transcript version and ring size
+	headerSize = sizeSize + 32 + 32
+	memberSize = 3 * 32 // response, then the coordinates of the member
+	imageSize  = 2 * 32
+
+	versionShift = 56 // position of the version in the size field
+
+	// transcriptVersion is the version encoded in the size field. The
+	// contract interface has no version and implements the original
+	// transcript, whose encoded version is zero.
+	transcriptVersion = 0
+)
+
+// packInput returns the precompile input the verifier contract builds for
+// the arguments of verify.
+func packInput(message [32]byte, members [][2]*big.Int, c *big.Int, s []*big.Int, image [2]*big.Int) []byte {
+	n := len(members)
+	input := make([]byte, prefixSize+headerSize+n*memberSize+imageSize)
+
+	p := input[prefixSize:]
+	binary.BigEndian.PutUint64(p, uint64(transcriptVersion)<<versionShift|uint64(n))
+	copy(p[sizeSize:], message[:])
+	math.ReadBits(c, p[sizeSize+32:headerSize])
+
+	p = p[headerSize:]
+	for i := 0; i < n; i++ {
+		math.ReadBits(s[i], p[:32])
+		math.ReadBits(members[i][0], p[32:64])
+		math.ReadBits(members[i][1], p[64:96])
+		p = p[memberSize:]
+	}
+	math.ReadBits(image[0], p[:32])
+	math.ReadBits(image[1], p[32:64])
+	return input
+}
+
+// ABI returns the contents of the ABI file of the verifier contract,
+// ring.RingVerifierABI in compact form.
+func ABI() ([]byte, error) {
+	var abi bytes.Buffer
+	if err := json.Compact(&abi, []byte(ring.RingVerifierABI)); err != nil {
+		return nil, err
+	}
+	return abi.Bytes(), nil
+}
+
+// Source returns the Solidity source of the verifier contract.
+func Source() ([]byte, error) {
+	var src bytes.Buffer
+	err := sourceTemplate.Execute(&src, map[string]interface{}{
+		"Precompile":   fmt.Sprintf("0x%02x", precompile),
+		"Prefix":       prefixSize,
+		"Size":         sizeSize,
+		"Header":       headerSize,
+		"Member":       memberSize,
+		"Image":        imageSize,
+		"Version":      transcriptVersion,
+		"VersionShift": versionShift,
+		"SizeShift":    8 * (32 - sizeSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return src.Bytes(), nil
+}
+
+var sourceTemplate = template.Must(template.New("").Parse(strings.TrimLeft(`
+// Code generated by gen_verifier.go. DO NOT EDIT.
+
+pragma solidity ^0.4.24;
+
+// RingVerifier verifies linkable ring signatures over secp256k1 with the
+// original transcript. It encodes its arguments in the binary signature format
+// and calls the ring verification precompile.
+contract RingVerifier {
+    uint256 constant PREFIX_SIZE = {{.Prefix}};
+    uint256 constant SIZE_SIZE = {{.Size}};
+    uint256 constant HEADER_SIZE = {{.Header}};
+    uint256 constant MEMBER_SIZE = {{.Member}};
+    uint256 constant IMAGE_SIZE = {{.Image}};
+    uint256 constant VERSION = {{.Version}};
+
+    mapping(bytes32 => uint256[2][]) rings;
+
+    // verify reports whether the signature over message by a member of ring
+    // is valid.
+    function verify(bytes32 message, uint256[2][] ring, uint256 c, uint256[] s, uint256[2] keyImage) public view returns (bool) {
+        return check(message, ring, c, s, keyImage);
+    }
+
+    // verifyWithRing verifies a signature by a member of a registered ring.
+    function verifyWithRing(bytes32 ringId, bytes32 message, uint256 c, uint256[] s, uint256[2] keyImage) public view returns (bool) {
+        uint256[2][] memory ring = rings[ringId];
+        if (ring.length == 0) {
+            return false;
+        }
+        return check(message, ring, c, s, keyImage);
+    }
+
+    // registerRing stores ring under its identifier, the hash of its ABI
+    // encoding.
+    function registerRing(uint256[2][] ring) public returns (bytes32 ringId) {
+        ringId = keccak256(abi.encode(ring));
+        if (rings[ringId].length == 0) {
+            for (uint256 i = 0; i < ring.length; i++) {
+                rings[ringId].push(ring[i]);
+            }
+        }
+    }
+
+    // check builds the precompile input, a word the precompile ignores
+    // followed by the signature, and calls the precompile.
+    function check(bytes32 message, uint256[2][] ring, uint256 c, uint256[] s, uint256[2] keyImage) internal view returns (bool) {
+        uint256 n = ring.length;
+        if (n != s.length) {
+            return false;
+        }
+        bytes memory input = new bytes(PREFIX_SIZE + HEADER_SIZE + n * MEMBER_SIZE + IMAGE_SIZE);
+        uint256 p;
+        assembly {
+            p := input
+        }
+        p += 32 + PREFIX_SIZE;
+        store(p, (VERSION * 2**{{.VersionShift}} | n) * 2**{{.SizeShift}});
+        store(p + SIZE_SIZE, uint256(message));
+        store(p + SIZE_SIZE + 32, c);
+        p += HEADER_SIZE;
+        for (uint256 i = 0; i < n; i++) {
+            store(p, s[i]);
+            store(p + 32, ring[i][0]);
+            store(p + 64, ring[i][1]);
+            p += MEMBER_SIZE;
+        }
+        store(p, keyImage[0]);
+        store(p + 32, keyImage[1]);
+
+        bytes memory output = new bytes(1);
+        bool ok;
+        assembly {
+            ok := staticcall(gas, {{.Precompile}}, add(input, 32), mload(input), add(output, 32), 1)
+        }
+        return ok && output[0] == 1;
+    }
+
+    // store writes the word w to memory at p.
+    function store(uint256 p, uint256 w) internal pure {
+        assembly {
+            mstore(p, w)
+        }
+    }
+}
+`, "\n")))
@@ -0,0 +1,107 @@
+pragma solidity ^0.4.24;
+
+// RingBallot decodes the binary ring signature format of the ring verification
+// precompile at 0x09: an 8 byte version and size, the message, the challenge,
+// then a response and the coordinates of every member, then the key image.
+library RingBallot {
+    uint256 constant MESSAGE = 8;
+    uint256 constant MEMBERS = 72;
+    uint256 constant MEMBER_SIZE = 96;
+
+    // size returns the number of ring members of the signature, checking that
+    // its length matches.
+    function size(bytes signature) internal pure returns (uint256 n) {
+        n = word(signature, 0) / 2**192 & (2**56 - 1);
+        require(signature.length == MEMBERS + n * MEMBER_SIZE + 64);
+    }
+
+    // message returns the hash signed by the signature.
+    function message(bytes signature) internal pure returns (bytes32) {
+        return bytes32(word(signature, MESSAGE));
+    }
+
+    // ringId returns the identifier of the ring of the signature, the hash of
+    // its ABI encoding as uint256[2][].
+    function ringId(bytes signature) internal pure returns (bytes32) {
+        uint256 n = size(signature);
+        uint256[2][] memory members = new uint256[2][](n);
+        for (uint256 i = 0; i < n; i++) {
+            uint256 member = MEMBERS + i * MEMBER_SIZE;
+            members[i] = [word(signature, member + 32), word(signature, member + 64)];
+        }
+        return keccak256(abi.encode(members));
+    }
+
+    // tag returns the tag of the signature for the proposal, the hash of the
+    // proposal and the key image. It is the same for every signature by a
+    // member on the proposal.
+    function tag(bytes32 proposal, bytes signature) internal pure returns (bytes32) {
+        uint256 image = MEMBERS + size(signature) * MEMBER_SIZE;
+        return keccak256(abi.encodePacked(proposal, word(signature, image), word(signature, image + 32)));
+    }
+
+    // verify calls the ring verification precompile, whose input is a word
+    // it ignores followed by the signature.
+    function verify(bytes signature) internal view returns (bool) {
+        bytes memory input = abi.encodePacked(bytes32(0), signature);
+        bytes memory output = new bytes(1);
+        bool ok;
+        assembly {
+            ok := staticcall(gas, 0x09, add(input, 32), mload(input), add(output, 32), 1)
+        }
+        return ok && output[0] == 1;
+    }
+
+    // word returns the 32 bytes of b at offset.
+    function word(bytes b, uint256 offset) internal pure returns (uint256 w) {
+        assembly {
+            w := mload(add(add(b, 32), offset))
+        }
+    }
+}
+
+// Voting is a ballot box for anonymous votes on proposals. The owner registers
+// the rings of the electorate of a proposal, members vote with a ring
+// signature over their choice and the tag of the signature allows one vote per
+// member and proposal.
+contract Voting {
+    using RingBallot for bytes;
+
+    address public owner;
+    mapping(bytes32 => mapping(bytes32 => bool)) public rings;
+    mapping(bytes32 => mapping(bytes32 => bool)) public voted;
+    mapping(bytes32 => mapping(uint8 => uint256)) public votes;
+
+    event Vote(bytes32 indexed proposal, uint8 choice, bytes32 tag);
+
+    constructor() public {
+        owner = msg.sender;
+    }
+
+    // addRings registers the rings of members allowed to vote on the proposal.
+    function addRings(bytes32 proposal, bytes32[] ringIds) public {
+        require(msg.sender == owner);
+        for (uint256 i = 0; i < ringIds.length; i++) {
+            rings[proposal][ringIds[i]] = true;
+        }
+    }
+
+    // ballotHash is the message signed by votes.
+    function ballotHash(bytes32 proposal, uint8 choice) public view returns (bytes32) {
+        return keccak256(abi.encodePacked(address(this), proposal, choice));
+    }
+
+    // vote counts the choice of a member of a registered ring.
+    function vote(bytes32 proposal, uint8 choice, bytes signature) public {
+        require(signature.message() == ballotHash(proposal, choice));
+        require(rings[proposal][signature.ringId()]);
+
+        bytes32 tag = RingBallot.tag(proposal, signature);
+        require(!voted[proposal][tag]);
+        require(signature.verify());
+
+        voted[proposal][tag] = true;
+        votes[proposal][choice]++;
+        emit Vote(proposal, choice, tag);
+    }
+}
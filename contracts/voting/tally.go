@@ -0,0 +1,101 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package voting
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// Tally is the outcome of counting the ballots of a proposal.
+type Tally struct {
+	Box      common.Address       // ballot box the proposal was voted with
+	Proposal common.Hash          // proposal the ballots were cast on
+	Votes    []uint64             // number of votes for each choice
+	Tags     map[common.Hash]bool // tags of the counted votes
+	Rejected map[int]error        // reasons for not counting ballots, by count order
+}
+
+// NewTally creates an empty tally of the votes on the proposal, for choices
+// from zero to one less than choices.
+func NewTally(box common.Address, proposal common.Hash, choices int) *Tally {
+	return &Tally{
+		Box:      box,
+		Proposal: proposal,
+		Votes:    make([]uint64, choices),
+		Tags:     make(map[common.Hash]bool),
+		Rejected: make(map[int]error),
+	}
+}
+
+// Count verifies the ballots independently of the ballot box contract and
+// adds the valid ones to the tally. The rings are the electorate registered
+// for the proposal. Like the contract, only the first vote of a member is
+// counted, later ones are rejected.
+func (t *Tally) Count(rings []ring.Ring, ballots []*Ballot) {
+	ids := make(map[common.Hash]bool)
+	for _, r := range rings {
+		if id, err := ring.RingID(r); err == nil {
+			ids[id] = true
+		}
+	}
+	offset := len(t.Tags) + len(t.Rejected)
+	for i, ballot := range ballots {
+		if err := t.verify(ids, ballot); err != nil {
+			t.Rejected[offset+i] = err
+			continue
+		}
+		t.Tags[ballot.Tag] = true
+		t.Votes[ballot.Choice]++
+	}
+}
+
+// verify checks that the ballot is a valid first vote on the proposal by a
+// member of one of the rings with the given identifiers.
+func (t *Tally) verify(ids map[common.Hash]bool, ballot *Ballot) error {
+	switch {
+	case ballot.Box != t.Box:
+		return errWrongBox
+	case ballot.Proposal != t.Proposal:
+		return errWrongVote
+	case int(ballot.Choice) >= len(t.Votes):
+		return errBadChoice
+	}
+	sig, err := ring.DecodeStrict(ring.EncodingBinary, ballot.Signature)
+	if err != nil {
+		return err
+	}
+	if sig.M != BallotHash(t.Box, t.Proposal, ballot.Choice) {
+		return errBadMessage
+	}
+	// Recompute the ring and tag, the ones in the ballot are only informative
+	id, err := ring.RingID(sig.Ring)
+	if err != nil {
+		return err
+	}
+	if !ids[id] {
+		return errNoRing
+	}
+	tag := Tag(t.Proposal, sig.I)
+	if t.Tags[tag] {
+		return errDuplicate
+	}
+	if !ring.Verify(sig) {
+		return errBadBallot
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package voting builds and tallies anonymous votes on proposals, letting the
+// members of an electorate vote without revealing which member they are.
+//
+// The electorate of a proposal is partitioned into rings whose identifiers,
+// see ring.RingID, are registered with the ballot box contract in
+// contract/Voting.sol. A member votes by ring signing its choice with its key;
+// the contract verifies the signature with the ring verification precompile,
+// checks that its ring is registered for the proposal and allows one vote per
+// tag, the hash of the proposal and the key image of the signature.
+//
+// The key image of a linkable ring signature is the same for every signature
+// by a key, so while votes don't reveal the member who cast them, the votes
+// of a member on different proposals can be linked to each other. Votes
+// should be sent from fresh accounts, as funding them from the member's
+// address would link the two.
+package voting
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// VotingABI is the interface of a ballot box contract. The signature passed to
+// vote is in the binary format of the ring verification precompile.
+const VotingABI = `[
+	{"type":"function","name":"addRings","constant":false,
+	 "inputs":[{"name":"proposal","type":"bytes32"},{"name":"ringIds","type":"bytes32[]"}],"outputs":[]},
+	{"type":"function","name":"vote","constant":false,
+	 "inputs":[{"name":"proposal","type":"bytes32"},{"name":"choice","type":"uint8"},{"name":"signature","type":"bytes"}],"outputs":[]},
+	{"type":"function","name":"voted","stateMutability":"view","constant":true,
+	 "inputs":[{"name":"proposal","type":"bytes32"},{"name":"tag","type":"bytes32"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"votes","stateMutability":"view","constant":true,
+	 "inputs":[{"name":"proposal","type":"bytes32"},{"name":"choice","type":"uint8"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"event","name":"Vote","anonymous":false,
+	 "inputs":[{"name":"proposal","type":"bytes32","indexed":true},{"name":"choice","type":"uint8","indexed":false},{"name":"tag","type":"bytes32","indexed":false}]}
+]`
+
+var (
+	errNotVoter   = errors.New("key not in any ring")
+	errNotBallot  = errors.New("calldata is not a vote")
+	errWrongBox   = errors.New("ballot for another ballot box")
+	errWrongVote  = errors.New("ballot for another proposal")
+	errBadBallot  = errors.New("invalid ballot signature")
+	errBadChoice  = errors.New("choice out of range")
+	errBadMessage = errors.New("signature not over the ballot")
+	errNoRing     = errors.New("ring not registered for the proposal")
+	errDuplicate  = errors.New("member already voted")
+)
+
+// votingABI is the parsed VotingABI.
+var votingABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(VotingABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// PackAddRings returns the calldata registering the rings as the electorate of
+// the proposal with the ballot box contract.
+func PackAddRings(proposal common.Hash, rings []ring.Ring) ([]byte, error) {
+	ids := make([][32]byte, len(rings))
+	for i, r := range rings {
+		id, err := ring.RingID(r)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return votingABI.Pack("addRings", proposal, ids)
+}
+
+// BallotHash returns the message signed by votes for the choice on the
+// proposal, the hash of the packed ballot box address, proposal and choice.
+func BallotHash(box common.Address, proposal common.Hash, choice uint8) common.Hash {
+	return crypto.Keccak256Hash(box.Bytes(), proposal.Bytes(), []byte{choice})
+}
+
+// Tag returns the tag of votes on the proposal by the member with the key
+// image, the hash of the proposal and the key image.
+func Tag(proposal common.Hash, image *ecdsa.PublicKey) common.Hash {
+	return crypto.Keccak256Hash(proposal.Bytes(), math.PaddedBigBytes(image.X, 32), math.PaddedBigBytes(image.Y, 32))
+}
+
+// Ballot is a vote on a proposal by an unknown member of its electorate.
+type Ballot struct {
+	Box       common.Address // ballot box contract to vote with
+	Proposal  common.Hash    // proposal voted on
+	Choice    uint8          // choice voted for
+	RingID    common.Hash    // identifier of the ring the member hides in
+	Tag       common.Hash    // tag of the member's votes on the proposal
+	Signature []byte         // binary ring signature over the ballot hash
+}
+
+// BuildBallot signs a vote for the choice on the proposal, with the key of a
+// member in one of the rings.
+func BuildBallot(box common.Address, proposal common.Hash, choice uint8, rings []ring.Ring, key *ecdsa.PrivateKey) (*Ballot, error) {
+	for _, members := range rings {
+		i := members.Index(&key.PublicKey)
+		if i < 0 {
+			continue
+		}
+		sig, err := ring.Sign(BallotHash(box, proposal, choice), members, key, i)
+		if err != nil {
+			return nil, err
+		}
+		id, err := ring.RingID(members)
+		if err != nil {
+			return nil, err
+		}
+		return &Ballot{
+			Box:       box,
+			Proposal:  proposal,
+			Choice:    choice,
+			RingID:    id,
+			Tag:       Tag(proposal, sig.I),
+			Signature: sig.SerializeSignature(),
+		}, nil
+	}
+	return nil, errNotVoter
+}
+
+// ParseBallot decodes the calldata of a vote sent to the ballot box, such as
+// the input of a transaction, into a ballot. The signature isn't verified, see
+// Tally for that.
+func ParseBallot(box common.Address, calldata []byte) (*Ballot, error) {
+	method := votingABI.Methods["vote"]
+	if len(calldata) < 4 || !bytes.Equal(calldata[:4], method.Id()) {
+		return nil, errNotBallot
+	}
+	var args struct {
+		Proposal  [32]byte
+		Choice    uint8
+		Signature []byte
+	}
+	if err := method.Inputs.Unpack(&args, calldata[4:]); err != nil {
+		return nil, err
+	}
+	sig, err := ring.DeserializeSignature(args.Signature)
+	if err != nil {
+		return nil, err
+	}
+	id, err := ring.RingID(sig.Ring)
+	if err != nil {
+		return nil, err
+	}
+	return &Ballot{
+		Box:       box,
+		Proposal:  args.Proposal,
+		Choice:    args.Choice,
+		RingID:    id,
+		Tag:       Tag(args.Proposal, sig.I),
+		Signature: args.Signature,
+	}, nil
+}
+
+// Calldata returns the calldata submitting the vote to the ballot box.
+func (b *Ballot) Calldata() ([]byte, error) {
+	return votingABI.Pack("vote", b.Proposal, b.Choice, b.Signature)
+}
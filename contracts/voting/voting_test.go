@@ -0,0 +1,148 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package voting
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+var (
+	testBox      = common.HexToAddress("0x000000000000000000000000000000000000cccc")
+	testProposal = common.HexToHash("0x01")
+)
+
+// testElectorate returns two rings of three members and their keys.
+func testElectorate() ([]ring.Ring, []*ecdsa.PrivateKey) {
+	var (
+		keys  = make([]*ecdsa.PrivateKey, 6)
+		rings = make([]ring.Ring, 2)
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		rings[i/3] = append(rings[i/3], &keys[i].PublicKey)
+	}
+	return rings, keys
+}
+
+func TestBuildBallot(t *testing.T) {
+	rings, keys := testElectorate()
+
+	ballot, err := BuildBallot(testBox, testProposal, 1, rings, keys[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := ring.RingID(rings[1])
+	if ballot.RingID != id {
+		t.Errorf("ring ID mismatch: have %x, want %x", ballot.RingID, id)
+	}
+	out, err := vm.PrecompiledContractsRing[vm.RingVerifyAddress].Run(append(make([]byte, 32), ballot.Signature...))
+	if err != nil || !bytes.Equal(out, []byte{1}) {
+		t.Fatalf("precompile rejected ballot: %x, %v", out, err)
+	}
+	// Ballots of a member share their tag on a proposal only
+	again, err := BuildBallot(testBox, testProposal, 0, rings, keys[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Tag != ballot.Tag {
+		t.Error("tags of the same member differ")
+	}
+	other, err := BuildBallot(testBox, common.HexToHash("0x02"), 1, rings, keys[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Tag == ballot.Tag {
+		t.Error("tags of different proposals match")
+	}
+	// Ballots survive a round trip through their calldata
+	data, err := ballot.Calldata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseBallot(testBox, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Proposal != ballot.Proposal || parsed.Choice != ballot.Choice || parsed.RingID != ballot.RingID || parsed.Tag != ballot.Tag || !bytes.Equal(parsed.Signature, ballot.Signature) {
+		t.Errorf("parsed ballot mismatch: have %+v, want %+v", parsed, ballot)
+	}
+	if _, err := ParseBallot(testBox, data[4:]); err != errNotBallot {
+		t.Errorf("non vote error mismatch: have %v, want %v", err, errNotBallot)
+	}
+	if _, err := PackAddRings(testProposal, rings); err != nil {
+		t.Errorf("failed to pack rings: %v", err)
+	}
+	outsider, _ := crypto.GenerateKey()
+	if _, err := BuildBallot(testBox, testProposal, 1, rings, outsider); err != errNotVoter {
+		t.Errorf("non voter error mismatch: have %v, want %v", err, errNotVoter)
+	}
+}
+
+func TestTally(t *testing.T) {
+	rings, keys := testElectorate()
+
+	vote := func(proposal common.Hash, choice uint8, rings []ring.Ring, key *ecdsa.PrivateKey) *Ballot {
+		ballot, err := BuildBallot(testBox, proposal, choice, rings, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ballot
+	}
+	outsider, _ := crypto.GenerateKey()
+	foreign := ring.Ring{&keys[0].PublicKey, &outsider.PublicKey}
+
+	forged := vote(testProposal, 0, rings, keys[5])
+	forged.Choice = 1
+
+	ballots := []*Ballot{
+		vote(testProposal, 0, rings, keys[0]),
+		vote(testProposal, 1, rings, keys[1]),
+		vote(testProposal, 1, rings, keys[3]),
+		vote(testProposal, 0, rings, keys[3]),                 // second vote of a member
+		vote(common.HexToHash("0x02"), 0, rings, keys[2]),     // other proposal
+		vote(testProposal, 3, rings, keys[2]),                 // unknown choice
+		vote(testProposal, 0, []ring.Ring{foreign}, outsider), // unregistered ring
+		forged, // tampered choice
+	}
+	tally := NewTally(testBox, testProposal, 2)
+	tally.Count(rings, ballots)
+
+	if tally.Votes[0] != 1 || tally.Votes[1] != 2 {
+		t.Errorf("votes mismatch: have %v, want [1 2]", tally.Votes)
+	}
+	want := map[int]error{3: errDuplicate, 4: errWrongVote, 5: errBadChoice, 6: errNoRing, 7: errBadMessage}
+	for i, err := range want {
+		if tally.Rejected[i] != err {
+			t.Errorf("ballot %d: rejection mismatch: have %v, want %v", i, tally.Rejected[i], err)
+		}
+	}
+	if len(tally.Rejected) != len(want) {
+		t.Errorf("rejected count mismatch: have %d, want %d", len(tally.Rejected), len(want))
+	}
+	// Ballots counted later can't vote again
+	tally.Count(rings, []*Ballot{vote(testProposal, 1, rings, keys[0]), vote(testProposal, 1, rings, keys[5])})
+	if tally.Votes[1] != 3 || tally.Rejected[8] != errDuplicate {
+		t.Errorf("later votes mismatch: have %v, %v", tally.Votes, tally.Rejected[8])
+	}
+}
@@ -32,4 +32,8 @@ var (
 	// ErrNonceTooHigh is returned if the nonce of a transaction is higher than the
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
+
+	// ErrKeyImageSpent is returned if the key image of a ring transaction was
+	// already spent by a transaction included in the chain.
+	ErrKeyImageSpent = errors.New("key image already spent")
 )
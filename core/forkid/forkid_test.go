@@ -0,0 +1,109 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package forkid
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that fork IDs are calculated correctly, matching the EIP-2124 values
+// for mainnet, and that scheduling the ring fork changes them.
+func TestCreation(t *testing.T) {
+	type testcase struct {
+		head uint64
+		want ID
+	}
+	tests := []struct {
+		config *params.ChainConfig
+		cases  []testcase
+	}{
+		{
+			params.MainnetChainConfig,
+			[]testcase{
+				{0, ID{Hash: [4]byte{0xfc, 0x64, 0xec, 0x04}, Next: 1150000}},
+				{1149999, ID{Hash: [4]byte{0xfc, 0x64, 0xec, 0x04}, Next: 1150000}},
+				{1150000, ID{Hash: [4]byte{0x97, 0xc2, 0xc3, 0x4c}, Next: 1920000}},
+				{1920000, ID{Hash: [4]byte{0x91, 0xd1, 0xf9, 0x48}, Next: 2463000}},
+				{2463000, ID{Hash: [4]byte{0x7a, 0x64, 0xda, 0x13}, Next: 2675000}},
+				{2675000, ID{Hash: [4]byte{0x3e, 0xdd, 0x5b, 0x10}, Next: 4370000}},
+				{4370000, ID{Hash: [4]byte{0xa0, 0x0b, 0xc3, 0x24}, Next: 0}},
+				{6000000, ID{Hash: [4]byte{0xa0, 0x0b, 0xc3, 0x24}, Next: 0}},
+			},
+		},
+	}
+	for i, tt := range tests {
+		for j, c := range tt.cases {
+			if have := NewID(tt.config, params.MainnetGenesisHash, c.head); have != c.want {
+				t.Errorf("test %d, case %d: fork ID mismatch: have %x, want %x", i, j, have, c.want)
+			}
+		}
+	}
+	// The ring fork is announced before it activates and changes the ID after
+	config := *params.MainnetChainConfig
+	config.RingForkBlock = big.NewInt(5000000)
+	if have := NewID(&config, params.MainnetGenesisHash, 4370000); have.Next != 5000000 {
+		t.Errorf("ring fork not announced: have next %d, want 5000000", have.Next)
+	}
+	if NewID(&config, params.MainnetGenesisHash, 5000000) == NewID(params.MainnetChainConfig, params.MainnetGenesisHash, 5000000) {
+		t.Error("ring fork doesn't change the fork ID")
+	}
+}
+
+// Tests that remote fork IDs are accepted or rejected by the filter.
+func TestValidation(t *testing.T) {
+	config := *params.MainnetChainConfig
+	config.RingForkBlock = big.NewInt(5000000)
+	var (
+		homestead = NewID(&config, params.MainnetGenesisHash, 1150000)
+		byzantium = NewID(&config, params.MainnetGenesisHash, 4370000)
+		ring      = NewID(&config, params.MainnetGenesisHash, 5000000)
+		noRing    = NewID(params.MainnetChainConfig, params.MainnetGenesisHash, 4370000)
+	)
+	tests := []struct {
+		head uint64
+		id   ID
+		err  error
+	}{
+		// Same state, same or no announced fork
+		{4370000, byzantium, nil},
+		{4370000, noRing, nil},
+		// Remote at an earlier fork, knowing of the next local one
+		{4370000, homestead, nil},
+		// Remote ahead of the local node
+		{4370000, ring, nil},
+		{1150000, byzantium, nil},
+		// Remote behind without knowing of the next fork
+		{4370000, ID{Hash: homestead.Hash, Next: 0}, ErrRemoteStale},
+		// Remote still syncing, behind the ring fork it knows of
+		{6000000, byzantium, nil},
+		// Local node passed the ring fork, the remote doesn't know of it
+		{5000000, noRing, ErrRemoteStale},
+		// Local node passed a fork the remote announces but never took
+		{4370000, ID{Hash: byzantium.Hash, Next: 4000000}, ErrLocalIncompatibleOrStale},
+		// Unknown checksum
+		{4370000, ID{Hash: [4]byte{0xba, 0xdd, 0xca, 0xfe}}, ErrLocalIncompatibleOrStale},
+	}
+	for i, tt := range tests {
+		filter := NewFilter(&config, params.MainnetGenesisHash, func() uint64 { return tt.head })
+		if err := filter(tt.id); err != tt.err {
+			t.Errorf("test %d: validation error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
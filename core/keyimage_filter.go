@@ -0,0 +1,204 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	keyImageFilterMissMeter  = metrics.NewRegisteredMeter("txpool/keyimage/filter/miss", nil)  // Lookups answered by the filter alone
+	keyImageFilterCheckMeter = metrics.NewRegisteredMeter("txpool/keyimage/filter/check", nil) // Lookups falling back to the state
+)
+
+// keyImageFilterReorgDepth is the number of blocks a new head may be ahead of
+// the filter to be added incrementally, beyond which the filter is rebuilt.
+const keyImageFilterReorgDepth = 64
+
+// KeyImageFilter is a Bloom filter over the spent key image set of a state,
+// answering most lookups of unspent key images without touching the state.
+// A key image absent from the filter is certainly not spent, a present one
+// is checked against the state, which stays authoritative: false positives
+// only cost the lookup the filter would have spared.
+//
+// The filter holds the storage keys of the key image precompile, which are
+// hashes of the key images. It is added to as blocks are appended to the
+// chain and rebuilt from the state on reorgs, as Bloom filters can't forget
+// entries. Key images pruned from the state stay in the filter until the next
+// rebuild, as false positives.
+type KeyImageFilter struct {
+	bits     []uint64 // bit set of the filter
+	k        uint64   // number of hash functions
+	n        uint64   // number of entries added
+	capacity uint64   // number of entries sized for
+	fpRate   float64  // false positive rate at capacity
+
+	lock sync.RWMutex
+}
+
+// NewKeyImageFilter creates an empty filter sized for capacity entries with
+// the given false positive rate.
+func NewKeyImageFilter(capacity uint64, fpRate float64) *KeyImageFilter {
+	f := &KeyImageFilter{fpRate: fpRate}
+	f.resize(capacity)
+	return f
+}
+
+// resize clears the filter and sizes it for capacity entries, with the
+// optimal number of bits and hash functions for the false positive rate.
+func (f *KeyImageFilter) resize(capacity uint64) {
+	if capacity == 0 {
+		capacity = 1
+	}
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(f.fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Floor(float64(m)/float64(capacity)*math.Ln2 + 0.5))
+	if k == 0 {
+		k = 1
+	}
+	f.bits = make([]uint64, (m+63)/64)
+	f.k, f.n, f.capacity = k, 0, capacity
+}
+
+// keyImageFilterKey returns the key a key image is filtered by, its hashed
+// storage key in the key image precompile.
+func keyImageFilterKey(image []byte) []byte {
+	return crypto.Keccak256(vm.KeyImageSlot(image).Bytes())
+}
+
+// locations calls fn with the bit indices of a key, derived from the two
+// halves of its first 16 bytes by double hashing.
+func (f *KeyImageFilter) locations(key []byte, fn func(uint64) bool) bool {
+	var (
+		m  = uint64(len(f.bits)) * 64
+		h1 = binary.BigEndian.Uint64(key[0:8])
+		h2 = binary.BigEndian.Uint64(key[8:16]) | 1
+	)
+	for i := uint64(0); i < f.k; i++ {
+		if !fn((h1 + i*h2) % m) {
+			return false
+		}
+	}
+	return true
+}
+
+// add inserts a hashed key into the filter. The lock must be held.
+func (f *KeyImageFilter) add(key []byte) {
+	f.locations(key, func(bit uint64) bool {
+		f.bits[bit/64] |= 1 << (bit % 64)
+		return true
+	})
+	f.n++
+}
+
+// Add inserts a spent key image, given as the 64 byte concatenation of its
+// coordinates, into the filter.
+func (f *KeyImageFilter) Add(image []byte) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.add(keyImageFilterKey(image))
+}
+
+// MayContain reports whether the key image may be in the filter. If false,
+// the key image is certainly not spent in the state the filter follows.
+func (f *KeyImageFilter) MayContain(image []byte) bool {
+	key := keyImageFilterKey(image)
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.locations(key, func(bit uint64) bool {
+		return f.bits[bit/64]&(1<<(bit%64)) != 0
+	})
+}
+
+// Spent reports whether the key image is spent in statedb, which must be the
+// state the filter follows, consulting the state only if the filter can't
+// rule the key image out.
+func (f *KeyImageFilter) Spent(statedb *state.StateDB, image []byte) bool {
+	if !f.MayContain(image) {
+		keyImageFilterMissMeter.Mark(1)
+		return false
+	}
+	keyImageFilterCheckMeter.Mark(1)
+	return vm.KeyImageSeen(statedb, image)
+}
+
+// Rebuild refills the filter with the key image set of statedb, growing it if
+// the set outgrew its capacity.
+func (f *KeyImageFilter) Rebuild(statedb *state.StateDB) error {
+	start := time.Now()
+
+	var keys [][]byte
+	if tr := statedb.StorageTrie(vm.KeyImageSetAddress); tr != nil {
+		it := trie.NewIterator(tr.NodeIterator(nil))
+		for it.Next() {
+			keys = append(keys, common.CopyBytes(it.Key))
+		}
+		if it.Err != nil {
+			return it.Err
+		}
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	capacity := f.capacity
+	for uint64(len(keys)) > capacity {
+		capacity *= 2
+	}
+	f.resize(capacity)
+	for _, key := range keys {
+		f.add(key)
+	}
+	log.Debug("Rebuilt key image filter", "entries", len(keys), "capacity", capacity, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// AddBlock inserts the key images spent by the ring transactions of a block.
+// Key images of failed transactions are added too, as harmless false
+// positives. Shielded pool spends keep their key images in a set of their own.
+func (f *KeyImageFilter) AddBlock(block *types.Block) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, tx := range block.Transactions() {
+		if image := tx.KeyImage(); image != nil {
+			f.add(keyImageFilterKey(image))
+		}
+	}
+}
+
+// full reports whether the filter holds more entries than it was sized for,
+// exceeding its false positive rate.
+func (f *KeyImageFilter) full() bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.n > f.capacity
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// testKeyImage returns a distinct fake 64 byte key image for i.
+func testKeyImage(i uint64) []byte {
+	image := make([]byte, 64)
+	binary.BigEndian.PutUint64(image[56:], i+1)
+	return image
+}
+
+// Tests that the filter never misses an added key image and keeps its false
+// positive rate close to the configured one at capacity.
+func TestKeyImageFilterFalsePositives(t *testing.T) {
+	filter := NewKeyImageFilter(1000, 0.01)
+	for i := uint64(0); i < 1000; i++ {
+		filter.Add(testKeyImage(i))
+	}
+	for i := uint64(0); i < 1000; i++ {
+		if !filter.MayContain(testKeyImage(i)) {
+			t.Fatalf("key image %d missing", i)
+		}
+	}
+	var positives int
+	for i := uint64(1000); i < 11000; i++ {
+		if filter.MayContain(testKeyImage(i)) {
+			positives++
+		}
+	}
+	if positives > 300 {
+		t.Errorf("false positive rate too high: %d/10000, want ~100", positives)
+	}
+	if filter.full() {
+		t.Error("filter full at capacity")
+	}
+	filter.Add(testKeyImage(1000))
+	if !filter.full() {
+		t.Error("filter not full over capacity")
+	}
+}
+
+// Tests that rebuilt filters track the key image set of the state, growing
+// past their capacity, and that lookups stay authoritative.
+func TestKeyImageFilterRebuild(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	for i := uint64(0); i < 100; i++ {
+		vm.MarkKeyImageSeen(statedb, testKeyImage(i), i)
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ = state.New(root, statedb.Database())
+
+	filter := NewKeyImageFilter(10, 0.001)
+	if err := filter.Rebuild(statedb); err != nil {
+		t.Fatal(err)
+	}
+	if filter.full() {
+		t.Fatal("rebuilt filter not grown")
+	}
+	for i := uint64(0); i < 100; i++ {
+		if !filter.Spent(statedb, testKeyImage(i)) {
+			t.Fatalf("key image %d not spent", i)
+		}
+	}
+	for i := uint64(100); i < 200; i++ {
+		if filter.Spent(statedb, testKeyImage(i)) {
+			t.Fatalf("key image %d spent", i)
+		}
+	}
+	// Rebuilding from an empty state forgets every key image
+	empty, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	if err := filter.Rebuild(empty); err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 100; i++ {
+		if filter.MayContain(testKeyImage(i)) {
+			t.Fatalf("key image %d left after rebuild", i)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ReadRingMetadataVersion retrieves the version of the ring transaction
+// metadata in the database, zero if it was never written.
+func ReadRingMetadataVersion(db DatabaseReader) uint64 {
+	var version uint64
+
+	enc, _ := db.Get(ringMetadataVersionKey)
+	rlp.DecodeBytes(enc, &version)
+
+	return version
+}
+
+// WriteRingMetadataVersion stores the version of the ring transaction metadata.
+func WriteRingMetadataVersion(db DatabaseWriter, version uint64) {
+	enc, _ := rlp.EncodeToBytes(version)
+	if err := db.Put(ringMetadataVersionKey, enc); err != nil {
+		log.Crit("Failed to store the ring metadata version", "err", err)
+	}
+}
+
+// ReadRingMetadataProgress retrieves the number of the last block whose ring
+// metadata was migrated, and whether a migration is in progress.
+func ReadRingMetadataProgress(db DatabaseReader) (uint64, bool) {
+	data, _ := db.Get(ringMetadataProgressKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteRingMetadataProgress stores the number of the last block whose ring
+// metadata was migrated.
+func WriteRingMetadataProgress(db DatabaseWriter, number uint64) {
+	if err := db.Put(ringMetadataProgressKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the ring metadata progress", "err", err)
+	}
+}
+
+// DeleteRingMetadataProgress removes the migration progress once done.
+func DeleteRingMetadataProgress(db DatabaseDeleter) {
+	if err := db.Delete(ringMetadataProgressKey); err != nil {
+		log.Crit("Failed to delete the ring metadata progress", "err", err)
+	}
+}
+
+// ReadKeyImageLookupEntry retrieves the positional metadata of the ring
+// transaction spending a key image, given as the 64 byte concatenation of its
+// coordinates, or nil if it wasn't spent on the canonical chain.
+func ReadKeyImageLookupEntry(db DatabaseReader, image []byte) *KeyImageLookupEntry {
+	data, _ := db.Get(keyImageLookupKey(image))
+	if len(data) == 0 {
+		return nil
+	}
+	entry := new(KeyImageLookupEntry)
+	if err := rlp.DecodeBytes(data, entry); err != nil {
+		log.Error("Invalid key image lookup entry RLP", "image", common.Bytes2Hex(image), "err", err)
+		return nil
+	}
+	return entry
+}
+
+// WriteKeyImageLookupEntry stores the positional metadata of the ring
+// transaction spending a key image.
+func WriteKeyImageLookupEntry(db DatabaseWriter, image []byte, entry *KeyImageLookupEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to encode key image lookup entry", "err", err)
+	}
+	if err := db.Put(keyImageLookupKey(image), data); err != nil {
+		log.Crit("Failed to store key image lookup entry", "err", err)
+	}
+}
+
+// DeleteKeyImageLookupEntry removes the positional metadata of a key image.
+func DeleteKeyImageLookupEntry(db DatabaseDeleter, image []byte) {
+	db.Delete(keyImageLookupKey(image))
+}
+
+// ReadRingMembers retrieves the members of the ring with the given fingerprint,
+// the sender account of ring transactions signed over it.
+func ReadRingMembers(db DatabaseReader, fingerprint common.Address) ring.Ring {
+	data, _ := db.Get(ringFingerprintKey(fingerprint))
+	if len(data) == 0 {
+		return nil
+	}
+	var keys [][]byte
+	if err := rlp.DecodeBytes(data, &keys); err != nil {
+		log.Error("Invalid ring members RLP", "fingerprint", fingerprint, "err", err)
+		return nil
+	}
+	members := make(ring.Ring, len(keys))
+	for i, key := range keys {
+		pub, err := crypto.DecompressPubkey(key)
+		if err != nil {
+			log.Error("Invalid ring member", "fingerprint", fingerprint, "index", i, "err", err)
+			return nil
+		}
+		members[i] = pub
+	}
+	return members
+}
+
+// WriteRingMembers stores the members of a ring under its fingerprint. Rings
+// are never deleted, as their fingerprint is derived from their members.
+func WriteRingMembers(db DatabaseWriter, members ring.Ring) {
+	fingerprint, err := types.RingFingerprint(members)
+	if err != nil {
+		log.Error("Invalid ring", "err", err)
+		return
+	}
+	keys := make([][]byte, len(members))
+	for i, pub := range members {
+		keys[i] = crypto.CompressPubkey(pub)
+	}
+	data, err := rlp.EncodeToBytes(keys)
+	if err != nil {
+		log.Crit("Failed to encode ring members", "err", err)
+	}
+	if err := db.Put(ringFingerprintKey(fingerprint), data); err != nil {
+		log.Crit("Failed to store ring members", "err", err)
+	}
+}
+
+// WriteRingTxLookupEntries stores the key image lookup metadata and the ring
+// members of every ring transaction from a block.
+func WriteRingTxLookupEntries(db DatabaseWriter, block *types.Block) {
+	for _, tx := range block.Transactions() {
+		image := tx.KeyImage()
+		if image == nil {
+			continue
+		}
+		WriteKeyImageLookupEntry(db, image, &KeyImageLookupEntry{
+			BlockHash:  block.Hash(),
+			BlockIndex: block.NumberU64(),
+			TxHash:     tx.Hash(),
+		})
+		WriteRingMembers(db, tx.RingSignature().Ring)
+	}
+}
+
+// DeleteRingTxLookupEntries removes the key image lookup metadata of the ring
+// transactions from a block.
+func DeleteRingTxLookupEntries(db DatabaseDeleter, block *types.Block) {
+	for _, tx := range block.Transactions() {
+		if image := tx.KeyImage(); image != nil {
+			DeleteKeyImageLookupEntry(db, image)
+		}
+	}
+}
+
+// ReadShieldedOutput retrieves the shielded pool output with the given index.
+func ReadShieldedOutput(db DatabaseReader, index uint64) *ShieldedOutputEntry {
+	data, _ := db.Get(shieldedOutputKey(index))
+	if len(data) == 0 {
+		return nil
+	}
+	entry := new(ShieldedOutputEntry)
+	if err := rlp.DecodeBytes(data, entry); err != nil {
+		log.Error("Invalid shielded output RLP", "index", index, "err", err)
+		return nil
+	}
+	return entry
+}
+
+// WriteShieldedOutput stores the shielded pool output with the given index.
+func WriteShieldedOutput(db DatabaseWriter, index uint64, entry *ShieldedOutputEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to encode shielded output", "err", err)
+	}
+	if err := db.Put(shieldedOutputKey(index), data); err != nil {
+		log.Crit("Failed to store shielded output", "err", err)
+	}
+}
+
+// DeleteShieldedOutput removes the shielded pool output with the given index.
+func DeleteShieldedOutput(db DatabaseDeleter, index uint64) {
+	db.Delete(shieldedOutputKey(index))
+}
+
+// ReadRingMemberTxs retrieves the ring transactions with the given compressed
+// public key among their members, ordered by block number. Entries of blocks
+// reorged out of the chain may be included, callers must check them against
+// the canonical chain.
+func ReadRingMemberTxs(db DatabaseReader, member []byte) []RingMemberTxEntry {
+	data, _ := db.Get(ringMemberTxsKey(member))
+	if len(data) == 0 {
+		return nil
+	}
+	var entries []RingMemberTxEntry
+	if err := rlp.DecodeBytes(data, &entries); err != nil {
+		log.Error("Invalid ring member transactions RLP", "member", common.Bytes2Hex(member), "err", err)
+		return nil
+	}
+	return entries
+}
+
+// WriteRingMemberTxs stores the ring transactions with the given compressed
+// public key among their members.
+func WriteRingMemberTxs(db DatabaseWriter, member []byte, entries []RingMemberTxEntry) {
+	data, err := rlp.EncodeToBytes(entries)
+	if err != nil {
+		log.Crit("Failed to encode ring member transactions", "err", err)
+	}
+	if err := db.Put(ringMemberTxsKey(member), data); err != nil {
+		log.Crit("Failed to store ring member transactions", "err", err)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Tests that the key image lookups and ring members of ring transactions can
+// be stored, retrieved and deleted.
+func TestRingTxLookupStorage(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	members := make(ring.Ring, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		members[i] = &keys[i].PublicKey
+	}
+	chainID := big.NewInt(1)
+	signer := types.NewRingSigner(chainID)
+	tx, err := types.SignRingTx(types.NewRingTransaction(chainID, 0, &common.Address{}, big.NewInt(1), 100000, big.NewInt(1), nil), signer, members, keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := types.NewTransaction(1, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	block := types.NewBlock(&types.Header{Number: big.NewInt(314)}, []*types.Transaction{legacy, tx}, nil, nil)
+
+	if entry := ReadKeyImageLookupEntry(db, tx.KeyImage()); entry != nil {
+		t.Fatalf("non existent key image lookup returned: %v", entry)
+	}
+	WriteRingTxLookupEntries(db, block)
+
+	entry := ReadKeyImageLookupEntry(db, tx.KeyImage())
+	if entry == nil {
+		t.Fatal("key image lookup not found")
+	}
+	if entry.BlockHash != block.Hash() || entry.BlockIndex != block.NumberU64() || entry.TxHash != tx.Hash() {
+		t.Fatalf("key image lookup mismatch: have %x/%d/%x, want %x/%d/%x", entry.BlockHash, entry.BlockIndex, entry.TxHash, block.Hash(), block.NumberU64(), tx.Hash())
+	}
+	fingerprint, _ := types.RingFingerprint(members)
+	stored := ReadRingMembers(db, fingerprint)
+	if len(stored) != len(members) {
+		t.Fatalf("ring member count mismatch: have %d, want %d", len(stored), len(members))
+	}
+	for i := range members {
+		if !ring.PublicKeyEqual(stored[i], members[i]) {
+			t.Errorf("ring member %d mismatch", i)
+		}
+	}
+	// Deleting the block drops the lookups but keeps the ring
+	DeleteRingTxLookupEntries(db, block)
+	if entry := ReadKeyImageLookupEntry(db, tx.KeyImage()); entry != nil {
+		t.Fatalf("deleted key image lookup returned: %v", entry)
+	}
+	if ReadRingMembers(db, fingerprint) == nil {
+		t.Fatal("ring members deleted with block")
+	}
+}
+
+// Tests that shielded output commitments can be stored, retrieved and deleted.
+func TestShieldedOutputStorage(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	output := &ShieldedOutputEntry{Key: []byte{0x02, 0x01}, Commitment: []byte{0x03, 0x02}, TxHash: common.Hash{0x01}}
+	if entry := ReadShieldedOutput(db, 7); entry != nil {
+		t.Fatalf("non existent output returned: %v", entry)
+	}
+	WriteShieldedOutput(db, 7, output)
+	entry := ReadShieldedOutput(db, 7)
+	if entry == nil || !bytes.Equal(entry.Key, output.Key) || !bytes.Equal(entry.Commitment, output.Commitment) || entry.TxHash != output.TxHash {
+		t.Fatalf("output mismatch: have %v, want %v", entry, output)
+	}
+	DeleteShieldedOutput(db, 7)
+	if entry := ReadShieldedOutput(db, 7); entry != nil {
+		t.Fatalf("deleted output returned: %v", entry)
+	}
+}
+
+// Tests the ring metadata version and migration progress markers.
+func TestRingMetadataMarkers(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	if version := ReadRingMetadataVersion(db); version != 0 {
+		t.Fatalf("pristine version mismatch: have %d, want 0", version)
+	}
+	if _, ok := ReadRingMetadataProgress(db); ok {
+		t.Fatal("progress found in pristine database")
+	}
+	WriteRingMetadataProgress(db, 42)
+	if number, ok := ReadRingMetadataProgress(db); !ok || number != 42 {
+		t.Fatalf("progress mismatch: have %d/%v, want 42/true", number, ok)
+	}
+	DeleteRingMetadataProgress(db)
+	if _, ok := ReadRingMetadataProgress(db); ok {
+		t.Fatal("deleted progress found")
+	}
+	WriteRingMetadataVersion(db, 1)
+	if version := ReadRingMetadataVersion(db); version != 1 {
+		t.Fatalf("version mismatch: have %d, want 1", version)
+	}
+}
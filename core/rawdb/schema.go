@@ -21,6 +21,7 @@ import (
 	"encoding/binary"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/metrics"
 )
 
@@ -41,6 +42,12 @@ var (
 	// fastTrieProgressKey tracks the number of trie entries imported during fast sync.
 	fastTrieProgressKey = []byte("TrieSync")
 
+	// ringMetadataVersionKey tracks the version of the ring transaction metadata.
+	ringMetadataVersionKey = []byte("RingMetadataVersion")
+
+	// ringMetadataProgressKey tracks the last block whose ring metadata was migrated.
+	ringMetadataProgressKey = []byte("RingMetadataProgress")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -53,11 +60,17 @@ var (
 	txLookupPrefix  = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
+	keyImageLookupPrefix  = []byte("k") // keyImageLookupPrefix + keccak256(image) -> key image lookup metadata
+	ringFingerprintPrefix = []byte("f") // ringFingerprintPrefix + fingerprint -> ring members
+	shieldedOutputPrefix  = []byte("o") // shieldedOutputPrefix + index (uint64 big endian) -> shielded output commitment
+	ringMemberTxsPrefix   = []byte("m") // ringMemberTxsPrefix + compressed public key -> ring transactions with the key as member
+
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
-	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	BloomBitsIndexPrefix  = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	RingMemberIndexPrefix = []byte("iR") // RingMemberIndexPrefix is the data table of the ring member indexer to track its progress
 
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
@@ -71,6 +84,31 @@ type TxLookupEntry struct {
 	Index      uint64
 }
 
+// KeyImageLookupEntry is a positional metadata to help looking up the ring
+// transaction spending a key image.
+type KeyImageLookupEntry struct {
+	BlockHash  common.Hash
+	BlockIndex uint64
+	TxHash     common.Hash
+}
+
+// ShieldedOutputEntry is the commitment of an output of the shielded pool,
+// along with the transaction creating it.
+type ShieldedOutputEntry struct {
+	Key        []byte // compressed one-time key
+	Commitment []byte // compressed amount commitment
+	TxHash     common.Hash
+}
+
+// RingMemberTxEntry is a positional metadata of a ring transaction with a
+// given public key among its ring members.
+type RingMemberTxEntry struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	RingSize    uint64
+}
+
 // encodeBlockNumber encodes a block number as big endian uint64
 func encodeBlockNumber(number uint64) []byte {
 	enc := make([]byte, 8)
@@ -123,6 +161,26 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// keyImageLookupKey = keyImageLookupPrefix + keccak256(image)
+func keyImageLookupKey(image []byte) []byte {
+	return append(keyImageLookupPrefix, crypto.Keccak256(image)...)
+}
+
+// ringFingerprintKey = ringFingerprintPrefix + fingerprint
+func ringFingerprintKey(fingerprint common.Address) []byte {
+	return append(ringFingerprintPrefix, fingerprint.Bytes()...)
+}
+
+// shieldedOutputKey = shieldedOutputPrefix + index (uint64 big endian)
+func shieldedOutputKey(index uint64) []byte {
+	return append(shieldedOutputPrefix, encodeBlockNumber(index)...)
+}
+
+// ringMemberTxsKey = ringMemberTxsPrefix + compressed public key
+func ringMemberTxsKey(member []byte) []byte {
+	return append(ringMemberTxsPrefix, member...)
+}
+
 // preimageKey = preimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(preimagePrefix, hash.Bytes()...)
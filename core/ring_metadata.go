@@ -0,0 +1,120 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// RingMetadataVersion is the version of the ring transaction metadata kept
+// in the database: key image lookups, ring members by fingerprint and the
+// commitments of shielded outputs. Databases with an older version get the
+// metadata of their canonical chain written on startup.
+const RingMetadataVersion = 1
+
+// writeRingMetadata stores the ring transaction metadata of a canonical block.
+func writeRingMetadata(db rawdb.DatabaseWriter, block *types.Block, receipts types.Receipts) {
+	rawdb.WriteRingTxLookupEntries(db, block)
+
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if l.Address != vm.ShieldedPoolAddress || len(l.Topics) != 2 || l.Topics[0] != vm.ShieldedOutputTopic {
+				continue
+			}
+			var output vm.ShieldedOutput
+			if err := rlp.DecodeBytes(l.Data, &output); err != nil {
+				log.Error("Invalid shielded output log", "block", block.Number(), "tx", l.TxHash, "err", err)
+				continue
+			}
+			rawdb.WriteShieldedOutput(db, l.Topics[1].Big().Uint64(), &rawdb.ShieldedOutputEntry{
+				Key:        output.Key,
+				Commitment: output.Commitment,
+				TxHash:     l.TxHash,
+			})
+		}
+	}
+}
+
+// deleteRingMetadata removes the ring transaction metadata of a block leaving
+// the canonical chain. Ring members are kept, they don't depend on the block.
+func deleteRingMetadata(db rawdb.DatabaseDeleter, block *types.Block, receipts types.Receipts) {
+	rawdb.DeleteRingTxLookupEntries(db, block)
+
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if l.Address == vm.ShieldedPoolAddress && len(l.Topics) == 2 && l.Topics[0] == vm.ShieldedOutputTopic {
+				rawdb.DeleteShieldedOutput(db, l.Topics[1].Big().Uint64())
+			}
+		}
+	}
+}
+
+// migrateRingMetadata writes the ring transaction metadata of the canonical
+// chain up to the current head, if the database predates it. The migration
+// records its progress and resumes after interruptions; rewriting the entries
+// of a block is harmless, so it is safe to run more than once.
+func (bc *BlockChain) migrateRingMetadata() error {
+	if rawdb.ReadRingMetadataVersion(bc.db) >= RingMetadataVersion {
+		return nil
+	}
+	head := bc.CurrentBlock().NumberU64()
+
+	var next uint64
+	if number, ok := rawdb.ReadRingMetadataProgress(bc.db); ok {
+		next = number + 1
+	}
+	if next <= head {
+		log.Info("Migrating ring transaction metadata", "from", next, "head", head)
+	}
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		batch  = bc.db.NewBatch()
+	)
+	for number := next; number <= head; number++ {
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		if block := rawdb.ReadBlock(bc.db, hash, number); block != nil {
+			writeRingMetadata(batch, block, rawdb.ReadReceipts(bc.db, hash, number))
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize || number == head {
+			rawdb.WriteRingMetadataProgress(batch, number)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating ring transaction metadata", "number", number, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	rawdb.WriteRingMetadataVersion(bc.db, RingMetadataVersion)
+	rawdb.DeleteRingMetadataProgress(bc.db)
+
+	if next <= head {
+		log.Info("Migrated ring transaction metadata", "blocks", head-next+1, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
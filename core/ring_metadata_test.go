@@ -0,0 +1,115 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the ring transaction metadata follows the canonical chain, and
+// that databases predating it are migrated once on startup.
+func TestRingMetadata(t *testing.T) {
+	var (
+		db   = ethdb.NewMemDatabase()
+		keys = make([]*ecdsa.PrivateKey, 3)
+		ms   = make(ring.Ring, len(keys))
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		ms[i] = &keys[i].PublicKey
+	}
+	from, _ := types.RingFingerprint(ms)
+	gspec := &Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  GenesisAlloc{from: {Balance: big.NewInt(1000000000)}},
+	}
+	genesis := gspec.MustCommit(db)
+
+	tx := types.NewRingTransaction(gspec.Config.ChainID, 0, &common.Address{}, big.NewInt(1), params.TxGas+uint64(len(ms))*params.RingVerifyPerMemberGas, new(big.Int), nil)
+	tx, err := types.SignRingTx(tx, types.NewRingSigner(gspec.Config.ChainID), ms, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, block *BlockGen) {
+		if i == 0 {
+			block.AddTx(tx)
+		}
+	})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if entry := rawdb.ReadKeyImageLookupEntry(db, tx.KeyImage()); entry == nil || entry.TxHash != tx.Hash() {
+		t.Fatalf("key image lookup mismatch: have %v, want tx %x", entry, tx.Hash())
+	}
+	if rawdb.ReadRingMembers(db, from) == nil {
+		t.Fatal("ring members not stored")
+	}
+	// Reorg to a longer chain without the transaction, dropping its lookup
+	forks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, block *BlockGen) {
+		block.SetCoinbase(common.Address{1})
+	})
+	if _, err := blockchain.InsertChain(forks); err != nil {
+		t.Fatal(err)
+	}
+	if entry := rawdb.ReadKeyImageLookupEntry(db, tx.KeyImage()); entry != nil {
+		t.Fatalf("key image lookup left after reorg: %v", entry)
+	}
+	// Include it again, then simulate a database predating the metadata
+	blocks, _ = GenerateChain(gspec.Config, forks[2], ethash.NewFaker(), db, 1, func(i int, block *BlockGen) {
+		block.AddTx(tx)
+	})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	blockchain.Stop()
+
+	rawdb.DeleteKeyImageLookupEntry(db, tx.KeyImage())
+	db.Delete([]byte("RingMetadataVersion"))
+
+	for i := 0; i < 2; i++ {
+		blockchain, err := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blockchain.Stop()
+
+		if version := rawdb.ReadRingMetadataVersion(db); version != RingMetadataVersion {
+			t.Fatalf("run %d: version mismatch: have %d, want %d", i, version, RingMetadataVersion)
+		}
+		if _, ok := rawdb.ReadRingMetadataProgress(db); ok {
+			t.Fatalf("run %d: migration progress left", i)
+		}
+		entry := rawdb.ReadKeyImageLookupEntry(db, tx.KeyImage())
+		if entry == nil || entry.BlockHash != blocks[0].Hash() {
+			t.Fatalf("run %d: migrated key image lookup mismatch: have %v, want block %x", i, entry, blocks[0].Hash())
+		}
+	}
+}
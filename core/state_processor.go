@@ -90,6 +90,11 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	if err != nil {
 		return nil, 0, err
 	}
+	// Ring transactions spend their key image, which can only be spent once
+	image := tx.KeyImage()
+	if image != nil && vm.KeyImageSeen(statedb, image) {
+		return nil, 0, ErrKeyImageSpent
+	}
 	// Create a new context to be used in the EVM environment
 	context := NewEVMContext(msg, header, bc, author)
 	// Create a new environment which holds all relevant information
@@ -100,6 +105,9 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	if err != nil {
 		return nil, 0, err
 	}
+	if image != nil {
+		vm.MarkKeyImageSeen(statedb, image, header.Number.Uint64())
+	}
 	// Update the state with pending changes
 	var root []byte
 	if config.IsByzantium(header.Number) {
@@ -0,0 +1,133 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the key images of ring transactions are spent by block processing,
+// rolled back by reorgs and never forgotten.
+func TestKeyImageSet(t *testing.T) {
+	var (
+		db   = ethdb.NewMemDatabase()
+		keys = make([]*ecdsa.PrivateKey, 3)
+		ms   = make(ring.Ring, len(keys))
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		ms[i] = &keys[i].PublicKey
+	}
+	from, _ := types.RingFingerprint(ms)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{
+			ChainID:        big.NewInt(1),
+			HomesteadBlock: new(big.Int),
+			EIP155Block:    new(big.Int),
+			EIP158Block:    new(big.Int),
+			ByzantiumBlock: new(big.Int),
+			RingForkBlock:  new(big.Int),
+		},
+		Alloc: GenesisAlloc{from: {Balance: big.NewInt(1000000000)}},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.NewRingSigner(gspec.Config.ChainID)
+
+	ringTx := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx := types.NewRingTransaction(gspec.Config.ChainID, nonce, &common.Address{}, big.NewInt(1), params.TxGas+uint64(len(ms))*params.RingVerifyPerMemberGas, new(big.Int), nil)
+		tx, err := types.SignRingTx(tx, signer, ms, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	spent := ringTx(0, keys[0])
+
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer blockchain.Stop()
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, block *BlockGen) {
+		if i == 0 {
+			block.AddTx(spent)
+		}
+	})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ := blockchain.State()
+	if number, ok := vm.KeyImageSpentAt(statedb, spent.KeyImage()); !ok || number != 1 {
+		t.Fatalf("key image spent at: have %d %v, want 1 true", number, ok)
+	}
+	// A second spend of the key image is invalid, whatever its ring or nonce
+	var (
+		gp      = new(GasPool).AddGas(blocks[2].GasLimit())
+		header  = types.CopyHeader(blocks[2].Header())
+		usedGas uint64
+	)
+	header.Number.SetUint64(4)
+	if _, _, err := ApplyTransaction(gspec.Config, blockchain, &common.Address{}, gp, statedb, header, ringTx(1, keys[0]), &usedGas, vm.Config{}); err != ErrKeyImageSpent {
+		t.Fatalf("double spend: have %v, want %v", err, ErrKeyImageSpent)
+	}
+	if _, _, err := ApplyTransaction(gspec.Config, blockchain, &common.Address{}, gp, statedb, header, ringTx(1, keys[1]), &usedGas, vm.Config{}); err != nil {
+		t.Fatalf("spend of another key image failed: %v", err)
+	}
+
+	// Reorg to a longer chain without the spend, which must be forgotten
+	forks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, block *BlockGen) {
+		block.SetCoinbase(common.Address{1})
+	})
+	if _, err := blockchain.InsertChain(forks); err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ = blockchain.State()
+	if vm.KeyImageSeen(statedb, spent.KeyImage()) {
+		t.Fatal("key image still spent after reorg")
+	}
+
+	// Spend it on the new chain and check it stays spent however deep
+	blocks, _ = GenerateChain(gspec.Config, forks[3], ethash.NewFaker(), db, 16, func(i int, block *BlockGen) {
+		if i == 0 {
+			block.AddTx(spent)
+		}
+	})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		statedb, _ := blockchain.StateAt(block.Root())
+		if !vm.KeyImageSeen(statedb, spent.KeyImage()) {
+			t.Errorf("block %d: key image not spent", block.NumberU64())
+		}
+	}
+	statedb, _ = blockchain.State()
+	header = types.CopyHeader(blocks[len(blocks)-1].Header())
+	header.Number.Add(header.Number, common.Big1)
+	if _, _, err := ApplyTransaction(gspec.Config, blockchain, &common.Address{}, gp, statedb, header, ringTx(1, keys[0]), &usedGas, vm.Config{}); err != ErrKeyImageSpent {
+		t.Fatalf("deep double spend: have %v, want %v", err, ErrKeyImageSpent)
+	}
+}
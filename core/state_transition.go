@@ -73,10 +73,12 @@ type Message interface {
 	Nonce() uint64
 	CheckNonce() bool
 	Data() []byte
+	RingSize() int // members of the ring signature, zero if not ring signed
 }
 
-// IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, contractCreation, homestead bool) (uint64, error) {
+// IntrinsicGas computes the 'intrinsic gas' for a message with the given data,
+// signed by a ring of ringSize members or by a single key if ringSize is zero.
+func IntrinsicGas(data []byte, ringSize int, contractCreation, homestead bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if contractCreation && homestead {
@@ -84,6 +86,13 @@ func IntrinsicGas(data []byte, contractCreation, homestead bool) (uint64, error)
 	} else {
 		gas = params.TxGas
 	}
+	// Ring signatures are verified at the price of the verification precompile
+	if ringSize > 0 {
+		if (math.MaxUint64-gas)/params.RingVerifyPerMemberGas < uint64(ringSize) {
+			return 0, vm.ErrOutOfGas
+		}
+		gas += uint64(ringSize) * params.RingVerifyPerMemberGas
+	}
 	// Bump the required gas by the amount of transactional data
 	if len(data) > 0 {
 		// Zero and non-zero bytes are priced differently
@@ -190,7 +199,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	contractCreation := msg.To() == nil
 
 	// Pay intrinsic gas
-	gas, err := IntrinsicGas(st.data, contractCreation, homestead)
+	gas, err := IntrinsicGas(st.data, msg.RingSize(), contractCreation, homestead)
 	if err != nil {
 		return nil, 0, false, err
 	}
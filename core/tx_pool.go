@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -99,6 +100,10 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
+
+	// Metrics for ring transactions sharing a key image
+	keyImageReplaceCounter = metrics.NewRegisteredCounter("txpool/keyimage/replace", nil)
+	keyImageSpentCounter   = metrics.NewRegisteredCounter("txpool/keyimage/spent", nil)
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -137,6 +142,9 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	KeyImageFilterSize   uint64  // Number of spent key images the filter is initially sized for
+	KeyImageFilterFPRate float64 // False positive rate of the spent key image filter
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -154,6 +162,9 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	KeyImageFilterSize:   1 << 20,
+	KeyImageFilterFPRate: 0.001,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -172,6 +183,14 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.KeyImageFilterSize < 1 {
+		log.Warn("Sanitizing invalid txpool key image filter size", "provided", conf.KeyImageFilterSize, "updated", DefaultTxPoolConfig.KeyImageFilterSize)
+		conf.KeyImageFilterSize = DefaultTxPoolConfig.KeyImageFilterSize
+	}
+	if conf.KeyImageFilterFPRate <= 0 || conf.KeyImageFilterFPRate >= 1 {
+		log.Warn("Sanitizing invalid txpool key image filter false positive rate", "provided", conf.KeyImageFilterFPRate, "updated", DefaultTxPoolConfig.KeyImageFilterFPRate)
+		conf.KeyImageFilterFPRate = DefaultTxPoolConfig.KeyImageFilterFPRate
+	}
 	return conf
 }
 
@@ -198,6 +217,9 @@ type TxPool struct {
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
 
+	keyImages     *KeyImageFilter // Filter over the spent key images of the current state
+	keyImagesHead common.Hash     // Head block the key image filter follows
+
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
@@ -210,6 +232,7 @@ type TxPool struct {
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
+	ring      bool // Whether ring transactions are valid in the next block
 }
 
 // NewTxPool creates a new transaction pool to gather, sort and filter inbound
@@ -223,13 +246,14 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		config:      config,
 		chainconfig: chainconfig,
 		chain:       chain,
-		signer:      types.NewEIP155Signer(chainconfig.ChainID),
+		signer:      types.NewRingSigner(chainconfig.ChainID),
 		pending:     make(map[common.Address]*txList),
 		queue:       make(map[common.Address]*txList),
 		beats:       make(map[common.Address]time.Time),
 		all:         newTxLookup(),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
+		keyImages:   NewKeyImageFilter(config.KeyImageFilterSize, config.KeyImageFilterFPRate),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
@@ -413,12 +437,18 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.ring = pool.chainconfig.IsRing(new(big.Int).Add(newHead.Number, big.NewInt(1)))
+	pool.updateKeyImageFilter(newHead)
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	senderCacher.recover(pool.signer, reinject)
 	pool.addTxsLocked(reinject, false)
 
+	// Drop ring transactions whose key image was spent by the new chain, they
+	// can't be included anymore whichever account they are sent from
+	pool.dropSpentKeyImages()
+
 	// validate the pool of pending transactions, this will remove
 	// any transactions that have been included in the block or
 	// have been invalidated because of another transaction (e.g.
@@ -435,6 +465,57 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.promoteExecutables(nil)
 }
 
+// updateKeyImageFilter brings the spent key image filter to the new head,
+// adding the blocks on top of the head it follows, or rebuilding it from the
+// current state after reorgs, deep gaps or once it outgrew its capacity.
+// Resets to the head the filter already follows rebuild it too, as the state
+// may have been rewound underneath.
+func (pool *TxPool) updateKeyImageFilter(newHead *types.Header) {
+	if pool.keyImagesHead != (common.Hash{}) && !pool.keyImages.full() {
+		var (
+			blocks []*types.Block
+			block  = pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64())
+		)
+		for block != nil && block.Hash() != pool.keyImagesHead && len(blocks) < keyImageFilterReorgDepth && block.NumberU64() > 0 {
+			blocks = append(blocks, block)
+			block = pool.chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		}
+		if block != nil && block.Hash() == pool.keyImagesHead && len(blocks) > 0 {
+			for _, block := range blocks {
+				pool.keyImages.AddBlock(block)
+			}
+			pool.keyImagesHead = newHead.Hash()
+			return
+		}
+	}
+	if err := pool.keyImages.Rebuild(pool.currentState); err != nil {
+		// Without the filter every key image would be reported unspent, fall
+		// back to the state until the next successful rebuild
+		log.Error("Failed to rebuild key image filter", "err", err)
+		pool.keyImagesHead = common.Hash{}
+		return
+	}
+	pool.keyImagesHead = newHead.Hash()
+}
+
+// keyImageSpent reports whether a key image is spent in the current state,
+// consulting the filter first. The pool lock must be held.
+func (pool *TxPool) keyImageSpent(image []byte) bool {
+	if pool.keyImagesHead == (common.Hash{}) {
+		return vm.KeyImageSeen(pool.currentState, image)
+	}
+	return pool.keyImages.Spent(pool.currentState, image)
+}
+
+// KeyImageSpent reports whether a key image, given as the 64 byte
+// concatenation of its coordinates, is spent in the current state of the pool.
+func (pool *TxPool) KeyImageSpent(image []byte) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.keyImageSpent(image)
+}
+
 // Stop terminates the transaction pool.
 func (pool *TxPool) Stop() {
 	// Unsubscribe all subscriptions registered from txpool
@@ -579,11 +660,19 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentMaxGas < tx.Gas() {
 		return ErrGasLimit
 	}
+	// Ring transactions are only valid after the ring fork
+	if tx.Type() == types.RingTxType && !pool.ring {
+		return types.ErrTxTypeNotSupported
+	}
 	// Make sure the transaction is signed properly
 	from, err := types.Sender(pool.signer, tx)
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Ring transactions can't spend a key image already spent on chain
+	if image := tx.KeyImage(); image != nil && pool.keyImageSpent(image) {
+		return ErrKeyImageSpent
+	}
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
 	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
@@ -598,7 +687,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
 		return ErrInsufficientFunds
 	}
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.RingSize(), tx.To() == nil, pool.homestead)
 	if err != nil {
 		return err
 	}
@@ -629,6 +718,29 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	// If the transaction reuses the key image of a pooled one, both spend the
+	// same ring member: only accept it as a price bumped replacement. The
+	// conflicting transaction is only dropped once the new one is sure to be
+	// accepted, so check the price bump over any other one holding its nonce
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	conflict := pool.all.GetByKeyImage(tx.KeyImage())
+	if conflict != nil {
+		if !priceBumped(conflict, tx, pool.config.PriceBump) {
+			log.Trace("Discarding conflicting ring transaction", "hash", hash, "conflict", conflict.Hash())
+			return false, ErrReplaceUnderpriced
+		}
+		var old *types.Transaction
+		if list := pool.pending[from]; list != nil {
+			old = list.txs.Get(tx.Nonce())
+		}
+		if list := pool.queue[from]; old == nil && list != nil {
+			old = list.txs.Get(tx.Nonce())
+		}
+		if old != nil && old.Hash() != conflict.Hash() && !priceBumped(old, tx, pool.config.PriceBump) {
+			log.Trace("Discarding underpriced ring transaction replacement", "hash", hash, "old", old.Hash())
+			return false, ErrReplaceUnderpriced
+		}
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -645,8 +757,13 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			pool.removeTx(tx.Hash(), false)
 		}
 	}
+	// Every check passed, drop the transaction spending the same key image
+	if conflict != nil {
+		log.Trace("Replacing conflicting ring transaction", "hash", hash, "old", conflict.Hash())
+		pool.removeTx(conflict.Hash(), true)
+		keyImageReplaceCounter.Inc(1)
+	}
 	// If the transaction is replacing an already pending one, do directly
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
 		inserted, old := list.Add(tx, pool.config.PriceBump)
@@ -916,6 +1033,24 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 	}
 }
 
+// dropSpentKeyImages removes all ring transactions from the pool whose key image
+// was spent by a transaction included in the chain, or all of them if the chain
+// was reorged to before the ring fork.
+func (pool *TxPool) dropSpentKeyImages() {
+	var spent []common.Hash
+	pool.all.Range(func(hash common.Hash, tx *types.Transaction) bool {
+		if image := tx.KeyImage(); image != nil && (!pool.ring || pool.keyImageSpent(image)) {
+			spent = append(spent, hash)
+		}
+		return true
+	})
+	for _, hash := range spent {
+		log.Trace("Removed ring transaction with spent key image", "hash", hash)
+		pool.removeTx(hash, true)
+		keyImageSpentCounter.Inc(1)
+	}
+}
+
 // promoteExecutables moves transactions that have become processable from the
 // future queue to the set of pending transactions. During this process, all
 // invalidated transactions (low nonce, low balance) are deleted.
@@ -1210,14 +1345,16 @@ func (as *accountSet) flatten() []common.Address {
 // peeking into the pool in TxPool.Get without having to acquire the widely scoped
 // TxPool.mu mutex.
 type txLookup struct {
-	all  map[common.Hash]*types.Transaction
-	lock sync.RWMutex
+	all    map[common.Hash]*types.Transaction
+	images map[string]common.Hash // Ring transactions by key image
+	lock   sync.RWMutex
 }
 
 // newTxLookup returns a new txLookup structure.
 func newTxLookup() *txLookup {
 	return &txLookup{
-		all: make(map[common.Hash]*types.Transaction),
+		all:    make(map[common.Hash]*types.Transaction),
+		images: make(map[string]common.Hash),
 	}
 }
 
@@ -1241,6 +1378,18 @@ func (t *txLookup) Get(hash common.Hash) *types.Transaction {
 	return t.all[hash]
 }
 
+// GetByKeyImage returns the ring transaction with the given key image if it
+// exists in the lookup, or nil if not found.
+func (t *txLookup) GetByKeyImage(image []byte) *types.Transaction {
+	if image == nil {
+		return nil
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.all[t.images[string(image)]]
+}
+
 // Count returns the current number of items in the lookup.
 func (t *txLookup) Count() int {
 	t.lock.RLock()
@@ -1255,6 +1404,9 @@ func (t *txLookup) Add(tx *types.Transaction) {
 	defer t.lock.Unlock()
 
 	t.all[tx.Hash()] = tx
+	if image := tx.KeyImage(); image != nil {
+		t.images[string(image)] = tx.Hash()
+	}
 }
 
 // Remove removes a transaction from the lookup.
@@ -1262,5 +1414,10 @@ func (t *txLookup) Remove(hash common.Hash) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
+	if tx := t.all[hash]; tx != nil {
+		if image := string(tx.KeyImage()); t.images[image] == hash {
+			delete(t.images, image)
+		}
+	}
 	delete(t.all, hash)
 }
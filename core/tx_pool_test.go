@@ -29,7 +29,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
@@ -1567,6 +1569,165 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that ring transactions spending the same key image conflict in the
+// pool, can only replace each other with a price bump, and are dropped once the
+// key image is spent on chain.
+func TestTransactionRingKeyImage(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	// Create a ring of accounts and fund its fingerprint account
+	keys := make([]*ecdsa.PrivateKey, 3)
+	members := make(ring.Ring, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		members[i] = &keys[i].PublicKey
+	}
+	from, _ := types.RingFingerprint(members)
+	pool.currentState.AddBalance(from, big.NewInt(1000000000))
+
+	signer := types.NewRingSigner(params.TestChainConfig.ChainID)
+	ringTx := func(nonce uint64, gasprice int64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx := types.NewRingTransaction(params.TestChainConfig.ChainID, nonce, &common.Address{}, big.NewInt(100), 100000, big.NewInt(gasprice), nil)
+		tx, err := types.SignRingTx(tx, signer, members, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	first := ringTx(0, 1, keys[0])
+	if err := pool.AddRemote(first); err != nil {
+		t.Fatalf("failed to add ring transaction: %v", err)
+	}
+	// A second spend of the same key image is a replacement, whatever its nonce
+	if err := pool.AddRemote(ringTx(1, 1, keys[0])); err != ErrReplaceUnderpriced {
+		t.Fatalf("double spend error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	second := ringTx(0, 2, keys[0])
+	if err := pool.AddRemote(second); err != nil {
+		t.Fatalf("failed to replace ring transaction: %v", err)
+	}
+	if pool.Get(first.Hash()) != nil {
+		t.Fatalf("replaced ring transaction still pooled")
+	}
+	// Other members of the ring spend other key images
+	other := ringTx(1, 10, keys[1])
+	if err := pool.AddRemote(other); err != nil {
+		t.Fatalf("failed to add ring transaction of another member: %v", err)
+	}
+	// A replacement outbidding the conflicting transaction but not the one
+	// holding its nonce is rejected without dropping either
+	if err := pool.AddRemote(ringTx(1, 3, keys[0])); err != ErrReplaceUnderpriced {
+		t.Fatalf("nonce replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if pool.Get(second.Hash()) == nil || pool.Get(other.Hash()) == nil {
+		t.Fatalf("rejected replacement dropped pooled ring transactions")
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d pending, %d queued, want 2, 0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Spending a key image on chain evicts its pooled transaction and rejects
+	// any further one
+	vm.MarkKeyImageSeen(statedb, other.KeyImage(), 1)
+	pool.lockedReset(nil, nil)
+
+	if pool.Get(other.Hash()) != nil {
+		t.Fatalf("ring transaction with spent key image still pooled")
+	}
+	if err := pool.AddRemote(ringTx(2, 10, keys[1])); err != ErrKeyImageSpent {
+		t.Fatalf("spent key image error mismatch: have %v, want %v", err, ErrKeyImageSpent)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that ring transactions are rejected by the pool until the ring fork
+// activates.
+func TestTransactionRingFork(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := *params.TestChainConfig
+	config.RingForkBlock = big.NewInt(10)
+
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	decoy, _ := crypto.GenerateKey()
+	members := ring.Ring{&key.PublicKey, &decoy.PublicKey}
+	from, _ := types.RingFingerprint(members)
+	pool.currentState.AddBalance(from, big.NewInt(1000000000))
+
+	tx := types.NewRingTransaction(config.ChainID, 0, &common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil)
+	tx, err := types.SignRingTx(tx, types.NewRingSigner(config.ChainID), members, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.AddRemote(tx); err != types.ErrTxTypeNotSupported {
+		t.Fatalf("pre-fork ring transaction error mismatch: have %v, want %v", err, types.ErrTxTypeNotSupported)
+	}
+	// Plain transactions are unaffected by the fork
+	plain, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(plain.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(1), plain)); err != nil {
+		t.Fatalf("failed to add plain transaction: %v", err)
+	}
+}
+
+// Tests that ring transactions have to pay intrinsic gas for verifying every
+// member of their ring.
+func TestTransactionRingIntrinsicGas(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	keys := make([]*ecdsa.PrivateKey, 8)
+	members := make(ring.Ring, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		members[i] = &keys[i].PublicKey
+	}
+	from, _ := types.RingFingerprint(members)
+	pool.currentState.AddBalance(from, big.NewInt(1000000000))
+
+	signer := types.NewRingSigner(params.TestChainConfig.ChainID)
+	ringTx := func(nonce, gas uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx := types.NewRingTransaction(params.TestChainConfig.ChainID, nonce, &common.Address{}, big.NewInt(100), gas, big.NewInt(1), nil)
+		tx, err := types.SignRingTx(tx, signer, members, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	intrinsic := params.TxGas + uint64(len(members))*params.RingVerifyPerMemberGas
+	if err := pool.AddRemote(ringTx(0, params.TxGas, keys[0])); err != ErrIntrinsicGas {
+		t.Fatalf("transfer gas error mismatch: have %v, want %v", err, ErrIntrinsicGas)
+	}
+	if err := pool.AddRemote(ringTx(0, intrinsic-1, keys[0])); err != ErrIntrinsicGas {
+		t.Fatalf("intrinsic gas error mismatch: have %v, want %v", err, ErrIntrinsicGas)
+	}
+	if err := pool.AddRemote(ringTx(0, intrinsic, keys[0])); err != nil {
+		t.Fatalf("failed to add ring transaction paying intrinsic gas: %v", err)
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
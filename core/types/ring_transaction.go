@@ -0,0 +1,367 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Ring transactions are signed by one of a ring of accounts with a linkable
+// ring signature instead of an ECDSA signature, hiding which member sent
+// them. They are EIP-2718 typed transactions: their canonical encoding is the
+// type byte followed by the RLP encoding of the payload, which is wrapped in
+// an RLP string inside blocks.
+//
+// The sender of a ring transaction is the fingerprint of its ring, see
+// RingFingerprint, so all transactions signed over the same ring share their
+// sender account and its nonce. The key image of the signature links all
+// transactions sent by the same member. Signatures must use the v3 transcript,
+// whose key images do not reveal the member and which gives every member a
+// single key image.
+
+// RingTxType is the EIP-2718 transaction type of ring transactions.
+const RingTxType = 0x72
+
+var (
+	ErrTxTypeNotSupported = errors.New("transaction type not supported")
+	ErrInvalidRingSig     = errors.New("invalid transaction ring signature")
+)
+
+// ringTxData holds the fields of ring transactions that legacy transactions
+// lack.
+type ringTxData struct {
+	ChainID *big.Int
+	Sig     *ring.RingSign // nil until signed
+}
+
+// ringTxPayload is the RLP encoding of a ring transaction after its type byte.
+type ringTxPayload struct {
+	ChainID      *big.Int
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"`
+	Amount       *big.Int
+	Payload      []byte
+	Sig          *ring.RingSign
+}
+
+// ringTxJSON is the web3 RPC format of ring transactions.
+type ringTxJSON struct {
+	Type         hexutil.Uint64  `json:"type"`
+	ChainID      *hexutil.Big    `json:"chainId"`
+	AccountNonce hexutil.Uint64  `json:"nonce"`
+	Price        *hexutil.Big    `json:"gasPrice"`
+	GasLimit     hexutil.Uint64  `json:"gas"`
+	Recipient    *common.Address `json:"to"`
+	Amount       *hexutil.Big    `json:"value"`
+	Payload      hexutil.Bytes   `json:"input"`
+	Signature    hexutil.Bytes   `json:"ringSignature"`
+	Hash         *common.Hash    `json:"hash,omitempty"`
+}
+
+// NewRingTransaction creates an unsigned ring transaction for the chain with
+// the given ID. A nil recipient creates a contract.
+func NewRingTransaction(chainID *big.Int, nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	tx := newTransaction(nonce, to, amount, gasLimit, gasPrice, data)
+	tx.ring = &ringTxData{ChainID: new(big.Int)}
+	if chainID != nil {
+		tx.ring.ChainID.Set(chainID)
+	}
+	return tx
+}
+
+// Type returns the EIP-2718 type of the transaction, 0 for legacy ones.
+func (tx *Transaction) Type() uint8 {
+	if tx.ring != nil {
+		return RingTxType
+	}
+	return 0
+}
+
+// RingSignature returns the ring signature of a ring transaction, nil for
+// other transactions and unsigned ring transactions.
+func (tx *Transaction) RingSignature() *ring.RingSign {
+	if tx.ring == nil {
+		return nil
+	}
+	return tx.ring.Sig
+}
+
+// RingSize returns the number of ring members of a signed ring transaction.
+// It returns zero for other transactions.
+func (tx *Transaction) RingSize() int {
+	sig := tx.RingSignature()
+	if sig == nil {
+		return 0
+	}
+	return len(sig.Ring)
+}
+
+// KeyImage returns the key image of a signed ring transaction as the 64 byte
+// concatenation of its coordinates, the format of the key image precompile.
+// It returns nil for other transactions.
+func (tx *Transaction) KeyImage() []byte {
+	sig := tx.RingSignature()
+	if sig == nil || sig.I == nil {
+		return nil
+	}
+	return append(common.LeftPadBytes(sig.I.X.Bytes(), 32), common.LeftPadBytes(sig.I.Y.Bytes(), 32)...)
+}
+
+// WithRingSignature returns a copy of the ring transaction signed with sig.
+func (tx *Transaction) WithRingSignature(sig *ring.RingSign) (*Transaction, error) {
+	if tx.ring == nil {
+		return nil, ErrTxTypeNotSupported
+	}
+	cpy := &Transaction{data: tx.data, ring: &ringTxData{ChainID: tx.ring.ChainID, Sig: sig}}
+	return cpy, nil
+}
+
+// payload returns the fields of a ring transaction encoded after its type.
+func (tx *Transaction) payload() *ringTxPayload {
+	return &ringTxPayload{
+		ChainID:      tx.ring.ChainID,
+		AccountNonce: tx.data.AccountNonce,
+		Price:        tx.data.Price,
+		GasLimit:     tx.data.GasLimit,
+		Recipient:    tx.data.Recipient,
+		Amount:       tx.data.Amount,
+		Payload:      tx.data.Payload,
+		Sig:          tx.ring.Sig,
+	}
+}
+
+// MarshalBinary returns the canonical encoding of the transaction: the RLP
+// encoding for legacy transactions, the type byte followed by the RLP
+// encoded payload for typed ones.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	if tx.ring == nil {
+		return rlp.EncodeToBytes(&tx.data)
+	}
+	if tx.ring.Sig == nil {
+		return nil, ErrInvalidRingSig
+	}
+	enc, err := rlp.EncodeToBytes(tx.payload())
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{RingTxType}, enc...), nil
+}
+
+// UnmarshalBinary decodes the canonical encoding of a transaction, see
+// MarshalBinary.
+func (tx *Transaction) UnmarshalBinary(b []byte) error {
+	if len(b) > 0 && b[0] > 0x7f {
+		return rlp.DecodeBytes(b, tx)
+	}
+	return tx.decodeTyped(b)
+}
+
+// decodeTyped decodes the canonical encoding of a typed transaction.
+func (tx *Transaction) decodeTyped(b []byte) error {
+	if len(b) == 0 {
+		return errors.New("empty typed transaction")
+	}
+	if b[0] != RingTxType {
+		return ErrTxTypeNotSupported
+	}
+	var dec ringTxPayload
+	if err := rlp.DecodeBytes(b[1:], &dec); err != nil {
+		return err
+	}
+	if dec.Sig == nil {
+		return ErrInvalidRingSig
+	}
+	*tx = Transaction{
+		data: txdata{
+			AccountNonce: dec.AccountNonce,
+			Price:        dec.Price,
+			GasLimit:     dec.GasLimit,
+			Recipient:    dec.Recipient,
+			Amount:       dec.Amount,
+			Payload:      dec.Payload,
+			V:            new(big.Int),
+			R:            new(big.Int),
+			S:            new(big.Int),
+		},
+		ring: &ringTxData{ChainID: dec.ChainID, Sig: dec.Sig},
+	}
+	tx.size.Store(common.StorageSize(rlp.ListSize(uint64(len(b)))))
+	return nil
+}
+
+// marshalRingJSON encodes a ring transaction in the web3 RPC format.
+func (tx *Transaction) marshalRingJSON() ([]byte, error) {
+	hash := tx.Hash()
+	enc := ringTxJSON{
+		Type:         RingTxType,
+		ChainID:      (*hexutil.Big)(tx.ring.ChainID),
+		AccountNonce: hexutil.Uint64(tx.data.AccountNonce),
+		Price:        (*hexutil.Big)(tx.data.Price),
+		GasLimit:     hexutil.Uint64(tx.data.GasLimit),
+		Recipient:    tx.data.Recipient,
+		Amount:       (*hexutil.Big)(tx.data.Amount),
+		Payload:      tx.data.Payload,
+		Hash:         &hash,
+	}
+	if tx.ring.Sig != nil {
+		sig, err := rlp.EncodeToBytes(tx.ring.Sig)
+		if err != nil {
+			return nil, err
+		}
+		enc.Signature = sig
+	}
+	return json.Marshal(&enc)
+}
+
+// unmarshalRingJSON decodes a ring transaction in the web3 RPC format.
+func (tx *Transaction) unmarshalRingJSON(input []byte) error {
+	var dec ringTxJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.ChainID == nil || dec.Price == nil || dec.Amount == nil {
+		return errors.New("missing required field in ring transaction")
+	}
+	t := NewRingTransaction((*big.Int)(dec.ChainID), uint64(dec.AccountNonce), dec.Recipient, (*big.Int)(dec.Amount), uint64(dec.GasLimit), (*big.Int)(dec.Price), dec.Payload)
+	if len(dec.Signature) > 0 {
+		t.ring.Sig = new(ring.RingSign)
+		if err := rlp.DecodeBytes(dec.Signature, t.ring.Sig); err != nil {
+			return err
+		}
+	}
+	*tx = Transaction{data: t.data, ring: t.ring}
+	return nil
+}
+
+// prefixedRlpHash returns the Keccak256 hash of the type byte followed by
+// the RLP encoding of x.
+func prefixedRlpHash(prefix byte, x interface{}) (h common.Hash) {
+	hw := sha3.NewKeccak256()
+	hw.Write([]byte{prefix})
+	rlp.Encode(hw, x)
+	hw.Sum(h[:0])
+	return h
+}
+
+// RingFingerprint returns the sender address of ring transactions signed
+// over members.
+func RingFingerprint(members ring.Ring) (common.Address, error) {
+	id, err := ring.RingID(members)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(id[12:]), nil
+}
+
+// SignRingTx signs the ring transaction with prv, whose public key must be a
+// member of the ring.
+func SignRingTx(tx *Transaction, s RingSigner, members ring.Ring, prv *ecdsa.PrivateKey) (*Transaction, error) {
+	if tx.ring == nil {
+		return nil, ErrTxTypeNotSupported
+	}
+	opts := &ring.SignOpts{Version: ring.TranscriptV3}
+	sig, err := ring.SignWithOpts(s.Hash(tx), members, prv, members.Index(&prv.PublicKey), opts)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithRingSignature(sig)
+}
+
+// ringVerifyCacheSize is the number of ring signature checks remembered.
+const ringVerifyCacheSize = 4096
+
+// ringVerifyCache holds the results of ring signature checks, shared by all
+// ring signers. A transaction is verified when it is gossiped, when it enters
+// the pool and when its block is processed, on a different copy each time.
+var ringVerifyCache, _ = ring.NewVerifyCache(ringVerifyCacheSize)
+
+// RingSigner implements Signer for ring transactions and, following the
+// EIP155 rules, for legacy ones.
+type RingSigner struct {
+	EIP155Signer
+}
+
+// NewRingSigner returns a signer for ring transactions on the chain with the
+// given ID.
+func NewRingSigner(chainId *big.Int) RingSigner {
+	return RingSigner{NewEIP155Signer(chainId)}
+}
+
+func (s RingSigner) Equal(s2 Signer) bool {
+	r, ok := s2.(RingSigner)
+	return ok && r.chainId.Cmp(s.chainId) == 0
+}
+
+// Sender returns the fingerprint of the ring of a ring transaction after
+// verifying its signature.
+func (s RingSigner) Sender(tx *Transaction) (common.Address, error) {
+	if tx.ring == nil {
+		return s.EIP155Signer.Sender(tx)
+	}
+	if tx.ring.ChainID.Cmp(s.chainId) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	sig := tx.ring.Sig
+	if sig == nil || sig.Curve != crypto.S256() || sig.Version != ring.TranscriptV3 || common.Hash(sig.M) != s.Hash(tx) {
+		return common.Address{}, ErrInvalidRingSig
+	}
+	if err := sig.Ring.Validate(); err != nil {
+		return common.Address{}, ErrInvalidRingSig
+	}
+	if !ringVerifyCache.Verify(sig) {
+		return common.Address{}, ErrInvalidRingSig
+	}
+	return RingFingerprint(sig.Ring)
+}
+
+// SignatureValues is not supported for ring transactions, which have no
+// ECDSA signature.
+func (s RingSigner) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.ring != nil {
+		return nil, nil, nil, ErrTxTypeNotSupported
+	}
+	return s.EIP155Signer.SignatureValues(tx, sig)
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s RingSigner) Hash(tx *Transaction) common.Hash {
+	if tx.ring == nil {
+		return s.EIP155Signer.Hash(tx)
+	}
+	return prefixedRlpHash(RingTxType, []interface{}{
+		s.chainId,
+		tx.data.AccountNonce,
+		tx.data.Price,
+		tx.data.GasLimit,
+		tx.data.Recipient,
+		tx.data.Amount,
+		tx.data.Payload,
+	})
+}
@@ -0,0 +1,214 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// signedRingTx returns a ring transaction signed by the second member of a
+// ring of size members.
+func signedRingTx(t *testing.T, signer RingSigner, size int) (*Transaction, ring.Ring) {
+	keys := make([]*ecdsa.PrivateKey, size)
+	members := make(ring.Ring, size)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		members[i] = &keys[i].PublicKey
+	}
+	to := common.HexToAddress("0x0000000000000000000000000000000000000aaa")
+	tx := NewRingTransaction(signer.chainId, 3, &to, big.NewInt(10), 50000, big.NewInt(1), []byte{0x55, 0x44})
+	tx, err := SignRingTx(tx, signer, members, keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx, members
+}
+
+func TestRingTransactionSender(t *testing.T) {
+	signer := NewRingSigner(big.NewInt(18))
+	tx, members := signedRingTx(t, signer, 4)
+
+	from, err := Sender(signer, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := RingFingerprint(members)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != want {
+		t.Errorf("sender mismatch: have %x, want %x", from, want)
+	}
+	if tx.Type() != RingTxType || !tx.Protected() || tx.ChainId().Cmp(big.NewInt(18)) != 0 {
+		t.Errorf("type %d, protected %v, chain id %v", tx.Type(), tx.Protected(), tx.ChainId())
+	}
+	image := tx.KeyImage()
+	if len(image) != 64 || !bytes.Equal(image[:32], common.LeftPadBytes(tx.RingSignature().I.X.Bytes(), 32)) {
+		t.Errorf("invalid key image %x", image)
+	}
+}
+
+// Tests that the signature of a ring transaction is verified once, with later
+// copies of the transaction served from the shared verification cache.
+func TestRingTransactionSenderCache(t *testing.T) {
+	signer := NewRingSigner(big.NewInt(18))
+	tx, _ := signedRingTx(t, signer, 3)
+
+	enc, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits, misses := ringVerifyCache.Stats()
+	if _, err := signer.Sender(tx); err != nil {
+		t.Fatal(err)
+	}
+	if h, m := ringVerifyCache.Stats(); h != hits || m != misses+1 {
+		t.Fatalf("first check: have %d hits and %d misses, want %d and %d", h, m, hits, misses+1)
+	}
+	// A decoded copy doesn't share the sender cache of the original
+	cpy := new(Transaction)
+	if err := rlp.DecodeBytes(enc, cpy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Sender(cpy); err != nil {
+		t.Fatal(err)
+	}
+	if h, m := ringVerifyCache.Stats(); h != hits+1 || m != misses+1 {
+		t.Fatalf("second check: have %d hits and %d misses, want %d and %d", h, m, hits+1, misses+1)
+	}
+}
+
+func TestRingTransactionInvalid(t *testing.T) {
+	signer := NewRingSigner(big.NewInt(18))
+	tx, _ := signedRingTx(t, signer, 3)
+
+	if _, err := NewRingSigner(big.NewInt(1)).Sender(tx); err != ErrInvalidChainId {
+		t.Errorf("wrong chain: have %v, want %v", err, ErrInvalidChainId)
+	}
+	if _, err := NewEIP155Signer(big.NewInt(18)).Sender(tx); err != ErrTxTypeNotSupported {
+		t.Errorf("legacy signer: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	if _, err := tx.WithSignature(signer, make([]byte, 65)); err != ErrTxTypeNotSupported {
+		t.Errorf("ECDSA signature: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+
+	// Changing any signed field must invalidate the signature.
+	tampered := NewRingTransaction(big.NewInt(18), 3, tx.To(), big.NewInt(11), 50000, big.NewInt(1), tx.Data())
+	tampered, _ = tampered.WithRingSignature(tx.RingSignature())
+	if _, err := signer.Sender(tampered); err != ErrInvalidRingSig {
+		t.Errorf("tampered value: have %v, want %v", err, ErrInvalidRingSig)
+	}
+	sig := *tx.RingSignature()
+	sig.S = append([]*big.Int{new(big.Int).Add(sig.S[0], common.Big1)}, sig.S[1:]...)
+	forged, _ := tx.WithRingSignature(&sig)
+	if _, err := signer.Sender(forged); err != ErrInvalidRingSig {
+		t.Errorf("forged signature: have %v, want %v", err, ErrInvalidRingSig)
+	}
+	unsigned := NewRingTransaction(big.NewInt(18), 0, nil, nil, 0, nil, nil)
+	if _, err := signer.Sender(unsigned); err != ErrInvalidRingSig {
+		t.Errorf("unsigned: have %v, want %v", err, ErrInvalidRingSig)
+	}
+	// valid signatures with an older transcript leak the signer and carry a
+	// different key image, so they are rejected
+	key, _ := crypto.GenerateKey()
+	members := ring.Ring{&key.PublicKey, tx.RingSignature().Ring[1]}
+	old, err := ring.SignWithOpts(signer.Hash(tx), members, key, 0, &ring.SignOpts{Version: ring.TranscriptV2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ring.Verify(old) {
+		t.Fatal("v2 signature does not verify")
+	}
+	legacy, _ := tx.WithRingSignature(old)
+	if _, err := signer.Sender(legacy); err != ErrInvalidRingSig {
+		t.Errorf("v2 signature: have %v, want %v", err, ErrInvalidRingSig)
+	}
+}
+
+func TestRingTransactionEncode(t *testing.T) {
+	signer := NewRingSigner(big.NewInt(18))
+	tx, _ := signedRingTx(t, signer, 3)
+
+	bin, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bin[0] != RingTxType {
+		t.Fatalf("type prefix: have %#x, want %#x", bin[0], RingTxType)
+	}
+	if want := crypto.Keccak256Hash(bin); tx.Hash() != want {
+		t.Errorf("hash mismatch: have %x, want %x", tx.Hash(), want)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(bin); err != nil {
+		t.Fatal(err)
+	}
+	checkRingTx(t, "binary", tx, &dec)
+
+	// Inside lists, typed transactions are wrapped in an RLP string.
+	enc, err := rlp.EncodeToBytes(Transactions{rightvrsTx, tx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var txs Transactions
+	if err := rlp.DecodeBytes(enc, &txs); err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].Hash() != rightvrsTx.Hash() {
+		t.Fatal("legacy transaction mismatch")
+	}
+	checkRingTx(t, "rlp", tx, txs[1])
+	if txs[1].Size() != tx.Size() {
+		t.Errorf("size mismatch: have %v, want %v", txs[1].Size(), tx.Size())
+	}
+	if !bytes.Equal(Transactions(txs).GetRlp(1), bin) {
+		t.Error("GetRlp does not return the canonical encoding")
+	}
+
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Transaction
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	checkRingTx(t, "json", tx, &parsed)
+}
+
+func checkRingTx(t *testing.T, name string, want, have *Transaction) {
+	if have.Hash() != want.Hash() {
+		t.Errorf("%s: hash mismatch: have %x, want %x", name, have.Hash(), want.Hash())
+	}
+	signer := NewRingSigner(want.ChainId())
+	from, err := Sender(signer, have)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if wantFrom, _ := Sender(signer, want); from != wantFrom {
+		t.Errorf("%s: sender mismatch: have %x, want %x", name, from, wantFrom)
+	}
+}
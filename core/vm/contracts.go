@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"errors"
 	"math/big"
@@ -45,7 +46,7 @@ var PrecompiledContractsHomestead = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{2}): &sha256hash{},
 	common.BytesToAddress([]byte{3}): &ripemd160hash{},
 	common.BytesToAddress([]byte{4}): &dataCopy{},
-	common.BytesToAddress([]byte{9}): &ringVerify{},
+	RingVerifyAddress:                &ringVerifyLegacy{},
 }
 
 // PrecompiledContractsByzantium contains the default set of pre-compiled Ethereum
@@ -59,7 +60,18 @@ var PrecompiledContractsByzantium = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{6}): &bn256Add{},
 	common.BytesToAddress([]byte{7}): &bn256ScalarMul{},
 	common.BytesToAddress([]byte{8}): &bn256Pairing{},
-	common.BytesToAddress([]byte{9}): &ringVerify{},
+	RingVerifyAddress:                &ringVerifyLegacy{},
+}
+
+// PrecompiledContractsRing contains the pre-compiled contracts added or
+// replaced by the ring fork on top of those of the active Ethereum release.
+// The ring signature verification precompile predates the fork and is part of
+// every release; the fork prices it by ring size and decodes its input
+// strictly.
+var PrecompiledContractsRing = map[common.Address]PrecompiledContract{
+	RingVerifyAddress:   &ringVerify{},
+	KeyImageSetAddress:  &keyImageSeen{},
+	ShieldedPoolAddress: &shieldedPool{},
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -71,16 +83,55 @@ func RunPrecompiledContract(p PrecompiledContract, input []byte, contract *Contr
 	return nil, ErrOutOfGas
 }
 
-type ringVerify struct{}
+// RingVerifyAddress is the address of the ring signature verification
+// precompile.
+var RingVerifyAddress = common.BytesToAddress([]byte{9})
 
-func (c *ringVerify) RequiredGas(input []byte) uint64  {
-	// todo: change required gas to vary with ring size
+// ringVerifyLegacy implements ring signature verification before the ring
+// fork. The input is a 32 byte word followed by the binary encoded signature,
+// the output is a single byte, 1 if the signature is valid and 0 otherwise.
+type ringVerifyLegacy struct{}
+
+func (c *ringVerifyLegacy) RequiredGas(input []byte) uint64 {
 	return params.RingVerifyGas
 }
 
+func (c *ringVerifyLegacy) Run(input []byte) ([]byte, error) {
+	if len(input) < 32 {
+		return []byte{0}, nil
+	}
+	sig, err := ring.DeserializeSignature(input[32:])
+	// the size field of the original encoding had no room for a version
+	if err != nil || sig.Version != 0 {
+		return []byte{0}, nil
+	}
+	if ring.Verify(sig) {
+		return []byte{1}, nil
+	}
+	return []byte{0}, nil
+}
+
+// ringVerify implements ring signature verification from the ring fork on,
+// with the input and output of ringVerifyLegacy.
+type ringVerify struct{}
+
+// RequiredGas charges for every ring member the input has room for, so that
+// inputs which fail to decode cost as much as valid ones of the same length.
+func (c *ringVerify) RequiredGas(input []byte) uint64 {
+	const header, member = 32 + 136, 96
+	var members uint64
+	if len(input) > header {
+		members = (uint64(len(input)-header) + member - 1) / member
+	}
+	return params.RingVerifyBaseGas + members*params.RingVerifyPerMemberGas
+}
+
 
 func (c *ringVerify) Run(input []byte) ([]byte, error) {
-	sig, err := ring.DeserializeSignature(input[32:])
+	if len(input) < 32 {
+		return []byte{0}, nil
+	}
+	sig, err := ring.DecodeStrict(ring.EncodingBinary, input[32:])
 	if err != nil {
 		return []byte{0}, nil
 	}
@@ -94,6 +145,46 @@ func (c *ringVerify) Run(input []byte) ([]byte, error) {
 	}
 }
 
+// statefulPrecompiledContract is a precompiled contract that accesses the state
+// and context of the call it is run in.
+type statefulPrecompiledContract interface {
+	PrecompiledContract
+	withContext(evm *EVM, contract *Contract, readOnly bool) PrecompiledContract
+}
+
+// keyImageSeen reports whether a key image has been seen, either on chain or
+// in a list supplied by the caller. The input is the 64 byte key image
+// followed by any number of 64 byte key images to check it against, the
+// output is 1 if it was seen and 0 otherwise. Contracts preventing double
+// spends or double votes keep their own list or rely on the chain's.
+type keyImageSeen struct {
+	state StateDB
+}
+
+func (c *keyImageSeen) withContext(evm *EVM, contract *Contract, readOnly bool) PrecompiledContract {
+	return &keyImageSeen{state: evm.StateDB}
+}
+
+func (c *keyImageSeen) RequiredGas(input []byte) uint64 {
+	return params.KeyImageSeenBaseGas + uint64(len(input)/64)*params.KeyImageSeenPerImageGas
+}
+
+func (c *keyImageSeen) Run(input []byte) ([]byte, error) {
+	if len(input) < 64 || len(input)%64 != 0 {
+		return []byte{0}, nil
+	}
+	image := input[:64]
+	for list := input[64:]; len(list) > 0; list = list[64:] {
+		if bytes.Equal(list[:64], image) {
+			return []byte{1}, nil
+		}
+	}
+	if c.state != nil && KeyImageSeen(c.state, image) {
+		return []byte{1}, nil
+	}
+	return []byte{0}, nil
+}
+
 // ECRECOVER implemented as a native contract.
 type ecrecover struct{}
 
@@ -17,11 +17,18 @@
 package vm
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -336,8 +343,16 @@ var bn256PairingTests = []precompiledTest{
 	},
 }
 
+// precompiledContract returns the Byzantium or ring precompile at addr.
+func precompiledContract(addr string) PrecompiledContract {
+	if p := PrecompiledContractsRing[common.HexToAddress(addr)]; p != nil {
+		return p
+	}
+	return PrecompiledContractsByzantium[common.HexToAddress(addr)]
+}
+
 func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
-	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
+	p := precompiledContract(addr)
 	in := common.Hex2Bytes(test.input)
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
 		nil, new(big.Int), p.RequiredGas(in))
@@ -354,7 +369,7 @@ func benchmarkPrecompiled(addr string, test precompiledTest, bench *testing.B) {
 	if test.noBenchmark {
 		return
 	}
-	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
+	p := precompiledContract(addr)
 	in := common.Hex2Bytes(test.input)
 	reqGas := p.RequiredGas(in)
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
@@ -481,3 +496,241 @@ func BenchmarkPrecompiledBn256Pairing(bench *testing.B) {
 		benchmarkPrecompiled("08", test, bench)
 	}
 }
+
+// ringVerifyTests returns test data for the ring signature verification
+// precompile, signed with fresh keys.
+func ringVerifyTests(t testing.TB) []precompiledTest {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ring.Sign([32]byte{1}, ring.GenNewKeyRing(4, key, 1), key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := ring.PrecompileInput(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []precompiledTest{
+		{input: common.Bytes2Hex(input), expected: "01", name: "valid"},
+		{input: common.Bytes2Hex(append(input, 0)), expected: "00", name: "trailing_byte", noBenchmark: true},
+		{input: common.Bytes2Hex(input[:len(input)-1]), expected: "00", name: "truncated", noBenchmark: true},
+		{input: "00", expected: "00", name: "short_input", noBenchmark: true},
+	}
+}
+
+// Tests the ring signature verification precompile.
+func TestPrecompiledRingVerify(t *testing.T) {
+	for _, test := range ringVerifyTests(t) {
+		testPrecompiled("09", test, t)
+	}
+}
+
+// Tests that the ring signature verification precompile runs out of gas when
+// given less than it charges, and charges by ring size.
+func TestPrecompiledRingVerifyOOG(t *testing.T) {
+	p := PrecompiledContractsRing[RingVerifyAddress]
+	for _, test := range ringVerifyTests(t) {
+		in := common.Hex2Bytes(test.input)
+		contract := NewContract(AccountRef(common.HexToAddress("1337")),
+			nil, new(big.Int), p.RequiredGas(in)-1)
+		if _, err := RunPrecompiledContract(p, in, contract); err != ErrOutOfGas {
+			t.Errorf("%s: expected out of gas, got %v", test.name, err)
+		}
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prev uint64
+	for _, size := range []int{2, 3, 16} {
+		sig, err := ring.Sign([32]byte{1}, ring.GenNewKeyRing(size, key, 0), key, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		input, err := ring.PrecompileInput(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := params.RingVerifyBaseGas + uint64(size)*params.RingVerifyPerMemberGas
+		if gas := p.RequiredGas(input); gas != want {
+			t.Errorf("ring size %d: expected %d gas, got %d", size, want, gas)
+		} else if gas <= prev {
+			t.Errorf("ring size %d: gas %d not above smaller ring", size, gas)
+		}
+		prev = want
+	}
+	if gas := p.RequiredGas(nil); gas != params.RingVerifyBaseGas {
+		t.Errorf("empty input: expected %d gas, got %d", params.RingVerifyBaseGas, gas)
+	}
+}
+
+// Tests that the ring signature verification precompile keeps its flat price
+// and lenient decoding before the ring fork.
+func TestPrecompiledRingVerifyLegacy(t *testing.T) {
+	p := PrecompiledContractsByzantium[RingVerifyAddress]
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := ring.GenNewKeyRing(16, key, 3)
+	v1, err := ring.SignWithOpts([32]byte{1}, members, key, 3, &ring.SignOpts{Version: ring.TranscriptV1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3, err := ring.Sign([32]byte{1}, members, key, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := append(make([]byte, 32), v1.SerializeSignature()...)
+	tests := []struct {
+		name  string
+		input []byte
+		want  byte
+	}{
+		{"valid", input, 1},
+		{"trailing_byte", append(input, 0), 1},
+		{"truncated", input[:len(input)-1], 0},
+		{"short_input", []byte{0}, 0},
+		{"versioned", append(make([]byte, 32), v3.SerializeSignature()...), 0},
+	}
+	for _, test := range tests {
+		if gas := p.RequiredGas(test.input); gas != params.RingVerifyGas {
+			t.Errorf("%s: expected %d gas, got %d", test.name, params.RingVerifyGas, gas)
+		}
+		out, err := p.Run(test.input)
+		if err != nil || !bytes.Equal(out, []byte{test.want}) {
+			t.Errorf("%s: expected %02x, got %x, %v", test.name, test.want, out, err)
+		}
+	}
+}
+
+// Tests that the ring fork switches the ring signature verification
+// precompile to its size based price and strict decoding.
+func TestPrecompiledRingVerifyFork(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainID:        big.NewInt(1),
+		HomesteadBlock: big.NewInt(0),
+		ByzantiumBlock: big.NewInt(0),
+		RingForkBlock:  big.NewInt(20),
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ring.SignWithOpts([32]byte{1}, ring.GenNewKeyRing(4, key, 1), key, 1, &ring.SignOpts{Version: ring.TranscriptV1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := append(append(make([]byte, 32), sig.SerializeSignature()...), 0)
+
+	tests := []struct {
+		number int64
+		want   byte
+		gas    uint64
+	}{
+		{19, 1, params.RingVerifyGas},
+		{20, 0, params.RingVerifyBaseGas + 5*params.RingVerifyPerMemberGas},
+	}
+	caller := AccountRef(common.HexToAddress("1337"))
+	for _, test := range tests {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		evm := NewEVM(Context{BlockNumber: big.NewInt(test.number)}, statedb, config, Config{})
+		ret, left, err := evm.StaticCall(caller, RingVerifyAddress, input, 1000000)
+		if err != nil {
+			t.Fatalf("block %d: %v", test.number, err)
+		}
+		if !bytes.Equal(ret, []byte{test.want}) {
+			t.Errorf("block %d: trailing byte: expected %02x, got %x", test.number, test.want, ret)
+		}
+		if used := 1000000 - left; used != test.gas {
+			t.Errorf("block %d: expected %d gas, used %d", test.number, test.gas, used)
+		}
+	}
+}
+
+// Benchmarks the ring signature verification precompile.
+func BenchmarkPrecompiledRingVerify(bench *testing.B) {
+	for _, test := range ringVerifyTests(bench) {
+		benchmarkPrecompiled("09", test, bench)
+	}
+}
+
+// Tests the key image precompile against caller supplied lists.
+func TestPrecompiledKeyImageSeen(t *testing.T) {
+	image := "01" + strings.Repeat("00", 63)
+	other := "02" + strings.Repeat("00", 63)
+	tests := []precompiledTest{
+		{input: image, expected: "00", name: "no_list"},
+		{input: image + other, expected: "00", name: "not_listed"},
+		{input: image + other + image, expected: "01", name: "listed"},
+		{input: image + "00", expected: "00", name: "unaligned"},
+		{input: "", expected: "00", name: "empty"},
+	}
+	for _, test := range tests {
+		testPrecompiled("0a", test, t)
+	}
+}
+
+// Tests that the key image precompile consults the key images marked in the
+// state when called through the EVM.
+func TestPrecompiledKeyImageSeenState(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	env := NewEVM(Context{BlockNumber: big.NewInt(1)}, statedb, params.TestChainConfig, Config{})
+
+	image := common.RightPadBytes([]byte{1}, 64)
+	caller := AccountRef(common.HexToAddress("1337"))
+	ret, _, err := env.StaticCall(caller, KeyImageSetAddress, image, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ret, []byte{0}) {
+		t.Errorf("unmarked key image: expected 00, got %x", ret)
+	}
+	MarkKeyImageSeen(statedb, image, 1)
+	if !KeyImageSeen(statedb, image) {
+		t.Fatal("marked key image not seen")
+	}
+	if ret, _, err = env.StaticCall(caller, KeyImageSetAddress, image, 1000000); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ret, []byte{1}) {
+		t.Errorf("marked key image: expected 01, got %x", ret)
+	}
+}
+
+// Tests that the ring signature verification precompile is active on chains
+// without the ring fork, while the precompiles of the fork are not.
+func TestPrecompiledRingFork(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainID:        big.NewInt(1),
+		HomesteadBlock: big.NewInt(0),
+		ByzantiumBlock: big.NewInt(10),
+		RingForkBlock:  big.NewInt(20),
+	}
+	tests := []struct {
+		number           int64
+		verify, ringFork bool
+	}{
+		{0, true, false},
+		{10, true, false},
+		{20, true, true},
+	}
+	for _, test := range tests {
+		evm := NewEVM(Context{BlockNumber: big.NewInt(test.number)}, nil, config, Config{})
+		if have := evm.precompile(RingVerifyAddress) != nil; have != test.verify {
+			t.Errorf("block %d: ring verification precompile active %v, want %v", test.number, have, test.verify)
+		}
+		for _, addr := range []common.Address{KeyImageSetAddress, ShieldedPoolAddress} {
+			if have := evm.precompile(addr) != nil; have != test.ringFork {
+				t.Errorf("block %d: precompile %x active %v, want %v", test.number, addr, have, test.ringFork)
+			}
+		}
+	}
+	// Unscheduled forks keep the verification precompile of the release
+	config = &params.ChainConfig{ChainID: big.NewInt(1), HomesteadBlock: big.NewInt(0)}
+	if evm := NewEVM(Context{BlockNumber: big.NewInt(100)}, nil, config, Config{}); evm.precompile(RingVerifyAddress) == nil {
+		t.Error("ring verification precompile inactive without ring fork")
+	}
+}
@@ -40,14 +40,36 @@ type (
 	GetHashFunc func(uint64) common.Hash
 )
 
+// precompile returns the precompiled contract at addr active at the current
+// block, nil if there is none.
+func (evm *EVM) precompile(addr common.Address) PrecompiledContract {
+	if evm.chainRules.IsRing {
+		if p := PrecompiledContractsRing[addr]; p != nil {
+			return p
+		}
+	}
+	precompiles := PrecompiledContractsHomestead
+	if evm.chainRules.IsByzantium {
+		precompiles = PrecompiledContractsByzantium
+	}
+	return precompiles[addr]
+}
+
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	if contract.CodeAddr != nil {
-		precompiles := PrecompiledContractsHomestead
-		if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if p := precompiles[*contract.CodeAddr]; p != nil {
+		if p := evm.precompile(*contract.CodeAddr); p != nil {
+			if sp, ok := p.(statefulPrecompiledContract); ok {
+				p = sp.withContext(evm, contract, readOnly)
+			}
+			if evm.vmConfig.Debug {
+				if tracer, ok := evm.vmConfig.Tracer.(PrecompileTracer); ok {
+					gas := contract.Gas
+					ret, err := RunPrecompiledContract(p, input, contract)
+					tracer.CapturePrecompile(evm, *contract.CodeAddr, input, gas, gas-contract.Gas, ret, evm.depth+1, err)
+					return ret, err
+				}
+			}
 			return RunPrecompiledContract(p, input, contract)
 		}
 	}
@@ -197,11 +219,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		snapshot = evm.StateDB.Snapshot()
 	)
 	if !evm.StateDB.Exist(addr) {
-		precompiles := PrecompiledContractsHomestead
-		if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
-			precompiles = PrecompiledContractsByzantium
-		}
-		if precompiles[addr] == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
+		if evm.precompile(addr) == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
 				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
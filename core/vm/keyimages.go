@@ -0,0 +1,82 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The key images spent on chain are kept in the storage of the key image
+// precompile, so they are part of the state root: reorgs roll them back and
+// state snapshots revert them like any other state change.
+//
+// Every key image, given as the 64 byte concatenation of its coordinates, is
+// stored at the hash of the image and holds the number of the block spending
+// it plus one. Ring transactions don't expire, so a spent key image must stay
+// in the set for good: forgetting it would let its ring member spend again.
+
+// KeyImageSetAddress is the address of the key image precompile. Its storage
+// holds the key images seen on chain, see MarkKeyImageSeen.
+var KeyImageSetAddress = common.BytesToAddress([]byte{10})
+
+// KeyImageSlot returns the storage slot marking a key image.
+func KeyImageSlot(image []byte) common.Hash {
+	return crypto.Keccak256Hash(image)
+}
+
+// KeyImageSeen reports whether the key image, given as the 64 byte
+// concatenation of its coordinates, has been marked as seen in db.
+func KeyImageSeen(db StateDB, image []byte) bool {
+	_, seen := spentAt(db, KeyImageSetAddress, image)
+	return seen
+}
+
+// KeyImageSpentAt returns the number of the block the key image was marked as
+// seen in, and whether it was.
+func KeyImageSpentAt(db StateDB, image []byte) (uint64, bool) {
+	return spentAt(db, KeyImageSetAddress, image)
+}
+
+// MarkKeyImageSeen records the key image, given as the 64 byte concatenation
+// of its coordinates, as seen in db by the block with the given number.
+func MarkKeyImageSeen(db StateDB, image []byte, number uint64) {
+	markSpent(db, KeyImageSetAddress, image, number)
+}
+
+// spentAt returns the number of the block the key image was spent in, in the
+// set kept in the storage of the given account, and whether it was.
+func spentAt(db StateDB, set common.Address, image []byte) (uint64, bool) {
+	value := db.GetState(set, KeyImageSlot(image))
+	if value == (common.Hash{}) {
+		return 0, false
+	}
+	return value.Big().Uint64() - 1, true
+}
+
+// markSpent records the key image as spent by the block with the given number
+// in the set kept in the storage of the given account.
+func markSpent(db StateDB, set common.Address, image []byte, number uint64) {
+	// Precompile accounts have no code and maybe no balance, give them a
+	// nonce so that the EIP-158 cleanup doesn't delete them with their storage.
+	if db.GetNonce(set) == 0 {
+		db.SetNonce(set, 1)
+	}
+	db.SetState(set, KeyImageSlot(image), common.BigToHash(new(big.Int).SetUint64(number+1)))
+}
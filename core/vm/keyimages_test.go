@@ -0,0 +1,57 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestKeyImageSet(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+
+	images := [][]byte{
+		common.RightPadBytes([]byte{1}, 64),
+		common.RightPadBytes([]byte{2}, 64),
+		common.RightPadBytes([]byte{3}, 64),
+	}
+	MarkKeyImageSeen(statedb, images[0], 0)
+	MarkKeyImageSeen(statedb, images[1], 5)
+	MarkKeyImageSeen(statedb, images[2], 5)
+
+	// The set must survive the EIP-158 cleanup of empty accounts
+	statedb.Finalise(true)
+	for i, image := range images {
+		if _, ok := KeyImageSpentAt(statedb, image); !ok {
+			t.Fatalf("image %d: not seen", i)
+		}
+	}
+	if number, _ := KeyImageSpentAt(statedb, images[2]); number != 5 {
+		t.Errorf("spent at: have %d, want 5", number)
+	}
+
+	snapshot := statedb.Snapshot()
+	unseen := common.RightPadBytes([]byte{4}, 64)
+	MarkKeyImageSeen(statedb, unseen, 6)
+	statedb.RevertToSnapshot(snapshot)
+	if KeyImageSeen(statedb, unseen) {
+		t.Error("reverted mark left image seen")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 )
 
 // Storage represents a contract's storage.
@@ -103,6 +104,43 @@ type Tracer interface {
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
 }
 
+// PrecompileTracer is implemented by tracers also collecting the calls to the
+// precompiled contracts, which run no opcodes for CaptureState to report. It
+// is called after the precompile ran, with the gas available to and charged by
+// the call.
+type PrecompileTracer interface {
+	CapturePrecompile(env *EVM, addr common.Address, input []byte, gas, cost uint64, output []byte, depth int, err error) error
+}
+
+// RingVerifyLog is the structured trace of a call to the ring signature
+// verification precompile.
+type RingVerifyLog struct {
+	Depth    int    // call depth of the precompile
+	RingSize int    // number of ring members, zero if the signature didn't decode
+	Gas      uint64 // gas available to the call
+	GasCost  uint64 // gas charged by the precompile
+	Valid    bool   // whether the signature verified
+	Err      error  // error of the call, out of gas only
+}
+
+// NewRingVerifyLog decodes a call to the ring signature verification precompile
+// into its structured trace.
+func NewRingVerifyLog(input []byte, gas, cost uint64, output []byte, depth int, err error) *RingVerifyLog {
+	log := &RingVerifyLog{
+		Depth:   depth,
+		Gas:     gas,
+		GasCost: cost,
+		Valid:   err == nil && len(output) == 1 && output[0] == 1,
+		Err:     err,
+	}
+	if len(input) >= 32 {
+		if sig, err := ring.DecodeStrict(ring.EncodingBinary, input[32:]); err == nil {
+			log.RingSize = len(sig.Ring)
+		}
+	}
+	return log
+}
+
 // StructLogger is an EVM state logger and implements Tracer.
 //
 // StructLogger can capture state based on the given Log configuration and also keeps
@@ -112,6 +150,7 @@ type StructLogger struct {
 	cfg LogConfig
 
 	logs          []StructLog
+	ringVerifies  []RingVerifyLog
 	changedValues map[common.Address]Storage
 	output        []byte
 	err           error
@@ -189,6 +228,15 @@ func (l *StructLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost ui
 	return nil
 }
 
+// CapturePrecompile implements the PrecompileTracer interface, tracing the
+// calls to the ring signature verification precompile.
+func (l *StructLogger) CapturePrecompile(env *EVM, addr common.Address, input []byte, gas, cost uint64, output []byte, depth int, err error) error {
+	if addr == RingVerifyAddress {
+		l.ringVerifies = append(l.ringVerifies, *NewRingVerifyLog(input, gas, cost, output, depth, err))
+	}
+	return nil
+}
+
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	l.output = output
@@ -205,6 +253,10 @@ func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration
 // StructLogs returns the captured log entries.
 func (l *StructLogger) StructLogs() []StructLog { return l.logs }
 
+// RingVerifyLogs returns the captured calls to the ring signature verification
+// precompile.
+func (l *StructLogger) RingVerifyLogs() []RingVerifyLog { return l.ringVerifies }
+
 // Error returns the VM error captured by the trace.
 func (l *StructLogger) Error() error { return l.err }
 
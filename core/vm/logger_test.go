@@ -21,6 +21,8 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -63,3 +65,40 @@ func TestStoreCapture(t *testing.T) {
 		t.Errorf("expected %x, got %x", exp, logger.changedValues[contract.Address()][index])
 	}
 }
+
+// Tests that calls to the ring signature verification precompile are traced
+// with their ring size, gas and result.
+func TestRingVerifyCapture(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sig, err := ring.Sign([32]byte{1}, ring.GenNewKeyRing(3, key, 0), key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := ring.PrecompileInput(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		statedb, base = newShieldedEnv()
+		logger        = NewStructLogger(nil)
+		env           = NewEVM(base.Context, statedb, params.TestChainConfig, Config{Debug: true, Tracer: logger})
+		caller        = AccountRef(common.HexToAddress("1337"))
+	)
+	if _, _, err := env.Call(caller, RingVerifyAddress, input, 1000000, new(big.Int)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := env.Call(caller, RingVerifyAddress, input[:len(input)-1], 1000000, new(big.Int)); err != nil {
+		t.Fatal(err)
+	}
+	logs := logger.RingVerifyLogs()
+	if len(logs) != 2 {
+		t.Fatalf("ring verification count mismatch: have %d, want 2", len(logs))
+	}
+	cost := params.RingVerifyBaseGas + 3*params.RingVerifyPerMemberGas
+	if log := logs[0]; log.RingSize != 3 || !log.Valid || log.Gas != 1000000 || log.GasCost != cost || log.Depth != 1 {
+		t.Errorf("valid signature trace mismatch: have %+v", log)
+	}
+	if log := logs[1]; log.RingSize != 0 || log.Valid {
+		t.Errorf("truncated signature trace mismatch: have %+v", log)
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// The shielded pool holds ether in confidential outputs. An output is a
+// one-time stealth key and a Pedersen commitment to its amount, both on
+// secp256k1, and is appended as a leaf to an incremental Merkle tree kept in
+// the storage of the pool precompile.
+//
+// Deposits send ether to the pool and create an output committing to the
+// value sent with a public blinding factor. Spends are RingCT transactions
+// whose inputs hide the spent outputs among other leaves of the tree: the
+// pool checks that every ring member is a leaf, that the transaction balances
+// and its outputs are in range, and that the Triptych key images of its inputs
+// were never spent, marking them in the key image set. The plaintext fee of
+// the transaction is paid out of the pool to a recipient, which withdraws it.
+//
+// Every new output is announced with a log of the pool carrying its index as
+// second topic and the RLP encoded ShieldedOutput as data, for recipients to
+// scan with their view key.
+
+// ShieldedPoolAddress is the address of the shielded pool precompile.
+var ShieldedPoolAddress = common.BytesToAddress([]byte{11})
+
+// ShieldedOutputTopic is the first topic of the logs announcing outputs.
+var ShieldedOutputTopic = crypto.Keccak256Hash([]byte("ShieldedOutput(uint256,bytes)"))
+
+// ShieldedTreeDepth is the depth of the Merkle tree of outputs.
+const ShieldedTreeDepth = 32
+
+// Operations of the shielded pool, given by the first input byte.
+const (
+	ShieldedDepositOp byte = 0x00
+	ShieldedSpendOp   byte = 0x01
+)
+
+var (
+	errShieldedOp       = errors.New("unknown shielded pool operation")
+	errShieldedContext  = errors.New("shielded pool must be called directly")
+	errShieldedReadOnly = errors.New("shielded pool called in read-only context")
+	errShieldedDeposit  = errors.New("invalid shielded deposit")
+	errShieldedSpend    = errors.New("invalid shielded spend")
+	errShieldedMember   = errors.New("ring member is not a shielded output")
+	errShieldedSpent    = errors.New("shielded output already spent")
+	errShieldedFull     = errors.New("shielded output tree is full")
+)
+
+// ShieldedDeposit is the input of a deposit, following its operation byte.
+// The output commits to the value of the call.
+type ShieldedDeposit struct {
+	Key   []byte   // compressed one-time key of the output
+	Mask  *big.Int // blinding factor of the amount commitment
+	TxPub []byte   // compressed transaction key the recipient scans with
+	Note  []byte   // encrypted note for the recipient
+}
+
+// ShieldedSpend is the input of a spend, following its operation byte.
+type ShieldedSpend struct {
+	Tx        *ring.RingCTTransaction
+	Members   [][]uint64     // leaf indices of the ring members, one list per input
+	Recipient common.Address // receives the fee of the transaction
+	TxPub     []byte         // compressed transaction key the recipients scan with
+	Notes     [][]byte       // encrypted notes, none or one per output
+}
+
+// ShieldedOutput is the data of the log announcing an output.
+type ShieldedOutput struct {
+	Key        []byte // compressed one-time key
+	Commitment []byte // compressed amount commitment
+	TxPub      []byte
+	Note       []byte
+}
+
+// Storage layout of the pool: the number of leaves, the root, the rightmost
+// filled subtree of every level and the leaves by index, at the hash of the 32
+// byte index. The key images of spent outputs are kept apart from those of
+// ring transactions, at the hash of the 64 byte image like in the key image
+// set, which never collides with a leaf slot.
+var (
+	shieldedCountSlot = common.Hash{}
+	shieldedRootSlot  = common.BytesToHash([]byte{1})
+)
+
+func shieldedSubtreeSlot(level int) common.Hash {
+	return common.BigToHash(big.NewInt(int64(2 + level)))
+}
+
+func shieldedLeafSlot(index uint64) common.Hash {
+	return crypto.Keccak256Hash(common.BigToHash(new(big.Int).SetUint64(index)).Bytes())
+}
+
+// shieldedZeros holds the roots of empty subtrees by level.
+var shieldedZeros = func() [ShieldedTreeDepth + 1]common.Hash {
+	var zeros [ShieldedTreeDepth + 1]common.Hash
+	for i := 1; i <= ShieldedTreeDepth; i++ {
+		zeros[i] = crypto.Keccak256Hash(zeros[i-1][:], zeros[i-1][:])
+	}
+	return zeros
+}()
+
+// ShieldedLeaf returns the tree leaf of an output.
+func ShieldedLeaf(key, commitment *ecdsa.PublicKey) common.Hash {
+	return crypto.Keccak256Hash(ring.Ring{key, commitment}.Compress())
+}
+
+// ShieldedSpent reports whether the pool output with the given key image,
+// the 64 byte concatenation of its coordinates, has been spent in db.
+func ShieldedSpent(db StateDB, image []byte) bool {
+	_, spent := spentAt(db, ShieldedPoolAddress, image)
+	return spent
+}
+
+// ShieldedSpentAt returns the number of the block that spent the pool output
+// with the given key image, and whether one did.
+func ShieldedSpentAt(db StateDB, image []byte) (uint64, bool) {
+	return spentAt(db, ShieldedPoolAddress, image)
+}
+
+// MarkShieldedSpent records the key image of a pool output as spent in db by
+// the block with the given number.
+func MarkShieldedSpent(db StateDB, image []byte, number uint64) {
+	markSpent(db, ShieldedPoolAddress, image, number)
+}
+
+// ShieldedCount returns the number of outputs in the pool.
+func ShieldedCount(db StateDB) uint64 {
+	return db.GetState(ShieldedPoolAddress, shieldedCountSlot).Big().Uint64()
+}
+
+// ShieldedRoot returns the root of the tree of outputs.
+func ShieldedRoot(db StateDB) common.Hash {
+	if root := db.GetState(ShieldedPoolAddress, shieldedRootSlot); root != (common.Hash{}) {
+		return root
+	}
+	return shieldedZeros[ShieldedTreeDepth]
+}
+
+// ShieldedLeafAt returns the leaf with the given index, the zero hash if it
+// doesn't exist.
+func ShieldedLeafAt(db StateDB, index uint64) common.Hash {
+	return db.GetState(ShieldedPoolAddress, shieldedLeafSlot(index))
+}
+
+// appendShieldedLeaf inserts a leaf into the tree and returns its index.
+func appendShieldedLeaf(db StateDB, leaf common.Hash) (uint64, error) {
+	index := ShieldedCount(db)
+	if index >= 1<<ShieldedTreeDepth {
+		return 0, errShieldedFull
+	}
+	if db.GetNonce(ShieldedPoolAddress) == 0 {
+		db.SetNonce(ShieldedPoolAddress, 1)
+	}
+	db.SetState(ShieldedPoolAddress, shieldedLeafSlot(index), leaf)
+
+	node := leaf
+	for level, i := 0, index; level < ShieldedTreeDepth; level, i = level+1, i>>1 {
+		if i&1 == 0 {
+			db.SetState(ShieldedPoolAddress, shieldedSubtreeSlot(level), node)
+			node = crypto.Keccak256Hash(node[:], shieldedZeros[level][:])
+		} else {
+			left := db.GetState(ShieldedPoolAddress, shieldedSubtreeSlot(level))
+			node = crypto.Keccak256Hash(left[:], node[:])
+		}
+	}
+	db.SetState(ShieldedPoolAddress, shieldedRootSlot, node)
+	db.SetState(ShieldedPoolAddress, shieldedCountSlot, common.BigToHash(new(big.Int).SetUint64(index+1)))
+	return index, nil
+}
+
+// shieldedOutputCount returns the number of outputs of a spend without
+// decoding it, zero if the input is malformed.
+func shieldedOutputCount(input []byte) uint64 {
+	spend, _, err := rlp.SplitList(input)
+	if err != nil {
+		return 0
+	}
+	tx, _, err := rlp.SplitList(spend)
+	if err != nil {
+		return 0
+	}
+	// skip the curve name, inputs and pseudo outputs
+	for i := 0; i < 3; i++ {
+		if _, _, tx, err = rlp.Split(tx); err != nil {
+			return 0
+		}
+	}
+	outputs, _, err := rlp.SplitString(tx)
+	if err != nil {
+		return 0
+	}
+	return uint64(len(outputs) / ring.CompressedSize(crypto.S256()))
+}
+
+// ShieldedSpendKeyImages returns the key images spent by a call of the pool
+// with the given input, as 64 byte concatenations of their coordinates. It
+// returns nil for other operations and malformed spends.
+func ShieldedSpendKeyImages(input []byte) [][]byte {
+	if len(input) == 0 || input[0] != ShieldedSpendOp {
+		return nil
+	}
+	var sp ShieldedSpend
+	if err := rlp.DecodeBytes(input[1:], &sp); err != nil || sp.Tx == nil {
+		return nil
+	}
+	images := make([][]byte, 0, len(sp.Tx.Inputs))
+	for _, sig := range sp.Tx.Inputs {
+		if sig.I == nil {
+			return nil
+		}
+		images = append(images, append(common.LeftPadBytes(sig.I.X.Bytes(), 32), common.LeftPadBytes(sig.I.Y.Bytes(), 32)...))
+	}
+	return images
+}
+
+// shieldedPool implements the shielded pool operations.
+type shieldedPool struct {
+	evm      *EVM
+	contract *Contract
+	readOnly bool
+}
+
+func (c *shieldedPool) withContext(evm *EVM, contract *Contract, readOnly bool) PrecompiledContract {
+	return &shieldedPool{evm: evm, contract: contract, readOnly: readOnly}
+}
+
+func (c *shieldedPool) RequiredGas(input []byte) uint64 {
+	if len(input) == 0 {
+		return 0
+	}
+	switch input[0] {
+	case ShieldedDepositOp:
+		return params.ShieldedDepositGas
+	case ShieldedSpendOp:
+		return ShieldedSpendGas(uint64(len(input)), shieldedOutputCount(input[1:]))
+	}
+	return 0
+}
+
+// ShieldedSpendGas returns the gas charged for a spend with an input of size
+// bytes, including the operation byte, creating outputs outputs.
+func ShieldedSpendGas(size, outputs uint64) uint64 {
+	return params.ShieldedSpendBaseGas + size*params.ShieldedSpendPerByteGas + outputs*params.ShieldedOutputGas
+}
+
+// ShieldedSpendSize returns an upper bound of the input size of a spend, with
+// the operation byte, of inputs hidden in rings of ringSize members and paying
+// outputs outputs with notes, for estimating its cost before building it.
+func ShieldedSpendSize(ringSize, inputs, outputs int) (int, error) {
+	curve := crypto.S256()
+	txSize, err := ring.RingCTSize(curve, ringSize, inputs, outputs)
+	if err != nil {
+		return 0, err
+	}
+	members := make([][]uint64, inputs)
+	for i := range members {
+		members[i] = make([]uint64, ringSize)
+		for j := range members[i] {
+			members[i][j] = 1<<ShieldedTreeDepth - 1
+		}
+	}
+	notes := make([][]byte, outputs)
+	for i := range notes {
+		notes[i] = make([]byte, ring.NoteSize(curve))
+	}
+	// Only the size of the transaction matters, not its encoding
+	enc, err := rlp.EncodeToBytes(&struct {
+		Tx        rlp.RawValue
+		Members   [][]uint64
+		Recipient common.Address
+		TxPub     []byte
+		Notes     [][]byte
+	}{make(rlp.RawValue, txSize), members, common.Address{}, make([]byte, ring.CompressedSize(curve)), notes})
+	if err != nil {
+		return 0, err
+	}
+	return 1 + len(enc), nil
+}
+
+func (c *shieldedPool) Run(input []byte) ([]byte, error) {
+	if c.evm == nil || c.contract.Address() != ShieldedPoolAddress {
+		return nil, errShieldedContext
+	}
+	if c.readOnly {
+		return nil, errShieldedReadOnly
+	}
+	if len(input) == 0 {
+		return nil, errShieldedOp
+	}
+	var (
+		index uint64
+		err   error
+	)
+	switch input[0] {
+	case ShieldedDepositOp:
+		index, err = c.deposit(input[1:])
+	case ShieldedSpendOp:
+		index, err = c.spend(input[1:])
+	default:
+		return nil, errShieldedOp
+	}
+	if err != nil {
+		return nil, err
+	}
+	return common.BigToHash(new(big.Int).SetUint64(index)).Bytes(), nil
+}
+
+// deposit creates an output for the value of the call and returns its index.
+func (c *shieldedPool) deposit(input []byte) (uint64, error) {
+	var dep ShieldedDeposit
+	if err := rlp.DecodeBytes(input, &dep); err != nil {
+		return 0, errShieldedDeposit
+	}
+	curve := crypto.S256()
+	value := c.contract.value
+	if value == nil || value.Sign() <= 0 || value.BitLen() > 64 {
+		return 0, errShieldedDeposit
+	}
+	if dep.Mask == nil || dep.Mask.Cmp(curve.Params().N) >= 0 {
+		return 0, errShieldedDeposit
+	}
+	key, err := ring.DecompressRing(curve, dep.Key)
+	if err != nil || len(key) != 1 {
+		return 0, errShieldedDeposit
+	}
+	commitment := ring.Commit(curve, value, dep.Mask)
+	return c.output(key[0], commitment, dep.TxPub, dep.Note)
+}
+
+// spend verifies a RingCT transaction against the pool, appends its outputs
+// and pays its fee to the recipient. It returns the index of the first output.
+func (c *shieldedPool) spend(input []byte) (uint64, error) {
+	var sp ShieldedSpend
+	if err := rlp.DecodeBytes(input, &sp); err != nil {
+		return 0, errShieldedSpend
+	}
+	tx := sp.Tx
+	if tx == nil || tx.Curve != crypto.S256() || len(sp.Members) != len(tx.Inputs) {
+		return 0, errShieldedSpend
+	}
+	if len(sp.Notes) != 0 && len(sp.Notes) != len(tx.Outputs) {
+		return 0, errShieldedSpend
+	}
+	db := c.evm.StateDB
+
+	// Every ring member must be an output of the pool
+	count := ShieldedCount(db)
+	for i, sig := range tx.Inputs {
+		if len(sp.Members[i]) != len(sig.Ring) || len(sig.Commitments) != len(sig.Ring) {
+			return 0, errShieldedSpend
+		}
+		for j, index := range sp.Members[i] {
+			if index >= count || ShieldedLeafAt(db, index) != ShieldedLeaf(sig.Ring[j], sig.Commitments[j]) {
+				return 0, errShieldedMember
+			}
+		}
+	}
+	if !ring.VerifyRingCT(tx) {
+		return 0, errShieldedSpend
+	}
+	// Spend the key images, which are distinct within the transaction
+	number := c.evm.BlockNumber.Uint64()
+	for _, sig := range tx.Inputs {
+		image := append(common.LeftPadBytes(sig.I.X.Bytes(), 32), common.LeftPadBytes(sig.I.Y.Bytes(), 32)...)
+		if ShieldedSpent(db, image) {
+			return 0, errShieldedSpent
+		}
+		MarkShieldedSpent(db, image, number)
+	}
+	first := count
+	for i := range tx.Outputs {
+		var note []byte
+		if len(sp.Notes) != 0 {
+			note = sp.Notes[i]
+		}
+		if _, err := c.output(tx.Outputs[i], tx.Commitments[i], sp.TxPub, note); err != nil {
+			return 0, err
+		}
+	}
+	// Withdraw the fee, which the pool always holds as it covers every output
+	if tx.Fee > 0 {
+		fee := new(big.Int).SetUint64(tx.Fee)
+		if db.GetBalance(ShieldedPoolAddress).Cmp(fee) < 0 {
+			return 0, errShieldedSpend
+		}
+		db.SubBalance(ShieldedPoolAddress, fee)
+		db.AddBalance(sp.Recipient, fee)
+	}
+	return first, nil
+}
+
+// output appends an output to the tree and announces it.
+func (c *shieldedPool) output(key, commitment *ecdsa.PublicKey, txPub, note []byte) (uint64, error) {
+	db := c.evm.StateDB
+	index, err := appendShieldedLeaf(db, ShieldedLeaf(key, commitment))
+	if err != nil {
+		return 0, err
+	}
+	data, err := rlp.EncodeToBytes(&ShieldedOutput{
+		Key:        ring.Ring{key}.Compress(),
+		Commitment: ring.Ring{commitment}.Compress(),
+		TxPub:      txPub,
+		Note:       note,
+	})
+	if err != nil {
+		return 0, err
+	}
+	db.AddLog(&types.Log{
+		Address:     ShieldedPoolAddress,
+		Topics:      []common.Hash{ShieldedOutputTopic, common.BigToHash(new(big.Int).SetUint64(index))},
+		Data:        data,
+		BlockNumber: c.evm.BlockNumber.Uint64(),
+	})
+	return index, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// shieldedNote is an output of the pool known to the test.
+type shieldedNote struct {
+	key    *ecdsa.PrivateKey
+	amount uint64
+	mask   *big.Int
+}
+
+func newShieldedEnv() (*state.StateDB, *EVM) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	ctx := Context{
+		CanTransfer: func(db StateDB, addr common.Address, amount *big.Int) bool {
+			return db.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer: func(db StateDB, from, to common.Address, amount *big.Int) {
+			db.SubBalance(from, amount)
+			db.AddBalance(to, amount)
+		},
+		BlockNumber: big.NewInt(7),
+	}
+	return statedb, NewEVM(ctx, statedb, params.TestChainConfig, Config{})
+}
+
+func shieldedDeposit(t *testing.T, env *EVM, from common.Address, amount uint64) *shieldedNote {
+	key, _ := crypto.GenerateKey()
+	note := &shieldedNote{key: key, amount: amount, mask: big.NewInt(int64(amount) * 31)}
+	input, err := rlp.EncodeToBytes(&ShieldedDeposit{Key: ring.Ring{&key.PublicKey}.Compress(), Mask: note.mask})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := new(big.Int).SetUint64(amount)
+	if _, _, err := env.Call(AccountRef(from), ShieldedPoolAddress, append([]byte{ShieldedDepositOp}, input...), 1000000, value); err != nil {
+		t.Fatalf("deposit failed: %v", err)
+	}
+	return note
+}
+
+// shieldedSpendInput spends the note at index of the pool, hidden among all
+// notes, into a single output and the fee.
+func shieldedSpendInput(t *testing.T, notes []*shieldedNote, index int, fee uint64, recipient common.Address) []byte {
+	curve := crypto.S256()
+	in := &ring.RingCTInput{
+		Key:    notes[index].key,
+		Amount: notes[index].amount,
+		Mask:   notes[index].mask,
+		Index:  index,
+	}
+	members := make([]uint64, len(notes))
+	for i, note := range notes {
+		in.Ring = append(in.Ring, &note.key.PublicKey)
+		in.Commitments = append(in.Commitments, ring.Commit(curve, new(big.Int).SetUint64(note.amount), note.mask))
+		members[i] = uint64(i)
+	}
+	out, _ := crypto.GenerateKey()
+	tx, _, err := ring.BuildRingCT([]*ring.RingCTInput{in}, []*ring.RingCTOutput{{Key: &out.PublicKey, Amount: notes[index].amount - fee}}, fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := rlp.EncodeToBytes(&ShieldedSpend{Tx: tx, Members: [][]uint64{members}, Recipient: recipient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append([]byte{ShieldedSpendOp}, input...)
+}
+
+func TestShieldedPool(t *testing.T) {
+	statedb, env := newShieldedEnv()
+	var (
+		from      = common.HexToAddress("1337")
+		recipient = common.HexToAddress("beef")
+	)
+	statedb.AddBalance(from, big.NewInt(1000))
+
+	var notes []*shieldedNote
+	for _, amount := range []uint64{10, 60, 20, 30} {
+		notes = append(notes, shieldedDeposit(t, env, from, amount))
+	}
+	if n := ShieldedCount(statedb); n != 4 {
+		t.Fatalf("output count: have %d, want 4", n)
+	}
+	if have := statedb.GetBalance(ShieldedPoolAddress); have.Cmp(big.NewInt(120)) != 0 {
+		t.Fatalf("pool balance: have %v, want 120", have)
+	}
+
+	// Spend the second deposit, withdrawing part of it as fee
+	input := shieldedSpendInput(t, notes, 1, 25, recipient)
+	if gas := (&shieldedPool{}).RequiredGas(input); gas != params.ShieldedSpendBaseGas+uint64(len(input))*params.ShieldedSpendPerByteGas+params.ShieldedOutputGas {
+		t.Errorf("spend gas: have %d", gas)
+	}
+	if size, err := ShieldedSpendSize(len(notes), 1, 1); err != nil || size < len(input) {
+		t.Errorf("spend size estimate: have %d (error %v), encoded %d", size, err, len(input))
+	}
+	ret, _, err := env.Call(AccountRef(from), ShieldedPoolAddress, input, 10000000, new(big.Int))
+	if err != nil {
+		t.Fatalf("spend failed: %v", err)
+	}
+	if index := new(big.Int).SetBytes(ret); index.Uint64() != 4 {
+		t.Errorf("first output index: have %v, want 4", index)
+	}
+	if have := statedb.GetBalance(recipient); have.Cmp(big.NewInt(25)) != 0 {
+		t.Errorf("withdrawn: have %v, want 25", have)
+	}
+	if have := statedb.GetBalance(ShieldedPoolAddress); have.Cmp(big.NewInt(95)) != 0 {
+		t.Errorf("pool balance: have %v, want 95", have)
+	}
+	if n := ShieldedCount(statedb); n != 5 {
+		t.Errorf("output count: have %d, want 5", n)
+	}
+	logs := statedb.Logs()
+	if len(logs) != 5 || logs[4].Topics[0] != ShieldedOutputTopic || logs[4].Topics[1] != common.BigToHash(big.NewInt(4)) {
+		t.Fatalf("missing output log: %v", logs)
+	}
+	var output ShieldedOutput
+	if err := rlp.DecodeBytes(logs[4].Data, &output); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := ring.DecompressRing(crypto.S256(), output.Key)
+	commitment, _ := ring.DecompressRing(crypto.S256(), output.Commitment)
+	if ShieldedLeaf(key[0], commitment[0]) != ShieldedLeafAt(statedb, 4) {
+		t.Error("logged output doesn't match its leaf")
+	}
+
+	// A second spend of the same note is rejected
+	again := shieldedSpendInput(t, notes, 1, 5, recipient)
+	if _, _, err := env.Call(AccountRef(from), ShieldedPoolAddress, again, 10000000, new(big.Int)); err != errShieldedSpent {
+		t.Errorf("double spend: have %v, want %v", err, errShieldedSpent)
+	}
+	if n := ShieldedCount(statedb); n != 5 {
+		t.Errorf("output count after failed spend: have %d, want 5", n)
+	}
+}
+
+// Tests that spent outputs are kept in a set of their own, apart from the key
+// images of ring transactions, and stay spent in later blocks.
+func TestShieldedSpentSet(t *testing.T) {
+	statedb, env := newShieldedEnv()
+	var (
+		from      = common.HexToAddress("1337")
+		recipient = common.HexToAddress("beef")
+	)
+	statedb.AddBalance(from, big.NewInt(1000))
+
+	var notes []*shieldedNote
+	for _, amount := range []uint64{10, 60, 20} {
+		notes = append(notes, shieldedDeposit(t, env, from, amount))
+	}
+	if _, _, err := env.Call(AccountRef(from), ShieldedPoolAddress, shieldedSpendInput(t, notes, 1, 5, recipient), 10000000, new(big.Int)); err != nil {
+		t.Fatalf("spend failed: %v", err)
+	}
+	image := ring.TriptychKeyImage(notes[1].key)
+	enc := append(common.LeftPadBytes(image.X.Bytes(), 32), common.LeftPadBytes(image.Y.Bytes(), 32)...)
+	if number, ok := ShieldedSpentAt(statedb, enc); !ok || number != 7 {
+		t.Fatalf("spent at: have %d %v, want 7 true", number, ok)
+	}
+	if KeyImageSeen(statedb, enc) {
+		t.Error("shielded spend marked in the ring key image set")
+	}
+
+	// Move far ahead, across the EIP-158 cleanup of every block
+	for i := 0; i < 1024; i++ {
+		statedb.Finalise(true)
+		env.BlockNumber.Add(env.BlockNumber, common.Big1)
+	}
+	if _, _, err := env.Call(AccountRef(from), ShieldedPoolAddress, shieldedSpendInput(t, notes, 1, 5, recipient), 10000000, new(big.Int)); err != errShieldedSpent {
+		t.Errorf("late double spend: have %v, want %v", err, errShieldedSpent)
+	}
+}
+
+func TestShieldedPoolInvalid(t *testing.T) {
+	statedb, env := newShieldedEnv()
+	from := common.HexToAddress("1337")
+	statedb.AddBalance(from, big.NewInt(1000))
+
+	var notes []*shieldedNote
+	for _, amount := range []uint64{10, 20} {
+		notes = append(notes, shieldedDeposit(t, env, from, amount))
+	}
+	// Ring members must be outputs of the pool
+	key, _ := crypto.GenerateKey()
+	forged := append([]*shieldedNote{}, notes...)
+	forged[0] = &shieldedNote{key: key, amount: 1000, mask: big.NewInt(1)}
+	input := shieldedSpendInput(t, forged, 0, 1000, from)
+	if _, _, err := env.Call(AccountRef(from), ShieldedPoolAddress, input, 10000000, new(big.Int)); err != errShieldedMember {
+		t.Errorf("forged member: have %v, want %v", err, errShieldedMember)
+	}
+	// Deposits need value and state changes aren't allowed in static calls
+	deposit, _ := rlp.EncodeToBytes(&ShieldedDeposit{Key: ring.Ring{&key.PublicKey}.Compress(), Mask: big.NewInt(1)})
+	deposit = append([]byte{ShieldedDepositOp}, deposit...)
+	if _, _, err := env.Call(AccountRef(from), ShieldedPoolAddress, deposit, 1000000, new(big.Int)); err != errShieldedDeposit {
+		t.Errorf("empty deposit: have %v, want %v", err, errShieldedDeposit)
+	}
+	if _, _, err := env.StaticCall(AccountRef(from), ShieldedPoolAddress, deposit, 1000000); err != errShieldedReadOnly {
+		t.Errorf("static deposit: have %v, want %v", err, errShieldedReadOnly)
+	}
+	if _, _, err := env.Call(AccountRef(from), ShieldedPoolAddress, []byte{0x02}, 1000000, new(big.Int)); err != errShieldedOp {
+		t.Errorf("unknown operation: have %v, want %v", err, errShieldedOp)
+	}
+	if n := ShieldedCount(statedb); n != 2 {
+		t.Errorf("output count: have %d, want 2", n)
+	}
+}
+
+func TestShieldedTreeRoot(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	if ShieldedRoot(statedb) != shieldedZeros[ShieldedTreeDepth] {
+		t.Fatal("empty tree root mismatch")
+	}
+	var leaves []common.Hash
+	for i := 0; i < 5; i++ {
+		leaf := crypto.Keccak256Hash([]byte{byte(i)})
+		if index, err := appendShieldedLeaf(statedb, leaf); err != nil || index != uint64(i) {
+			t.Fatalf("leaf %d: index %d, error %v", i, index, err)
+		}
+		leaves = append(leaves, leaf)
+
+		// Recompute the root from all leaves, padding every level with zeros
+		level := append([]common.Hash{}, leaves...)
+		for depth := 0; depth < ShieldedTreeDepth; depth++ {
+			if len(level)%2 == 1 {
+				level = append(level, shieldedZeros[depth])
+			}
+			next := make([]common.Hash, len(level)/2)
+			for j := range next {
+				next[j] = crypto.Keccak256Hash(level[2*j][:], level[2*j+1][:])
+			}
+			level = next
+		}
+		if root := ShieldedRoot(statedb); root != level[0] {
+			t.Errorf("%d leaves: root %x, want %x", i+1, root, level[0])
+		}
+	}
+}
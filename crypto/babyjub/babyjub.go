@@ -0,0 +1,297 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package babyjub implements the prime order subgroup of the BabyJubjub
+// twisted Edwards curve behind the elliptic.Curve interface.
+//
+// BabyJubjub is defined over the scalar field of BN254, so its arithmetic can
+// be expressed natively in circuits over that curve. The parameters and the
+// base point match circomlib. Points are affine (x, y) pairs with the neutral
+// element (0, 1).
+//
+// The implementation uses math/big and is not constant time.
+package babyjub
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+var errInvalidPoint = errors.New("invalid BabyJubjub point encoding")
+
+func fromDecimal(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid constant " + s)
+	}
+	return n
+}
+
+var (
+	fieldP   = fromDecimal("21888242871839275222246405745257275088548364400416034343698204186575808495617")
+	groupN   = fromDecimal("2736030358979909402780800718157159386076813972158567259200215660948447373041")
+	curveA   = big.NewInt(168700)
+	curveD   = big.NewInt(168696)
+	baseX    = fromDecimal("5299619240641551281634865583518297030282874472190772894086521144482721001553")
+	baseY    = fromDecimal("16950150798460657717958625567821834550301663161624707787222815936182638968203")
+	cofactor = big.NewInt(8)
+	halfP    = new(big.Int).Rsh(fieldP, 1)
+	bigOne   = big.NewInt(1)
+)
+
+// Curve is the prime order subgroup of BabyJubjub.
+type Curve struct {
+	params *elliptic.CurveParams
+}
+
+var theCurve = &Curve{
+	params: &elliptic.CurveParams{
+		Name:    "BabyJubjub",
+		P:       fieldP,
+		N:       groupN,
+		B:       curveD,
+		Gx:      baseX,
+		Gy:      baseY,
+		BitSize: 254,
+	},
+}
+
+// BabyJub returns the BabyJubjub curve.
+func BabyJub() *Curve {
+	return theCurve
+}
+
+// Params returns the parameters of the curve. Since the curve is not in short
+// Weierstrass form, B holds the twisted Edwards coefficient d.
+func (c *Curve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+func feMod(a *big.Int) *big.Int { return a.Mod(a, fieldP) }
+
+func feMul(a, b *big.Int) *big.Int { return feMod(new(big.Int).Mul(a, b)) }
+
+// projective point (X:Y:Z) representing (X/Z, Y/Z).
+type projective struct {
+	x, y, z *big.Int
+}
+
+func toProjective(x, y *big.Int) *projective {
+	if x == nil || y == nil {
+		return &projective{new(big.Int), big.NewInt(1), big.NewInt(1)}
+	}
+	return &projective{new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1)}
+}
+
+func (p *projective) affine() (*big.Int, *big.Int) {
+	zinv := new(big.Int).ModInverse(p.z, fieldP)
+	return feMul(p.x, zinv), feMul(p.y, zinv)
+}
+
+// add returns p+q using the complete add-2008-bbjlp formulas, which also
+// cover doubling and the neutral element.
+func (p *projective) add(q *projective) *projective {
+	a := feMul(p.z, q.z)
+	b := feMul(a, a)
+	c := feMul(p.x, q.x)
+	d := feMul(p.y, q.y)
+	e := feMul(feMul(curveD, c), d)
+	f := feMod(new(big.Int).Sub(b, e))
+	g := feMod(new(big.Int).Add(b, e))
+
+	t := feMul(new(big.Int).Add(p.x, p.y), new(big.Int).Add(q.x, q.y))
+	t.Sub(t, c)
+	t.Sub(t, d)
+	x3 := feMul(feMul(a, f), t)
+
+	u := new(big.Int).Sub(d, feMul(curveA, c))
+	y3 := feMul(feMul(a, g), u)
+
+	return &projective{x3, y3, feMul(f, g)}
+}
+
+func (c *Curve) mul(x, y, k *big.Int) (*big.Int, *big.Int) {
+	p := toProjective(x, y)
+	acc := toProjective(nil, nil)
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = acc.add(acc)
+		if k.Bit(i) == 1 {
+			acc = acc.add(p)
+		}
+	}
+	return acc.affine()
+}
+
+// isOnCurve checks the curve equation a*x^2 + y^2 = 1 + d*x^2*y^2 only.
+func isOnCurve(x, y *big.Int) bool {
+	if x.Sign() < 0 || y.Sign() < 0 || x.Cmp(fieldP) >= 0 || y.Cmp(fieldP) >= 0 {
+		return false
+	}
+	xx, yy := feMul(x, x), feMul(y, y)
+	lhs := feMod(new(big.Int).Add(feMul(curveA, xx), yy))
+	rhs := feMod(new(big.Int).Add(bigOne, feMul(curveD, feMul(xx, yy))))
+	return lhs.Cmp(rhs) == 0
+}
+
+// IsOnCurve reports whether (x, y) is a point of the prime order subgroup.
+func (c *Curve) IsOnCurve(x, y *big.Int) bool {
+	if x == nil || y == nil || !isOnCurve(x, y) {
+		return false
+	}
+	ox, oy := c.mul(x, y, groupN)
+	return c.IsIdentity(ox, oy)
+}
+
+// Add returns the sum of (x1, y1) and (x2, y2).
+func (c *Curve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	return toProjective(x1, y1).add(toProjective(x2, y2)).affine()
+}
+
+// Double returns 2*(x, y).
+func (c *Curve) Double(x, y *big.Int) (*big.Int, *big.Int) {
+	p := toProjective(x, y)
+	return p.add(p).affine()
+}
+
+// ScalarMult returns k*(x, y) where k is a number in big-endian form.
+func (c *Curve) ScalarMult(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	return c.mul(x, y, new(big.Int).SetBytes(k))
+}
+
+// ScalarBaseMult returns k*G where G is the base point of the subgroup and k
+// is a number in big-endian form.
+func (c *Curve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.mul(baseX, baseY, new(big.Int).SetBytes(k))
+}
+
+// Neg returns -(x, y).
+func (c *Curve) Neg(x, y *big.Int) (*big.Int, *big.Int) {
+	if x == nil || y == nil {
+		return new(big.Int), big.NewInt(1)
+	}
+	return feMod(new(big.Int).Neg(x)), new(big.Int).Set(y)
+}
+
+// IsIdentity reports whether (x, y) is the neutral element (0, 1).
+func (c *Curve) IsIdentity(x, y *big.Int) bool {
+	if x == nil || y == nil {
+		return true
+	}
+	return x.Sign() == 0 && y.Cmp(bigOne) == 0
+}
+
+// recoverX returns the x coordinate of the point with the given y whose sign
+// matches, or nil if there is none.
+func recoverX(y *big.Int, sign bool) *big.Int {
+	// x^2 = (1 - y^2) / (a - d*y^2)
+	yy := feMul(y, y)
+	num := feMod(new(big.Int).Sub(bigOne, yy))
+	den := feMod(new(big.Int).Sub(curveA, feMul(curveD, yy)))
+	if den.Sign() == 0 {
+		return nil
+	}
+	x := new(big.Int).ModSqrt(feMul(num, new(big.Int).ModInverse(den, fieldP)), fieldP)
+	if x == nil {
+		return nil
+	}
+	if (x.Cmp(halfP) > 0) != sign {
+		x = feMod(x.Neg(x))
+	}
+	return x
+}
+
+// HashToPoint maps data to a point of the prime order subgroup with unknown
+// discrete logarithm, using try-and-increment with SHA-256 on the y
+// coordinate followed by cofactor clearing.
+func (c *Curve) HashToPoint(data []byte) (*big.Int, *big.Int) {
+	var ctr [8]byte
+	for i := uint64(0); ; i++ {
+		binary.BigEndian.PutUint64(ctr[:], i)
+		h := sha256.Sum256(append(ctr[:], data...))
+
+		y := feMod(new(big.Int).SetBytes(h[:]))
+		x := recoverX(y, false)
+		if x == nil {
+			continue
+		}
+		if px, py := c.mul(x, y, cofactor); !c.IsIdentity(px, py) {
+			return px, py
+		}
+	}
+}
+
+// Marshal returns the 32 byte circomlib packing of a point: y in little-endian
+// order with the most significant bit set if x is larger than (p-1)/2.
+func (c *Curve) Marshal(x, y *big.Int) []byte {
+	out := make([]byte, 32)
+	if x == nil || y == nil {
+		x, y = c.Neg(nil, nil)
+	}
+	y.FillBytes(out)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	if x.Cmp(halfP) > 0 {
+		out[31] |= 0x80
+	}
+	return out
+}
+
+// Unmarshal parses a packed point and checks that it is a member of the prime
+// order subgroup.
+func (c *Curve) Unmarshal(b []byte) (*big.Int, *big.Int, error) {
+	if len(b) != 32 {
+		return nil, nil, errInvalidPoint
+	}
+	buf := make([]byte, 32)
+	for i := range buf {
+		buf[i] = b[31-i]
+	}
+	sign := buf[0]&0x80 != 0
+	buf[0] &= 0x7f
+
+	y := new(big.Int).SetBytes(buf)
+	if y.Cmp(fieldP) >= 0 {
+		return nil, nil, errInvalidPoint
+	}
+	x := recoverX(y, sign)
+	if x == nil || (x.Sign() == 0 && sign) || !c.IsOnCurve(x, y) {
+		return nil, nil, errInvalidPoint
+	}
+	return x, y, nil
+}
+
+// UnmarshalPubkey parses a packed BabyJubjub public key.
+func UnmarshalPubkey(b []byte) (*ecdsa.PublicKey, error) {
+	x, y, err := theCurve.Unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	if theCurve.IsIdentity(x, y) {
+		return nil, errInvalidPoint
+	}
+	return &ecdsa.PublicKey{Curve: theCurve, X: x, Y: y}, nil
+}
+
+// GenerateKey creates a new random private key on the prime order subgroup.
+func GenerateKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(theCurve, rand.Reader)
+}
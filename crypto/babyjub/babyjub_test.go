@@ -0,0 +1,86 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package babyjub
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBasePoint(t *testing.T) {
+	c := BabyJub()
+	if !isOnCurve(baseX, baseY) {
+		t.Fatal("base point not on curve")
+	}
+	if !c.IsOnCurve(baseX, baseY) {
+		t.Fatal("base point not in subgroup")
+	}
+	if x, y := c.ScalarBaseMult(groupN.Bytes()); !c.IsIdentity(x, y) {
+		t.Error("N*G is not the neutral element")
+	}
+}
+
+func TestGroupLaw(t *testing.T) {
+	c := BabyJub()
+	a, b := big.NewInt(31337), big.NewInt(4242)
+
+	ax, ay := c.ScalarBaseMult(a.Bytes())
+	bx, by := c.ScalarBaseMult(b.Bytes())
+	sx, sy := c.Add(ax, ay, bx, by)
+	wx, wy := c.ScalarBaseMult(new(big.Int).Add(a, b).Bytes())
+	if sx.Cmp(wx) != 0 || sy.Cmp(wy) != 0 {
+		t.Error("a*G + b*G != (a+b)*G")
+	}
+	dx, dy := c.Double(ax, ay)
+	ex, ey := c.Add(ax, ay, ax, ay)
+	if dx.Cmp(ex) != 0 || dy.Cmp(ey) != 0 {
+		t.Error("doubling mismatch")
+	}
+	nx, ny := c.Neg(ax, ay)
+	if zx, zy := c.Add(ax, ay, nx, ny); !c.IsIdentity(zx, zy) {
+		t.Error("P + -P is not the neutral element")
+	}
+	for _, x := range []*big.Int{ax, nx} {
+		px, py, err := c.Unmarshal(c.Marshal(x, ay))
+		if err != nil || px.Cmp(x) != 0 || py.Cmp(ay) != 0 {
+			t.Errorf("round trip failed: %v", err)
+		}
+	}
+}
+
+func TestHashToPoint(t *testing.T) {
+	c := BabyJub()
+	x, y := c.HashToPoint([]byte("test"))
+	if !c.IsOnCurve(x, y) || c.IsIdentity(x, y) {
+		t.Fatal("hashed point not in subgroup")
+	}
+}
+
+func TestInvalidEncoding(t *testing.T) {
+	c := BabyJub()
+	// a curve point of small order lies outside the prime order subgroup
+	low := c.Marshal(new(big.Int), new(big.Int).Sub(fieldP, bigOne))
+	if _, _, err := c.Unmarshal(low); err == nil {
+		t.Error("point of order two accepted")
+	}
+	if _, _, err := c.Unmarshal(make([]byte, 31)); err == nil {
+		t.Error("short encoding accepted")
+	}
+	if _, err := UnmarshalPubkey(c.Marshal(nil, nil)); err == nil {
+		t.Error("neutral element accepted as public key")
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bls12381 implements the G1 group of the BLS12-381 pairing-friendly
+// curve behind the elliptic.Curve interface.
+//
+// G1 is the curve y^2 = x^3 + 4 over a 381 bit prime field. Points are handled
+// in affine coordinates with the point at infinity represented by nil
+// coordinates. Only the prime order subgroup is exposed: hashing to the curve
+// clears the cofactor and decoding rejects points outside the subgroup.
+//
+// The implementation uses math/big and is not constant time. Pairings are not
+// implemented.
+package bls12381
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+var (
+	errInvalidEncoding = errors.New("invalid BLS12-381 G1 encoding")
+	errNotInSubgroup   = errors.New("BLS12-381 G1 point not in prime order subgroup")
+	errInvalidKey      = errors.New("invalid BLS12-381 secret key")
+)
+
+func fromHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid constant " + s)
+	}
+	return n
+}
+
+var (
+	fieldP   = fromHex("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab")
+	groupN   = fromHex("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+	cofactor = fromHex("396c8c005555e1568c00aaab0000aaab")
+	curveB   = big.NewInt(4)
+	baseX    = fromHex("17f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb")
+	baseY    = fromHex("08b3f481e3aaa0f1a09e30ed741d8ae4fcf5e095d5d00af600db18cb2c04b3edd03cc744a2888ae40caa232946c5e7e1")
+	halfP    = new(big.Int).Rsh(fieldP, 1)
+)
+
+// G1Curve is the G1 group of BLS12-381.
+type G1Curve struct {
+	params *elliptic.CurveParams
+}
+
+var theCurve = &G1Curve{
+	params: &elliptic.CurveParams{
+		Name:    "BLS12-381 G1",
+		P:       fieldP,
+		N:       groupN,
+		B:       curveB,
+		Gx:      baseX,
+		Gy:      baseY,
+		BitSize: 381,
+	},
+}
+
+// G1 returns the G1 group of BLS12-381.
+func G1() *G1Curve {
+	return theCurve
+}
+
+// Params returns the parameters of the curve.
+func (c *G1Curve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+func fpMod(a *big.Int) *big.Int { return a.Mod(a, fieldP) }
+
+// rhs returns x^3 + 4.
+func rhs(x *big.Int) *big.Int {
+	r := new(big.Int).Exp(x, big.NewInt(3), fieldP)
+	return fpMod(r.Add(r, curveB))
+}
+
+// isOnCurve checks the curve equation only.
+func isOnCurve(x, y *big.Int) bool {
+	if x.Sign() < 0 || y.Sign() < 0 || x.Cmp(fieldP) >= 0 || y.Cmp(fieldP) >= 0 {
+		return false
+	}
+	yy := fpMod(new(big.Int).Mul(y, y))
+	return yy.Cmp(rhs(x)) == 0
+}
+
+// IsOnCurve reports whether (x, y) is a point of the prime order subgroup.
+func (c *G1Curve) IsOnCurve(x, y *big.Int) bool {
+	if x == nil || y == nil || !isOnCurve(x, y) {
+		return false
+	}
+	return c.inSubgroup(x, y)
+}
+
+func (c *G1Curve) inSubgroup(x, y *big.Int) bool {
+	ox, _ := c.mul(x, y, groupN)
+	return ox == nil
+}
+
+// jacobian point (X, Y, Z) representing (X/Z^2, Y/Z^3), Z = 0 is infinity.
+type jacobian struct {
+	x, y, z *big.Int
+}
+
+func toJacobian(x, y *big.Int) *jacobian {
+	if x == nil || y == nil {
+		return &jacobian{new(big.Int), new(big.Int), new(big.Int)}
+	}
+	return &jacobian{new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1)}
+}
+
+func (p *jacobian) affine() (*big.Int, *big.Int) {
+	if p.z.Sign() == 0 {
+		return nil, nil
+	}
+	zinv := new(big.Int).ModInverse(p.z, fieldP)
+	zinv2 := fpMod(new(big.Int).Mul(zinv, zinv))
+	x := fpMod(new(big.Int).Mul(p.x, zinv2))
+	y := fpMod(new(big.Int).Mul(p.y, fpMod(zinv2.Mul(zinv2, zinv))))
+	return x, y
+}
+
+// double returns 2p (dbl-2009-l for a = 0).
+func (p *jacobian) double() *jacobian {
+	if p.z.Sign() == 0 || p.y.Sign() == 0 {
+		return toJacobian(nil, nil)
+	}
+	a := fpMod(new(big.Int).Mul(p.x, p.x))
+	b := fpMod(new(big.Int).Mul(p.y, p.y))
+	c := fpMod(new(big.Int).Mul(b, b))
+
+	d := new(big.Int).Add(p.x, b)
+	d.Mul(d, d)
+	d.Sub(d, a)
+	d.Sub(d, c)
+	d.Lsh(d, 1)
+	fpMod(d)
+
+	e := fpMod(new(big.Int).Mul(a, big.NewInt(3)))
+	f := fpMod(new(big.Int).Mul(e, e))
+
+	x3 := new(big.Int).Sub(f, new(big.Int).Lsh(d, 1))
+	fpMod(x3)
+	y3 := new(big.Int).Sub(d, x3)
+	y3.Mul(y3, e)
+	y3.Sub(y3, new(big.Int).Lsh(c, 3))
+	fpMod(y3)
+	z3 := new(big.Int).Mul(p.y, p.z)
+	z3.Lsh(z3, 1)
+	fpMod(z3)
+
+	return &jacobian{x3, y3, z3}
+}
+
+// add returns p+q (add-2007-bl), handling infinity and doubling.
+func (p *jacobian) add(q *jacobian) *jacobian {
+	if p.z.Sign() == 0 {
+		return q
+	}
+	if q.z.Sign() == 0 {
+		return p
+	}
+	z1z1 := fpMod(new(big.Int).Mul(p.z, p.z))
+	z2z2 := fpMod(new(big.Int).Mul(q.z, q.z))
+	u1 := fpMod(new(big.Int).Mul(p.x, z2z2))
+	u2 := fpMod(new(big.Int).Mul(q.x, z1z1))
+	s1 := fpMod(new(big.Int).Mul(p.y, fpMod(new(big.Int).Mul(q.z, z2z2))))
+	s2 := fpMod(new(big.Int).Mul(q.y, fpMod(new(big.Int).Mul(p.z, z1z1))))
+
+	if u1.Cmp(u2) == 0 {
+		if s1.Cmp(s2) == 0 {
+			return p.double()
+		}
+		return toJacobian(nil, nil)
+	}
+	h := fpMod(new(big.Int).Sub(u2, u1))
+	i := new(big.Int).Lsh(h, 1)
+	i = fpMod(i.Mul(i, i))
+	j := fpMod(new(big.Int).Mul(h, i))
+	r := fpMod(new(big.Int).Lsh(new(big.Int).Sub(s2, s1), 1))
+	v := fpMod(new(big.Int).Mul(u1, i))
+
+	x3 := new(big.Int).Mul(r, r)
+	x3.Sub(x3, j)
+	x3.Sub(x3, new(big.Int).Lsh(v, 1))
+	fpMod(x3)
+
+	y3 := new(big.Int).Sub(v, x3)
+	y3.Mul(y3, r)
+	y3.Sub(y3, new(big.Int).Lsh(new(big.Int).Mul(s1, j), 1))
+	fpMod(y3)
+
+	z3 := new(big.Int).Add(p.z, q.z)
+	z3.Mul(z3, z3)
+	z3.Sub(z3, z1z1)
+	z3.Sub(z3, z2z2)
+	z3.Mul(z3, h)
+	fpMod(z3)
+
+	return &jacobian{x3, y3, z3}
+}
+
+// mul returns k*(x, y) using double-and-add.
+func (c *G1Curve) mul(x, y, k *big.Int) (*big.Int, *big.Int) {
+	p := toJacobian(x, y)
+	acc := toJacobian(nil, nil)
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = acc.double()
+		if k.Bit(i) == 1 {
+			acc = acc.add(p)
+		}
+	}
+	return acc.affine()
+}
+
+// Add returns the sum of (x1, y1) and (x2, y2).
+func (c *G1Curve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	return toJacobian(x1, y1).add(toJacobian(x2, y2)).affine()
+}
+
+// Double returns 2*(x, y).
+func (c *G1Curve) Double(x, y *big.Int) (*big.Int, *big.Int) {
+	return toJacobian(x, y).double().affine()
+}
+
+// ScalarMult returns k*(x, y) where k is a number in big-endian form.
+func (c *G1Curve) ScalarMult(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	return c.mul(x, y, new(big.Int).SetBytes(k))
+}
+
+// ScalarBaseMult returns k*G where G is the generator of G1 and k is a number
+// in big-endian form.
+func (c *G1Curve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.mul(baseX, baseY, new(big.Int).SetBytes(k))
+}
+
+// Neg returns -(x, y).
+func (c *G1Curve) Neg(x, y *big.Int) (*big.Int, *big.Int) {
+	if x == nil || y == nil {
+		return nil, nil
+	}
+	return new(big.Int).Set(x), fpMod(new(big.Int).Sub(fieldP, y))
+}
+
+// IsIdentity reports whether (x, y) is the point at infinity. Since infinity
+// is represented by nil coordinates, this only holds for nil.
+func (c *G1Curve) IsIdentity(x, y *big.Int) bool {
+	return x == nil || y == nil
+}
+
+// HashToPoint maps data to a point of the prime order subgroup with unknown
+// discrete logarithm, using try-and-increment with SHA-256 followed by
+// cofactor clearing.
+func (c *G1Curve) HashToPoint(data []byte) (*big.Int, *big.Int) {
+	var ctr [8]byte
+	for i := uint64(0); ; i++ {
+		binary.BigEndian.PutUint64(ctr[:], i)
+
+		// two hash blocks give 512 bits, reduced to a field element
+		h0 := sha256.Sum256(append(append([]byte{0}, ctr[:]...), data...))
+		h1 := sha256.Sum256(append(append([]byte{1}, ctr[:]...), data...))
+		x := new(big.Int).SetBytes(append(h0[:], h1[:]...))
+		fpMod(x)
+
+		y := new(big.Int).ModSqrt(rhs(x), fieldP)
+		if y == nil {
+			continue
+		}
+		if y.Cmp(halfP) > 0 {
+			y.Sub(fieldP, y)
+		}
+		px, py := c.mul(x, y, cofactor)
+		if px != nil {
+			return px, py
+		}
+	}
+}
+
+// Marshal returns the 48 byte compressed encoding of a point, following the
+// ZCash serialization format used by BLS12-381 libraries: the three most
+// significant bits flag compression, infinity and the larger y coordinate.
+func (c *G1Curve) Marshal(x, y *big.Int) []byte {
+	out := make([]byte, 48)
+	if x == nil || y == nil {
+		out[0] = 0xc0
+		return out
+	}
+	x.FillBytes(out)
+	out[0] |= 0x80
+	if y.Cmp(halfP) > 0 {
+		out[0] |= 0x20
+	}
+	return out
+}
+
+// Unmarshal parses a 48 byte compressed point and checks that it is a member
+// of the prime order subgroup. The point at infinity is rejected.
+func (c *G1Curve) Unmarshal(b []byte) (*big.Int, *big.Int, error) {
+	if len(b) != 48 || b[0]&0x80 == 0 || b[0]&0x40 != 0 {
+		return nil, nil, errInvalidEncoding
+	}
+	buf := make([]byte, 48)
+	copy(buf, b)
+	large := buf[0]&0x20 != 0
+	buf[0] &= 0x1f
+
+	x := new(big.Int).SetBytes(buf)
+	if x.Cmp(fieldP) >= 0 {
+		return nil, nil, errInvalidEncoding
+	}
+	y := new(big.Int).ModSqrt(rhs(x), fieldP)
+	if y == nil {
+		return nil, nil, errInvalidEncoding
+	}
+	if (y.Cmp(halfP) > 0) != large {
+		y.Sub(fieldP, y)
+	}
+	if !c.inSubgroup(x, y) {
+		return nil, nil, errNotInSubgroup
+	}
+	return x, y, nil
+}
+
+// ToECDSA converts a 32 byte big-endian BLS secret key into a private key on
+// G1, the group BLS public keys live in under the minimal-pubkey-size variant.
+func ToECDSA(secret []byte) (*ecdsa.PrivateKey, error) {
+	if len(secret) != 32 {
+		return nil, errInvalidKey
+	}
+	d := new(big.Int).SetBytes(secret)
+	if d.Sign() == 0 || d.Cmp(groupN) >= 0 {
+		return nil, errInvalidKey
+	}
+	x, y := theCurve.ScalarBaseMult(secret)
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: theCurve, X: x, Y: y},
+		D:         d,
+	}, nil
+}
+
+// UnmarshalPubkey parses a 48 byte compressed BLS public key.
+func UnmarshalPubkey(b []byte) (*ecdsa.PublicKey, error) {
+	x, y, err := theCurve.Unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: theCurve, X: x, Y: y}, nil
+}
+
+// GenerateKey creates a new random private key on G1.
+func GenerateKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(theCurve, rand.Reader)
+}
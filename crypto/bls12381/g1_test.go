@@ -0,0 +1,133 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bls12381
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestGenerator(t *testing.T) {
+	c := G1()
+	if !c.IsOnCurve(baseX, baseY) {
+		t.Fatal("generator not in subgroup")
+	}
+	want, _ := hex.DecodeString("97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb")
+	if enc := c.Marshal(baseX, baseY); !bytes.Equal(enc, want) {
+		t.Fatalf("generator encoding mismatch: have %x, want %x", enc, want)
+	}
+	x, y, err := c.Unmarshal(want)
+	if err != nil || x.Cmp(baseX) != 0 || y.Cmp(baseY) != 0 {
+		t.Fatalf("generator decoding failed: %v", err)
+	}
+}
+
+func TestGroupLaw(t *testing.T) {
+	c := G1()
+	a, b := big.NewInt(12345), big.NewInt(67890)
+
+	ax, ay := c.ScalarBaseMult(a.Bytes())
+	bx, by := c.ScalarBaseMult(b.Bytes())
+	sx, sy := c.Add(ax, ay, bx, by)
+	wx, wy := c.ScalarBaseMult(new(big.Int).Add(a, b).Bytes())
+	if sx.Cmp(wx) != 0 || sy.Cmp(wy) != 0 {
+		t.Error("a*G + b*G != (a+b)*G")
+	}
+	dx, dy := c.Double(ax, ay)
+	ex, ey := c.Add(ax, ay, ax, ay)
+	if dx.Cmp(ex) != 0 || dy.Cmp(ey) != 0 {
+		t.Error("doubling mismatch")
+	}
+	nx, ny := c.Neg(ax, ay)
+	if zx, zy := c.Add(ax, ay, nx, ny); !c.IsIdentity(zx, zy) {
+		t.Error("P + -P is not the identity")
+	}
+	if ox, oy := c.ScalarBaseMult(groupN.Bytes()); !c.IsIdentity(ox, oy) {
+		t.Error("N*G is not the identity")
+	}
+	// marshalling round trip of a point with the larger y
+	for _, y := range []*big.Int{ay, ny} {
+		x, yy, err := c.Unmarshal(c.Marshal(ax, y))
+		if err != nil || x.Cmp(ax) != 0 || yy.Cmp(y) != 0 {
+			t.Errorf("round trip failed: %v", err)
+		}
+	}
+}
+
+func TestHashToPoint(t *testing.T) {
+	c := G1()
+	x, y := c.HashToPoint([]byte("test"))
+	if !c.IsOnCurve(x, y) {
+		t.Fatal("hashed point not in subgroup")
+	}
+	x2, y2 := c.HashToPoint([]byte("test"))
+	if x.Cmp(x2) != 0 || y.Cmp(y2) != 0 {
+		t.Error("hash to point not deterministic")
+	}
+}
+
+func TestInvalidEncoding(t *testing.T) {
+	c := G1()
+	enc := c.Marshal(baseX, baseY)
+
+	uncompressed := append([]byte{}, enc...)
+	uncompressed[0] &^= 0x80
+	infinity := make([]byte, 48)
+	infinity[0] = 0xc0
+
+	for i, b := range [][]byte{enc[:47], uncompressed, infinity} {
+		if _, _, err := c.Unmarshal(b); err == nil {
+			t.Errorf("case %d: invalid encoding accepted", i)
+		}
+	}
+	// a curve point outside the prime order subgroup
+	for x := int64(0); ; x++ {
+		bx := big.NewInt(x)
+		y := new(big.Int).ModSqrt(rhs(bx), fieldP)
+		if y == nil {
+			continue
+		}
+		if c.inSubgroup(bx, y) {
+			continue
+		}
+		if _, _, err := c.Unmarshal(c.Marshal(bx, y)); err != errNotInSubgroup {
+			t.Errorf("point outside subgroup: have %v, want %v", err, errNotInSubgroup)
+		}
+		break
+	}
+}
+
+func TestToECDSA(t *testing.T) {
+	secret := make([]byte, 32)
+	secret[31] = 1
+	key, err := ToECDSA(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := UnmarshalPubkey(G1().Marshal(key.X, key.Y))
+	if err != nil || pub.X.Cmp(baseX) != 0 {
+		t.Fatalf("pubkey round trip failed: %v", err)
+	}
+	if _, err := ToECDSA(make([]byte, 32)); err == nil {
+		t.Error("zero secret key accepted")
+	}
+	if _, err := ToECDSA(groupN.Bytes()); err == nil {
+		t.Error("secret key >= N accepted")
+	}
+}
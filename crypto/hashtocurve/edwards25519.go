@@ -0,0 +1,120 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hashtocurve
+
+import (
+	"crypto/sha512"
+	"math/big"
+)
+
+var (
+	edP = fromHex("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed")
+	edF = field{edP}
+
+	// curve25519: K*t^2 = s^3 + J*s^2 + s with K = 1
+	montJ = big.NewInt(486662)
+	ellZ  = big.NewInt(2)
+
+	// edwards25519: -x^2 + y^2 = 1 + d*x^2*y^2
+	edD = edF.mul(edF.neg(big.NewInt(121665)), edF.inv0(big.NewInt(121666)))
+
+	// sqrt(-486664) with sgn0 = 0, scaling the birational map
+	edC1 = func() *big.Int {
+		c := edF.sqrt(edF.neg(big.NewInt(486664)))
+		if sgn0(c) == 1 {
+			c = edF.neg(c)
+		}
+		return c
+	}()
+)
+
+// elligator2 implements map_to_curve_elligator2 of RFC 9380 section 6.7.1
+// onto curve25519 in Montgomery coordinates (s, t).
+func elligator2(u *big.Int) (*big.Int, *big.Int) {
+	f := edF
+	// x1 = -(J / K) * inv0(1 + Z * u^2), or -(J / K) if that is zero
+	x1 := f.neg(f.mul(montJ, f.inv0(f.add(big.NewInt(1), f.mul(ellZ, f.mul(u, u))))))
+	if x1.Sign() == 0 {
+		x1 = f.neg(montJ)
+	}
+	gx := func(x *big.Int) *big.Int {
+		xx := f.mul(x, x)
+		return f.add(f.add(f.mul(xx, x), f.mul(montJ, xx)), x)
+	}
+	var x, y *big.Int
+	if gx1 := gx(x1); f.isSquare(gx1) {
+		x, y = x1, f.sqrt(gx1)
+		if sgn0(y) == 0 {
+			y = f.neg(y)
+		}
+	} else {
+		x = f.sub(f.neg(x1), montJ)
+		y = f.sqrt(gx(x))
+		if sgn0(y) == 1 {
+			y = f.neg(y)
+		}
+	}
+	return x, y
+}
+
+// montgomeryToEdwards applies the birational map of RFC 7748 between
+// curve25519 and edwards25519, mapping exceptional points to the identity.
+func montgomeryToEdwards(s, t *big.Int) (*big.Int, *big.Int) {
+	f := edF
+	den := f.add(s, big.NewInt(1))
+	if t.Sign() == 0 || den.Sign() == 0 {
+		return new(big.Int), big.NewInt(1)
+	}
+	x := f.mul(f.mul(edC1, s), f.inv0(t))
+	y := f.mul(f.sub(s, big.NewInt(1)), f.inv0(den))
+	return x, y
+}
+
+// edwardsAdd adds two points of edwards25519 with the complete affine
+// formulas.
+func edwardsAdd(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	f := edF
+	t := f.mul(edD, f.mul(f.mul(x1, x2), f.mul(y1, y2)))
+	x := f.mul(f.add(f.mul(x1, y2), f.mul(y1, x2)), f.inv0(f.add(big.NewInt(1), t)))
+	y := f.mul(f.add(f.mul(y1, y2), f.mul(x1, x2)), f.inv0(f.sub(big.NewInt(1), t)))
+	return x, y
+}
+
+// MapToEdwards25519 maps a field element to edwards25519 with Elligator 2,
+// without clearing the cofactor.
+func MapToEdwards25519(u *big.Int) (*big.Int, *big.Int) {
+	return montgomeryToEdwards(elligator2(edF.mod(new(big.Int).Set(u))))
+}
+
+// Edwards25519 hashes msg to a point of the prime order subgroup of
+// edwards25519 with the suite edwards25519_XMD:SHA-512_ELL2_RO_ and the domain
+// separation tag dst. The point is returned in affine coordinates.
+func Edwards25519(msg, dst []byte) (*big.Int, *big.Int, error) {
+	u, err := hashToField(sha512.New, msg, dst, edP, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	x0, y0 := MapToEdwards25519(u[0])
+	x1, y1 := MapToEdwards25519(u[1])
+	x, y := edwardsAdd(x0, y0, x1, y1)
+
+	// clear the cofactor 8
+	for i := 0; i < 3; i++ {
+		x, y = edwardsAdd(x, y, x, y)
+	}
+	return x, y, nil
+}
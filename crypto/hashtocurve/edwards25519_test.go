@@ -0,0 +1,68 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+package hashtocurve
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func onEdwards25519(x, y *big.Int) bool {
+	f := edF
+	xx, yy := f.mul(x, x), f.mul(y, y)
+	return f.sub(yy, xx).Cmp(f.add(big.NewInt(1), f.mul(edD, f.mul(xx, yy)))) == 0
+}
+
+// Test vector from RFC 9380 appendix J.5.1.
+func TestEdwards25519(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_ELL2_RO_")
+	x, y, err := Edwards25519(nil, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprintf("%064x", x), "3c3da6925a3c3c268448dcabb47ccde5439559d9599646a8260e47b1e4822fc6"; got != want {
+		t.Errorf("x mismatch: have %s, want %s", got, want)
+	}
+	if got, want := fmt.Sprintf("%064x", y), "09a6c8561a0b22bef63124c588ce4c62ea83a3c899763af26d795302e115dc21"; got != want {
+		t.Errorf("y mismatch: have %s, want %s", got, want)
+	}
+}
+
+func TestEdwards25519PrimeOrder(t *testing.T) {
+	// l = 2^252 + 27742317777372353535851937790883648493
+	l, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	for i := 0; i < 4; i++ {
+		x, y, err := Edwards25519([]byte{byte(i)}, []byte("test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !onEdwards25519(x, y) {
+			t.Fatalf("msg %d: point not on curve", i)
+		}
+		// l*P must be the identity (0, 1)
+		ax, ay := new(big.Int), big.NewInt(1)
+		for j := l.BitLen() - 1; j >= 0; j-- {
+			ax, ay = edwardsAdd(ax, ay, ax, ay)
+			if l.Bit(j) == 1 {
+				ax, ay = edwardsAdd(ax, ay, x, y)
+			}
+		}
+		if ax.Sign() != 0 || ay.Cmp(big.NewInt(1)) != 0 {
+			t.Fatalf("msg %d: point not in the prime order subgroup", i)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hashtocurve
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+)
+
+var (
+	errDSTLength    = errors.New("hashtocurve: domain separation tag too long")
+	errOutputLength = errors.New("hashtocurve: requested output too long")
+)
+
+// ExpandMessageXMD implements expand_message_xmd of RFC 9380 section 5.3.1,
+// producing size uniformly random bytes from msg and the domain separation
+// tag dst with the hash function h.
+func ExpandMessageXMD(h func() hash.Hash, msg, dst []byte, size int) ([]byte, error) {
+	if len(dst) > 255 {
+		return nil, errDSTLength
+	}
+	hh := h()
+	b, s := hh.Size(), hh.BlockSize()
+	ell := (size + b - 1) / b
+	if ell > 255 || size > 65535 || size <= 0 {
+		return nil, errOutputLength
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	// b_0 = H(Z_pad || msg || l_i_b_str || I2OSP(0, 1) || DST_prime)
+	hh.Write(make([]byte, s))
+	hh.Write(msg)
+	hh.Write([]byte{byte(size >> 8), byte(size), 0})
+	hh.Write(dstPrime)
+	b0 := hh.Sum(nil)
+
+	// b_1 = H(b_0 || I2OSP(1, 1) || DST_prime)
+	hh.Reset()
+	hh.Write(b0)
+	hh.Write([]byte{1})
+	hh.Write(dstPrime)
+	bi := hh.Sum(nil)
+
+	out := make([]byte, 0, ell*b)
+	out = append(out, bi...)
+	for i := 2; i <= ell; i++ {
+		// b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime)
+		x := make([]byte, b)
+		for j := range x {
+			x[j] = b0[j] ^ bi[j]
+		}
+		hh.Reset()
+		hh.Write(x)
+		hh.Write([]byte{byte(i)})
+		hh.Write(dstPrime)
+		bi = hh.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:size], nil
+}
+
+// hashToField implements hash_to_field of RFC 9380 section 5.2 for a prime
+// field of order p with k = 128 bit security, returning count elements.
+func hashToField(h func() hash.Hash, msg, dst []byte, p *big.Int, count int) ([]*big.Int, error) {
+	L := (p.BitLen() + 128 + 7) / 8
+	uniform, err := ExpandMessageXMD(h, msg, dst, count*L)
+	if err != nil {
+		return nil, err
+	}
+	u := make([]*big.Int, count)
+	for i := range u {
+		u[i] = new(big.Int).SetBytes(uniform[i*L : (i+1)*L])
+		u[i].Mod(u[i], p)
+	}
+	return u, nil
+}
+
+// field bundles modular arithmetic over a prime.
+type field struct {
+	p *big.Int
+}
+
+func (f field) mod(a *big.Int) *big.Int    { return a.Mod(a, f.p) }
+func (f field) add(a, b *big.Int) *big.Int { return f.mod(new(big.Int).Add(a, b)) }
+func (f field) sub(a, b *big.Int) *big.Int { return f.mod(new(big.Int).Sub(a, b)) }
+func (f field) mul(a, b *big.Int) *big.Int { return f.mod(new(big.Int).Mul(a, b)) }
+func (f field) neg(a *big.Int) *big.Int    { return f.mod(new(big.Int).Neg(a)) }
+
+// inv0 returns 1/a, or 0 for a = 0.
+func (f field) inv0(a *big.Int) *big.Int {
+	if a.Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).ModInverse(a, f.p)
+}
+
+func (f field) isSquare(a *big.Int) bool {
+	return a.Sign() == 0 || big.Jacobi(a, f.p) == 1
+}
+
+func (f field) sqrt(a *big.Int) *big.Int {
+	return new(big.Int).ModSqrt(a, f.p)
+}
+
+// sgn0 returns the sign of a, its parity for prime fields.
+func sgn0(a *big.Int) uint {
+	return a.Bit(0)
+}
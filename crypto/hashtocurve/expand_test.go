@@ -0,0 +1,64 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+package hashtocurve
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors from RFC 9380 appendix K.1.
+func TestExpandMessageXMD(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	tests := []struct {
+		msg  string
+		size int
+		want string
+	}{
+		{"", 0x20, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+		{"abc", 0x20, "d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+	}
+	for _, tt := range tests {
+		out, err := ExpandMessageXMD(sha256.New, []byte(tt.msg), dst, tt.size)
+		if err != nil {
+			t.Fatalf("msg %q: %v", tt.msg, err)
+		}
+		if got := hex.EncodeToString(out); got != tt.want {
+			t.Errorf("msg %q: have %s, want %s", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestExpandMessageXMDPrefix(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	long, _ := ExpandMessageXMD(sha256.New, []byte("abc"), dst, 100)
+	short, _ := ExpandMessageXMD(sha256.New, []byte("abc"), dst, 50)
+	// the requested length is bound into b_0, so outputs are unrelated
+	if bytes.Equal(long[:50], short) {
+		t.Error("outputs of different length share a prefix")
+	}
+}
+
+func TestExpandMessageXMDLimits(t *testing.T) {
+	if _, err := ExpandMessageXMD(sha256.New, nil, make([]byte, 256), 32); err != errDSTLength {
+		t.Errorf("long tag: have %v, want %v", err, errDSTLength)
+	}
+	if _, err := ExpandMessageXMD(sha256.New, nil, []byte("dst"), 255*32+1); err != errOutputLength {
+		t.Errorf("long output: have %v, want %v", err, errOutputLength)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package hashtocurve implements the hash_to_curve constructions of RFC 9380
+// for secp256k1 and edwards25519.
+//
+// The suites secp256k1_XMD:SHA-256_SSWU_RO_ and
+// edwards25519_XMD:SHA-512_ELL2_RO_ map arbitrary messages to curve points
+// whose discrete logarithm is unknown, indifferentiable from a random oracle.
+// The implementation uses math/big and is not constant time.
+package hashtocurve
+
+import (
+	"crypto/sha256"
+	"math/big"
+)
+
+func fromHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid constant " + s)
+	}
+	return n
+}
+
+var (
+	secpP   = fromHex("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f")
+	secpF   = field{secpP}
+	secpB   = big.NewInt(7)
+	isoA    = fromHex("3f8731abdd661adca08a5558f0f5d272e953d363cb6f0e5d405447c01a444533")
+	isoB    = big.NewInt(1771)
+	secpZ   = secpF.neg(big.NewInt(11))
+	isoXNum = []*big.Int{
+		fromHex("8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa8c7"),
+		fromHex("07d3d4c80bc321d5b9f315cea7fd44c5d595d2fc0bf63b92dfff1044f17c6581"),
+		fromHex("534c328d23f234e6e2a413deca25caece4506144037c40314ecbd0b53d9dd262"),
+		fromHex("8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa88c"),
+	}
+	isoXDen = []*big.Int{
+		fromHex("d35771193d94918a9ca34ccbb7b640dd86cd409542f8487d9fe6b745781eb49b"),
+		fromHex("edadc6f64383dc1df7c4b2d51b54225406d36b641f5e41bbc52a56612a8c6d14"),
+		big.NewInt(1),
+	}
+	isoYNum = []*big.Int{
+		fromHex("4bda12f684bda12f684bda12f684bda12f684bda12f684bda12f684b8e38e23c"),
+		fromHex("c75e0c32d5cb7c0fa9d0a54b12a0a6d5647ab046d686da6fdffc90fc201d71a3"),
+		fromHex("29a6194691f91a73715209ef6512e576722830a201be2018a765e85a9ecee931"),
+		fromHex("2f684bda12f684bda12f684bda12f684bda12f684bda12f684bda12f38e38d84"),
+	}
+	isoYDen = []*big.Int{
+		fromHex("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffff93b"),
+		fromHex("7a06534bb8bdb49fd5e9e6632722c2989467c1bfc8e8d978dfb425d2685c2573"),
+		fromHex("6484aa716545ca2cf3a70c3fa8fe337e0a3d21162f0d6299a7bf8192bfd2a76f"),
+		big.NewInt(1),
+	}
+)
+
+// poly evaluates the polynomial with the given coefficients, lowest degree
+// first, at x.
+func (f field) poly(coeffs []*big.Int, x *big.Int) *big.Int {
+	acc := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		acc = f.add(f.mul(acc, x), coeffs[i])
+	}
+	return acc
+}
+
+// sswu implements map_to_curve_simple_swu of RFC 9380 section 6.6.2 onto the
+// curve y^2 = x^3 + A*x + B isogenous to secp256k1.
+func sswu(u *big.Int) (*big.Int, *big.Int) {
+	f := secpF
+	zu2 := f.mul(secpZ, f.mul(u, u))
+	tv1 := f.inv0(f.add(f.mul(zu2, zu2), zu2))
+
+	// x1 = (-B / A) * (1 + tv1), or B / (Z * A) if tv1 = 0
+	var x1 *big.Int
+	if tv1.Sign() == 0 {
+		x1 = f.mul(isoB, f.inv0(f.mul(secpZ, isoA)))
+	} else {
+		x1 = f.mul(f.neg(f.mul(isoB, f.inv0(isoA))), f.add(big.NewInt(1), tv1))
+	}
+	gx := func(x *big.Int) *big.Int {
+		return f.add(f.add(f.mul(f.mul(x, x), x), f.mul(isoA, x)), isoB)
+	}
+	x, y := x1, new(big.Int)
+	if gx1 := gx(x1); f.isSquare(gx1) {
+		y = f.sqrt(gx1)
+	} else {
+		x = f.mul(zu2, x1)
+		y = f.sqrt(gx(x))
+	}
+	if sgn0(u) != sgn0(y) {
+		y = f.neg(y)
+	}
+	return x, y
+}
+
+// isoMap maps a point of the isogenous curve to secp256k1 with the 3-isogeny
+// of RFC 9380 appendix E.1. It returns nil coordinates for the point at
+// infinity.
+func isoMap(x, y *big.Int) (*big.Int, *big.Int) {
+	f := secpF
+	xDen := f.poly(isoXDen, x)
+	yDen := f.poly(isoYDen, x)
+	if xDen.Sign() == 0 || yDen.Sign() == 0 {
+		return nil, nil
+	}
+	xm := f.mul(f.poly(isoXNum, x), f.inv0(xDen))
+	ym := f.mul(y, f.mul(f.poly(isoYNum, x), f.inv0(yDen)))
+	return xm, ym
+}
+
+// weierstrassAdd adds two points of y^2 = x^3 + b (a = 0) in affine
+// coordinates, with nil coordinates denoting the point at infinity.
+func weierstrassAdd(f field, x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if x1 == nil {
+		return x2, y2
+	}
+	if x2 == nil {
+		return x1, y1
+	}
+	var lambda *big.Int
+	if x1.Cmp(x2) == 0 {
+		if f.add(y1, y2).Sign() == 0 {
+			return nil, nil
+		}
+		// lambda = 3*x^2 / 2*y
+		lambda = f.mul(f.mul(big.NewInt(3), f.mul(x1, x1)), f.inv0(f.mul(big.NewInt(2), y1)))
+	} else {
+		lambda = f.mul(f.sub(y2, y1), f.inv0(f.sub(x2, x1)))
+	}
+	x3 := f.sub(f.sub(f.mul(lambda, lambda), x1), x2)
+	y3 := f.sub(f.mul(lambda, f.sub(x1, x3)), y1)
+	return x3, y3
+}
+
+// MapToSecp256k1 maps a field element to secp256k1 with the simplified SWU
+// map and the 3-isogeny. It returns nil coordinates for the point at infinity.
+func MapToSecp256k1(u *big.Int) (*big.Int, *big.Int) {
+	return isoMap(sswu(secpF.mod(new(big.Int).Set(u))))
+}
+
+// Secp256k1 hashes msg to a point of secp256k1 with the suite
+// secp256k1_XMD:SHA-256_SSWU_RO_ and the domain separation tag dst. The
+// result is the point at infinity, with nil coordinates, with negligible
+// probability.
+func Secp256k1(msg, dst []byte) (*big.Int, *big.Int, error) {
+	u, err := hashToField(sha256.New, msg, dst, secpP, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	x0, y0 := MapToSecp256k1(u[0])
+	x1, y1 := MapToSecp256k1(u[1])
+	x, y := weierstrassAdd(secpF, x0, y0, x1, y1)
+	return x, y, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+package hashtocurve
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func onSecp256k1(x, y *big.Int) bool {
+	f := secpF
+	return f.mul(y, y).Cmp(f.add(f.mul(f.mul(x, x), x), secpB)) == 0
+}
+
+// Test vectors from RFC 9380 appendix J.8.1.
+func TestSecp256k1(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-secp256k1_XMD:SHA-256_SSWU_RO_")
+	tests := []struct {
+		msg  string
+		x, y string
+	}{
+		{"",
+			"c1cae290e291aee617ebaef1be6d73861479c48b841eaba9b7b5852ddfeb1346",
+			"64fa678e07ae116126f08b022a94af6de15985c996c3a91b64c406a960e51067"},
+		{"abc",
+			"3377e01eab42db296b512293120c6cee72b6ecf9f9205760bd9ff11fb3cb2c4b",
+			"7f95890f33efebd1044d382a01b1bee0900fb6116f94688d487c6c7b9c8371f6"},
+	}
+	for _, tt := range tests {
+		x, y, err := Secp256k1([]byte(tt.msg), dst)
+		if err != nil {
+			t.Fatalf("msg %q: %v", tt.msg, err)
+		}
+		if got := fmt.Sprintf("%064x", x); got != tt.x {
+			t.Errorf("msg %q: x mismatch: have %s, want %s", tt.msg, got, tt.x)
+		}
+		if got := fmt.Sprintf("%064x", y); got != tt.y {
+			t.Errorf("msg %q: y mismatch: have %s, want %s", tt.msg, got, tt.y)
+		}
+	}
+}
+
+func TestMapToSecp256k1OnCurve(t *testing.T) {
+	for i := int64(0); i < 64; i++ {
+		x, y := MapToSecp256k1(big.NewInt(i))
+		if x == nil {
+			continue
+		}
+		if !onSecp256k1(x, y) {
+			t.Fatalf("u = %d: point not on curve", i)
+		}
+	}
+}
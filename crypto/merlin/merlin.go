@@ -0,0 +1,64 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package merlin implements Merlin transcripts, the STROBE based Fiat-Shamir
+// transcripts used by schnorrkel (sr25519) and other Rust proof systems.
+//
+// A transcript absorbs labelled messages and produces challenges bound to
+// everything absorbed before. The output is byte compatible with the Rust
+// merlin crate.
+package merlin
+
+import "encoding/binary"
+
+// Transcript is a Merlin transcript.
+type Transcript struct {
+	s *strobe128
+}
+
+// NewTranscript creates a transcript with the given application label.
+func NewTranscript(label string) *Transcript {
+	t := &Transcript{s: newStrobe128("Merlin v1.0")}
+	t.AppendMessage([]byte("dom-sep"), []byte(label))
+	return t
+}
+
+// AppendMessage absorbs a labelled message into the transcript.
+func (t *Transcript) AppendMessage(label, message []byte) {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(message)))
+
+	t.s.metaAD(label, false)
+	t.s.metaAD(size[:], true)
+	t.s.ad(message, false)
+}
+
+// AppendUint64 absorbs a labelled integer in little-endian order.
+func (t *Transcript) AppendUint64(label []byte, x uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], x)
+	t.AppendMessage(label, buf[:])
+}
+
+// ChallengeBytes fills out with challenge bytes bound to the transcript.
+func (t *Transcript) ChallengeBytes(label []byte, out []byte) {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(out)))
+
+	t.s.metaAD(label, false)
+	t.s.metaAD(size[:], true)
+	t.s.prf(out, false)
+}
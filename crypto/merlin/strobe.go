@@ -0,0 +1,126 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package merlin
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// strobe128 is the minimal subset of STROBE-128 used by Merlin: meta-AD, AD
+// and PRF operations on a Keccak-f[1600] duplex with a rate of 166 bytes.
+type strobe128 struct {
+	state    [200]byte
+	pos      int
+	posBegin int
+	curFlags byte
+}
+
+const (
+	strobeR = 166
+
+	flagI = 1 << 0
+	flagA = 1 << 1
+	flagC = 1 << 2
+	flagT = 1 << 3
+	flagM = 1 << 4
+	flagK = 1 << 5
+)
+
+func newStrobe128(protocol string) *strobe128 {
+	s := new(strobe128)
+	copy(s.state[:], []byte{1, strobeR + 2, 1, 0, 1, 96})
+	copy(s.state[6:], "STROBEv1.0.2")
+	s.permute()
+	s.metaAD([]byte(protocol), false)
+	return s
+}
+
+// permute runs Keccak-f[1600] on the byte state.
+func (s *strobe128) permute() {
+	var lanes [25]uint64
+	for i := range lanes {
+		lanes[i] = binary.LittleEndian.Uint64(s.state[8*i:])
+	}
+	sha3.KeccakF1600(&lanes)
+	for i, lane := range lanes {
+		binary.LittleEndian.PutUint64(s.state[8*i:], lane)
+	}
+}
+
+func (s *strobe128) runF() {
+	s.state[s.pos] ^= byte(s.posBegin)
+	s.state[s.pos+1] ^= 0x04
+	s.state[strobeR+1] ^= 0x80
+	s.permute()
+	s.pos, s.posBegin = 0, 0
+}
+
+func (s *strobe128) absorb(data []byte) {
+	for _, b := range data {
+		s.state[s.pos] ^= b
+		if s.pos++; s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) squeeze(out []byte) {
+	for i := range out {
+		out[i] = s.state[s.pos]
+		s.state[s.pos] = 0
+		if s.pos++; s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe128) beginOp(flags byte, more bool) {
+	if more {
+		if s.curFlags != flags {
+			panic("merlin: continued STROBE operation with different flags")
+		}
+		return
+	}
+	if flags&flagT != 0 {
+		panic("merlin: transport operations are not supported")
+	}
+	oldBegin := s.posBegin
+	s.posBegin = s.pos + 1
+	s.curFlags = flags
+	s.absorb([]byte{byte(oldBegin), flags})
+
+	if flags&(flagC|flagK) != 0 && s.pos != 0 {
+		s.runF()
+	}
+}
+
+func (s *strobe128) metaAD(data []byte, more bool) {
+	s.beginOp(flagM|flagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe128) ad(data []byte, more bool) {
+	s.beginOp(flagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe128) prf(out []byte, more bool) {
+	s.beginOp(flagI|flagA|flagC, more)
+	s.squeeze(out)
+}
@@ -0,0 +1,162 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package poseidon
+
+import "math/big"
+
+// grain is the Grain LFSR used by the Poseidon reference implementation to
+// derive round constants and MDS matrices from the instance parameters.
+type grain struct {
+	state []byte // one bit per byte, oldest first
+}
+
+// newGrain seeds the LFSR for a prime field instance with the x^5 S-box.
+func newGrain(n, t, rf, rp int) *grain {
+	g := new(grain)
+	push := func(v, bits int) {
+		for i := bits - 1; i >= 0; i-- {
+			g.state = append(g.state, byte(v>>uint(i))&1)
+		}
+	}
+	push(1, 2) // prime field
+	push(0, 4) // x^alpha S-box
+	push(n, 12)
+	push(t, 12)
+	push(rf, 10)
+	push(rp, 10)
+	push(1<<30-1, 30)
+
+	// discard the first 160 bits
+	for i := 0; i < 160; i++ {
+		g.step()
+	}
+	return g
+}
+
+// step clocks the register once and returns the new bit.
+func (g *grain) step() byte {
+	s := g.state
+	bit := s[62] ^ s[51] ^ s[38] ^ s[23] ^ s[13] ^ s[0]
+	g.state = append(s[1:], bit)
+	return bit
+}
+
+// bit returns the next output bit using the self-shrinking rule: bits are
+// produced in pairs, and the second bit of a pair is only output if the
+// first one is set.
+func (g *grain) bit() byte {
+	for {
+		if g.step() == 1 {
+			return g.step()
+		}
+		g.step()
+	}
+}
+
+// bits returns the next n output bits as a big-endian integer.
+func (g *grain) bits(n int) *big.Int {
+	v := new(big.Int)
+	for i := 0; i < n; i++ {
+		v.Lsh(v, 1)
+		if g.bit() == 1 {
+			v.SetBit(v, 0, 1)
+		}
+	}
+	return v
+}
+
+// field returns the next uniformly sampled field element, rejecting values
+// that are not reduced.
+func (g *grain) field() *big.Int {
+	for {
+		if v := g.bits(fieldBits); v.Cmp(fieldP) < 0 {
+			return v
+		}
+	}
+}
+
+// params holds the constants of one Poseidon instance.
+type params struct {
+	t   int
+	rf  int
+	rp  int
+	ark []*big.Int   // (rf + rp) * t round constants
+	mds [][]*big.Int // t x t Cauchy matrix
+}
+
+// newParams derives the constants of the instance with width t.
+func newParams(t int) *params {
+	p := &params{t: t, rf: FullRounds, rp: roundsP[t-2]}
+	g := newGrain(fieldBits, t, p.rf, p.rp)
+
+	p.ark = make([]*big.Int, (p.rf+p.rp)*t)
+	for i := range p.ark {
+		p.ark[i] = g.field()
+	}
+	p.mds = newMDS(g, t)
+	return p
+}
+
+// newMDS samples 2t distinct field elements x_i, y_j and returns the Cauchy
+// matrix M[i][j] = 1 / (x_i + y_j).
+func newMDS(g *grain, t int) [][]*big.Int {
+	for {
+		xs := make([]*big.Int, 2*t)
+		for {
+			for i := range xs {
+				xs[i] = g.bits(fieldBits)
+				xs[i].Mod(xs[i], fieldP)
+			}
+			if distinct(xs) {
+				break
+			}
+		}
+		ys := xs[t:]
+		xs = xs[:t]
+
+		m := make([][]*big.Int, t)
+		ok := true
+		for i := range m {
+			m[i] = make([]*big.Int, t)
+			for j := range m[i] {
+				sum := new(big.Int).Add(xs[i], ys[j])
+				if sum.Mod(sum, fieldP).Sign() == 0 {
+					ok = false
+					break
+				}
+				m[i][j] = sum.ModInverse(sum, fieldP)
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			return m
+		}
+	}
+}
+
+func distinct(vs []*big.Int) bool {
+	for i := range vs {
+		for j := i + 1; j < len(vs); j++ {
+			if vs[i].Cmp(vs[j]) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package poseidon implements the Poseidon hash function over the scalar field
+// of the BN254 curve, with the parameters used by circomlib.
+//
+// Poseidon is cheap to evaluate inside arithmetic circuits, which makes it the
+// hash of choice for values that have to be proven in a zk-SNARK. The round
+// constants and MDS matrices are derived at first use with the Grain LFSR of
+// the reference implementation.
+package poseidon
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// fieldP is the order of the BN254 scalar field.
+var fieldP, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+const (
+	fieldBits = 254
+
+	// FullRounds is the number of full rounds of every instance.
+	FullRounds = 8
+
+	// MaxInputs is the maximum number of inputs Hash accepts.
+	MaxInputs = 16
+)
+
+// roundsP holds the number of partial rounds by state width, starting at 2.
+var roundsP = []int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+
+var (
+	errInputCount = errors.New("poseidon: invalid number of inputs")
+	errInputRange = errors.New("poseidon: input not in field")
+)
+
+var (
+	paramsLock sync.Mutex
+	paramsMap  = make(map[int]*params)
+)
+
+// instance returns the cached parameters for state width t.
+func instance(t int) *params {
+	paramsLock.Lock()
+	defer paramsLock.Unlock()
+
+	p, ok := paramsMap[t]
+	if !ok {
+		p = newParams(t)
+		paramsMap[t] = p
+	}
+	return p
+}
+
+// Modulus returns the order of the field Poseidon operates on.
+func Modulus() *big.Int {
+	return new(big.Int).Set(fieldP)
+}
+
+// Hash returns the Poseidon hash of 1 to MaxInputs field elements. The state
+// starts with a zero capacity element followed by the inputs, and the first
+// element of the permuted state is the output.
+func Hash(inputs ...*big.Int) (*big.Int, error) {
+	if len(inputs) == 0 || len(inputs) > MaxInputs {
+		return nil, errInputCount
+	}
+	state := make([]*big.Int, len(inputs)+1)
+	state[0] = new(big.Int)
+	for i, in := range inputs {
+		if in == nil || in.Sign() < 0 || in.Cmp(fieldP) >= 0 {
+			return nil, errInputRange
+		}
+		state[i+1] = new(big.Int).Set(in)
+	}
+	permute(instance(len(state)), state)
+	return state[0], nil
+}
+
+// Constants returns the parameters of the instance hashing n inputs, for
+// implementations of Poseidon inside circuits: the number of partial rounds,
+// the (FullRounds + partial) * (n + 1) round constants and the MDS matrix.
+func Constants(n int) (partial int, ark []*big.Int, mds [][]*big.Int, err error) {
+	if n < 1 || n > MaxInputs {
+		return 0, nil, nil, errInputCount
+	}
+	p := instance(n + 1)
+	ark = make([]*big.Int, len(p.ark))
+	for i, c := range p.ark {
+		ark[i] = new(big.Int).Set(c)
+	}
+	mds = make([][]*big.Int, p.t)
+	for i, row := range p.mds {
+		mds[i] = make([]*big.Int, p.t)
+		for j, c := range row {
+			mds[i][j] = new(big.Int).Set(c)
+		}
+	}
+	return p.rp, ark, mds, nil
+}
+
+// permute applies the Poseidon permutation to state in place.
+func permute(p *params, state []*big.Int) {
+	var (
+		rounds = p.rf + p.rp
+		half   = p.rf / 2
+		next   = make([]*big.Int, p.t)
+		tmp    = new(big.Int)
+	)
+	for i := range next {
+		next[i] = new(big.Int)
+	}
+	for r := 0; r < rounds; r++ {
+		for i := range state {
+			state[i].Add(state[i], p.ark[r*p.t+i])
+		}
+		if r < half || r >= half+p.rp {
+			for i := range state {
+				sbox(state[i])
+			}
+		} else {
+			sbox(state[0])
+		}
+		for i := range next {
+			next[i].SetInt64(0)
+			for j := range state {
+				next[i].Add(next[i], tmp.Mul(p.mds[i][j], state[j]))
+			}
+			next[i].Mod(next[i], fieldP)
+		}
+		for i := range state {
+			state[i].Set(next[i])
+		}
+	}
+}
+
+// sbox raises x to the fifth power modulo the field order.
+func sbox(x *big.Int) {
+	x.Mod(x, fieldP)
+	x2 := new(big.Int).Mul(x, x)
+	x2.Mod(x2, fieldP)
+	x4 := x2.Mul(x2, x2)
+	x4.Mod(x4, fieldP)
+	x.Mul(x, x4)
+	x.Mod(x, fieldP)
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+)
+
+// Test vectors from circomlib.
+var hashTests = []struct {
+	inputs []int64
+	want   string
+}{
+	{[]int64{1}, "18586133768512220936620570745912940619677854269274689475585506675881198879027"},
+	{[]int64{1, 2}, "7853200120776062878684798364095072458815029376092732009249414926327459813530"},
+	{[]int64{1, 2, 0, 0, 0}, "1018317224307729531995786483840663576608797660851238720571059489595066344487"},
+}
+
+func TestHash(t *testing.T) {
+	for i, test := range hashTests {
+		inputs := make([]*big.Int, len(test.inputs))
+		for j, v := range test.inputs {
+			inputs[j] = big.NewInt(v)
+		}
+		have, err := Hash(inputs...)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if have.String() != test.want {
+			t.Errorf("test %d: have %v, want %s", i, have, test.want)
+		}
+	}
+}
+
+func TestHashInvalidInputs(t *testing.T) {
+	if _, err := Hash(); err != errInputCount {
+		t.Errorf("no inputs: have %v, want %v", err, errInputCount)
+	}
+	if _, err := Hash(make([]*big.Int, MaxInputs+1)...); err != errInputCount {
+		t.Errorf("too many inputs: have %v, want %v", err, errInputCount)
+	}
+	if _, err := Hash(Modulus()); err != errInputRange {
+		t.Errorf("unreduced input: have %v, want %v", err, errInputRange)
+	}
+}
+
+// TestConstants recomputes hashes from the exported constants, the way a
+// circuit would.
+func TestConstants(t *testing.T) {
+	for i, test := range hashTests {
+		partial, ark, mds, err := Constants(len(test.inputs))
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		state := make([]*big.Int, len(test.inputs)+1)
+		state[0] = new(big.Int)
+		for j, v := range test.inputs {
+			state[j+1] = big.NewInt(v)
+		}
+		p := &params{t: len(state), rf: FullRounds, rp: partial, ark: ark, mds: mds}
+		permute(p, state)
+		if state[0].String() != test.want {
+			t.Errorf("test %d: have %v, want %s", i, state[0], test.want)
+		}
+	}
+	if _, _, _, err := Constants(MaxInputs + 1); err != errInputCount {
+		t.Errorf("too many inputs: have %v, want %v", err, errInputCount)
+	}
+}
@@ -0,0 +1,133 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The helpers below produce calldata for verifying linkable ring signatures
+// on chain, either through the ring verification precompile at address 0x09
+// or through a Solidity contract implementing RingVerifierABI. Contracts see
+// scalars as uint256 and points as uint256[2] affine coordinates.
+//
+// A ring can also be registered once and referenced by its identifier, the
+// Keccak256 hash of its ABI encoding as uint256[2][], which keeps the calldata
+// of repeated verifications against the same ring small.
+//
+// The verifier contract interface has no transcript version and implements
+// the original transcript; signatures with the v2 transcript go through the
+// precompile, whose input carries the version. Package
+// contracts/ringverifier holds the canonical verifier contract, generated
+// from the encoding used here.
+
+// RingVerifierABI is the interface of an on-chain ring signature verifier.
+const RingVerifierABI = `[
+	{"type":"function","name":"verify","stateMutability":"view","constant":true,
+	 "inputs":[{"name":"message","type":"bytes32"},{"name":"ring","type":"uint256[2][]"},{"name":"c","type":"uint256"},{"name":"s","type":"uint256[]"},{"name":"keyImage","type":"uint256[2]"}],
+	 "outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"verifyWithRing","stateMutability":"view","constant":true,
+	 "inputs":[{"name":"ringId","type":"bytes32"},{"name":"message","type":"bytes32"},{"name":"c","type":"uint256"},{"name":"s","type":"uint256[]"},{"name":"keyImage","type":"uint256[2]"}],
+	 "outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"registerRing","constant":false,
+	 "inputs":[{"name":"ring","type":"uint256[2][]"}],
+	 "outputs":[{"name":"ringId","type":"bytes32"}]}
+]`
+
+var errNotSecp256k1 = errors.New("on-chain verification requires secp256k1")
+
+// ringVerifier is the parsed RingVerifierABI.
+var ringVerifier = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(RingVerifierABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// abiPoint converts p into its uint256[2] representation.
+func abiPoint(p *ecdsa.PublicKey) [2]*big.Int {
+	return [2]*big.Int{p.X, p.Y}
+}
+
+// abiRing converts a ring into its uint256[2][] representation.
+func abiRing(ring Ring) ([][2]*big.Int, error) {
+	points := make([][2]*big.Int, len(ring))
+	for i, pub := range ring {
+		if pub == nil || pub.Curve != crypto.S256() {
+			return nil, errNotSecp256k1
+		}
+		points[i] = abiPoint(pub)
+	}
+	return points, nil
+}
+
+// RingID returns the identifier under which a verifier contract stores ring.
+func RingID(ring Ring) (common.Hash, error) {
+	points, err := abiRing(ring)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	enc, err := ringVerifier.Methods["registerRing"].Inputs.Pack(points)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(enc), nil
+}
+
+// PackRegisterRing returns the calldata registering ring with a verifier
+// contract.
+func PackRegisterRing(ring Ring) ([]byte, error) {
+	points, err := abiRing(ring)
+	if err != nil {
+		return nil, err
+	}
+	return ringVerifier.Pack("registerRing", points)
+}
+
+// PackVerify returns the calldata verifying sig with a verifier contract,
+// passing the ring along.
+func PackVerify(sig *RingSign) ([]byte, error) {
+	if sig.Curve != crypto.S256() {
+		return nil, errNotSecp256k1
+	}
+	if encodedVersion(sig) != 0 {
+		return nil, errTranscriptVersion
+	}
+	points, err := abiRing(sig.Ring)
+	if err != nil {
+		return nil, err
+	}
+	return ringVerifier.Pack("verify", sig.M, points, sig.C, sig.S, abiPoint(sig.I))
+}
+
+// PackVerifyWithRing returns the calldata verifying sig with a verifier
+// contract against a previously registered ring.
+func PackVerifyWithRing(sig *RingSign) ([]byte, error) {
+	if sig.Curve != crypto.S256() {
+		return nil, errNotSecp256k1
+	}
+	if encodedVersion(sig) != 0 {
+		return nil, errTranscriptVersion
+	}
+	id, err := RingID(sig.Ring)
+	if err != nil {
+		return nil, err
+	}
+	return ringVerifier.Pack("verifyWithRing", id, sig.M, sig.C, sig.S, abiPoint(sig.I))
+}
+
+// PrecompileInput returns the input of the ring verification precompile for
+// sig: a 32 byte word, ignored by the precompile, followed by the serialized
+// signature.
+func PrecompileInput(sig *RingSign) ([]byte, error) {
+	if sig.Curve != crypto.S256() {
+		return nil, errNotSecp256k1
+	}
+	return append(make([]byte, 32), sig.SerializeSignature()...), nil
+}
@@ -0,0 +1,103 @@
+package ring
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestPackVerify(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(3, key, 1)
+	sig, err := SignWithOpts([32]byte{1}, ring, key, 1, &SignOpts{Version: TranscriptV1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := PackVerify(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[:4], ringVerifier.Methods["verify"].Id()) {
+		t.Fatalf("wrong selector %x", data[:4])
+	}
+	// head: message, ring offset, c, s offset, keyImage (2 words)
+	// tail: ring length and 2 words per member, s length and 1 word per member
+	if want := 4 + 32*(6+1+2*3+1+3); len(data) != want {
+		t.Fatalf("wrong calldata length: have %d, want %d", len(data), want)
+	}
+	words := data[4:]
+	word := func(i int) *big.Int { return new(big.Int).SetBytes(words[32*i : 32*(i+1)]) }
+	if !bytes.Equal(words[:32], sig.M[:]) {
+		t.Fatal("message mismatch")
+	}
+	if word(2).Cmp(sig.C) != 0 {
+		t.Fatal("challenge mismatch")
+	}
+	if word(4).Cmp(sig.I.X) != 0 || word(5).Cmp(sig.I.Y) != 0 {
+		t.Fatal("key image mismatch")
+	}
+	ringOff := int(word(1).Int64()) / 32
+	if n := word(ringOff).Int64(); n != 3 {
+		t.Fatalf("wrong ring length %d", n)
+	}
+	for i, pub := range ring {
+		if word(ringOff+1+2*i).Cmp(pub.X) != 0 || word(ringOff+2+2*i).Cmp(pub.Y) != 0 {
+			t.Fatalf("ring member %d mismatch", i)
+		}
+	}
+	sOff := int(word(3).Int64()) / 32
+	for i, s := range sig.S {
+		if word(sOff+1+i).Cmp(s) != 0 {
+			t.Fatalf("response %d mismatch", i)
+		}
+	}
+}
+
+func TestPackVerifyWithRing(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(2, key, 0)
+	sig, err := SignWithOpts([32]byte{2}, ring, key, 0, &SignOpts{Version: TranscriptV1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := RingID(ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := PackVerifyWithRing(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.BytesToHash(data[4:36]) != id {
+		t.Fatal("ring identifier mismatch")
+	}
+	reg, err := PackRegisterRing(ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the identifier hashes the registration arguments
+	if crypto.Keccak256Hash(reg[4:]) != id {
+		t.Fatal("ring identifier does not match registration calldata")
+	}
+}
+
+func TestPrecompileInput(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(2, key, 1)
+	sig, _ := Sign([32]byte{3}, ring, key, 1)
+
+	input, err := PrecompileInput(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := DeserializeSignature(input[32:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(dec) {
+		t.Fatal("precompile input does not verify")
+	}
+}
@@ -0,0 +1,258 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Accumulators commit to a set of ring members with a single group element
+// and give every member a witness of constant size, however large the set
+// grows. Unlike a Merkle root, members are added and removed without
+// rebuilding anything, which suits sets that change every block, such as all
+// outputs ever created.
+//
+// The accumulator is the RSA accumulator of Benaloh and de Mare, in the group
+// of units modulo the RSA-2048 challenge number, whose factorization nobody
+// knows. There is no trapdoor: anyone can run an accumulator, and nobody can
+// forge a witness without computing roots modulo N. Each member maps to a
+// prime x, and the accumulated value and the witness of x are
+//
+//	A = g^(x_1...x_n)
+//	W = g^(x_1...x_n / x)
+//
+// which verifies as W^x = A. Witness holders keep their witness current with
+// the updates published on every change, see Update. Removing a member takes
+// the accumulator one exponentiation over the remaining set.
+//
+// As with MerkleSign, a signature hides its signer among the ring it picked
+// from the set, each ring member carrying its witness.
+
+// accumulatorDomain separates the hashes of accumulated rings from other
+// hashes in the package.
+var accumulatorDomain = []byte("go-ethereum/crypto/ring accumulator")
+
+var (
+	// accumulatorModulus is the RSA-2048 factoring challenge number, a
+	// modulus of unknown order.
+	accumulatorModulus, _ = new(big.Int).SetString("25195908475657893494027183240048398571429282126204032027777137836043662020707595556264018525880784406918290641249515082189298559149176184502808489120072844992687392807287776735971418347270261896375014971824691165077613379859095700097330459748808428401797429100642458691817195118746121515172654632282216869987549182422433637259085141865462043576798423387184774447920739934236584823824281198163815010674810451660377306056201619676256133844143603833904414952634432190114657544454178424020924616515723350778707749817125772467962926386356373289912154831438167899885040445364023527381951378636564391212010397122822120720357", 10)
+
+	// accumulatorBase is the value of the empty accumulator.
+	accumulatorBase = big.NewInt(3)
+)
+
+var (
+	errAccumulated    = errors.New("member already accumulated")
+	errNotAccumulated = errors.New("member not accumulated")
+	errMemberRemoved  = errors.New("witness member has been removed")
+	errWitnessCount   = errors.New("number of witnesses does not match ring size")
+)
+
+// accumulatorElement maps a member key to the prime it is accumulated as, the
+// first 256 bit prime of a counter-mode hash of the key.
+func accumulatorElement(pub *ecdsa.PublicKey) *big.Int {
+	data := append(append([]byte{}, accumulatorDomain...), pointBytes(pub)...)
+	data = append(data, make([]byte, 4)...)
+	x := new(big.Int)
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(data[len(data)-4:], i)
+		h := sha3.Sum256(data)
+		h[0] |= 0x80
+		h[31] |= 1
+		if x.SetBytes(h[:]).ProbablyPrime(20) {
+			return x
+		}
+	}
+}
+
+// accumulatorValid reports whether v is a residue modulo the accumulator
+// modulus other than zero.
+func accumulatorValid(v *big.Int) bool {
+	return v != nil && v.Sign() > 0 && v.Cmp(accumulatorModulus) < 0
+}
+
+// Accumulator maintains an accumulated set of ring members. It holds no
+// secrets and is not safe for concurrent use.
+type Accumulator struct {
+	value   *big.Int
+	members map[string]*big.Int
+}
+
+// AccumulatorUpdate records one change of an accumulator. Updates are
+// published so that witness holders can follow the set.
+type AccumulatorUpdate struct {
+	Member  *ecdsa.PublicKey // added or removed member
+	Removed bool             // whether the member was removed
+	Prev    *big.Int         // accumulated value before the change
+	Value   *big.Int         // accumulated value after the change
+}
+
+// NewAccumulator creates an empty accumulator.
+func NewAccumulator() *Accumulator {
+	return &Accumulator{
+		value:   accumulatorBase,
+		members: make(map[string]*big.Int),
+	}
+}
+
+// Value returns the current accumulated value. It must not be modified, the
+// accumulator replaces rather than changes its value.
+func (a *Accumulator) Value() *big.Int {
+	return a.value
+}
+
+// Len returns the number of accumulated members.
+func (a *Accumulator) Len() int {
+	return len(a.members)
+}
+
+// Contains reports whether pub is accumulated.
+func (a *Accumulator) Contains(pub *ecdsa.PublicKey) bool {
+	return pub != nil && a.members[string(pointBytes(pub))] != nil
+}
+
+// Add accumulates pub, which must be a valid ring member.
+func (a *Accumulator) Add(pub *ecdsa.PublicKey) (*AccumulatorUpdate, error) {
+	if err := checkMember(pub, pub); err != nil {
+		return nil, err
+	}
+	if a.Contains(pub) {
+		return nil, errAccumulated
+	}
+	x := accumulatorElement(pub)
+	update := &AccumulatorUpdate{Member: pub, Prev: a.value}
+	a.value = new(big.Int).Exp(a.value, x, accumulatorModulus)
+	a.members[string(pointBytes(pub))] = x
+	update.Value = a.value
+	return update, nil
+}
+
+// Remove removes pub from the set.
+func (a *Accumulator) Remove(pub *ecdsa.PublicKey) (*AccumulatorUpdate, error) {
+	if !a.Contains(pub) {
+		return nil, errNotAccumulated
+	}
+	update := &AccumulatorUpdate{Member: pub, Removed: true, Prev: a.value}
+	a.value = a.without(string(pointBytes(pub)))
+	delete(a.members, string(pointBytes(pub)))
+	update.Value = a.value
+	return update, nil
+}
+
+// without returns g raised to the product of all members but the given one.
+func (a *Accumulator) without(key string) *big.Int {
+	e := big.NewInt(1)
+	for k, x := range a.members {
+		if k != key {
+			e.Mul(e, x)
+		}
+	}
+	return new(big.Int).Exp(accumulatorBase, e, accumulatorModulus)
+}
+
+// Witness returns the membership witness of pub for the current value.
+func (a *Accumulator) Witness(pub *ecdsa.PublicKey) (*big.Int, error) {
+	if !a.Contains(pub) {
+		return nil, errNotAccumulated
+	}
+	return a.without(string(pointBytes(pub))), nil
+}
+
+// Update returns the witness of pub after the change u, given its witness
+// before the change.
+func (u *AccumulatorUpdate) Update(pub *ecdsa.PublicKey, witness *big.Int) (*big.Int, error) {
+	if !accumulatorValid(witness) || !accumulatorValid(u.Value) {
+		return nil, errNotAccumulated
+	}
+	x := accumulatorElement(pub)
+	y := accumulatorElement(u.Member)
+	if x.Cmp(y) == 0 {
+		if u.Removed {
+			return nil, errMemberRemoved
+		}
+		return nil, errAccumulated
+	}
+	if !u.Removed {
+		// W' = W^y
+		return new(big.Int).Exp(witness, y, accumulatorModulus), nil
+	}
+	// With a*x + b*y = 1 and A'^y = A = W^x, W' = W^b * A'^a satisfies
+	// W'^x = A'^(b*y) * A'^(a*x) = A'.
+	a, b := new(big.Int), new(big.Int)
+	new(big.Int).GCD(a, b, x, y)
+	return new(big.Int).Mod(new(big.Int).Mul(modExp(witness, b), modExp(u.Value, a)), accumulatorModulus), nil
+}
+
+// modExp returns v^e modulo the accumulator modulus for a possibly negative e.
+func modExp(v, e *big.Int) *big.Int {
+	if e.Sign() >= 0 {
+		return new(big.Int).Exp(v, e, accumulatorModulus)
+	}
+	inv := new(big.Int).ModInverse(v, accumulatorModulus)
+	return inv.Exp(inv, new(big.Int).Neg(e), accumulatorModulus)
+}
+
+// VerifyWitness reports whether witness proves that pub is accumulated in
+// value.
+func VerifyWitness(value *big.Int, pub *ecdsa.PublicKey, witness *big.Int) bool {
+	if !accumulatorValid(value) || pub == nil || !accumulatorValid(witness) {
+		return false
+	}
+	return new(big.Int).Exp(witness, accumulatorElement(pub), accumulatorModulus).Cmp(value) == 0
+}
+
+// AccumulatorSign is a ring signature over M by one of the members
+// accumulated in Value.
+type AccumulatorSign struct {
+	M         [32]byte   // message
+	Value     *big.Int   // accumulated value the ring was taken from
+	Witnesses []*big.Int // membership witnesses, one per ring member
+	Sig       *RingSign  // signature over the ring, see accumulatorMessage
+}
+
+// accumulatorMessage binds m to an accumulated value.
+func accumulatorMessage(value *big.Int, m [32]byte) [32]byte {
+	data := append([]byte{}, accumulatorDomain...)
+	data = append(append(data, math.PaddedBigBytes(value, (accumulatorModulus.BitLen()+7)/8)...), m[:]...)
+	return sha3.Sum256(data)
+}
+
+// SignAccumulated creates a signature over m with the key of ring member s.
+// The ring members must be accumulated in value, witnesses holds their
+// witnesses in ring order.
+func SignAccumulated(m [32]byte, value *big.Int, ring Ring, witnesses []*big.Int, privkey *ecdsa.PrivateKey, s int) (*AccumulatorSign, error) {
+	if len(witnesses) != len(ring) {
+		return nil, errWitnessCount
+	}
+	for i, pub := range ring {
+		if !VerifyWitness(value, pub, witnesses[i]) {
+			return nil, &MemberError{Index: i, Err: errNotAccumulated}
+		}
+	}
+	sig, err := Sign(accumulatorMessage(value, m), ring, privkey, s)
+	if err != nil {
+		return nil, err
+	}
+	return &AccumulatorSign{M: m, Value: value, Witnesses: witnesses, Sig: sig}, nil
+}
+
+// VerifyAccumulated verifies a signature by a member accumulated in value. It
+// returns true if a valid signature, false otherwise.
+func VerifyAccumulated(value *big.Int, sig *AccumulatorSign) bool {
+	if value == nil || sig == nil || sig.Sig == nil || sig.Value == nil || sig.Value.Cmp(value) != 0 {
+		return false
+	}
+	if sig.Sig.M != accumulatorMessage(value, sig.M) || len(sig.Witnesses) != len(sig.Sig.Ring) {
+		return false
+	}
+	for i, pub := range sig.Sig.Ring {
+		if !VerifyWitness(value, pub, sig.Witnesses[i]) {
+			return false
+		}
+	}
+	return Verify(sig.Sig)
+}
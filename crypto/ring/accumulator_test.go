@@ -0,0 +1,119 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAccumulator(t *testing.T) {
+	ring, _ := testRing(t, 5)
+	acc := NewAccumulator()
+	for _, pub := range ring[:4] {
+		if _, err := acc.Add(pub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := acc.Add(ring[0]); err != errAccumulated {
+		t.Errorf("duplicate member: got %v, want %v", err, errAccumulated)
+	}
+	if _, err := acc.Witness(ring[4]); err != errNotAccumulated {
+		t.Errorf("missing member: got %v, want %v", err, errNotAccumulated)
+	}
+	w, err := acc.Witness(ring[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyWitness(acc.Value(), ring[0], w) {
+		t.Fatal("witness does not verify")
+	}
+	if VerifyWitness(acc.Value(), ring[1], w) {
+		t.Error("witness verifies for other member")
+	}
+
+	// The member follows the set with the published updates only.
+	added, err := acc.Add(ring[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyWitness(acc.Value(), ring[0], w) {
+		t.Error("stale witness verifies")
+	}
+	if w, err = added.Update(ring[0], w); err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyWitness(acc.Value(), ring[0], w) {
+		t.Fatal("witness does not verify after addition")
+	}
+	removed, err := acc.Remove(ring[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, err = removed.Update(ring[0], w); err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyWitness(acc.Value(), ring[0], w) {
+		t.Fatal("witness does not verify after removal")
+	}
+	if fresh, err := acc.Witness(ring[0]); err != nil || fresh.Cmp(w) != 0 {
+		t.Errorf("updated witness differs from recomputed one: %v", err)
+	}
+	if VerifyWitness(acc.Value(), ring[0], acc.Value()) {
+		t.Error("accumulated value verifies as witness")
+	}
+	if _, err := removed.Update(ring[2], w); err != errMemberRemoved {
+		t.Errorf("removed member: got %v, want %v", err, errMemberRemoved)
+	}
+	if acc.Len() != 4 || acc.Contains(ring[2]) {
+		t.Error("removed member still accumulated")
+	}
+}
+
+func TestSignAccumulated(t *testing.T) {
+	ring, keys := testRing(t, 4)
+	acc := NewAccumulator()
+	for _, pub := range ring[:3] {
+		if _, err := acc.Add(pub); err != nil {
+			t.Fatal(err)
+		}
+	}
+	value := acc.Value()
+	witnesses := make([]*big.Int, 3)
+	for i := range witnesses {
+		var err error
+		if witnesses[i], err = acc.Witness(ring[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig, err := SignAccumulated([32]byte{1}, value, ring[:3], witnesses, keys[1], 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyAccumulated(value, sig) {
+		t.Fatal("signature does not verify")
+	}
+	sig.M[0] ^= 1
+	if VerifyAccumulated(value, sig) {
+		t.Error("signature verifies for other message")
+	}
+	sig.M[0] ^= 1
+
+	// A ring member outside the set is rejected on signing and verification.
+	witnesses[2] = witnesses[0]
+	if _, err := SignAccumulated([32]byte{1}, value, Ring{ring[0], ring[1], ring[3]}, witnesses, keys[1], 1); err == nil {
+		t.Error("signed with member outside set")
+	}
+	forged := *sig
+	if forged.Sig, err = Sign(sig.Sig.M, Ring{ring[0], ring[1], ring[3]}, keys[1], 1); err != nil {
+		t.Fatal(err)
+	}
+	if VerifyAccumulated(value, &forged) {
+		t.Error("signature verifies with member outside set")
+	}
+
+	if _, err := acc.Add(ring[3]); err != nil {
+		t.Fatal(err)
+	}
+	if VerifyAccumulated(acc.Value(), sig) {
+		t.Error("signature verifies against other value")
+	}
+}
@@ -0,0 +1,213 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+)
+
+// Audited signatures add selective traceability to linkable ring signatures.
+// Besides the ordinary signature the signer encrypts their public key to a
+// compliance auditor with ElGamal, (E1, E2) = (r*G, P_s + r*A), and proves in
+// zero knowledge that the ciphertext holds the key behind the signature's key
+// image. Everyone can verify the signature and the proof, but only the auditor
+// learns the signer, and can prove to third parties that it opened correctly.
+//
+// The proof is a disjunction over ring members j of the conjunction
+//
+//	P_j = x*G  and  I = x*H_p(P_j)  and  E1 = r*G  and  E2 - P_j = r*A
+//
+// so the encrypted key is the one whose private key produced the key image I.
+// Unlike escrowed signatures, audited signatures remain linkable and the audit
+// data can be stripped to obtain a plain RingSign.
+
+// auditDomain separates the hashes of audit proofs from other hashes in the
+// package.
+var auditDomain = []byte("go-ethereum/crypto/ring audit")
+
+// AuditedSign is a linkable ring signature whose signer is encrypted to an
+// auditor.
+type AuditedSign struct {
+	Sig     *RingSign        // linkable ring signature
+	Auditor *ecdsa.PublicKey // key of the auditor
+	E1, E2  *ecdsa.PublicKey // encryption of the signer's key to the auditor
+	C       []*big.Int       // challenges, one per ring member
+	S       []*big.Int       // key responses, one per ring member
+	T       []*big.Int       // encryption responses, one per ring member
+}
+
+// auditChallenge computes H(m, ring, I, A, E1, E2, a, b, d, e).
+func auditChallenge(sig *AuditedSign, a, b, d, e []*ecdsa.PublicKey) *big.Int {
+	data := [][]byte{auditDomain, sig.Sig.M[:]}
+	for _, p := range sig.Sig.Ring {
+		data = append(data, pointBytes(p))
+	}
+	data = append(data, pointBytes(auditImage(sig)), pointBytes(sig.Auditor), pointBytes(sig.E1), pointBytes(sig.E2))
+	for _, points := range [][]*ecdsa.PublicKey{a, b, d, e} {
+		for _, p := range points {
+			data = append(data, pointBytes(p))
+		}
+	}
+	return hashToScalar(sig.Sig.Curve, data...)
+}
+
+// auditHashPoint returns H_p(P) as used for the key image of sig.
+func auditHashPoint(sig *AuditedSign, p *ecdsa.PublicKey) *ecdsa.PublicKey {
+	x, y := memberHasher(transcriptVersion(sig.Sig))(p)
+	return newPoint(p.Curve, x, y)
+}
+
+// auditImage returns the key image of sig. GenKeyImage leaves its curve unset.
+func auditImage(sig *AuditedSign) *ecdsa.PublicKey {
+	return newPoint(sig.Sig.Curve, sig.Sig.I.X, sig.Sig.I.Y)
+}
+
+// auditCommitments recomputes the proof commitments of member j from its
+// challenge and responses.
+func auditCommitments(sig *AuditedSign, j int) (a, b, d, e *ecdsa.PublicKey) {
+	curve, P, c := sig.Sig.Curve, sig.Sig.Ring[j], sig.C[j]
+	a = pointAdd(baseMul(curve, sig.S[j]), pointMul(P, c))
+	b = pointAdd(pointMul(auditHashPoint(sig, P), sig.S[j]), pointMul(auditImage(sig), c))
+	d = pointAdd(baseMul(curve, sig.T[j]), pointMul(sig.E1, c))
+	e = pointAdd(pointMul(sig.Auditor, sig.T[j]), pointMul(pointSub(sig.E2, P), c))
+	return a, b, d, e
+}
+
+// SignAudited creates a linkable ring signature over m and encrypts the
+// signer's identity to auditor.
+func SignAudited(m [32]byte, ring Ring, auditor *ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int) (*AuditedSign, error) {
+	if auditor == nil {
+		return nil, errNoAuthority
+	}
+	if s >= 0 && s < len(ring) && !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	ringSig, err := Sign(m, ring, privkey, s)
+	if err != nil {
+		return nil, err
+	}
+	curve := privkey.Curve
+	N := curve.Params().N
+	n := len(ring)
+
+	// random scalars: encryption randomness r and the nonces u, v of the signer
+	rnd := make([]*big.Int, 3)
+	for i := range rnd {
+		if rnd[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+	}
+	r, u, v := rnd[0], rnd[1], rnd[2]
+
+	sig := &AuditedSign{
+		Sig:     ringSig,
+		Auditor: auditor,
+		E1:      baseMul(curve, r),
+		E2:      pointAdd(ring[s], pointMul(auditor, r)),
+		C:       make([]*big.Int, n),
+		S:       make([]*big.Int, n),
+		T:       make([]*big.Int, n),
+	}
+	a := make([]*ecdsa.PublicKey, n)
+	b := make([]*ecdsa.PublicKey, n)
+	d := make([]*ecdsa.PublicKey, n)
+	e := make([]*ecdsa.PublicKey, n)
+	for j := range ring {
+		if j == s {
+			a[j], b[j] = baseMul(curve, u), pointMul(auditHashPoint(sig, ring[s]), u)
+			d[j], e[j] = baseMul(curve, v), pointMul(auditor, v)
+			continue
+		}
+		for _, x := range []**big.Int{&sig.C[j], &sig.S[j], &sig.T[j]} {
+			if *x, err = randomScalar(curve); err != nil {
+				return nil, err
+			}
+		}
+		a[j], b[j], d[j], e[j] = auditCommitments(sig, j)
+	}
+	// c_s = c - sum_{j != s} c_j, s_s = u - c_s*x, t_s = v - c_s*r
+	c := auditChallenge(sig, a, b, d, e)
+	for j, cj := range sig.C {
+		if j != s {
+			c.Sub(c, cj)
+		}
+	}
+	sig.C[s] = c.Mod(c, N)
+
+	ss := new(big.Int).Mul(sig.C[s], privkey.D)
+	ss.Sub(u, ss)
+	sig.S[s] = ss.Mod(ss, N)
+	ts := new(big.Int).Mul(sig.C[s], r)
+	ts.Sub(v, ts)
+	sig.T[s] = ts.Mod(ts, N)
+
+	return sig, nil
+}
+
+// VerifyAudited verifies the ring signature and the audit proof of sig. It
+// does not reveal or require knowledge of the signer.
+// returns true if a valid signature, false otherwise
+func VerifyAudited(sig *AuditedSign) bool {
+	if sig == nil || sig.Sig == nil || sig.Sig.I == nil || sig.Sig.C == nil || sig.Sig.Curve == nil {
+		return false
+	}
+	if sig.Auditor == nil || sig.E1 == nil || sig.E2 == nil {
+		return false
+	}
+	n := len(sig.Sig.Ring)
+	if n < 2 || sig.Sig.Size != n || len(sig.Sig.S) != n {
+		return false
+	}
+	if len(sig.C) != n || len(sig.S) != n || len(sig.T) != n {
+		return false
+	}
+	a := make([]*ecdsa.PublicKey, n)
+	b := make([]*ecdsa.PublicKey, n)
+	d := make([]*ecdsa.PublicKey, n)
+	e := make([]*ecdsa.PublicKey, n)
+	sum := new(big.Int)
+	for j := range sig.Sig.Ring {
+		if sig.Sig.Ring[j] == nil || sig.Sig.S[j] == nil {
+			return false
+		}
+		if sig.C[j] == nil || sig.S[j] == nil || sig.T[j] == nil {
+			return false
+		}
+		a[j], b[j], d[j], e[j] = auditCommitments(sig, j)
+		sum.Add(sum, sig.C[j])
+	}
+	c := auditChallenge(sig, a, b, d, e)
+	if sum.Mod(sum, sig.Sig.Curve.Params().N).Cmp(c) != 0 {
+		return false
+	}
+	return Verify(sig.Sig)
+}
+
+// Audit decrypts the signer of a valid audited signature with the auditor's
+// private key. It returns the signer's index in the ring and a proof that the
+// decryption is correct.
+func Audit(sig *AuditedSign, auditor *ecdsa.PrivateKey) (int, *OpeningProof, error) {
+	if !pointEqual(sig.Auditor, &auditor.PublicKey) {
+		return -1, nil, errWrongAuthority
+	}
+	// P = E2 - a*E1
+	signer := pointSub(sig.E2, pointMul(sig.E1, auditor.D))
+	for i, pub := range sig.Sig.Ring {
+		if pointEqual(pub, signer) {
+			proof, err := proveOpening(auditDomain, auditor, sig.E1, sig.E2, signer)
+			if err != nil {
+				return -1, nil, err
+			}
+			return i, proof, nil
+		}
+	}
+	return -1, nil, errNotRingMember
+}
+
+// VerifyAudit checks that the ring member at index is the correct opening of
+// sig by the signature's auditor.
+func VerifyAudit(sig *AuditedSign, index int, proof *OpeningProof) bool {
+	if sig == nil || sig.Sig == nil || index < 0 || index >= len(sig.Sig.Ring) {
+		return false
+	}
+	return verifyOpening(sig.Sig.Curve, auditDomain, sig.Auditor, sig.E1, sig.E2, sig.Sig.Ring[index], proof)
+}
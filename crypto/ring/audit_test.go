@@ -0,0 +1,86 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestAudit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditor, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(5, key, 2)
+
+	sig, err := SignAudited([32]byte{9}, ring, &auditor.PublicKey, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyAudited(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	index, proof, err := Audit(sig, auditor)
+	if err != nil {
+		t.Fatalf("failed to audit: %v", err)
+	}
+	if index != 2 {
+		t.Fatalf("wrong signer: have %d, want 2", index)
+	}
+	if !VerifyAudit(sig, index, proof) {
+		t.Fatal("valid opening rejected")
+	}
+	if VerifyAudit(sig, 0, proof) {
+		t.Fatal("opening to the wrong member accepted")
+	}
+	// the audit data does not affect linkability of the signature
+	other, err := SignAudited([32]byte{10}, ring, &auditor.PublicKey, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pointEqual(sig.Sig.I, other.Sig.I) {
+		t.Fatal("audited signatures of the same key do not link")
+	}
+}
+
+func TestAuditWrongAuditor(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auditor, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(3, key, 0)
+
+	sig, err := SignAudited([32]byte{1}, ring, &auditor.PublicKey, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Audit(sig, other); err != errWrongAuthority {
+		t.Fatalf("wrong error: have %v, want %v", err, errWrongAuthority)
+	}
+}
+
+func TestAuditTampered(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auditor, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(4, key, 1)
+
+	sig, err := SignAudited([32]byte{2}, ring, &auditor.PublicKey, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// re-encrypting a different member breaks the proof
+	e2 := sig.E2
+	sig.E2 = pointAdd(pointSub(e2, ring[1]), ring[3])
+	if VerifyAudited(sig) {
+		t.Fatal("signature with swapped ciphertext accepted")
+	}
+	sig.E2 = e2
+
+	sig.Sig.M[0] ^= 1
+	if VerifyAudited(sig) {
+		t.Fatal("signature over modified message accepted")
+	}
+}
@@ -0,0 +1,128 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Every challenge of a linkable ring signature hashes the points computed from
+// the previous one, so unlike Triptych signatures they cannot be folded into
+// a single multi-scalar multiplication. Batch verification instead hashes each
+// distinct ring member to the curve only once, which pays off in blocks where
+// many signatures draw on the same outputs, and spreads the signatures over
+// all CPUs.
+
+// memberKey identifies a ring member across signatures, together with the
+// hash to the curve it is needed for.
+type memberKey struct {
+	curve  elliptic.Curve
+	point  string
+	mapped bool // hashed with HashToPoint rather than HashPoint
+}
+
+// wellFormed reports whether the fields of sig are consistent enough to
+// recompute its challenges.
+func wellFormed(sig *RingSign) bool {
+	if sig == nil || sig.Curve == nil || sig.C == nil || sig.I == nil || sig.I.X == nil || sig.I.Y == nil {
+		return false
+	}
+	if sig.Size < 2 || len(sig.S) != sig.Size || len(sig.Ring) != sig.Size || !validVersion(sig.Version) {
+		return false
+	}
+	for i, pub := range sig.Ring {
+		if pub == nil || pub.X == nil || pub.Y == nil || sig.S[i] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parallel calls fn(0), ..., fn(n-1) from as many goroutines as there are
+// CPUs.
+func parallel(n int, fn func(i int)) {
+	var (
+		next    int64 = -1
+		wg      sync.WaitGroup
+		workers = runtime.NumCPU()
+	)
+	if workers > n {
+		workers = n
+	}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := int(atomic.AddInt64(&next, 1)); i < n; i = int(atomic.AddInt64(&next, 1)) {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// VerifyBatch verifies many linkable ring signatures at once and returns the
+// result of each, in order. Malformed signatures are reported as invalid.
+func VerifyBatch(sigs []*RingSign) []bool {
+	defer batchTimer.UpdateSince(time.Now())
+	batchSigsMeter.Mark(int64(len(sigs)))
+
+	// collect the distinct ring members, taking the hashes of precomputed
+	// rings from the cache, and hash the remaining ones in parallel
+	index := make(map[memberKey]int)
+	var (
+		members []*ecdsa.PublicKey
+		mapped  []bool
+		hashes  [][2]*big.Int
+	)
+	for _, sig := range sigs {
+		if !wellFormed(sig) || checkScalars(sig) != nil {
+			continue
+		}
+		version := transcriptVersion(sig)
+		pre := lookupPrecomputed(sig.Ring)
+		for i, pub := range sig.Ring {
+			key := memberKey{sig.Curve, string(pointBytes(pub)), version == TranscriptV3}
+			if _, ok := index[key]; !ok {
+				index[key] = len(members)
+				members = append(members, pub)
+				mapped = append(mapped, key.mapped)
+				hashes = append(hashes, [2]*big.Int{})
+			}
+			if pre != nil {
+				hashes[index[key]] = pre.hashesOf(version)[i]
+			}
+		}
+	}
+	parallel(len(members), func(i int) {
+		if hashes[i][0] == nil {
+			if mapped[i] {
+				hashes[i][0], hashes[i][1] = HashToPoint(members[i])
+			} else {
+				hashes[i][0], hashes[i][1] = HashPoint(members[i])
+			}
+		}
+	})
+	hashPoint := func(sig *RingSign) func(*ecdsa.PublicKey) (*big.Int, *big.Int) {
+		mapped := transcriptVersion(sig) == TranscriptV3
+		return func(pub *ecdsa.PublicKey) (*big.Int, *big.Int) {
+			h := hashes[index[memberKey{sig.Curve, string(pointBytes(pub)), mapped}]]
+			return h[0], h[1]
+		}
+	}
+
+	results := make([]bool, len(sigs))
+	parallel(len(sigs), func(i int) {
+		sig := sigs[i]
+		if !wellFormed(sig) || checkScalars(sig) != nil {
+			return
+		}
+		C := challengeChainWith(sig, hashPoint(sig))
+		results[i] = sig.C.Cmp(C[sig.Size]) == 0
+	})
+	return results
+}
@@ -0,0 +1,81 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	ring := make(Ring, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		ring[i] = &keys[i].PublicKey
+	}
+	// all signatures share the same ring
+	var sigs []*RingSign
+	for i, key := range keys {
+		sig, err := Sign([32]byte{byte(i)}, ring, key, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+	}
+	// and a signature over an unrelated ring
+	key, _ := crypto.GenerateKey()
+	other, err := Sign([32]byte{0xff}, GenNewKeyRing(3, key, 1), key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs = append(sigs, other)
+
+	tampered := *sigs[1]
+	tampered.M[0] ^= 1
+	malformed := *sigs[2]
+	malformed.S = malformed.S[1:]
+	sigs = append(sigs, &tampered, &malformed, nil, &RingSign{})
+
+	results := VerifyBatch(sigs)
+	want := []bool{true, true, true, true, true, false, false, false, false}
+	if len(results) != len(want) {
+		t.Fatalf("have %d results, want %d", len(results), len(want))
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("signature %d: have %v, want %v", i, results[i], want[i])
+		}
+		if want[i] && !Verify(sigs[i]) {
+			t.Errorf("signature %d rejected by Verify", i)
+		}
+	}
+	if len(VerifyBatch(nil)) != 0 {
+		t.Error("results for empty batch")
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	keys := make([]*ecdsa.PrivateKey, 16)
+	ring := make(Ring, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		ring[i] = &keys[i].PublicKey
+	}
+	sigs := make([]*RingSign, 64)
+	for i := range sigs {
+		sigs[i], _ = Sign([32]byte{byte(i)}, ring, keys[i%len(keys)], i%len(keys))
+	}
+	b.Run("Verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, sig := range sigs {
+				Verify(sig)
+			}
+		}
+	})
+	b.Run("VerifyBatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			VerifyBatch(sigs)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BIP340 identifies secp256k1 public keys by their x coordinate alone and
+// implicitly picks the point with an even y coordinate. Rings of such x-only
+// keys, as used by Taproot outputs and other Schnorr based systems, are lifted
+// to full points here, and a signer whose key has an odd y coordinate negates
+// its secret scalar so that it matches the lifted point.
+//
+// BIP340 ring signatures are disjunctive Schnorr proofs whose challenge is a
+// BIP340 style tagged hash over the message, the x-only keys and the nonce
+// commitments in compressed form.
+
+// bip340Tag is the tag of the challenge hash of BIP340 ring signatures.
+const bip340Tag = "RingSig/BIP340/challenge"
+
+var errInvalidXOnly = errors.New("invalid x-only public key")
+
+// BIP340Sign is a ring signature over x-only secp256k1 keys.
+type BIP340Sign struct {
+	M    [32]byte   // message
+	Keys [][32]byte // x-only public keys of the ring members
+	C    []*big.Int // challenges, one per ring member
+	S    []*big.Int // responses, one per ring member
+}
+
+// TaggedHash returns the BIP340 tagged hash
+// SHA256(SHA256(tag) || SHA256(tag) || msg).
+func TaggedHash(tag string, msg ...[]byte) [32]byte {
+	th := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(th[:])
+	h.Write(th[:])
+	for _, b := range msg {
+		h.Write(b)
+	}
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// LiftX returns the secp256k1 point with x coordinate x and an even y
+// coordinate, as specified by BIP340.
+func LiftX(x [32]byte) (*ecdsa.PublicKey, error) {
+	curve := crypto.S256()
+	p := curve.Params().P
+
+	px := new(big.Int).SetBytes(x[:])
+	if px.Cmp(p) >= 0 {
+		return nil, errInvalidXOnly
+	}
+	// y^2 = x^3 + 7
+	c := new(big.Int).Exp(px, big.NewInt(3), p)
+	c.Add(c, curve.Params().B)
+	c.Mod(c, p)
+
+	py := new(big.Int).ModSqrt(c, p)
+	if py == nil {
+		return nil, errInvalidXOnly
+	}
+	if py.Bit(0) == 1 {
+		py.Sub(p, py)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: px, Y: py}, nil
+}
+
+// XOnly returns the 32 byte x-only encoding of pub.
+func XOnly(pub *ecdsa.PublicKey) [32]byte {
+	var x [32]byte
+	copy(x[:], math.PaddedBigBytes(pub.X, 32))
+	return x
+}
+
+// RingFromXOnly lifts a list of x-only public keys into a ring.
+func RingFromXOnly(keys [][32]byte) (Ring, error) {
+	ring := make(Ring, len(keys))
+	for i, x := range keys {
+		pub, err := LiftX(x)
+		if err != nil {
+			return nil, err
+		}
+		ring[i] = pub
+	}
+	return ring, nil
+}
+
+// NormalizeBIP340Key returns the private key whose public key is the even y
+// point with the same x coordinate as the public key of priv. The key is
+// returned unchanged if its y coordinate is already even.
+func NormalizeBIP340Key(priv *ecdsa.PrivateKey) *ecdsa.PrivateKey {
+	if priv.Y.Bit(0) == 0 {
+		return priv
+	}
+	N := priv.Curve.Params().N
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: priv.Curve,
+			X:     new(big.Int).Set(priv.X),
+			Y:     new(big.Int).Sub(priv.Curve.Params().P, priv.Y),
+		},
+		D: new(big.Int).Sub(N, priv.D),
+	}
+}
+
+// bip340Hasher returns the tagged challenge hash over m and the x-only keys.
+func bip340Hasher(m [32]byte, keys [][32]byte) orHasher {
+	return func(R []*ecdsa.PublicKey) *big.Int {
+		data := [][]byte{m[:]}
+		for i := range keys {
+			data = append(data, keys[i][:])
+		}
+		for _, r := range R {
+			if r == nil {
+				data = append(data, make([]byte, 33))
+				continue
+			}
+			data = append(data, crypto.CompressPubkey(r))
+		}
+		h := TaggedHash(bip340Tag, data...)
+		e := new(big.Int).SetBytes(h[:])
+		return e.Mod(e, crypto.S256().Params().N)
+	}
+}
+
+// SignBIP340 creates a ring signature over m on a ring of x-only keys. The
+// private key may have either y parity, its x-only key must be keys[s].
+func SignBIP340(m [32]byte, keys [][32]byte, privkey *ecdsa.PrivateKey, s int) (*BIP340Sign, error) {
+	if len(keys) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(keys) {
+		return nil, errIndexOutOfRange
+	}
+	if privkey.Curve != crypto.S256() || XOnly(&privkey.PublicKey) != keys[s] {
+		return nil, errNotSigner
+	}
+	ring, err := RingFromXOnly(keys)
+	if err != nil {
+		return nil, err
+	}
+	sig := &BIP340Sign{M: m, Keys: append([][32]byte{}, keys...)}
+	sig.C, sig.S, err = proveOr(ring, NormalizeBIP340Key(privkey), s, bip340Hasher(m, sig.Keys))
+	if err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// VerifyBIP340 verifies a ring signature over x-only keys.
+// returns true if a valid signature, false otherwise
+func VerifyBIP340(sig *BIP340Sign) bool {
+	if sig == nil {
+		return false
+	}
+	ring, err := RingFromXOnly(sig.Keys)
+	if err != nil {
+		return false
+	}
+	return verifyOr(crypto.S256(), ring, sig.C, sig.S, bip340Hasher(sig.M, sig.Keys))
+}
@@ -0,0 +1,68 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestLiftX(t *testing.T) {
+	// BIP340 test vector 0: secret key 3
+	key, err := crypto.HexToECDSA("0000000000000000000000000000000000000000000000000000000000000003")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := hex.DecodeString("f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9")
+	x := XOnly(&key.PublicKey)
+	if !bytes.Equal(x[:], want) {
+		t.Fatalf("x-only key mismatch: have %x, want %x", x, want)
+	}
+	pub, err := LiftX(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Y.Bit(0) != 0 {
+		t.Error("lifted point has odd y")
+	}
+	norm := NormalizeBIP340Key(key)
+	if norm.X.Cmp(pub.X) != 0 || norm.Y.Cmp(pub.Y) != 0 {
+		t.Error("normalized key does not match lifted point")
+	}
+	// x = p is not a field element
+	var p [32]byte
+	copy(p[:], crypto.S256().Params().P.Bytes())
+	if _, err := LiftX(p); err != errInvalidXOnly {
+		t.Errorf("x >= p: have %v, want %v", err, errInvalidXOnly)
+	}
+}
+
+func TestBIP340(t *testing.T) {
+	// sign with keys of both y parities
+	for _, parity := range []uint{0, 1} {
+		key, err := crypto.GenerateKey()
+		for err == nil && key.Y.Bit(0) != parity {
+			key, err = crypto.GenerateKey()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring := GenNewKeyRing(3, key, 1)
+		keys := make([][32]byte, len(ring))
+		for i, pub := range ring {
+			keys[i] = XOnly(pub)
+		}
+		sig, err := SignBIP340([32]byte{0x34, 0x0}, keys, key, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyBIP340(sig) {
+			t.Errorf("parity %d: valid signature rejected", parity)
+		}
+		sig.Keys[0], sig.Keys[2] = sig.Keys[2], sig.Keys[0]
+		if VerifyBIP340(sig) {
+			t.Errorf("parity %d: signature accepted for reordered ring", parity)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+// Blind ring signatures let a user obtain a ring signature on a message the
+// signer never sees. The scheme blinds the Cramer-Damgard-Schoenmakers
+// disjunctive Schnorr proof: a signature consists of a challenge c_i and a
+// response s_i per ring member such that
+//
+//	sum_i c_i = H(m, ring, s_0*G + c_0*P_0, ..., s_{n-1}*G + c_{n-1}*P_{n-1})
+//
+// The protocol takes three moves:
+//
+//  1. The signer simulates every ring member but itself and sends the nonce
+//     commitments R_i to the user (NewBlindSigner).
+//  2. The user shifts every commitment to R'_i = R_i + a_i*G + b_i*P_i with
+//     random a_i, b_i, hashes them with the message into c' and sends the
+//     blinded challenge c = c' - sum_i b_i to the signer (Blind).
+//  3. The signer closes its own challenge and returns all c_i, s_i
+//     (BlindSigner.Sign), which the user unblinds into c'_i = c_i + b_i and
+//     s'_i = s_i + a_i (Blinder.Unblind).
+//
+// The signer can neither link the final signature to the session nor learn the
+// message. As with all blind Schnorr schemes, a signer must not run many
+// sessions concurrently with the same user, since parallel sessions expose it
+// to ROS-style forgery attacks.
+
+// blindDomain separates the challenges of blind signatures from other hashes
+// in the package.
+var blindDomain = []byte("go-ethereum/crypto/ring blind")
+
+var (
+	errBlindCommitments = errors.New("number of nonce commitments differs from ring size")
+	errBlindResponse    = errors.New("invalid blind signer response")
+)
+
+// BlindRingSign is an unblinded ring signature obtained through the blind
+// signing protocol.
+type BlindRingSign struct {
+	M     [32]byte   // message
+	Ring  Ring       // array of public keys
+	C     []*big.Int // challenges, one per ring member
+	S     []*big.Int // responses, one per ring member
+	Curve elliptic.Curve
+}
+
+// blindChallenge computes H(m, ring, R_0, ..., R_{n-1}).
+func blindChallenge(curve elliptic.Curve, m [32]byte, ring Ring, R []*ecdsa.PublicKey) *big.Int {
+	data := [][]byte{blindDomain, m[:]}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	for _, r := range R {
+		data = append(data, pointBytes(r))
+	}
+	return hashToScalar(curve, data...)
+}
+
+// BlindSigner is the signer's side of one blind signing session.
+type BlindSigner struct {
+	ring Ring
+	key  *ecdsa.PrivateKey
+	idx  int
+	k    *big.Int
+	c, s []*big.Int // challenges and responses of all members
+}
+
+// NewBlindSigner starts a blind signing session for the owner of ring[s] and
+// returns the nonce commitments to send to the user.
+func NewBlindSigner(ring Ring, privkey *ecdsa.PrivateKey, s int) (*BlindSigner, []*ecdsa.PublicKey, error) {
+	if len(ring) < 2 {
+		return nil, nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, nil, errIndexOutOfRange
+	}
+	if !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, nil, errNotSigner
+	}
+	curve := privkey.Curve
+	signer := &BlindSigner{
+		ring: ring,
+		key:  privkey,
+		idx:  s,
+		c:    make([]*big.Int, len(ring)),
+		s:    make([]*big.Int, len(ring)),
+	}
+	R := make([]*ecdsa.PublicKey, len(ring))
+	for i := range ring {
+		var err error
+		if i == s {
+			if signer.k, err = randomScalar(curve); err != nil {
+				return nil, nil, err
+			}
+			R[i] = baseMul(curve, signer.k)
+			continue
+		}
+		if signer.c[i], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+		if signer.s[i], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+		R[i] = pointAdd(baseMul(curve, signer.s[i]), pointMul(ring[i], signer.c[i]))
+	}
+	return signer, R, nil
+}
+
+// Sign answers the user's blinded challenge c. It returns the challenges and
+// responses of all ring members. A session can only be signed once.
+func (b *BlindSigner) Sign(c *big.Int) ([]*big.Int, []*big.Int, error) {
+	if b.k == nil {
+		return nil, nil, errSessionState
+	}
+	N := b.key.Curve.Params().N
+
+	// c_s = c - sum_{i != s} c_i, s_s = k - c_s*x
+	cs := new(big.Int).Set(c)
+	for i, ci := range b.c {
+		if i != b.idx {
+			cs.Sub(cs, ci)
+		}
+	}
+	b.c[b.idx] = cs.Mod(cs, N)
+
+	ss := new(big.Int).Mul(cs, b.key.D)
+	ss.Sub(b.k, ss)
+	b.s[b.idx] = ss.Mod(ss, N)
+	b.k = nil
+
+	return b.c, b.s, nil
+}
+
+// Blinder is the user's side of one blind signing session.
+type Blinder struct {
+	m      [32]byte
+	ring   Ring
+	nonces []*ecdsa.PublicKey
+	alpha  []*big.Int
+	beta   []*big.Int
+	c      *big.Int
+}
+
+// Blind takes the signer's nonce commitments and returns the blinded challenge
+// to send back to the signer for message m.
+func Blind(m [32]byte, ring Ring, commitments []*ecdsa.PublicKey) (*Blinder, *big.Int, error) {
+	if len(ring) < 2 {
+		return nil, nil, errRingTooSmall
+	}
+	if len(commitments) != len(ring) {
+		return nil, nil, errBlindCommitments
+	}
+	curve := ring[0].Curve
+	N := curve.Params().N
+
+	b := &Blinder{
+		m:      m,
+		ring:   ring,
+		nonces: commitments,
+		alpha:  make([]*big.Int, len(ring)),
+		beta:   make([]*big.Int, len(ring)),
+	}
+	blinded := make([]*ecdsa.PublicKey, len(ring))
+	for i := range ring {
+		if commitments[i] == nil {
+			return nil, nil, errBlindCommitments
+		}
+		var err error
+		if b.alpha[i], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+		if b.beta[i], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+		// R'_i = R_i + a_i*G + b_i*P_i
+		blinded[i] = pointAdd(commitments[i], pointAdd(baseMul(curve, b.alpha[i]), pointMul(ring[i], b.beta[i])))
+	}
+	// c = H(m, ring, R') - sum_i b_i
+	c := blindChallenge(curve, m, ring, blinded)
+	for _, beta := range b.beta {
+		c.Sub(c, beta)
+	}
+	b.c = c.Mod(c, N)
+
+	return b, new(big.Int).Set(b.c), nil
+}
+
+// Unblind checks the signer's answer against the session and turns it into a
+// ring signature on the user's message.
+func (b *Blinder) Unblind(C, S []*big.Int) (*BlindRingSign, error) {
+	if len(C) != len(b.ring) || len(S) != len(b.ring) {
+		return nil, errBlindResponse
+	}
+	curve := b.ring[0].Curve
+	N := curve.Params().N
+
+	sum := new(big.Int)
+	sig := &BlindRingSign{
+		M:     b.m,
+		Ring:  b.ring,
+		C:     make([]*big.Int, len(b.ring)),
+		S:     make([]*big.Int, len(b.ring)),
+		Curve: curve,
+	}
+	for i, pub := range b.ring {
+		if C[i] == nil || S[i] == nil {
+			return nil, errBlindResponse
+		}
+		// the signer must have answered with the committed nonces
+		r := pointAdd(baseMul(curve, S[i]), pointMul(pub, C[i]))
+		if !pointEqual(r, b.nonces[i]) {
+			return nil, errBlindResponse
+		}
+		sum.Add(sum, C[i])
+
+		ci := new(big.Int).Add(C[i], b.beta[i])
+		sig.C[i] = ci.Mod(ci, N)
+		si := new(big.Int).Add(S[i], b.alpha[i])
+		sig.S[i] = si.Mod(si, N)
+	}
+	if sum.Mod(sum, N).Cmp(b.c) != 0 {
+		return nil, errBlindResponse
+	}
+	return sig, nil
+}
+
+// VerifyBlind verifies an unblinded ring signature.
+// returns true if a valid signature, false otherwise
+func VerifyBlind(sig *BlindRingSign) bool {
+	if sig == nil || len(sig.Ring) < 2 || len(sig.C) != len(sig.Ring) || len(sig.S) != len(sig.Ring) {
+		return false
+	}
+	curve := sig.Curve
+	N := curve.Params().N
+
+	sum := new(big.Int)
+	R := make([]*ecdsa.PublicKey, len(sig.Ring))
+	for i, pub := range sig.Ring {
+		if pub == nil || sig.C[i] == nil || sig.S[i] == nil {
+			return false
+		}
+		R[i] = pointAdd(baseMul(curve, sig.S[i]), pointMul(pub, sig.C[i]))
+		sum.Add(sum, sig.C[i])
+	}
+	return sum.Mod(sum, N).Cmp(blindChallenge(curve, sig.M, sig.Ring, R)) == 0
+}
@@ -0,0 +1,67 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBlind(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(4, key, 2)
+	msg := [32]byte{0xbb}
+
+	signer, nonces, err := NewBlindSigner(ring, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blinder, c, err := Blind(msg, ring, nonces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	C, S, err := signer.Sign(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := blinder.Unblind(C, S)
+	if err != nil {
+		t.Fatalf("failed to unblind: %v", err)
+	}
+	if !VerifyBlind(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	// the signer's transcript must not appear in the signature
+	for i := range C {
+		if C[i].Cmp(sig.C[i]) == 0 || S[i].Cmp(sig.S[i]) == 0 {
+			t.Errorf("member %d: signature not blinded", i)
+		}
+	}
+	sig.M[0] = 0
+	if VerifyBlind(sig) {
+		t.Error("signature over different message accepted")
+	}
+	if _, _, err := signer.Sign(c); err != errSessionState {
+		t.Errorf("session signed twice: %v", err)
+	}
+}
+
+func TestBlindBadResponse(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(3, key, 0)
+
+	signer, nonces, _ := NewBlindSigner(ring, key, 0)
+	blinder, c, _ := Blind([32]byte{}, ring, nonces)
+	C, S, _ := signer.Sign(c)
+
+	S[1] = new(big.Int).Add(S[1], big.NewInt(1))
+	if _, err := blinder.Unblind(C, S); err != errBlindResponse {
+		t.Fatalf("error mismatch: have %v, want %v", err, errBlindResponse)
+	}
+}
@@ -0,0 +1,43 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+func TestBLS12381(t *testing.T) {
+	ring := make(Ring, 3)
+	var key *ecdsa.PrivateKey
+	for i := range ring {
+		k, err := bls12381.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring[i] = &k.PublicKey
+		if i == 1 {
+			key = k
+		}
+	}
+	msg := [32]byte{0x12, 0x38, 0x1}
+
+	trip, err := SignTriptych(msg, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyTriptych(trip) {
+		t.Error("triptych signature rejected")
+	}
+	uniq, err := SignUnique(msg, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyUnique(uniq) {
+		t.Error("unique signature rejected")
+	}
+	uniq.M[0]++
+	if VerifyUnique(uniq) {
+		t.Error("signature over different message accepted")
+	}
+}
@@ -0,0 +1,151 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// BorromeanSign is a Borromean ring signature (Maxwell and Poelstra, 2015):
+// a set of rings whose challenge chains all start from the same value E0.
+// A valid signature proves knowledge of one private key in every ring while
+// costing only one shared challenge instead of one per ring. It is the
+// building block of bit-commitment range proofs, where each ring holds the
+// two candidate openings of a single bit commitment.
+type BorromeanSign struct {
+	M     [32]byte     // message
+	E0    *big.Int     // shared challenge
+	S     [][]*big.Int // signature values, one slice per ring
+	Rings []Ring       // public key rings
+	Curve elliptic.Curve
+}
+
+var (
+	errNoRings         = errors.New("no rings to sign")
+	errRingCount       = errors.New("number of rings, keys and indices differ")
+	errEmptyRing       = errors.New("empty ring")
+	errIndexOutOfRange = errors.New("secret index out of range of ring size")
+	errNotSigner       = errors.New("secret index in ring is not signer")
+)
+
+// borromeanChallenge computes e_{i,j+1} = H(m || R_{i,j} || i || j+1).
+func borromeanChallenge(curve elliptic.Curve, m [32]byte, r *ecdsa.PublicKey, i, j int) *big.Int {
+	var pos [16]byte
+	binary.BigEndian.PutUint64(pos[:8], uint64(i))
+	binary.BigEndian.PutUint64(pos[8:], uint64(j))
+	return hashToScalar(curve, m[:], pointBytes(r), pos[:])
+}
+
+// borromeanE0 computes the shared challenge from the last R of every ring.
+func borromeanE0(curve elliptic.Curve, m [32]byte, last []*ecdsa.PublicKey) *big.Int {
+	data := [][]byte{m[:]}
+	for _, r := range last {
+		data = append(data, pointBytes(r))
+	}
+	return hashToScalar(curve, data...)
+}
+
+// SignBorromean creates a Borromean ring signature over m. For every ring
+// rings[i], privkeys[i] must be the private key of the member at index s[i].
+func SignBorromean(m [32]byte, rings []Ring, privkeys []*ecdsa.PrivateKey, s []int) (*BorromeanSign, error) {
+	if len(rings) == 0 {
+		return nil, errNoRings
+	}
+	if len(rings) != len(privkeys) || len(rings) != len(s) {
+		return nil, errRingCount
+	}
+	curve := privkeys[0].Curve
+	for i, ring := range rings {
+		if len(ring) == 0 {
+			return nil, errEmptyRing
+		}
+		if s[i] < 0 || s[i] >= len(ring) {
+			return nil, errIndexOutOfRange
+		}
+		if !pointEqual(ring[s[i]], &privkeys[i].PublicKey) {
+			return nil, errNotSigner
+		}
+	}
+
+	k := make([]*big.Int, len(rings))
+	S := make([][]*big.Int, len(rings))
+	last := make([]*ecdsa.PublicKey, len(rings))
+
+	// walk every ring from the signer to its end, starting from R = k*G
+	for i, ring := range rings {
+		S[i] = make([]*big.Int, len(ring))
+
+		var err error
+		if k[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+		r := baseMul(curve, k[i])
+		for j := s[i] + 1; j < len(ring); j++ {
+			if S[i][j], err = randomScalar(curve); err != nil {
+				return nil, err
+			}
+			e := borromeanChallenge(curve, m, r, i, j)
+			r = pointAdd(baseMul(curve, S[i][j]), pointMul(ring[j], e))
+		}
+		last[i] = r
+	}
+
+	// tie all rings together with the shared challenge
+	e0 := borromeanE0(curve, m, last)
+
+	// walk every ring from its start to the signer and close it
+	N := curve.Params().N
+	for i, ring := range rings {
+		e := e0
+		for j := 0; j < s[i]; j++ {
+			var err error
+			if S[i][j], err = randomScalar(curve); err != nil {
+				return nil, err
+			}
+			r := pointAdd(baseMul(curve, S[i][j]), pointMul(ring[j], e))
+			e = borromeanChallenge(curve, m, r, i, j+1)
+		}
+		// s = k - e*x mod N
+		sj := new(big.Int).Mul(e, privkeys[i].D)
+		sj.Sub(k[i], sj)
+		S[i][s[i]] = sj.Mod(sj, N)
+	}
+
+	return &BorromeanSign{
+		M:     m,
+		E0:    e0,
+		S:     S,
+		Rings: rings,
+		Curve: curve,
+	}, nil
+}
+
+// VerifyBorromean verifies a Borromean ring signature.
+// returns true if a valid signature, false otherwise
+func VerifyBorromean(sig *BorromeanSign) bool {
+	if sig == nil || sig.E0 == nil || len(sig.Rings) == 0 || len(sig.S) != len(sig.Rings) {
+		return false
+	}
+	curve := sig.Curve
+	last := make([]*ecdsa.PublicKey, len(sig.Rings))
+	for i, ring := range sig.Rings {
+		if len(ring) == 0 || len(sig.S[i]) != len(ring) {
+			return false
+		}
+		e := sig.E0
+		var r *ecdsa.PublicKey
+		for j, pub := range ring {
+			if pub == nil || sig.S[i][j] == nil {
+				return false
+			}
+			if j > 0 {
+				e = borromeanChallenge(curve, sig.M, r, i, j)
+			}
+			r = pointAdd(baseMul(curve, sig.S[i][j]), pointMul(pub, e))
+		}
+		last[i] = r
+	}
+	return borromeanE0(curve, sig.M, last).Cmp(sig.E0) == 0
+}
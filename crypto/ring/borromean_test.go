@@ -0,0 +1,62 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestRings creates n rings of the given size along with the signing key
+// and its index for every ring.
+func newTestRings(t *testing.T, n, size int) ([]Ring, []*ecdsa.PrivateKey, []int) {
+	rings := make([]Ring, n)
+	keys := make([]*ecdsa.PrivateKey, n)
+	idx := make([]int, n)
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+		idx[i] = i % size
+		rings[i] = GenNewKeyRing(size, key, idx[i])
+	}
+	return rings, keys, idx
+}
+
+func TestBorromean(t *testing.T) {
+	rings, keys, idx := newTestRings(t, 4, 3)
+	msg := [32]byte{1, 2, 3}
+
+	sig, err := SignBorromean(msg, rings, keys, idx)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if !VerifyBorromean(sig) {
+		t.Fatal("valid signature rejected")
+	}
+
+	// tampering with the message must invalidate the signature
+	sig.M[0] ^= 1
+	if VerifyBorromean(sig) {
+		t.Error("signature over different message accepted")
+	}
+	sig.M[0] ^= 1
+
+	// tampering with any signature value must invalidate the signature
+	sig.S[2][1] = new(big.Int).Add(sig.S[2][1], big.NewInt(1))
+	if VerifyBorromean(sig) {
+		t.Error("tampered signature accepted")
+	}
+}
+
+func TestBorromeanWrongKey(t *testing.T) {
+	rings, keys, idx := newTestRings(t, 2, 3)
+	idx[1] = (idx[1] + 1) % 3
+
+	if _, err := SignBorromean([32]byte{}, rings, keys, idx); err != errNotSigner {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNotSigner)
+	}
+}
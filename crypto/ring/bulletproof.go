@@ -0,0 +1,441 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// Bulletproofs (Bunz et al., "Bulletproofs: Short Proofs for Confidential
+// Transactions and More", 2018) show that Pedersen commitments
+// V_j = gamma_j*G + v_j*H open to values in [0, 2^64) without revealing them.
+// A proof for m values is aggregated into a single proof whose size grows with
+// log(64*m), and consists of a commitment to the bit decomposition of the
+// values, a commitment to the polynomial t(X) = <l(X), r(X)>, and an inner
+// product argument for the vectors l and r.
+//
+// The value generator of the paper is H and the blinding generator is G, so
+// the commitments are the ones returned by Commit. Proofs over a number of
+// values that is not a power of two are padded with commitments to zero.
+
+const bulletproofBits = 64
+
+// bulletproofDomain separates the hashes and generators of range proofs from
+// other hashes in the package.
+var bulletproofDomain = []byte("go-ethereum/crypto/ring bulletproof")
+
+var (
+	errRangeCount = errors.New("number of values and blinding factors differ")
+	errRangeEmpty = errors.New("no values to prove")
+)
+
+var (
+	bulletproofLock sync.Mutex
+	bulletproofU    = make(map[elliptic.Curve]*ecdsa.PublicKey)
+	bulletproofG    = make(map[elliptic.Curve][]*ecdsa.PublicKey)
+	bulletproofH    = make(map[elliptic.Curve][]*ecdsa.PublicKey)
+)
+
+// RangeProof is an aggregated Bulletproofs range proof.
+type RangeProof struct {
+	V      Ring             // commitments to the values
+	A      *ecdsa.PublicKey // commitment to the bits of the values
+	S      *ecdsa.PublicKey // commitment to the blinding vectors
+	T1     *ecdsa.PublicKey // commitment to the coefficient t_1
+	T2     *ecdsa.PublicKey // commitment to the coefficient t_2
+	TauX   *big.Int         // blinding factor of t(x)
+	Mu     *big.Int         // blinding factor of A and S
+	T      *big.Int         // t(x) = <l(x), r(x)>
+	L      Ring             // left commitments of the inner product argument
+	R      Ring             // right commitments of the inner product argument
+	InnerA *big.Int         // final scalar of l
+	InnerB *big.Int         // final scalar of r
+	Curve  elliptic.Curve
+}
+
+// bulletproofGenerators returns the vector generators G_i and H_i and the
+// inner product generator U, all with unknown discrete logarithms.
+func bulletproofGenerators(curve elliptic.Curve, n int) ([]*ecdsa.PublicKey, []*ecdsa.PublicKey, *ecdsa.PublicKey) {
+	bulletproofLock.Lock()
+	defer bulletproofLock.Unlock()
+
+	u, ok := bulletproofU[curve]
+	if !ok {
+		u = hashToPoint(curve, bulletproofDomain, []byte("U"))
+		bulletproofU[curve] = u
+	}
+	gs, hs := bulletproofG[curve], bulletproofH[curve]
+	for i := len(gs); i < n; i++ {
+		var idx [8]byte
+		binary.BigEndian.PutUint64(idx[:], uint64(i))
+		gs = append(gs, hashToPoint(curve, bulletproofDomain, []byte("G"), idx[:]))
+		hs = append(hs, hashToPoint(curve, bulletproofDomain, []byte("H"), idx[:]))
+	}
+	bulletproofG[curve], bulletproofH[curve] = gs, hs
+	return gs[:n], hs[:n], u
+}
+
+// bulletproofHash chains a challenge over the previous one and the given
+// points.
+func bulletproofHash(curve elliptic.Curve, prev *big.Int, points ...*ecdsa.PublicKey) *big.Int {
+	data := [][]byte{bulletproofDomain, math.PaddedBigBytes(prev, 32)}
+	for _, p := range points {
+		data = append(data, pointBytes(p))
+	}
+	return hashToScalar(curve, data...)
+}
+
+// bulletproofScalars chains a challenge over the previous one and scalars.
+func bulletproofScalars(curve elliptic.Curve, prev *big.Int, scalars ...*big.Int) *big.Int {
+	data := [][]byte{bulletproofDomain, math.PaddedBigBytes(prev, 32)}
+	for _, s := range scalars {
+		data = append(data, math.PaddedBigBytes(s, 32))
+	}
+	return hashToScalar(curve, data...)
+}
+
+// nextPow2 returns the smallest power of two not less than n.
+func nextPow2(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
+
+// scalarPowers returns 1, x, ..., x^(n-1) modulo N.
+func scalarPowers(x *big.Int, n int, N *big.Int) []*big.Int {
+	pows := make([]*big.Int, n)
+	acc := big.NewInt(1)
+	for i := range pows {
+		pows[i] = new(big.Int).Set(acc)
+		acc.Mul(acc, x)
+		acc.Mod(acc, N)
+	}
+	return pows
+}
+
+// innerProduct returns <a, b> modulo N.
+func innerProduct(a, b []*big.Int, N *big.Int) *big.Int {
+	sum := new(big.Int)
+	for i := range a {
+		sum.Add(sum, new(big.Int).Mul(a[i], b[i]))
+	}
+	return sum.Mod(sum, N)
+}
+
+// vectorCommit returns r*G + <a, gs> + <b, hs>.
+func vectorCommit(curve elliptic.Curve, r *big.Int, a, b []*big.Int, gs, hs []*ecdsa.PublicKey) *ecdsa.PublicKey {
+	acc := baseMul(curve, r)
+	for i := range a {
+		acc = pointAdd(acc, pointMul(gs[i], a[i]))
+		acc = pointAdd(acc, pointMul(hs[i], b[i]))
+	}
+	return acc
+}
+
+// bulletproofDelta computes (z - z^2)*<1, y^nm> - sum_j z^(j+3)*<1, 2^n>.
+func bulletproofDelta(yPow []*big.Int, z *big.Int, m int, N *big.Int) *big.Int {
+	sumY := new(big.Int)
+	for _, yi := range yPow {
+		sumY.Add(sumY, yi)
+	}
+	zz := new(big.Int).Mul(z, z)
+	delta := new(big.Int).Sub(z, zz)
+	delta.Mul(delta, sumY)
+
+	ones := new(big.Int).Lsh(big.NewInt(1), bulletproofBits)
+	ones.Sub(ones, big.NewInt(1))
+	zj := new(big.Int).Mul(zz, z)
+	for j := 0; j < m; j++ {
+		delta.Sub(delta, new(big.Int).Mul(zj, ones))
+		zj.Mul(zj, z)
+		zj.Mod(zj, N)
+	}
+	return delta.Mod(delta, N)
+}
+
+// ProveRange creates an aggregated range proof that the commitments
+// Commit(curve, v_j, blinds_j) open to the 64 bit values v_j.
+func ProveRange(curve elliptic.Curve, values []uint64, blinds []*big.Int) (*RangeProof, error) {
+	if len(values) == 0 {
+		return nil, errRangeEmpty
+	}
+	if len(values) != len(blinds) {
+		return nil, errRangeCount
+	}
+	N := curve.Params().N
+	m := nextPow2(len(values))
+	nm := bulletproofBits * m
+	gs, hs, u := bulletproofGenerators(curve, nm)
+
+	proof := &RangeProof{V: make(Ring, len(values)), Curve: curve}
+	for j, v := range values {
+		proof.V[j] = Commit(curve, new(big.Int).SetUint64(v), blinds[j])
+	}
+	// bit decomposition aL and aR = aL - 1
+	aL := make([]*big.Int, nm)
+	aR := make([]*big.Int, nm)
+	for j := 0; j < m; j++ {
+		for k := 0; k < bulletproofBits; k++ {
+			var bit int64
+			if j < len(values) {
+				bit = int64(values[j]>>uint(k)) & 1
+			}
+			aL[j*bulletproofBits+k] = big.NewInt(bit)
+			aR[j*bulletproofBits+k] = new(big.Int).Mod(big.NewInt(bit-1), N)
+		}
+	}
+	alpha, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	rho, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	sL := make([]*big.Int, nm)
+	sR := make([]*big.Int, nm)
+	for i := range sL {
+		if sL[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+		if sR[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+	}
+	proof.A = vectorCommit(curve, alpha, aL, aR, gs, hs)
+	proof.S = vectorCommit(curve, rho, sL, sR, gs, hs)
+
+	y := bulletproofHash(curve, new(big.Int), append(append(Ring{}, proof.V...), proof.A, proof.S)...)
+	z := bulletproofScalars(curve, y)
+
+	// l(X) = l0 + l1*X, r(X) = r0 + r1*X
+	yPow := scalarPowers(y, nm, N)
+	zPow := scalarPowers(z, m+3, N)
+	l0 := make([]*big.Int, nm)
+	r0 := make([]*big.Int, nm)
+	r1 := make([]*big.Int, nm)
+	for i := range l0 {
+		j, k := i/bulletproofBits, uint(i%bulletproofBits)
+		l0[i] = new(big.Int).Sub(aL[i], z)
+		l0[i].Mod(l0[i], N)
+
+		d := new(big.Int).Lsh(zPow[j+2], k)
+		r0[i] = new(big.Int).Add(aR[i], z)
+		r0[i].Mul(r0[i], yPow[i])
+		r0[i].Add(r0[i], d)
+		r0[i].Mod(r0[i], N)
+
+		r1[i] = new(big.Int).Mul(yPow[i], sR[i])
+		r1[i].Mod(r1[i], N)
+	}
+	t1 := new(big.Int).Add(innerProduct(l0, r1, N), innerProduct(sL, r0, N))
+	t1.Mod(t1, N)
+	t2 := innerProduct(sL, r1, N)
+
+	tau1, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	tau2, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	proof.T1 = Commit(curve, t1, tau1)
+	proof.T2 = Commit(curve, t2, tau2)
+
+	x := bulletproofHash(curve, z, proof.T1, proof.T2)
+
+	// tau_x = tau2*x^2 + tau1*x + sum_j z^(j+2)*gamma_j
+	xx := new(big.Int).Mul(x, x)
+	proof.TauX = new(big.Int).Mul(tau2, xx)
+	proof.TauX.Add(proof.TauX, new(big.Int).Mul(tau1, x))
+	for j, gamma := range blinds {
+		proof.TauX.Add(proof.TauX, new(big.Int).Mul(zPow[j+2], gamma))
+	}
+	proof.TauX.Mod(proof.TauX, N)
+
+	proof.Mu = new(big.Int).Mul(rho, x)
+	proof.Mu.Add(proof.Mu, alpha)
+	proof.Mu.Mod(proof.Mu, N)
+
+	l := make([]*big.Int, nm)
+	r := make([]*big.Int, nm)
+	for i := range l {
+		l[i] = new(big.Int).Mul(sL[i], x)
+		l[i].Add(l[i], l0[i])
+		l[i].Mod(l[i], N)
+		r[i] = new(big.Int).Mul(r1[i], x)
+		r[i].Add(r[i], r0[i])
+		r[i].Mod(r[i], N)
+	}
+	proof.T = innerProduct(l, r, N)
+
+	// inner product argument over G_i and H'_i = y^-i * H_i
+	w := bulletproofScalars(curve, x, proof.TauX, proof.Mu, proof.T)
+	yInv := new(big.Int).ModInverse(y, N)
+	yInvPow := scalarPowers(yInv, nm, N)
+	hPrime := make([]*ecdsa.PublicKey, nm)
+	for i := range hPrime {
+		hPrime[i] = pointMul(hs[i], yInvPow[i])
+	}
+	proof.L, proof.R, proof.InnerA, proof.InnerB = proveInnerProduct(curve, gs, hPrime, pointMul(u, w), l, r, w)
+	return proof, nil
+}
+
+// proveInnerProduct runs the logarithmic inner product argument for
+// P = <a, gs> + <b, hs> + <a, b>*U, chaining challenges from prev.
+func proveInnerProduct(curve elliptic.Curve, gs, hs []*ecdsa.PublicKey, U *ecdsa.PublicKey, a, b []*big.Int, prev *big.Int) (Ring, Ring, *big.Int, *big.Int) {
+	N := curve.Params().N
+	var L, R Ring
+	for len(a) > 1 {
+		n := len(a) / 2
+		cL := innerProduct(a[:n], b[n:], N)
+		cR := innerProduct(a[n:], b[:n], N)
+
+		left := pointMul(U, cL)
+		right := pointMul(U, cR)
+		for i := 0; i < n; i++ {
+			left = pointAdd(left, pointAdd(pointMul(gs[n+i], a[i]), pointMul(hs[i], b[n+i])))
+			right = pointAdd(right, pointAdd(pointMul(gs[i], a[n+i]), pointMul(hs[n+i], b[i])))
+		}
+		L, R = append(L, left), append(R, right)
+
+		e := bulletproofHash(curve, prev, left, right)
+		eInv := new(big.Int).ModInverse(e, N)
+		prev = e
+
+		// fold the halves with e and its inverse
+		nextG := make([]*ecdsa.PublicKey, n)
+		nextH := make([]*ecdsa.PublicKey, n)
+		nextA := make([]*big.Int, n)
+		nextB := make([]*big.Int, n)
+		for i := 0; i < n; i++ {
+			nextG[i] = pointAdd(pointMul(gs[i], eInv), pointMul(gs[n+i], e))
+			nextH[i] = pointAdd(pointMul(hs[i], e), pointMul(hs[n+i], eInv))
+
+			nextA[i] = new(big.Int).Mul(a[i], e)
+			nextA[i].Add(nextA[i], new(big.Int).Mul(a[n+i], eInv))
+			nextA[i].Mod(nextA[i], N)
+			nextB[i] = new(big.Int).Mul(b[i], eInv)
+			nextB[i].Add(nextB[i], new(big.Int).Mul(b[n+i], e))
+			nextB[i].Mod(nextB[i], N)
+		}
+		gs, hs, a, b = nextG, nextH, nextA, nextB
+	}
+	return L, R, a[0], b[0]
+}
+
+// VerifyRangeProof verifies an aggregated range proof.
+// returns true if a valid proof, false otherwise
+func VerifyRangeProof(proof *RangeProof) bool {
+	if proof == nil || proof.Curve == nil || len(proof.V) == 0 {
+		return false
+	}
+	curve := proof.Curve
+	N := curve.Params().N
+	m := nextPow2(len(proof.V))
+	nm := bulletproofBits * m
+
+	rounds := 0
+	for 1<<uint(rounds) < nm {
+		rounds++
+	}
+	if len(proof.L) != rounds || len(proof.R) != rounds {
+		return false
+	}
+	for _, s := range []*big.Int{proof.TauX, proof.Mu, proof.T, proof.InnerA, proof.InnerB} {
+		if s == nil || s.Sign() < 0 || s.Cmp(N) >= 0 {
+			return false
+		}
+	}
+	for _, p := range proof.V {
+		if p == nil {
+			return false
+		}
+	}
+	gs, hs, u := bulletproofGenerators(curve, nm)
+
+	y := bulletproofHash(curve, new(big.Int), append(append(Ring{}, proof.V...), proof.A, proof.S)...)
+	z := bulletproofScalars(curve, y)
+	x := bulletproofHash(curve, z, proof.T1, proof.T2)
+	w := bulletproofScalars(curve, x, proof.TauX, proof.Mu, proof.T)
+	if y.Sign() == 0 || z.Sign() == 0 || x.Sign() == 0 || w.Sign() == 0 {
+		return false
+	}
+	yPow := scalarPowers(y, nm, N)
+	zPow := scalarPowers(z, m+3, N)
+	xx := new(big.Int).Mul(x, x)
+
+	// t*H + tau_x*G = sum_j z^(j+2)*V_j + delta*H + x*T1 + x^2*T2
+	H := GeneratorH(curve)
+	check := newMultiExp(curve)
+	check.add(H, new(big.Int).Sub(proof.T, bulletproofDelta(yPow, z, m, N)))
+	check.addBase(proof.TauX)
+	for j, V := range proof.V {
+		check.add(V, new(big.Int).Neg(zPow[j+2]))
+	}
+	check.add(proof.T1, new(big.Int).Neg(x))
+	check.add(proof.T2, new(big.Int).Neg(xx))
+	if check.sum() != nil {
+		return false
+	}
+
+	// recover the inner product challenges and the folding weights s_i
+	e := make([]*big.Int, rounds)
+	eInv := make([]*big.Int, rounds)
+	prev := w
+	for j := range e {
+		e[j] = bulletproofHash(curve, prev, proof.L[j], proof.R[j])
+		if e[j].Sign() == 0 {
+			return false
+		}
+		eInv[j] = new(big.Int).ModInverse(e[j], N)
+		prev = e[j]
+	}
+	s := make([]*big.Int, nm)
+	for i := range s {
+		s[i] = big.NewInt(1)
+		for j := 0; j < rounds; j++ {
+			if (i>>uint(rounds-1-j))&1 == 1 {
+				s[i].Mul(s[i], e[j])
+			} else {
+				s[i].Mul(s[i], eInv[j])
+			}
+			s[i].Mod(s[i], N)
+		}
+	}
+	// A + x*S - mu*G + sum_i (-z - a*s_i)*G_i + (z + y^-i*(d_i - b/s_i))*H_i
+	//   + w*(t - a*b)*U + sum_j e_j^2*L_j + e_j^-2*R_j = 0
+	yInvPow := scalarPowers(new(big.Int).ModInverse(y, N), nm, N)
+	ipa := newMultiExp(curve)
+	ipa.add(proof.A, big.NewInt(1))
+	ipa.add(proof.S, x)
+	ipa.addBase(new(big.Int).Neg(proof.Mu))
+	for i := 0; i < nm; i++ {
+		j, k := i/bulletproofBits, uint(i%bulletproofBits)
+
+		gi := new(big.Int).Mul(proof.InnerA, s[i])
+		gi.Add(gi, z)
+		ipa.add(gs[i], gi.Neg(gi))
+
+		hi := new(big.Int).Lsh(zPow[j+2], k)
+		hi.Sub(hi, new(big.Int).Mul(proof.InnerB, new(big.Int).ModInverse(s[i], N)))
+		hi.Mul(hi, yInvPow[i])
+		hi.Add(hi, z)
+		ipa.add(hs[i], hi)
+	}
+	ab := new(big.Int).Mul(proof.InnerA, proof.InnerB)
+	ipa.add(u, new(big.Int).Mul(w, new(big.Int).Sub(proof.T, ab)))
+	for j := range e {
+		ipa.add(proof.L[j], new(big.Int).Mul(e[j], e[j]))
+		ipa.add(proof.R[j], new(big.Int).Mul(eInv[j], eInv[j]))
+	}
+	return ipa.sum() == nil
+}
@@ -0,0 +1,64 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRangeProof(t *testing.T) {
+	curve := crypto.S256()
+	values := []uint64{0, 1, 1<<64 - 1}
+	blinds := make([]*big.Int, len(values))
+	for i := range blinds {
+		var err error
+		if blinds[i], err = randomScalar(curve); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// single proof
+	proof, err := ProveRange(curve, values[2:], blinds[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyRangeProof(proof) {
+		t.Fatal("valid single proof rejected")
+	}
+	// aggregated proof, padded to four values
+	proof, err = ProveRange(curve, values, blinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proof.L) != 8 {
+		t.Errorf("aggregated proof has %d rounds, want 8", len(proof.L))
+	}
+	if !VerifyRangeProof(proof) {
+		t.Fatal("valid aggregated proof rejected")
+	}
+	// swapping a commitment must invalidate the proof
+	proof.V[0] = Commit(curve, big.NewInt(5), blinds[0])
+	if VerifyRangeProof(proof) {
+		t.Error("proof accepted for different commitment")
+	}
+}
+
+func TestRangeProofOutOfRange(t *testing.T) {
+	curve := crypto.S256()
+	blind, err := randomScalar(curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := ProveRange(curve, []uint64{7}, []*big.Int{blind})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a commitment to -1 is the same point as to N-1, far outside the range
+	proof.V[0] = Commit(curve, big.NewInt(-1), blind)
+	if VerifyRangeProof(proof) {
+		t.Error("proof accepted for negative value")
+	}
+	if _, err := ProveRange(curve, []uint64{1}, nil); err != errRangeCount {
+		t.Errorf("missing blinds: have %v, want %v", err, errRangeCount)
+	}
+}
@@ -0,0 +1,84 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+// A response s_i and s_i + N produce the same points, so without range checks
+// every valid signature has many valid variants. Verify rejects responses
+// outside [0, N) and negative challenges. The challenge c[0] is not reduced:
+// it is the raw 256-bit hash closing the ring, which the final comparison
+// already pins to a single value, and on curves with orders below 2^256 it
+// would fail a check against N.
+//
+// Points admit variants as well: a coordinate x + P denotes the same point as
+// x to curve implementations that reduce their inputs, so a key image could
+// be encoded in several ways that all verify. VerifyStrict also requires all
+// points to be valid curve points with reduced coordinates, which leaves one
+// encoding per signature for deduplication by hash or key image bytes.
+
+var (
+	errChallengeRange = errors.New("challenge out of range")
+	errNonCanonical   = errors.New("point not in canonical form")
+	errMemberCurve    = errors.New("ring member on different curve than signature")
+)
+
+// maxChallenge bounds challenges, which are 256-bit hashes.
+var maxChallenge = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// checkScalars returns an error if a challenge or response of the well formed
+// signature sig is out of range.
+func checkScalars(sig *RingSign) error {
+	if sig.C.Sign() < 0 || sig.C.Cmp(maxChallenge) >= 0 {
+		return errChallengeRange
+	}
+	N := sig.Curve.Params().N
+	for _, s := range sig.S {
+		if s.Sign() < 0 || s.Cmp(N) >= 0 {
+			return errScalarRange
+		}
+	}
+	return nil
+}
+
+// CheckCanonical returns an error if the signature is not in its canonical
+// form: challenges and responses in range, and the ring members and key image
+// valid points on the curve of the signature with reduced coordinates.
+func (r *RingSign) CheckCanonical() error {
+	if !wellFormed(r) {
+		return errMalformedSignature
+	}
+	if err := checkScalars(r); err != nil {
+		return err
+	}
+	for _, pub := range r.Ring {
+		if pub.Curve != r.Curve {
+			return errMemberCurve
+		}
+		if !canonicalPoint(r.Curve, pub.X, pub.Y) {
+			return errNonCanonical
+		}
+	}
+	if !canonicalPoint(r.Curve, r.I.X, r.I.Y) {
+		return errNonCanonical
+	}
+	return nil
+}
+
+// canonicalPoint reports whether (x, y) is a point on curve other than the
+// identity, with coordinates in [0, P).
+func canonicalPoint(curve elliptic.Curve, x, y *big.Int) bool {
+	P := curve.Params().P
+	if x.Sign() < 0 || y.Sign() < 0 || x.Cmp(P) >= 0 || y.Cmp(P) >= 0 {
+		return false
+	}
+	return curve.IsOnCurve(x, y) && newPoint(curve, x, y) != nil
+}
+
+// VerifyStrict verifies a signature that must also be in canonical form, see
+// CheckCanonical. It returns true if a valid signature, false otherwise.
+func VerifyStrict(sig *RingSign) bool {
+	return sig.CheckCanonical() == nil && Verify(sig)
+}
@@ -0,0 +1,74 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+)
+
+func TestVerifyScalarRange(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	sig, err := Sign([32]byte{1}, ring, keys[2], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyStrict(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	N := sig.Curve.Params().N
+
+	shifted := *sig
+	shifted.S = append([]*big.Int{}, sig.S...)
+	shifted.S[1] = new(big.Int).Add(sig.S[1], N)
+	negated := *sig
+	negated.C = new(big.Int).Neg(sig.C)
+	wide := *sig
+	wide.C = new(big.Int).Add(sig.C, maxChallenge)
+
+	tests := []struct {
+		name string
+		sig  *RingSign
+		err  error
+	}{
+		{"response + N", &shifted, errScalarRange},
+		{"negative challenge", &negated, errChallengeRange},
+		{"challenge + 2^256", &wide, errChallengeRange},
+	}
+	for _, tt := range tests {
+		if Verify(tt.sig) {
+			t.Errorf("%s: signature accepted", tt.name)
+		}
+		if err := tt.sig.CheckCanonical(); err != tt.err {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+		if VerifyBatch([]*RingSign{tt.sig})[0] {
+			t.Errorf("%s: signature accepted by VerifyBatch", tt.name)
+		}
+	}
+}
+
+func TestCheckCanonicalPoints(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	sig, err := Sign([32]byte{1}, ring, keys[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	P := sig.Curve.Params().P
+
+	image := *sig
+	image.I = &ecdsa.PublicKey{Curve: sig.Curve, X: new(big.Int).Add(sig.I.X, P), Y: sig.I.Y}
+	member := *sig
+	member.Ring = Ring{ring[0], ring[1], &ecdsa.PublicKey{Curve: sig.Curve, X: ring[2].X, Y: new(big.Int).Add(ring[2].Y, big.NewInt(1))}}
+
+	for name, sig := range map[string]*RingSign{"unreduced key image": &image, "off-curve member": &member} {
+		if err := sig.CheckCanonical(); err != errNonCanonical {
+			t.Errorf("%s: got %v, want %v", name, err, errNonCanonical)
+		}
+		if VerifyStrict(sig) {
+			t.Errorf("%s: accepted by VerifyStrict", name)
+		}
+	}
+	if err := (&RingSign{}).CheckCanonical(); err != errMalformedSignature {
+		t.Errorf("empty signature: got %v, want %v", err, errMalformedSignature)
+	}
+}
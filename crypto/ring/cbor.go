@@ -0,0 +1,384 @@
+package ring
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// CBOR encoding follows the layout of a COSE_Sign1 message (RFC 8152) so that
+// clients already speaking CBOR and COSE can route and inspect signatures with
+// their existing tooling:
+//
+//	18([protected, unprotected, payload, signature])
+//
+// The protected header is a serialized map carrying the scheme, "lsag", or
+// "lsag-v2" and "lsag-v3" for signatures with the v2 and v3 transcripts, the
+// curve and the hash algorithm, the payload is the signed message and the
+// signature is an array [c, [s_i], [P_i], I] with compressed points. Only the
+// definite length subset of CBOR needed for this layout is supported.
+
+// COSE header labels. The curve and hash labels are in the private use range.
+const (
+	coseTagSign1     = 18
+	coseHeaderAlg    = 1
+	coseHeaderCurve  = -65537
+	coseHeaderHash   = -65538
+	coseHashSHA3_256 = "SHA3-256"
+)
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+)
+
+var (
+	errCBOR       = errors.New("malformed CBOR")
+	errCOSEHeader = errors.New("unsupported COSE header")
+)
+
+// cborWriter appends CBOR data items to a buffer.
+type cborWriter struct {
+	buf []byte
+}
+
+func (w *cborWriter) head(major byte, n uint64) {
+	switch {
+	case n < 24:
+		w.buf = append(w.buf, major<<5|byte(n))
+	case n <= 0xff:
+		w.buf = append(w.buf, major<<5|24, byte(n))
+	case n <= 0xffff:
+		w.buf = append(w.buf, major<<5|25, 0, 0)
+		binary.BigEndian.PutUint16(w.buf[len(w.buf)-2:], uint16(n))
+	case n <= 0xffffffff:
+		w.buf = append(w.buf, major<<5|26, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(w.buf[len(w.buf)-4:], uint32(n))
+	default:
+		w.buf = append(w.buf, major<<5|27, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(w.buf[len(w.buf)-8:], n)
+	}
+}
+
+func (w *cborWriter) int(n int64) {
+	if n < 0 {
+		w.head(cborNegInt, uint64(-1-n))
+		return
+	}
+	w.head(cborUint, uint64(n))
+}
+
+func (w *cborWriter) bytes(b []byte) {
+	w.head(cborBytes, uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *cborWriter) text(s string) {
+	w.head(cborText, uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// cborReader consumes CBOR data items from a buffer.
+type cborReader struct {
+	buf []byte
+}
+
+// head reads the initial byte and argument of the next data item. Indefinite
+// lengths and non-minimal arguments are rejected.
+func (r *cborReader) head() (byte, uint64, error) {
+	if len(r.buf) == 0 {
+		return 0, 0, errCBOR
+	}
+	major, info := r.buf[0]>>5, r.buf[0]&0x1f
+	r.buf = r.buf[1:]
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, errCBOR
+	}
+	size := 1 << (info - 24)
+	if len(r.buf) < size {
+		return 0, 0, errCBOR
+	}
+	var n uint64
+	for _, b := range r.buf[:size] {
+		n = n<<8 | uint64(b)
+	}
+	r.buf = r.buf[size:]
+	if (size == 1 && n < 24) || (size > 1 && n>>(uint(size)*4) == 0) {
+		return 0, 0, errCBOR
+	}
+	return major, n, nil
+}
+
+// expect reads a data item head of the given major type.
+func (r *cborReader) expect(major byte) (uint64, error) {
+	m, n, err := r.head()
+	if err != nil {
+		return 0, err
+	}
+	if m != major {
+		return 0, errCBOR
+	}
+	return n, nil
+}
+
+// length reads the head of an array or map and bounds its length by the
+// remaining input, as every element takes at least one byte.
+func (r *cborReader) length(major byte) (int, error) {
+	n, err := r.expect(major)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(r.buf)) {
+		return 0, errCBOR
+	}
+	return int(n), nil
+}
+
+func (r *cborReader) int() (int64, error) {
+	major, n, err := r.head()
+	if err != nil {
+		return 0, err
+	}
+	if n > 1<<62 {
+		return 0, errCBOR
+	}
+	switch major {
+	case cborUint:
+		return int64(n), nil
+	case cborNegInt:
+		return -1 - int64(n), nil
+	}
+	return 0, errCBOR
+}
+
+func (r *cborReader) bytes() ([]byte, error) {
+	n, err := r.expect(cborBytes)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.buf)) {
+		return nil, errCBOR
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b, nil
+}
+
+func (r *cborReader) text() (string, error) {
+	n, err := r.expect(cborText)
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(len(r.buf)) {
+		return "", errCBOR
+	}
+	s := string(r.buf[:n])
+	r.buf = r.buf[n:]
+	return s, nil
+}
+
+// coseProtected encodes the protected header of a signature on curve.
+func coseProtected(scheme, curve string) []byte {
+	w := new(cborWriter)
+	w.head(cborMap, 3)
+	w.int(coseHeaderAlg)
+	w.text(scheme)
+	w.int(coseHeaderCurve)
+	w.text(curve)
+	w.int(coseHeaderHash)
+	w.text(coseHashSHA3_256)
+	return w.buf
+}
+
+// parseCOSEProtected decodes a protected header and returns its scheme and
+// curve name.
+func parseCOSEProtected(b []byte) (string, string, error) {
+	r := &cborReader{buf: b}
+	n, err := r.length(cborMap)
+	if err != nil {
+		return "", "", err
+	}
+	headers := make(map[int64]string)
+	for i := 0; i < n; i++ {
+		label, err := r.int()
+		if err != nil {
+			return "", "", err
+		}
+		value, err := r.text()
+		if err != nil {
+			return "", "", err
+		}
+		if _, ok := headers[label]; ok {
+			return "", "", errCBOR
+		}
+		headers[label] = value
+	}
+	if len(r.buf) != 0 {
+		return "", "", errCBOR
+	}
+	if headers[coseHeaderHash] != coseHashSHA3_256 {
+		return "", "", errCOSEHeader
+	}
+	return headers[coseHeaderAlg], headers[coseHeaderCurve], nil
+}
+
+// MarshalCBOR encodes the signature as a COSE_Sign1 style CBOR message.
+func (r *RingSign) MarshalCBOR() ([]byte, error) {
+	name, err := CurveName(r.Curve)
+	if err != nil {
+		return nil, err
+	}
+	curve := r.Curve
+	scalar := scalarSize(curve)
+
+	w := new(cborWriter)
+	w.head(cborTag, coseTagSign1)
+	w.head(cborArray, 4)
+	scheme := "lsag"
+	switch encodedVersion(r) {
+	case TranscriptV2:
+		scheme = "lsag-v2"
+	case TranscriptV3:
+		scheme = "lsag-v3"
+	}
+	w.bytes(coseProtected(scheme, name))
+	w.head(cborMap, 0)
+	w.bytes(r.M[:])
+
+	w.head(cborArray, 4)
+	w.bytes(math.PaddedBigBytes(r.C, scalar))
+	w.head(cborArray, uint64(len(r.S)))
+	for _, s := range r.S {
+		w.bytes(math.PaddedBigBytes(s, scalar))
+	}
+	w.head(cborArray, uint64(len(r.Ring)))
+	for _, pub := range r.Ring {
+		w.bytes(compressPoint(curve, pub))
+	}
+	w.bytes(compressPoint(curve, newPoint(curve, r.I.X, r.I.Y)))
+	return w.buf, nil
+}
+
+// UnmarshalCBOR decodes a signature encoded by MarshalCBOR.
+func (r *RingSign) UnmarshalCBOR(b []byte) error {
+	rd := &cborReader{buf: b}
+	if tag, err := rd.expect(cborTag); err != nil || tag != coseTagSign1 {
+		return errCBOR
+	}
+	if n, err := rd.length(cborArray); err != nil || n != 4 {
+		return errCBOR
+	}
+	protected, err := rd.bytes()
+	if err != nil {
+		return err
+	}
+	scheme, name, err := parseCOSEProtected(protected)
+	if err != nil {
+		return err
+	}
+	var version byte
+	switch scheme {
+	case "lsag":
+	case "lsag-v2":
+		version = TranscriptV2
+	case "lsag-v3":
+		version = TranscriptV3
+	default:
+		return errCOSEHeader
+	}
+	curve, err := CurveByName(name)
+	if err != nil {
+		return err
+	}
+	// no unprotected headers are defined
+	if n, err := rd.length(cborMap); err != nil || n != 0 {
+		return errCOSEHeader
+	}
+	m, err := rd.bytes()
+	if err != nil {
+		return err
+	}
+	if len(m) != 32 {
+		return errEncodingLength
+	}
+	if n, err := rd.length(cborArray); err != nil || n != 4 {
+		return errCBOR
+	}
+	scalar := scalarSize(curve)
+	readScalar := func() (*big.Int, error) {
+		b, err := rd.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != scalar {
+			return nil, errEncodingLength
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	c, err := readScalar()
+	if err != nil {
+		return err
+	}
+	n, err := rd.length(cborArray)
+	if err != nil {
+		return err
+	}
+	S := make([]*big.Int, n)
+	for i := range S {
+		if S[i], err = readScalar(); err != nil {
+			return err
+		}
+	}
+	if k, err := rd.length(cborArray); err != nil || k != n {
+		return errSignatureSize
+	}
+	ring := make(Ring, n)
+	for i := range ring {
+		b, err := rd.bytes()
+		if err != nil {
+			return err
+		}
+		if ring[i], err = decompressPoint(curve, b); err != nil {
+			return err
+		}
+		if ring[i] == nil {
+			return errInvalidPoint
+		}
+	}
+	b, err = rd.bytes()
+	if err != nil {
+		return err
+	}
+	image, err := decompressPoint(curve, b)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return errInvalidPoint
+	}
+	if len(rd.buf) != 0 {
+		return errCBOR
+	}
+	*r = RingSign{
+		Size:    n,
+		C:       c,
+		S:       S,
+		Ring:    ring,
+		I:       image,
+		Curve:   curve,
+		Version: version,
+	}
+	copy(r.M[:], m)
+	return nil
+}
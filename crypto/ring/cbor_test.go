@@ -0,0 +1,91 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Examples from RFC 8949 appendix A.
+func TestCBORHead(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{500, "1901f4"},
+		{1000000, "1a000f4240"},
+		{1000000000000, "1b000000e8d4a51000"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+	}
+	for _, tt := range tests {
+		w := new(cborWriter)
+		w.int(tt.n)
+		if got := hex.EncodeToString(w.buf); got != tt.want {
+			t.Errorf("%d: have %s, want %s", tt.n, got, tt.want)
+		}
+		r := &cborReader{buf: w.buf}
+		if n, err := r.int(); err != nil || n != tt.n {
+			t.Errorf("%d: decoded %d, %v", tt.n, n, err)
+		}
+	}
+	// non-minimal and indefinite length encodings are rejected
+	for _, enc := range []string{"1817", "190017", "5f", "9f"} {
+		b, _ := hex.DecodeString(enc)
+		if _, _, err := (&cborReader{buf: b}).head(); err != errCBOR {
+			t.Errorf("%s: accepted", enc)
+		}
+	}
+}
+
+func TestRingSignCBOR(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(4, key, 3)
+	sig, err := Sign([32]byte{4}, ring, key, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := sig.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// COSE_Sign1 tag followed by a four element array
+	if !bytes.HasPrefix(enc, []byte{0xd2, 0x84}) {
+		t.Fatalf("unexpected envelope: %x", enc[:2])
+	}
+	var dec RingSign
+	if err := dec.UnmarshalCBOR(enc); err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(&dec) {
+		t.Fatal("decoded signature rejected")
+	}
+	if err := dec.UnmarshalCBOR(append(enc, 0)); err != errCBOR {
+		t.Fatalf("trailing data: wrong error: have %v, want %v", err, errCBOR)
+	}
+	if err := dec.UnmarshalCBOR(enc[:len(enc)-1]); err == nil {
+		t.Fatal("truncated message accepted")
+	}
+}
+
+func TestCOSEProtected(t *testing.T) {
+	scheme, curve, err := parseCOSEProtected(coseProtected("lsag", "secp256k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheme != "lsag" || curve != "secp256k1" {
+		t.Fatalf("wrong header: %s, %s", scheme, curve)
+	}
+	w := new(cborWriter)
+	w.head(cborMap, 1)
+	w.int(coseHeaderHash)
+	w.text("SHA-256")
+	if _, _, err := parseCOSEProtected(w.buf); err != errCOSEHeader {
+		t.Fatalf("wrong error: have %v, want %v", err, errCOSEHeader)
+	}
+}
@@ -0,0 +1,162 @@
+// Package chainkeys assembles rings of public keys from Ethereum addresses.
+//
+// Ethereum accounts are known by their address, the hash of their public key,
+// while rings are made of the public keys themselves. Every transaction an
+// account sends reveals its public key through the signature, so a ring of
+// addresses can be assembled by recovering the senders of transactions found on
+// chain. Accounts that never sent a transaction cannot be part of a ring.
+package chainkeys
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+var (
+	errTxSignature  = errors.New("invalid transaction signature")
+	errRingTooSmall = errors.New("ring needs at least two addresses")
+)
+
+// BlockReader retrieves blocks by number, with nil denoting the head of the
+// chain. It is implemented by ethclient.Client.
+type BlockReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// MissingKeysError is returned when the public keys of some addresses could
+// not be recovered, usually because they never sent a transaction.
+type MissingKeysError struct {
+	Addresses []common.Address
+}
+
+func (err *MissingKeysError) Error() string {
+	return fmt.Sprintf("no public key found for %d address(es), first %x", len(err.Addresses), err.Addresses[0])
+}
+
+// TxPublicKey recovers the public key of the sender of tx. Transactions
+// protected by EIP-155 are hashed with their own chain ID, the rest with the
+// homestead rules.
+func TxPublicKey(tx *types.Transaction) (*ecdsa.PublicKey, error) {
+	var signer types.Signer = types.HomesteadSigner{}
+	v, r, s := tx.RawSignatureValues()
+	v = new(big.Int).Set(v)
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+		v.Sub(v, new(big.Int).Mul(tx.ChainId(), big.NewInt(2)))
+		v.Sub(v, big.NewInt(8))
+	}
+	if v.BitLen() > 8 || v.Uint64() < 27 {
+		return nil, errTxSignature
+	}
+	recid := byte(v.Uint64() - 27)
+	if !crypto.ValidateSignatureValues(recid, r, s, false) {
+		return nil, errTxSignature
+	}
+	sig := make([]byte, 65)
+	copy(sig[32-len(r.Bytes()):32], r.Bytes())
+	copy(sig[64-len(s.Bytes()):64], s.Bytes())
+	sig[64] = recid
+
+	hash := signer.Hash(tx)
+	return crypto.SigToPub(hash[:], sig)
+}
+
+// RingBuilder assembles rings from addresses by scanning the chain for
+// transactions sent by them. Recovered keys are kept, so rings over
+// overlapping address sets only scan the chain once.
+type RingBuilder struct {
+	chain   BlockReader
+	keys    map[common.Address]*ecdsa.PublicKey
+	scanned map[uint64]bool
+}
+
+// NewRingBuilder creates a ring builder reading blocks from chain.
+func NewRingBuilder(chain BlockReader) *RingBuilder {
+	return &RingBuilder{
+		chain:   chain,
+		keys:    make(map[common.Address]*ecdsa.PublicKey),
+		scanned: make(map[uint64]bool),
+	}
+}
+
+// AddTransaction records the public key of the sender of tx.
+func (b *RingBuilder) AddTransaction(tx *types.Transaction) error {
+	pub, err := TxPublicKey(tx)
+	if err != nil {
+		return err
+	}
+	b.keys[crypto.PubkeyToAddress(*pub)] = pub
+	return nil
+}
+
+// PublicKey returns the recovered public key of addr, if any.
+func (b *RingBuilder) PublicKey(addr common.Address) (*ecdsa.PublicKey, bool) {
+	pub, ok := b.keys[addr]
+	return pub, ok
+}
+
+// missing returns the addresses whose public key is not known yet.
+func (b *RingBuilder) missing(addrs []common.Address) []common.Address {
+	var missing []common.Address
+	for _, addr := range addrs {
+		if _, ok := b.keys[addr]; !ok {
+			missing = append(missing, addr)
+		}
+	}
+	return missing
+}
+
+// Build returns the ring of the public keys of addrs, in the same order, see
+// PublicKeys. Duplicate addresses are reported as a *ring.MemberError.
+func (b *RingBuilder) Build(ctx context.Context, addrs []common.Address, from, to uint64) (ring.Ring, error) {
+	if len(addrs) < 2 {
+		return nil, errRingTooSmall
+	}
+	keys, err := b.PublicKeys(ctx, addrs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := ring.Ring(keys).Validate(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// PublicKeys returns the public keys of addrs, in the same order. Blocks from
+// to down to from are scanned, newest first, until all keys are found. If
+// some addresses have no transaction in that range, a *MissingKeysError
+// listing them is returned.
+func (b *RingBuilder) PublicKeys(ctx context.Context, addrs []common.Address, from, to uint64) ([]*ecdsa.PublicKey, error) {
+	for number := to; number >= from && len(b.missing(addrs)) > 0; number-- {
+		if !b.scanned[number] {
+			block, err := b.chain.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+			if err != nil {
+				return nil, err
+			}
+			for _, tx := range block.Transactions() {
+				// a malformed transaction cannot hide keys of other senders
+				b.AddTransaction(tx)
+			}
+			b.scanned[number] = true
+		}
+		if number == 0 {
+			break
+		}
+	}
+	if missing := b.missing(addrs); len(missing) > 0 {
+		return nil, &MissingKeysError{Addresses: missing}
+	}
+	keys := make([]*ecdsa.PublicKey, len(addrs))
+	for i, addr := range addrs {
+		keys[i] = b.keys[addr]
+	}
+	return keys, nil
+}
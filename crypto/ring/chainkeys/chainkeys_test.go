@@ -0,0 +1,113 @@
+package chainkeys
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// testChain is an in-memory BlockReader counting block retrievals.
+type testChain struct {
+	blocks []*types.Block
+	reads  int
+}
+
+func (c *testChain) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if number == nil {
+		number = big.NewInt(int64(len(c.blocks) - 1))
+	}
+	if number.Uint64() >= uint64(len(c.blocks)) {
+		return nil, ethereum.NotFound
+	}
+	c.reads++
+	return c.blocks[number.Uint64()], nil
+}
+
+func signedTx(t *testing.T, signer types.Signer, key *ecdsa.PrivateKey) *types.Transaction {
+	tx := types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	tx, err := types.SignTx(tx, signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestTxPublicKey(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	for _, signer := range []types.Signer{types.HomesteadSigner{}, types.NewEIP155Signer(big.NewInt(1)), types.NewEIP155Signer(big.NewInt(1337))} {
+		pub, err := TxPublicKey(signedTx(t, signer, key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ring.PublicKeyEqual(pub, &key.PublicKey) {
+			t.Errorf("%T: wrong public key recovered", signer)
+		}
+	}
+}
+
+func TestRingBuilder(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	addrs := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	chain := &testChain{blocks: []*types.Block{
+		types.NewBlock(&types.Header{Number: big.NewInt(0)}, nil, nil, nil),
+		types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{signedTx(t, types.HomesteadSigner{}, keys[0])}, nil, nil),
+		types.NewBlock(&types.Header{Number: big.NewInt(2)}, []*types.Transaction{signedTx(t, signer, keys[1]), signedTx(t, signer, keys[2])}, nil, nil),
+	}}
+	builder := NewRingBuilder(chain)
+
+	pair, err := builder.Build(context.Background(), addrs[1:3], 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.reads != 1 {
+		t.Errorf("scanned %d blocks, want 1", chain.reads)
+	}
+	if !ring.PublicKeyEqual(pair[0], &keys[1].PublicKey) || !ring.PublicKeyEqual(pair[1], &keys[2].PublicKey) {
+		t.Fatal("ring does not match addresses")
+	}
+	all, err := builder.Build(context.Background(), addrs[:3], 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.reads != 2 {
+		t.Errorf("scanned %d blocks, want 2", chain.reads)
+	}
+	for i, pub := range all {
+		if !ring.PublicKeyEqual(pub, &keys[i].PublicKey) {
+			t.Fatalf("ring member %d does not match address", i)
+		}
+	}
+
+	// A single key can be looked up, though it makes no ring
+	if _, err := builder.Build(context.Background(), addrs[:1], 0, 2); err != errRingTooSmall {
+		t.Errorf("have error %v, want %v", err, errRingTooSmall)
+	}
+	single, err := builder.PublicKeys(context.Background(), addrs[:1], 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ring.PublicKeyEqual(single[0], &keys[0].PublicKey) {
+		t.Error("public key does not match address")
+	}
+
+	_, err = builder.Build(context.Background(), addrs, 0, 2)
+	missing, ok := err.(*MissingKeysError)
+	if !ok {
+		t.Fatalf("have error %v, want missing keys", err)
+	}
+	if len(missing.Addresses) != 1 || missing.Addresses[0] != addrs[3] {
+		t.Errorf("wrong missing addresses %x", missing.Addresses)
+	}
+}
@@ -0,0 +1,211 @@
+package chainkeys
+
+import (
+	"context"
+	"crypto/ecdsa"
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	mrand "math/rand"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// Decoys picked uniformly among recent senders give away the signer: real
+// signers usually spent funds they received recently, so the newest member of
+// a ring is the signer far more often than chance. A DecoySelector follows
+// Monero and samples the age of every decoy from the distribution of real
+// spends, a gamma distribution over the logarithm of the output age in
+// seconds, then picks a random output of the block of that age. Outputs are
+// the public keys revealed by transactions, so keys of busy senders are
+// proportionally more likely, as they are for real signers.
+
+const (
+	// DecoyGammaShape and DecoyGammaScale parametrise the gamma distribution of
+	// the log of decoy ages in seconds, as fitted to real spends by Möser et
+	// al. and used by Monero.
+	DecoyGammaShape = 19.28
+	DecoyGammaScale = 1 / 1.61
+
+	// decoyAttempts bounds the samples drawn per requested decoy before
+	// giving up, as ages beyond the window or spent keys are rejected.
+	decoyAttempts = 100
+)
+
+// decoyBlock holds the outputs of a block.
+type decoyBlock struct {
+	time uint64
+	keys []*ecdsa.PublicKey
+}
+
+// DecoySelector samples decoy ring members among the outputs of recent
+// blocks. Scanned blocks are kept, so selecting again only scans new blocks.
+// A DecoySelector is not safe for concurrent use.
+type DecoySelector struct {
+	chain  BlockReader
+	window uint64
+
+	// Shape and Scale of the gamma distribution of the log of decoy ages in
+	// seconds, DecoyGammaShape and DecoyGammaScale by default.
+	Shape, Scale float64
+
+	blocks map[uint64]*decoyBlock
+	spent  map[common.Address]bool
+	rand   *mrand.Rand
+}
+
+// NewDecoySelector creates a decoy selector sampling outputs of the last
+// window blocks read from chain.
+func NewDecoySelector(chain BlockReader, window uint64) *DecoySelector {
+	return &DecoySelector{
+		chain:  chain,
+		window: window,
+		Shape:  DecoyGammaShape,
+		Scale:  DecoyGammaScale,
+		blocks: make(map[uint64]*decoyBlock),
+		spent:  make(map[common.Address]bool),
+		rand:   mrand.New(cryptoSource{}),
+	}
+}
+
+// MarkSpent excludes keys known to be spent from selection. A spent decoy
+// doesn't hide the signer, it only shrinks the effective ring.
+func (s *DecoySelector) MarkSpent(keys ...*ecdsa.PublicKey) {
+	for _, key := range keys {
+		s.spent[crypto.PubkeyToAddress(*key)] = true
+	}
+}
+
+// Select returns n distinct decoys, none of them spent or among exclude, which
+// usually holds the key of the signer.
+func (s *DecoySelector) Select(ctx context.Context, n int, exclude ...*ecdsa.PublicKey) (ring.Ring, error) {
+	head, first, err := s.scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[common.Address]bool, n+len(exclude))
+	for _, key := range exclude {
+		taken[crypto.PubkeyToAddress(*key)] = true
+	}
+	var (
+		decoys = make(ring.Ring, 0, n)
+		now    = s.blocks[head].time
+	)
+	for attempt := 0; len(decoys) < n && attempt < n*decoyAttempts; attempt++ {
+		age := math.Exp(s.gamma())
+		if age > float64(now) {
+			continue
+		}
+		block := s.blockAt(head, first, now-uint64(age))
+		if block == nil || len(block.keys) == 0 {
+			continue
+		}
+		key := block.keys[s.rand.Intn(len(block.keys))]
+		addr := crypto.PubkeyToAddress(*key)
+		if taken[addr] || s.spent[addr] {
+			continue
+		}
+		taken[addr] = true
+		decoys = append(decoys, key)
+	}
+	if len(decoys) < n {
+		return nil, fmt.Errorf("only %d of %d decoys found in the last %d blocks", len(decoys), n, head-first+1)
+	}
+	return decoys, nil
+}
+
+// scan reads the blocks of the window not scanned yet, and returns the number
+// of the head block and of the first block of the window.
+func (s *DecoySelector) scan(ctx context.Context) (uint64, uint64, error) {
+	block, err := s.chain.BlockByNumber(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	head := block.NumberU64()
+	first := uint64(0)
+	if s.window > 0 && head >= s.window {
+		first = head - s.window + 1
+	}
+	for number := head; number >= first; number-- {
+		if s.blocks[number] == nil {
+			if number != head {
+				if block, err = s.chain.BlockByNumber(ctx, new(big.Int).SetUint64(number)); err != nil {
+					return 0, 0, err
+				}
+			}
+			scanned := &decoyBlock{time: block.Time().Uint64()}
+			for _, tx := range block.Transactions() {
+				if pub, err := TxPublicKey(tx); err == nil {
+					scanned.keys = append(scanned.keys, pub)
+				}
+			}
+			s.blocks[number] = scanned
+		}
+		if number == 0 {
+			break
+		}
+	}
+	// Forget blocks that left the window
+	for number := range s.blocks {
+		if number < first || number > head {
+			delete(s.blocks, number)
+		}
+	}
+	return head, first, nil
+}
+
+// blockAt returns the newest block of the window not younger than time, or nil
+// if time precedes the window.
+func (s *DecoySelector) blockAt(head, first, time uint64) *decoyBlock {
+	// Binary search for the first block younger than time
+	lo, hi := first, head+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if s.blocks[mid].time > time {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	if lo == first {
+		return nil
+	}
+	return s.blocks[lo-1]
+}
+
+// gamma samples the gamma distribution of the selector with the method of
+// Marsaglia and Tsang, which requires a shape of at least one.
+func (s *DecoySelector) gamma() float64 {
+	d := s.Shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := s.rand.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := s.rand.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v * s.Scale
+		}
+	}
+}
+
+// cryptoSource is a math/rand source reading from crypto/rand, so that decoy
+// choices can't be predicted from earlier ones.
+type cryptoSource struct{}
+
+func (cryptoSource) Int63() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("reading from crypto/rand failed: " + err.Error())
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) >> 1)
+}
+
+func (cryptoSource) Seed(int64) {}
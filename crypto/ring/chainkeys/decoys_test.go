@@ -0,0 +1,92 @@
+package chainkeys
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// decoyChain creates a chain of blocks ten seconds apart, each with two
+// transactions of new senders.
+func decoyChain(t *testing.T, blocks int) (*testChain, []*ecdsa.PrivateKey) {
+	var (
+		chain  = new(testChain)
+		keys   []*ecdsa.PrivateKey
+		signer = types.NewEIP155Signer(big.NewInt(1))
+	)
+	for i := 0; i < blocks; i++ {
+		var txs []*types.Transaction
+		for j := 0; j < 2; j++ {
+			key, _ := crypto.GenerateKey()
+			keys = append(keys, key)
+			txs = append(txs, signedTx(t, signer, key))
+		}
+		header := &types.Header{Number: big.NewInt(int64(i)), Time: big.NewInt(int64(1000 + 10*i))}
+		chain.blocks = append(chain.blocks, types.NewBlock(header, txs, nil, nil))
+	}
+	return chain, keys
+}
+
+func TestDecoySelector(t *testing.T) {
+	chain, keys := decoyChain(t, 50)
+	selector := NewDecoySelector(chain, 40)
+	selector.Shape, selector.Scale = 10, 0.5 // ages around e^5 seconds, within the window
+
+	signer, spent := &keys[99].PublicKey, &keys[98].PublicKey
+	selector.MarkSpent(spent)
+	decoys, err := selector.Select(context.Background(), 10, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoys) != 10 {
+		t.Fatalf("have %d decoys, want 10", len(decoys))
+	}
+	seen := make(map[common.Address]bool)
+	for _, key := range decoys {
+		addr := crypto.PubkeyToAddress(*key)
+		if seen[addr] {
+			t.Errorf("duplicate decoy %x", addr)
+		}
+		seen[addr] = true
+	}
+	if seen[crypto.PubkeyToAddress(*signer)] || seen[crypto.PubkeyToAddress(*spent)] {
+		t.Error("excluded or spent key selected")
+	}
+	// Outputs of blocks before the window must never be picked
+	for _, key := range keys[:20] {
+		if seen[crypto.PubkeyToAddress(key.PublicKey)] {
+			t.Error("decoy selected outside of the window")
+		}
+	}
+	// Selecting again only scans new blocks
+	reads := chain.reads
+	if _, err := selector.Select(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+	if chain.reads != reads+1 {
+		t.Errorf("rescanned %d blocks, want only the head", chain.reads-reads)
+	}
+	if _, err := selector.Select(context.Background(), 81); err == nil {
+		t.Error("selected more decoys than outputs in the window")
+	}
+}
+
+func TestDecoyGamma(t *testing.T) {
+	selector := NewDecoySelector(nil, 0)
+
+	var sum float64
+	const samples = 20000
+	for i := 0; i < samples; i++ {
+		sum += selector.gamma()
+	}
+	mean, want := sum/samples, DecoyGammaShape*DecoyGammaScale
+	if math.Abs(mean-want) > 0.02*want {
+		t.Errorf("gamma mean %f, want %f", mean, want)
+	}
+}
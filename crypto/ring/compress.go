@@ -0,0 +1,218 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// Compressed encodings store every point in a fixed number of bytes: SEC1
+// compressed form on short Weierstrass curves, i.e. 33 bytes on secp256k1, and
+// the curve's own encoding on curves that provide one, such as ristretto255.
+// They halve the size of rings and signatures compared to the X||Y format.
+
+var (
+	errInvalidPoint   = errors.New("invalid compressed point")
+	errEncodingLength = errors.New("invalid encoding length")
+	errSignatureSize  = errors.New("signature size does not match ring")
+)
+
+// pointCodec is implemented by curves with a native compressed encoding.
+type pointCodec interface {
+	Marshal(x, y *big.Int) []byte
+	Unmarshal(b []byte) (*big.Int, *big.Int, error)
+}
+
+// coordinateSize returns the byte width of a coordinate on curve.
+func coordinateSize(curve elliptic.Curve) int {
+	if bits := curve.Params().BitSize; bits > 256 {
+		return (bits + 7) / 8
+	}
+	return 32
+}
+
+// scalarSize returns the byte width of a scalar modulo the group order.
+func scalarSize(curve elliptic.Curve) int {
+	return (curve.Params().N.BitLen() + 7) / 8
+}
+
+// CompressedSize returns the length of a compressed point on curve.
+func CompressedSize(curve elliptic.Curve) int {
+	if codec, ok := curve.(pointCodec); ok {
+		params := curve.Params()
+		return len(codec.Marshal(params.Gx, params.Gy))
+	}
+	return 1 + coordinateSize(curve)
+}
+
+// compressPoint encodes p in compressed form. The point at infinity is encoded
+// as all zeroes on short Weierstrass curves.
+func compressPoint(curve elliptic.Curve, p *ecdsa.PublicKey) []byte {
+	if codec, ok := curve.(pointCodec); ok {
+		if p == nil {
+			x, y := curve.ScalarBaseMult(make([]byte, 1))
+			return codec.Marshal(x, y)
+		}
+		return codec.Marshal(p.X, p.Y)
+	}
+	if p == nil {
+		return make([]byte, CompressedSize(curve))
+	}
+	return append([]byte{0x02 | byte(p.Y.Bit(0))}, math.PaddedBigBytes(p.X, coordinateSize(curve))...)
+}
+
+// decompressPoint decodes a point compressed by compressPoint and checks that
+// it lies on curve.
+func decompressPoint(curve elliptic.Curve, b []byte) (*ecdsa.PublicKey, error) {
+	if len(b) != CompressedSize(curve) {
+		return nil, errEncodingLength
+	}
+	if codec, ok := curve.(pointCodec); ok {
+		x, y, err := codec.Unmarshal(b)
+		if err != nil {
+			return nil, err
+		}
+		return newPoint(curve, x, y), nil
+	}
+	if b[0] == 0 {
+		for _, c := range b[1:] {
+			if c != 0 {
+				return nil, errInvalidPoint
+			}
+		}
+		return nil, nil
+	}
+	if b[0] != 0x02 && b[0] != 0x03 {
+		return nil, errInvalidPoint
+	}
+	params := curve.Params()
+	p := params.P
+	x := new(big.Int).SetBytes(b[1:])
+	if x.Cmp(p) >= 0 {
+		return nil, errInvalidPoint
+	}
+	// y^2 = x^3 + ax + b
+	rhs := new(big.Int).Exp(x, big.NewInt(3), p)
+	rhs.Add(rhs, new(big.Int).Mul(curveA(curve), x))
+	rhs.Add(rhs, params.B)
+	rhs.Mod(rhs, p)
+
+	y := new(big.Int).ModSqrt(rhs, p)
+	if y == nil {
+		return nil, errInvalidPoint
+	}
+	if y.Bit(0) != uint(b[0]&1) {
+		y.Sub(p, y)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errInvalidPoint
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// Compress returns the concatenated compressed encodings of the ring members.
+// The ring must not be empty.
+func (r Ring) Compress() []byte {
+	if len(r) == 0 {
+		return nil
+	}
+	curve := r[0].Curve
+	b := make([]byte, 0, len(r)*CompressedSize(curve))
+	for _, pub := range r {
+		b = append(b, compressPoint(curve, pub)...)
+	}
+	return b
+}
+
+// DecompressRing decodes a ring of keys on curve encoded by Ring.Compress.
+func DecompressRing(curve elliptic.Curve, b []byte) (Ring, error) {
+	size := CompressedSize(curve)
+	if len(b)%size != 0 {
+		return nil, errEncodingLength
+	}
+	ring := make(Ring, len(b)/size)
+	for i := range ring {
+		pub, err := decompressPoint(curve, b[i*size:(i+1)*size])
+		if err != nil {
+			return nil, err
+		}
+		if pub == nil {
+			return nil, errInvalidPoint
+		}
+		ring[i] = pub
+	}
+	return ring, nil
+}
+
+// SerializeCompressed converts the signature to its compressed byte form:
+//
+//	version (1) || size (7) || m (32) || c || n * (s_i || P_i) || I
+//
+// with scalars padded to the group order size and compressed points. The
+// version is zero for the original transcript.
+func (r *RingSign) SerializeCompressed() []byte {
+	curve := r.Curve
+	scalar := scalarSize(curve)
+
+	sig := make([]byte, 8, 8+32+scalar+r.Size*(scalar+CompressedSize(curve))+CompressedSize(curve))
+	binary.BigEndian.PutUint64(sig, joinSize(encodedVersion(r), r.Size))
+	sig = append(sig, r.M[:]...)
+	sig = append(sig, math.PaddedBigBytes(r.C, scalar)...)
+	for i := 0; i < r.Size; i++ {
+		sig = append(sig, math.PaddedBigBytes(r.S[i], scalar)...)
+		sig = append(sig, compressPoint(curve, r.Ring[i])...)
+	}
+	return append(sig, compressPoint(curve, newPoint(curve, r.I.X, r.I.Y))...)
+}
+
+// DeserializeCompressedSignature decodes a signature on curve serialized by
+// SerializeCompressed.
+func DeserializeCompressedSignature(curve elliptic.Curve, b []byte) (*RingSign, error) {
+	scalar, point := scalarSize(curve), CompressedSize(curve)
+	if len(b) < 8+32+scalar+point {
+		return nil, errEncodingLength
+	}
+	version, size := splitSize(binary.BigEndian.Uint64(b[:8]))
+	if !validVersion(version) {
+		return nil, errTranscriptVersion
+	}
+	if size > uint64(len(b)) || uint64(len(b)) != uint64(8+32+scalar+point)+size*uint64(scalar+point) {
+		return nil, errSignatureSize
+	}
+	sig := &RingSign{
+		Size:    int(size),
+		C:       new(big.Int).SetBytes(b[40 : 40+scalar]),
+		S:       make([]*big.Int, size),
+		Ring:    make(Ring, size),
+		Curve:   curve,
+		Version: version,
+	}
+	copy(sig.M[:], b[8:40])
+
+	b = b[40+scalar:]
+	for i := range sig.Ring {
+		sig.S[i] = new(big.Int).SetBytes(b[:scalar])
+		pub, err := decompressPoint(curve, b[scalar:scalar+point])
+		if err != nil {
+			return nil, err
+		}
+		if pub == nil {
+			return nil, errInvalidPoint
+		}
+		sig.Ring[i] = pub
+		b = b[scalar+point:]
+	}
+	image, err := decompressPoint(curve, b)
+	if err != nil {
+		return nil, err
+	}
+	if image == nil {
+		return nil, errInvalidPoint
+	}
+	sig.I = image
+	return sig, nil
+}
@@ -0,0 +1,97 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ristretto255"
+)
+
+func TestCompressPoint(t *testing.T) {
+	for _, curve := range []elliptic.Curve{crypto.S256(), elliptic.P256(), elliptic.P384(), ristretto255.Curve()} {
+		for i := 0; i < 8; i++ {
+			k, _ := randomScalar(curve)
+			p := baseMul(curve, k)
+			b := compressPoint(curve, p)
+			if len(b) != CompressedSize(curve) {
+				t.Fatalf("%s: wrong size: have %d, want %d", curve.Params().Name, len(b), CompressedSize(curve))
+			}
+			q, err := decompressPoint(curve, b)
+			if err != nil {
+				t.Fatalf("%s: %v", curve.Params().Name, err)
+			}
+			if !pointEqual(p, q) {
+				t.Fatalf("%s: point mismatch after round trip", curve.Params().Name)
+			}
+		}
+	}
+	if CompressedSize(crypto.S256()) != 33 {
+		t.Fatalf("wrong secp256k1 size: have %d, want 33", CompressedSize(crypto.S256()))
+	}
+	// compatible with the SEC1 encoding of the crypto package
+	key, _ := crypto.GenerateKey()
+	if !bytes.Equal(compressPoint(key.Curve, &key.PublicKey), crypto.CompressPubkey(&key.PublicKey)) {
+		t.Fatal("encoding differs from crypto.CompressPubkey")
+	}
+}
+
+func TestDecompressInvalid(t *testing.T) {
+	curve := crypto.S256()
+	b := make([]byte, 33)
+	b[0] = 0x04
+	if _, err := decompressPoint(curve, b); err != errInvalidPoint {
+		t.Fatalf("wrong error: have %v, want %v", err, errInvalidPoint)
+	}
+	// x = 5 gives x^3 + 7 = 132, which is not a square modulo p
+	b[0], b[32] = 0x02, 5
+	if _, err := decompressPoint(curve, b); err != errInvalidPoint {
+		t.Fatalf("wrong error: have %v, want %v", err, errInvalidPoint)
+	}
+	if _, err := decompressPoint(curve, b[:32]); err != errEncodingLength {
+		t.Fatalf("wrong error: have %v, want %v", err, errEncodingLength)
+	}
+}
+
+func TestRingCompress(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := Ring(GenNewKeyRing(5, key, 2))
+
+	b := ring.Compress()
+	if len(b) != 5*33 {
+		t.Fatalf("wrong length: have %d, want %d", len(b), 5*33)
+	}
+	dec, err := DecompressRing(crypto.S256(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range ring {
+		if !pointEqual(ring[i], dec[i]) {
+			t.Fatalf("member %d mismatch", i)
+		}
+	}
+}
+
+func TestSerializeCompressed(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(4, key, 1)
+	sig, err := Sign([32]byte{6}, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := sig.SerializeCompressed()
+	if want := 8 + 32 + 32 + 4*(32+33) + 33; len(b) != want {
+		t.Fatalf("wrong length: have %d, want %d", len(b), want)
+	}
+	dec, err := DeserializeCompressedSignature(crypto.S256(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(dec) {
+		t.Fatal("decoded signature rejected")
+	}
+	if _, err := DeserializeCompressedSignature(crypto.S256(), b[:len(b)-1]); err != errSignatureSize {
+		t.Fatalf("wrong error: have %v, want %v", err, errSignatureSize)
+	}
+}
@@ -0,0 +1,408 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// Sign walks the ring starting at the secret index, encodes scalars with
+// big.Int.Bytes and closes the ring with math/big, so its timing depends on
+// both the index and the key. The constant-time signing path below avoids
+// that:
+//
+//   - the ring is walked in the same rotated order, but every member, hash and
+//     response is fetched by scanning all entries, and every challenge is
+//     stored the same way, so memory accesses do not depend on the index
+//   - the signer's own step uses the same formula as the decoys,
+//     L = a*G + b*P and R = a*H_p(P) + b*I, with a random b and a = u - b*x
+//   - scalars are reduced modulo the group order with fixed-width Montgomery
+//     arithmetic, and passed to the curve in fixed-width encoding
+//
+// The curve operations themselves are only as constant-time as the curve
+// implementation: libsecp256k1 behind crypto.S256 in cgo builds, and the
+// standard library NIST curves. The point additions go through curve.Add;
+// their operands are either derived from the published signature or
+// uniformly random.
+
+var (
+	errIndexRange = errors.New("secret index out of range of ring size")
+	errSigner     = errors.New("secret index in ring is not signer")
+)
+
+// SignOpts holds optional settings for SignWithOpts.
+type SignOpts struct {
+	// ConstantTime selects the signing path whose timing does not depend on
+	// the secret index or key.
+	ConstantTime bool
+
+	// Nonces selects how the random values of the signature are obtained.
+	Nonces NonceMode
+
+	// Version selects the transcript of the challenge hashes, zero for
+	// DefaultTranscript.
+	Version byte
+}
+
+// SignWithOpts creates a ring signature like Sign, with the settings in opts.
+// A nil opts is the same as calling Sign.
+func SignWithOpts(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int, opts *SignOpts) (*RingSign, error) {
+	if opts == nil {
+		opts = new(SignOpts)
+	}
+	if opts.ConstantTime {
+		return signConstantTime(m, ring, privkey, s, opts)
+	}
+	return sign(m, ring, privkey, s, opts)
+}
+
+// SignConstantTime creates a ring signature whose computation does not branch
+// on, or index memory by, the secret index s or the private key. The resulting
+// signature is verified by Verify like any other.
+func SignConstantTime(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int) (*RingSign, error) {
+	return signConstantTime(m, ring, privkey, s, new(SignOpts))
+}
+
+// signConstantTime is SignConstantTime with the nonce mode and transcript
+// version of opts.
+func signConstantTime(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int, opts *SignOpts) (*RingSign, error) {
+	n := len(ring)
+	if n < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= n {
+		return nil, errIndexRange
+	}
+	if !validVersion(opts.Version) {
+		return nil, errTranscriptVersion
+	}
+	curve := privkey.Curve
+	for _, pub := range ring {
+		if pub == nil || pub.Curve != curve {
+			return nil, errCurveMismatch
+		}
+	}
+	if err := Ring(ring).Validate(); err != nil {
+		return nil, err
+	}
+	field := newScalarField(curve)
+	size := scalarSize(curve)
+	version := signVersion(opts.Version)
+
+	// the random values are reduced in constant time as well
+	r, err := nonceReader(opts.Nonces, privkey, m, ring)
+	if err != nil {
+		return nil, err
+	}
+	wide := make([]byte, nonceSize(privkey))
+	nonce := func() ([]uint32, error) {
+		if _, err := io.ReadFull(r, wide); err != nil {
+			return nil, err
+		}
+		return field.fromWide(wide), nil
+	}
+	// the private key x, glue value u and the signer's factor b
+	x := field.fromBytes(math.PaddedBigBytes(privkey.D, size))
+	u, err := nonce()
+	if err != nil {
+		return nil, err
+	}
+	blind, err := nonce()
+	if err != nil {
+		return nil, err
+	}
+
+	// fixed-width encodings of the members, their hashes to the curve and the
+	// responses, all of which are public
+	hashPoint := ringHasher(ring, version)
+	members := make([][]byte, n)
+	hashes := make([][]byte, n)
+	S := make([][]byte, n)
+	C := make([][]byte, n)
+	for i, pub := range ring {
+		members[i] = pointBytes(pub)
+		hx, hy := hashPoint(pub)
+		hashes[i] = pointBytes(&ecdsa.PublicKey{Curve: curve, X: hx, Y: hy})
+
+		k, err := nonce()
+		if err != nil {
+			return nil, err
+		}
+		S[i] = field.toBytes(k)
+		C[i] = make([]byte, 32)
+	}
+	P := make([]byte, len(members[0]))
+	H := make([]byte, len(hashes[0]))
+
+	// check the signer against its member in constant time, an error only
+	// reveals that the input was wrong
+	ctSelect(P, members, s)
+	if subtle.ConstantTimeCompare(P, pointBytes(privkey.Public().(*ecdsa.PublicKey))) != 1 {
+		return nil, errSigner
+	}
+
+	// key image I = x*H_p(P[s])
+	ctSelect(H, hashes, s)
+	hx, hy := splitPoint(H)
+	ix, iy := curve.ScalarMult(hx, hy, field.toBytes(x))
+
+	sc := getScratch()
+	defer putScratch(sc)
+
+	var (
+		a, b = field.alloc(), field.alloc()
+		resp = make([]byte, size)
+		c    = make([]byte, 32)
+	)
+	for j := 0; j < n; j++ {
+		idx := ctIndex(s+j, n)
+		ctSelect(P, members, idx)
+		ctSelect(H, hashes, idx)
+
+		if j == 0 {
+			// a = u - b*x, so that L = u*G and R = u*H_p(P[s])
+			field.mul(a, blind, x)
+			field.sub(a, u, a)
+			copy(b, blind)
+		} else {
+			ctSelect(resp, S, idx)
+			ctSelect(c, C, idx)
+			copy(a, field.fromBytes(resp))
+			copy(b, field.fromBytes(c))
+		}
+		ak, bk := field.toBytes(a), field.toBytes(b)
+
+		// L = a*G + b*P
+		px, py := splitPoint(P)
+		lx, ly := curve.ScalarBaseMult(ak)
+		tx, ty := curve.ScalarMult(px, py, bk)
+		lx, ly = curve.Add(lx, ly, tx, ty)
+
+		// R = a*H_p(P) + b*I
+		hx, hy := splitPoint(H)
+		rx, ry := curve.ScalarMult(hx, hy, ak)
+		tx, ty = curve.ScalarMult(ix, iy, bk)
+		rx, ry = curve.Add(rx, ry, tx, ty)
+
+		ctStore(C, ctIndex(idx+1, n), sc.challenge(version, curve, m[:], lx, ly, rx, ry).Bytes())
+	}
+
+	// close the ring with S[s] = u - c[s]*x
+	ctSelect(c, C, s)
+	field.mul(a, field.fromBytes(c), x)
+	field.sub(a, u, a)
+	ctStore(S, s, field.toBytes(a))
+
+	sig := &RingSign{
+		Size:    n,
+		M:       m,
+		C:       new(big.Int).SetBytes(C[0]),
+		S:       make([]*big.Int, n),
+		Ring:    ring,
+		I:       &ecdsa.PublicKey{Curve: curve, X: ix, Y: iy},
+		Curve:   curve,
+		Version: version,
+	}
+	for i := range S {
+		sig.S[i] = new(big.Int).SetBytes(S[i])
+	}
+	return sig, nil
+}
+
+// ctIndex returns v mod n for 0 <= v < 2n without branching on v.
+func ctIndex(v, n int) int {
+	return v - n*subtle.ConstantTimeLessOrEq(n, v)
+}
+
+// ctSelect copies items[idx] into dst, reading every item.
+func ctSelect(dst []byte, items [][]byte, idx int) {
+	for i, item := range items {
+		subtle.ConstantTimeCopy(subtle.ConstantTimeEq(int32(i), int32(idx)), dst, item)
+	}
+}
+
+// ctStore copies src into items[idx], writing every item. Shorter sources are
+// zero padded on the left.
+func ctStore(items [][]byte, idx int, src []byte) {
+	padded := make([]byte, len(items[0]))
+	copy(padded[len(padded)-len(src):], src)
+	for i, item := range items {
+		subtle.ConstantTimeCopy(subtle.ConstantTimeEq(int32(i), int32(idx)), item, padded)
+	}
+}
+
+// splitPoint decodes a fixed-width X||Y encoding as returned by pointBytes.
+func splitPoint(b []byte) (*big.Int, *big.Int) {
+	half := len(b) / 2
+	return new(big.Int).SetBytes(b[:half]), new(big.Int).SetBytes(b[half:])
+}
+
+// scalarField implements arithmetic modulo the group order of a curve in
+// Montgomery form, with 32-bit limbs stored least significant first. No
+// operation branches on or indexes memory by its operands. Inputs of up to the
+// limb width are accepted, which covers both scalars and 256-bit challenges.
+type scalarField struct {
+	n     []uint32 // group order
+	r2    []uint32 // R^2 mod n, with R = 2^(32*len(n))
+	n0inv uint32   // -n^-1 mod 2^32
+	size  int      // byte length of the encoded scalars
+}
+
+// newScalarField returns the scalar field of curve, whose group order must be
+// odd.
+func newScalarField(curve elliptic.Curve) *scalarField {
+	N := curve.Params().N
+	size := scalarSize(curve)
+	limbs := (size + 3) / 4
+	if limbs < 8 {
+		limbs = 8
+	}
+	f := &scalarField{size: size}
+	f.n = toLimbs(math.PaddedBigBytes(N, 4*limbs))
+
+	r2 := new(big.Int).Lsh(big.NewInt(1), uint(64*limbs))
+	f.r2 = toLimbs(math.PaddedBigBytes(r2.Mod(r2, N), 4*limbs))
+
+	// Newton iteration doubles the number of correct low bits every step
+	inv := uint32(1)
+	for i := 0; i < 5; i++ {
+		inv *= 2 - f.n[0]*inv
+	}
+	f.n0inv = -inv
+	return f
+}
+
+// toLimbs converts a big-endian number into little-endian 32-bit limbs.
+func toLimbs(b []byte) []uint32 {
+	limbs := make([]uint32, len(b)/4)
+	for i := range limbs {
+		j := len(b) - 4*i
+		limbs[i] = uint32(b[j-1]) | uint32(b[j-2])<<8 | uint32(b[j-3])<<16 | uint32(b[j-4])<<24
+	}
+	return limbs
+}
+
+func (f *scalarField) alloc() []uint32 {
+	return make([]uint32, len(f.n))
+}
+
+// fromBytes returns the big-endian number b, of at most 4*len(f.n) bytes,
+// reduced and in Montgomery form.
+func (f *scalarField) fromBytes(b []byte) []uint32 {
+	padded := make([]byte, 4*len(f.n))
+	copy(padded[len(padded)-len(b):], b)
+
+	z := f.alloc()
+	f.mul(z, toLimbs(padded), f.r2)
+	return z
+}
+
+// fromWide returns the big-endian number b, of at most 8*len(f.n) bytes,
+// reduced and in Montgomery form.
+func (f *scalarField) fromWide(b []byte) []uint32 {
+	split := len(b) - 4*len(f.n)
+	if split <= 0 {
+		return f.fromBytes(b)
+	}
+	// b = hi*R + lo, and hi*R in Montgomery form is hi*R^2
+	z := f.alloc()
+	f.mul(z, f.fromBytes(b[:split]), f.r2)
+	f.add(z, z, f.fromBytes(b[split:]))
+	return z
+}
+
+// toBytes returns the scalar x in Montgomery form as a big-endian number of
+// f.size bytes.
+func (f *scalarField) toBytes(x []uint32) []byte {
+	one := f.alloc()
+	one[0] = 1
+	z := f.alloc()
+	f.mul(z, x, one)
+
+	b := make([]byte, 4*len(z))
+	for i, limb := range z {
+		j := len(b) - 4*i
+		b[j-1], b[j-2], b[j-3], b[j-4] = byte(limb), byte(limb>>8), byte(limb>>16), byte(limb>>24)
+	}
+	return b[len(b)-f.size:]
+}
+
+// mul sets z = x*y/R mod n. Either operand may exceed n as long as x*y < R*n.
+func (f *scalarField) mul(z, x, y []uint32) {
+	k := len(f.n)
+	t := make([]uint32, k+2)
+	for i := 0; i < k; i++ {
+		// t += x[i]*y
+		var c uint64
+		for j := 0; j < k; j++ {
+			v := uint64(t[j]) + uint64(x[i])*uint64(y[j]) + c
+			t[j], c = uint32(v), v>>32
+		}
+		v := uint64(t[k]) + c
+		t[k], t[k+1] = uint32(v), uint32(v>>32)
+
+		// t = (t + m*n) / 2^32, with m chosen to clear the lowest limb
+		m := t[0] * f.n0inv
+		c = (uint64(t[0]) + uint64(m)*uint64(f.n[0])) >> 32
+		for j := 1; j < k; j++ {
+			v := uint64(t[j]) + uint64(m)*uint64(f.n[j]) + c
+			t[j-1], c = uint32(v), v>>32
+		}
+		v = uint64(t[k]) + c
+		t[k-1], t[k] = uint32(v), t[k+1]+uint32(v>>32)
+	}
+	// t < 2n, subtract n once unless that borrows past the top limb
+	d := make([]uint32, k)
+	var borrow uint64
+	for j := 0; j < k; j++ {
+		v := uint64(t[j]) - uint64(f.n[j]) - borrow
+		d[j], borrow = uint32(v), (v>>32)&1
+	}
+	mask := -(t[k] | uint32(borrow^1))
+	for j := 0; j < k; j++ {
+		z[j] = d[j]&mask | t[j]&^mask
+	}
+}
+
+// add sets z = x + y mod n for x, y < n.
+func (f *scalarField) add(z, x, y []uint32) {
+	k := len(f.n)
+	t := make([]uint32, k)
+	var c uint64
+	for j := 0; j < k; j++ {
+		v := uint64(x[j]) + uint64(y[j]) + c
+		t[j], c = uint32(v), v>>32
+	}
+	// subtract n unless that borrows past the carry
+	var borrow uint64
+	for j := 0; j < k; j++ {
+		v := uint64(t[j]) - uint64(f.n[j]) - borrow
+		z[j], borrow = uint32(v), (v>>32)&1
+	}
+	mask := -(uint32(c) | uint32(borrow^1))
+	for j := 0; j < k; j++ {
+		z[j] = z[j]&mask | t[j]&^mask
+	}
+}
+
+// sub sets z = x - y mod n for x, y < n.
+func (f *scalarField) sub(z, x, y []uint32) {
+	k := len(f.n)
+	var borrow uint64
+	for j := 0; j < k; j++ {
+		v := uint64(x[j]) - uint64(y[j]) - borrow
+		z[j], borrow = uint32(v), (v>>32)&1
+	}
+	// add n back if the subtraction borrowed
+	mask := -uint32(borrow)
+	var c uint64
+	for j := 0; j < k; j++ {
+		v := uint64(z[j]) + uint64(f.n[j]&mask) + c
+		z[j], c = uint32(v), v>>32
+	}
+}
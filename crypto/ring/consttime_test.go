@@ -0,0 +1,138 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"flag"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var timing = flag.Bool("timing", false, "run wall-clock timing tests")
+
+func TestSignConstantTime(t *testing.T) {
+	for _, curve := range []elliptic.Curve{crypto.S256(), elliptic.P256(), elliptic.P384()} {
+		ring := make(Ring, 5)
+		keys := make([]*ecdsa.PrivateKey, len(ring))
+		for i := range ring {
+			key, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys[i], ring[i] = key, &key.PublicKey
+		}
+		for s := range ring {
+			sig, err := SignWithOpts([32]byte{1, 2, 3}, ring, keys[s], s, &SignOpts{ConstantTime: true})
+			if err != nil {
+				t.Fatalf("%s, index %d: %v", curve.Params().Name, s, err)
+			}
+			if !Verify(sig) {
+				t.Errorf("%s, index %d: signature does not verify", curve.Params().Name, s)
+			}
+			image := KeyImage{GenKeyImage(keys[s])}
+			if !sig.KeyImage().Equal(image) {
+				t.Errorf("%s, index %d: wrong key image", curve.Params().Name, s)
+			}
+		}
+	}
+}
+
+func TestSignConstantTimeErrors(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	if _, err := SignConstantTime([32]byte{}, ring, keys[0], 1); err != errSigner {
+		t.Errorf("wrong signer: got %v, want %v", err, errSigner)
+	}
+	if _, err := SignConstantTime([32]byte{}, ring, keys[0], 3); err != errIndexRange {
+		t.Errorf("index out of range: got %v, want %v", err, errIndexRange)
+	}
+	if _, err := SignConstantTime([32]byte{}, ring[:1], keys[0], 0); err != errRingTooSmall {
+		t.Errorf("small ring: got %v, want %v", err, errRingTooSmall)
+	}
+	// a copy of the signer's key is accepted
+	pub := keys[0].PublicKey
+	if _, err := SignConstantTime([32]byte{}, Ring{&pub, ring[1]}, keys[0], 0); err != nil {
+		t.Errorf("copied signer key: %v", err)
+	}
+}
+
+func TestScalarField(t *testing.T) {
+	for _, curve := range []elliptic.Curve{crypto.S256(), elliptic.P224(), elliptic.P384(), elliptic.P521()} {
+		var (
+			N     = curve.Params().N
+			field = newScalarField(curve)
+			size  = scalarSize(curve)
+		)
+		for i := 0; i < 100; i++ {
+			x, _ := rand.Int(rand.Reader, N)
+			y, _ := rand.Int(rand.Reader, N)
+			h := make([]byte, 32)
+			rand.Read(h)
+
+			wide := make([]byte, size+16)
+			rand.Read(wide)
+
+			// x*y - h + w, with the unreduced challenge h and wide nonce w
+			z := field.alloc()
+			field.mul(z, field.fromBytes(math.PaddedBigBytes(x, size)), field.fromBytes(math.PaddedBigBytes(y, size)))
+			field.sub(z, z, field.fromBytes(h))
+			field.add(z, z, field.fromWide(wide))
+
+			want := new(big.Int).Mul(x, y)
+			want.Sub(want, new(big.Int).SetBytes(h))
+			want.Add(want, new(big.Int).SetBytes(wide))
+			want.Mod(want, N)
+			if got := new(big.Int).SetBytes(field.toBytes(z)); got.Cmp(want) != 0 {
+				t.Fatalf("%s: x*y-h+w = %x, want %x", curve.Params().Name, got, want)
+			}
+		}
+	}
+}
+
+// TestSignConstantTimeVariance compares the signing time for the first and
+// last secret index of a ring. The threshold is loose, the test is meant to
+// catch paths whose work grows with the index, not to measure side channels.
+// Wall-clock timings are noisy on shared machines, so the test only runs with
+// the -timing flag.
+func TestSignConstantTimeVariance(t *testing.T) {
+	if !*timing {
+		t.Skip("timing test, enable with -timing")
+	}
+	const rounds = 30
+
+	ring, keys := testRing(t, 8)
+	first, last := 0, len(ring)-1
+	timings := make(map[int][]time.Duration)
+	for i := 0; i < rounds; i++ {
+		for _, s := range []int{first, last} {
+			start := time.Now()
+			if _, err := SignConstantTime([32]byte{}, ring, keys[s], s); err != nil {
+				t.Fatal(err)
+			}
+			timings[s] = append(timings[s], time.Since(start))
+		}
+	}
+	median := func(d []time.Duration) float64 {
+		sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+		return float64(d[len(d)/2])
+	}
+	a, b := median(timings[first]), median(timings[last])
+	if diff := (a - b) / (a + b) * 2; diff > 0.2 || diff < -0.2 {
+		t.Errorf("median signing time differs by %.0f%% between index %d and %d", diff*100, first, last)
+	}
+}
+
+func BenchmarkSignConstantTime(b *testing.B) {
+	ring, keys := testRing(b, 16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := SignConstantTime([32]byte{}, ring, keys[3], 3); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
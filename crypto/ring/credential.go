@@ -0,0 +1,287 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Anonymous credentials are issued through the blind ring signature protocol.
+// A holder commits to a vector of attributes,
+//
+//	A = r*G + a_0*H_0 + ... + a_{k-1}*H_{k-1}
+//
+// and obtains a blind ring signature on the commitment from a ring of issuers.
+// The issuers never see A, so a later presentation cannot be linked to the
+// issuing session, and verifiers only learn that some issuer in the ring
+// signed. Public information the issuer does see, such as the credential type
+// or an expiry epoch, is bound by tweaking every ring key P_i into
+// P_i + H(info, P_i)*G, which makes the signature partially blind.
+//
+// A presentation reveals the commitment and the signature, discloses a chosen
+// subset of attributes and proves knowledge of the remaining ones together
+// with the blinding factor. Presentations of the same credential share the
+// commitment, which serves as a nullifier against double use, for instance to
+// keep an airdrop sybil resistant. Holders wanting unlinkable presentations
+// request one credential per presentation.
+//
+// Since the issuers sign blindly they cannot inspect the hidden attributes.
+// Everything an issuer vouches for must go into the public info.
+
+// credentialDomain separates the hashes of credentials from other hashes in
+// the package.
+var credentialDomain = []byte("go-ethereum/crypto/ring credential")
+
+var (
+	errNoAttributes      = errors.New("credential without attributes")
+	errAttributeIndex    = errors.New("attribute index out of range")
+	errCredentialPending = errors.New("credential not issued yet")
+)
+
+// Credential is the holder's view of an anonymous credential.
+type Credential struct {
+	Attributes []*big.Int       // attribute values
+	Blinding   *big.Int         // blinding factor r of the commitment
+	Commitment *ecdsa.PublicKey // commitment to the attributes
+	Info       []byte           // public information bound by the issuer
+	Sig        *BlindRingSign   // issuer ring signature on the commitment
+	Curve      elliptic.Curve
+}
+
+// Presentation proves possession of a credential issued by a ring of issuers
+// while disclosing only some of its attributes.
+type Presentation struct {
+	Commitment *ecdsa.PublicKey // commitment of the credential
+	Sig        *BlindRingSign   // issuer ring signature on the commitment
+	Size       int              // number of attributes
+	Disclosed  map[int]*big.Int // disclosed attribute values by index
+	C          *big.Int         // challenge of the proof of knowledge
+	Z          *big.Int         // response for the blinding factor
+	Hidden     map[int]*big.Int // responses for hidden attributes by index
+	Curve      elliptic.Curve
+}
+
+// attributeGenerator returns the generator H_i for attribute i.
+func attributeGenerator(curve elliptic.Curve, i int) *ecdsa.PublicKey {
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], uint32(i))
+	return hashToPoint(curve, credentialDomain, []byte("attribute"), idx[:])
+}
+
+// issuerTweak computes H(info, P) for the issuer key P.
+func issuerTweak(info []byte, pub *ecdsa.PublicKey) *big.Int {
+	return hashToScalar(pub.Curve, credentialDomain, []byte("issuer"), info, pointBytes(pub))
+}
+
+// IssuerRing returns the ring of issuer keys tweaked by info, under which
+// credentials carrying info are signed.
+func IssuerRing(issuers Ring, info []byte) Ring {
+	ring := make(Ring, len(issuers))
+	for i, pub := range issuers {
+		if pub == nil {
+			continue
+		}
+		ring[i] = pointAdd(pub, baseMul(pub.Curve, issuerTweak(info, pub)))
+	}
+	return ring
+}
+
+// credentialMessage computes the message signed by the issuers.
+func credentialMessage(info []byte, commitment *ecdsa.PublicKey) [32]byte {
+	h := sha3.New256()
+	h.Write(credentialDomain)
+	h.Write(pointBytes(commitment))
+	h.Write(info)
+
+	var m [32]byte
+	copy(m[:], h.Sum(nil))
+	return m
+}
+
+// NewIssuerSession starts a blind signing session for a credential carrying
+// info, on behalf of the owner of issuers[s]. The returned nonce commitments
+// go to the holder; the holder's blinded challenge is answered with the Sign
+// method of the session.
+func NewIssuerSession(issuers Ring, info []byte, privkey *ecdsa.PrivateKey, s int) (*BlindSigner, []*ecdsa.PublicKey, error) {
+	if s < 0 || s >= len(issuers) {
+		return nil, nil, errIndexOutOfRange
+	}
+	if !pointEqual(issuers[s], &privkey.PublicKey) {
+		return nil, nil, errNotSigner
+	}
+	// x' = x + H(info, P)
+	curve := privkey.Curve
+	d := new(big.Int).Add(privkey.D, issuerTweak(info, &privkey.PublicKey))
+	d.Mod(d, curve.Params().N)
+	tweaked := &ecdsa.PrivateKey{PublicKey: *baseMul(curve, d), D: d}
+
+	return NewBlindSigner(IssuerRing(issuers, info), tweaked, s)
+}
+
+// NewCredential commits to attrs with a fresh blinding factor. The credential
+// becomes usable once issued through Request and Finish.
+func NewCredential(curve elliptic.Curve, attrs []*big.Int, info []byte) (*Credential, error) {
+	if len(attrs) == 0 {
+		return nil, errNoAttributes
+	}
+	r, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	N := curve.Params().N
+	cred := &Credential{
+		Attributes: make([]*big.Int, len(attrs)),
+		Blinding:   r,
+		Info:       info,
+		Curve:      curve,
+	}
+	commitment := baseMul(curve, r)
+	for i, a := range attrs {
+		cred.Attributes[i] = new(big.Int).Mod(a, N)
+		commitment = pointAdd(commitment, pointMul(attributeGenerator(curve, i), cred.Attributes[i]))
+	}
+	cred.Commitment = commitment
+	return cred, nil
+}
+
+// Request blinds the credential commitment for the issuers' nonce commitments
+// and returns the challenge to send to the issuer.
+func (cred *Credential) Request(issuers Ring, commitments []*ecdsa.PublicKey) (*Blinder, *big.Int, error) {
+	m := credentialMessage(cred.Info, cred.Commitment)
+	return Blind(m, IssuerRing(issuers, cred.Info), commitments)
+}
+
+// Finish unblinds the issuer's answer and stores the resulting signature in
+// the credential.
+func (cred *Credential) Finish(b *Blinder, C, S []*big.Int) error {
+	sig, err := b.Unblind(C, S)
+	if err != nil {
+		return err
+	}
+	cred.Sig = sig
+	return nil
+}
+
+// presentationChallenge computes H(info, context, A, disclosed, T).
+func presentationChallenge(p *Presentation, info, context []byte, t *ecdsa.PublicKey) *big.Int {
+	var idx [4]byte
+	data := [][]byte{credentialDomain, []byte("show")}
+	for _, b := range [][]byte{info, context} {
+		binary.BigEndian.PutUint32(idx[:], uint32(len(b)))
+		data = append(data, append([]byte{}, idx[:]...), b)
+	}
+	binary.BigEndian.PutUint32(idx[:], uint32(p.Size))
+	data = append(data, pointBytes(p.Commitment), append([]byte{}, idx[:]...))
+
+	size := (p.Curve.Params().N.BitLen() + 7) / 8
+	for i := 0; i < p.Size; i++ {
+		if a, ok := p.Disclosed[i]; ok {
+			binary.BigEndian.PutUint32(idx[:], uint32(i))
+			data = append(data, append([]byte{}, idx[:]...), math.PaddedBigBytes(a, size))
+		}
+	}
+	data = append(data, pointBytes(t))
+	return hashToScalar(p.Curve, data...)
+}
+
+// Show creates a presentation of the credential disclosing the attributes at
+// the given indices. The proof is bound to context, which verifiers should
+// choose freshly to prevent replays.
+func (cred *Credential) Show(disclose []int, context []byte) (*Presentation, error) {
+	if cred.Sig == nil {
+		return nil, errCredentialPending
+	}
+	curve := cred.Curve
+	N := curve.Params().N
+
+	p := &Presentation{
+		Commitment: cred.Commitment,
+		Sig:        cred.Sig,
+		Size:       len(cred.Attributes),
+		Disclosed:  make(map[int]*big.Int),
+		Hidden:     make(map[int]*big.Int),
+		Curve:      curve,
+	}
+	for _, i := range disclose {
+		if i < 0 || i >= p.Size {
+			return nil, errAttributeIndex
+		}
+		p.Disclosed[i] = cred.Attributes[i]
+	}
+	// T = k_r*G + sum_{hidden j} k_j*H_j
+	kr, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	k := make(map[int]*big.Int)
+	t := baseMul(curve, kr)
+	for i := 0; i < p.Size; i++ {
+		if _, ok := p.Disclosed[i]; ok {
+			continue
+		}
+		if k[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+		t = pointAdd(t, pointMul(attributeGenerator(curve, i), k[i]))
+	}
+	// z_r = k_r - c*r, z_j = k_j - c*a_j
+	p.C = presentationChallenge(p, cred.Info, context, t)
+	z := new(big.Int).Mul(p.C, cred.Blinding)
+	p.Z = z.Sub(kr, z).Mod(z, N)
+	for i, ki := range k {
+		zi := new(big.Int).Mul(p.C, cred.Attributes[i])
+		p.Hidden[i] = zi.Sub(ki, zi).Mod(zi, N)
+	}
+	return p, nil
+}
+
+// VerifyPresentation checks that p presents a credential carrying info that
+// was issued by a member of issuers, and that the proof is bound to context.
+// returns true if a valid presentation, false otherwise
+func VerifyPresentation(p *Presentation, issuers Ring, info, context []byte) bool {
+	if p == nil || p.Commitment == nil || p.Sig == nil || p.C == nil || p.Z == nil {
+		return false
+	}
+	if p.Size <= 0 || len(p.Disclosed)+len(p.Hidden) != p.Size {
+		return false
+	}
+	curve := p.Curve
+
+	// the issuers signed this commitment with info under the tweaked ring
+	ring := IssuerRing(issuers, info)
+	if len(p.Sig.Ring) != len(ring) {
+		return false
+	}
+	for i := range ring {
+		if !pointEqual(p.Sig.Ring[i], ring[i]) {
+			return false
+		}
+	}
+	if p.Sig.M != credentialMessage(info, p.Commitment) || !VerifyBlind(p.Sig) {
+		return false
+	}
+	// T = z_r*G + sum_{hidden j} z_j*H_j + c*(A - sum_{disclosed i} a_i*H_i)
+	rest := p.Commitment
+	t := baseMul(curve, p.Z)
+	for i := 0; i < p.Size; i++ {
+		if a, ok := p.Disclosed[i]; ok {
+			if a == nil {
+				return false
+			}
+			rest = pointSub(rest, pointMul(attributeGenerator(curve, i), a))
+			continue
+		}
+		z, ok := p.Hidden[i]
+		if !ok || z == nil {
+			return false
+		}
+		t = pointAdd(t, pointMul(attributeGenerator(curve, i), z))
+	}
+	t = pointAdd(t, pointMul(rest, p.C))
+	return presentationChallenge(p, info, context, t).Cmp(p.C) == 0
+}
@@ -0,0 +1,95 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// issueCredential runs the issuance protocol between a holder and the issuer
+// at index s.
+func issueCredential(t *testing.T, issuers Ring, key *ecdsa.PrivateKey, s int, attrs []*big.Int, info []byte) *Credential {
+	cred, err := NewCredential(crypto.S256(), attrs, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, nonces, err := NewIssuerSession(issuers, info, key, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blinder, c, err := cred.Request(issuers, nonces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	C, S, err := session.Sign(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cred.Finish(blinder, C, S); err != nil {
+		t.Fatal(err)
+	}
+	return cred
+}
+
+func TestCredential(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	issuers := GenNewKeyRing(4, key, 1)
+	info := []byte("airdrop/2024")
+	attrs := []*big.Int{big.NewInt(18), big.NewInt(1337), big.NewInt(42)}
+
+	cred := issueCredential(t, issuers, key, 1, attrs, info)
+	p, err := cred.Show([]int{0}, []byte("nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyPresentation(p, issuers, info, []byte("nonce")) {
+		t.Fatal("valid presentation rejected")
+	}
+	if len(p.Disclosed) != 1 || p.Disclosed[0].Cmp(big.NewInt(18)) != 0 {
+		t.Fatalf("wrong disclosed attributes: %v", p.Disclosed)
+	}
+	if VerifyPresentation(p, issuers, info, []byte("other nonce")) {
+		t.Fatal("presentation replayed in another context")
+	}
+	if VerifyPresentation(p, issuers, []byte("airdrop/2025"), []byte("nonce")) {
+		t.Fatal("presentation accepted with wrong public info")
+	}
+	others := append(Ring{}, issuers...)
+	others[0] = pointAdd(others[0], baseMul(crypto.S256(), big.NewInt(1)))
+	if VerifyPresentation(p, others, info, []byte("nonce")) {
+		t.Fatal("presentation accepted for a different issuer ring")
+	}
+}
+
+func TestCredentialForgedAttribute(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	issuers := GenNewKeyRing(3, key, 0)
+	cred := issueCredential(t, issuers, key, 0, []*big.Int{big.NewInt(17), big.NewInt(5)}, nil)
+
+	p, err := cred.Show([]int{0, 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyPresentation(p, issuers, nil, nil) {
+		t.Fatal("valid presentation rejected")
+	}
+	p.Disclosed[0] = big.NewInt(18)
+	if VerifyPresentation(p, issuers, nil, nil) {
+		t.Fatal("presentation with altered attribute accepted")
+	}
+}
+
+func TestCredentialNotIssued(t *testing.T) {
+	cred, err := NewCredential(crypto.S256(), []*big.Int{big.NewInt(1)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cred.Show(nil, nil); err != errCredentialPending {
+		t.Fatalf("wrong error: have %v, want %v", err, errCredentialPending)
+	}
+	if _, err := NewCredential(crypto.S256(), nil, nil); err != errNoAttributes {
+		t.Fatalf("wrong error: have %v, want %v", err, errNoAttributes)
+	}
+}
@@ -0,0 +1,238 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// CryptoNote transactions, as used by Monero, spend a txin_to_key input
+//
+//	tag 0x02 || varint amount || varint n || n * varint offset || key image
+//
+// where the ring members are outputs referenced by their global index, stored
+// as offsets relative to the previous member. The ring signature follows in
+// the transaction's signature section as n * (c_i || r_i), with scalars in
+// little-endian byte order.
+//
+// The helpers below translate between that layout and RingSign. The c_i are
+// the intermediate challenges of the linkable ring signature and r_i its
+// responses, so only c_0 carries information; the others are checked for
+// consistency on import. Points use the compressed encoding of the curve.
+// The layout carries no transcript version, imported signatures use
+// DefaultTranscript.
+
+const cryptoNoteInputTag = 0x02
+
+var (
+	errOffsetOrder       = errors.New("ring member indices not strictly ascending")
+	errCryptoNoteInput   = errors.New("invalid CryptoNote input")
+	errCryptoNoteSigSize = errors.New("CryptoNote signature size does not match ring")
+	errChallengeMismatch = errors.New("intermediate challenge mismatch")
+)
+
+// CryptoNoteInput is a txin_to_key transaction input.
+type CryptoNoteInput struct {
+	Amount   uint64           // zero for RingCT inputs
+	Indices  []uint64         // global output indices of the ring members
+	KeyImage *ecdsa.PublicKey // key image of the spent output
+}
+
+// OutputLookup returns the public key of the output with the given global
+// index.
+type OutputLookup func(index uint64) (*ecdsa.PublicKey, error)
+
+// RelativeOffsets converts ascending global output indices into the relative
+// offsets stored in an input.
+func RelativeOffsets(indices []uint64) ([]uint64, error) {
+	offsets := make([]uint64, len(indices))
+	for i, index := range indices {
+		if i == 0 {
+			offsets[i] = index
+			continue
+		}
+		if index <= indices[i-1] {
+			return nil, errOffsetOrder
+		}
+		offsets[i] = index - indices[i-1]
+	}
+	return offsets, nil
+}
+
+// AbsoluteOffsets converts relative offsets into global output indices.
+func AbsoluteOffsets(offsets []uint64) ([]uint64, error) {
+	indices := make([]uint64, len(offsets))
+	for i, offset := range offsets {
+		if i == 0 {
+			indices[i] = offset
+			continue
+		}
+		if offset == 0 || indices[i-1]+offset <= indices[i-1] {
+			return nil, errOffsetOrder
+		}
+		indices[i] = indices[i-1] + offset
+	}
+	return indices, nil
+}
+
+// MarshalCryptoNote encodes the input in the txin_to_key layout.
+func (in *CryptoNoteInput) MarshalCryptoNote() ([]byte, error) {
+	offsets, err := RelativeOffsets(in.Indices)
+	if err != nil {
+		return nil, err
+	}
+	var buf [binary.MaxVarintLen64]byte
+
+	enc := []byte{cryptoNoteInputTag}
+	enc = append(enc, buf[:binary.PutUvarint(buf[:], in.Amount)]...)
+	enc = append(enc, buf[:binary.PutUvarint(buf[:], uint64(len(offsets)))]...)
+	for _, offset := range offsets {
+		enc = append(enc, buf[:binary.PutUvarint(buf[:], offset)]...)
+	}
+	return append(enc, compressPoint(in.KeyImage.Curve, in.KeyImage)...), nil
+}
+
+// ParseCryptoNoteInput decodes a txin_to_key input with a key image on curve.
+func ParseCryptoNoteInput(curve elliptic.Curve, b []byte) (*CryptoNoteInput, error) {
+	if len(b) == 0 || b[0] != cryptoNoteInputTag {
+		return nil, errCryptoNoteInput
+	}
+	b = b[1:]
+	uvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return 0, errCryptoNoteInput
+		}
+		b = b[n:]
+		return v, nil
+	}
+	amount, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	size, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	// every offset takes at least one byte
+	if size > uint64(len(b)) {
+		return nil, errCryptoNoteInput
+	}
+	offsets := make([]uint64, size)
+	for i := range offsets {
+		if offsets[i], err = uvarint(); err != nil {
+			return nil, err
+		}
+	}
+	indices, err := AbsoluteOffsets(offsets)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < CompressedSize(curve) {
+		return nil, errEncodingLength
+	}
+	if len(b) > CompressedSize(curve) {
+		return nil, errTrailingData
+	}
+	image, err := decompressPoint(curve, b)
+	if err != nil {
+		return nil, err
+	}
+	if image == nil {
+		return nil, errInvalidPoint
+	}
+	return &CryptoNoteInput{Amount: amount, Indices: indices, KeyImage: image}, nil
+}
+
+// littleEndian returns k as a little-endian number of size bytes.
+func littleEndian(k *big.Int, size int) []byte {
+	b := math.PaddedBigBytes(k, size)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
+
+// fromLittleEndian decodes a little-endian number.
+func fromLittleEndian(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// CryptoNoteInput returns the input spending with sig, whose ring members are
+// the outputs with the given global indices. The ring must be ordered by
+// ascending index.
+func (r *RingSign) CryptoNoteInput(indices []uint64) (*CryptoNoteInput, error) {
+	if len(indices) != r.Size {
+		return nil, errSignatureSize
+	}
+	if _, err := RelativeOffsets(indices); err != nil {
+		return nil, err
+	}
+	return &CryptoNoteInput{
+		Indices:  indices,
+		KeyImage: newPoint(r.Curve, r.I.X, r.I.Y),
+	}, nil
+}
+
+// MarshalCryptoNoteSignature encodes the signature as n * (c_i || r_i).
+func (r *RingSign) MarshalCryptoNoteSignature() []byte {
+	scalar := scalarSize(r.Curve)
+	C := challengeChain(r)
+
+	enc := make([]byte, 0, 2*scalar*r.Size)
+	for i := 0; i < r.Size; i++ {
+		enc = append(enc, littleEndian(C[i], scalar)...)
+		enc = append(enc, littleEndian(r.S[i], scalar)...)
+	}
+	return enc
+}
+
+// ImportCryptoNote reassembles the signature of message m spending input in,
+// resolving the ring members through outputs.
+func ImportCryptoNote(m [32]byte, in *CryptoNoteInput, signature []byte, outputs OutputLookup) (*RingSign, error) {
+	curve := in.KeyImage.Curve
+	scalar := scalarSize(curve)
+	size := len(in.Indices)
+	if size < 2 {
+		return nil, errRingTooSmall
+	}
+	if len(signature) != 2*scalar*size {
+		return nil, errCryptoNoteSigSize
+	}
+	sig := &RingSign{
+		Size:    size,
+		M:       m,
+		C:       fromLittleEndian(signature[:scalar]),
+		S:       make([]*big.Int, size),
+		Ring:    make(Ring, size),
+		I:       in.KeyImage,
+		Curve:   curve,
+		Version: DefaultTranscript,
+	}
+	for i, index := range in.Indices {
+		pub, err := outputs(index)
+		if err != nil {
+			return nil, err
+		}
+		if pub == nil || pub.Curve != curve {
+			return nil, errInvalidPoint
+		}
+		sig.Ring[i] = pub
+		sig.S[i] = fromLittleEndian(signature[(2*i+1)*scalar : (2*i+2)*scalar])
+	}
+	C := challengeChain(sig)
+	for i := 1; i < size; i++ {
+		if C[i].Cmp(fromLittleEndian(signature[2*i*scalar:(2*i+1)*scalar])) != 0 {
+			return nil, errChallengeMismatch
+		}
+	}
+	return sig, nil
+}
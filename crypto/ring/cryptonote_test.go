@@ -0,0 +1,108 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestOffsets(t *testing.T) {
+	indices := []uint64{5, 7, 100, 101}
+	offsets, err := RelativeOffsets(indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []uint64{5, 2, 93, 1} {
+		if offsets[i] != want {
+			t.Fatalf("offset %d is %d, want %d", i, offsets[i], want)
+		}
+	}
+	back, err := AbsoluteOffsets(offsets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range indices {
+		if back[i] != indices[i] {
+			t.Fatalf("round trip mismatch: %v", back)
+		}
+	}
+	if _, err := RelativeOffsets([]uint64{3, 3}); err != errOffsetOrder {
+		t.Errorf("repeated index: have %v, want %v", err, errOffsetOrder)
+	}
+	if _, err := AbsoluteOffsets([]uint64{3, 0}); err != errOffsetOrder {
+		t.Errorf("zero offset: have %v, want %v", err, errOffsetOrder)
+	}
+	if _, err := AbsoluteOffsets([]uint64{^uint64(0), 1}); err != errOffsetOrder {
+		t.Errorf("overflow: have %v, want %v", err, errOffsetOrder)
+	}
+}
+
+func TestCryptoNoteRoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(4, key, 2)
+	sig, err := Sign([32]byte{0x0c}, ring, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indices := []uint64{12, 300, 301, 70000}
+	outputs := make(map[uint64]*ecdsa.PublicKey)
+	for i, index := range indices {
+		outputs[index] = ring[i]
+	}
+	lookup := func(index uint64) (*ecdsa.PublicKey, error) {
+		return outputs[index], nil
+	}
+
+	in, err := sig.CryptoNoteInput(indices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.Amount = 1000
+	encIn, err := in.MarshalCryptoNote()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// tag, amount (2 bytes), count, offsets 12, 288 (2 bytes), 1, 69699 (3 bytes)
+	if want := 1 + 2 + 1 + 1 + 2 + 1 + 3 + 33; len(encIn) != want {
+		t.Fatalf("input is %d bytes, want %d", len(encIn), want)
+	}
+	encSig := sig.MarshalCryptoNoteSignature()
+
+	dec, err := ParseCryptoNoteInput(crypto.S256(), encIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Amount != 1000 || !pointEqual(dec.KeyImage, sig.I) {
+		t.Fatal("input mismatch")
+	}
+	imported, err := ImportCryptoNote(sig.M, dec, encSig, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(imported) {
+		t.Fatal("imported signature rejected")
+	}
+	if !bytes.Equal(imported.SerializeSignature(), sig.SerializeSignature()) {
+		t.Fatal("imported signature differs")
+	}
+
+	// tampering with an intermediate challenge is detected
+	encSig[2*32] ^= 1
+	if _, err := ImportCryptoNote(sig.M, dec, encSig, lookup); err != errChallengeMismatch {
+		t.Errorf("tampered challenge: have %v, want %v", err, errChallengeMismatch)
+	}
+	if _, err := ImportCryptoNote(sig.M, dec, encSig[1:], lookup); err != errCryptoNoteSigSize {
+		t.Errorf("short signature: have %v, want %v", err, errCryptoNoteSigSize)
+	}
+	if _, err := ParseCryptoNoteInput(crypto.S256(), append(encIn, 0)); err != errTrailingData {
+		t.Errorf("trailing data: have %v, want %v", err, errTrailingData)
+	}
+	if _, err := ParseCryptoNoteInput(crypto.S256(), encIn[:5]); err == nil {
+		t.Error("truncated input accepted")
+	}
+	if _, err := sig.CryptoNoteInput([]uint64{1, 2, 2, 3}); err != errOffsetOrder {
+		t.Errorf("unordered indices: have %v, want %v", err, errOffsetOrder)
+	}
+}
@@ -0,0 +1,72 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/babyjub"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"github.com/ethereum/go-ethereum/crypto/ristretto255"
+)
+
+// The curve registry maps names to the curves rings can be formed over, so
+// that configuration files and encodings can select a curve by name. Besides
+// secp256k1 it carries the NIST curves P-256 and P-384 for deployments bound
+// to FIPS approved algorithms, and the alternative groups of this package.
+
+var (
+	errUnknownCurve   = errors.New("unknown curve")
+	errCurveNameTaken = errors.New("curve name already registered")
+)
+
+var (
+	curvesLock sync.RWMutex
+	curves     = map[string]elliptic.Curve{
+		"secp256k1":    crypto.S256(),
+		"P-256":        elliptic.P256(),
+		"P-384":        elliptic.P384(),
+		"ristretto255": ristretto255.Curve(),
+		"BLS12-381":    bls12381.G1(),
+		"BabyJubjub":   babyjub.BabyJub(),
+	}
+)
+
+// RegisterCurve makes curve available under name. Registering a name twice is
+// an error.
+func RegisterCurve(name string, curve elliptic.Curve) error {
+	curvesLock.Lock()
+	defer curvesLock.Unlock()
+
+	if _, ok := curves[name]; ok {
+		return errCurveNameTaken
+	}
+	curves[name] = curve
+	return nil
+}
+
+// CurveByName returns the curve registered under name.
+func CurveByName(name string) (elliptic.Curve, error) {
+	curvesLock.RLock()
+	defer curvesLock.RUnlock()
+
+	curve, ok := curves[name]
+	if !ok {
+		return nil, errUnknownCurve
+	}
+	return curve, nil
+}
+
+// CurveName returns the name curve is registered under.
+func CurveName(curve elliptic.Curve) (string, error) {
+	curvesLock.RLock()
+	defer curvesLock.RUnlock()
+
+	for name, c := range curves {
+		if c == curve {
+			return name, nil
+		}
+	}
+	return "", errUnknownCurve
+}
@@ -0,0 +1,77 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func TestCurveRegistry(t *testing.T) {
+	for _, name := range []string{"secp256k1", "P-256", "P-384", "ristretto255", "BLS12-381", "BabyJubjub"} {
+		curve, err := CurveByName(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if have, err := CurveName(curve); err != nil || have != name {
+			t.Errorf("%s: name lookup returned %q, %v", name, have, err)
+		}
+	}
+	if _, err := CurveByName("P-521"); err != errUnknownCurve {
+		t.Errorf("unknown curve: have %v, want %v", err, errUnknownCurve)
+	}
+	if err := RegisterCurve("P-256", elliptic.P256()); err != errCurveNameTaken {
+		t.Errorf("duplicate name: have %v, want %v", err, errCurveNameTaken)
+	}
+}
+
+// TestNIST signs and verifies over the NIST curves, including the points at
+// infinity the standard library returns as (0, 0).
+func TestNIST(t *testing.T) {
+	for _, name := range []string{"P-256", "P-384"} {
+		curve, _ := CurveByName(name)
+		ring := make(Ring, 3)
+		var key *ecdsa.PrivateKey
+		for i := range ring {
+			k, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ring[i], key = &k.PublicKey, k
+		}
+		if p := pointMul(ring[0], curve.Params().N); p != nil {
+			t.Errorf("%s: N*P is not the point at infinity", name)
+		}
+		if p := pointAdd(ring[0], pointNeg(ring[0])); p != nil {
+			t.Errorf("%s: P + -P is not the point at infinity", name)
+		}
+		if size := len(pointBytes(ring[0])); size != 2*((curve.Params().BitSize+7)/8) {
+			t.Errorf("%s: point encoding is %d bytes", name, size)
+		}
+		if e := hashToScalar(curve, []byte("x")); e.Cmp(curve.Params().N) >= 0 {
+			t.Errorf("%s: hashed scalar not reduced", name)
+		}
+		msg := [32]byte{0x25, 0x6}
+		trip, err := SignTriptych(msg, ring, key, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyTriptych(trip) {
+			t.Errorf("%s: triptych signature rejected", name)
+		}
+		uniq, err := SignUnique(msg, ring, key, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyUnique(uniq) {
+			t.Errorf("%s: unique signature rejected", name)
+		}
+		bor, err := SignBorromean(msg, []Ring{ring}, []*ecdsa.PrivateKey{key}, []int{2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyBorromean(bor) {
+			t.Errorf("%s: borromean signature rejected", name)
+		}
+	}
+}
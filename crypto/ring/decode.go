@@ -0,0 +1,108 @@
+package ring
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Signatures received from the network are decoded with DecodeStrict, which
+// bounds the input size before any work is done and the ring size before any
+// verification, rejects trailing bytes in every encoding and only returns
+// signatures in canonical form, so a signature has a single accepted encoding
+// per format. The individual decoders stay lenient where they always were,
+// such as DeserializeSignature ignoring trailing bytes.
+
+// Encoding identifies a wire format of ring signatures.
+type Encoding int
+
+const (
+	EncodingBinary     Encoding = iota // SerializeSignature, secp256k1 only
+	EncodingCompressed                 // SerializeCompressed, secp256k1 only
+	EncodingRLP                        // EncodeRLP
+	EncodingCBOR                       // MarshalCBOR
+	EncodingDER                        // MarshalDER
+	EncodingProto                      // MarshalProto
+)
+
+// DecodeLimits bounds the signatures accepted by Decode.
+type DecodeLimits struct {
+	MaxRingSize    int // maximum number of ring members
+	MaxMessageSize int // maximum length of the encoded signature in bytes
+}
+
+// DefaultDecodeLimits are the limits of DecodeStrict. They admit rings of up
+// to 1024 members in any encoding.
+var DefaultDecodeLimits = DecodeLimits{
+	MaxRingSize:    1024,
+	MaxMessageSize: 256 * 1024,
+}
+
+var (
+	errUnknownEncoding = errors.New("unknown signature encoding")
+	errMessageTooLarge = errors.New("encoded signature exceeds size limit")
+	errRingTooLarge    = errors.New("ring exceeds size limit")
+)
+
+// DecodeStrict decodes a signature in the given encoding with the default
+// limits. It is meant for input from untrusted sources, see
+// DecodeLimits.Decode.
+func DecodeStrict(enc Encoding, b []byte) (*RingSign, error) {
+	return DefaultDecodeLimits.Decode(enc, b)
+}
+
+// Decode decodes a signature in the given encoding. It fails if the input or
+// the ring exceed the limits, if bytes follow the signature or if the
+// signature is not in canonical form, see RingSign.CheckCanonical.
+func (l DecodeLimits) Decode(enc Encoding, b []byte) (*RingSign, error) {
+	if len(b) > l.MaxMessageSize {
+		return nil, errMessageTooLarge
+	}
+	var (
+		sig = new(RingSign)
+		err error
+	)
+	switch enc {
+	case EncodingBinary:
+		sig, err = decodeBinary(b)
+	case EncodingCompressed:
+		sig, err = DeserializeCompressedSignature(crypto.S256(), b)
+	case EncodingRLP:
+		err = rlp.DecodeBytes(b, sig)
+	case EncodingCBOR:
+		err = sig.UnmarshalCBOR(b)
+	case EncodingDER:
+		err = sig.UnmarshalDER(b)
+	case EncodingProto:
+		err = sig.UnmarshalProto(b)
+	default:
+		return nil, errUnknownEncoding
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sig.Ring) > l.MaxRingSize {
+		return nil, errRingTooLarge
+	}
+	if err := sig.CheckCanonical(); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// decodeBinary decodes the output of SerializeSignature, which must fill b
+// exactly.
+func decodeBinary(b []byte) (*RingSign, error) {
+	if len(b) < 136 {
+		return nil, errEncodingLength
+	}
+	sig, err := DeserializeSignature(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 136+96*sig.Size {
+		return nil, errEncodingLength
+	}
+	return sig, nil
+}
@@ -0,0 +1,101 @@
+package ring
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// testEncodings returns the encodings of sig in every format.
+func testEncodings(t testing.TB, sig *RingSign) map[Encoding][]byte {
+	encs := map[Encoding][]byte{
+		EncodingBinary:     sig.SerializeSignature(),
+		EncodingCompressed: sig.SerializeCompressed(),
+	}
+	var err error
+	if encs[EncodingRLP], err = rlp.EncodeToBytes(sig); err != nil {
+		t.Fatal(err)
+	}
+	if encs[EncodingCBOR], err = sig.MarshalCBOR(); err != nil {
+		t.Fatal(err)
+	}
+	if encs[EncodingDER], err = sig.MarshalDER(); err != nil {
+		t.Fatal(err)
+	}
+	// protobuf only carries signatures with the original transcript
+	if encodedVersion(sig) == 0 {
+		if encs[EncodingProto], err = sig.MarshalProto(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return encs
+}
+
+func TestDecodeStrict(t *testing.T) {
+	ring, keys := testRing(t, 4)
+	for _, version := range []byte{TranscriptV1, DefaultTranscript} {
+		sig, err := SignWithOpts([32]byte{1}, ring, keys[2], 2, &SignOpts{Version: version})
+		if err != nil {
+			t.Fatal(err)
+		}
+		testDecodeStrict(t, sig)
+	}
+	if _, err := DecodeStrict(Encoding(-1), nil); err != errUnknownEncoding {
+		t.Errorf("unknown encoding: got %v, want %v", err, errUnknownEncoding)
+	}
+}
+
+func testDecodeStrict(t *testing.T, sig *RingSign) {
+	for enc, b := range testEncodings(t, sig) {
+		dec, err := DecodeStrict(enc, b)
+		if err != nil {
+			t.Fatalf("encoding %d: %v", enc, err)
+		}
+		if !Verify(dec) {
+			t.Errorf("encoding %d: decoded signature does not verify", enc)
+		}
+		// trailing bytes, except for protobuf which has no end marker
+		if enc != EncodingProto {
+			if _, err := DecodeStrict(enc, append(b, 0)); err == nil {
+				t.Errorf("encoding %d: trailing byte accepted", enc)
+			}
+		}
+		for i := 0; i < len(b); i++ {
+			if _, err := DecodeStrict(enc, b[:i]); err == nil {
+				t.Errorf("encoding %d: input truncated to %d bytes accepted", enc, i)
+			}
+		}
+		limits := DecodeLimits{MaxRingSize: 3, MaxMessageSize: len(b)}
+		if _, err := limits.Decode(enc, b); err != errRingTooLarge {
+			t.Errorf("encoding %d: got %v, want %v", enc, err, errRingTooLarge)
+		}
+		limits = DecodeLimits{MaxRingSize: 4, MaxMessageSize: len(b) - 1}
+		if _, err := limits.Decode(enc, b); err != errMessageTooLarge {
+			t.Errorf("encoding %d: got %v, want %v", enc, err, errMessageTooLarge)
+		}
+	}
+}
+
+func FuzzDecodeStrict(f *testing.F) {
+	ring, keys := testRing(f, 3)
+	for _, version := range []byte{TranscriptV1, DefaultTranscript} {
+		sig, err := SignWithOpts([32]byte{1}, ring, keys[0], 0, &SignOpts{Version: version})
+		if err != nil {
+			f.Fatal(err)
+		}
+		for enc, b := range testEncodings(f, sig) {
+			f.Add(int(enc), b)
+		}
+	}
+	f.Fuzz(func(t *testing.T, enc int, b []byte) {
+		sig, err := DecodeStrict(Encoding(enc), b)
+		if err != nil {
+			return
+		}
+		// accepted signatures have a single encoding
+		if reenc := testEncodings(t, sig)[Encoding(enc)]; enc != int(EncodingProto) && !bytes.Equal(reenc, b) {
+			t.Errorf("encoding %d: decoded signature re-encodes differently", enc)
+		}
+	})
+}
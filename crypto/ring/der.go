@@ -0,0 +1,284 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+)
+
+// DER encodings let signatures and rings travel through PKI tooling and be
+// stored as PEM files. Curves are named by their standard object identifiers,
+// so only secp256k1, P-256 and P-384 can be encoded. The ASN.1 modules are
+//
+//	RingPublicKeys ::= SEQUENCE {
+//	    curve   OBJECT IDENTIFIER,
+//	    keys    SEQUENCE OF OCTET STRING }
+//
+//	RingSignature ::= SEQUENCE {
+//	    curve    OBJECT IDENTIFIER,
+//	    message  OCTET STRING,
+//	    keys     SEQUENCE OF OCTET STRING,
+//	    c        INTEGER,
+//	    s        SEQUENCE OF INTEGER,
+//	    keyImage OCTET STRING,
+//	    version  INTEGER DEFAULT 1 }
+//
+// with compressed points as described in compress.go.
+
+// PEM block types.
+const (
+	pemSignatureType = "RING SIGNATURE"
+	pemRingType      = "RING PUBLIC KEYS"
+)
+
+var (
+	oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	oidP256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidP384      = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+
+	// curveOIDs maps registered curve names to object identifiers.
+	curveOIDs = map[string]asn1.ObjectIdentifier{
+		"secp256k1": oidSecp256k1,
+		"P-256":     oidP256,
+		"P-384":     oidP384,
+	}
+)
+
+var (
+	errNoCurveOID   = errors.New("curve has no object identifier")
+	errTrailingData = errors.New("trailing data after DER structure")
+	errPEMType      = errors.New("unexpected PEM block type")
+	errPEMBlock     = errors.New("no PEM block found")
+	errScalarRange  = errors.New("scalar out of range")
+)
+
+type derRing struct {
+	Curve asn1.ObjectIdentifier
+	Keys  [][]byte
+}
+
+type derRingSign struct {
+	Curve    asn1.ObjectIdentifier
+	Message  []byte
+	Keys     [][]byte
+	C        *big.Int
+	S        []*big.Int
+	KeyImage []byte
+	Version  int `asn1:"optional,default:1"`
+}
+
+// curveOID returns the object identifier of curve.
+func curveOID(curve elliptic.Curve) (asn1.ObjectIdentifier, error) {
+	name, err := CurveName(curve)
+	if err != nil {
+		return nil, err
+	}
+	oid, ok := curveOIDs[name]
+	if !ok {
+		return nil, errNoCurveOID
+	}
+	return oid, nil
+}
+
+// curveByOID returns the curve named by oid.
+func curveByOID(oid asn1.ObjectIdentifier) (elliptic.Curve, error) {
+	for name, known := range curveOIDs {
+		if known.Equal(oid) {
+			return CurveByName(name)
+		}
+	}
+	return nil, errUnknownCurve
+}
+
+// decompressKeys decodes a list of compressed ring members.
+func decompressKeys(curve elliptic.Curve, keys [][]byte) (Ring, error) {
+	ring := make(Ring, len(keys))
+	for i, b := range keys {
+		pub, err := decompressPoint(curve, b)
+		if err != nil {
+			return nil, err
+		}
+		if pub == nil {
+			return nil, errInvalidPoint
+		}
+		ring[i] = pub
+	}
+	return ring, nil
+}
+
+// MarshalDER encodes the ring as a DER RingPublicKeys structure.
+func (r Ring) MarshalDER() ([]byte, error) {
+	if len(r) == 0 {
+		return nil, errEmptyRing
+	}
+	curve := r[0].Curve
+	oid, err := curveOID(curve)
+	if err != nil {
+		return nil, err
+	}
+	dec := derRing{Curve: oid, Keys: make([][]byte, len(r))}
+	for i, pub := range r {
+		dec.Keys[i] = compressPoint(curve, pub)
+	}
+	return asn1.Marshal(dec)
+}
+
+// ParseRingDER decodes a ring encoded by Ring.MarshalDER. Invalid members are
+// reported as a *MemberError, see Ring.Validate.
+func ParseRingDER(b []byte) (Ring, error) {
+	var dec derRing
+	rest, err := asn1.Unmarshal(b, &dec)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errTrailingData
+	}
+	curve, err := curveByOID(dec.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if len(dec.Keys) == 0 {
+		return nil, errEmptyRing
+	}
+	ring, err := decompressKeys(curve, dec.Keys)
+	if err != nil {
+		return nil, err
+	}
+	if err := ring.Validate(); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// MarshalDER encodes the signature as a DER RingSignature structure.
+func (r *RingSign) MarshalDER() ([]byte, error) {
+	curve := r.Curve
+	oid, err := curveOID(curve)
+	if err != nil {
+		return nil, err
+	}
+	dec := derRingSign{
+		Curve:    oid,
+		Message:  r.M[:],
+		Keys:     make([][]byte, len(r.Ring)),
+		C:        r.C,
+		S:        r.S,
+		KeyImage: compressPoint(curve, newPoint(curve, r.I.X, r.I.Y)),
+		Version:  int(transcriptVersion(r)),
+	}
+	for i, pub := range r.Ring {
+		dec.Keys[i] = compressPoint(curve, pub)
+	}
+	return asn1.Marshal(dec)
+}
+
+// UnmarshalDER decodes a signature encoded by MarshalDER.
+func (r *RingSign) UnmarshalDER(b []byte) error {
+	var dec derRingSign
+	rest, err := asn1.Unmarshal(b, &dec)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errTrailingData
+	}
+	curve, err := curveByOID(dec.Curve)
+	if err != nil {
+		return err
+	}
+	if len(dec.Message) != 32 {
+		return errEncodingLength
+	}
+	if len(dec.S) != len(dec.Keys) {
+		return errSignatureSize
+	}
+	if dec.Version < int(TranscriptV1) || dec.Version > int(TranscriptV3) {
+		return errTranscriptVersion
+	}
+	N := curve.Params().N
+	for _, s := range append([]*big.Int{dec.C}, dec.S...) {
+		if s.Sign() < 0 || s.Cmp(N) >= 0 {
+			return errScalarRange
+		}
+	}
+	ring, err := decompressKeys(curve, dec.Keys)
+	if err != nil {
+		return err
+	}
+	image, err := decompressPoint(curve, dec.KeyImage)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return errInvalidPoint
+	}
+	*r = RingSign{
+		Size:  len(ring),
+		C:     dec.C,
+		S:     dec.S,
+		Ring:  ring,
+		I:     image,
+		Curve: curve,
+	}
+	if dec.Version != int(TranscriptV1) {
+		r.Version = byte(dec.Version)
+	}
+	copy(r.M[:], dec.Message)
+	return nil
+}
+
+// decodePEM returns the contents of the first PEM block in b, which must be of
+// the given type.
+func decodePEM(b []byte, typ string) ([]byte, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errPEMBlock
+	}
+	if block.Type != typ {
+		return nil, errPEMType
+	}
+	return block.Bytes, nil
+}
+
+// EncodeSignaturePEM encodes sig as a "RING SIGNATURE" PEM block.
+func EncodeSignaturePEM(sig *RingSign) ([]byte, error) {
+	der, err := sig.MarshalDER()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemSignatureType, Bytes: der}), nil
+}
+
+// DecodeSignaturePEM decodes the first "RING SIGNATURE" PEM block in b.
+func DecodeSignaturePEM(b []byte) (*RingSign, error) {
+	der, err := decodePEM(b, pemSignatureType)
+	if err != nil {
+		return nil, err
+	}
+	sig := new(RingSign)
+	if err := sig.UnmarshalDER(der); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// EncodeRingPEM encodes ring as a "RING PUBLIC KEYS" PEM block.
+func EncodeRingPEM(ring Ring) ([]byte, error) {
+	der, err := ring.MarshalDER()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemRingType, Bytes: der}), nil
+}
+
+// DecodeRingPEM decodes the first "RING PUBLIC KEYS" PEM block in b.
+func DecodeRingPEM(b []byte) (Ring, error) {
+	der, err := decodePEM(b, pemRingType)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRingDER(der)
+}
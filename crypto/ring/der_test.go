@@ -0,0 +1,79 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ristretto255"
+)
+
+func TestSignaturePEM(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(3, key, 1)
+	sig, err := Sign([32]byte{3}, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := EncodeSignaturePEM(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(enc, []byte("-----BEGIN RING SIGNATURE-----\n")) {
+		t.Fatalf("unexpected armor: %q", enc[:32])
+	}
+	dec, err := DecodeSignaturePEM(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(dec) {
+		t.Fatal("decoded signature rejected")
+	}
+	if _, err := DecodeRingPEM(enc); err != errPEMType {
+		t.Fatalf("wrong error: have %v, want %v", err, errPEMType)
+	}
+}
+
+func TestRingPEM(t *testing.T) {
+	for _, curve := range []elliptic.Curve{crypto.S256(), elliptic.P256(), elliptic.P384()} {
+		ring := make(Ring, 4)
+		for i := range ring {
+			k, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ring[i] = &k.PublicKey
+		}
+		enc, err := EncodeRingPEM(ring)
+		if err != nil {
+			t.Fatalf("%s: %v", curve.Params().Name, err)
+		}
+		dec, err := DecodeRingPEM(enc)
+		if err != nil {
+			t.Fatalf("%s: %v", curve.Params().Name, err)
+		}
+		if len(dec) != len(ring) {
+			t.Fatalf("%s: wrong ring size: have %d, want %d", curve.Params().Name, len(dec), len(ring))
+		}
+		for i := range ring {
+			if !pointEqual(ring[i], dec[i]) {
+				t.Fatalf("%s: member %d mismatch", curve.Params().Name, i)
+			}
+		}
+	}
+	key, _ := crypto.GenerateKey()
+	der, _ := Ring(GenNewKeyRing(2, key, 0)).MarshalDER()
+	if _, err := ParseRingDER(append(der, 0)); err != errTrailingData {
+		t.Fatalf("wrong error: have %v, want %v", err, errTrailingData)
+	}
+}
+
+func TestDERNoOID(t *testing.T) {
+	key, _ := ristretto255.GenerateKey()
+	if _, err := (Ring{&key.PublicKey}).MarshalDER(); err != errNoCurveOID {
+		t.Fatalf("wrong error: have %v, want %v", err, errNoCurveOID)
+	}
+}
@@ -0,0 +1,108 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+// Designated-verifier ring signatures convince only one named verifier. The
+// signature proves knowledge of the private key of a ring member or of the
+// verifier's private key. The verifier knows it did not create the signature
+// and so is convinced a ring member did, but anybody else has to consider that
+// the verifier simulated it, so the signature cannot be passed on as evidence.
+// SimulateDesignated creates exactly such a simulation.
+//
+// The proof is a Cramer-Damgard-Schoenmakers disjunction of Schnorr proofs
+// over the ring extended by the verifier's key: the challenges c_i of all
+// members must sum up to H(m, ring, V, s_0*G + c_0*P_0, ..., s_n*G + c_n*V).
+
+// designatedDomain separates the hashes of designated-verifier signatures from
+// other hashes in the package.
+var designatedDomain = []byte("go-ethereum/crypto/ring designated")
+
+var errNoVerifier = errors.New("missing designated verifier key")
+
+// DesignatedSign is a ring signature designated to a single verifier. C and S
+// hold one entry per ring member followed by one for the verifier.
+type DesignatedSign struct {
+	M        [32]byte         // message
+	Ring     Ring             // array of public keys
+	Verifier *ecdsa.PublicKey // designated verifier
+	C        []*big.Int       // challenges
+	S        []*big.Int       // responses
+	Curve    elliptic.Curve
+}
+
+// designatedPrefix returns the data the challenge is computed over besides the
+// nonce commitments: the message, the ring and the verifier key.
+func designatedPrefix(m [32]byte, keys Ring) [][]byte {
+	data := [][]byte{designatedDomain, m[:]}
+	for _, p := range keys {
+		data = append(data, pointBytes(p))
+	}
+	return data
+}
+
+// designatedKeys returns the ring extended by the verifier key.
+func designatedKeys(ring Ring, verifier *ecdsa.PublicKey) Ring {
+	keys := make(Ring, len(ring), len(ring)+1)
+	copy(keys, ring)
+	return append(keys, verifier)
+}
+
+// signDesignated proves knowledge of the private key of keys[s].
+func signDesignated(m [32]byte, ring Ring, verifier *ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int) (*DesignatedSign, error) {
+	keys := designatedKeys(ring, verifier)
+	C, S, err := proveSchnorrOr(designatedPrefix(m, keys), keys, privkey, s)
+	if err != nil {
+		return nil, err
+	}
+	return &DesignatedSign{
+		M:        m,
+		Ring:     ring,
+		Verifier: verifier,
+		C:        C,
+		S:        S,
+		Curve:    privkey.Curve,
+	}, nil
+}
+
+// SignDesignated creates a ring signature over m for the ring member at index
+// s that only the holder of the verifier key is convinced by.
+func SignDesignated(m [32]byte, ring Ring, verifier *ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int) (*DesignatedSign, error) {
+	if verifier == nil {
+		return nil, errNoVerifier
+	}
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	if !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	return signDesignated(m, ring, verifier, privkey, s)
+}
+
+// SimulateDesignated creates a signature indistinguishable from one made by a
+// ring member, using only the designated verifier's private key.
+func SimulateDesignated(m [32]byte, ring Ring, verifier *ecdsa.PrivateKey) (*DesignatedSign, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	return signDesignated(m, ring, &verifier.PublicKey, verifier, len(ring))
+}
+
+// VerifyDesignated verifies a designated-verifier ring signature. A valid
+// signature only carries meaning for the designated verifier itself.
+// returns true if a valid signature, false otherwise
+func VerifyDesignated(sig *DesignatedSign) bool {
+	if sig == nil || sig.Verifier == nil || len(sig.Ring) < 2 {
+		return false
+	}
+	keys := designatedKeys(sig.Ring, sig.Verifier)
+	return verifySchnorrOr(sig.Curve, designatedPrefix(sig.M, keys), keys, sig.C, sig.S)
+}
@@ -0,0 +1,40 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDesignated(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(3, key, 0)
+
+	sig, err := SignDesignated([32]byte{3}, ring, &verifier.PublicKey, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyDesignated(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	// the verifier can produce an equally valid signature on its own
+	fake, err := SimulateDesignated([32]byte{4}, ring, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyDesignated(fake) {
+		t.Fatal("simulated signature rejected")
+	}
+	// redirecting the signature to another verifier must fail
+	sig.Verifier = &key.PublicKey
+	if VerifyDesignated(sig) {
+		t.Error("signature accepted for different verifier")
+	}
+}
@@ -0,0 +1,89 @@
+package ring
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Verify only learns whether the challenge chain closes, not where it went
+// wrong. When a signature produced by another implementation fails, the
+// intermediate challenges that implementation computed, such as the c_i of
+// a CryptoNote signature, pinpoint the first ring member on which the two
+// disagree. VerifyDetailed walks the chain comparing against them and stops
+// at the first difference.
+
+var (
+	errMalformedSignature = errors.New("malformed signature")
+	errChallengeCount     = errors.New("number of expected challenges differs from ring size")
+	errExpectedStart      = errors.New("expected challenges do not start at c[0] of signature")
+)
+
+// VerifyResult is the outcome of VerifyDetailed.
+type VerifyResult struct {
+	Valid bool
+
+	// Index is the ring member whose step produced the first wrong challenge,
+	// or -1 for a valid signature.
+	Index int
+
+	// Expected and Computed are the challenge following member Index as given
+	// and as recomputed. The last member is followed by c[0].
+	Expected, Computed *big.Int
+}
+
+func (res *VerifyResult) String() string {
+	if res.Valid {
+		return "valid signature"
+	}
+	return fmt.Sprintf("challenge after ring member %d diverged: expected %x, computed %x", res.Index, res.Expected, res.Computed)
+}
+
+// VerifyDetailed verifies sig and, if it is invalid, reports where its
+// challenge chain diverged. The optional expected holds the challenges
+// c[0], ..., c[n-1] computed by the signer, starting with sig.C; without them
+// only the closing check against c[0] can be reported. An error is returned
+// for malformed signatures and out of range scalars.
+func VerifyDetailed(sig *RingSign, expected []*big.Int) (*VerifyResult, error) {
+	if !wellFormed(sig) {
+		return nil, errMalformedSignature
+	}
+	if err := checkScalars(sig); err != nil {
+		return nil, err
+	}
+	if expected != nil && len(expected) != sig.Size {
+		return nil, errChallengeCount
+	}
+	if expected != nil && expected[0].Cmp(sig.C) != 0 {
+		return nil, errExpectedStart
+	}
+	hashPoint := ringHasher(sig.Ring, transcriptVersion(sig))
+
+	sc := getScratch()
+	defer putScratch(sc)
+
+	c := sig.C
+	for i := 0; i < sig.Size; i++ {
+		c = challengeStep(sc, sig, hashPoint, i, c)
+
+		var want *big.Int
+		switch {
+		case i+1 == sig.Size:
+			want = sig.C
+		case expected != nil:
+			want = expected[i+1]
+		default:
+			continue
+		}
+		if c.Cmp(want) != 0 {
+			return &VerifyResult{Index: i, Expected: want, Computed: c}, nil
+		}
+	}
+	return &VerifyResult{Valid: true, Index: -1}, nil
+}
+
+// Challenges returns the challenges c[0], ..., c[n-1] of the signature, for
+// comparison with another implementation through VerifyDetailed.
+func (r *RingSign) Challenges() []*big.Int {
+	return challengeChain(r)[:r.Size]
+}
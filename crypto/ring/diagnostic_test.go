@@ -0,0 +1,68 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestVerifyDetailed(t *testing.T) {
+	ring, keys := testRing(t, 4)
+	sig, err := Sign([32]byte{1}, ring, keys[1], 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := sig.Challenges()
+
+	res, err := VerifyDetailed(sig, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.Index != -1 {
+		t.Fatalf("valid signature reported as %v", res)
+	}
+	// a wrong response for member 2 first changes the challenge after it
+	tampered := *sig
+	tampered.S = append([]*big.Int{}, sig.S...)
+	tampered.S[2] = new(big.Int).Add(sig.S[2], big.NewInt(1))
+
+	tests := []struct {
+		expected []*big.Int
+		index    int
+	}{
+		{expected, 2},
+		{nil, 3},
+	}
+	for _, tt := range tests {
+		res, err := VerifyDetailed(&tampered, tt.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Valid || Verify(&tampered) {
+			t.Fatal("tampered signature accepted")
+		}
+		if res.Index != tt.index {
+			t.Errorf("expected challenges %v: diverged at %d, want %d", tt.expected != nil, res.Index, tt.index)
+		}
+		if res.Expected.Cmp(res.Computed) == 0 {
+			t.Errorf("diverging challenges are equal: %v", res)
+		}
+	}
+}
+
+func TestVerifyDetailedErrors(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	sig, err := Sign([32]byte{}, ring, keys[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyDetailed(&RingSign{}, nil); err != errMalformedSignature {
+		t.Errorf("malformed signature: got %v, want %v", err, errMalformedSignature)
+	}
+	if _, err := VerifyDetailed(sig, sig.Challenges()[1:]); err != errChallengeCount {
+		t.Errorf("short challenges: got %v, want %v", err, errChallengeCount)
+	}
+	shifted := append(sig.Challenges()[1:], sig.C)
+	if _, err := VerifyDetailed(sig, shifted); err != errExpectedStart {
+		t.Errorf("shifted challenges: got %v, want %v", err, errExpectedStart)
+	}
+}
@@ -0,0 +1,95 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+)
+
+// The disjunctive discrete log equality proof below shows that for some j
+// log_G(P_j) = log_h(Q_j), where P_j are the ring members and Q_j the matching
+// images under the base h, without revealing j. Every member gets a challenge
+// c_j and a response s_j with
+//
+//	a_j = s_j*G + c_j*P_j, b_j = s_j*h + c_j*Q_j
+//
+// and the challenges must sum up to H(prefix, a_0, ..., a_{n-1}, b_0, ...,
+// b_{n-1}). It is the common core of the tag based schemes in this package.
+
+// dleqChallenge computes H(prefix, a, b).
+func dleqChallenge(curve elliptic.Curve, prefix [][]byte, a, b []*ecdsa.PublicKey) *big.Int {
+	data := append([][]byte{}, prefix...)
+	for _, p := range a {
+		data = append(data, pointBytes(p))
+	}
+	for _, p := range b {
+		data = append(data, pointBytes(p))
+	}
+	return hashToScalar(curve, data...)
+}
+
+// proveDLEQOr proves that the private key x of ring[s] satisfies
+// images[s] = x*h. It returns the challenges and responses of all members.
+func proveDLEQOr(prefix [][]byte, h *ecdsa.PublicKey, ring Ring, images []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int) ([]*big.Int, []*big.Int, error) {
+	curve := privkey.Curve
+	N := curve.Params().N
+	n := len(ring)
+
+	C := make([]*big.Int, n)
+	S := make([]*big.Int, n)
+	a := make([]*ecdsa.PublicKey, n)
+	b := make([]*ecdsa.PublicKey, n)
+	w, err := randomScalar(curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	// simulate everyone but the signer
+	for j := range ring {
+		if j == s {
+			a[j], b[j] = baseMul(curve, w), pointMul(h, w)
+			continue
+		}
+		if C[j], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+		if S[j], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+		a[j] = pointAdd(baseMul(curve, S[j]), pointMul(ring[j], C[j]))
+		b[j] = pointAdd(pointMul(h, S[j]), pointMul(images[j], C[j]))
+	}
+	// c_s = c - sum_{j != s} c_j, s_s = w - c_s*x
+	c := dleqChallenge(curve, prefix, a, b)
+	for j, cj := range C {
+		if j != s {
+			c.Sub(c, cj)
+		}
+	}
+	C[s] = c.Mod(c, N)
+	z := new(big.Int).Mul(C[s], privkey.D)
+	z.Sub(w, z)
+	S[s] = z.Mod(z, N)
+
+	return C, S, nil
+}
+
+// verifyDLEQOr verifies a proof created by proveDLEQOr.
+func verifyDLEQOr(curve elliptic.Curve, prefix [][]byte, h *ecdsa.PublicKey, ring Ring, images []*ecdsa.PublicKey, C, S []*big.Int) bool {
+	n := len(ring)
+	if n < 2 || len(images) != n || len(C) != n || len(S) != n {
+		return false
+	}
+	a := make([]*ecdsa.PublicKey, n)
+	b := make([]*ecdsa.PublicKey, n)
+	sum := new(big.Int)
+	for j, pub := range ring {
+		if pub == nil || images[j] == nil || C[j] == nil || S[j] == nil {
+			return false
+		}
+		a[j] = pointAdd(baseMul(curve, S[j]), pointMul(pub, C[j]))
+		b[j] = pointAdd(pointMul(h, S[j]), pointMul(images[j], C[j]))
+		sum.Add(sum, C[j])
+	}
+	c := dleqChallenge(curve, prefix, a, b)
+	return sum.Mod(sum, curve.Params().N).Cmp(c) == 0
+}
@@ -0,0 +1,319 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EIP-712 structured data can be ring signed by signing its digest
+// keccak256(0x19 0x01 || domainSeparator || hashStruct(message)) in place of
+// an arbitrary message. Dapps keep their existing typed message formats while
+// authorizing them anonymously on behalf of a ring.
+
+var (
+	errUnknownType   = errors.New("unknown EIP-712 type")
+	errTypedValue    = errors.New("invalid EIP-712 value")
+	errMissingDomain = errors.New("typed data lacks EIP712Domain type")
+)
+
+// TypedDataField is a member of an EIP-712 struct type.
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is an EIP-712 typed data description, as passed to
+// eth_signTypedData.
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+}
+
+// ParseTypedData decodes a JSON typed data description. Numbers are kept
+// exact rather than converted to float64.
+func ParseTypedData(data []byte) (*TypedData, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	td := new(TypedData)
+	if err := dec.Decode(td); err != nil {
+		return nil, err
+	}
+	return td, nil
+}
+
+// dependencies returns the struct types referenced by typ, typ included, with
+// typ first and the remaining types sorted by name.
+func (td *TypedData) dependencies(typ string, found map[string]bool) []string {
+	typ = strings.TrimRightFunc(typ, func(r rune) bool { return r == ']' || r == '[' || (r >= '0' && r <= '9') })
+	if found[typ] {
+		return nil
+	}
+	if _, ok := td.Types[typ]; !ok {
+		return nil
+	}
+	found[typ] = true
+	deps := []string{typ}
+	for _, field := range td.Types[typ] {
+		deps = append(deps, td.dependencies(field.Type, found)...)
+	}
+	return deps
+}
+
+// EncodeType returns the encoding of the struct type typ and the types it
+// references, e.g. "Mail(Person from,Person to,string contents)Person(...)".
+func (td *TypedData) EncodeType(typ string) (string, error) {
+	if _, ok := td.Types[typ]; !ok {
+		return "", fmt.Errorf("%v: %s", errUnknownType, typ)
+	}
+	deps := td.dependencies(typ, make(map[string]bool))
+	sort.Strings(deps[1:])
+
+	var buf strings.Builder
+	for _, dep := range deps {
+		buf.WriteString(dep)
+		buf.WriteByte('(')
+		for i, field := range td.Types[dep] {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(field.Type)
+			buf.WriteByte(' ')
+			buf.WriteString(field.Name)
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String(), nil
+}
+
+// TypeHash returns keccak256(EncodeType(typ)).
+func (td *TypedData) TypeHash(typ string) (common.Hash, error) {
+	enc, err := td.EncodeType(typ)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte(enc)), nil
+}
+
+// HashStruct returns hashStruct(data) for the struct type typ.
+func (td *TypedData) HashStruct(typ string, data map[string]interface{}) (common.Hash, error) {
+	typeHash, err := td.TypeHash(typ)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	enc := typeHash.Bytes()
+	for _, field := range td.Types[typ] {
+		word, err := td.encodeValue(field.Type, data[field.Name])
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("%s.%s: %v", typ, field.Name, err)
+		}
+		enc = append(enc, word...)
+	}
+	return crypto.Keccak256Hash(enc), nil
+}
+
+// DomainSeparator returns hashStruct(domain) for the EIP712Domain type.
+func (td *TypedData) DomainSeparator() (common.Hash, error) {
+	if _, ok := td.Types["EIP712Domain"]; !ok {
+		return common.Hash{}, errMissingDomain
+	}
+	return td.HashStruct("EIP712Domain", td.Domain)
+}
+
+// Digest returns the EIP-712 digest of the typed data, the value ring signed
+// in place of a message.
+func (td *TypedData) Digest() ([32]byte, error) {
+	domain, err := td.DomainSeparator()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	var digest [32]byte
+	copy(digest[:], crypto.Keccak256([]byte{0x19, 0x01}, domain[:], message[:]))
+	return digest, nil
+}
+
+// encodeValue returns the 32 byte encoding of value as a member of type typ.
+func (td *TypedData) encodeValue(typ string, value interface{}) ([]byte, error) {
+	// arrays hash the concatenated encodings of their elements
+	if strings.HasSuffix(typ, "]") {
+		elem := typ[:strings.LastIndexByte(typ, '[')]
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: %s is not an array", errTypedValue, typ)
+		}
+		if n := typ[len(elem)+1 : len(typ)-1]; n != "" {
+			if size, err := strconv.Atoi(n); err != nil || size != len(items) {
+				return nil, fmt.Errorf("%v: %s has %d elements", errTypedValue, typ, len(items))
+			}
+		}
+		var enc []byte
+		for _, item := range items {
+			word, err := td.encodeValue(elem, item)
+			if err != nil {
+				return nil, err
+			}
+			enc = append(enc, word...)
+		}
+		return crypto.Keccak256(enc), nil
+	}
+	if _, ok := td.Types[typ]; ok {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v: %s is not a struct", errTypedValue, typ)
+		}
+		hash, err := td.HashStruct(typ, fields)
+		return hash[:], err
+	}
+	switch {
+	case typ == "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v: %v is not a string", errTypedValue, value)
+		}
+		return crypto.Keccak256([]byte(s)), nil
+
+	case typ == "bytes":
+		b, err := typedBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v: %v is not a bool", errTypedValue, value)
+		}
+		word := make([]byte, 32)
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+
+	case typ == "address":
+		s, ok := value.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("%v: %v is not an address", errTypedValue, value)
+		}
+		return common.LeftPadBytes(common.HexToAddress(s).Bytes(), 32), nil
+
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(typ[5:])
+		if err != nil || size < 1 || size > 32 {
+			return nil, fmt.Errorf("%v: %s", errUnknownType, typ)
+		}
+		b, err := typedBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != size {
+			return nil, fmt.Errorf("%v: %d bytes for %s", errTypedValue, len(b), typ)
+		}
+		return common.RightPadBytes(b, 32), nil
+
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		signed := typ[0] == 'i'
+		bits, err := strconv.Atoi(strings.TrimLeft(typ, "uint"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("%v: %s", errUnknownType, typ)
+		}
+		n, err := typedInteger(value)
+		if err != nil {
+			return nil, err
+		}
+		min, max := new(big.Int), new(big.Int).Lsh(common.Big1, uint(bits))
+		if signed {
+			max.Rsh(max, 1)
+			min.Neg(max)
+		}
+		if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+			return nil, fmt.Errorf("%v: %v overflows %s", errTypedValue, n, typ)
+		}
+		return math.PaddedBigBytes(math.U256(n), 32), nil
+	}
+	return nil, fmt.Errorf("%v: %s", errUnknownType, typ)
+}
+
+// typedBytes decodes a hex string or byte slice value.
+func typedBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		b, err := hexutil.Decode(v)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", errTypedValue, err)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("%v: %v is not a byte string", errTypedValue, value)
+}
+
+// typedInteger decodes an integer given as a JSON number, a decimal or hex
+// string, or a Go integer.
+func typedInteger(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return new(big.Int).Set(v), nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case float64:
+		if v == float64(int64(v)) {
+			return big.NewInt(int64(v)), nil
+		}
+	case json.Number:
+		if n, ok := new(big.Int).SetString(string(v), 10); ok {
+			return n, nil
+		}
+	case string:
+		neg := strings.HasPrefix(v, "-")
+		if n, ok := math.ParseBig256(strings.TrimPrefix(v, "-")); ok {
+			if neg {
+				n.Neg(n)
+			}
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("%v: %v is not an integer", errTypedValue, value)
+}
+
+// SignTypedData ring signs the EIP-712 digest of td.
+func SignTypedData(td *TypedData, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int) (*RingSign, error) {
+	digest, err := td.Digest()
+	if err != nil {
+		return nil, err
+	}
+	return Sign(digest, ring, privkey, s)
+}
+
+// VerifyTypedData verifies a ring signature of the EIP-712 digest of td.
+// It returns true if a valid signature, false otherwise.
+func VerifyTypedData(td *TypedData, sig *RingSign) bool {
+	digest, err := td.Digest()
+	if err != nil || sig.M != digest {
+		return false
+	}
+	return Verify(sig)
+}
@@ -0,0 +1,139 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mailTypedData is the example of the EIP-712 specification.
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedDataHashing(t *testing.T) {
+	td, err := ParseTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := td.EncodeType("Mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; enc != want {
+		t.Errorf("type encoding mismatch: have %s, want %s", enc, want)
+	}
+	domain, err := td.DomainSeparator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.HexToHash("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"); domain != want {
+		t.Errorf("domain separator mismatch: have %x, want %x", domain, want)
+	}
+	message, err := td.HashStruct("Mail", td.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.HexToHash("0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"); message != want {
+		t.Errorf("struct hash mismatch: have %x, want %x", message, want)
+	}
+	digest, err := td.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"); common.Hash(digest) != want {
+		t.Errorf("digest mismatch: have %x, want %x", digest, want)
+	}
+}
+
+func TestTypedDataValues(t *testing.T) {
+	td := &TypedData{
+		Types: map[string][]TypedDataField{
+			"Values": {
+				{Name: "flag", Type: "bool"},
+				{Name: "delta", Type: "int8"},
+				{Name: "tag", Type: "bytes4"},
+				{Name: "data", Type: "bytes"},
+				{Name: "amounts", Type: "uint64[2]"},
+			},
+		},
+	}
+	valid := map[string]interface{}{
+		"flag":    true,
+		"delta":   "-128",
+		"tag":     "0x01020304",
+		"data":    []byte{1},
+		"amounts": []interface{}{1, "0x2"},
+	}
+	if _, err := td.HashStruct("Values", valid); err != nil {
+		t.Fatalf("valid values rejected: %v", err)
+	}
+	for field, value := range map[string]interface{}{
+		"flag":    "true",
+		"delta":   128,
+		"tag":     "0x0102",
+		"data":    "0xzz",
+		"amounts": []interface{}{1},
+	} {
+		invalid := make(map[string]interface{})
+		for k, v := range valid {
+			invalid[k] = v
+		}
+		invalid[field] = value
+		if _, err := td.HashStruct("Values", invalid); err == nil {
+			t.Errorf("%s: invalid value %v accepted", field, value)
+		}
+	}
+	if _, err := td.HashStruct("Missing", valid); err == nil {
+		t.Error("unknown type accepted")
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	td, err := ParseTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(3, key, 2)
+	sig, err := SignTypedData(td, ring, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyTypedData(td, sig) {
+		t.Fatal("typed data signature rejected")
+	}
+	td.Message["contents"] = "Hello, Alice!"
+	if VerifyTypedData(td, sig) {
+		t.Fatal("signature accepted for different typed data")
+	}
+}
@@ -0,0 +1,164 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Anonymous leader election combines a verifiable random function with a ring
+// proof. For every slot each member of a validator ring evaluates the VRF
+//
+//	Y = x*H_p(seed, slot, ring),  beta = H(Y)
+//
+// and is a leader if beta falls below a threshold chosen so that on average
+// the configured number of members win. An elected member proves that Y was
+// computed with the private key of some ring member, without revealing which.
+// The output is unique per member and slot, so members cannot grind for a
+// better result, and two proofs for the same slot with the same output are
+// known to come from the same member.
+
+// electionDomain separates the hashes of leader elections from other hashes in
+// the package.
+var electionDomain = []byte("go-ethereum/crypto/ring election")
+
+var (
+	errNotElected  = errors.New("not elected for slot")
+	errLeaderCount = errors.New("expected leader count must be positive")
+)
+
+// ElectionSlot identifies a slot of the election and its expected number of
+// leaders.
+type ElectionSlot struct {
+	Seed    []byte // public randomness of the epoch
+	Number  uint64 // slot number
+	Leaders int    // expected number of leaders per slot
+}
+
+// Election proves that an anonymous ring member is a leader for a slot.
+type Election struct {
+	M      [32]byte         // message endorsed by the leader, e.g. a block hash
+	Slot   ElectionSlot     // slot the leader was elected for
+	Ring   Ring             // array of public keys of the validators
+	Output *ecdsa.PublicKey // VRF output Y of the leader
+	C      []*big.Int       // challenges, one per ring member
+	S      []*big.Int       // responses, one per ring member
+	Curve  elliptic.Curve
+}
+
+// electionBase hashes the slot and ring into the VRF base.
+func electionBase(curve elliptic.Curve, slot ElectionSlot, ring Ring) *ecdsa.PublicKey {
+	var num [8]byte
+	binary.BigEndian.PutUint64(num[:], slot.Number)
+
+	var seedLen [4]byte
+	binary.BigEndian.PutUint32(seedLen[:], uint32(len(slot.Seed)))
+
+	data := [][]byte{electionDomain, seedLen[:], slot.Seed, num[:]}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	return hashToPoint(curve, data...)
+}
+
+// electionPrefix returns the data the challenge is computed over besides the
+// proof commitments.
+func electionPrefix(e *Election) [][]byte {
+	var num [8]byte
+	binary.BigEndian.PutUint64(num[:], e.Slot.Number)
+
+	data := [][]byte{electionDomain, []byte("c"), e.M[:], num[:]}
+	for _, p := range e.Ring {
+		data = append(data, pointBytes(p))
+	}
+	return append(data, pointBytes(e.Output))
+}
+
+// electionValue computes the VRF value beta = H(Y).
+func electionValue(output *ecdsa.PublicKey) [32]byte {
+	var beta [32]byte
+	h := sha3.New256()
+	h.Write(electionDomain)
+	h.Write(pointBytes(output))
+	copy(beta[:], h.Sum(nil))
+	return beta
+}
+
+// elected reports whether beta falls below 2^256 * leaders / n.
+func elected(beta [32]byte, leaders, n int) bool {
+	if leaders >= n {
+		return true
+	}
+	threshold := new(big.Int).Lsh(big.NewInt(int64(leaders)), 256)
+	threshold.Div(threshold, big.NewInt(int64(n)))
+	return new(big.Int).SetBytes(beta[:]).Cmp(threshold) < 0
+}
+
+// Value returns the VRF value of the elected leader. It can be used to order
+// multiple leaders of the same slot.
+func (e *Election) Value() [32]byte {
+	return electionValue(e.Output)
+}
+
+// IsElected reports whether the owner of privkey is a leader for slot in ring,
+// without creating a proof.
+func IsElected(slot ElectionSlot, ring Ring, privkey *ecdsa.PrivateKey) bool {
+	if slot.Leaders <= 0 {
+		return false
+	}
+	output := pointMul(electionBase(privkey.Curve, slot, ring), privkey.D)
+	return elected(electionValue(output), slot.Leaders, len(ring))
+}
+
+// Elect proves that the owner of ring[s] is a leader for slot and endorses m.
+// It returns errNotElected if the member did not win the slot.
+func Elect(m [32]byte, slot ElectionSlot, ring Ring, privkey *ecdsa.PrivateKey, s int) (*Election, error) {
+	if slot.Leaders <= 0 {
+		return nil, errLeaderCount
+	}
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	if !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	curve := privkey.Curve
+	h := electionBase(curve, slot, ring)
+	output := pointMul(h, privkey.D)
+	if !elected(electionValue(output), slot.Leaders, len(ring)) {
+		return nil, errNotElected
+	}
+	e := &Election{
+		M:      m,
+		Slot:   slot,
+		Ring:   ring,
+		Output: output,
+		Curve:  curve,
+	}
+	C, S, err := proveDLEQOr(electionPrefix(e), h, ring, uniqueImages(output, len(ring)), privkey, s)
+	if err != nil {
+		return nil, err
+	}
+	e.C, e.S = C, S
+	return e, nil
+}
+
+// VerifyElection verifies that e proves a leader of its slot.
+// returns true if a valid election proof, false otherwise
+func VerifyElection(e *Election) bool {
+	if e == nil || e.Output == nil || e.Slot.Leaders <= 0 {
+		return false
+	}
+	if !elected(electionValue(e.Output), e.Slot.Leaders, len(e.Ring)) {
+		return false
+	}
+	h := electionBase(e.Curve, e.Slot, e.Ring)
+	return verifyDLEQOr(e.Curve, electionPrefix(e), h, e.Ring, uniqueImages(e.Output, len(e.Ring)), e.C, e.S)
+}
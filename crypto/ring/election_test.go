@@ -0,0 +1,74 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestElection(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 8)
+	ring := make(Ring, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		ring[i] = &keys[i].PublicKey
+	}
+	// find a slot with at least one leader and check every member
+	leaders := 0
+	for num := uint64(0); leaders == 0; num++ {
+		slot := ElectionSlot{Seed: []byte("epoch 1"), Number: num, Leaders: 2}
+		for i, key := range keys {
+			e, err := Elect([32]byte{1}, slot, ring, key, i)
+			if !IsElected(slot, ring, key) {
+				if err != errNotElected {
+					t.Fatalf("slot %d member %d: wrong error: have %v, want %v", num, i, err, errNotElected)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			leaders++
+			if !VerifyElection(e) {
+				t.Fatal("valid election rejected")
+			}
+			// the proof does not carry over to other slots or messages
+			e.Slot.Number++
+			if VerifyElection(e) {
+				t.Fatal("election accepted for another slot")
+			}
+			e.Slot.Number--
+			e.M[0] ^= 1
+			if VerifyElection(e) {
+				t.Fatal("election accepted for another message")
+			}
+		}
+	}
+}
+
+func TestElectionEveryone(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(3, key, 1)
+	slot := ElectionSlot{Seed: []byte("seed"), Number: 7, Leaders: 3}
+
+	e, err := Elect([32]byte{}, slot, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyElection(e) {
+		t.Fatal("valid election rejected")
+	}
+	// the VRF output is unique per member and slot
+	again, err := Elect([32]byte{2}, slot, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Value() != again.Value() {
+		t.Fatal("VRF value differs between proofs of the same member")
+	}
+	slot.Leaders = 0
+	if _, err := Elect([32]byte{}, slot, ring, key, 1); err != errLeaderCount {
+		t.Fatalf("wrong error: have %v, want %v", err, errLeaderCount)
+	}
+}
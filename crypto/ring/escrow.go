@@ -0,0 +1,244 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+// Escrowed ring signatures are accountable: the signer encrypts their public
+// key to a designated authority with ElGamal, (E1, E2) = (r*G, P_s + r*A), and
+// proves that the ciphertext holds the key of a ring member whose private key
+// they know. Ordinary verifiers learn nothing about the signer, while the
+// authority can decrypt the ciphertext, e.g. under a court order, and publish
+// a proof that the opening is correct. Opening two signatures also links them.
+//
+// The proof is a disjunction over ring members j of the conjunction
+//
+//	P_j = x*G  and  E1 = r*G  and  E2 - P_j = r*A
+//
+// with one challenge c_j and two responses s_j, t_j per member.
+
+// escrowDomain separates the hashes of escrowed signatures from other hashes
+// in the package.
+var escrowDomain = []byte("go-ethereum/crypto/ring escrow")
+
+var (
+	errNoAuthority    = errors.New("missing authority key")
+	errWrongAuthority = errors.New("authority key does not match signature")
+	errNotRingMember  = errors.New("opened key is not a ring member")
+)
+
+// EscrowSign is a ring signature whose signer can be revealed by an authority.
+type EscrowSign struct {
+	M         [32]byte         // message
+	Ring      Ring             // array of public keys
+	Authority *ecdsa.PublicKey // key of the opening authority
+	E1, E2    *ecdsa.PublicKey // encryption of the signer's key to the authority
+	C         []*big.Int       // challenges, one per ring member
+	S         []*big.Int       // key responses, one per ring member
+	T         []*big.Int       // encryption responses, one per ring member
+	Curve     elliptic.Curve
+}
+
+// OpeningProof shows that an authority decrypted an escrowed signature
+// correctly, i.e. log_G(A) = log_E1(E2 - P).
+type OpeningProof struct {
+	C *big.Int
+	Z *big.Int
+}
+
+// escrowChallenge computes H(m, ring, A, E1, E2, a, b, d).
+func escrowChallenge(curve elliptic.Curve, sig *EscrowSign, a, b, d []*ecdsa.PublicKey) *big.Int {
+	data := [][]byte{escrowDomain, sig.M[:]}
+	for _, p := range sig.Ring {
+		data = append(data, pointBytes(p))
+	}
+	data = append(data, pointBytes(sig.Authority), pointBytes(sig.E1), pointBytes(sig.E2))
+	for _, points := range [][]*ecdsa.PublicKey{a, b, d} {
+		for _, p := range points {
+			data = append(data, pointBytes(p))
+		}
+	}
+	return hashToScalar(curve, data...)
+}
+
+// escrowCommitments recomputes the proof commitments of member j from its
+// challenge and responses.
+func escrowCommitments(sig *EscrowSign, j int) (a, b, d *ecdsa.PublicKey) {
+	curve := sig.Curve
+	a = pointAdd(baseMul(curve, sig.S[j]), pointMul(sig.Ring[j], sig.C[j]))
+	b = pointAdd(baseMul(curve, sig.T[j]), pointMul(sig.E1, sig.C[j]))
+	d = pointAdd(pointMul(sig.Authority, sig.T[j]), pointMul(pointSub(sig.E2, sig.Ring[j]), sig.C[j]))
+	return a, b, d
+}
+
+// SignEscrowed creates a ring signature over m that the holder of the private
+// key of authority can open.
+func SignEscrowed(m [32]byte, ring Ring, authority *ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int) (*EscrowSign, error) {
+	if authority == nil {
+		return nil, errNoAuthority
+	}
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	if !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	curve := privkey.Curve
+	N := curve.Params().N
+	n := len(ring)
+
+	// random scalars: encryption randomness r and the nonces u, v of the signer
+	rnd := make([]*big.Int, 3)
+	for i := range rnd {
+		var err error
+		if rnd[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+	}
+	r, u, v := rnd[0], rnd[1], rnd[2]
+
+	sig := &EscrowSign{
+		M:         m,
+		Ring:      ring,
+		Authority: authority,
+		E1:        baseMul(curve, r),
+		E2:        pointAdd(ring[s], pointMul(authority, r)),
+		C:         make([]*big.Int, n),
+		S:         make([]*big.Int, n),
+		T:         make([]*big.Int, n),
+		Curve:     curve,
+	}
+	a := make([]*ecdsa.PublicKey, n)
+	b := make([]*ecdsa.PublicKey, n)
+	d := make([]*ecdsa.PublicKey, n)
+	for j := range ring {
+		if j == s {
+			a[j], b[j], d[j] = baseMul(curve, u), baseMul(curve, v), pointMul(authority, v)
+			continue
+		}
+		for _, x := range []**big.Int{&sig.C[j], &sig.S[j], &sig.T[j]} {
+			var err error
+			if *x, err = randomScalar(curve); err != nil {
+				return nil, err
+			}
+		}
+		a[j], b[j], d[j] = escrowCommitments(sig, j)
+	}
+	// c_s = c - sum_{j != s} c_j, s_s = u - c_s*x, t_s = v - c_s*r
+	c := escrowChallenge(curve, sig, a, b, d)
+	for j, cj := range sig.C {
+		if j != s {
+			c.Sub(c, cj)
+		}
+	}
+	sig.C[s] = c.Mod(c, N)
+
+	ss := new(big.Int).Mul(sig.C[s], privkey.D)
+	ss.Sub(u, ss)
+	sig.S[s] = ss.Mod(ss, N)
+	ts := new(big.Int).Mul(sig.C[s], r)
+	ts.Sub(v, ts)
+	sig.T[s] = ts.Mod(ts, N)
+
+	return sig, nil
+}
+
+// VerifyEscrowed verifies an escrowed ring signature. It does not reveal or
+// require knowledge of the signer.
+// returns true if a valid signature, false otherwise
+func VerifyEscrowed(sig *EscrowSign) bool {
+	if sig == nil || sig.Authority == nil || sig.E1 == nil || sig.E2 == nil {
+		return false
+	}
+	n := len(sig.Ring)
+	if n < 2 || len(sig.C) != n || len(sig.S) != n || len(sig.T) != n {
+		return false
+	}
+	a := make([]*ecdsa.PublicKey, n)
+	b := make([]*ecdsa.PublicKey, n)
+	d := make([]*ecdsa.PublicKey, n)
+	sum := new(big.Int)
+	for j := range sig.Ring {
+		if sig.Ring[j] == nil || sig.C[j] == nil || sig.S[j] == nil || sig.T[j] == nil {
+			return false
+		}
+		a[j], b[j], d[j] = escrowCommitments(sig, j)
+		sum.Add(sum, sig.C[j])
+	}
+	c := escrowChallenge(sig.Curve, sig, a, b, d)
+	return sum.Mod(sum, sig.Curve.Params().N).Cmp(c) == 0
+}
+
+// openingChallenge computes the challenge of an opening proof.
+func openingChallenge(curve elliptic.Curve, domain []byte, authority, e1, e2, signer, t1, t2 *ecdsa.PublicKey) *big.Int {
+	return hashToScalar(curve, domain, []byte("open"), pointBytes(authority),
+		pointBytes(e1), pointBytes(e2), pointBytes(signer), pointBytes(t1), pointBytes(t2))
+}
+
+// proveOpening proves that signer = E2 - a*E1 for the private key a of the
+// authority with a Chaum-Pedersen proof of log_G(A) = log_E1(E2 - signer).
+func proveOpening(domain []byte, authority *ecdsa.PrivateKey, e1, e2, signer *ecdsa.PublicKey) (*OpeningProof, error) {
+	curve := authority.Curve
+	w, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	c := openingChallenge(curve, domain, &authority.PublicKey, e1, e2, signer, baseMul(curve, w), pointMul(e1, w))
+	z := new(big.Int).Mul(c, authority.D)
+	z.Sub(w, z)
+
+	return &OpeningProof{C: c, Z: z.Mod(z, curve.Params().N)}, nil
+}
+
+// verifyOpening checks a proof created by proveOpening.
+func verifyOpening(curve elliptic.Curve, domain []byte, authority, e1, e2, signer *ecdsa.PublicKey, proof *OpeningProof) bool {
+	if authority == nil || e1 == nil || e2 == nil || signer == nil {
+		return false
+	}
+	if proof == nil || proof.C == nil || proof.Z == nil {
+		return false
+	}
+	t1 := pointAdd(baseMul(curve, proof.Z), pointMul(authority, proof.C))
+	t2 := pointAdd(pointMul(e1, proof.Z), pointMul(pointSub(e2, signer), proof.C))
+	return openingChallenge(curve, domain, authority, e1, e2, signer, t1, t2).Cmp(proof.C) == 0
+}
+
+// Open decrypts the signer of a valid escrowed signature with the authority's
+// private key and proves the decryption correct.
+func Open(sig *EscrowSign, authority *ecdsa.PrivateKey) (*ecdsa.PublicKey, *OpeningProof, error) {
+	if !pointEqual(sig.Authority, &authority.PublicKey) {
+		return nil, nil, errWrongAuthority
+	}
+	// P = E2 - a*E1
+	signer := pointSub(sig.E2, pointMul(sig.E1, authority.D))
+	member := false
+	for _, pub := range sig.Ring {
+		if pointEqual(pub, signer) {
+			member = true
+			break
+		}
+	}
+	if !member {
+		return nil, nil, errNotRingMember
+	}
+	proof, err := proveOpening(escrowDomain, authority, sig.E1, sig.E2, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signer, proof, nil
+}
+
+// VerifyOpen checks that signer is the correct opening of sig by the
+// signature's authority.
+func VerifyOpen(sig *EscrowSign, signer *ecdsa.PublicKey, proof *OpeningProof) bool {
+	if sig == nil {
+		return false
+	}
+	return verifyOpening(sig.Curve, escrowDomain, sig.Authority, sig.E1, sig.E2, signer, proof)
+}
@@ -0,0 +1,48 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEscrow(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authority, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(5, key, 3)
+
+	sig, err := SignEscrowed([32]byte{7}, ring, &authority.PublicKey, key, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyEscrowed(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	signer, proof, err := Open(sig, authority)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	if !pointEqual(signer, ring[3]) {
+		t.Fatal("opened wrong signer")
+	}
+	if !VerifyOpen(sig, signer, proof) {
+		t.Fatal("valid opening rejected")
+	}
+	if VerifyOpen(sig, ring[0], proof) {
+		t.Error("false opening accepted")
+	}
+	if _, _, err := Open(sig, key); err != errWrongAuthority {
+		t.Errorf("error mismatch: have %v, want %v", err, errWrongAuthority)
+	}
+	// swapping the authority must invalidate the signature
+	sig.Authority = &key.PublicKey
+	if VerifyEscrowed(sig) {
+		t.Error("signature with replaced authority accepted")
+	}
+}
@@ -0,0 +1,92 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Ring signatures are often used as anonymous authorizations, and one that
+// leaks can otherwise be replayed forever. A signature with a deadline signs
+// the message bound to a last valid block number and timestamp instead of the
+// message itself, so the deadline cannot be changed without invalidating the
+// signature. The key image does not depend on the message, signatures with
+// and without deadline remain linkable.
+
+// expiryDomain separates messages bound to a deadline from other messages
+// signed with the package.
+var expiryDomain = []byte("go-ethereum/crypto/ring expiry")
+
+var errNoDeadline = errors.New("deadline without block number or timestamp")
+
+// Deadline is the last block a signature is valid in. A zero field does not
+// restrict validity.
+type Deadline struct {
+	Block uint64 // last valid block number
+	Time  uint64 // last valid block timestamp, in seconds
+}
+
+// Expired reports whether a block with the given number and timestamp is past
+// the deadline.
+func (d Deadline) Expired(number, time uint64) bool {
+	return (d.Block != 0 && number > d.Block) || (d.Time != 0 && time > d.Time)
+}
+
+// bind returns the message signed in place of m.
+func (d Deadline) bind(m [32]byte) (h [32]byte) {
+	var enc [16]byte
+	binary.BigEndian.PutUint64(enc[:8], d.Block)
+	binary.BigEndian.PutUint64(enc[8:], d.Time)
+
+	hw := sha3.NewKeccak256()
+	hw.Write(expiryDomain)
+	hw.Write(m[:])
+	hw.Write(enc[:])
+	hw.Sum(h[:0])
+	return h
+}
+
+// ChainContext provides the head of the chain deadlines are checked against.
+type ChainContext interface {
+	// Head returns the number and timestamp of the current head block.
+	Head() (number uint64, time uint64)
+}
+
+// ExpiringSign is a ring signature over M that is only valid up to Deadline.
+// Sig signs M bound to the deadline.
+type ExpiringSign struct {
+	M        [32]byte
+	Deadline Deadline
+	Sig      *RingSign
+}
+
+// SignWithDeadline creates a ring signature over m, see Sign, that is only
+// valid in blocks up to the deadline.
+func SignWithDeadline(m [32]byte, ring Ring, privkey *ecdsa.PrivateKey, s int, deadline Deadline) (*ExpiringSign, error) {
+	if deadline == (Deadline{}) {
+		return nil, errNoDeadline
+	}
+	sig, err := Sign(deadline.bind(m), ring, privkey, s)
+	if err != nil {
+		return nil, err
+	}
+	return &ExpiringSign{M: m, Deadline: deadline, Sig: sig}, nil
+}
+
+// VerifyAt verifies a signature with a deadline in a block with the given
+// number and timestamp. It returns true if a valid signature, false otherwise.
+func VerifyAt(sig *ExpiringSign, number, time uint64) bool {
+	if sig == nil || sig.Sig == nil || sig.Deadline == (Deadline{}) || sig.Deadline.Expired(number, time) {
+		return false
+	}
+	return sig.Sig.M == sig.Deadline.bind(sig.M) && Verify(sig.Sig)
+}
+
+// VerifyWithChain verifies a signature with a deadline against the current
+// head of chain. It returns true if a valid signature, false otherwise.
+func VerifyWithChain(sig *ExpiringSign, chain ChainContext) bool {
+	number, time := chain.Head()
+	return VerifyAt(sig, number, time)
+}
@@ -0,0 +1,51 @@
+package ring
+
+import "testing"
+
+type testHead struct{ number, time uint64 }
+
+func (h testHead) Head() (uint64, uint64) { return h.number, h.time }
+
+func TestSignWithDeadline(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	sig, err := SignWithDeadline([32]byte{1}, ring, keys[1], 1, Deadline{Block: 100, Time: 5000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		head  testHead
+		valid bool
+	}{
+		{testHead{99, 4000}, true},
+		{testHead{100, 5000}, true},
+		{testHead{101, 4000}, false},
+		{testHead{99, 5001}, false},
+	}
+	for _, tt := range tests {
+		if valid := VerifyWithChain(sig, tt.head); valid != tt.valid {
+			t.Errorf("head %d at %d: valid %v, want %v", tt.head.number, tt.head.time, valid, tt.valid)
+		}
+	}
+	// the deadline is bound to the signature
+	extended := *sig
+	extended.Deadline.Block = 200
+	if VerifyAt(&extended, 150, 0) {
+		t.Error("signature valid with extended deadline")
+	}
+	other := *sig
+	other.M[0]++
+	if VerifyAt(&other, 0, 0) {
+		t.Error("signature valid for other message")
+	}
+	// the key image links it to signatures without deadline
+	plain, err := Sign([32]byte{1}, ring, keys[1], 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plain.KeyImage().Equal(sig.Sig.KeyImage()) {
+		t.Error("signatures with and without deadline not linked")
+	}
+	if _, err := SignWithDeadline([32]byte{}, ring, keys[0], 0, Deadline{}); err != errNoDeadline {
+		t.Errorf("empty deadline: got %v, want %v", err, errNoDeadline)
+	}
+}
@@ -0,0 +1,163 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+)
+
+// External signing lets a device holding the private key x of a ring member,
+// such as a hardware wallet, take part in ring signing without the key ever
+// leaving the device. The device only performs the secret scalar operations;
+// the host supplies the ring and computes the challenge chain:
+//
+//	host -> device    message to confirm
+//	device -> host    ExternalCommitment  P = x*G, I = x*H_p(P), L = u*G,
+//	                                      R = u*H_p(P) for a fresh nonce u
+//	host -> device    challenge c_s of the signer, computed from L and R and
+//	                  random responses of the decoys
+//	device -> host    response s_s = u - c_s*x, closing the ring
+//
+// The device must answer a single challenge per commitment: responses to two
+// challenges under the same nonce reveal x. The host checks the response
+// against the commitment before assembling the signature.
+
+// ExternalCommitment is the device's first message.
+type ExternalCommitment struct {
+	Public *ecdsa.PublicKey // public key P of the device
+	Image  *ecdsa.PublicKey // key image x*H_p(P)
+	L, R   *ecdsa.PublicKey // nonce commitments u*G and u*H_p(P)
+}
+
+// ExternalSigner is the host side of an external signing session. A signer is
+// used for one signature.
+type ExternalSigner struct {
+	m      [32]byte
+	ring   Ring
+	index  int
+	h      *ecdsa.PublicKey
+	commit *ExternalCommitment
+	S, C   []*big.Int
+}
+
+// NewExternalSigner starts signing m over ring with the device that sent
+// commit, whose public key must be a member of the ring. It returns the
+// challenge to send to the device.
+func NewExternalSigner(m [32]byte, ring Ring, commit *ExternalCommitment) (*ExternalSigner, *big.Int, error) {
+	if len(ring) < 2 {
+		return nil, nil, errRingTooSmall
+	}
+	if err := ring.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if commit == nil || commit.Public == nil || commit.Image == nil || commit.L == nil || commit.R == nil {
+		return nil, nil, errInvalidResponse
+	}
+	index := ring.Index(commit.Public)
+	if index < 0 {
+		return nil, nil, errKeyNotInRing
+	}
+	curve := ring[index].Curve
+	for _, p := range []*ecdsa.PublicKey{commit.Image, commit.L, commit.R} {
+		if p.Curve != curve || p.X == nil || p.Y == nil || !curve.IsOnCurve(p.X, p.Y) {
+			return nil, nil, errInvalidResponse
+		}
+	}
+	S := make([]*big.Int, len(ring))
+	for i := range S {
+		if i == index {
+			continue
+		}
+		var err error
+		if S[i], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+	}
+	sc := getScratch()
+	defer putScratch(sc)
+
+	C := ringChallenges(sc, curve, m, ring, index, S, commit.Image, HashToPoint, DefaultTranscript, commit.L.X, commit.L.Y, commit.R.X, commit.R.Y)
+	signer := &ExternalSigner{
+		m:      m,
+		ring:   append(Ring{}, ring...),
+		index:  index,
+		h:      hashPointOf(ring[index]),
+		commit: commit,
+		S:      S,
+		C:      C,
+	}
+	return signer, C[index], nil
+}
+
+// Index returns the position of the device's key in the ring.
+func (e *ExternalSigner) Index() int {
+	return e.index
+}
+
+// Finalize checks the device's response to the challenge and returns the ring
+// signature.
+func (e *ExternalSigner) Finalize(z *big.Int) (*RingSign, error) {
+	if e.S == nil {
+		return nil, errSessionState
+	}
+	curve := e.h.Curve
+	if z == nil || z.Sign() < 0 || z.Cmp(curve.Params().N) >= 0 {
+		return nil, errInvalidResponse
+	}
+	if !partialValid(e.h, e.commit.Public, e.commit.Image, e.commit.L, e.commit.R, z, e.C[e.index]) {
+		return nil, errInvalidResponse
+	}
+	S := e.S
+	e.S = nil
+	S[e.index] = new(big.Int).Set(z)
+	return &RingSign{
+		Size:    len(e.ring),
+		M:       e.m,
+		C:       e.C[0],
+		S:       S,
+		Ring:    e.ring,
+		I:       e.commit.Image,
+		Curve:   curve,
+		Version: DefaultTranscript,
+	}, nil
+}
+
+// ExternalKey is the device side of an external signing session, for devices
+// and emulators implemented in Go. A key session answers one challenge.
+type ExternalKey struct {
+	key *ecdsa.PrivateKey
+	u   *big.Int
+}
+
+// NewExternalKey commits to a fresh nonce for signing with key and returns
+// the commitment to send to the host.
+func NewExternalKey(key *ecdsa.PrivateKey) (*ExternalKey, *ExternalCommitment, error) {
+	u, err := randomScalar(key.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := hashPointOf(&key.PublicKey)
+	commit := &ExternalCommitment{
+		Public: &key.PublicKey,
+		Image:  GenKeyImage(key),
+		L:      baseMul(key.Curve, u),
+		R:      pointMul(h, u),
+	}
+	commit.Image.Curve = key.Curve
+	return &ExternalKey{key: key, u: u}, commit, nil
+}
+
+// Respond returns the response s_s = u - c*x to the challenge c and discards
+// the nonce.
+func (k *ExternalKey) Respond(c *big.Int) (*big.Int, error) {
+	if k.u == nil {
+		return nil, errSessionState
+	}
+	N := k.key.Curve.Params().N
+	if c == nil || c.Sign() < 0 || c.Cmp(N) >= 0 {
+		return nil, errInvalidResponse
+	}
+	z := new(big.Int).Mul(c, k.key.D)
+	z.Sub(k.u, z).Mod(z, N)
+	k.u = nil
+	return z, nil
+}
@@ -0,0 +1,63 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestExternalSign(t *testing.T) {
+	ring, keys := testRing(t, 4)
+	device, commit, err := NewExternalKey(keys[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, c, err := NewExternalSigner([32]byte{1}, ring, commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Index() != 2 {
+		t.Fatalf("signer index: have %d, want 2", signer.Index())
+	}
+	z, err := device.Respond(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := device.Respond(c); err != errSessionState {
+		t.Errorf("second challenge: have %v, want %v", err, errSessionState)
+	}
+	// A corrupted response must be caught before assembling the signature
+	if _, err := signer.Finalize(new(big.Int).Add(z, big.NewInt(1))); err != errInvalidResponse {
+		t.Errorf("corrupt response: have %v, want %v", err, errInvalidResponse)
+	}
+	sig, err := signer.Finalize(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(sig) {
+		t.Fatal("signature does not verify")
+	}
+	local, err := Sign([32]byte{2}, ring, keys[2], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Link(sig, local) {
+		t.Error("external signature doesn't link to a local one of the same key")
+	}
+	if _, err := signer.Finalize(z); err != errSessionState {
+		t.Errorf("second finalize: have %v, want %v", err, errSessionState)
+	}
+}
+
+func TestExternalSignOutsider(t *testing.T) {
+	ring, _ := testRing(t, 3)
+	key, _ := crypto.GenerateKey()
+	_, commit, err := NewExternalKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewExternalSigner([32]byte{1}, ring, commit); err != errKeyNotInRing {
+		t.Errorf("outside key: have %v, want %v", err, errKeyNotInRing)
+	}
+}
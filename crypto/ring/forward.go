@@ -0,0 +1,266 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Forward-secure ring signatures split time into periods. A member's long-term
+// public key is the Merkle root over one public key per period, and the private
+// key for period t+1 is derived from a seed that is itself a one-way hash of
+// the seed of period t. Evolving the key erases the old seed, so a key stolen
+// in period t cannot be used to forge signatures for any earlier period.
+//
+// Members publish their key schedule, the list of per-period public keys, once.
+// A signature for period t is a disjunctive Schnorr proof over the ring
+// members' period t keys, each accompanied by a Merkle path to its root, so a
+// verifier only needs the roots to check it.
+
+// forwardDomain separates the hashes of forward-secure keys and signatures
+// from other hashes in the package.
+var forwardDomain = []byte("go-ethereum/crypto/ring forward")
+
+var (
+	errNoPeriods      = errors.New("key needs at least one period")
+	errKeyExpired     = errors.New("key has no periods left")
+	errPeriodMismatch = errors.New("ring member has no key for signing period")
+)
+
+// ForwardSecurePublicKey is the public key schedule of a forward-secure ring
+// member. Root is the long-term identity, Keys holds one key per period.
+type ForwardSecurePublicKey struct {
+	Root [32]byte
+	Keys []*ecdsa.PublicKey
+}
+
+// ForwardSecureKey is an evolving private key. Only the seed of the current
+// period is kept.
+type ForwardSecureKey struct {
+	Public *ForwardSecurePublicKey
+
+	period uint64
+	seed   [32]byte
+	key    *ecdsa.PrivateKey
+}
+
+// ForwardSecureSign is a forward-secure ring signature valid for one period.
+type ForwardSecureSign struct {
+	M      [32]byte     // message
+	Period uint64       // period the signature was created in
+	Roots  [][32]byte   // long-term identities of the ring members
+	Ring   Ring         // the ring members' keys for the period
+	Paths  [][][32]byte // Merkle paths of the period keys to the roots
+	C      []*big.Int   // challenges, one per ring member
+	S      []*big.Int   // responses, one per ring member
+	Curve  elliptic.Curve
+}
+
+// forwardPeriodKey derives the private key of a period from its seed.
+func forwardPeriodKey(curve elliptic.Curve, seed [32]byte) *ecdsa.PrivateKey {
+	d := hashToScalar(curve, forwardDomain, []byte("key"), seed[:])
+	if d.Sign() == 0 {
+		d.SetInt64(1) // unreachable in practice
+	}
+	pub := baseMul(curve, d)
+	return &ecdsa.PrivateKey{PublicKey: *pub, D: d}
+}
+
+// forwardNextSeed advances a seed by one period.
+func forwardNextSeed(seed [32]byte) [32]byte {
+	return sha3.Sum256(append(append([]byte{}, forwardDomain...), seed[:]...))
+}
+
+// forwardLeaf hashes a period key into a Merkle leaf.
+func forwardLeaf(pub *ecdsa.PublicKey) [32]byte {
+	data := append([]byte{0}, forwardDomain...)
+	return sha3.Sum256(append(data, pointBytes(pub)...))
+}
+
+// forwardNode hashes two Merkle children into their parent.
+func forwardNode(left, right [32]byte) [32]byte {
+	data := append([]byte{1}, forwardDomain...)
+	data = append(data, left[:]...)
+	return sha3.Sum256(append(data, right[:]...))
+}
+
+// forwardTree returns the levels of the Merkle tree over the period keys, the
+// leaves first. The leaf level is padded with zero hashes to a power of two.
+func forwardTree(keys []*ecdsa.PublicKey) [][][32]byte {
+	size := 1
+	for size < len(keys) {
+		size *= 2
+	}
+	level := make([][32]byte, size)
+	for i, pub := range keys {
+		level[i] = forwardLeaf(pub)
+	}
+	levels := [][][32]byte{level}
+	for len(level) > 1 {
+		next := make([][32]byte, len(level)/2)
+		for i := range next {
+			next[i] = forwardNode(level[2*i], level[2*i+1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// Path returns the Merkle path of the key of the given period.
+func (pub *ForwardSecurePublicKey) Path(period uint64) ([][32]byte, error) {
+	if period >= uint64(len(pub.Keys)) {
+		return nil, errPeriodMismatch
+	}
+	levels := forwardTree(pub.Keys)
+	path := make([][32]byte, 0, len(levels)-1)
+	idx := period
+	for _, level := range levels[:len(levels)-1] {
+		path = append(path, level[idx^1])
+		idx /= 2
+	}
+	return path, nil
+}
+
+// verifyForwardPath checks that pub is the key of period in the tree with the
+// given root.
+func verifyForwardPath(root [32]byte, pub *ecdsa.PublicKey, period uint64, path [][32]byte) bool {
+	if len(path) >= 64 || period>>uint(len(path)) != 0 {
+		return false
+	}
+	node := forwardLeaf(pub)
+	for i, sibling := range path {
+		if (period>>uint(i))&1 == 0 {
+			node = forwardNode(node, sibling)
+		} else {
+			node = forwardNode(sibling, node)
+		}
+	}
+	return node == root
+}
+
+// GenerateForwardSecureKey creates a forward-secure key for the given number of
+// periods on curve, starting in period 0.
+func GenerateForwardSecureKey(curve elliptic.Curve, periods uint64) (*ForwardSecureKey, error) {
+	if periods == 0 {
+		return nil, errNoPeriods
+	}
+	var seed [32]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	// derive the whole schedule from the initial seed
+	keys := make([]*ecdsa.PublicKey, periods)
+	next := seed
+	for i := range keys {
+		priv := forwardPeriodKey(curve, next)
+		keys[i] = &priv.PublicKey
+		next = forwardNextSeed(next)
+	}
+	levels := forwardTree(keys)
+
+	return &ForwardSecureKey{
+		Public: &ForwardSecurePublicKey{Root: levels[len(levels)-1][0], Keys: keys},
+		seed:   seed,
+		key:    forwardPeriodKey(curve, seed),
+	}, nil
+}
+
+// Period returns the current period of the key.
+func (k *ForwardSecureKey) Period() uint64 {
+	return k.period
+}
+
+// Evolve moves the key into the next period and erases the key material of
+// the current one. Once the last period has passed the key can no longer sign.
+func (k *ForwardSecureKey) Evolve() error {
+	if k.key == nil {
+		return errKeyExpired
+	}
+	curve := k.key.Curve
+	next := forwardNextSeed(k.seed)
+	for i := range k.seed {
+		k.seed[i] = 0
+	}
+	k.key.D.SetInt64(0)
+	k.key = nil
+
+	k.period++
+	if k.period < uint64(len(k.Public.Keys)) {
+		k.seed = next
+		k.key = forwardPeriodKey(curve, next)
+	}
+	return nil
+}
+
+// forwardPrefix returns the data the challenge is computed over besides the
+// nonce commitments.
+func forwardPrefix(m [32]byte, period uint64, roots [][32]byte, ring Ring) [][]byte {
+	var pb [8]byte
+	binary.BigEndian.PutUint64(pb[:], period)
+
+	data := [][]byte{forwardDomain, m[:], pb[:]}
+	for i := range roots {
+		data = append(data, roots[i][:], pointBytes(ring[i]))
+	}
+	return data
+}
+
+// SignForwardSecure creates a ring signature over m for the current period of
+// key, which must belong to ring[s].
+func SignForwardSecure(m [32]byte, ring []*ForwardSecurePublicKey, key *ForwardSecureKey, s int) (*ForwardSecureSign, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	if key.key == nil {
+		return nil, errKeyExpired
+	}
+	if ring[s].Root != key.Public.Root {
+		return nil, errNotSigner
+	}
+	period := key.period
+	sig := &ForwardSecureSign{
+		M:      m,
+		Period: period,
+		Roots:  make([][32]byte, len(ring)),
+		Ring:   make(Ring, len(ring)),
+		Paths:  make([][][32]byte, len(ring)),
+		Curve:  key.key.Curve,
+	}
+	for i, member := range ring {
+		path, err := member.Path(period)
+		if err != nil {
+			return nil, err
+		}
+		sig.Roots[i], sig.Ring[i], sig.Paths[i] = member.Root, member.Keys[period], path
+	}
+	var err error
+	sig.C, sig.S, err = proveSchnorrOr(forwardPrefix(m, period, sig.Roots, sig.Ring), sig.Ring, key.key, s)
+	if err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// VerifyForwardSecure verifies a forward-secure ring signature against the
+// roots it carries.
+// returns true if a valid signature, false otherwise
+func VerifyForwardSecure(sig *ForwardSecureSign) bool {
+	if sig == nil || len(sig.Ring) < 2 || len(sig.Roots) != len(sig.Ring) || len(sig.Paths) != len(sig.Ring) {
+		return false
+	}
+	for i, pub := range sig.Ring {
+		if pub == nil || !verifyForwardPath(sig.Roots[i], pub, sig.Period, sig.Paths[i]) {
+			return false
+		}
+	}
+	return verifySchnorrOr(sig.Curve, forwardPrefix(sig.M, sig.Period, sig.Roots, sig.Ring), sig.Ring, sig.C, sig.S)
+}
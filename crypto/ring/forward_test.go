@@ -0,0 +1,45 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestForwardSecure(t *testing.T) {
+	curve := crypto.S256()
+	keys := make([]*ForwardSecureKey, 3)
+	ring := make([]*ForwardSecurePublicKey, len(keys))
+	for i := range keys {
+		key, err := GenerateForwardSecureKey(curve, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i], ring[i] = key, key.Public
+	}
+	signer := keys[1]
+
+	for period := uint64(0); period < 5; period++ {
+		if signer.Period() != period {
+			t.Fatalf("period mismatch: have %d, want %d", signer.Period(), period)
+		}
+		sig, err := SignForwardSecure([32]byte{byte(period)}, ring, signer, 1)
+		if err != nil {
+			t.Fatalf("period %d: failed to sign: %v", period, err)
+		}
+		if !VerifyForwardSecure(sig) {
+			t.Fatalf("period %d: valid signature rejected", period)
+		}
+		// moving the signature into another period must fail
+		sig.Period ^= 1
+		if VerifyForwardSecure(sig) {
+			t.Errorf("period %d: signature accepted for period %d", period, sig.Period)
+		}
+		if err := signer.Evolve(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := SignForwardSecure([32]byte{}, ring, signer, 1); err != errKeyExpired {
+		t.Fatalf("error mismatch: have %v, want %v", err, errKeyExpired)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build none
+// +build none
+
+/*
+The gen_vectors tool writes the deterministic linkable ring signature test
+vectors in JSON. All keys and random values are derived from fixed seeds, so
+the output only changes if the signing transcript does.
+
+	go run gen_vectors.go -out testdata/lsag_vectors.json
+*/
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// scalar derives a non-zero scalar from a label and an index.
+func scalar(label string, i int) *big.Int {
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], uint64(i))
+	for ctr := byte(0); ; ctr++ {
+		k := new(big.Int).SetBytes(crypto.Keccak256([]byte("go-ethereum ring test vector"), []byte(label), idx[:], []byte{ctr}))
+		if k.Sign() > 0 && k.Cmp(crypto.S256().Params().N) < 0 {
+			return k
+		}
+	}
+}
+
+func main() {
+	out := flag.String("out", "", "output file (default stdout)")
+	flag.Parse()
+
+	cases := []struct {
+		size, signer int
+		message      string
+	}{
+		{2, 0, "two members, first signs"},
+		{2, 1, "two members, last signs"},
+		{3, 1, "three members"},
+		{5, 4, "five members"},
+		{11, 6, "eleven members"},
+	}
+	var vectors []*ring.TestVector
+	for n, c := range cases {
+		keys := make(ring.Ring, c.size)
+		responses := make([]*big.Int, c.size)
+		signer := new(big.Int)
+		for i := range keys {
+			d := scalar(fmt.Sprintf("key %d", n), i)
+			priv, err := crypto.ToECDSA(math.PaddedBigBytes(d, 32))
+			if err != nil {
+				fatalf("invalid key: %v", err)
+			}
+			keys[i] = &priv.PublicKey
+			responses[i] = scalar(fmt.Sprintf("response %d", n), i)
+			if i == c.signer {
+				signer = d
+			}
+		}
+		priv, _ := crypto.ToECDSA(math.PaddedBigBytes(signer, 32))
+		keys[c.signer] = &priv.PublicKey
+
+		m := crypto.Keccak256Hash([]byte(c.message))
+		v, err := ring.NewTestVector(c.message, keys, priv, c.signer, m, scalar(fmt.Sprintf("nonce %d", n), 0), responses)
+		if err != nil {
+			fatalf("failed to sign %q: %v", c.message, err)
+		}
+		vectors = append(vectors, v)
+	}
+	enc, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		fatalf("failed to encode vectors: %v", err)
+	}
+	enc = append(enc, '\n')
+	if *out == "" {
+		os.Stdout.Write(enc)
+		return
+	}
+	if err := ioutil.WriteFile(*out, enc, 0644); err != nil {
+		fatalf("failed to write vectors: %v", err)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,336 @@
+package ring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"math/big"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/crypto/babyjub"
+	"github.com/ethereum/go-ethereum/crypto/poseidon"
+)
+
+// The verification relation of SNARK-friendly ring signatures, see SNARKSign,
+// can be exported as a gnark circuit. GnarkCircuit generates a Go package for
+// a fixed ring size holding the circuit and a Groth16 prover and verifier
+// around it, so that the verification of a signature over any number of
+// members is replaced by a constant size proof. gnark exports verifying keys
+// as Solidity contracts checking proofs with the BN254 pairing precompiles.
+// The circuit compiles unchanged for PLONK with the scs builder.
+//
+// The only public input is the digest of the message and the ring, see
+// SNARKDigest. Verifiers compute it from the message and ring they expect.
+// The generated package depends on gnark only, which go-ethereum does not
+// vendor; its Witness type is converted from SNARKWitness. The circuit is
+// compiled, proven and verified by the tests built with the gnark tag, which
+// fetch gnark with the go command.
+
+var (
+	errInvalidSNARKSign = errors.New("invalid SNARK-friendly ring signature")
+	errPackageName      = errors.New("invalid package name")
+)
+
+// SNARKWitness holds the values a circuit proving a SNARKSign is assigned.
+type SNARKWitness struct {
+	Digest *big.Int      // public digest of the message and the ring
+	MsgHi  *big.Int      // upper 16 bytes of the message
+	MsgLo  *big.Int      // lower 16 bytes of the message
+	Ring   [][2]*big.Int // affine coordinates of the ring members
+	C      *big.Int      // challenge of the first ring member
+	S      []*big.Int    // responses, one per ring member
+}
+
+// SNARKDigest returns the digest of m and ring that is the public input of a
+// proof of a SNARKSign.
+func SNARKDigest(m [32]byte, ring Ring) (*big.Int, error) {
+	return snarkDigest(m, ring)
+}
+
+// Witness returns the circuit assignment of the signature, which must be
+// valid.
+func (sig *SNARKSign) Witness() (*SNARKWitness, error) {
+	if !VerifySNARK(sig) {
+		return nil, errInvalidSNARKSign
+	}
+	digest, err := snarkDigest(sig.M, sig.Ring)
+	if err != nil {
+		return nil, err
+	}
+	w := &SNARKWitness{
+		Digest: digest,
+		MsgHi:  new(big.Int).SetBytes(sig.M[:16]),
+		MsgLo:  new(big.Int).SetBytes(sig.M[16:]),
+		Ring:   make([][2]*big.Int, len(sig.Ring)),
+		C:      new(big.Int).Set(sig.C),
+		S:      make([]*big.Int, len(sig.S)),
+	}
+	for i, pub := range sig.Ring {
+		w.Ring[i] = [2]*big.Int{new(big.Int).Set(pub.X), new(big.Int).Set(pub.Y)}
+		w.S[i] = new(big.Int).Set(sig.S[i])
+	}
+	return w, nil
+}
+
+// gnarkData is the data the circuit template is executed with.
+type gnarkData struct {
+	Package string
+	Size    int
+	A, D    *big.Int
+	Gx, Gy  *big.Int
+	Tag     *big.Int
+	Full    int
+	Partial int
+	ARK     []*big.Int
+	MDS     [][]*big.Int
+}
+
+// GnarkCircuit returns the source of Go package pkg with a gnark circuit
+// verifying SNARK-friendly ring signatures over rings of size members.
+func GnarkCircuit(pkg string, size int) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, errPackageName
+	}
+	if size < 2 {
+		return nil, errRingTooSmall
+	}
+	partial, ark, mds, err := poseidon.Constants(3)
+	if err != nil {
+		return nil, err
+	}
+	params := babyjub.BabyJub().Params()
+	data := &gnarkData{
+		Package: pkg,
+		Size:    size,
+		A:       big.NewInt(168700),
+		D:       params.B,
+		Gx:      params.Gx,
+		Gy:      params.Gy,
+		Tag:     snarkTag,
+		Full:    poseidon.FullRounds,
+		Partial: partial,
+		ARK:     ark,
+		MDS:     mds,
+	}
+	buffer := new(bytes.Buffer)
+	tmpl := template.Must(template.New("").Parse(gnarkTemplate))
+	if err := tmpl.Execute(buffer, data); err != nil {
+		return nil, err
+	}
+	code, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, buffer)
+	}
+	return code, nil
+}
+
+// gnarkTemplate is the source of the generated circuit package.
+const gnarkTemplate = `// Code generated by crypto/ring. DO NOT EDIT.
+
+// Package {{.Package}} proves SNARK-friendly ring signatures over rings of
+// {{.Size}} members with Groth16 over BN254.
+package {{.Package}}
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// RingSize is the number of ring members the circuit verifies.
+const RingSize = {{.Size}}
+
+var errRingSize = errors.New("ring size does not match circuit")
+
+// RingCircuit verifies a ring.SNARKSign. The digest of the message and the
+// ring is its only public input.
+type RingCircuit struct {
+	Digest frontend.Variable ` + "`gnark:\",public\"`" + `
+
+	MsgHi frontend.Variable
+	MsgLo frontend.Variable
+	Ring  [RingSize][2]frontend.Variable
+	C     frontend.Variable
+	S     [RingSize]frontend.Variable
+}
+
+func bigInt(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 10)
+	return n
+}
+
+// BabyJubjub in twisted Edwards form a*x^2 + y^2 = 1 + d*x^2*y^2.
+var (
+	curveA = bigInt("{{.A}}")
+	curveD = bigInt("{{.D}}")
+	baseX  = bigInt("{{.Gx}}")
+	baseY  = bigInt("{{.Gy}}")
+	tag    = bigInt("{{.Tag}}")
+)
+
+// Poseidon over three inputs with the circomlib parameters.
+const (
+	poseidonWidth   = 4
+	poseidonFull    = {{.Full}}
+	poseidonPartial = {{.Partial}}
+)
+
+var poseidonARK = []*big.Int{
+{{- range .ARK}}
+	bigInt("{{.}}"),
+{{- end}}
+}
+
+var poseidonMDS = [][]*big.Int{
+{{- range .MDS}}
+	{
+	{{- range .}}
+		bigInt("{{.}}"),
+	{{- end}}
+	},
+{{- end}}
+}
+
+// Define implements frontend.Circuit.
+func (c *RingCircuit) Define(api frontend.API) error {
+	h := poseidon(api, tag, c.MsgHi, c.MsgLo)
+	for i := range c.Ring {
+		assertOnCurve(api, c.Ring[i])
+		h = poseidon(api, h, c.Ring[i][0], c.Ring[i][1])
+	}
+	api.AssertIsEqual(h, c.Digest)
+
+	base := [2]frontend.Variable{baseX, baseY}
+	ch := c.C
+	for i := range c.Ring {
+		L := add(api, scalarMul(api, base, c.S[i]), scalarMul(api, c.Ring[i], ch))
+		ch = poseidon(api, h, L[0], L[1])
+	}
+	api.AssertIsEqual(ch, c.C)
+	return nil
+}
+
+func poseidon(api frontend.API, inputs ...frontend.Variable) frontend.Variable {
+	state := append([]frontend.Variable{0}, inputs...)
+	for r := 0; r < poseidonFull+poseidonPartial; r++ {
+		for i := range state {
+			state[i] = api.Add(state[i], poseidonARK[r*poseidonWidth+i])
+		}
+		if r < poseidonFull/2 || r >= poseidonFull/2+poseidonPartial {
+			for i := range state {
+				state[i] = sbox(api, state[i])
+			}
+		} else {
+			state[0] = sbox(api, state[0])
+		}
+		next := make([]frontend.Variable, poseidonWidth)
+		for i := range next {
+			next[i] = 0
+			for j := range state {
+				next[i] = api.Add(next[i], api.Mul(poseidonMDS[i][j], state[j]))
+			}
+		}
+		state = next
+	}
+	return state[0]
+}
+
+func sbox(api frontend.API, x frontend.Variable) frontend.Variable {
+	x2 := api.Mul(x, x)
+	return api.Mul(x, x2, x2)
+}
+
+func assertOnCurve(api frontend.API, p [2]frontend.Variable) {
+	xx := api.Mul(p[0], p[0])
+	yy := api.Mul(p[1], p[1])
+	api.AssertIsEqual(api.Add(api.Mul(curveA, xx), yy), api.Add(1, api.Mul(curveD, xx, yy)))
+}
+
+// add is the complete twisted Edwards addition law.
+func add(api frontend.API, p, q [2]frontend.Variable) [2]frontend.Variable {
+	xx := api.Mul(p[0], q[0])
+	yy := api.Mul(p[1], q[1])
+	dxy := api.Mul(curveD, xx, yy)
+	x := api.Div(api.Add(api.Mul(p[0], q[1]), api.Mul(p[1], q[0])), api.Add(1, dxy))
+	y := api.Div(api.Sub(yy, api.Mul(curveA, xx)), api.Sub(1, dxy))
+	return [2]frontend.Variable{x, y}
+}
+
+// scalarMul multiplies p by k, which is used unreduced like the challenges
+// of ring.SNARKSign.
+func scalarMul(api frontend.API, p [2]frontend.Variable, k frontend.Variable) [2]frontend.Variable {
+	bits := api.ToBinary(k, 254)
+	acc := [2]frontend.Variable{0, 1}
+	for i := len(bits) - 1; i >= 0; i-- {
+		acc = add(api, acc, acc)
+		sum := add(api, acc, p)
+		acc = [2]frontend.Variable{api.Select(bits[i], sum[0], acc[0]), api.Select(bits[i], sum[1], acc[1])}
+	}
+	return acc
+}
+
+// Witness holds the values of a ring.SNARKWitness, which converts to it.
+type Witness struct {
+	Digest *big.Int
+	MsgHi  *big.Int
+	MsgLo  *big.Int
+	Ring   [][2]*big.Int
+	C      *big.Int
+	S      []*big.Int
+}
+
+// Assign returns the circuit assignment of a signature witness.
+func Assign(w *Witness) (*RingCircuit, error) {
+	if len(w.Ring) != RingSize || len(w.S) != RingSize {
+		return nil, errRingSize
+	}
+	c := &RingCircuit{Digest: w.Digest, MsgHi: w.MsgHi, MsgLo: w.MsgLo, C: w.C}
+	for i := range w.Ring {
+		c.Ring[i] = [2]frontend.Variable{w.Ring[i][0], w.Ring[i][1]}
+		c.S[i] = w.S[i]
+	}
+	return c, nil
+}
+
+// Compile compiles the circuit into a rank-1 constraint system over BN254.
+func Compile() (constraint.ConstraintSystem, error) {
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, new(RingCircuit))
+}
+
+// Setup generates Groth16 keys for the circuit. Keys used in production must
+// come from a multi-party ceremony instead.
+func Setup(ccs constraint.ConstraintSystem) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	return groth16.Setup(ccs)
+}
+
+// Prove proves that the witness of a signature, see ring.SNARKSign.Witness,
+// satisfies the circuit.
+func Prove(ccs constraint.ConstraintSystem, pk groth16.ProvingKey, w *Witness) (groth16.Proof, error) {
+	assignment, err := Assign(w)
+	if err != nil {
+		return nil, err
+	}
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	return groth16.Prove(ccs, pk, witness)
+}
+
+// Verify checks a proof of a signature over the message and ring with the
+// given digest, see ring.SNARKDigest.
+func Verify(vk groth16.VerifyingKey, proof groth16.Proof, digest *big.Int) error {
+	public, err := frontend.NewWitness(&RingCircuit{Digest: digest}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return err
+	}
+	return groth16.Verify(proof, vk, public)
+}
+`
@@ -0,0 +1,123 @@
+//go:build gnark
+// +build gnark
+
+package ring
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// gnarkVersion is the gnark release the generated circuit is tested with.
+const gnarkVersion = "v0.11.0"
+
+// gnarkProveTest proves and verifies the signature witness in witness.json
+// with the generated circuit package.
+const gnarkProveTest = `package ringcircuit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"testing"
+)
+
+func TestProve(t *testing.T) {
+	data, err := ioutil.ReadFile("witness.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w Witness
+	if err := json.Unmarshal(data, &w); err != nil {
+		t.Fatal(err)
+	}
+	ccs, err := Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := Prove(ccs, pk, &w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(vk, proof, w.Digest); err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+	if err := Verify(vk, proof, new(big.Int).Add(w.Digest, big.NewInt(1))); err == nil {
+		t.Error("proof verifies for other digest")
+	}
+	w.S[0] = new(big.Int).Add(w.S[0], big.NewInt(1))
+	if _, err := Prove(ccs, pk, &w); err == nil {
+		t.Error("proved invalid signature")
+	}
+}
+`
+
+// TestGnarkProve compiles the generated circuit with gnark and proves the
+// witness of a signature accepted by VerifySNARK. It needs the go command and
+// access to the module proxy, so it only runs with the gnark build tag.
+func TestGnarkProve(t *testing.T) {
+	gocmd := runtime.GOROOT() + "/bin/go"
+	if !common.FileExist(gocmd) {
+		t.Skip("go sdk not found for testing")
+	}
+	ws, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary workspace: %v", err)
+	}
+	defer os.RemoveAll(ws)
+
+	ring, key := newBabyJubRing(t, 2, 1)
+	sig, err := SignSNARK([32]byte{1, 2, 3}, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifySNARK(sig) {
+		t.Fatal("signature does not verify")
+	}
+	w, err := sig.Witness()
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := GnarkCircuit("ringcircuit", len(ring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"go.mod":          []byte("module ringcircuit\n\ngo 1.22\n"),
+		"circuit.go":      code,
+		"circuit_test.go": []byte(gnarkProveTest),
+		"witness.json":    witness,
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(ws, name), data, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	for _, args := range [][]string{
+		{"get", "github.com/consensys/gnark@" + gnarkVersion},
+		{"mod", "tidy"},
+		{"test", "-v", "-count", "1"},
+	} {
+		cmd := exec.Command(gocmd, args...)
+		cmd.Dir = ws
+		cmd.Env = append(os.Environ(), "GO111MODULE=on", "GOFLAGS=-mod=mod")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %v failed: %v\n%s", args, err, out)
+		}
+	}
+}
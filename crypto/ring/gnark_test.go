@@ -0,0 +1,86 @@
+package ring
+
+import (
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/babyjub"
+	"github.com/ethereum/go-ethereum/crypto/poseidon"
+)
+
+func TestSNARKWitness(t *testing.T) {
+	ring, key := newBabyJubRing(t, 3, 2)
+	sig, err := SignSNARK([32]byte{1, 2, 3}, ring, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := sig.Witness()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Evaluate the circuit relation on the witness values alone.
+	h, err := poseidon.Hash(snarkTag, w.MsgHi, w.MsgLo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range w.Ring {
+		if h, err = poseidon.Hash(h, p[0], p[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h.Cmp(w.Digest) != 0 {
+		t.Fatal("digest does not match ring and message")
+	}
+	curve := babyjub.BabyJub()
+	c := w.C
+	for i, p := range w.Ring {
+		x, y := curve.ScalarBaseMult(w.S[i].Bytes())
+		px, py := curve.ScalarMult(p[0], p[1], c.Bytes())
+		x, y = curve.Add(x, y, px, py)
+		if c, err = poseidon.Hash(h, x, y); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.Cmp(w.C) != 0 {
+		t.Error("witness does not close the ring")
+	}
+
+	sig.S[0].Add(sig.S[0], w.C)
+	if _, err := sig.Witness(); err != errInvalidSNARKSign {
+		t.Errorf("invalid signature: have %v, want %v", err, errInvalidSNARKSign)
+	}
+}
+
+func TestGnarkCircuit(t *testing.T) {
+	code, err := GnarkCircuit("ringcircuit", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "circuit.go", code, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name.Name != "ringcircuit" {
+		t.Errorf("package name: have %s, want ringcircuit", file.Name.Name)
+	}
+	if !strings.Contains(string(code), "const RingSize = 16\n") {
+		t.Error("ring size missing from circuit")
+	}
+	partial, ark, _, _ := poseidon.Constants(3)
+	if have := strings.Count(string(code), "\tbigInt(\""); have < len(ark) {
+		t.Errorf("round constants: have %d, want at least %d", have, len(ark))
+	}
+	if !strings.Contains(string(code), "poseidonPartial = "+strconv.Itoa(partial)) {
+		t.Error("partial rounds missing from circuit")
+	}
+
+	if _, err := GnarkCircuit("ring circuit", 16); err != errPackageName {
+		t.Errorf("invalid package: have %v, want %v", err, errPackageName)
+	}
+	if _, err := GnarkCircuit("ringcircuit", 1); err != errRingTooSmall {
+		t.Errorf("small ring: have %v, want %v", err, errRingTooSmall)
+	}
+}
@@ -0,0 +1,139 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+)
+
+// Group signatures complement the spontaneous ring model for permissioned
+// deployments. A group manager maintains the list of members and holds the
+// opening key; members sign on behalf of the whole group without choosing a
+// ring, and only the manager can reveal who signed. Openings come with a proof
+// that anyone holding the group's public description can check.
+//
+// Signatures are escrowed ring signatures over the member list, encrypted to
+// the opener key of the group.
+
+var (
+	errNotGroupMember = errors.New("key is not a group member")
+	errMemberExists   = errors.New("key is already a group member")
+)
+
+// Group is the public description of a group, as published by its manager.
+type Group struct {
+	Members Ring             // public keys of the members
+	Opener  *ecdsa.PublicKey // key able to open signatures
+}
+
+// GroupManager sets up a group and opens its signatures.
+type GroupManager struct {
+	opener  *ecdsa.PrivateKey
+	members Ring
+}
+
+// NewGroupManager creates a manager for a new, empty group on curve with a
+// fresh opening key.
+func NewGroupManager(curve elliptic.Curve) (*GroupManager, error) {
+	d, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	opener := &ecdsa.PrivateKey{PublicKey: *baseMul(curve, d), D: d}
+	return &GroupManager{opener: opener}, nil
+}
+
+// NewGroupManagerFromKey creates a manager for a new, empty group with the
+// given opening key.
+func NewGroupManagerFromKey(opener *ecdsa.PrivateKey) *GroupManager {
+	return &GroupManager{opener: opener}
+}
+
+// Add registers a member key with the group.
+func (gm *GroupManager) Add(pub *ecdsa.PublicKey) error {
+	if gm.index(pub) >= 0 {
+		return errMemberExists
+	}
+	gm.members = append(gm.members, pub)
+	return nil
+}
+
+// Remove revokes a member key. Signatures made before the removal remain
+// valid with respect to the group description they were made for.
+func (gm *GroupManager) Remove(pub *ecdsa.PublicKey) error {
+	i := gm.index(pub)
+	if i < 0 {
+		return errNotGroupMember
+	}
+	gm.members = append(gm.members[:i:i], gm.members[i+1:]...)
+	return nil
+}
+
+func (gm *GroupManager) index(pub *ecdsa.PublicKey) int {
+	return (&Group{Members: gm.members}).index(pub)
+}
+
+// Group returns the current public description of the group.
+func (gm *GroupManager) Group() *Group {
+	return &Group{
+		Members: append(Ring{}, gm.members...),
+		Opener:  &gm.opener.PublicKey,
+	}
+}
+
+// Open reveals the index of the member who created sig in the group it was
+// made for, together with a proof of correct opening.
+func (gm *GroupManager) Open(sig *EscrowSign) (int, *OpeningProof, error) {
+	signer, proof, err := Open(sig, gm.opener)
+	if err != nil {
+		return -1, nil, err
+	}
+	return (&Group{Members: sig.Ring}).index(signer), proof, nil
+}
+
+func (g *Group) index(pub *ecdsa.PublicKey) int {
+	for i, member := range g.Members {
+		if pointEqual(member, pub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matches reports whether sig was made for the group g.
+func (g *Group) matches(sig *EscrowSign) bool {
+	if sig == nil || len(sig.Ring) != len(g.Members) || !pointEqual(sig.Authority, g.Opener) {
+		return false
+	}
+	for i := range g.Members {
+		if !pointEqual(sig.Ring[i], g.Members[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SignGroup creates a group signature over m with the private key of a member
+// of group.
+func SignGroup(m [32]byte, group *Group, privkey *ecdsa.PrivateKey) (*EscrowSign, error) {
+	s := group.index(&privkey.PublicKey)
+	if s < 0 {
+		return nil, errNotGroupMember
+	}
+	return SignEscrowed(m, group.Members, group.Opener, privkey, s)
+}
+
+// VerifyGroup verifies a group signature against the group description.
+// returns true if a valid signature, false otherwise
+func VerifyGroup(group *Group, sig *EscrowSign) bool {
+	return group.matches(sig) && VerifyEscrowed(sig)
+}
+
+// VerifyGroupOpen checks that the member at index of group is the correct
+// opening of sig.
+func VerifyGroupOpen(group *Group, sig *EscrowSign, index int, proof *OpeningProof) bool {
+	if !group.matches(sig) || index < 0 || index >= len(group.Members) {
+		return false
+	}
+	return VerifyOpen(sig, group.Members[index], proof)
+}
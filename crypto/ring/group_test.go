@@ -0,0 +1,78 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestGroup(t *testing.T) {
+	gm, err := NewGroupManager(crypto.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		if err := gm.Add(&keys[i].PublicKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gm.Add(&keys[0].PublicKey); err != errMemberExists {
+		t.Fatalf("wrong error: have %v, want %v", err, errMemberExists)
+	}
+	group := gm.Group()
+
+	sig, err := SignGroup([32]byte{3}, group, keys[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyGroup(group, sig) {
+		t.Fatal("valid signature rejected")
+	}
+	index, proof, err := gm.Open(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 2 {
+		t.Fatalf("wrong signer: have %d, want 2", index)
+	}
+	if !VerifyGroupOpen(group, sig, index, proof) {
+		t.Fatal("valid opening rejected")
+	}
+	if VerifyGroupOpen(group, sig, 1, proof) {
+		t.Fatal("opening to the wrong member accepted")
+	}
+	// revoked members can no longer sign for the new group
+	if err := gm.Remove(&keys[2].PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	updated := gm.Group()
+	if _, err := SignGroup([32]byte{4}, updated, keys[2]); err != errNotGroupMember {
+		t.Fatalf("wrong error: have %v, want %v", err, errNotGroupMember)
+	}
+	if VerifyGroup(updated, sig) {
+		t.Fatal("signature accepted for a different group")
+	}
+	if !VerifyGroup(group, sig) {
+		t.Fatal("old signature rejected for its group")
+	}
+}
+
+func TestGroupOtherOpener(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	gm, _ := NewGroupManager(crypto.S256())
+	gm.Add(&key.PublicKey)
+	gm.Add(&other.PublicKey)
+
+	sig, err := SignGroup([32]byte{5}, gm.Group(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	impostor, _ := NewGroupManager(crypto.S256())
+	if _, _, err := impostor.Open(sig); err != errWrongAuthority {
+		t.Fatalf("wrong error: have %v, want %v", err, errWrongAuthority)
+	}
+}
@@ -0,0 +1,170 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Hierarchical deterministic keys (BIP32) let a wallet recover all of its ring
+// and stealth keys from one seed, usually derived from a BIP39 mnemonic. Keys
+// are derived on secp256k1 along these paths:
+//
+//	m/44'/60'/account'/0/index   ring signing keys
+//	m/44'/60'/account'/2'/0'     stealth view key
+//	m/44'/60'/account'/2'/1'     stealth spend key
+//
+// Ring signing keys follow the Ethereum account path, so they are the keys of
+// the wallet's addresses and can be found on chain by a chainkeys.RingBuilder. The
+// stealth keys use hardened derivation from a branch of their own: a leaked
+// view key together with public derivation data reveals nothing about the
+// spend key or the account keys.
+
+// hardened marks a hardened derivation path component.
+const hardened = 0x80000000
+
+var (
+	errSeedLength   = errors.New("seed must be between 16 and 64 bytes")
+	errInvalidChild = errors.New("derived key is invalid, use the next index")
+)
+
+// HDKey is an extended private key on secp256k1.
+type HDKey struct {
+	key       *big.Int
+	chainCode [32]byte
+}
+
+// MnemonicToSeed returns the BIP39 seed of mnemonic protected by passphrase.
+// The mnemonic is not checked against the BIP39 word list.
+func MnemonicToSeed(mnemonic, passphrase string) []byte {
+	password := norm.NFKD.Bytes([]byte(mnemonic))
+	salt := norm.NFKD.Bytes([]byte("mnemonic" + passphrase))
+	return pbkdf2.Key(password, salt, 2048, 64, sha512.New)
+}
+
+// NewMasterKey returns the master key of a seed.
+func NewMasterKey(seed []byte) (*HDKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errSeedLength
+	}
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	return newHDKey(mac.Sum(nil), nil)
+}
+
+// NewMasterKeyFromMnemonic returns the master key of the BIP39 seed of
+// mnemonic and passphrase.
+func NewMasterKeyFromMnemonic(mnemonic, passphrase string) (*HDKey, error) {
+	return NewMasterKey(MnemonicToSeed(mnemonic, passphrase))
+}
+
+// newHDKey creates the key IL + parent || IR from the output I of HMAC-SHA512,
+// with a nil parent for master keys.
+func newHDKey(I []byte, parent *big.Int) (*HDKey, error) {
+	N := crypto.S256().Params().N
+	k := new(big.Int).SetBytes(I[:32])
+	if k.Cmp(N) >= 0 {
+		return nil, errInvalidChild
+	}
+	if parent != nil {
+		k.Add(k, parent).Mod(k, N)
+	}
+	if k.Sign() == 0 {
+		return nil, errInvalidChild
+	}
+	key := &HDKey{key: k}
+	copy(key.chainCode[:], I[32:])
+	return key, nil
+}
+
+// Child returns the child key with the given index, hardened if the index is
+// at least 2^31.
+func (k *HDKey) Child(index uint32) (*HDKey, error) {
+	var data []byte
+	if index >= hardened {
+		data = append([]byte{0}, k.keyBytes()...)
+	} else {
+		data = crypto.CompressPubkey(&k.PrivateKey().PublicKey)
+	}
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], index)
+
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	mac.Write(data)
+	mac.Write(idx[:])
+	return newHDKey(mac.Sum(nil), k.key)
+}
+
+// Derive returns the key at path relative to k. Paths are compatible with
+// accounts.DerivationPath.
+func (k *HDKey) Derive(path []uint32) (*HDKey, error) {
+	var err error
+	for _, index := range path {
+		if k, err = k.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// keyBytes returns the private key as 32 bytes.
+func (k *HDKey) keyBytes() []byte {
+	return math.PaddedBigBytes(k.key, 32)
+}
+
+// PrivateKey returns the secp256k1 private key of k.
+func (k *HDKey) PrivateKey() *ecdsa.PrivateKey {
+	key, err := crypto.ToECDSA(k.keyBytes())
+	if err != nil {
+		panic(err) // keys are checked on derivation
+	}
+	return key
+}
+
+// RingKeyPath returns the derivation path of the ring signing key index of an
+// account.
+func RingKeyPath(account, index uint32) []uint32 {
+	return []uint32{hardened + 44, hardened + 60, hardened + account, 0, index}
+}
+
+// StealthKeyPaths returns the derivation paths of the stealth view and spend
+// keys of an account.
+func StealthKeyPaths(account uint32) (view, spend []uint32) {
+	base := []uint32{hardened + 44, hardened + 60, hardened + account, hardened + 2}
+	view = append(append([]uint32{}, base...), hardened+0)
+	spend = append(append([]uint32{}, base...), hardened+1)
+	return view, spend
+}
+
+// RingKey derives the ring signing key index of an account from the master
+// key k.
+func (k *HDKey) RingKey(account, index uint32) (*RingKeyPair, error) {
+	child, err := k.Derive(RingKeyPath(account, index))
+	if err != nil {
+		return nil, err
+	}
+	return NewRingKeyPair(child.PrivateKey()), nil
+}
+
+// StealthKeys derives the stealth view and spend keys of an account from the
+// master key k.
+func (k *HDKey) StealthKeys(account uint32) (view, spend *ecdsa.PrivateKey, err error) {
+	viewPath, spendPath := StealthKeyPaths(account)
+	viewKey, err := k.Derive(viewPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	spendKey, err := k.Derive(spendPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return viewKey.PrivateKey(), spendKey.PrivateKey(), nil
+}
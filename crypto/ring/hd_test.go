@@ -0,0 +1,80 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Test vector 1 of BIP32.
+func TestHDKeyDerive(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path []uint32
+		key  string
+	}{
+		{nil, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{[]uint32{hardened}, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{[]uint32{hardened, 1}, "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{[]uint32{hardened, 1, hardened + 2}, "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{[]uint32{hardened, 1, hardened + 2, 2}, "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{[]uint32{hardened, 1, hardened + 2, 2, 1000000000}, "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, tt := range tests {
+		key, err := master.Derive(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := hex.EncodeToString(crypto.FromECDSA(key.PrivateKey())); have != tt.key {
+			t.Errorf("path %v: key %s, want %s", tt.path, have, tt.key)
+		}
+	}
+	if _, err := NewMasterKey(seed[:15]); err != errSeedLength {
+		t.Errorf("short seed: got %v, want %v", err, errSeedLength)
+	}
+}
+
+func TestMnemonicToSeed(t *testing.T) {
+	mnemonic := strings.Repeat("abandon ", 11) + "about"
+	want, _ := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	if seed := MnemonicToSeed(mnemonic, "TREZOR"); !bytes.Equal(seed, want) {
+		t.Errorf("seed %x, want %x", seed, want)
+	}
+}
+
+func TestHDRingAndStealthKeys(t *testing.T) {
+	master, err := NewMasterKeyFromMnemonic(strings.Repeat("abandon ", 11)+"about", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first ring key is the first Ethereum account of the mnemonic
+	kp, err := master.RingKey(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr := crypto.PubkeyToAddress(*kp.PublicMember()); addr.Hex() != "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" {
+		t.Errorf("first ring key has address %s", addr.Hex())
+	}
+	view, spend, err := master.StealthKeys(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := master.StealthKeys(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.D.Cmp(spend.D) == 0 || view.D.Cmp(other.D) == 0 {
+		t.Error("stealth keys not distinct")
+	}
+	again, _, _ := master.StealthKeys(0)
+	if again.D.Cmp(view.D) != 0 {
+		t.Error("stealth key derivation not deterministic")
+	}
+}
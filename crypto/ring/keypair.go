@@ -0,0 +1,73 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+)
+
+var errKeyZeroed = errors.New("key pair has been zeroed")
+
+// RingKeyPair is the key of a ring member. It finds its own position in the
+// rings it signs for and can erase its private key once no longer needed.
+// It is not safe for concurrent use with Zero.
+type RingKeyPair struct {
+	key   *ecdsa.PrivateKey
+	pub   ecdsa.PublicKey
+	image *ecdsa.PublicKey
+}
+
+// GenerateRingKeyPair creates a key pair on curve.
+func GenerateRingKeyPair(curve elliptic.Curve) (*RingKeyPair, error) {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewRingKeyPair(key), nil
+}
+
+// NewRingKeyPair wraps key, which the key pair takes ownership of: Zero
+// overwrites it.
+func NewRingKeyPair(key *ecdsa.PrivateKey) *RingKeyPair {
+	return &RingKeyPair{key: key, pub: key.PublicKey, image: GenKeyImage(key)}
+}
+
+// PublicMember returns the public key that represents the key pair in rings.
+func (k *RingKeyPair) PublicMember() *ecdsa.PublicKey {
+	pub := k.pub
+	return &pub
+}
+
+// KeyImage returns the key image of the key pair, which links all of its
+// signatures.
+func (k *RingKeyPair) KeyImage() KeyImage {
+	return KeyImage{k.image}
+}
+
+// SignInRing creates a ring signature over m, see Sign. The ring must contain
+// the public member of the key pair.
+func (k *RingKeyPair) SignInRing(ring Ring, m [32]byte) (*RingSign, error) {
+	if k.key == nil {
+		return nil, errKeyZeroed
+	}
+	s := ring.Index(&k.pub)
+	if s < 0 {
+		return nil, errKeyNotInRing
+	}
+	return Sign(m, ring, k.key, s)
+}
+
+// Zero overwrites the private key in memory. The key pair cannot sign
+// afterwards, its public member and key image remain available.
+func (k *RingKeyPair) Zero() {
+	if k.key == nil {
+		return
+	}
+	b := k.key.D.Bits()
+	for i := range b {
+		b[i] = 0
+	}
+	k.key.D.SetInt64(0)
+	k.key = nil
+}
@@ -0,0 +1,41 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRingKeyPair(t *testing.T) {
+	ring, _ := testRing(t, 3)
+	kp, err := GenerateRingKeyPair(crypto.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kp.SignInRing(ring, [32]byte{}); err != errKeyNotInRing {
+		t.Errorf("outside ring: got %v, want %v", err, errKeyNotInRing)
+	}
+	ring = append(ring[:1], append(Ring{kp.PublicMember()}, ring[1:]...)...)
+	sig, err := kp.SignInRing(ring, [32]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(sig) {
+		t.Error("signature does not verify")
+	}
+	if !sig.KeyImage().Equal(kp.KeyImage()) {
+		t.Error("signature has wrong key image")
+	}
+
+	key := kp.key
+	kp.Zero()
+	if key.D.Sign() != 0 {
+		t.Error("private key not zeroed")
+	}
+	if _, err := kp.SignInRing(ring, [32]byte{1}); err != errKeyZeroed {
+		t.Errorf("zeroed key: got %v, want %v", err, errKeyZeroed)
+	}
+	if !ring.Contains(kp.PublicMember()) || !sig.KeyImage().Equal(kp.KeyImage()) {
+		t.Error("public parts lost by zeroing")
+	}
+}
@@ -0,0 +1,388 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Lattice ring signatures are an experimental post-quantum scheme. They follow
+// Lyubashevsky's Fiat-Shamir with aborts in an AOS ring: all members share a
+// public matrix A over R_q = Z_q[X]/(X^256+1) and a member's public key is
+// t = A*s for a short secret vector s. For every member the signature holds a
+// short response z_i, and the challenges are chained as
+//
+//	c_{i+1} = H(m, ring, A*z_i - c_i*t_i)
+//
+// where the challenges are sparse ternary polynomials. The signer's response
+// z_s = y + c_s*s is rejection sampled so that it is distributed exactly like
+// the uniformly random responses of the other members.
+//
+// The module dimensions and modulus follow Dilithium2, but the commitments are
+// hashed in full without compression, so keys and signatures are a few
+// kilobytes per ring member. The parameters have not been analysed for this
+// construction; do not rely on the scheme for anything but experiments.
+
+const (
+	latticeN      = 256
+	latticeQ      = 8380417
+	latticeK      = 4
+	latticeL      = 4
+	latticeEta    = 2
+	latticeTau    = 39
+	latticeGamma1 = 1 << 17
+	latticeBeta   = latticeTau * latticeEta
+	latticeBound  = latticeGamma1 - latticeBeta - 1 // maximum norm of a response
+)
+
+// latticeDomain separates the hashes of lattice ring signatures from other
+// hashes in the package.
+var latticeDomain = []byte("go-ethereum/crypto/ring lattice")
+
+var errLatticeKey = errors.New("invalid lattice public key encoding")
+
+// latticePoly is an element of R_q. Coefficients of public values are kept in
+// [0, q), those of secret and response vectors are centered around zero.
+type latticePoly [latticeN]int32
+
+type latticeVecK [latticeK]latticePoly
+type latticeVecL [latticeL]latticePoly
+
+// LatticePublicKey is the public key t = A*s of a lattice ring member.
+type LatticePublicKey struct {
+	T latticeVecK
+}
+
+// LatticePrivateKey holds the short secret vector s of a lattice ring member.
+type LatticePrivateKey struct {
+	LatticePublicKey
+	s latticeVecL
+}
+
+// LatticeSign is an experimental lattice based ring signature.
+type LatticeSign struct {
+	M    [32]byte            // message
+	Ring []*LatticePublicKey // array of public keys
+	C    [32]byte            // seed of the challenge of the first ring member
+	Z    []latticeVecL       // responses, one per ring member
+}
+
+var (
+	latticeMatrixOnce sync.Once
+	latticeMatrix     [latticeK]latticeVecL
+)
+
+// latticeA returns the public matrix shared by all members, expanded from the
+// package domain with SHAKE128.
+func latticeA() *[latticeK]latticeVecL {
+	latticeMatrixOnce.Do(func() {
+		for i := range latticeMatrix {
+			for j := range latticeMatrix[i] {
+				h := sha3.NewShake128()
+				h.Write(latticeDomain)
+				h.Write([]byte{byte(i), byte(j)})
+				var buf [3]byte
+				for n := 0; n < latticeN; {
+					h.Read(buf[:])
+					v := int32(buf[0]) | int32(buf[1])<<8 | int32(buf[2]&0x7f)<<16
+					if v < latticeQ {
+						latticeMatrix[i][j][n] = v
+						n++
+					}
+				}
+			}
+		}
+	})
+	return &latticeMatrix
+}
+
+// latticeReduce maps v into [0, q).
+func latticeReduce(v int64) int32 {
+	v %= latticeQ
+	if v < 0 {
+		v += latticeQ
+	}
+	return int32(v)
+}
+
+// mul returns a*b in R_q.
+func (a *latticePoly) mul(b *latticePoly) latticePoly {
+	var lo, hi [latticeN]int64
+	for i := 0; i < latticeN; i++ {
+		ai := int64(latticeReduce(int64(a[i])))
+		if ai == 0 {
+			continue
+		}
+		for j := 0; j < latticeN; j++ {
+			p := ai * int64(latticeReduce(int64(b[j])))
+			if i+j < latticeN {
+				lo[i+j] += p
+			} else {
+				hi[i+j-latticeN] += p // X^N = -1
+			}
+		}
+	}
+	var r latticePoly
+	for i := range r {
+		r[i] = latticeReduce(lo[i]%latticeQ - hi[i]%latticeQ)
+	}
+	return r
+}
+
+// add sets a to a+b in R_q.
+func (a *latticePoly) add(b *latticePoly) {
+	for i := range a {
+		a[i] = latticeReduce(int64(a[i]) + int64(b[i]))
+	}
+}
+
+// latticeMulA returns A*v.
+func latticeMulA(v *latticeVecL) latticeVecK {
+	A := latticeA()
+	var r latticeVecK
+	for i := range r {
+		for j := range v {
+			p := A[i][j].mul(&v[j])
+			r[i].add(&p)
+		}
+	}
+	return r
+}
+
+// latticeCommitment returns w = A*z - c*t.
+func latticeCommitment(z *latticeVecL, c *latticePoly, t *latticeVecK) latticeVecK {
+	w := latticeMulA(z)
+	for i := range w {
+		ct := c.mul(&t[i])
+		for n := range ct {
+			ct[n] = latticeReduce(-int64(ct[n]))
+		}
+		w[i].add(&ct)
+	}
+	return w
+}
+
+// latticePack packs the coefficients of a vector of polynomials, reduced into
+// [0, q), as 3 byte little-endian integers.
+func latticePack(polys []latticePoly) []byte {
+	out := make([]byte, 0, len(polys)*latticeN*3)
+	for i := range polys {
+		for _, v := range polys[i] {
+			u := latticeReduce(int64(v))
+			out = append(out, byte(u), byte(u>>8), byte(u>>16))
+		}
+	}
+	return out
+}
+
+// Bytes returns the encoding of the public key.
+func (pub *LatticePublicKey) Bytes() []byte {
+	return latticePack(pub.T[:])
+}
+
+// ParseLatticePublicKey decodes a public key encoded with Bytes.
+func ParseLatticePublicKey(b []byte) (*LatticePublicKey, error) {
+	if len(b) != latticeK*latticeN*3 {
+		return nil, errLatticeKey
+	}
+	pub := new(LatticePublicKey)
+	for i := range pub.T {
+		for n := range pub.T[i] {
+			off := 3 * (i*latticeN + n)
+			v := int32(b[off]) | int32(b[off+1])<<8 | int32(b[off+2])<<16
+			if v >= latticeQ {
+				return nil, errLatticeKey
+			}
+			pub.T[i][n] = v
+		}
+	}
+	return pub, nil
+}
+
+// latticeUniform returns a uniformly random integer in [-bound, bound].
+func latticeUniform(bound int64) (int32, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(2*bound+1))
+	if err != nil {
+		return 0, err
+	}
+	return int32(v.Int64() - bound), nil
+}
+
+// latticeSample fills v with uniformly random coefficients in [-bound, bound].
+func latticeSample(v *latticeVecL, bound int64) error {
+	for i := range v {
+		for n := range v[i] {
+			c, err := latticeUniform(bound)
+			if err != nil {
+				return err
+			}
+			v[i][n] = c
+		}
+	}
+	return nil
+}
+
+// GenerateLatticeKey creates a new lattice ring signature key.
+func GenerateLatticeKey() (*LatticePrivateKey, error) {
+	key := new(LatticePrivateKey)
+	if err := latticeSample(&key.s, latticeEta); err != nil {
+		return nil, err
+	}
+	key.T = latticeMulA(&key.s)
+	return key, nil
+}
+
+// latticeChallenge expands a seed into a polynomial with latticeTau
+// coefficients of +-1 and zeroes elsewhere, as in Dilithium's SampleInBall.
+func latticeChallenge(seed [32]byte) latticePoly {
+	h := sha3.NewShake256()
+	h.Write(seed[:])
+
+	var buf [8]byte
+	io.ReadFull(h, buf[:])
+	signs := binary.LittleEndian.Uint64(buf[:])
+
+	var c latticePoly
+	var b [1]byte
+	for i := latticeN - latticeTau; i < latticeN; i++ {
+		for {
+			h.Read(b[:])
+			if int(b[0]) <= i {
+				break
+			}
+		}
+		j := int(b[0])
+		c[i] = c[j]
+		c[j] = 1
+		if signs&1 == 1 {
+			c[j] = latticeQ - 1
+		}
+		signs >>= 1
+	}
+	return c
+}
+
+// latticeDigest hashes the message and the ring for the challenge chain.
+func latticeDigest(m [32]byte, ring []*LatticePublicKey) []byte {
+	h := sha3.New256()
+	h.Write(latticeDomain)
+	h.Write(m[:])
+	for _, pub := range ring {
+		h.Write(pub.Bytes())
+	}
+	return h.Sum(nil)
+}
+
+// latticeNext computes the seed of the challenge following commitment w.
+func latticeNext(digest []byte, w *latticeVecK) [32]byte {
+	var seed [32]byte
+	h := sha3.New256()
+	h.Write(digest)
+	h.Write(latticePack(w[:]))
+	h.Sum(seed[:0])
+	return seed
+}
+
+// latticeShort reports whether all coefficients of z are within the response
+// bound.
+func latticeShort(z *latticeVecL) bool {
+	for i := range z {
+		for _, v := range z[i] {
+			if v > latticeBound || v < -latticeBound {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SignLattice creates an experimental lattice based ring signature over m.
+// key must belong to ring[s].
+func SignLattice(m [32]byte, ring []*LatticePublicKey, key *LatticePrivateKey, s int) (*LatticeSign, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	for _, pub := range ring {
+		if pub == nil {
+			return nil, errLatticeKey
+		}
+	}
+	if !bytes.Equal(ring[s].Bytes(), key.Bytes()) {
+		return nil, errNotSigner
+	}
+	digest := latticeDigest(m, ring)
+	n := len(ring)
+
+	for {
+		seeds := make([][32]byte, n)
+		Z := make([]latticeVecL, n)
+
+		var y latticeVecL
+		if err := latticeSample(&y, latticeGamma1-1); err != nil {
+			return nil, err
+		}
+		w := latticeMulA(&y)
+		seeds[(s+1)%n] = latticeNext(digest, &w)
+
+		for j := 1; j < n; j++ {
+			i := (s + j) % n
+			if err := latticeSample(&Z[i], latticeBound); err != nil {
+				return nil, err
+			}
+			c := latticeChallenge(seeds[i])
+			w := latticeCommitment(&Z[i], &c, &ring[i].T)
+			seeds[(i+1)%n] = latticeNext(digest, &w)
+		}
+		// z_s = y + c_s*s, restarting unless it is short enough to hide s
+		c := latticeChallenge(seeds[s])
+		for i := range Z[s] {
+			cs := c.mul(&key.s[i])
+			for k := range cs {
+				v := int64(cs[k])
+				if v > latticeQ/2 {
+					v -= latticeQ
+				}
+				Z[s][i][k] = y[i][k] + int32(v)
+			}
+		}
+		if !latticeShort(&Z[s]) {
+			continue
+		}
+		return &LatticeSign{
+			M:    m,
+			Ring: append([]*LatticePublicKey{}, ring...),
+			C:    seeds[0],
+			Z:    Z,
+		}, nil
+	}
+}
+
+// VerifyLattice verifies an experimental lattice based ring signature.
+// returns true if a valid signature, false otherwise
+func VerifyLattice(sig *LatticeSign) bool {
+	if sig == nil || len(sig.Ring) < 2 || len(sig.Z) != len(sig.Ring) {
+		return false
+	}
+	for i, pub := range sig.Ring {
+		if pub == nil || !latticeShort(&sig.Z[i]) {
+			return false
+		}
+	}
+	digest := latticeDigest(sig.M, sig.Ring)
+	seed := sig.C
+	for i, pub := range sig.Ring {
+		c := latticeChallenge(seed)
+		w := latticeCommitment(&sig.Z[i], &c, &pub.T)
+		seed = latticeNext(digest, &w)
+	}
+	return seed == sig.C
+}
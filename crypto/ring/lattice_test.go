@@ -0,0 +1,59 @@
+package ring
+
+import "testing"
+
+func TestLattice(t *testing.T) {
+	ring := make([]*LatticePublicKey, 3)
+	var key *LatticePrivateKey
+	for i := range ring {
+		k, err := GenerateLatticeKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring[i] = &k.LatticePublicKey
+		if i == 1 {
+			key = k
+		}
+	}
+	sig, err := SignLattice([32]byte{0x9}, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyLattice(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	sig.M[0]++
+	if VerifyLattice(sig) {
+		t.Error("signature over different message accepted")
+	}
+	sig.M[0]--
+	sig.Z[2][0][0] = latticeBound + 1
+	if VerifyLattice(sig) {
+		t.Error("signature with long response accepted")
+	}
+	if _, err := SignLattice([32]byte{}, ring, key, 0); err != errNotSigner {
+		t.Errorf("wrong signer index: have %v, want %v", err, errNotSigner)
+	}
+	pub, err := ParseLatticePublicKey(ring[0].Bytes())
+	if err != nil || pub.T != ring[0].T {
+		t.Errorf("public key round trip failed: %v", err)
+	}
+}
+
+func TestSchemeRegistry(t *testing.T) {
+	scheme, err := SchemeByName("lattice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !scheme.PostQuantum || !scheme.Experimental {
+		t.Errorf("lattice scheme flags wrong: %+v", scheme)
+	}
+	for _, s := range Schemes(false) {
+		if s.Experimental {
+			t.Errorf("experimental scheme %s listed", s.Name)
+		}
+	}
+	if err := RegisterScheme(Scheme{Name: "lsag"}); err != errSchemeNameTaken {
+		t.Errorf("duplicate name: have %v, want %v", err, errSchemeNameTaken)
+	}
+}
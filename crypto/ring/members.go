@@ -0,0 +1,83 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+)
+
+// Decoys are usually picked from keys supplied by others, a chain or a
+// wallet's peers, and a malicious one can sabotage the signature: a member off
+// the curve or at infinity makes the ring unsignable or the signature
+// unverifiable on other implementations, and a duplicate shrinks the
+// anonymity set without changing the ring size. NewRing and Ring.Validate
+// reject such members and report their index, and signing validates its ring.
+//
+// Signature decoders do not validate rings, which would make them stricter
+// than Verify; VerifyStrict rejects signatures over invalid points.
+
+var (
+	errNilMember       = errors.New("nil ring member")
+	errIdentityMember  = errors.New("ring member is the point at infinity")
+	errOffCurveMember  = errors.New("ring member not on curve")
+	errCurveMembers    = errors.New("ring members on different curves")
+	errDuplicateMember = errors.New("duplicate ring member")
+)
+
+// MemberError is returned when a ring contains an invalid member.
+type MemberError struct {
+	Index int   // position of the member in the ring
+	Err   error // reason the member was rejected
+}
+
+func (err *MemberError) Error() string {
+	return fmt.Sprintf("ring member %d: %v", err.Index, err.Err)
+}
+
+// NewRing creates a ring of the given keys, which must be distinct points on
+// a single curve other than the point at infinity. An invalid member is
+// reported as a *MemberError.
+func NewRing(keys ...*ecdsa.PublicKey) (Ring, error) {
+	if len(keys) < 2 {
+		return nil, errRingTooSmall
+	}
+	ring := append(Ring{}, keys...)
+	if err := ring.Validate(); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// Validate returns a *MemberError for the first member of the ring that is
+// nil, the point at infinity, not a reduced point on the curve, on a different
+// curve than the first member or equal to an earlier member.
+func (r Ring) Validate() error {
+	seen := make(map[string]bool, len(r))
+	for i, pub := range r {
+		if err := checkMember(r[0], pub); err != nil {
+			return &MemberError{Index: i, Err: err}
+		}
+		key := string(pointBytes(pub))
+		if seen[key] {
+			return &MemberError{Index: i, Err: errDuplicateMember}
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// checkMember returns an error if pub is not a valid member of a ring whose
+// first member is first.
+func checkMember(first, pub *ecdsa.PublicKey) error {
+	switch {
+	case pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil:
+		return errNilMember
+	case first.Curve == nil || pub.Curve != first.Curve:
+		return errCurveMembers
+	case newPoint(pub.Curve, pub.X, pub.Y) == nil:
+		return errIdentityMember
+	case !canonicalPoint(pub.Curve, pub.X, pub.Y):
+		return errOffCurveMember
+	}
+	return nil
+}
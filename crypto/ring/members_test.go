@@ -0,0 +1,56 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNewRing(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	if _, err := NewRing(ring...); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	pub := keys[0].PublicKey
+	tests := []struct {
+		name   string
+		member *ecdsa.PublicKey
+		err    error
+	}{
+		{"nil", nil, errNilMember},
+		{"identity", &ecdsa.PublicKey{Curve: crypto.S256(), X: new(big.Int), Y: new(big.Int)}, errIdentityMember},
+		{"off curve", &ecdsa.PublicKey{Curve: crypto.S256(), X: pub.X, Y: new(big.Int).Add(pub.Y, big.NewInt(1))}, errOffCurveMember},
+		{"unreduced", &ecdsa.PublicKey{Curve: crypto.S256(), X: new(big.Int).Add(pub.X, crypto.S256().Params().P), Y: pub.Y}, errOffCurveMember},
+		{"other curve", &other.PublicKey, errCurveMembers},
+		{"duplicate", &pub, errDuplicateMember},
+	}
+	for _, tt := range tests {
+		_, err := NewRing(ring[0], ring[1], tt.member, ring[2])
+		merr, ok := err.(*MemberError)
+		if !ok || merr.Index != 2 || merr.Err != tt.err {
+			t.Errorf("%s: got %v, want member 2: %v", tt.name, err, tt.err)
+		}
+	}
+	if _, err := NewRing(ring[0]); err != errRingTooSmall {
+		t.Errorf("small ring: got %v, want %v", err, errRingTooSmall)
+	}
+}
+
+func TestSignInvalidRing(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	ring[2] = ring[0]
+	for _, ct := range []bool{false, true} {
+		_, err := SignWithOpts([32]byte{}, ring, keys[1], 1, &SignOpts{ConstantTime: ct})
+		if merr, ok := err.(*MemberError); !ok || merr.Index != 2 || merr.Err != errDuplicateMember {
+			t.Errorf("constant time %v: got %v, want duplicate member 2", ct, err)
+		}
+	}
+	if _, err := NewSession(ring, keys[1]); err == nil {
+		t.Error("session created over ring with duplicate member")
+	}
+}
@@ -0,0 +1,204 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Large anonymity sets are committed to as the root of a Merkle tree over the
+// member keys, so verifiers keep 32 bytes instead of every key. A signature
+// picks a ring of members from the tree, proves with a Merkle path that each
+// of them is a leaf of the root and signs with LSAG over that ring, binding
+// the message to the root.
+//
+// The signer is hidden among the members of the ring it picked, not among all
+// leaves: a verifier learns which leaves were used. Rings are sorted by leaf
+// position so their order does not reveal the signer. Hiding the signer among
+// the whole tree takes a zero-knowledge proof of the Merkle path, such as the
+// SNARK relation of SNARKSign extended by the path.
+//
+// The key image does not depend on the tree, so signatures against different
+// roots remain linkable.
+
+// merkleDomain separates the hashes of Merkle-committed rings from other
+// hashes in the package.
+var merkleDomain = []byte("go-ethereum/crypto/ring merkle")
+
+var (
+	errLeafIndex  = errors.New("leaf index out of range of tree")
+	errMerkleRing = errors.New("ring size exceeds number of leaves")
+)
+
+// MerkleRing is a set of ring members committed to by a Merkle root.
+type MerkleRing struct {
+	members Ring
+	levels  [][][32]byte
+}
+
+// merkleLeaf hashes a member key into a Merkle leaf.
+func merkleLeaf(pub *ecdsa.PublicKey) [32]byte {
+	data := append([]byte{0}, merkleDomain...)
+	return sha3.Sum256(append(data, pointBytes(pub)...))
+}
+
+// merkleNode hashes two Merkle children into their parent.
+func merkleNode(left, right [32]byte) [32]byte {
+	data := append([]byte{1}, merkleDomain...)
+	data = append(data, left[:]...)
+	return sha3.Sum256(append(data, right[:]...))
+}
+
+// NewMerkleRing builds the tree over members, which must form a valid ring,
+// see Ring.Validate. The leaf level is padded with zero hashes to a power of
+// two.
+func NewMerkleRing(members Ring) (*MerkleRing, error) {
+	if len(members) < 2 {
+		return nil, errRingTooSmall
+	}
+	if err := members.Validate(); err != nil {
+		return nil, err
+	}
+	size := 1
+	for size < len(members) {
+		size *= 2
+	}
+	level := make([][32]byte, size)
+	parallel(len(members), func(i int) {
+		level[i] = merkleLeaf(members[i])
+	})
+	levels := [][][32]byte{level}
+	for len(level) > 1 {
+		next := make([][32]byte, len(level)/2)
+		for i := range next {
+			next[i] = merkleNode(level[2*i], level[2*i+1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return &MerkleRing{members: append(Ring{}, members...), levels: levels}, nil
+}
+
+// Root returns the Merkle root committing to the members.
+func (t *MerkleRing) Root() [32]byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Len returns the number of members.
+func (t *MerkleRing) Len() int {
+	return len(t.members)
+}
+
+// Path returns the Merkle path of the member at leaf index i.
+func (t *MerkleRing) Path(i uint64) ([][32]byte, error) {
+	if i >= uint64(len(t.members)) {
+		return nil, errLeafIndex
+	}
+	path := make([][32]byte, 0, len(t.levels)-1)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		path = append(path, level[i^1])
+		i /= 2
+	}
+	return path, nil
+}
+
+// VerifyMerklePath reports whether pub is the leaf at index of the tree with
+// the given root.
+func VerifyMerklePath(root [32]byte, pub *ecdsa.PublicKey, index uint64, path [][32]byte) bool {
+	if pub == nil || len(path) >= 64 || index>>uint(len(path)) != 0 {
+		return false
+	}
+	node := merkleLeaf(pub)
+	for i, sibling := range path {
+		if (index>>uint(i))&1 == 0 {
+			node = merkleNode(node, sibling)
+		} else {
+			node = merkleNode(sibling, node)
+		}
+	}
+	return node == root
+}
+
+// MerkleSign is a ring signature over M by one of the leaves of Root.
+type MerkleSign struct {
+	M       [32]byte     // message
+	Root    [32]byte     // root of the member tree
+	Indices []uint64     // leaf indices of the ring members, ascending
+	Paths   [][][32]byte // Merkle paths of the ring members
+	Sig     *RingSign    // signature over the ring, see merkleMessage
+}
+
+// merkleMessage binds m to the root of the member tree.
+func merkleMessage(root, m [32]byte) [32]byte {
+	data := append(append(append([]byte{}, merkleDomain...), root[:]...), m[:]...)
+	return sha3.Sum256(data)
+}
+
+// Sign creates a signature over m with privkey, whose public key must be a
+// member, hidden in a ring of size members of the tree picked at random.
+func (t *MerkleRing) Sign(m [32]byte, privkey *ecdsa.PrivateKey, size int) (*MerkleSign, error) {
+	if size < 2 {
+		return nil, errRingTooSmall
+	}
+	if size > len(t.members) {
+		return nil, errMerkleRing
+	}
+	signer := t.members.Index(&privkey.PublicKey)
+	if signer < 0 {
+		return nil, errKeyNotInRing
+	}
+	picked := map[uint64]bool{uint64(signer): true}
+	for len(picked) < size {
+		i, err := rand.Int(rand.Reader, big.NewInt(int64(len(t.members))))
+		if err != nil {
+			return nil, err
+		}
+		picked[i.Uint64()] = true
+	}
+	indices := make([]uint64, 0, size)
+	for i := range picked {
+		indices = append(indices, i)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	sig := &MerkleSign{M: m, Root: t.Root(), Indices: indices, Paths: make([][][32]byte, size)}
+	ring := make(Ring, size)
+	s := 0
+	for j, i := range indices {
+		ring[j] = t.members[i]
+		sig.Paths[j], _ = t.Path(i)
+		if i == uint64(signer) {
+			s = j
+		}
+	}
+	var err error
+	if sig.Sig, err = Sign(merkleMessage(sig.Root, m), ring, privkey, s); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// VerifyMerkle verifies a signature by a member of the tree with the given
+// root. It returns true if a valid signature, false otherwise.
+func VerifyMerkle(root [32]byte, sig *MerkleSign) bool {
+	if sig == nil || sig.Sig == nil || sig.Root != root || sig.Sig.M != merkleMessage(root, sig.M) {
+		return false
+	}
+	ring := sig.Sig.Ring
+	if len(sig.Indices) != len(ring) || len(sig.Paths) != len(ring) {
+		return false
+	}
+	for j, pub := range ring {
+		if j > 0 && sig.Indices[j] <= sig.Indices[j-1] {
+			return false
+		}
+		if !VerifyMerklePath(root, pub, sig.Indices[j], sig.Paths[j]) {
+			return false
+		}
+	}
+	return Verify(sig.Sig)
+}
@@ -0,0 +1,83 @@
+package ring
+
+import "testing"
+
+func TestMerkleRing(t *testing.T) {
+	ring, keys := testRing(t, 5)
+	tree, err := NewMerkleRing(ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := tree.Root()
+	for i, pub := range ring {
+		path, err := tree.Path(uint64(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(path) != 3 {
+			t.Fatalf("member %d: path has %d nodes, want 3", i, len(path))
+		}
+		if !VerifyMerklePath(root, pub, uint64(i), path) {
+			t.Errorf("member %d: path does not verify", i)
+		}
+		if VerifyMerklePath(root, pub, uint64(i^1), path) {
+			t.Errorf("member %d: path verifies at wrong index", i)
+		}
+	}
+	if _, err := tree.Path(5); err != errLeafIndex {
+		t.Errorf("out of range: got %v, want %v", err, errLeafIndex)
+	}
+	if _, err := NewMerkleRing(append(ring, ring[0])); err == nil {
+		t.Error("tree built with duplicate member")
+	}
+
+	sig, err := tree.Sign([32]byte{1}, keys[3], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyMerkle(root, sig) {
+		t.Fatal("signature does not verify")
+	}
+	if len(sig.Sig.Ring) != 3 || !sig.Sig.Ring.Contains(ring[3]) {
+		t.Error("signer missing from ring")
+	}
+	if VerifyMerkle([32]byte{}, sig) {
+		t.Error("signature verifies against other root")
+	}
+	sig.M[0] ^= 1
+	if VerifyMerkle(root, sig) {
+		t.Error("signature verifies for other message")
+	}
+	sig.M[0] ^= 1
+	sig.Indices[0], sig.Indices[1] = sig.Indices[1], sig.Indices[0]
+	if VerifyMerkle(root, sig) {
+		t.Error("signature verifies with unsorted indices")
+	}
+	sig.Indices[0], sig.Indices[1] = sig.Indices[1], sig.Indices[0]
+
+	other, _ := testRing(t, 4)
+	outside, err := NewMerkleRing(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := outside.Sign([32]byte{1}, keys[3], 2); err != errKeyNotInRing {
+		t.Errorf("signer outside tree: got %v, want %v", err, errKeyNotInRing)
+	}
+	// Swapping a ring member for a key outside the tree breaks its path.
+	forged := *sig
+	sub := append(Ring{}, sig.Sig.Ring...)
+	j := 0
+	if PublicKeyEqual(sub[0], ring[3]) {
+		j = 1
+	}
+	sub[j] = other[0]
+	if forged.Sig, err = Sign(merkleMessage(root, sig.M), sub, keys[3], sub.Index(ring[3])); err != nil {
+		t.Fatal(err)
+	}
+	if VerifyMerkle(root, &forged) {
+		t.Error("signature verifies with member outside tree")
+	}
+	if _, err := tree.Sign([32]byte{}, keys[0], 6); err != errMerkleRing {
+		t.Errorf("oversized ring: got %v, want %v", err, errMerkleRing)
+	}
+}
@@ -0,0 +1,23 @@
+package ring
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Metrics of the signing and verification of linkable ring signatures, for
+// operators to monitor the cost of the ring features. Like all metrics they
+// are no-ops unless enabled with --metrics before the package is loaded.
+var (
+	signTimer        = metrics.NewRegisteredTimer("crypto/ring/sign", nil)
+	signFailMeter    = metrics.NewRegisteredMeter("crypto/ring/sign/fail", nil)
+	signSizeHist     = metrics.NewRegisteredHistogram("crypto/ring/sign/ringsize", nil, metrics.NewExpDecaySample(1028, 0.015))
+	verifyTimer      = metrics.NewRegisteredTimer("crypto/ring/verify", nil)
+	verifyFailMeter  = metrics.NewRegisteredMeter("crypto/ring/verify/fail", nil)
+	verifySizeHist   = metrics.NewRegisteredHistogram("crypto/ring/verify/ringsize", nil, metrics.NewExpDecaySample(1028, 0.015))
+	batchTimer       = metrics.NewRegisteredTimer("crypto/ring/batch", nil)
+	batchSigsMeter   = metrics.NewRegisteredMeter("crypto/ring/batch/sigs", nil)
+	cacheHitMeter    = metrics.NewRegisteredMeter("crypto/ring/verifycache/hit", nil)
+	cacheMissMeter   = metrics.NewRegisteredMeter("crypto/ring/verifycache/miss", nil)
+	precompHitMeter  = metrics.NewRegisteredMeter("crypto/ring/precompute/hit", nil)
+	precompMissMeter = metrics.NewRegisteredMeter("crypto/ring/precompute/miss", nil)
+)
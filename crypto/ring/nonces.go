@@ -0,0 +1,79 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// A ring signature takes fresh random values for the glue value u and the
+// responses of all decoys. If they repeat across two signatures of different
+// messages, or are predictable because the RNG is broken, the private key can
+// be solved for. Hedged signing derives them as
+//
+//	SHAKE256(domain || x || m || ring fingerprint || 32 random bytes)
+//
+// so that a broken RNG still yields values unique to key, message and ring,
+// while signing the same message twice yields unrelated signatures, which
+// keeps fault attacks on deterministic signers at bay.
+
+// nonceDomain separates the nonce derivation from other uses of SHAKE256.
+const nonceDomain = "ring signature nonces v1"
+
+// NonceMode selects how the random values of a signature are obtained.
+type NonceMode int
+
+const (
+	// HedgedNonces derives the values from the key, message, ring and fresh
+	// randomness. It is the default.
+	HedgedNonces NonceMode = iota
+
+	// RandomNonces draws the values from crypto/rand alone.
+	RandomNonces
+
+	// DeterministicNonces derives the values from the key, message and ring
+	// alone, so signing is reproducible. Meant for tests and test vectors.
+	DeterministicNonces
+)
+
+// nonceReader returns the stream the random values of a signature are read
+// from.
+func nonceReader(mode NonceMode, privkey *ecdsa.PrivateKey, m [32]byte, ring Ring) (io.Reader, error) {
+	if mode == RandomNonces {
+		return rand.Reader, nil
+	}
+	xof := sha3.NewShake256()
+	xof.Write([]byte(nonceDomain))
+	xof.Write(math.PaddedBigBytes(privkey.D, scalarSize(privkey.Curve)))
+	xof.Write(m[:])
+	fp := RingFingerprint(ring)
+	xof.Write(fp[:])
+	if mode == HedgedNonces {
+		entropy := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, entropy); err != nil {
+			return nil, err
+		}
+		xof.Write(entropy)
+	}
+	return xof, nil
+}
+
+// nonceSize returns the number of bytes read per scalar. The 128 extra bits
+// make the bias of the reduction modulo the group order negligible.
+func nonceSize(privkey *ecdsa.PrivateKey) int {
+	return scalarSize(privkey.Curve) + 16
+}
+
+// readNonce reads a scalar modulo the group order of privkey from r.
+func readNonce(r io.Reader, privkey *ecdsa.PrivateKey) (*big.Int, error) {
+	b := make([]byte, nonceSize(privkey))
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	k := new(big.Int).SetBytes(b)
+	return k.Mod(k, privkey.Curve.Params().N), nil
+}
@@ -0,0 +1,78 @@
+package ring
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+// zeroReader is a broken RNG.
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
+
+func sameSignature(a, b *RingSign) bool {
+	if a.C.Cmp(b.C) != 0 {
+		return false
+	}
+	for i := range a.S {
+		if a.S[i].Cmp(b.S[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNonceModes(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	for _, ct := range []bool{false, true} {
+		sign := func(m byte, mode NonceMode) *RingSign {
+			sig, err := SignWithOpts([32]byte{m}, ring, keys[1], 1, &SignOpts{ConstantTime: ct, Nonces: mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Verify(sig) {
+				t.Fatalf("constant time %v, mode %d: signature does not verify", ct, mode)
+			}
+			return sig
+		}
+		if !sameSignature(sign(1, DeterministicNonces), sign(1, DeterministicNonces)) {
+			t.Errorf("constant time %v: deterministic signatures differ", ct)
+		}
+		if sameSignature(sign(1, DeterministicNonces), sign(2, DeterministicNonces)) {
+			t.Errorf("constant time %v: deterministic signatures of different messages are equal", ct)
+		}
+		for _, mode := range []NonceMode{HedgedNonces, RandomNonces} {
+			if sameSignature(sign(1, mode), sign(1, mode)) {
+				t.Errorf("constant time %v, mode %d: repeated signatures are equal", ct, mode)
+			}
+		}
+	}
+}
+
+func TestHedgedNoncesBrokenRNG(t *testing.T) {
+	ring, keys := testRing(t, 3)
+
+	defer func(r interface{ Read([]byte) (int, error) }) { rand.Reader = r }(rand.Reader)
+	rand.Reader = zeroReader{}
+
+	a, err := Sign([32]byte{1}, ring, keys[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Sign([32]byte{2}, ring, keys[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the decoy responses would repeat if they were drawn from the RNG alone
+	if a.S[1].Cmp(b.S[1]) == 0 || a.S[2].Cmp(b.S[2]) == 0 {
+		t.Error("responses repeat across messages with a broken RNG")
+	}
+	if !Verify(a) || !Verify(b) {
+		t.Error("signature does not verify")
+	}
+}
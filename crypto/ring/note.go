@@ -0,0 +1,94 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// Output notes carry the opening of an output's amount commitment and an
+// optional payment ID to the recipient. They are ECIES encrypted to the
+// recipient's view key and bound to the one-time key of the output they are
+// attached to, so a note cannot be moved to another output. Unlike the
+// derived amounts of stealth outputs, notes also work for commitments whose
+// blinding factor was chosen freely, such as the outputs of BuildRingCT.
+
+var errInvalidNote = errors.New("invalid output note")
+
+// Note is the plaintext of an output note.
+type Note struct {
+	Amount    uint64   // committed amount
+	Mask      *big.Int // blinding factor of the commitment
+	PaymentID [8]byte  // optional identifier chosen by the recipient
+}
+
+// noteScalarSize returns the width of an encoded scalar on curve.
+func noteScalarSize(pub *ecdsa.PublicKey) int {
+	return (pub.Curve.Params().N.BitLen() + 7) / 8
+}
+
+// EncryptNote encrypts note to the view key of the recipient of the output
+// with one-time key output. The curve must be supported by ECIES.
+func EncryptNote(view, output *ecdsa.PublicKey, note *Note) ([]byte, error) {
+	if note.Mask == nil || output == nil {
+		return nil, errInvalidNote
+	}
+	plain := make([]byte, 8, 16+noteScalarSize(view))
+	binary.BigEndian.PutUint64(plain, note.Amount)
+	plain = append(plain, math.PaddedBigBytes(note.Mask, noteScalarSize(view))...)
+	plain = append(plain, note.PaymentID[:]...)
+
+	return ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(view), plain, nil, pointBytes(output))
+}
+
+// NoteSize returns the size of the notes encrypted to view keys on curve, or
+// zero if the curve is not supported by ECIES.
+func NoteSize(curve elliptic.Curve) int {
+	params := ecies.ParamsFromCurve(curve)
+	if params == nil {
+		return 0
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	plain := 16 + (curve.Params().N.BitLen()+7)/8
+	return 1 + 2*size + params.BlockSize + plain + params.Hash().Size()
+}
+
+// DecryptNote decrypts a note attached to the output with one-time key output.
+func DecryptNote(view *ecdsa.PrivateKey, output *ecdsa.PublicKey, ciphertext []byte) (*Note, error) {
+	if output == nil {
+		return nil, errInvalidNote
+	}
+	plain, err := ecies.ImportECDSA(view).Decrypt(ciphertext, nil, pointBytes(output))
+	if err != nil {
+		return nil, err
+	}
+	size := noteScalarSize(&view.PublicKey)
+	if len(plain) != 16+size {
+		return nil, errInvalidNote
+	}
+	note := &Note{
+		Amount: binary.BigEndian.Uint64(plain[:8]),
+		Mask:   new(big.Int).SetBytes(plain[8 : 8+size]),
+	}
+	copy(note.PaymentID[:], plain[8+size:])
+	return note, nil
+}
+
+// ReadNote decrypts the note of an output and checks that it opens the
+// output's commitment.
+func (k *WalletKeys) ReadNote(out *StealthOutput, ciphertext []byte) (*Note, error) {
+	note, err := DecryptNote(k.View, out.Key, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if !pointEqual(out.Commitment, Commit(k.View.Curve, new(big.Int).SetUint64(note.Amount), note.Mask)) {
+		return nil, errInvalidNote
+	}
+	return note, nil
+}
@@ -0,0 +1,47 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNote(t *testing.T) {
+	curve := crypto.S256()
+	wallet, err := GenerateWalletKeys(curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txKey, _ := crypto.GenerateKey()
+	key := DeriveStealthKey(wallet.Address(), txKey, 0)
+
+	mask, _ := randomScalar(curve)
+	out := &StealthOutput{Key: key, Commitment: Commit(curve, big.NewInt(777), mask)}
+	note := &Note{Amount: 777, Mask: mask, PaymentID: [8]byte{0xde, 0xad}}
+
+	ct, err := EncryptNote(wallet.Address().View, out.Key, note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ct) != NoteSize(curve) {
+		t.Errorf("note size: have %d, want %d", len(ct), NoteSize(curve))
+	}
+	have, err := wallet.WatchOnlyKeys().ReadNote(out, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have.Amount != 777 || have.Mask.Cmp(mask) != 0 || have.PaymentID != note.PaymentID {
+		t.Errorf("note mismatch: have %+v, want %+v", have, note)
+	}
+	// the note is bound to its output
+	other := &StealthOutput{Key: DeriveStealthKey(wallet.Address(), txKey, 1), Commitment: out.Commitment}
+	if _, err := wallet.ReadNote(other, ct); err == nil {
+		t.Error("note accepted for different output")
+	}
+	// a note that does not open the commitment is rejected
+	ct, _ = EncryptNote(wallet.Address().View, out.Key, &Note{Amount: 778, Mask: mask})
+	if _, err := wallet.ReadNote(out, ct); err != errInvalidNote {
+		t.Errorf("wrong amount: have %v, want %v", err, errInvalidNote)
+	}
+}
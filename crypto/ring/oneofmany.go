@@ -0,0 +1,288 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+// The one-out-of-many proof of Groth and Kohlweiss ("One-out-of-Many Proofs:
+// Or How to Leak a Secret and Spend a Coin", 2015) shows that one of N Pedersen
+// commitments opens to zero without revealing which one. The proof consists of
+// 4*log2(N) points and 3*log2(N)+1 scalars, so unlike RingSign its size grows
+// logarithmically with the ring.
+//
+// Since a public key P = x*G is a commitment to zero with blinding factor x,
+// proving that one ring member opens to zero is the same as proving knowledge
+// of one of the private keys, which turns the proof into a ring signature.
+
+// oneOfManyDomain separates the Fiat-Shamir challenges of this proof from
+// other hashes in the package.
+var oneOfManyDomain = []byte("go-ethereum/crypto/ring one-of-many")
+
+var errRingTooSmall = errors.New("size of ring less than two")
+
+// OneOfManyProof is a non-interactive proof that one commitment in a set opens
+// to zero. All slices have one entry per bit of the padded set size.
+type OneOfManyProof struct {
+	CL []*ecdsa.PublicKey // commitments to the bits of the secret index
+	CA []*ecdsa.PublicKey // commitments to the bit masks
+	CB []*ecdsa.PublicKey // commitments to bit*mask
+	CD []*ecdsa.PublicKey // commitments to the polynomial coefficients
+	F  []*big.Int         // masked bits
+	ZA []*big.Int         // opening of CL^x * CA
+	ZB []*big.Int         // opening of CL^(x-f) * CB
+	ZD *big.Int           // opening of the aggregated set
+}
+
+// OneOfManySign is a logarithmic-size ring signature built on the
+// Groth-Kohlweiss one-out-of-many proof.
+type OneOfManySign struct {
+	M     [32]byte // message
+	Ring  Ring     // array of public keys
+	Proof *OneOfManyProof
+	Curve elliptic.Curve
+}
+
+// ringBits returns the number of bits needed to index a ring of size n.
+func ringBits(n int) int {
+	m := 1
+	for 1<<uint(m) < n {
+		m++
+	}
+	return m
+}
+
+// padRing extends ring to 2^m members by repeating its last member. The padding
+// is deterministic so prover and verifier arrive at the same set.
+func padRing(ring Ring, m int) Ring {
+	padded := make(Ring, 1<<uint(m))
+	copy(padded, ring)
+	for i := len(ring); i < len(padded); i++ {
+		padded[i] = ring[len(ring)-1]
+	}
+	return padded
+}
+
+// polyMulLinear multiplies the polynomial p, given by its coefficients in
+// increasing order, with (c1*x + c0) modulo n.
+func polyMulLinear(p []*big.Int, c1, c0, n *big.Int) []*big.Int {
+	out := make([]*big.Int, len(p)+1)
+	for i := range out {
+		out[i] = new(big.Int)
+	}
+	for i, coeff := range p {
+		out[i].Add(out[i], new(big.Int).Mul(coeff, c0))
+		out[i+1].Add(out[i+1], new(big.Int).Mul(coeff, c1))
+	}
+	for i := range out {
+		out[i].Mod(out[i], n)
+	}
+	return out
+}
+
+// oneOfManyChallenge computes the Fiat-Shamir challenge of a proof.
+func oneOfManyChallenge(curve elliptic.Curve, msg []byte, set Ring, proof *OneOfManyProof) *big.Int {
+	data := [][]byte{oneOfManyDomain, msg}
+	for _, c := range set {
+		data = append(data, pointBytes(c))
+	}
+	for _, points := range [][]*ecdsa.PublicKey{proof.CL, proof.CA, proof.CB, proof.CD} {
+		for _, p := range points {
+			data = append(data, pointBytes(p))
+		}
+	}
+	return hashToScalar(curve, data...)
+}
+
+// ProveOneOfMany proves that set[l] is a commitment to zero with blinding
+// factor r, i.e. set[l] = r*G. The proof is bound to msg.
+func ProveOneOfMany(msg []byte, set Ring, l int, r *big.Int) (*OneOfManyProof, error) {
+	if len(set) < 2 {
+		return nil, errRingTooSmall
+	}
+	if l < 0 || l >= len(set) {
+		return nil, errIndexOutOfRange
+	}
+	curve := set[0].Curve
+	N := curve.Params().N
+	if !pointEqual(set[l], baseMul(curve, r)) {
+		return nil, errNotSigner
+	}
+	m := ringBits(len(set))
+	set = padRing(set, m)
+
+	proof := &OneOfManyProof{
+		CL: make([]*ecdsa.PublicKey, m),
+		CA: make([]*ecdsa.PublicKey, m),
+		CB: make([]*ecdsa.PublicKey, m),
+		CD: make([]*ecdsa.PublicKey, m),
+		F:  make([]*big.Int, m),
+		ZA: make([]*big.Int, m),
+		ZB: make([]*big.Int, m),
+	}
+	bits := make([]*big.Int, m)
+	rs := make([][5]*big.Int, m) // r_j, a_j, s_j, t_j, rho_j
+	for j := 0; j < m; j++ {
+		bits[j] = big.NewInt(int64((l >> uint(j)) & 1))
+		for k := range rs[j] {
+			var err error
+			if rs[j][k], err = randomScalar(curve); err != nil {
+				return nil, err
+			}
+		}
+		rj, aj, sj, tj := rs[j][0], rs[j][1], rs[j][2], rs[j][3]
+
+		proof.CL[j] = Commit(curve, bits[j], rj)
+		proof.CA[j] = Commit(curve, aj, sj)
+		proof.CB[j] = Commit(curve, new(big.Int).Mul(bits[j], aj), tj)
+	}
+
+	// p_i(x) = prod_j f_{j,i_j}(x) where f_{j,1}(x) = l_j*x + a_j and
+	// f_{j,0}(x) = (1-l_j)*x - a_j. Only p_l has degree m, the lower
+	// coefficients are hidden in the CD commitments.
+	one := big.NewInt(1)
+	for i, c := range set {
+		p := []*big.Int{one}
+		for j := 0; j < m; j++ {
+			aj := rs[j][1]
+			if (i>>uint(j))&1 == 1 {
+				p = polyMulLinear(p, bits[j], aj, N)
+			} else {
+				p = polyMulLinear(p, new(big.Int).Sub(one, bits[j]), new(big.Int).Neg(aj), N)
+			}
+		}
+		for k := 0; k < m; k++ {
+			proof.CD[k] = pointAdd(proof.CD[k], pointMul(c, p[k]))
+		}
+	}
+	for k := 0; k < m; k++ {
+		proof.CD[k] = pointAdd(proof.CD[k], baseMul(curve, rs[k][4]))
+	}
+
+	x := oneOfManyChallenge(curve, msg, set, proof)
+
+	// z_d = r*x^m - sum_k rho_k*x^k
+	zd := new(big.Int)
+	xk := big.NewInt(1)
+	for j := 0; j < m; j++ {
+		rj, aj, sj, tj, rho := rs[j][0], rs[j][1], rs[j][2], rs[j][3], rs[j][4]
+
+		f := new(big.Int).Mul(bits[j], x)
+		f.Add(f, aj).Mod(f, N)
+		proof.F[j] = f
+
+		za := new(big.Int).Mul(rj, x)
+		proof.ZA[j] = za.Add(za, sj).Mod(za, N)
+
+		zb := new(big.Int).Sub(x, f)
+		zb.Mul(zb, rj)
+		proof.ZB[j] = zb.Add(zb, tj).Mod(zb, N)
+
+		zd.Sub(zd, new(big.Int).Mul(rho, xk))
+		xk.Mul(xk, x).Mod(xk, N)
+	}
+	zd.Add(zd, new(big.Int).Mul(r, xk))
+	proof.ZD = zd.Mod(zd, N)
+
+	return proof, nil
+}
+
+// VerifyOneOfManyProof verifies that proof shows one member of set to be a
+// commitment to zero.
+func VerifyOneOfManyProof(msg []byte, set Ring, proof *OneOfManyProof) bool {
+	if len(set) < 2 || proof == nil || proof.ZD == nil {
+		return false
+	}
+	for _, c := range set {
+		if c == nil {
+			return false
+		}
+	}
+	m := ringBits(len(set))
+	if len(proof.CL) != m || len(proof.CA) != m || len(proof.CB) != m || len(proof.CD) != m ||
+		len(proof.F) != m || len(proof.ZA) != m || len(proof.ZB) != m {
+		return false
+	}
+	for j := 0; j < m; j++ {
+		if proof.CL[j] == nil || proof.CA[j] == nil || proof.CB[j] == nil || proof.CD[j] == nil ||
+			proof.F[j] == nil || proof.ZA[j] == nil || proof.ZB[j] == nil {
+			return false
+		}
+	}
+	curve := set[0].Curve
+	N := curve.Params().N
+	set = padRing(set, m)
+
+	x := oneOfManyChallenge(curve, msg, set, proof)
+
+	// check the bit commitments
+	for j := 0; j < m; j++ {
+		// x*CL + CA == Com(f; z_a)
+		lhs := pointAdd(pointMul(proof.CL[j], x), proof.CA[j])
+		if !pointEqual(lhs, Commit(curve, proof.F[j], proof.ZA[j])) {
+			return false
+		}
+		// (x-f)*CL + CB == Com(0; z_b)
+		lhs = pointAdd(pointMul(proof.CL[j], new(big.Int).Sub(x, proof.F[j])), proof.CB[j])
+		if !pointEqual(lhs, baseMul(curve, proof.ZB[j])) {
+			return false
+		}
+	}
+
+	// sum_i p_i(x)*C_i - sum_k x^k*CD_k == Com(0; z_d)
+	var acc *ecdsa.PublicKey
+	for i, c := range set {
+		coeff := big.NewInt(1)
+		for j := 0; j < m; j++ {
+			if (i>>uint(j))&1 == 1 {
+				coeff.Mul(coeff, proof.F[j])
+			} else {
+				coeff.Mul(coeff, new(big.Int).Sub(x, proof.F[j]))
+			}
+			coeff.Mod(coeff, N)
+		}
+		acc = pointAdd(acc, pointMul(c, coeff))
+	}
+	xk := big.NewInt(1)
+	for k := 0; k < m; k++ {
+		acc = pointSub(acc, pointMul(proof.CD[k], xk))
+		xk = new(big.Int).Mul(xk, x)
+		xk.Mod(xk, N)
+	}
+	return pointEqual(acc, baseMul(curve, proof.ZD))
+}
+
+// SignOneOfMany creates a logarithmic-size ring signature over m, proving
+// knowledge of the private key of ring[s].
+func SignOneOfMany(m [32]byte, ring Ring, privkey *ecdsa.PrivateKey, s int) (*OneOfManySign, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	if !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	proof, err := ProveOneOfMany(m[:], ring, s, privkey.D)
+	if err != nil {
+		return nil, err
+	}
+	return &OneOfManySign{
+		M:     m,
+		Ring:  ring,
+		Proof: proof,
+		Curve: privkey.Curve,
+	}, nil
+}
+
+// VerifyOneOfMany verifies a logarithmic-size ring signature.
+// returns true if a valid signature, false otherwise
+func VerifyOneOfMany(sig *OneOfManySign) bool {
+	if sig == nil {
+		return false
+	}
+	return VerifyOneOfManyProof(sig.M[:], sig.Ring, sig.Proof)
+}
@@ -0,0 +1,58 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestOneOfMany(t *testing.T) {
+	for _, size := range []int{2, 3, 8, 13} {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := size / 2
+		ring := GenNewKeyRing(size, key, s)
+		msg := [32]byte{byte(size)}
+
+		sig, err := SignOneOfMany(msg, ring, key, s)
+		if err != nil {
+			t.Fatalf("size %d: failed to sign: %v", size, err)
+		}
+		if have, want := len(sig.Proof.CL), ringBits(size); have != want {
+			t.Errorf("size %d: proof length mismatch: have %d, want %d", size, have, want)
+		}
+		if !VerifyOneOfMany(sig) {
+			t.Fatalf("size %d: valid signature rejected", size)
+		}
+		sig.M[1] = 1
+		if VerifyOneOfMany(sig) {
+			t.Errorf("size %d: signature over different message accepted", size)
+		}
+	}
+}
+
+func TestOneOfManyCommitments(t *testing.T) {
+	curve := crypto.S256()
+
+	// a set of commitments to non-zero values with one commitment to zero
+	set := make(Ring, 5)
+	for i := range set {
+		set[i] = Commit(curve, big.NewInt(int64(i+1)), big.NewInt(int64(100+i)))
+	}
+	r := big.NewInt(4242)
+	set[3] = Commit(curve, new(big.Int), r)
+
+	proof, err := ProveOneOfMany([]byte("commitments"), set, 3, r)
+	if err != nil {
+		t.Fatalf("failed to prove: %v", err)
+	}
+	if !VerifyOneOfManyProof([]byte("commitments"), set, proof) {
+		t.Fatal("valid proof rejected")
+	}
+	if _, err := ProveOneOfMany([]byte("commitments"), set, 2, r); err != errNotSigner {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNotSigner)
+	}
+}
@@ -0,0 +1,98 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+)
+
+// The disjunctive Schnorr proof of Cramer, Damgard and Schoenmakers shows
+// knowledge of the private key of one of the given public keys P_i. Every key
+// gets a challenge c_i and a response s_i, and the challenges must sum up to
+// H(prefix, s_0*G + c_0*P_0, ..., s_{n-1}*G + c_{n-1}*P_{n-1}).
+
+// orHasher computes the overall challenge of a disjunctive proof from the
+// nonce commitments R_i.
+type orHasher func(R []*ecdsa.PublicKey) *big.Int
+
+// schnorrOrChallenge computes H(prefix, R_0, ..., R_{n-1}).
+func schnorrOrChallenge(curve elliptic.Curve, prefix [][]byte, R []*ecdsa.PublicKey) *big.Int {
+	data := append([][]byte{}, prefix...)
+	for _, r := range R {
+		data = append(data, pointBytes(r))
+	}
+	return hashToScalar(curve, data...)
+}
+
+// proveSchnorrOr proves knowledge of the private key of keys[s]. It returns
+// the challenges and responses of all keys.
+func proveSchnorrOr(prefix [][]byte, keys Ring, privkey *ecdsa.PrivateKey, s int) ([]*big.Int, []*big.Int, error) {
+	curve := privkey.Curve
+	return proveOr(keys, privkey, s, func(R []*ecdsa.PublicKey) *big.Int {
+		return schnorrOrChallenge(curve, prefix, R)
+	})
+}
+
+// verifySchnorrOr verifies a proof created by proveSchnorrOr.
+func verifySchnorrOr(curve elliptic.Curve, prefix [][]byte, keys Ring, C, S []*big.Int) bool {
+	return verifyOr(curve, keys, C, S, func(R []*ecdsa.PublicKey) *big.Int {
+		return schnorrOrChallenge(curve, prefix, R)
+	})
+}
+
+// proveOr is proveSchnorrOr with a custom challenge hash.
+func proveOr(keys Ring, privkey *ecdsa.PrivateKey, s int, hash orHasher) ([]*big.Int, []*big.Int, error) {
+	curve := privkey.Curve
+	N := curve.Params().N
+
+	C := make([]*big.Int, len(keys))
+	S := make([]*big.Int, len(keys))
+	R := make([]*ecdsa.PublicKey, len(keys))
+	k, err := randomScalar(curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, pub := range keys {
+		if i == s {
+			R[i] = baseMul(curve, k)
+			continue
+		}
+		if C[i], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+		if S[i], err = randomScalar(curve); err != nil {
+			return nil, nil, err
+		}
+		R[i] = pointAdd(baseMul(curve, S[i]), pointMul(pub, C[i]))
+	}
+	// c_s = c - sum_{i != s} c_i, s_s = k - c_s*x
+	c := hash(R)
+	for i, ci := range C {
+		if i != s {
+			c.Sub(c, ci)
+		}
+	}
+	C[s] = c.Mod(c, N)
+	ss := new(big.Int).Mul(C[s], privkey.D)
+	ss.Sub(k, ss)
+	S[s] = ss.Mod(ss, N)
+
+	return C, S, nil
+}
+
+// verifyOr verifies a proof created by proveOr.
+func verifyOr(curve elliptic.Curve, keys Ring, C, S []*big.Int, hash orHasher) bool {
+	if len(keys) < 2 || len(C) != len(keys) || len(S) != len(keys) {
+		return false
+	}
+	sum := new(big.Int)
+	R := make([]*ecdsa.PublicKey, len(keys))
+	for i, pub := range keys {
+		if pub == nil || C[i] == nil || S[i] == nil {
+			return false
+		}
+		R[i] = pointAdd(baseMul(curve, S[i]), pointMul(pub, C[i]))
+		sum.Add(sum, C[i])
+	}
+	return sum.Mod(sum, curve.Params().N).Cmp(hash(R)) == 0
+}
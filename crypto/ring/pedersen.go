@@ -0,0 +1,121 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/hashtocurve"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// pedersenDomain seeds the derivation of the secondary generator H.
+var pedersenDomain = []byte("go-ethereum/crypto/ring pedersen H")
+
+// hashToCurveDST separates the RFC 9380 hash to secp256k1 used by this package
+// from other applications of the same suite.
+var hashToCurveDST = []byte("go-ethereum/crypto/ring-V01-CS01-with-secp256k1_XMD:SHA-256_SSWU_RO_")
+
+var (
+	generatorLock sync.Mutex
+	generatorH    = make(map[elliptic.Curve]*ecdsa.PublicKey)
+)
+
+// curveA recovers the coefficient a of the short Weierstrass equation
+// y^2 = x^3 + ax + b from the base point, since elliptic.CurveParams only
+// carries b. It returns 0 for secp256k1 and -3 for the NIST curves.
+func curveA(curve elliptic.Curve) *big.Int {
+	params := curve.Params()
+	p := params.P
+
+	// a = (Gy^2 - Gx^3 - b) / Gx mod p
+	a := new(big.Int).Mul(params.Gy, params.Gy)
+	x3 := new(big.Int).Exp(params.Gx, big.NewInt(3), p)
+	a.Sub(a, x3)
+	a.Sub(a, params.B)
+	a.Mul(a, new(big.Int).ModInverse(params.Gx, p))
+	return a.Mod(a, p)
+}
+
+// hashToPoint deterministically maps data to a curve point whose discrete
+// logarithm with respect to G is unknown. secp256k1 uses the RFC 9380 suite
+// secp256k1_XMD:SHA-256_SSWU_RO_, a groupCurve its own hash function and the
+// remaining short Weierstrass curves try-and-increment.
+func hashToPoint(curve elliptic.Curve, data ...[]byte) *ecdsa.PublicKey {
+	if group, ok := curve.(groupCurve); ok {
+		x, y := group.HashToPoint(concatBytes(data))
+		return newPoint(curve, x, y)
+	}
+	if curve == crypto.S256() {
+		x, y, err := hashtocurve.Secp256k1(concatBytes(data), hashToCurveDST)
+		if err != nil {
+			panic(err) // unreachable with the fixed tag and output size
+		}
+		return newPoint(curve, x, y)
+	}
+	params := curve.Params()
+	p := params.P
+	a := curveA(curve)
+
+	var ctr [8]byte
+	for i := uint64(0); ; i++ {
+		binary.BigEndian.PutUint64(ctr[:], i)
+
+		h := sha3.New256()
+		for _, b := range data {
+			h.Write(b)
+		}
+		h.Write(ctr[:])
+		x := new(big.Int).SetBytes(h.Sum(nil))
+		if x.Cmp(p) >= 0 {
+			continue
+		}
+		// y^2 = x^3 + ax + b
+		rhs := new(big.Int).Exp(x, big.NewInt(3), p)
+		rhs.Add(rhs, new(big.Int).Mul(a, x))
+		rhs.Add(rhs, params.B)
+		rhs.Mod(rhs, p)
+
+		y := new(big.Int).ModSqrt(rhs, p)
+		if y == nil {
+			continue
+		}
+		// pick the even root so the mapping is deterministic
+		if y.Bit(0) == 1 {
+			y.Sub(p, y)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	}
+}
+
+func concatBytes(data [][]byte) []byte {
+	var buf []byte
+	for _, b := range data {
+		buf = append(buf, b...)
+	}
+	return buf
+}
+
+// GeneratorH returns the secondary Pedersen generator H of curve. It is
+// derived by hashing to the curve, so nobody knows log_G(H).
+func GeneratorH(curve elliptic.Curve) *ecdsa.PublicKey {
+	generatorLock.Lock()
+	defer generatorLock.Unlock()
+
+	h, ok := generatorH[curve]
+	if !ok {
+		h = hashToPoint(curve, pedersenDomain)
+		generatorH[curve] = h
+	}
+	return h
+}
+
+// Commit returns the Pedersen commitment C = r*G + v*H to value v with
+// blinding factor r. A public key x*G is thus a commitment to zero with
+// blinding factor x.
+func Commit(curve elliptic.Curve, v, r *big.Int) *ecdsa.PublicKey {
+	return pointAdd(baseMul(curve, r), pointMul(GeneratorH(curve), v))
+}
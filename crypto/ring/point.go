@@ -0,0 +1,284 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// The helpers in this file operate on curve points stored as *ecdsa.PublicKey,
+// the same representation used for ring members and key images. The point at
+// infinity is represented by a nil pointer, which the underlying curve
+// implementations cannot handle on their own.
+//
+// secp256k1 points are multiplied by crypto.S256, which is backed by
+// libsecp256k1 in cgo builds and by a pure Go implementation otherwise. The
+// pure Go implementation is picked automatically when cgo is not available,
+// as for wasm and most gomobile targets, and can be forced with the nocgo
+// build tag. Nothing in this package depends on the choice: the accelerated
+// operations of libsecp256k1 are detected through interfaces such as
+// combinedMultCurve and fall back to elliptic.Curve.
+
+// groupCurve is implemented by curves that are not in short Weierstrass form,
+// such as ristretto255. Their group law is complete and their identity element
+// has regular coordinates, so the helpers below defer negation, identity
+// detection and hashing to the curve itself.
+type groupCurve interface {
+	elliptic.Curve
+	Neg(x, y *big.Int) (*big.Int, *big.Int)
+	IsIdentity(x, y *big.Int) bool
+	HashToPoint(data []byte) (*big.Int, *big.Int)
+}
+
+// newPoint wraps the affine coordinates (x, y) into a point on curve. A nil
+// coordinate, (0, 0) as returned by the standard library curves, or the
+// identity of a groupCurve denote the point at infinity.
+func newPoint(curve elliptic.Curve, x, y *big.Int) *ecdsa.PublicKey {
+	if x == nil || y == nil {
+		return nil
+	}
+	if group, ok := curve.(groupCurve); ok {
+		if group.IsIdentity(x, y) {
+			return nil
+		}
+	} else if x.Sign() == 0 && y.Sign() == 0 {
+		return nil
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+}
+
+// pointAdd returns a+b, taking care of the identity, doubling and inverse
+// cases that curve.Add does not cover.
+func pointAdd(a, b *ecdsa.PublicKey) *ecdsa.PublicKey {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	curve := a.Curve
+	if _, ok := curve.(groupCurve); ok {
+		x, y := curve.Add(a.X, a.Y, b.X, b.Y)
+		return newPoint(curve, x, y)
+	}
+	if a.X.Cmp(b.X) == 0 {
+		if a.Y.Cmp(b.Y) != 0 {
+			return nil
+		}
+		x, y := curve.Double(a.X, a.Y)
+		return newPoint(curve, x, y)
+	}
+	x, y := curve.Add(a.X, a.Y, b.X, b.Y)
+	return newPoint(curve, x, y)
+}
+
+// pointSub returns a-b.
+func pointSub(a, b *ecdsa.PublicKey) *ecdsa.PublicKey {
+	return pointAdd(a, pointNeg(b))
+}
+
+// pointNeg returns -p.
+func pointNeg(p *ecdsa.PublicKey) *ecdsa.PublicKey {
+	if p == nil {
+		return nil
+	}
+	if group, ok := p.Curve.(groupCurve); ok {
+		x, y := group.Neg(p.X, p.Y)
+		return newPoint(p.Curve, x, y)
+	}
+	y := new(big.Int).Sub(p.Curve.Params().P, p.Y)
+	return newPoint(p.Curve, new(big.Int).Set(p.X), y)
+}
+
+// pointMul returns k*p, reducing k modulo the group order first.
+func pointMul(p *ecdsa.PublicKey, k *big.Int) *ecdsa.PublicKey {
+	if p == nil {
+		return nil
+	}
+	k = new(big.Int).Mod(k, p.Curve.Params().N)
+	if k.Sign() == 0 {
+		return nil
+	}
+	x, y := p.Curve.ScalarMult(p.X, p.Y, k.Bytes())
+	return newPoint(p.Curve, x, y)
+}
+
+// baseMul returns k*G for the base point G of curve.
+func baseMul(curve elliptic.Curve, k *big.Int) *ecdsa.PublicKey {
+	k = new(big.Int).Mod(k, curve.Params().N)
+	if k.Sign() == 0 {
+		return nil
+	}
+	x, y := curve.ScalarBaseMult(k.Bytes())
+	return newPoint(curve, x, y)
+}
+
+// combinedMultCurve is implemented by curves that compute k*G + l*P in a
+// single pass, such as libsecp256k1 behind crypto.S256 in cgo builds. The
+// computation runs in variable time and must only see public scalars.
+type combinedMultCurve interface {
+	CombinedMult(x, y *big.Int, baseScalar, scalar []byte) (*big.Int, *big.Int)
+}
+
+// combinedMul returns k*G + l*p for public scalars k and l.
+func combinedMul(curve elliptic.Curve, k *big.Int, p *ecdsa.PublicKey, l *big.Int) *ecdsa.PublicKey {
+	if cm, ok := curve.(combinedMultCurve); ok && p != nil {
+		N := curve.Params().N
+		k, l = new(big.Int).Mod(k, N), new(big.Int).Mod(l, N)
+		x, y := cm.CombinedMult(p.X, p.Y, k.Bytes(), l.Bytes())
+		return newPoint(curve, x, y)
+	}
+	return pointAdd(baseMul(curve, k), pointMul(p, l))
+}
+
+// msm returns g*G + sum(scalars[i]*points[i]) for public scalars. Curves
+// implementing combinedMultCurve fold the base point into the first term;
+// otherwise every term is multiplied on its own, which beats evaluating the
+// sum with Straus or Pippenger through elliptic.Curve, whose additions each
+// cost a field inversion.
+func msm(curve elliptic.Curve, g *big.Int, points []*ecdsa.PublicKey, scalars []*big.Int) *ecdsa.PublicKey {
+	if len(points) == 0 {
+		return baseMul(curve, g)
+	}
+	acc := combinedMul(curve, g, points[0], scalars[0])
+	for i := 1; i < len(points); i++ {
+		acc = pointAdd(acc, pointMul(points[i], scalars[i]))
+	}
+	return acc
+}
+
+// affine returns the coordinates of p, with (0, 0) for the point at infinity.
+func affine(p *ecdsa.PublicKey) (*big.Int, *big.Int) {
+	if p == nil {
+		return new(big.Int), new(big.Int)
+	}
+	return p.X, p.Y
+}
+
+// PublicKeyEqual reports whether a and b are the same public key: the same
+// point on the same curve. Keys are compared by value, so distinct pointers to
+// equal keys are equal.
+func PublicKeyEqual(a, b *ecdsa.PublicKey) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Curve == b.Curve && pointEqual(a, b)
+}
+
+// pointEqual reports whether a and b are the same point, which must be on the
+// same curve.
+func pointEqual(a, b *ecdsa.PublicKey) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0
+}
+
+// pointBytes returns the fixed width X||Y encoding of p. Coordinates take 32
+// bytes, or the field size on curves wider than 256 bits. The point at infinity
+// is encoded as all zeroes.
+func pointBytes(p *ecdsa.PublicKey) []byte {
+	if p == nil {
+		return make([]byte, 64)
+	}
+	size := 32
+	if bits := p.Curve.Params().BitSize; bits > 256 {
+		size = (bits + 7) / 8
+	}
+	return append(math.PaddedBigBytes(p.X, size), math.PaddedBigBytes(p.Y, size)...)
+}
+
+// hashToScalar hashes the concatenation of data into a scalar modulo the
+// group order of curve. Groups larger than 256 bits, such as P-384, use
+// SHAKE256 with 128 extra bits of output to keep the reduction unbiased.
+func hashToScalar(curve elliptic.Curve, data ...[]byte) *big.Int {
+	N := curve.Params().N
+	if bits := N.BitLen(); bits > 256 {
+		h := sha3.NewShake256()
+		for _, b := range data {
+			h.Write(b)
+		}
+		out := make([]byte, (bits+128+7)/8)
+		h.Read(out)
+		e := new(big.Int).SetBytes(out)
+		return e.Mod(e, N)
+	}
+	h := sha3.New256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, N)
+}
+
+// randomScalar returns a uniformly random non-zero scalar modulo the group
+// order of curve.
+func randomScalar(curve elliptic.Curve) (*big.Int, error) {
+	for {
+		k, err := rand.Int(rand.Reader, curve.Params().N)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+// multiExp accumulates a sum of scalar*point terms. Terms sharing the same
+// base are merged before any multiplication, which pays off when verifying
+// many proofs over overlapping rings.
+type multiExp struct {
+	curve elliptic.Curve
+	g     *big.Int              // accumulated scalar of the base point
+	terms map[string]*multiTerm // accumulated scalars keyed by point encoding
+}
+
+type multiTerm struct {
+	point  *ecdsa.PublicKey
+	scalar *big.Int
+}
+
+func newMultiExp(curve elliptic.Curve) *multiExp {
+	return &multiExp{
+		curve: curve,
+		g:     new(big.Int),
+		terms: make(map[string]*multiTerm),
+	}
+}
+
+// add adds k*p to the sum.
+func (me *multiExp) add(p *ecdsa.PublicKey, k *big.Int) {
+	if p == nil {
+		return
+	}
+	key := string(pointBytes(p))
+	term, ok := me.terms[key]
+	if !ok {
+		term = &multiTerm{point: p, scalar: new(big.Int)}
+		me.terms[key] = term
+	}
+	term.scalar.Add(term.scalar, k)
+	term.scalar.Mod(term.scalar, me.curve.Params().N)
+}
+
+// addBase adds k*G to the sum.
+func (me *multiExp) addBase(k *big.Int) {
+	me.g.Add(me.g, k)
+	me.g.Mod(me.g, me.curve.Params().N)
+}
+
+// sum evaluates the accumulated terms.
+func (me *multiExp) sum() *ecdsa.PublicKey {
+	points := make([]*ecdsa.PublicKey, 0, len(me.terms))
+	scalars := make([]*big.Int, 0, len(me.terms))
+	for _, term := range me.terms {
+		points = append(points, term.point)
+		scalars = append(scalars, term.scalar)
+	}
+	return msm(me.curve, me.g, points, scalars)
+}
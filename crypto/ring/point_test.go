@@ -0,0 +1,55 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestMSM(t *testing.T) {
+	for _, curve := range []elliptic.Curve{crypto.S256(), elliptic.P256()} {
+		g, _ := randomScalar(curve)
+		points := make([]*ecdsa.PublicKey, 4)
+		scalars := make([]*big.Int, len(points))
+		want := baseMul(curve, g)
+		for i := range points {
+			k, _ := randomScalar(curve)
+			points[i] = baseMul(curve, k)
+			scalars[i], _ = randomScalar(curve)
+			want = pointAdd(want, pointMul(points[i], scalars[i]))
+		}
+		if have := msm(curve, g, points, scalars); !pointEqual(have, want) {
+			t.Errorf("%s: wrong sum", curve.Params().Name)
+		}
+		if have := msm(curve, g, nil, nil); !pointEqual(have, baseMul(curve, g)) {
+			t.Errorf("%s: wrong sum without terms", curve.Params().Name)
+		}
+		// unreduced scalars and the point at infinity
+		N := curve.Params().N
+		k := new(big.Int).Add(N, big.NewInt(3))
+		if have := combinedMul(curve, k, points[0], N); !pointEqual(have, baseMul(curve, big.NewInt(3))) {
+			t.Errorf("%s: scalars not reduced", curve.Params().Name)
+		}
+		if have := combinedMul(curve, big.NewInt(1), newPoint(curve, curve.Params().Gx, curve.Params().Gy), new(big.Int).Sub(N, big.NewInt(1))); have != nil {
+			t.Errorf("%s: G - G is not the point at infinity", curve.Params().Name)
+		}
+	}
+}
+
+func TestPublicKeyEqual(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pub := key.PublicKey
+	if !PublicKeyEqual(&pub, &key.PublicKey) {
+		t.Error("copied key not equal")
+	}
+	other := &ecdsa.PublicKey{Curve: elliptic.P256(), X: pub.X, Y: pub.Y}
+	if PublicKeyEqual(other, &pub) {
+		t.Error("keys on different curves equal")
+	}
+	if PublicKeyEqual(nil, &pub) || PublicKeyEqual(&pub, nil) || !PublicKeyEqual(nil, nil) {
+		t.Error("wrong result for nil keys")
+	}
+}
@@ -0,0 +1,142 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Applications that sign and verify against the same ring over and over, such
+// as a static validator set, can precompute the per member work of linkable
+// ring signatures once. Every member is hashed to the curve with HashPoint
+// and HashToPoint, which cost a full base point multiplication and a map to
+// the curve; Precompute stores the results keyed by the fingerprint of the
+// ring, and Sign, Verify and VerifyBatch pick them up from there.
+//
+// Fixed-base tables for G are left to the curve implementations, which already
+// carry them: libsecp256k1 behind crypto.S256 and the standard library NIST
+// curves. Windowed tables for the ring members were measured to be several
+// times slower than the native variable base multiplications, since every
+// addition through elliptic.Curve pays a field inversion, so they are not
+// built.
+
+// precomputedRing holds the precomputed values of a ring.
+type precomputedRing struct {
+	ring   Ring
+	hashes [][2]*big.Int // HashPoint of every member
+	mapped [][2]*big.Int // HashToPoint of every member
+}
+
+// hashesOf returns the hashes of the members to the curve used by the given
+// transcript version.
+func (pre *precomputedRing) hashesOf(version byte) [][2]*big.Int {
+	if version == TranscriptV3 {
+		return pre.mapped
+	}
+	return pre.hashes
+}
+
+var (
+	precomputedLock  sync.RWMutex
+	precomputedRings = make(map[[32]byte]*precomputedRing)
+	precomputedCount int32 // number of cached rings, read without the lock
+)
+
+// RingFingerprint returns a hash identifying the members of ring and their
+// order.
+func RingFingerprint(ring Ring) [32]byte {
+	h := sha3.New256()
+	for _, pub := range ring {
+		h.Write(pointBytes(pub))
+	}
+	var fp [32]byte
+	h.Sum(fp[:0])
+	return fp
+}
+
+// Precompute caches the per member values of ring used when signing and
+// verifying. It is safe for concurrent use and returns the ring fingerprint.
+func Precompute(ring Ring) [32]byte {
+	fp := RingFingerprint(ring)
+
+	precomputedLock.RLock()
+	_, ok := precomputedRings[fp]
+	precomputedLock.RUnlock()
+	if ok {
+		return fp
+	}
+	pre := &precomputedRing{
+		ring:   append(Ring{}, ring...),
+		hashes: make([][2]*big.Int, len(ring)),
+		mapped: make([][2]*big.Int, len(ring)),
+	}
+	parallel(len(ring), func(i int) {
+		pre.hashes[i][0], pre.hashes[i][1] = HashPoint(ring[i])
+		pre.mapped[i][0], pre.mapped[i][1] = HashToPoint(ring[i])
+	})
+	precomputedLock.Lock()
+	if _, ok := precomputedRings[fp]; !ok {
+		precomputedRings[fp] = pre
+		atomic.AddInt32(&precomputedCount, 1)
+	}
+	precomputedLock.Unlock()
+	return fp
+}
+
+// ForgetPrecomputed drops the cached values of ring.
+func ForgetPrecomputed(ring Ring) {
+	fp := RingFingerprint(ring)
+
+	precomputedLock.Lock()
+	defer precomputedLock.Unlock()
+	if _, ok := precomputedRings[fp]; ok {
+		delete(precomputedRings, fp)
+		atomic.AddInt32(&precomputedCount, -1)
+	}
+}
+
+// lookupPrecomputed returns the cached values of ring, or nil if it was not
+// precomputed. The fingerprint is only computed when some ring is cached.
+func lookupPrecomputed(ring Ring) *precomputedRing {
+	if atomic.LoadInt32(&precomputedCount) == 0 {
+		return nil
+	}
+	fp := RingFingerprint(ring)
+
+	precomputedLock.RLock()
+	pre := precomputedRings[fp]
+	precomputedLock.RUnlock()
+
+	// guard against members on different curves with equal coordinates
+	if pre == nil || len(pre.ring) == 0 || len(ring) == 0 || pre.ring[0].Curve != ring[0].Curve {
+		precompMissMeter.Mark(1)
+		return nil
+	}
+	precompHitMeter.Mark(1)
+	return pre
+}
+
+// ringHasher returns the function hashing the members of ring to the curve
+// in the given transcript version, served from the cache if ring was
+// precomputed.
+func ringHasher(ring Ring, version byte) func(*ecdsa.PublicKey) (*big.Int, *big.Int) {
+	hashPoint := memberHasher(version)
+	pre := lookupPrecomputed(ring)
+	if pre == nil {
+		return hashPoint
+	}
+	hashes := pre.hashesOf(version)
+	index := make(map[*ecdsa.PublicKey]int, len(ring))
+	for i, pub := range ring {
+		index[pub] = i
+	}
+	return func(p *ecdsa.PublicKey) (*big.Int, *big.Int) {
+		if i, ok := index[p]; ok {
+			return hashes[i][0], hashes[i][1]
+		}
+		return hashPoint(p)
+	}
+}
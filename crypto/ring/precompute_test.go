@@ -0,0 +1,102 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func testRing(t testing.TB, n int) (Ring, []*ecdsa.PrivateKey) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	ring := make(Ring, n)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i], ring[i] = key, &key.PublicKey
+	}
+	return ring, keys
+}
+
+func TestRingFingerprint(t *testing.T) {
+	ring, _ := testRing(t, 3)
+	if RingFingerprint(ring) != RingFingerprint(append(Ring{}, ring...)) {
+		t.Error("fingerprint depends on ring identity")
+	}
+	if RingFingerprint(ring) == RingFingerprint(Ring{ring[1], ring[0], ring[2]}) {
+		t.Error("fingerprint ignores member order")
+	}
+}
+
+func TestPrecompute(t *testing.T) {
+	ring, keys := testRing(t, 4)
+	fp := Precompute(ring)
+	defer ForgetPrecomputed(ring)
+
+	if fp != RingFingerprint(ring) {
+		t.Fatal("wrong fingerprint returned")
+	}
+	pre := lookupPrecomputed(ring)
+	if pre == nil {
+		t.Fatal("ring not cached")
+	}
+	for i, pub := range ring {
+		x, y := HashPoint(pub)
+		if x.Cmp(pre.hashes[i][0]) != 0 || y.Cmp(pre.hashes[i][1]) != 0 {
+			t.Fatalf("wrong hash of member %d", i)
+		}
+	}
+	// signatures created with and without the cache verify either way
+	var wg sync.WaitGroup
+	sigs := make([]*RingSign, len(keys))
+	for i := range keys {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sig, err := Sign([32]byte{byte(i)}, ring, keys[i], i)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			sigs[i] = sig
+		}(i)
+	}
+	wg.Wait()
+	for i, ok := range VerifyBatch(sigs) {
+		if !ok || !Verify(sigs[i]) {
+			t.Fatalf("signature %d rejected with cache", i)
+		}
+	}
+	ForgetPrecomputed(ring)
+	if lookupPrecomputed(ring) != nil {
+		t.Fatal("ring still cached")
+	}
+	for i, sig := range sigs {
+		if !Verify(sig) {
+			t.Fatalf("signature %d rejected without cache", i)
+		}
+	}
+}
+
+func BenchmarkPrecomputedVerify(b *testing.B) {
+	ring, keys := testRing(b, 16)
+	sig, err := Sign([32]byte{}, ring, keys[0], 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("plain", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Verify(sig)
+		}
+	})
+	Precompute(ring)
+	defer ForgetPrecomputed(ring)
+	b.Run("precomputed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Verify(sig)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package ring
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/ring/ringpb"
+	"github.com/golang/protobuf/proto"
+)
+
+var errImageCurve = errors.New("ring and key image on different curves")
+
+// MarshalProto encodes the signature in the Protocol Buffers format defined in
+// ringpb/ring.proto. The format has no transcript version yet, so only
+// signatures with the original transcript can be encoded.
+func (r *RingSign) MarshalProto() ([]byte, error) {
+	if encodedVersion(r) != 0 {
+		return nil, errTranscriptVersion
+	}
+	name, err := CurveName(r.Curve)
+	if err != nil {
+		return nil, err
+	}
+	curve := r.Curve
+	scalar := scalarSize(curve)
+
+	msg := &ringpb.RingSign{
+		Message:  r.M[:],
+		Ring:     &ringpb.Ring{Curve: name, Keys: make([][]byte, len(r.Ring))},
+		C:        math.PaddedBigBytes(r.C, scalar),
+		S:        make([][]byte, len(r.S)),
+		KeyImage: &ringpb.KeyImage{Curve: name, Point: compressPoint(curve, newPoint(curve, r.I.X, r.I.Y))},
+	}
+	for i, pub := range r.Ring {
+		msg.Ring.Keys[i] = compressPoint(curve, pub)
+	}
+	for i, s := range r.S {
+		msg.S[i] = math.PaddedBigBytes(s, scalar)
+	}
+	return proto.Marshal(msg)
+}
+
+// UnmarshalProto decodes a signature encoded by MarshalProto.
+func (r *RingSign) UnmarshalProto(b []byte) error {
+	msg := new(ringpb.RingSign)
+	if err := proto.Unmarshal(b, msg); err != nil {
+		return err
+	}
+	if msg.Ring == nil || msg.KeyImage == nil || msg.Ring.Curve != msg.KeyImage.Curve {
+		return errImageCurve
+	}
+	curve, err := CurveByName(msg.Ring.Curve)
+	if err != nil {
+		return err
+	}
+	if len(msg.Message) != 32 {
+		return errEncodingLength
+	}
+	if len(msg.S) != len(msg.Ring.Keys) {
+		return errSignatureSize
+	}
+	scalar := scalarSize(curve)
+	readScalar := func(b []byte) (*big.Int, error) {
+		if len(b) != scalar {
+			return nil, errEncodingLength
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	sig := RingSign{
+		Size:  len(msg.Ring.Keys),
+		S:     make([]*big.Int, len(msg.S)),
+		Ring:  make(Ring, len(msg.Ring.Keys)),
+		Curve: curve,
+	}
+	copy(sig.M[:], msg.Message)
+	if sig.C, err = readScalar(msg.C); err != nil {
+		return err
+	}
+	for i := range sig.S {
+		if sig.S[i], err = readScalar(msg.S[i]); err != nil {
+			return err
+		}
+		if sig.Ring[i], err = decompressPoint(curve, msg.Ring.Keys[i]); err != nil {
+			return err
+		}
+		if sig.Ring[i] == nil {
+			return errInvalidPoint
+		}
+	}
+	if sig.I, err = decompressPoint(curve, msg.KeyImage.Point); err != nil {
+		return err
+	}
+	if sig.I == nil {
+		return errInvalidPoint
+	}
+	*r = sig
+	return nil
+}
@@ -6,9 +6,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"math/big"
-	"crypto/rand"
 	"crypto/elliptic"
 	"crypto/ecdsa"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto/sha3"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -24,6 +24,7 @@ type RingSign struct {
 	Ring Ring // array of public keys
 	I *ecdsa.PublicKey // key image
 	Curve elliptic.Curve 
+	Version byte // transcript version, zero for TranscriptV1
 }
 
 // helper function, returns type of v
@@ -40,6 +41,22 @@ func (r Ring) Bytes() (b []byte) {
 	return
 }
 
+// Contains reports whether pub is a member of the ring, see PublicKeyEqual.
+func (r Ring) Contains(pub *ecdsa.PublicKey) bool {
+	return r.Index(pub) >= 0
+}
+
+// Index returns the position of the first occurrence of pub in the ring, or
+// -1 if pub is not a member.
+func (r Ring) Index(pub *ecdsa.PublicKey) int {
+	for i, member := range r {
+		if PublicKeyEqual(member, pub) {
+			return i
+		}
+	}
+	return -1
+}
+
 func PadTo32Bytes(in []byte) (out []byte) {
 	out = append(out, in...)
 	for {
@@ -53,21 +70,21 @@ func PadTo32Bytes(in []byte) (out []byte) {
 // converts the signature to a byte array
 // this is the format that will be used when passing EVM bytecode
 func (r *RingSign) SerializeSignature() (sig []byte) {
-	// add size and message
+	// add version, size and message
 	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, uint64(r.Size))
+	binary.BigEndian.PutUint64(b, joinSize(encodedVersion(r), r.Size))
 	sig = append(sig, b[:]...)
 	sig = append(sig, r.M[:]...)
-	sig = append(sig, r.C.Bytes()...)
+	sig = append(sig, PadTo32Bytes(r.C.Bytes())...)
 
 	for i := 0; i < r.Size; i++ {
-		sig = append(sig, r.S[i].Bytes()...)
-		sig = append(sig, r.Ring[i].X.Bytes()...)
-		sig = append(sig, r.Ring[i].Y.Bytes()...)
+		sig = append(sig, PadTo32Bytes(r.S[i].Bytes())...)
+		sig = append(sig, PadTo32Bytes(r.Ring[i].X.Bytes())...)
+		sig = append(sig, PadTo32Bytes(r.Ring[i].Y.Bytes())...)
     }
 
-	sig = append(sig, r.I.X.Bytes()...)
-	sig = append(sig, r.I.Y.Bytes()...)
+	sig = append(sig, PadTo32Bytes(r.I.X.Bytes())...)
+	sig = append(sig, PadTo32Bytes(r.I.Y.Bytes())...)
 
     // correct length of byteified signature in bytes:
     // m + c + I + n*(P.X + P.Y + s) + size
@@ -79,24 +96,31 @@ func (r *RingSign) SerializeSignature() (sig []byte) {
 // deserializes the byteified signature into a RingSign struct
 func DeserializeSignature(r []byte) (*RingSign, error) {
 	sig := new(RingSign)
-	size := r[0:8]
 
 	if len(r) < 72 {
 		return nil, errors.New("incorrect ring size")
 	}
+	size := r[0:8]
 
 	m := r[8:40]
 
 	var m_byte [32]byte
 	copy(m_byte[:], m)
 
-	size_uint := binary.BigEndian.Uint64(size)
+	version, size_uint := splitSize(binary.BigEndian.Uint64(size))
+	if !validVersion(version) {
+		return nil, errTranscriptVersion
+	}
 	size_int := int(size_uint)
 
 	sig.Size = size_int
+	sig.Version = version
 	sig.M = m_byte
 	sig.C = new(big.Int).SetBytes(r[40:72])
 
+	if size_uint > uint64(len(r)) / 96 {
+		return nil, errors.New("incorrect ring size")
+	}
 	bytelen := size_int * 96
 
 	if len(r) < bytelen+136 {
@@ -107,7 +131,7 @@ func DeserializeSignature(r []byte) (*RingSign, error) {
 	sig.S = make([]*big.Int, size_int)
 	sig.Ring = make([]*ecdsa.PublicKey, size_int)
 
-	for i := 72; i < bytelen; i += 96 {
+	for i := 72; i < bytelen+72; i += 96 {
 		s_i := r[i:i+32]
 		x_i := r[i+32:i+64]
 		y_i := r[i+64:i+96]
@@ -152,15 +176,21 @@ func GenNewKeyRing(size int, privkey *ecdsa.PrivateKey, s int) ([]*ecdsa.PublicK
 }
 
 // calculate key image I = x * H_p(P) where H_p is a hash function that returns a point
-// H_p(P) = sha3(P) * G
+// H_p is HashToPoint, the hash of DefaultTranscript, see GenKeyImageVersion
 func GenKeyImage(privkey *ecdsa.PrivateKey) (*ecdsa.PublicKey) {
+	return GenKeyImageVersion(privkey, DefaultTranscript)
+}
+
+// GenKeyImageVersion returns the key image x * H_p(P) of privkey in signatures
+// with the given transcript version.
+func GenKeyImageVersion(privkey *ecdsa.PrivateKey, version byte) (*ecdsa.PublicKey) {
 	pubkey := privkey.Public().(*ecdsa.PublicKey)
 	image := new(ecdsa.PublicKey)
 
-	// calculate sha3(P)
-	h_x, h_y := HashPoint(pubkey)
+	// calculate H_p(P)
+	h_x, h_y := memberHasher(version)(pubkey)
 
-	// calculate H_p(P) = x * sha3(P) * G
+	// calculate I = x * H_p(P)
 	i_x, i_y := privkey.Curve.ScalarMult(h_x, h_y, privkey.D.Bytes())
 
 	image.X = i_x
@@ -168,17 +198,51 @@ func GenKeyImage(privkey *ecdsa.PrivateKey) (*ecdsa.PublicKey) {
 	return image
 }
 
+// HashPoint returns H_p(P) = sha3(P) * G, the hash of ring members to the
+// curve of transcripts v1 and v2. Its discrete logarithm is public, so it is
+// only kept to verify old signatures, see HashToPoint.
 func HashPoint(p *ecdsa.PublicKey) (*big.Int, *big.Int) {
 	hash := sha3.Sum256(append(p.X.Bytes(), p.Y.Bytes()...))
 	return p.Curve.ScalarBaseMult(hash[:])
 }
 
+// hashPointDomain separates the hash of ring members to the curve from the
+// other uses of hashToPoint.
+var hashPointDomain = []byte("go-ethereum/crypto/ring H_p")
+
+// HashToPoint returns H_p(P) of transcript v3, the fixed-width encoding of P
+// mapped to the curve by hashToPoint. Nobody knows its discrete logarithm.
+func HashToPoint(p *ecdsa.PublicKey) (*big.Int, *big.Int) {
+	h := hashToPoint(p.Curve, hashPointDomain, pointBytes(p))
+	return h.X, h.Y
+}
+
 // create ring signature from list of public keys given inputs:
 // msg: byte array, message to be signed
 // ring: array of *ecdsa.PublicKeys to be included in the ring
 // privkey: *ecdsa.PrivateKey of signer
 // s: index of signer in ring
+// the random values of the signature are hedged, see HedgedNonces
+// invalid ring members are reported as a *MemberError, see Ring.Validate
 func Sign(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int) (*RingSign, error) {
+	return sign(m, ring, privkey, s, new(SignOpts))
+}
+
+// sign is Sign with the nonce mode and transcript version of opts.
+func sign(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int, opts *SignOpts) (*RingSign, error) {
+	start := time.Now()
+	sig, err := signRing(m, ring, privkey, s, opts)
+	if err != nil {
+		signFailMeter.Mark(1)
+		return nil, err
+	}
+	signTimer.UpdateSince(start)
+	signSizeHist.Update(int64(len(ring)))
+	return sig, nil
+}
+
+// signRing creates the signature of sign.
+func signRing(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int, opts *SignOpts) (*RingSign, error) {
 	// check ringsize > 1
 	ringsize := len(ring)
 	if ringsize < 2 {
@@ -187,6 +251,88 @@ func Sign(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int)
 		return nil, errors.New("secret index out of range of ring size")
 	}
 
+	if !validVersion(opts.Version) {
+		return nil, errTranscriptVersion
+	}
+	if err := Ring(ring).Validate(); err != nil {
+		return nil, err
+	}
+
+	u, S, err := signingRandomness(m, ring, privkey, s, opts.Nonces)
+	if err != nil {
+		return nil, err
+	}
+	version := signVersion(opts.Version)
+	return signWithImage(m, ring, privkey, s, u, S, GenKeyImageVersion(privkey, version), ringHasher(ring, version), version)
+}
+
+// signingRandomness picks the random scalar u (glue value) and random
+// responses s_i for all members other than s, modulo the group order.
+func signingRandomness(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int, mode NonceMode) (*big.Int, []*big.Int, error) {
+	r, err := nonceReader(mode, privkey, m, ring)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, err := readNonce(r, privkey)
+	if err != nil {
+		return nil, nil, err
+	}
+	S := make([]*big.Int, len(ring))
+	for i := range S {
+		if i == s {
+			continue
+		}
+		if S[i], err = readNonce(r, privkey); err != nil {
+			return nil, nil, err
+		}
+	}
+	return u, S, nil
+}
+
+// signWithRandomness creates a ring signature using the glue value u and the
+// responses S[i] of all members i != s, which makes signing deterministic. It
+// uses the original transcript pinned by the test vectors.
+func signWithRandomness(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int, u *big.Int, S []*big.Int) (*RingSign, error) {
+	return signWithImage(m, ring, privkey, s, u, S, GenKeyImageVersion(privkey, TranscriptV1), ringHasher(ring, TranscriptV1), 0)
+}
+
+// ringChallenges computes the challenges of a signature whose signer at index
+// s committed to L_s and R_s, given the responses S[i] of all other members.
+func ringChallenges(sc *scratch, curve elliptic.Curve, m [32]byte, ring []*ecdsa.PublicKey, s int, S []*big.Int, image *ecdsa.PublicKey, hashPoint func(*ecdsa.PublicKey) (*big.Int, *big.Int), version byte, l_x, l_y, r_x, r_y *big.Int) []*big.Int {
+	ringsize := len(ring)
+	C := make([]*big.Int, ringsize)
+
+	// concatenate m and u*G and calculate c[s+1] = H(m, L_s, R_s)
+	idx := (s+1) % ringsize
+	C[idx] = sc.challenge(version, curve, m[:], l_x, l_y, r_x, r_y)
+
+	// start loop at s+1
+	for i := 1; i < ringsize; i++ { 
+		idx := (s+i) % ringsize
+
+		s_i := S[idx]
+
+		// calculate L_i = s_i*G + c_i*P_i, both scalars are public
+		l_x, l_y := affine(combinedMul(curve, s_i, ring[idx], C[idx]))
+
+		// calculate R_i = s_i*H_p(P_i) + c_i*I
+		px, py := curve.ScalarMult(image.X, image.Y, C[idx].Bytes()) // px, py = c_i*I
+		hx, hy := hashPoint(ring[idx])
+		sx, sy := curve.ScalarMult(hx, hy, s_i.Bytes())	// sx, sy = s[n-1]*H_p(P_i)
+		r_x, r_y := sc.add(curve, sx, sy, px, py)
+
+		// calculate c[i+1] = H(m, L_i, R_i)
+		C[(idx+1)%ringsize] = sc.challenge(version, curve, m[:], l_x, l_y, r_x, r_y)
+	}
+	return C
+}
+
+// signWithImage is signWithRandomness with the key image of privkey and the
+// hash of ring members to curve points supplied by the caller, producing a
+// signature with the given transcript version.
+func signWithImage(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int, u *big.Int, S []*big.Int, image *ecdsa.PublicKey, hashPoint func(*ecdsa.PublicKey) (*big.Int, *big.Int), version byte) (*RingSign, error) {
+	ringsize := len(ring)
+
 	// setup
 	pubkey := privkey.Public().(*ecdsa.PublicKey)
 	curve := pubkey.Curve
@@ -195,75 +341,30 @@ func Sign(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int)
 	sig.M = m
 	sig.Ring = ring
 	sig.Curve = curve
+	sig.Version = version
 
 	// check that key at index s is indeed the signer
-	if ring[s] != pubkey {
+	if !PublicKeyEqual(ring[s], pubkey) {
 		return nil, errors.New("secret index in ring is not signer")
 	}
 
-	// generate key image
-	image := GenKeyImage(privkey)
 	sig.I = image
 
 	// start at c[1]
 	// pick random scalar u (glue value), calculate c[1] = H(m, u*G) where H is a hash function and G is the base point of the curve
-	C := make([]*big.Int, ringsize)
-	S := make([]*big.Int, ringsize)
-
-	// pick random scalar u
-	u, err := rand.Int(rand.Reader, curve.Params().P)
-	if err != nil {
-		return nil, err
-	}
+	S = append([]*big.Int{}, S...)
 
 	// start at secret index s
 	// compute L_s = u*G
 	l_x, l_y := curve.ScalarBaseMult(u.Bytes())
 	// compute R_s = u*H_p(P[s])
-	h_x, h_y := HashPoint(pubkey)
+	h_x, h_y := hashPoint(ring[s])
 	r_x, r_y := curve.ScalarMult(h_x, h_y, u.Bytes())
 
-	l := append(l_x.Bytes(), l_y.Bytes()...)
-	r := append(r_x.Bytes(), r_y.Bytes()...)
-
-	// concatenate m and u*G and calculate c[s+1] = H(m, L_s, R_s)
-	C_i := sha3.Sum256(append(m[:], append(l, r...)...))
-	idx := (s+1) % ringsize
-	C[idx] = new(big.Int).SetBytes(C_i[:])
-
-	// start loop at s+1
-	for i := 1; i < ringsize; i++ { 
-		idx := (s+i) % ringsize
-
-		// pick random scalar s_i
-		s_i, err := rand.Int(rand.Reader, curve.Params().P)
-		S[idx] = s_i
-		if err != nil {
-			return nil, err
-		}	
-
-		// calculate L_i = s_i*G + c_i*P_i
-		px, py := curve.ScalarMult(ring[idx].X, ring[idx].Y, C[idx].Bytes()) // px, py = c_i*P_i
-		sx, sy := curve.ScalarBaseMult(s_i.Bytes())	// sx, sy = s[n-1]*G
-		l_x, l_y := curve.Add(sx, sy, px, py) 
+	sc := getScratch()
+	defer putScratch(sc)
 
-		// calculate R_i = s_i*H_p(P_i) + c_i*I
-		px, py = curve.ScalarMult(image.X, image.Y, C[idx].Bytes()) // px, py = c_i*I
-		hx, hy := HashPoint(ring[idx])
-		sx, sy = curve.ScalarMult(hx, hy, s_i.Bytes())	// sx, sy = s[n-1]*H_p(P_i)
-		r_x, r_y := curve.Add(sx, sy, px, py) 
-
-		// calculate c[i+1] = H(m, L_i, R_i)
-		l := append(l_x.Bytes(), l_y.Bytes()...)
-		r := append(r_x.Bytes(), r_y.Bytes()...)
-		C_i = sha3.Sum256(append(m[:], append(l, r...)...))
-
-		if i == ringsize - 1 {
-			C[s] = new(big.Int).SetBytes(C_i[:])
-		} else {
-			C[(idx+1)%ringsize] = new(big.Int).SetBytes(C_i[:])
-		}
-	}
+	C := ringChallenges(sc, curve, m, ring, s, S, image, hashPoint, version, l_x, l_y, r_x, r_y)
 
 	// close ring by finding S[s] = ( u - c[s]*k[s] ) mod P where k[s] is the private key and P is the order of the curve
 	S[s] = new(big.Int).Mod(new(big.Int).Sub(u, new(big.Int).Mul(C[s], privkey.D)), curve.Params().N)
@@ -272,20 +373,14 @@ func Sign(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int)
 	ux, uy := curve.ScalarBaseMult(u.Bytes()) // u*G
 	px, py := curve.ScalarMult(ring[s].X, ring[s].Y, C[s].Bytes())
 	sx, sy := curve.ScalarBaseMult(S[s].Bytes())
-	l_x, l_y = curve.Add(sx, sy, px, py) 
+	l_x, l_y = sc.add(curve, sx, sy, px, py)
 
 	// check that u*H_p(P[s]) = S[s]*H_p(P[s]) + C[s]*I
 	px, py = curve.ScalarMult(image.X, image.Y, C[s].Bytes())// px, py = C[s]*I
-	hx, hy := HashPoint(ring[s])
+	hx, hy := hashPoint(ring[s])
 	tx, ty := curve.ScalarMult(hx, hy, u.Bytes())
 	sx, sy = curve.ScalarMult(hx, hy, S[s].Bytes())	// sx, sy = S[s]*H_p(P[s])
-	r_x, r_y = curve.Add(sx, sy, px, py) 
-
-	l = append(l_x.Bytes(), l_y.Bytes()...)
-	r = append(r_x.Bytes(), r_y.Bytes()...)
-
-	// check that H(m, L[s], R[s]) == C[s+1]
-	C_i = sha3.Sum256(append(m[:], append(l, r...)...))
+	r_x, r_y = sc.add(curve, sx, sy, px, py)
 
 	if !bytes.Equal(ux.Bytes(), l_x.Bytes()) || !bytes.Equal(uy.Bytes(), l_y.Bytes()) || !bytes.Equal(tx.Bytes(), r_x.Bytes()) || !bytes.Equal(ty.Bytes(), r_y.Bytes()) { //|| !bytes.Equal(C[(s+1)%ringsize].Bytes(), C_i[:]) {
 			return nil, errors.New("error closing ring")
@@ -300,45 +395,71 @@ func Sign(m [32]byte, ring []*ecdsa.PublicKey, privkey *ecdsa.PrivateKey, s int)
 
 // verify ring signature contained in RingSign struct
 // returns true if a valid signature, false otherwise
+// challenges and responses out of range are rejected, see checkScalars
 func Verify(sig *RingSign) (bool) { 
+	if !wellFormed(sig) || checkScalars(sig) != nil {
+		verifyFailMeter.Mark(1)
+		return false
+	}
+	start := time.Now()
+	C := challengeChain(sig)
+	valid := sig.C.Cmp(C[sig.Size]) == 0
+
+	verifyTimer.UpdateSince(start)
+	verifySizeHist.Update(int64(sig.Size))
+	if !valid {
+		verifyFailMeter.Mark(1)
+	}
+	return valid
+}
+
+// challengeChain recomputes the challenges of a signature from c[0] and the
+// responses. It returns c[0], ..., c[n-1] followed by the recomputed c[n],
+// which closes the ring if it equals c[0].
+func challengeChain(sig *RingSign) []*big.Int {
+	return challengeChainWith(sig, ringHasher(sig.Ring, transcriptVersion(sig)))
+}
+
+// challengeChainWith is challengeChain with the hash of ring members to curve
+// points supplied by the caller, so it can be shared between signatures.
+func challengeChainWith(sig *RingSign, hashPoint func(*ecdsa.PublicKey) (*big.Int, *big.Int)) []*big.Int {
 	// setup
-	ring := sig.Ring
 	ringsize := sig.Size
-	S := sig.S
-	C := make([]*big.Int, ringsize)
+	C := make([]*big.Int, ringsize+1)
 	C[0] = sig.C
-	curve := sig.Curve
-	image := sig.I
+
+	sc := getScratch()
+	defer putScratch(sc)
 
 	// calculate c[i+1] = H(m, s[i]*G + c[i]*P[i])
 	// and c[0] = H)(m, s[n-1]*G + c[n-1]*P[n-1]) where n is the ring size
 	for i := 0; i < ringsize; i++ {
-		// calculate L_i = s_i*G + c_i*P_i
-		px, py := curve.ScalarMult(ring[i].X, ring[i].Y, C[i].Bytes()) // px, py = c_i*P_i
-		sx, sy := curve.ScalarBaseMult(S[i].Bytes())	// sx, sy = s[i]*G
-		l_x, l_y := curve.Add(sx, sy, px, py) 
+		C[i+1] = challengeStep(sc, sig, hashPoint, i, C[i])
+	}
 
-		// calculate R_i = s_i*H_p(P_i) + c_i*I
-		px, py = curve.ScalarMult(image.X, image.Y, C[i].Bytes()) // px, py = c[i]*I
-		hx, hy := HashPoint(ring[i])
-		sx, sy = curve.ScalarMult(hx, hy, S[i].Bytes())	// sx, sy = s[i]*H_p(P[i])
-		r_x, r_y := curve.Add(sx, sy, px, py) 
+	return C
+}
 
-		// calculate c[i+1] = H(m, L_i, R_i)
-		l := append(l_x.Bytes(), l_y.Bytes()...)
-		r := append(r_x.Bytes(), r_y.Bytes()...)
-		C_i := sha3.Sum256(append(sig.M[:], append(l, r...)...))
-
-		if i == ringsize - 1 {
-			C[0] = new(big.Int).SetBytes(C_i[:])	
-		} else {
-			C[i+1] = new(big.Int).SetBytes(C_i[:])	
-		}	
-	}
+// challengeStep returns the challenge c[i+1] = H(m, L_i, R_i) following c[i]
+// = c in the chain of sig.
+func challengeStep(sc *scratch, sig *RingSign, hashPoint func(*ecdsa.PublicKey) (*big.Int, *big.Int), i int, c *big.Int) *big.Int {
+	curve, image := sig.Curve, sig.I
+
+	// calculate L_i = s_i*G + c_i*P_i
+	l_x, l_y := affine(combinedMul(curve, sig.S[i], sig.Ring[i], c))
+
+	// calculate R_i = s_i*H_p(P_i) + c_i*I
+	px, py := curve.ScalarMult(image.X, image.Y, c.Bytes()) // px, py = c[i]*I
+	hx, hy := hashPoint(sig.Ring[i])
+	sx, sy := curve.ScalarMult(hx, hy, sig.S[i].Bytes())	// sx, sy = s[i]*H_p(P[i])
+	r_x, r_y := sc.add(curve, sx, sy, px, py)
 
-	return bytes.Equal(sig.C.Bytes(), C[0].Bytes())
+	// calculate c[i+1] = H(m, L_i, R_i)
+	return sc.challenge(sig.Version, curve, sig.M[:], l_x, l_y, r_x, r_y)
 }
 
+// Link reports whether two signatures were made with the same private key,
+// that is whether their key images are equal.
 func Link(sig_a *RingSign, sig_b *RingSign) (bool) {
-	return sig_a.I.X == sig_b.I.X && sig_a.I.Y == sig_b.I.Y
+	return pointEqual(sig_a.I, sig_b.I)
 }
\ No newline at end of file
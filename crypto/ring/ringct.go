@@ -0,0 +1,315 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// RingCT transactions (Noether, "Ring Confidential Transactions", 2016) hide
+// the sender among decoys and the amounts behind Pedersen commitments. Every
+// input spends one output of a ring of (key, commitment) pairs with a Triptych
+// signature and re-commits to the spent amount in a pseudo output commitment.
+// The transaction balances if the pseudo outputs minus the output commitments
+// minus fee*H is the point at infinity, and a single aggregated range proof
+// shows that no output commitment hides a negative amount.
+
+// ringctDomain separates the transaction hash of RingCT transfers from other
+// hashes in the package.
+var ringctDomain = []byte("go-ethereum/crypto/ring ringct")
+
+var (
+	errNoInputs      = errors.New("transaction has no inputs")
+	errNoOutputs     = errors.New("transaction has no outputs")
+	errUnbalanced    = errors.New("input amounts do not cover outputs and fee")
+	errInputMismatch = errors.New("input key or mask does not open ring member")
+	errCurveMismatch = errors.New("inputs and outputs on different curves")
+)
+
+// RingCTInput is an output spent by a RingCT transaction together with the
+// decoys it hides among.
+type RingCTInput struct {
+	Key         *ecdsa.PrivateKey // one-time private key of the spent output
+	Amount      uint64            // amount of the spent output
+	Mask        *big.Int          // blinding factor of the spent output's commitment
+	Ring        Ring              // keys of the spent output and the decoys
+	Commitments Ring              // amount commitments of the ring members
+	Index       int               // position of the spent output in the ring
+}
+
+// RingCTOutput is a destination of a RingCT transaction.
+type RingCTOutput struct {
+	Key    *ecdsa.PublicKey // one-time public key of the recipient
+	Amount uint64
+}
+
+// RingCTTransaction is a balanced confidential transfer.
+type RingCTTransaction struct {
+	Inputs      []*TriptychSign // one signature per input
+	PseudoOuts  Ring            // pseudo output commitments, one per input
+	Outputs     Ring            // one-time keys of the recipients
+	Commitments Ring            // amount commitments of the outputs
+	RangeProof  *RangeProof     // aggregated range proof over Commitments
+	Fee         uint64          // plaintext fee
+	Curve       elliptic.Curve
+}
+
+// ringctHash computes the message every input signs: the transaction without
+// its signatures.
+func ringctHash(tx *RingCTTransaction, inputs []Ring, commitments []Ring) [32]byte {
+	var fee [8]byte
+	binary.BigEndian.PutUint64(fee[:], tx.Fee)
+
+	h := sha3.New256()
+	h.Write(ringctDomain)
+	h.Write(fee[:])
+	for i := range inputs {
+		for j := range inputs[i] {
+			h.Write(pointBytes(inputs[i][j]))
+			h.Write(pointBytes(commitments[i][j]))
+		}
+		h.Write(pointBytes(tx.PseudoOuts[i]))
+	}
+	for i := range tx.Outputs {
+		h.Write(pointBytes(tx.Outputs[i]))
+		h.Write(pointBytes(tx.Commitments[i]))
+	}
+	var m [32]byte
+	h.Sum(m[:0])
+	return m
+}
+
+// BuildRingCT creates a confidential transfer spending inputs to outputs and
+// paying fee. It returns the transaction and the blinding factors of the
+// output commitments, which the recipients need to spend them.
+func BuildRingCT(inputs []*RingCTInput, outputs []*RingCTOutput, fee uint64) (*RingCTTransaction, []*big.Int, error) {
+	if len(inputs) == 0 {
+		return nil, nil, errNoInputs
+	}
+	if len(outputs) == 0 {
+		return nil, nil, errNoOutputs
+	}
+	curve := inputs[0].Key.Curve
+	N := curve.Params().N
+
+	// check the inputs open their ring members and the amounts balance
+	balance := new(big.Int).SetUint64(fee)
+	for _, out := range outputs {
+		if out.Key == nil || out.Key.Curve != curve {
+			return nil, nil, errCurveMismatch
+		}
+		balance.Add(balance, new(big.Int).SetUint64(out.Amount))
+	}
+	for _, in := range inputs {
+		if in.Key.Curve != curve {
+			return nil, nil, errCurveMismatch
+		}
+		if len(in.Commitments) != len(in.Ring) {
+			return nil, nil, errCommitmentCount
+		}
+		if in.Index < 0 || in.Index >= len(in.Ring) {
+			return nil, nil, errIndexOutOfRange
+		}
+		commitment := Commit(curve, new(big.Int).SetUint64(in.Amount), in.Mask)
+		if !pointEqual(in.Ring[in.Index], &in.Key.PublicKey) || !pointEqual(in.Commitments[in.Index], commitment) {
+			return nil, nil, errInputMismatch
+		}
+		balance.Sub(balance, new(big.Int).SetUint64(in.Amount))
+	}
+	if balance.Sign() != 0 {
+		return nil, nil, errUnbalanced
+	}
+	// output commitments and their range proof
+	tx := &RingCTTransaction{
+		PseudoOuts:  make(Ring, len(inputs)),
+		Outputs:     make(Ring, len(outputs)),
+		Commitments: make(Ring, len(outputs)),
+		Fee:         fee,
+		Curve:       curve,
+	}
+	amounts := make([]uint64, len(outputs))
+	masks := make([]*big.Int, len(outputs))
+	outMask := new(big.Int)
+	for i, out := range outputs {
+		mask, err := randomScalar(curve)
+		if err != nil {
+			return nil, nil, err
+		}
+		tx.Outputs[i] = out.Key
+		tx.Commitments[i] = Commit(curve, new(big.Int).SetUint64(out.Amount), mask)
+		amounts[i], masks[i] = out.Amount, mask
+		outMask.Add(outMask, mask)
+	}
+	proof, err := ProveRange(curve, amounts, masks)
+	if err != nil {
+		return nil, nil, err
+	}
+	tx.RangeProof = proof
+
+	// pseudo outputs re-commit to the input amounts, their masks summing up
+	// to the output masks so that the blinding factors cancel
+	pseudoMasks := make([]*big.Int, len(inputs))
+	for i, in := range inputs {
+		if i == len(inputs)-1 {
+			pseudoMasks[i] = outMask.Mod(outMask, N)
+		} else {
+			if pseudoMasks[i], err = randomScalar(curve); err != nil {
+				return nil, nil, err
+			}
+			outMask.Sub(outMask, pseudoMasks[i])
+		}
+		tx.PseudoOuts[i] = Commit(curve, new(big.Int).SetUint64(in.Amount), pseudoMasks[i])
+	}
+	rings := make([]Ring, len(inputs))
+	commitments := make([]Ring, len(inputs))
+	for i, in := range inputs {
+		rings[i], commitments[i] = in.Ring, in.Commitments
+	}
+	m := ringctHash(tx, rings, commitments)
+
+	tx.Inputs = make([]*TriptychSign, len(inputs))
+	for i, in := range inputs {
+		mask := new(big.Int).Sub(in.Mask, pseudoMasks[i])
+		mask.Mod(mask, N)
+		sig, err := SignTriptychCommitments(m, in.Ring, in.Commitments, tx.PseudoOuts[i], in.Key, mask, in.Index)
+		if err != nil {
+			return nil, nil, err
+		}
+		tx.Inputs[i] = sig
+	}
+	return tx, masks, nil
+}
+
+// RingCTSize returns an upper bound of the RLP encoded size of a RingCT
+// transaction on curve with inputs hidden in rings of ringSize members and
+// outputs outputs, without building its proofs. It encodes a transaction of
+// the same shape with scalars of full width.
+func RingCTSize(curve elliptic.Curve, ringSize, inputs, outputs int) (int, error) {
+	switch {
+	case ringSize < 2:
+		return 0, errRingTooSmall
+	case inputs < 1:
+		return 0, errNoInputs
+	case outputs < 1:
+		return 0, errNoOutputs
+	}
+	var (
+		params = curve.Params()
+		point  = &ecdsa.PublicKey{Curve: curve, X: params.Gx, Y: params.Gy}
+		scalar = new(big.Int).Sub(params.N, big.NewInt(1))
+	)
+	points := func(n int) Ring {
+		ring := make(Ring, n)
+		for i := range ring {
+			ring[i] = point
+		}
+		return ring
+	}
+	scalars := func(n int) []*big.Int {
+		s := make([]*big.Int, n)
+		for i := range s {
+			s[i] = scalar
+		}
+		return s
+	}
+	m := ringBits(ringSize)
+	tx := &RingCTTransaction{
+		Inputs:      make([]*TriptychSign, inputs),
+		PseudoOuts:  points(inputs),
+		Outputs:     points(outputs),
+		Commitments: points(outputs),
+		Fee:         math.MaxUint64,
+		Curve:       curve,
+	}
+	for i := range tx.Inputs {
+		tx.Inputs[i] = &TriptychSign{
+			Ring:        points(ringSize),
+			Commitments: points(ringSize),
+			Offset:      point,
+			I:           point,
+			K:           point,
+			Proof: &TriptychProof{
+				A: point, B: point, C: point, D: point,
+				X: points(m), Y: points(m),
+				F:  scalars(m),
+				ZA: scalar, ZC: scalar, Z: scalar,
+			},
+			Curve: curve,
+		}
+	}
+	rounds := ringBits(bulletproofBits * nextPow2(outputs))
+	tx.RangeProof = &RangeProof{
+		V: points(outputs),
+		A: point, S: point, T1: point, T2: point,
+		TauX: scalar, Mu: scalar, T: scalar,
+		L: points(rounds), R: points(rounds),
+		InnerA: scalar, InnerB: scalar,
+		Curve: curve,
+	}
+	enc, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return 0, err
+	}
+	return len(enc), nil
+}
+
+// VerifyRingCT verifies the signatures, the range proof and the balance of a
+// RingCT transaction. It does not check the key images against previously
+// spent outputs.
+// returns true if a valid transaction, false otherwise
+func VerifyRingCT(tx *RingCTTransaction) bool {
+	if tx == nil || tx.Curve == nil || len(tx.Inputs) == 0 || len(tx.Inputs) != len(tx.PseudoOuts) {
+		return false
+	}
+	if len(tx.Outputs) == 0 || len(tx.Commitments) != len(tx.Outputs) {
+		return false
+	}
+	// the range proof must cover exactly the output commitments
+	if tx.RangeProof == nil || tx.RangeProof.Curve != tx.Curve || len(tx.RangeProof.V) != len(tx.Commitments) {
+		return false
+	}
+	for i, c := range tx.Commitments {
+		if c == nil || !pointEqual(c, tx.RangeProof.V[i]) {
+			return false
+		}
+	}
+	if !VerifyRangeProof(tx.RangeProof) {
+		return false
+	}
+	// every input signs the transaction and balances against its pseudo output
+	rings := make([]Ring, len(tx.Inputs))
+	commitments := make([]Ring, len(tx.Inputs))
+	for i, sig := range tx.Inputs {
+		if sig == nil || sig.Curve != tx.Curve || sig.Commitments == nil || tx.PseudoOuts[i] == nil {
+			return false
+		}
+		rings[i], commitments[i] = sig.Ring, sig.Commitments
+	}
+	m := ringctHash(tx, rings, commitments)
+	for i, sig := range tx.Inputs {
+		if sig.M != m || !pointEqual(sig.Offset, tx.PseudoOuts[i]) || !VerifyTriptych(sig) {
+			return false
+		}
+		for _, prev := range tx.Inputs[:i] {
+			if LinkTriptych(prev, sig) {
+				return false
+			}
+		}
+	}
+	// sum(pseudo outputs) - sum(commitments) - fee*H = 0
+	var sum *ecdsa.PublicKey
+	for _, p := range tx.PseudoOuts {
+		sum = pointAdd(sum, p)
+	}
+	for _, c := range tx.Commitments {
+		sum = pointSub(sum, c)
+	}
+	sum = pointSub(sum, pointMul(GeneratorH(tx.Curve), new(big.Int).SetUint64(tx.Fee)))
+	return sum == nil
+}
@@ -0,0 +1,88 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// newRingCTInput creates an input spending amount hidden among decoys.
+func newRingCTInput(t *testing.T, amount uint64, size, index int) *RingCTInput {
+	curve := crypto.S256()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask, err := randomScalar(curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(size, key, index)
+	commitments := make(Ring, size)
+	for i := range commitments {
+		decoy, err := randomScalar(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitments[i] = Commit(curve, big.NewInt(int64(i)), decoy)
+	}
+	commitments[index] = Commit(curve, new(big.Int).SetUint64(amount), mask)
+	return &RingCTInput{Key: key, Amount: amount, Mask: mask, Ring: ring, Commitments: commitments, Index: index}
+}
+
+func TestRingCT(t *testing.T) {
+	inputs := []*RingCTInput{
+		newRingCTInput(t, 60, 4, 1),
+		newRingCTInput(t, 45, 4, 3),
+	}
+	var outputs []*RingCTOutput
+	for _, amount := range []uint64{70, 30} {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, &RingCTOutput{Key: &key.PublicKey, Amount: amount})
+	}
+	tx, masks, err := BuildRingCT(inputs, outputs, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyRingCT(tx) {
+		t.Fatal("valid transaction rejected")
+	}
+	// the returned masks open the output commitments
+	for i, out := range outputs {
+		if !pointEqual(tx.Commitments[i], Commit(crypto.S256(), new(big.Int).SetUint64(out.Amount), masks[i])) {
+			t.Errorf("mask %d does not open output commitment", i)
+		}
+	}
+	// raising the fee breaks the balance
+	tx.Fee++
+	if VerifyRingCT(tx) {
+		t.Error("unbalanced transaction accepted")
+	}
+	tx.Fee--
+
+	// the size estimate bounds the encoding
+	enc, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := RingCTSize(crypto.S256(), 4, len(inputs), len(outputs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size < len(enc) || size > len(enc)+40 {
+		t.Errorf("size estimate: have %d, encoded %d", size, len(enc))
+	}
+
+	if _, _, err := BuildRingCT(inputs, outputs, 6); err != errUnbalanced {
+		t.Errorf("overspend: have %v, want %v", err, errUnbalanced)
+	}
+	inputs[0].Amount++
+	if _, _, err := BuildRingCT(inputs, outputs, 6); err != errInputMismatch {
+		t.Errorf("wrong input amount: have %v, want %v", err, errInputMismatch)
+	}
+}
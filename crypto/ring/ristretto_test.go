@@ -0,0 +1,63 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/ristretto255"
+)
+
+// newRistrettoRing creates a ring of ristretto255 keys with the signer at s.
+func newRistrettoRing(t *testing.T, size, s int) (Ring, *ecdsa.PrivateKey) {
+	ring := make(Ring, size)
+	var signer *ecdsa.PrivateKey
+	for i := range ring {
+		key, err := ristretto255.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring[i] = &key.PublicKey
+		if i == s {
+			signer = key
+		}
+	}
+	return ring, signer
+}
+
+func TestRistretto(t *testing.T) {
+	ring, key := newRistrettoRing(t, 4, 2)
+	msg := [32]byte{0x25, 0x51, 0x9}
+
+	oom, err := SignOneOfMany(msg, ring, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyOneOfMany(oom) {
+		t.Error("one-of-many signature rejected")
+	}
+	trip, err := SignTriptych(msg, ring, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyTriptych(trip) {
+		t.Error("triptych signature rejected")
+	}
+	bor, err := SignBorromean(msg, []Ring{ring}, []*ecdsa.PrivateKey{key}, []int{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyBorromean(bor) {
+		t.Error("borromean signature rejected")
+	}
+	uniq, err := SignUnique(msg, ring, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyUnique(uniq) {
+		t.Error("unique signature rejected")
+	}
+	uniq.M[0]++
+	if VerifyUnique(uniq) {
+		t.Error("signature over different message accepted")
+	}
+}
@@ -0,0 +1,301 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// rlpRingSign is the RLP encoding of a RingSign. The curve is identified by
+// its registered name and all points are compressed. Signatures with a
+// transcript version other than the original carry it in a trailing element.
+type rlpRingSign struct {
+	Curve   string
+	M       [32]byte
+	C       *big.Int
+	S       []*big.Int
+	Ring    []byte
+	I       []byte
+	Version []uint `rlp:"tail"`
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (r *RingSign) EncodeRLP(w io.Writer) error {
+	name, err := CurveName(r.Curve)
+	if err != nil {
+		return err
+	}
+	enc := rlpRingSign{
+		Curve: name,
+		M:     r.M,
+		C:     r.C,
+		S:     r.S,
+		Ring:  r.Ring.Compress(),
+		I:     compressPoint(r.Curve, newPoint(r.Curve, r.I.X, r.I.Y)),
+	}
+	if v := encodedVersion(r); v != 0 {
+		enc.Version = []uint{uint(v)}
+	}
+	return rlp.Encode(w, enc)
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (r *RingSign) DecodeRLP(s *rlp.Stream) error {
+	var dec rlpRingSign
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	curve, err := CurveByName(dec.Curve)
+	if err != nil {
+		return err
+	}
+	ring, err := DecompressRing(curve, dec.Ring)
+	if err != nil {
+		return err
+	}
+	if len(ring) != len(dec.S) {
+		return errSignatureSize
+	}
+	image, err := decompressPoint(curve, dec.I)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return errInvalidPoint
+	}
+	var version byte
+	switch {
+	case len(dec.Version) > 1:
+		return errTranscriptVersion
+	case len(dec.Version) == 1:
+		if dec.Version[0] == 0 || dec.Version[0] > uint(TranscriptV3) {
+			return errTranscriptVersion
+		}
+		version = byte(dec.Version[0])
+	}
+	*r = RingSign{
+		Size:    len(ring),
+		M:       dec.M,
+		C:       dec.C,
+		S:       dec.S,
+		Ring:    ring,
+		I:       image,
+		Curve:   curve,
+		Version: version,
+	}
+	return nil
+}
+
+// rlpTriptych is the RLP encoding of a TriptychSign inside a RingCT
+// transaction. Points are compressed, point lists concatenated.
+type rlpTriptych struct {
+	M           [32]byte
+	Ring        []byte
+	Commitments []byte
+	Offset      []byte
+	I           []byte
+	K           []byte
+	A, B, C, D  []byte
+	X, Y        []byte
+	F           []*big.Int
+	ZA, ZC, Z   *big.Int
+}
+
+// rlpRangeProof is the RLP encoding of a RangeProof inside a RingCT
+// transaction.
+type rlpRangeProof struct {
+	V              []byte
+	A, S, T1, T2   []byte
+	TauX, Mu, T    *big.Int
+	L, R           []byte
+	InnerA, InnerB *big.Int
+}
+
+// rlpRingCT is the RLP encoding of a RingCTTransaction. The curve is
+// identified by its registered name and shared by all points.
+type rlpRingCT struct {
+	Curve       string
+	Inputs      []rlpTriptych
+	PseudoOuts  []byte
+	Outputs     []byte
+	Commitments []byte
+	RangeProof  rlpRangeProof
+	Fee         uint64
+}
+
+// compressPoints returns the concatenated compressed encodings of points on
+// curve, which may be empty.
+func compressPoints(curve elliptic.Curve, points Ring) []byte {
+	b := make([]byte, 0, len(points)*CompressedSize(curve))
+	for _, p := range points {
+		b = append(b, compressPoint(curve, p)...)
+	}
+	return b
+}
+
+// decompressPoints decodes points compressed by compressPoints, returning nil
+// for an empty encoding.
+func decompressPoints(curve elliptic.Curve, b []byte) (Ring, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return DecompressRing(curve, b)
+}
+
+// decompressOptional decodes a compressed point, or nil from an empty
+// encoding.
+func decompressOptional(curve elliptic.Curve, b []byte) (*ecdsa.PublicKey, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return decompressPoint(curve, b)
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (tx *RingCTTransaction) EncodeRLP(w io.Writer) error {
+	curve := tx.Curve
+	name, err := CurveName(curve)
+	if err != nil {
+		return err
+	}
+	optional := func(p *ecdsa.PublicKey) []byte {
+		if p == nil {
+			return nil
+		}
+		return compressPoint(curve, p)
+	}
+	enc := rlpRingCT{
+		Curve:       name,
+		Inputs:      make([]rlpTriptych, len(tx.Inputs)),
+		PseudoOuts:  compressPoints(curve, tx.PseudoOuts),
+		Outputs:     compressPoints(curve, tx.Outputs),
+		Commitments: compressPoints(curve, tx.Commitments),
+		Fee:         tx.Fee,
+	}
+	for i, sig := range tx.Inputs {
+		if sig == nil || sig.Proof == nil || sig.Curve != curve {
+			return errCurveMismatch
+		}
+		p := sig.Proof
+		enc.Inputs[i] = rlpTriptych{
+			M:           sig.M,
+			Ring:        compressPoints(curve, sig.Ring),
+			Commitments: compressPoints(curve, sig.Commitments),
+			Offset:      optional(sig.Offset),
+			I:           optional(sig.I),
+			K:           optional(sig.K),
+			A:           compressPoint(curve, p.A),
+			B:           compressPoint(curve, p.B),
+			C:           compressPoint(curve, p.C),
+			D:           compressPoint(curve, p.D),
+			X:           compressPoints(curve, p.X),
+			Y:           compressPoints(curve, p.Y),
+			F:           p.F,
+			ZA:          p.ZA,
+			ZC:          p.ZC,
+			Z:           p.Z,
+		}
+	}
+	if rp := tx.RangeProof; rp != nil {
+		enc.RangeProof = rlpRangeProof{
+			V:      compressPoints(curve, rp.V),
+			A:      compressPoint(curve, rp.A),
+			S:      compressPoint(curve, rp.S),
+			T1:     compressPoint(curve, rp.T1),
+			T2:     compressPoint(curve, rp.T2),
+			TauX:   rp.TauX,
+			Mu:     rp.Mu,
+			T:      rp.T,
+			L:      compressPoints(curve, rp.L),
+			R:      compressPoints(curve, rp.R),
+			InnerA: rp.InnerA,
+			InnerB: rp.InnerB,
+		}
+	}
+	return rlp.Encode(w, &enc)
+}
+
+// DecodeRLP implements rlp.Decoder. It only checks that all points are on the
+// curve, use VerifyRingCT to validate the transaction.
+func (tx *RingCTTransaction) DecodeRLP(s *rlp.Stream) error {
+	var dec rlpRingCT
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	curve, err := CurveByName(dec.Curve)
+	if err != nil {
+		return err
+	}
+	// decode every point, stopping at the first invalid one
+	point := func(b []byte) *ecdsa.PublicKey {
+		if err != nil {
+			return nil
+		}
+		var p *ecdsa.PublicKey
+		p, err = decompressOptional(curve, b)
+		return p
+	}
+	points := func(b []byte) Ring {
+		if err != nil {
+			return nil
+		}
+		var r Ring
+		r, err = decompressPoints(curve, b)
+		return r
+	}
+	out := RingCTTransaction{
+		Inputs:      make([]*TriptychSign, len(dec.Inputs)),
+		PseudoOuts:  points(dec.PseudoOuts),
+		Outputs:     points(dec.Outputs),
+		Commitments: points(dec.Commitments),
+		Fee:         dec.Fee,
+		Curve:       curve,
+	}
+	for i, in := range dec.Inputs {
+		out.Inputs[i] = &TriptychSign{
+			M:           in.M,
+			Ring:        points(in.Ring),
+			Commitments: points(in.Commitments),
+			Offset:      point(in.Offset),
+			I:           point(in.I),
+			K:           point(in.K),
+			Proof: &TriptychProof{
+				A:  point(in.A),
+				B:  point(in.B),
+				C:  point(in.C),
+				D:  point(in.D),
+				X:  points(in.X),
+				Y:  points(in.Y),
+				F:  in.F,
+				ZA: in.ZA,
+				ZC: in.ZC,
+				Z:  in.Z,
+			},
+			Curve: curve,
+		}
+	}
+	rp := dec.RangeProof
+	out.RangeProof = &RangeProof{
+		V:      points(rp.V),
+		A:      point(rp.A),
+		S:      point(rp.S),
+		T1:     point(rp.T1),
+		T2:     point(rp.T2),
+		TauX:   rp.TauX,
+		Mu:     rp.Mu,
+		T:      rp.T,
+		L:      points(rp.L),
+		R:      points(rp.R),
+		InnerA: rp.InnerA,
+		InnerB: rp.InnerB,
+		Curve:  curve,
+	}
+	if err != nil {
+		return err
+	}
+	*tx = out
+	return nil
+}
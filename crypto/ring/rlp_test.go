@@ -0,0 +1,61 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestRingSignRLP(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(3, key, 0)
+	sig, err := Sign([32]byte{8}, ring, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := rlp.EncodeToBytes(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec RingSign
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(&dec) {
+		t.Fatal("decoded signature rejected")
+	}
+	if !pointEqual(dec.I, newPoint(sig.Curve, sig.I.X, sig.I.Y)) {
+		t.Fatal("key image mismatch")
+	}
+}
+
+func TestRingCTRLP(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	inputs := []*RingCTInput{newRingCTInput(t, 50, 4, 2)}
+	outputs := []*RingCTOutput{{Key: &key.PublicKey, Amount: 40}}
+	tx, _, err := BuildRingCT(inputs, outputs, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec RingCTTransaction
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyRingCT(&dec) {
+		t.Fatal("decoded transaction rejected")
+	}
+	if !LinkTriptych(dec.Inputs[0], tx.Inputs[0]) {
+		t.Fatal("key image mismatch")
+	}
+	// corrupting a point must fail decoding
+	i := len(enc) - 40
+	enc[i] ^= 0xff
+	if err := rlp.DecodeBytes(enc, &dec); err == nil && VerifyRingCT(&dec) {
+		t.Fatal("corrupted transaction accepted")
+	}
+}
@@ -0,0 +1,90 @@
+package ring
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// The scheme registry describes the ring signature schemes of the package, so
+// that applications can pick one by name and check its properties, such as
+// whether it links signatures of the same signer or is only experimental.
+
+// Scheme describes a ring signature scheme.
+type Scheme struct {
+	Name         string
+	Linkable     bool // signatures of the same signer can be linked
+	PostQuantum  bool // security does not rely on discrete logarithms
+	Experimental bool // not fit for production use
+}
+
+var (
+	errUnknownScheme   = errors.New("unknown ring signature scheme")
+	errSchemeNameTaken = errors.New("scheme name already registered")
+)
+
+var (
+	schemesLock sync.RWMutex
+	schemes     = map[string]Scheme{
+		"lsag":           {Name: "lsag", Linkable: true},
+		"borromean":      {Name: "borromean"},
+		"one-of-many":    {Name: "one-of-many"},
+		"triptych":       {Name: "triptych", Linkable: true},
+		"threshold":      {Name: "threshold"},
+		"blind":          {Name: "blind"},
+		"traceable":      {Name: "traceable", Linkable: true},
+		"unique":         {Name: "unique", Linkable: true},
+		"election":       {Name: "election", Linkable: true},
+		"escrow":         {Name: "escrow"},
+		"group":          {Name: "group"},
+		"audited":        {Name: "audited", Linkable: true},
+		"designated":     {Name: "designated"},
+		"forward-secure": {Name: "forward-secure"},
+		"snark":          {Name: "snark"},
+		"bip340":         {Name: "bip340"},
+		"sr25519":        {Name: "sr25519"},
+		"lattice":        {Name: "lattice", PostQuantum: true, Experimental: true},
+	}
+)
+
+// RegisterScheme adds a scheme to the registry. Registering a name twice is an
+// error.
+func RegisterScheme(scheme Scheme) error {
+	schemesLock.Lock()
+	defer schemesLock.Unlock()
+
+	if _, ok := schemes[scheme.Name]; ok {
+		return errSchemeNameTaken
+	}
+	schemes[scheme.Name] = scheme
+	return nil
+}
+
+// SchemeByName returns the scheme registered under name.
+func SchemeByName(name string) (Scheme, error) {
+	schemesLock.RLock()
+	defer schemesLock.RUnlock()
+
+	scheme, ok := schemes[name]
+	if !ok {
+		return Scheme{}, errUnknownScheme
+	}
+	return scheme, nil
+}
+
+// Schemes returns all registered schemes sorted by name. Experimental schemes
+// are only included if experimental is set.
+func Schemes(experimental bool) []Scheme {
+	schemesLock.RLock()
+	defer schemesLock.RUnlock()
+
+	list := make([]Scheme, 0, len(schemes))
+	for _, scheme := range schemes {
+		if scheme.Experimental && !experimental {
+			continue
+		}
+		list = append(list, scheme)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
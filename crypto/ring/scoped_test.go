@@ -0,0 +1,53 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestScoped(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(4, key, 2)
+	sign := func(m byte, scope string, ring Ring, s int) *ScopedSign {
+		sig, err := SignScoped([32]byte{m}, []byte(scope), ring, key, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyScoped(sig) {
+			t.Fatal("valid signature rejected")
+		}
+		return sig
+	}
+	a, b, c := sign(1, "round 1", ring, 2), sign(2, "round 1", ring, 2), sign(1, "round 2", ring, 2)
+	if a.ImageID() != b.ImageID() {
+		t.Error("image differs for same scope")
+	}
+	if a.ImageID() == c.ImageID() {
+		t.Error("image equal for different scopes")
+	}
+	if !PublicKeyEqual(a.Image, ScopedKeyImage([]byte("round 1"), key)) {
+		t.Error("image mismatch with ScopedKeyImage")
+	}
+	// the image doesn't depend on the ring
+	other := GenNewKeyRing(3, key, 0)
+	if d := sign(3, "round 1", other, 0); d.ImageID() != a.ImageID() {
+		t.Error("image differs for another ring")
+	}
+	// changing the message or the scope invalidates the signature
+	a.M[0]++
+	if VerifyScoped(a) {
+		t.Error("signature with altered message accepted")
+	}
+	a.M[0]--
+	a.Scope = []byte("round 2")
+	if VerifyScoped(a) {
+		t.Error("signature with altered scope accepted")
+	}
+	if _, err := SignScoped([32]byte{}, nil, ring, key, 2); err != errEmptyScope {
+		t.Errorf("empty scope: have error %v, want %v", err, errEmptyScope)
+	}
+}
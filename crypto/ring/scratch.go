@@ -0,0 +1,126 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"hash"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Signing and verifying a linkable ring signature repeat the same few steps
+// for every ring member. The scratch space below is reused from one member to
+// the next and pooled between calls, so large rings do not produce garbage in
+// proportion to their size.
+
+// hashState is a SHA3 state that can be read from directly. Unlike Sum, Read
+// does not copy the state, so the state must be reset before reuse.
+type hashState interface {
+	hash.Hash
+	Read([]byte) (int, error)
+}
+
+// scratch holds the temporaries of a challenge chain computation.
+type scratch struct {
+	hash hashState
+	buf  []byte   // challenge hash input
+	sum  [32]byte // challenge hash output
+	t    [3]*big.Int
+}
+
+var scratchPool = sync.Pool{
+	New: func() interface{} {
+		return &scratch{
+			hash: sha3.New256().(hashState),
+			buf:  make([]byte, 0, 1+32+4*66),
+			t:    [3]*big.Int{new(big.Int), new(big.Int), new(big.Int)},
+		}
+	},
+}
+
+func getScratch() *scratch {
+	return scratchPool.Get().(*scratch)
+}
+
+func putScratch(sc *scratch) {
+	scratchPool.Put(sc)
+}
+
+// appendInt appends the minimal big-endian encoding of v to the hash input,
+// the same bytes as v.Bytes().
+func (sc *scratch) appendInt(v *big.Int) {
+	sc.appendPadded(v, (v.BitLen()+7)/8)
+}
+
+// appendPadded appends the big-endian encoding of v, padded to size bytes, to
+// the hash input.
+func (sc *scratch) appendPadded(v *big.Int, size int) {
+	start := len(sc.buf)
+	if cap(sc.buf) < start+size {
+		sc.buf = append(sc.buf, make([]byte, size)...)
+	} else {
+		sc.buf = sc.buf[:start+size]
+	}
+	math.ReadBits(v, sc.buf[start:])
+}
+
+// challenge returns H(m || L || R) for the points L = (lx, ly) and
+// R = (rx, ry) on curve, in the given transcript version: coordinates in
+// minimal big-endian form for TranscriptV1, and padded to the coordinate size
+// of the curve after the version byte for TranscriptV2 and TranscriptV3.
+func (sc *scratch) challenge(version byte, curve elliptic.Curve, m []byte, lx, ly, rx, ry *big.Int) *big.Int {
+	if version >= TranscriptV2 {
+		size := coordinateSize(curve)
+		sc.buf = append(sc.buf[:0], version)
+		sc.buf = append(sc.buf, m...)
+		sc.appendPadded(lx, size)
+		sc.appendPadded(ly, size)
+		sc.appendPadded(rx, size)
+		sc.appendPadded(ry, size)
+	} else {
+		sc.buf = append(sc.buf[:0], m...)
+		sc.appendInt(lx)
+		sc.appendInt(ly)
+		sc.appendInt(rx)
+		sc.appendInt(ry)
+	}
+
+	sc.hash.Reset()
+	sc.hash.Write(sc.buf)
+	sc.hash.Read(sc.sum[:])
+	return new(big.Int).SetBytes(sc.sum[:])
+}
+
+// add returns (x1, y1) + (x2, y2) in affine coordinates, as curve.Add. On
+// short Weierstrass curves the sum of distinct points is computed directly,
+// with a single inversion and the temporaries of the scratch space, instead of
+// going through Jacobian coordinates.
+func (sc *scratch) add(curve elliptic.Curve, x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if _, ok := curve.(groupCurve); ok || x1 == nil || x2 == nil || x1.Cmp(x2) == 0 {
+		return affine(pointAdd(newPoint(curve, x1, y1), newPoint(curve, x2, y2)))
+	}
+	p := curve.Params().P
+	lambda, t, u := sc.t[0], sc.t[1], sc.t[2]
+
+	// lambda = (y2 - y1) / (x2 - x1)
+	t.Sub(x2, x1)
+	t.ModInverse(t, p)
+	lambda.Sub(y2, y1)
+	lambda.Mul(lambda, t)
+	lambda.Mod(lambda, p)
+
+	// x3 = lambda^2 - x1 - x2
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, x1)
+	x3.Sub(x3, x2)
+	x3.Mod(x3, p)
+
+	// y3 = lambda * (x1 - x3) - y1
+	u.Sub(x1, x3)
+	u.Mul(u, lambda)
+	y3 := new(big.Int).Sub(u, y1)
+	y3.Mod(y3, p)
+	return x3, y3
+}
@@ -0,0 +1,53 @@
+package ring
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+func TestScratchAdd(t *testing.T) {
+	sc := getScratch()
+	defer putScratch(sc)
+
+	for _, curve := range []elliptic.Curve{crypto.S256(), elliptic.P256()} {
+		a, _ := randomScalar(curve)
+		b, _ := randomScalar(curve)
+		p, q := baseMul(curve, a), baseMul(curve, b)
+
+		x, y := sc.add(curve, p.X, p.Y, q.X, q.Y)
+		if !pointEqual(newPoint(curve, x, y), pointAdd(p, q)) {
+			t.Errorf("%s: wrong sum", curve.Params().Name)
+		}
+		x, y = sc.add(curve, p.X, p.Y, p.X, p.Y)
+		if !pointEqual(newPoint(curve, x, y), pointAdd(p, p)) {
+			t.Errorf("%s: wrong double", curve.Params().Name)
+		}
+		neg := pointNeg(p)
+		if x, y = sc.add(curve, p.X, p.Y, neg.X, neg.Y); x.Sign() != 0 || y.Sign() != 0 {
+			t.Errorf("%s: P - P is not (0, 0)", curve.Params().Name)
+		}
+	}
+}
+
+func TestScratchChallenge(t *testing.T) {
+	sc := getScratch()
+	defer putScratch(sc)
+
+	m := []byte("message")
+	vals := []*big.Int{big.NewInt(0), big.NewInt(0x1234), new(big.Int).Lsh(big.NewInt(1), 300), big.NewInt(7)}
+	for i := 0; i < 2; i++ {
+		var input []byte
+		input = append(input, m...)
+		for _, v := range vals {
+			input = append(input, v.Bytes()...)
+		}
+		want := sha3.Sum256(input)
+		if have := sc.challenge(TranscriptV1, crypto.S256(), m, vals[0], vals[1], vals[2], vals[3]); have.Cmp(new(big.Int).SetBytes(want[:])) != 0 {
+			t.Fatalf("challenge %d mismatch", i)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+)
+
+// Signing services that produce many signatures with the same key over a
+// fixed ring repeat the same setup for every one of them: finding the signer
+// in the ring, hashing every member to the curve and computing the key image.
+// A Session does that work once and leaves only the challenge chain to Sign.
+
+var errKeyNotInRing = errors.New("key is not a ring member")
+
+// Session signs messages with a fixed key over a fixed ring. It is safe for
+// concurrent use.
+type Session struct {
+	ring       Ring
+	key        *ecdsa.PrivateKey
+	index      int
+	image      *ecdsa.PublicKey
+	hashes     map[*ecdsa.PublicKey][2]*big.Int
+	compressed []byte
+}
+
+// NewSession prepares signing with privkey over ring, which must contain the
+// public key of privkey. The members are copied, so later changes to ring do
+// not affect the session.
+func NewSession(ring Ring, privkey *ecdsa.PrivateKey) (*Session, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	for _, pub := range ring {
+		if pub == nil || pub.Curve != privkey.Curve {
+			return nil, errCurveMismatch
+		}
+	}
+	if err := ring.Validate(); err != nil {
+		return nil, err
+	}
+	sess := &Session{
+		ring:   append(Ring{}, ring...),
+		key:    privkey,
+		index:  ring.Index(&privkey.PublicKey),
+		hashes: make(map[*ecdsa.PublicKey][2]*big.Int, len(ring)),
+	}
+	if sess.index < 0 {
+		return nil, errKeyNotInRing
+	}
+	hashes := make([][2]*big.Int, len(ring))
+	if pre := lookupPrecomputed(ring); pre != nil {
+		copy(hashes, pre.hashesOf(DefaultTranscript))
+	} else {
+		parallel(len(ring), func(i int) {
+			hashes[i][0], hashes[i][1] = HashToPoint(sess.ring[i])
+		})
+	}
+	for i, pub := range sess.ring {
+		sess.hashes[pub] = hashes[i]
+	}
+	h := hashes[sess.index]
+	ix, iy := privkey.Curve.ScalarMult(h[0], h[1], privkey.D.Bytes())
+	sess.image = &ecdsa.PublicKey{Curve: privkey.Curve, X: ix, Y: iy}
+	sess.compressed = sess.ring.Compress()
+	return sess, nil
+}
+
+// hashPoint returns the hash of a ring member to the curve.
+func (sess *Session) hashPoint(pub *ecdsa.PublicKey) (*big.Int, *big.Int) {
+	if h, ok := sess.hashes[pub]; ok {
+		return h[0], h[1]
+	}
+	return HashToPoint(pub)
+}
+
+// Sign creates a ring signature of m.
+func (sess *Session) Sign(m [32]byte) (*RingSign, error) {
+	u, S, err := signingRandomness(m, sess.ring, sess.key, sess.index, HedgedNonces)
+	if err != nil {
+		return nil, err
+	}
+	return signWithImage(m, sess.ring, sess.key, sess.index, u, S, sess.image, sess.hashPoint, DefaultTranscript)
+}
+
+// Ring returns the ring of the session.
+func (sess *Session) Ring() Ring {
+	return append(Ring{}, sess.ring...)
+}
+
+// CompressedRing returns the compressed encoding of the ring, as attached to
+// signatures sent over the wire.
+func (sess *Session) CompressedRing() []byte {
+	return append([]byte{}, sess.compressed...)
+}
+
+// Index returns the position of the signer in the ring.
+func (sess *Session) Index() int {
+	return sess.index
+}
+
+// KeyImage returns the key image shared by all signatures of the session.
+func (sess *Session) KeyImage() KeyImage {
+	return KeyImage{sess.image}
+}
@@ -0,0 +1,77 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSession(t *testing.T) {
+	ring, keys := testRing(t, 4)
+	// the signer's key need not be the same pointer
+	pub := keys[2].PublicKey
+	ring[2] = &pub
+
+	sess, err := NewSession(ring, keys[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring[0] = ring[1] // does not affect the session
+
+	if sess.Index() != 2 {
+		t.Errorf("signer index %d, want 2", sess.Index())
+	}
+	image := KeyImage{GenKeyImage(keys[2])}
+	if !sess.KeyImage().Equal(image) {
+		t.Error("wrong key image")
+	}
+	for i := 0; i < 3; i++ {
+		sig, err := sess.Sign([32]byte{byte(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(sig) {
+			t.Errorf("signature %d does not verify", i)
+		}
+		if !sig.KeyImage().Equal(image) {
+			t.Errorf("signature %d has wrong key image", i)
+		}
+	}
+	decoded, err := DecompressRing(crypto.S256(), sess.CompressedRing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pub := range sess.Ring() {
+		if !pointEqual(pub, decoded[i]) {
+			t.Errorf("compressed ring member %d differs", i)
+		}
+	}
+}
+
+func TestSessionErrors(t *testing.T) {
+	ring, _ := testRing(t, 3)
+	key, _ := crypto.GenerateKey()
+	if _, err := NewSession(ring, key); err != errKeyNotInRing {
+		t.Errorf("outside key: got %v, want %v", err, errKeyNotInRing)
+	}
+	if _, err := NewSession(Ring{&key.PublicKey}, key); err != errRingTooSmall {
+		t.Errorf("small ring: got %v, want %v", err, errRingTooSmall)
+	}
+	if _, err := NewSession(Ring{&key.PublicKey, nil}, key); err != errCurveMismatch {
+		t.Errorf("nil member: got %v, want %v", err, errCurveMismatch)
+	}
+}
+
+func BenchmarkSession(b *testing.B) {
+	ring, keys := testRing(b, 16)
+	sess, err := NewSession(ring, keys[3])
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := sess.Sign([32]byte{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// A ring signing key can be split into n Shamir shares so that any t of them
+// can sign, while fewer learn nothing about the key. Shares carry Feldman
+// commitments a_j*G to the coefficients a_j of the sharing polynomial f, with
+// f(0) the private key x, so every holder can check its share and anyone can
+// compute the public share X_i = f(i)*G of holder i.
+//
+// Signing never reconstructs x. With Lagrange coefficients l_i of the t
+// signing shares, x = sum l_i*x_i, and the signer's step of the ring is split
+// among them:
+//
+//	I   = sum l_i*x_i*H_p(P)              key image
+//	L_s = sum u_i*G, R_s = sum u_i*H_p(P) nonce commitments
+//	s_s = sum (u_i - c_s*l_i*x_i)         closing response
+//
+// Every holder is given the message and the ring it signs for. Signing takes
+// three rounds, with a ShareSession relaying the messages:
+//
+//	holders -> session  ShareCommitment  hash of the holder's nonces
+//	holders -> session  ShareNonces      partial key image I_i and nonce
+//	                                     commitments, once all hashes are in
+//	session -> holders  ShareChallenge   all nonces and the decoy responses
+//	holders -> session  z_i = u_i - c_s*l_i*x_i
+//
+// Committing to the nonces before any are revealed keeps a holder or the
+// session from choosing nonces as a function of the others', and every holder
+// recomputes the challenge chain over its own message and ring rather than
+// answering a challenge it is handed, as in two-party signing. The session
+// checks every partial response z_i against
+//
+//	z_i*G + c_s*l_i*X_i = u_i*G, z_i*H_p(P) + c_s*l_i*I_i = u_i*H_p(P)
+//
+// which identifies a holder that sent a wrong partial key image or response.
+// Nonces are used for one session only, concurrent sessions must use fresh
+// ShareSigners.
+
+// shareDomain separates the hashes of threshold signing from other hashes in
+// the package.
+var shareDomain = []byte("go-ethereum/crypto/ring shamir")
+
+var (
+	errShareThreshold = errors.New("threshold out of range of share count")
+	errShareCount     = errors.New("not enough shares")
+	errShareMismatch  = errors.New("shares belong to different keys")
+	errShareIndex     = errors.New("duplicate or invalid share index")
+	errInvalidShare   = errors.New("share does not match its commitments")
+)
+
+// KeyShare is a Shamir share of a private key.
+type KeyShare struct {
+	Index       int                // evaluation point of the share, 1 to n
+	Value       *big.Int           // the share f(Index)
+	Commitments []*ecdsa.PublicKey // commitments to the polynomial coefficients
+}
+
+// SplitKey splits key into n shares, any t of which can sign for it.
+func SplitKey(key *ecdsa.PrivateKey, t, n int) ([]*KeyShare, error) {
+	if t < 1 || t > n {
+		return nil, errShareThreshold
+	}
+	curve := key.Curve
+	N := curve.Params().N
+
+	coeffs := []*big.Int{new(big.Int).Set(key.D)}
+	for len(coeffs) < t {
+		a, err := randomScalar(curve)
+		if err != nil {
+			return nil, err
+		}
+		coeffs = append(coeffs, a)
+	}
+	commitments := make([]*ecdsa.PublicKey, t)
+	for j, a := range coeffs {
+		commitments[j] = baseMul(curve, a)
+	}
+	shares := make([]*KeyShare, n)
+	for i := range shares {
+		shares[i] = &KeyShare{
+			Index:       i + 1,
+			Value:       polyEval(coeffs, big.NewInt(int64(i+1)), N),
+			Commitments: commitments,
+		}
+	}
+	return shares, nil
+}
+
+// PublicKey returns the public key of the shared private key.
+func (s *KeyShare) PublicKey() *ecdsa.PublicKey {
+	return s.Commitments[0]
+}
+
+// Threshold returns the number of shares needed to sign.
+func (s *KeyShare) Threshold() int {
+	return len(s.Commitments)
+}
+
+// Verify reports whether the share matches its commitments.
+func (s *KeyShare) Verify() bool {
+	if s.Index < 1 || s.Value == nil || len(s.Commitments) == 0 || s.Commitments[0] == nil {
+		return false
+	}
+	return pointEqual(baseMul(s.Commitments[0].Curve, s.Value), sharePublic(s.Commitments, s.Index))
+}
+
+// sharePublic computes X_i = f(i)*G from the commitments to f.
+func sharePublic(commitments []*ecdsa.PublicKey, index int) *ecdsa.PublicKey {
+	x := big.NewInt(int64(index))
+
+	var acc *ecdsa.PublicKey
+	for j := len(commitments) - 1; j >= 0; j-- {
+		acc = pointAdd(pointMul(acc, x), commitments[j])
+	}
+	return acc
+}
+
+// partialValid checks the response z of a party with public share X and
+// partial key image I for the hash point h against its nonce commitments U and
+// V, given its weighted challenge c.
+func partialValid(h, X, I, U, V *ecdsa.PublicKey, z, c *big.Int) bool {
+	return pointEqual(pointAdd(baseMul(h.Curve, z), pointMul(X, c)), U) &&
+		pointEqual(pointAdd(pointMul(h, z), pointMul(I, c)), V)
+}
+
+// lagrangeAt0 returns the Lagrange coefficient at zero of the share with
+// index xs[i] among the shares with indices xs.
+func lagrangeAt0(xs []int, i int, N *big.Int) *big.Int {
+	num, den := big.NewInt(1), big.NewInt(1)
+	for j, x := range xs {
+		if j == i {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(x)))
+		den.Mul(den, big.NewInt(int64(x-xs[i])))
+	}
+	den.Mod(den, N)
+	num.Mul(num, den.ModInverse(den, N))
+	return num.Mod(num, N)
+}
+
+// checkShareIndices returns an error unless the shares are at least as many as
+// their threshold, have distinct indices and belong to the same key.
+func checkShareIndices(shares []*KeyShare) ([]int, error) {
+	if len(shares) == 0 || len(shares) < shares[0].Threshold() {
+		return nil, errShareCount
+	}
+	xs := make([]int, len(shares))
+	for i, s := range shares {
+		if !PublicKeyEqual(s.PublicKey(), shares[0].PublicKey()) {
+			return nil, errShareMismatch
+		}
+		xs[i] = s.Index
+	}
+	sorted := append([]int{}, xs...)
+	sort.Ints(sorted)
+	for i, x := range sorted {
+		if x < 1 || (i > 0 && x == sorted[i-1]) {
+			return nil, errShareIndex
+		}
+	}
+	return xs, nil
+}
+
+// CombineShares reconstructs the private key from at least threshold shares.
+func CombineShares(shares []*KeyShare) (*ecdsa.PrivateKey, error) {
+	xs, err := checkShareIndices(shares)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range shares {
+		if !s.Verify() {
+			return nil, errInvalidShare
+		}
+	}
+	pub := shares[0].PublicKey()
+	N := pub.Curve.Params().N
+	d := new(big.Int)
+	for i, s := range shares {
+		d.Add(d, new(big.Int).Mul(lagrangeAt0(xs, i, N), s.Value))
+	}
+	d.Mod(d, N)
+	return &ecdsa.PrivateKey{PublicKey: *pub, D: d}, nil
+}
+
+// ShareCommitment is the first message of a share holder in a signing
+// session: a hash of its nonces.
+type ShareCommitment struct {
+	Index      int      // index of the share
+	Commitment [32]byte // hash of the holder's ShareNonces
+}
+
+// ShareNonces is the second message of a share holder, sent once it has seen
+// the commitments of all signers.
+type ShareNonces struct {
+	Index int              // index of the share
+	Image *ecdsa.PublicKey // partial key image x_i*H_p(P)
+	U     *ecdsa.PublicKey // nonce commitment u_i*G
+	V     *ecdsa.PublicKey // nonce commitment u_i*H_p(P)
+}
+
+// commitment returns the hash a holder commits to its nonces with.
+func (n *ShareNonces) commitment() (h [32]byte) {
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], uint64(n.Index))
+
+	hw := sha3.NewKeccak256()
+	hw.Write(shareDomain)
+	hw.Write(index[:])
+	hw.Write(pointBytes(n.Image))
+	hw.Write(pointBytes(n.U))
+	hw.Write(pointBytes(n.V))
+	hw.Sum(h[:0])
+	return h
+}
+
+// ShareChallenge is sent by the session to every holder: the nonces of all
+// signers, in the order of the signer indices, and the responses of the
+// decoys.
+type ShareChallenge struct {
+	Nonces []*ShareNonces
+	S      []*big.Int
+}
+
+// shareChallenges combines the nonces of the signers and computes the key
+// image and the challenge chain of a session.
+func shareChallenges(m [32]byte, ring Ring, s int, S []*big.Int, signers []int, nonces []*ShareNonces) (*ecdsa.PublicKey, []*big.Int, error) {
+	curve := ring[s].Curve
+	N := curve.Params().N
+	var image, L, R *ecdsa.PublicKey
+	for i, n := range nonces {
+		image = pointAdd(image, pointMul(n.Image, lagrangeAt0(signers, i, N)))
+		L, R = pointAdd(L, n.U), pointAdd(R, n.V)
+	}
+	if image == nil || L == nil || R == nil {
+		return nil, nil, errInvalidResponse
+	}
+	sc := getScratch()
+	defer putScratch(sc)
+
+	return image, ringChallenges(sc, curve, m, ring, s, S, image, HashToPoint, DefaultTranscript, L.X, L.Y, R.X, R.Y), nil
+}
+
+// checkShareNonces returns an error unless nonces holds the well-formed
+// nonces of the signers, in order, matching their commitments.
+func checkShareNonces(nonces []*ShareNonces, signers []int, commitments []*ShareCommitment) error {
+	if len(nonces) != len(signers) {
+		return errCommitmentsCount
+	}
+	for i, n := range nonces {
+		if n == nil || n.Index != signers[i] || n.Image == nil || n.U == nil || n.V == nil {
+			return errShareIndex
+		}
+		if n.commitment() != commitments[i].Commitment {
+			return errNonceCommitment
+		}
+	}
+	return nil
+}
+
+// checkShareCommitments returns an error unless commitments holds the
+// commitments of the signers, in order.
+func checkShareCommitments(commitments []*ShareCommitment, signers []int) error {
+	if len(commitments) != len(signers) {
+		return errCommitmentsCount
+	}
+	for i, c := range commitments {
+		if c == nil || c.Index != signers[i] {
+			return errShareIndex
+		}
+	}
+	return nil
+}
+
+// ShareSigner is the state a share holder keeps during a signing session.
+type ShareSigner struct {
+	share   *KeyShare
+	m       [32]byte
+	ring    Ring
+	index   int
+	signers []int
+	at      int // position of the share among the signers
+	h       *ecdsa.PublicKey
+
+	u           *big.Int
+	own         *ShareNonces
+	commitments []*ShareCommitment
+}
+
+// NewShareSigner starts a signature over m by the holder of share, together
+// with the holders of the shares with the given indices, and returns the
+// commitment to hand to the session.
+func NewShareSigner(share *KeyShare, m [32]byte, ring Ring, signers []int) (*ShareSigner, *ShareCommitment, error) {
+	if !share.Verify() {
+		return nil, nil, errInvalidShare
+	}
+	if len(ring) < 2 {
+		return nil, nil, errRingTooSmall
+	}
+	if err := ring.Validate(); err != nil {
+		return nil, nil, err
+	}
+	pub := share.PublicKey()
+	index := ring.Index(pub)
+	if index < 0 {
+		return nil, nil, errKeyNotInRing
+	}
+	shares := make([]*KeyShare, len(signers))
+	at := -1
+	for i, x := range signers {
+		shares[i] = &KeyShare{Index: x, Commitments: share.Commitments}
+		if x == share.Index {
+			at = i
+		}
+	}
+	if _, err := checkShareIndices(shares); err != nil {
+		return nil, nil, err
+	}
+	if at < 0 {
+		return nil, nil, errShareIndex
+	}
+	u, err := randomScalar(pub.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := hashPointOf(pub)
+	own := &ShareNonces{
+		Index: share.Index,
+		Image: pointMul(h, share.Value),
+		U:     baseMul(pub.Curve, u),
+		V:     pointMul(h, u),
+	}
+	s := &ShareSigner{
+		share:   share,
+		m:       m,
+		ring:    append(Ring{}, ring...),
+		index:   index,
+		signers: append([]int{}, signers...),
+		at:      at,
+		h:       h,
+		u:       u,
+		own:     own,
+	}
+	return s, &ShareCommitment{Index: share.Index, Commitment: own.commitment()}, nil
+}
+
+// Reveal takes the commitments of all signers, in the order of the signer
+// indices, and returns the holder's nonces.
+func (s *ShareSigner) Reveal(commitments []*ShareCommitment) (*ShareNonces, error) {
+	if s.commitments != nil || s.u == nil {
+		return nil, errSessionState
+	}
+	if err := checkShareCommitments(commitments, s.signers); err != nil {
+		return nil, err
+	}
+	if commitments[s.at].Commitment != s.own.commitment() {
+		return nil, errNonceCommitment
+	}
+	s.commitments = append([]*ShareCommitment{}, commitments...)
+	own := *s.own
+	return &own, nil
+}
+
+// Respond checks the nonces of the signers against their commitments,
+// recomputes the challenge chain of the holder's message and ring and returns
+// its partial response. The nonce is erased afterwards so it cannot be reused.
+func (s *ShareSigner) Respond(msg *ShareChallenge) (*big.Int, error) {
+	if s.commitments == nil || s.u == nil {
+		return nil, errSessionState
+	}
+	if msg == nil || len(msg.S) != len(s.ring) {
+		return nil, errInvalidResponse
+	}
+	if err := checkShareNonces(msg.Nonces, s.signers, s.commitments); err != nil {
+		return nil, err
+	}
+	N := s.h.Curve.Params().N
+	for i, si := range msg.S {
+		if i != s.index && (si == nil || si.Sign() < 0 || si.Cmp(N) >= 0) {
+			return nil, errScalarRange
+		}
+	}
+	_, C, err := shareChallenges(s.m, s.ring, s.index, msg.S, s.signers, msg.Nonces)
+	if err != nil {
+		return nil, err
+	}
+	z := new(big.Int).Mul(C[s.index], lagrangeAt0(s.signers, s.at, N))
+	z.Mul(z, s.share.Value)
+	z.Sub(s.u, z)
+	s.u = nil
+	return z.Mod(z, N), nil
+}
+
+// ShareSession coordinates a signature by the holders of shares of a ring
+// member's key. It holds no secrets and may be run by any party.
+type ShareSession struct {
+	m           [32]byte
+	ring        Ring
+	index       int
+	commitments []*ecdsa.PublicKey
+	signers     []int
+	h           *ecdsa.PublicKey
+
+	nonceCommitments []*ShareCommitment
+	nonces           []*ShareNonces
+	image            *ecdsa.PublicKey
+	challenges       []*big.Int
+	responses        []*big.Int
+}
+
+// NewShareSession prepares a signature over m by the holders of the shares
+// with the given indices, for the ring member whose key was shared with the
+// given commitments.
+func NewShareSession(m [32]byte, ring Ring, commitments []*ecdsa.PublicKey, signers []int) (*ShareSession, error) {
+	if len(commitments) == 0 || commitments[0] == nil {
+		return nil, errShareMismatch
+	}
+	if err := ring.Validate(); err != nil {
+		return nil, err
+	}
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	pub := commitments[0]
+	index := ring.Index(pub)
+	if index < 0 {
+		return nil, errKeyNotInRing
+	}
+	shares := make([]*KeyShare, len(signers))
+	for i, x := range signers {
+		shares[i] = &KeyShare{Index: x, Commitments: commitments}
+	}
+	if _, err := checkShareIndices(shares); err != nil {
+		return nil, err
+	}
+	return &ShareSession{
+		m:           m,
+		ring:        append(Ring{}, ring...),
+		index:       index,
+		commitments: commitments,
+		signers:     append([]int{}, signers...),
+		h:           hashPointOf(pub),
+	}, nil
+}
+
+// Signers returns the indices of the signing shares, to pass to
+// NewShareSigner.
+func (s *ShareSession) Signers() []int {
+	return append([]int{}, s.signers...)
+}
+
+// Commit takes the commitments of the signers, given in the order of the
+// signer indices. They are relayed to every holder before any of them reveals
+// its nonces.
+func (s *ShareSession) Commit(commitments []*ShareCommitment) error {
+	if s.nonceCommitments != nil {
+		return errSessionState
+	}
+	if err := checkShareCommitments(commitments, s.signers); err != nil {
+		return err
+	}
+	s.nonceCommitments = append([]*ShareCommitment{}, commitments...)
+	return nil
+}
+
+// Challenge checks the revealed nonces of the signers, given in the order of
+// the signer indices, picks the responses of the decoys and returns the
+// message every signer responds to.
+func (s *ShareSession) Challenge(nonces []*ShareNonces) (*ShareChallenge, error) {
+	if s.nonceCommitments == nil || s.nonces != nil {
+		return nil, errSessionState
+	}
+	if err := checkShareNonces(nonces, s.signers, s.nonceCommitments); err != nil {
+		return nil, err
+	}
+	curve := s.h.Curve
+	S := make([]*big.Int, len(s.ring))
+	for i := range S {
+		if i == s.index {
+			continue
+		}
+		var err error
+		if S[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+	}
+	image, C, err := shareChallenges(s.m, s.ring, s.index, S, s.signers, nonces)
+	if err != nil {
+		return nil, err
+	}
+	s.nonces, s.image, s.challenges, s.responses = append([]*ShareNonces{}, nonces...), image, C, S
+	return &ShareChallenge{Nonces: s.nonces, S: append([]*big.Int{}, S...)}, nil
+}
+
+// Finalize checks the responses of the signers, given in the order of the
+// signer indices, and returns the ring signature.
+func (s *ShareSession) Finalize(responses []*big.Int) (*RingSign, error) {
+	if s.nonces == nil {
+		return nil, errSessionState
+	}
+	if len(responses) != len(s.signers) {
+		return nil, errResponsesCount
+	}
+	curve := s.h.Curve
+	N := curve.Params().N
+	closing := new(big.Int)
+	for i, z := range responses {
+		if z == nil || z.Sign() < 0 || z.Cmp(N) >= 0 {
+			return nil, errInvalidResponse
+		}
+		n := s.nonces[i]
+		cl := new(big.Int).Mul(s.challenges[s.index], lagrangeAt0(s.signers, i, N))
+		X := sharePublic(s.commitments, n.Index)
+		if !partialValid(s.h, X, n.Image, n.U, n.V, z, cl) {
+			return nil, errInvalidResponse
+		}
+		closing.Add(closing, z)
+	}
+	S := append([]*big.Int{}, s.responses...)
+	S[s.index] = closing.Mod(closing, N)
+
+	return &RingSign{
+		Size:    len(s.ring),
+		M:       s.m,
+		C:       s.challenges[0],
+		S:       S,
+		Ring:    s.ring,
+		I:       s.image,
+		Curve:   curve,
+		Version: DefaultTranscript,
+	}, nil
+}
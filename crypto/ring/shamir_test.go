@@ -0,0 +1,170 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSplitKey(t *testing.T) {
+	_, keys := testRing(t, 1)
+	shares, err := SplitKey(keys[0], 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range shares {
+		if !s.Verify() {
+			t.Fatalf("share %d does not verify", s.Index)
+		}
+	}
+	key, err := CombineShares([]*KeyShare{shares[4], shares[0], shares[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.D.Cmp(keys[0].D) != 0 {
+		t.Error("wrong key reconstructed")
+	}
+	if _, err := CombineShares(shares[:2]); err != errShareCount {
+		t.Errorf("too few shares: got %v, want %v", err, errShareCount)
+	}
+	if _, err := CombineShares([]*KeyShare{shares[0], shares[1], shares[1]}); err != errShareIndex {
+		t.Errorf("duplicate shares: got %v, want %v", err, errShareIndex)
+	}
+	bad := *shares[3]
+	bad.Value = new(big.Int).Add(bad.Value, big.NewInt(1))
+	if bad.Verify() {
+		t.Error("modified share verifies")
+	}
+	if _, err := SplitKey(keys[0], 4, 3); err != errShareThreshold {
+		t.Errorf("threshold above count: got %v, want %v", err, errShareThreshold)
+	}
+}
+
+// shareHolders starts a signature over m by the holders of shares and returns
+// them with their commitments.
+func shareHolders(t *testing.T, m [32]byte, ring Ring, shares []*KeyShare) ([]*ShareSigner, []*ShareCommitment) {
+	signers := make([]int, len(shares))
+	for i, s := range shares {
+		signers[i] = s.Index
+	}
+	holders := make([]*ShareSigner, len(shares))
+	commitments := make([]*ShareCommitment, len(shares))
+	for i, s := range shares {
+		var err error
+		if holders[i], commitments[i], err = NewShareSigner(s, m, ring, signers); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return holders, commitments
+}
+
+// runShareSession relays the messages between the holders and a session, and
+// returns the partial responses of the holders.
+func runShareSession(t *testing.T, sess *ShareSession, holders []*ShareSigner, commitments []*ShareCommitment) ([]*big.Int, error) {
+	if err := sess.Commit(commitments); err != nil {
+		t.Fatal(err)
+	}
+	nonces := make([]*ShareNonces, len(holders))
+	for i, h := range holders {
+		var err error
+		if nonces[i], err = h.Reveal(commitments); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msg, err := sess.Challenge(nonces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := make([]*big.Int, len(holders))
+	for i, h := range holders {
+		if responses[i], err = h.Respond(msg); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+// signWithShares runs a signing session with the given shares, corrupting the
+// response of the share at index corrupt if it is not negative.
+func signWithShares(t *testing.T, m [32]byte, ring Ring, shares []*KeyShare, corrupt int) (*RingSign, error) {
+	holders, commitments := shareHolders(t, m, ring, shares)
+	sess, err := NewShareSession(m, ring, shares[0].Commitments, holders[0].signers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses, err := runShareSession(t, sess, holders, commitments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corrupt >= 0 {
+		responses[corrupt] = new(big.Int).Add(responses[corrupt], big.NewInt(1))
+	}
+	return sess.Finalize(responses)
+}
+
+func TestShareSession(t *testing.T) {
+	ring, keys := testRing(t, 4)
+	shares, err := SplitKey(keys[1], 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signWithShares(t, [32]byte{1}, ring, []*KeyShare{shares[2], shares[0]}, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(sig) {
+		t.Fatal("signature does not verify")
+	}
+	if !sig.KeyImage().Equal(KeyImage{GenKeyImage(keys[1])}) {
+		t.Error("wrong key image")
+	}
+	if _, err := signWithShares(t, [32]byte{1}, ring, shares[:2], 1); err != errInvalidResponse {
+		t.Errorf("corrupt response: got %v, want %v", err, errInvalidResponse)
+	}
+	if _, err := NewShareSession([32]byte{}, ring, shares[0].Commitments, []int{1}); err != errShareCount {
+		t.Errorf("too few signers: got %v, want %v", err, errShareCount)
+	}
+}
+
+func TestShareSessionOtherMessage(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	shares, err := SplitKey(keys[0], 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a session signing another message than the holders were asked to
+	// does not get a response to its challenge
+	holders, commitments := shareHolders(t, [32]byte{1}, ring, shares[:2])
+	sess, err := NewShareSession([32]byte{2}, ring, shares[0].Commitments, holders[0].signers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses, err := runShareSession(t, sess, holders, commitments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sess.Finalize(responses); err != errInvalidResponse {
+		t.Errorf("other message: got %v, want %v", err, errInvalidResponse)
+	}
+
+	// nonces swapped after the commitments are refused
+	holders, commitments = shareHolders(t, [32]byte{1}, ring, shares[:2])
+	nonces := make([]*ShareNonces, len(holders))
+	for i, h := range holders {
+		if nonces[i], err = h.Reveal(commitments); err != nil {
+			t.Fatal(err)
+		}
+	}
+	forged := *nonces[1]
+	forged.U = pointAdd(forged.U, baseMul(forged.U.Curve, big.NewInt(1)))
+	S := make([]*big.Int, len(ring))
+	for i := range S {
+		S[i] = big.NewInt(int64(i + 1))
+	}
+	msg := &ShareChallenge{Nonces: []*ShareNonces{nonces[0], &forged}, S: S}
+	if _, err := holders[0].Respond(msg); err != errNonceCommitment {
+		t.Errorf("forged nonces: got %v, want %v", err, errNonceCommitment)
+	}
+	if _, err := holders[0].Reveal(commitments); err != errSessionState {
+		t.Errorf("second reveal: got %v, want %v", err, errSessionState)
+	}
+}
@@ -0,0 +1,157 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/babyjub"
+	"github.com/ethereum/go-ethereum/crypto/poseidon"
+)
+
+// SNARK-friendly ring signatures instantiate the AOS ring signature over the
+// BabyJubjub curve with Poseidon as the hash function. Both are native to the
+// BN254 scalar field, so the verification relation can be proven in a
+// zk-SNARK over BN254 with a few constraints per ring member, and a contract
+// only has to check the succinct proof however large the ring is.
+//
+// The relation a circuit has to enforce is:
+//
+//	h_0     = Poseidon(tag, m_hi, m_lo)
+//	h_{i+1} = Poseidon(h_i, P_i.x, P_i.y)          for every ring member
+//	L_i     = s_i*G + c_i*P_i
+//	c_{i+1} = Poseidon(h_n, L_i.x, L_i.y)          with c_n = c_0
+//
+// where m_hi and m_lo are the upper and lower 16 bytes of the message. The
+// challenges are field elements of BN254 and are used as scalars unreduced.
+
+// snarkTag is the Poseidon domain tag of the ring digest, "ring" as a number.
+var snarkTag = new(big.Int).SetBytes([]byte("ring"))
+
+var errWrongCurve = errors.New("ring member not on the BabyJubjub curve")
+
+// SNARKSign is an AOS ring signature over BabyJubjub with Poseidon challenges.
+type SNARKSign struct {
+	M     [32]byte   // message
+	Ring  Ring       // array of BabyJubjub public keys
+	C     *big.Int   // challenge of the first ring member
+	S     []*big.Int // responses, one per ring member
+	Curve elliptic.Curve
+}
+
+// snarkCoords returns the affine coordinates of p, mapping the point at
+// infinity to the BabyJubjub neutral element (0, 1).
+func snarkCoords(p *ecdsa.PublicKey) (*big.Int, *big.Int) {
+	if p == nil {
+		return new(big.Int), big.NewInt(1)
+	}
+	return p.X, p.Y
+}
+
+// snarkDigest folds the message and the ring into a single field element.
+func snarkDigest(m [32]byte, ring Ring) (*big.Int, error) {
+	h, err := poseidon.Hash(snarkTag, new(big.Int).SetBytes(m[:16]), new(big.Int).SetBytes(m[16:]))
+	if err != nil {
+		return nil, err
+	}
+	for _, pub := range ring {
+		if pub == nil || pub.Curve != babyjub.BabyJub() {
+			return nil, errWrongCurve
+		}
+		if h, err = poseidon.Hash(h, pub.X, pub.Y); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// snarkChallenge computes the challenge following commitment L.
+func snarkChallenge(digest *big.Int, L *ecdsa.PublicKey) (*big.Int, error) {
+	x, y := snarkCoords(L)
+	return poseidon.Hash(digest, x, y)
+}
+
+// SignSNARK creates a SNARK-friendly ring signature over m. All ring members
+// and the private key must be on the BabyJubjub curve.
+func SignSNARK(m [32]byte, ring Ring, privkey *ecdsa.PrivateKey, s int) (*SNARKSign, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	curve := babyjub.BabyJub()
+	if privkey.Curve != curve {
+		return nil, errWrongCurve
+	}
+	if !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	digest, err := snarkDigest(m, ring)
+	if err != nil {
+		return nil, err
+	}
+	N := curve.Params().N
+	n := len(ring)
+
+	C := make([]*big.Int, n)
+	S := make([]*big.Int, n)
+	k, err := randomScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	// start the chain at the signer's commitment and go round the ring
+	if C[(s+1)%n], err = snarkChallenge(digest, baseMul(curve, k)); err != nil {
+		return nil, err
+	}
+	for j := 1; j < n; j++ {
+		i := (s + j) % n
+		if S[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+		L := pointAdd(baseMul(curve, S[i]), pointMul(ring[i], C[i]))
+		if C[(i+1)%n], err = snarkChallenge(digest, L); err != nil {
+			return nil, err
+		}
+	}
+	// close the ring: s_s = k - c_s*x
+	ss := new(big.Int).Mul(C[s], privkey.D)
+	ss.Sub(k, ss)
+	S[s] = ss.Mod(ss, N)
+
+	return &SNARKSign{
+		M:     m,
+		Ring:  append(Ring{}, ring...),
+		C:     C[0],
+		S:     S,
+		Curve: curve,
+	}, nil
+}
+
+// VerifySNARK verifies a SNARK-friendly ring signature natively.
+// returns true if a valid signature, false otherwise
+func VerifySNARK(sig *SNARKSign) bool {
+	if sig == nil || sig.C == nil || len(sig.Ring) < 2 || len(sig.S) != len(sig.Ring) {
+		return false
+	}
+	if sig.Curve != babyjub.BabyJub() || sig.C.Cmp(poseidon.Modulus()) >= 0 {
+		return false
+	}
+	digest, err := snarkDigest(sig.M, sig.Ring)
+	if err != nil {
+		return false
+	}
+	N := sig.Curve.Params().N
+	c := sig.C
+	for i, pub := range sig.Ring {
+		if sig.S[i] == nil || sig.S[i].Sign() < 0 || sig.S[i].Cmp(N) >= 0 {
+			return false
+		}
+		L := pointAdd(baseMul(sig.Curve, sig.S[i]), pointMul(pub, c))
+		if c, err = snarkChallenge(digest, L); err != nil {
+			return false
+		}
+	}
+	return c.Cmp(sig.C) == 0
+}
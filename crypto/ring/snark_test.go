@@ -0,0 +1,64 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/babyjub"
+)
+
+// newBabyJubRing creates a ring of BabyJubjub keys with the signer at s.
+func newBabyJubRing(t *testing.T, size, s int) (Ring, *ecdsa.PrivateKey) {
+	ring := make(Ring, size)
+	var signer *ecdsa.PrivateKey
+	for i := range ring {
+		key, err := babyjub.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring[i] = &key.PublicKey
+		if i == s {
+			signer = key
+		}
+	}
+	return ring, signer
+}
+
+func TestSNARK(t *testing.T) {
+	ring, key := newBabyJubRing(t, 5, 3)
+	msg := [32]byte{0xba, 0xb7}
+
+	sig, err := SignSNARK(msg, ring, key, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifySNARK(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	sig.M[31]++
+	if VerifySNARK(sig) {
+		t.Error("signature over different message accepted")
+	}
+	sig.M[31]--
+	sig.S[0] = new(big.Int).Add(sig.S[0], big.NewInt(1))
+	if VerifySNARK(sig) {
+		t.Error("tampered signature accepted")
+	}
+}
+
+func TestSNARKWrongCurve(t *testing.T) {
+	ring, key := newBabyJubRing(t, 3, 0)
+	if _, err := SignSNARK([32]byte{}, ring, key, 1); err != errNotSigner {
+		t.Errorf("wrong signer index: have %v, want %v", err, errNotSigner)
+	}
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring[1] = &other.PublicKey
+	if _, err := SignSNARK([32]byte{}, ring, key, 0); err != errWrongCurve {
+		t.Errorf("secp256k1 ring member: have %v, want %v", err, errWrongCurve)
+	}
+}
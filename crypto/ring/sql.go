@@ -0,0 +1,138 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Signatures and key images are stored in databases and sent through gob,
+// e.g. by net/rpc, in their RLP encoding. Both carry the name of their curve,
+// so they can be decoded without further context.
+
+var errNullValue = errors.New("cannot scan NULL")
+
+// KeyImage is the key image of a linkable ring signature.
+type KeyImage struct {
+	*ecdsa.PublicKey
+}
+
+// KeyImage returns the key image of the signature.
+func (r *RingSign) KeyImage() KeyImage {
+	return KeyImage{newPoint(r.Curve, r.I.X, r.I.Y)}
+}
+
+// rlpKeyImage is the RLP encoding of a KeyImage.
+type rlpKeyImage struct {
+	Curve string
+	Point []byte
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (k KeyImage) EncodeRLP(w io.Writer) error {
+	if k.PublicKey == nil {
+		return errInvalidPoint
+	}
+	name, err := CurveName(k.Curve)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, rlpKeyImage{Curve: name, Point: compressPoint(k.Curve, k.PublicKey)})
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (k *KeyImage) DecodeRLP(s *rlp.Stream) error {
+	var dec rlpKeyImage
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	curve, err := CurveByName(dec.Curve)
+	if err != nil {
+		return err
+	}
+	image, err := decompressPoint(curve, dec.Point)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return errInvalidPoint
+	}
+	k.PublicKey = image
+	return nil
+}
+
+// Equal reports whether k and other are the same key image.
+func (k KeyImage) Equal(other KeyImage) bool {
+	return pointEqual(k.PublicKey, other.PublicKey)
+}
+
+// GobEncode implements gob.GobEncoder.
+func (k KeyImage) GobEncode() ([]byte, error) {
+	return rlp.EncodeToBytes(k)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (k *KeyImage) GobDecode(b []byte) error {
+	return rlp.DecodeBytes(b, k)
+}
+
+// Value implements driver.Valuer.
+func (k KeyImage) Value() (driver.Value, error) {
+	if k.PublicKey == nil {
+		return nil, nil
+	}
+	return rlp.EncodeToBytes(k)
+}
+
+// Scan implements sql.Scanner.
+func (k *KeyImage) Scan(src interface{}) error {
+	b, err := scanBytes(src)
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(b, k)
+}
+
+// GobEncode implements gob.GobEncoder.
+func (r *RingSign) GobEncode() ([]byte, error) {
+	return rlp.EncodeToBytes(r)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (r *RingSign) GobDecode(b []byte) error {
+	return rlp.DecodeBytes(b, r)
+}
+
+// Value implements driver.Valuer. A nil signature is stored as NULL.
+func (r *RingSign) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return rlp.EncodeToBytes(r)
+}
+
+// Scan implements sql.Scanner.
+func (r *RingSign) Scan(src interface{}) error {
+	b, err := scanBytes(src)
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(b, r)
+}
+
+// scanBytes returns the contents of a BLOB or TEXT column.
+func scanBytes(src interface{}) ([]byte, error) {
+	switch src := src.(type) {
+	case []byte:
+		return src, nil
+	case string:
+		return []byte(src), nil
+	case nil:
+		return nil, errNullValue
+	}
+	return nil, fmt.Errorf("cannot scan %T", src)
+}
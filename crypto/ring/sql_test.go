@@ -0,0 +1,90 @@
+package ring
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/gob"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	_ driver.Valuer = (*RingSign)(nil)
+	_ sql.Scanner   = (*RingSign)(nil)
+	_ driver.Valuer = KeyImage{}
+	_ sql.Scanner   = (*KeyImage)(nil)
+)
+
+func TestGob(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(3, key, 0)
+	sig, err := Sign([32]byte{0x60}, ring, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type envelope struct {
+		Sig   *RingSign
+		Image KeyImage
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(envelope{sig, sig.KeyImage()}); err != nil {
+		t.Fatal(err)
+	}
+	var dec envelope
+	if err := gob.NewDecoder(&buf).Decode(&dec); err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(dec.Sig) {
+		t.Fatal("decoded signature rejected")
+	}
+	if !dec.Image.Equal(sig.KeyImage()) || !dec.Image.Equal(dec.Sig.KeyImage()) {
+		t.Fatal("key image mismatch")
+	}
+}
+
+func TestSQL(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ring := GenNewKeyRing(2, key, 1)
+	sig, err := Sign([32]byte{0x53}, ring, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := sig.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec RingSign
+	if err := dec.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(&dec) || !bytes.Equal(dec.SerializeSignature(), sig.SerializeSignature()) {
+		t.Fatal("scanned signature mismatch")
+	}
+	if err := dec.Scan(string(value.([]byte))); err != nil {
+		t.Fatalf("text column rejected: %v", err)
+	}
+
+	value, err = sig.KeyImage().Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var image KeyImage
+	if err := image.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if !image.Equal(sig.KeyImage()) {
+		t.Fatal("scanned key image mismatch")
+	}
+
+	if value, err := (*RingSign)(nil).Value(); value != nil || err != nil {
+		t.Errorf("nil signature stored as %v, %v", value, err)
+	}
+	if err := dec.Scan(nil); err != errNullValue {
+		t.Errorf("NULL: have %v, want %v", err, errNullValue)
+	}
+	if err := image.Scan(int64(1)); err == nil {
+		t.Error("integer column accepted")
+	}
+}
@@ -0,0 +1,108 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/merlin"
+	"github.com/ethereum/go-ethereum/crypto/ristretto255"
+)
+
+// sr25519 ring signatures let Substrate and Polkadot accounts form rings
+// without converting their keys. Members are 32 byte sr25519 public keys and
+// the disjunctive Schnorr proof derives its challenge from a Merlin
+// transcript that starts like a schnorrkel signing transcript, binding the
+// signing context and message before the ring and the nonce commitments.
+
+// Sr25519Sign is a ring signature over sr25519 public keys.
+type Sr25519Sign struct {
+	Context []byte     // schnorrkel signing context, e.g. "substrate"
+	M       [32]byte   // message
+	Keys    [][32]byte // sr25519 public keys of the ring members
+	C       []*big.Int // challenges, one per ring member
+	S       []*big.Int // responses, one per ring member
+}
+
+// RingFromSr25519 parses a list of sr25519 public keys into a ring.
+func RingFromSr25519(keys [][32]byte) (Ring, error) {
+	ring := make(Ring, len(keys))
+	for i := range keys {
+		pub, err := ristretto255.PublicKeyFromSr25519(keys[i][:])
+		if err != nil {
+			return nil, err
+		}
+		ring[i] = pub
+	}
+	return ring, nil
+}
+
+// sr25519Hasher returns the Merlin challenge over context, message and keys.
+func sr25519Hasher(context []byte, m [32]byte, keys [][32]byte) orHasher {
+	return func(R []*ecdsa.PublicKey) *big.Int {
+		t := merlin.NewTranscript("SigningContext")
+		t.AppendMessage(nil, context)
+		t.AppendMessage([]byte("sign-bytes"), m[:])
+		t.AppendMessage([]byte("proto-name"), []byte("go-ethereum ring CDS"))
+		t.AppendUint64([]byte("ring-size"), uint64(len(keys)))
+		for i := range keys {
+			t.AppendMessage([]byte("pk"), keys[i][:])
+		}
+		for _, r := range R {
+			enc := make([]byte, 32) // the identity encodes as zeroes
+			if r != nil {
+				enc = ristretto255.Sr25519Bytes(r)
+			}
+			t.AppendMessage([]byte("R"), enc)
+		}
+		// reduce 64 little-endian bytes like schnorrkel's challenge scalars
+		var buf [64]byte
+		t.ChallengeBytes([]byte("ring-challenge"), buf[:])
+		for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+			buf[i], buf[j] = buf[j], buf[i]
+		}
+		c := new(big.Int).SetBytes(buf[:])
+		return c.Mod(c, ristretto255.Curve().Params().N)
+	}
+}
+
+// SignSr25519 creates a ring signature over m under the signing context on a
+// ring of sr25519 keys. The private key must be a ristretto255 key whose
+// public key is keys[s].
+func SignSr25519(context []byte, m [32]byte, keys [][32]byte, privkey *ecdsa.PrivateKey, s int) (*Sr25519Sign, error) {
+	if len(keys) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(keys) {
+		return nil, errIndexOutOfRange
+	}
+	ring, err := RingFromSr25519(keys)
+	if err != nil {
+		return nil, err
+	}
+	if privkey.Curve != ristretto255.Curve() || !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	sig := &Sr25519Sign{
+		Context: append([]byte{}, context...),
+		M:       m,
+		Keys:    append([][32]byte{}, keys...),
+	}
+	sig.C, sig.S, err = proveOr(ring, privkey, s, sr25519Hasher(sig.Context, m, sig.Keys))
+	if err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// VerifySr25519 verifies a ring signature over sr25519 keys.
+// returns true if a valid signature, false otherwise
+func VerifySr25519(sig *Sr25519Sign) bool {
+	if sig == nil {
+		return false
+	}
+	ring, err := RingFromSr25519(sig.Keys)
+	if err != nil {
+		return false
+	}
+	return verifyOr(ristretto255.Curve(), ring, sig.C, sig.S, sr25519Hasher(sig.Context, sig.M, sig.Keys))
+}
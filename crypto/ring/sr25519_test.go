@@ -0,0 +1,41 @@
+package ring
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/ristretto255"
+)
+
+func TestSr25519(t *testing.T) {
+	keys := make([][32]byte, 3)
+	var seed [32]byte
+	for i := range keys {
+		if _, err := rand.Read(seed[:]); err != nil {
+			t.Fatal(err)
+		}
+		key, err := ristretto255.PrivateKeyFromSr25519Seed(seed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		copy(keys[i][:], ristretto255.Sr25519Bytes(&key.PublicKey))
+	}
+	key, err := ristretto255.PrivateKeyFromSr25519Seed(seed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignSr25519([]byte("substrate"), [32]byte{0x25, 0x51}, keys, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifySr25519(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	sig.Context = []byte("polkadot")
+	if VerifySr25519(sig) {
+		t.Error("signature accepted under different context")
+	}
+	if _, err := SignSr25519(nil, [32]byte{}, keys, key, 0); err != errNotSigner {
+		t.Errorf("wrong signer index: have %v, want %v", err, errNotSigner)
+	}
+}
@@ -0,0 +1,85 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// Stealth addresses (CryptoNote) give every payment a fresh one-time output
+// key, so outputs cannot be linked to the recipient's published address. A
+// recipient publishes a view key A = a*G and a spend key B = b*G. For each
+// transaction the sender picks r and publishes R = r*G; output i goes to
+//
+//	P_i = H_s(r*A, i)*G + B
+//
+// The recipient recognises the output with the view key alone since
+// r*A = a*R, and only the spend key yields the one-time private key
+// x_i = H_s(a*R, i) + b.
+
+// stealthDomain separates the hashes of stealth derivations from other hashes
+// in the package.
+var stealthDomain = []byte("go-ethereum/crypto/ring stealth")
+
+var errNotOwnOutput = errors.New("output does not belong to the keys")
+
+// StealthAddress is the public address of a stealth payment recipient.
+type StealthAddress struct {
+	View  *ecdsa.PublicKey // A = a*G, used to detect payments
+	Spend *ecdsa.PublicKey // B = b*G, used to spend them
+}
+
+// stealthScalar computes H_s(D, i) for the shared derivation D.
+func stealthScalar(D *ecdsa.PublicKey, index uint64) *big.Int {
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], index)
+	return hashToScalar(D.Curve, stealthDomain, pointBytes(D), idx[:])
+}
+
+// DeriveStealthKey returns the one-time key of output index paid to addr in a
+// transaction with the private transaction key txKey.
+func DeriveStealthKey(addr *StealthAddress, txKey *ecdsa.PrivateKey, index uint64) *ecdsa.PublicKey {
+	D := pointMul(addr.View, txKey.D)
+	return pointAdd(baseMul(D.Curve, stealthScalar(D, index)), addr.Spend)
+}
+
+// IsStealthOutput reports whether output index of a transaction with public
+// transaction key txPub pays to the address with view private key view and
+// spend public key spend.
+func IsStealthOutput(view *ecdsa.PrivateKey, spend, txPub *ecdsa.PublicKey, index uint64, output *ecdsa.PublicKey) bool {
+	D := pointMul(txPub, view.D)
+	if D == nil || output == nil {
+		return false
+	}
+	return pointEqual(pointAdd(baseMul(D.Curve, stealthScalar(D, index)), spend), output)
+}
+
+// ScanStealth returns the indices of the outputs of a transaction that pay to
+// the address with view private key view and spend public key spend.
+func ScanStealth(view *ecdsa.PrivateKey, spend, txPub *ecdsa.PublicKey, outputs Ring) []int {
+	var owned []int
+	for i, out := range outputs {
+		if IsStealthOutput(view, spend, txPub, uint64(i), out) {
+			owned = append(owned, i)
+		}
+	}
+	return owned
+}
+
+// RecoverStealthKey returns the one-time private key of an output paid to the
+// address with private keys view and spend, checking that it matches output.
+func RecoverStealthKey(view, spend *ecdsa.PrivateKey, txPub *ecdsa.PublicKey, index uint64, output *ecdsa.PublicKey) (*ecdsa.PrivateKey, error) {
+	D := pointMul(txPub, view.D)
+	if D == nil {
+		return nil, errNotOwnOutput
+	}
+	x := new(big.Int).Add(stealthScalar(D, index), spend.D)
+	x.Mod(x, D.Curve.Params().N)
+
+	pub := baseMul(D.Curve, x)
+	if pub == nil || !pointEqual(pub, output) {
+		return nil, errNotOwnOutput
+	}
+	return &ecdsa.PrivateKey{PublicKey: *pub, D: x}, nil
+}
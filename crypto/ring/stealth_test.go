@@ -0,0 +1,45 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStealth(t *testing.T) {
+	view, _ := crypto.GenerateKey()
+	spend, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	txKey, _ := crypto.GenerateKey()
+	addr := &StealthAddress{View: &view.PublicKey, Spend: &spend.PublicKey}
+
+	// outputs 0 and 2 pay to addr, output 1 to someone else
+	outputs := Ring{
+		DeriveStealthKey(addr, txKey, 0),
+		DeriveStealthKey(&StealthAddress{View: &other.PublicKey, Spend: &other.PublicKey}, txKey, 1),
+		DeriveStealthKey(addr, txKey, 2),
+	}
+	owned := ScanStealth(view, &spend.PublicKey, &txKey.PublicKey, outputs)
+	if len(owned) != 2 || owned[0] != 0 || owned[1] != 2 {
+		t.Fatalf("scan found outputs %v, want [0 2]", owned)
+	}
+	if pointEqual(outputs[0], outputs[2]) {
+		t.Error("one-time keys of the same address are equal")
+	}
+	key, err := RecoverStealthKey(view, spend, &txKey.PublicKey, 2, outputs[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the one-time key can sign for the output
+	ring := Ring{outputs[0], outputs[1], outputs[2]}
+	sig, err := SignTriptych([32]byte{1}, ring, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyTriptych(sig) {
+		t.Error("signature with recovered key rejected")
+	}
+	if _, err := RecoverStealthKey(view, spend, &txKey.PublicKey, 1, outputs[1]); err != errNotOwnOutput {
+		t.Errorf("foreign output: have %v, want %v", err, errNotOwnOutput)
+	}
+}
@@ -0,0 +1,121 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// Subaddresses (Monero) derive any number of unlinkable receiving addresses
+// from one wallet. Subaddress (major, minor) has the spend key
+// D = B + m*G with m = H_s(a, major, minor) and the view key C = a*D, while
+// (0, 0) is the main address. A sender paying to a subaddress publishes
+// R = r*D instead of r*G, so the shared derivation r*C equals a*R as usual.
+// The wallet recovers the subaddress spend key of an output as
+// P - H_s(a*R, i)*G and looks it up in a table of its subaddresses, scanning
+// all of them with a single view key operation per transaction.
+
+// SubaddressIndex identifies a subaddress by account (major) and address
+// within the account (minor).
+type SubaddressIndex struct {
+	Major uint32
+	Minor uint32
+}
+
+// SubaddressTable maps subaddress spend keys of a wallet to their indices.
+type SubaddressTable struct {
+	keys map[string]SubaddressIndex
+}
+
+// subaddressScalar computes m = H_s(a, major, minor).
+func (k *WalletKeys) subaddressScalar(index SubaddressIndex) *big.Int {
+	var idx [8]byte
+	binary.BigEndian.PutUint32(idx[:4], index.Major)
+	binary.BigEndian.PutUint32(idx[4:], index.Minor)
+	a := math.PaddedBigBytes(k.View.D, (k.View.Curve.Params().N.BitLen()+7)/8)
+	return hashToScalar(k.View.Curve, stealthDomain, []byte("subaddress"), a, idx[:])
+}
+
+// subaddressSpend returns the spend public key D of a subaddress.
+func (k *WalletKeys) subaddressSpend(index SubaddressIndex) *ecdsa.PublicKey {
+	if index == (SubaddressIndex{}) {
+		return k.spendPub
+	}
+	return pointAdd(k.spendPub, baseMul(k.View.Curve, k.subaddressScalar(index)))
+}
+
+// Subaddress returns the stealth address of subaddress index. Index (0, 0) is
+// the main address.
+func (k *WalletKeys) Subaddress(index SubaddressIndex) *StealthAddress {
+	if index == (SubaddressIndex{}) {
+		return k.Address()
+	}
+	D := k.subaddressSpend(index)
+	return &StealthAddress{View: pointMul(D, k.View.D), Spend: D}
+}
+
+// SubaddressTable returns the lookup table of the first minors subaddresses of
+// the first majors accounts, including the main address.
+func (k *WalletKeys) SubaddressTable(majors, minors uint32) *SubaddressTable {
+	table := &SubaddressTable{keys: make(map[string]SubaddressIndex)}
+	for major := uint32(0); major < majors; major++ {
+		for minor := uint32(0); minor < minors; minor++ {
+			index := SubaddressIndex{major, minor}
+			table.keys[string(pointBytes(k.subaddressSpend(index)))] = index
+		}
+	}
+	return table
+}
+
+// SubaddressTxKey returns the public transaction key to publish when paying to
+// addr with the private transaction key txKey. For main addresses this is
+// txKey*G, for subaddresses txKey*D.
+func SubaddressTxKey(addr *StealthAddress, txKey *ecdsa.PrivateKey, subaddress bool) *ecdsa.PublicKey {
+	if !subaddress {
+		return &txKey.PublicKey
+	}
+	return pointMul(addr.Spend, txKey.D)
+}
+
+// ScanSubaddresses returns the outputs of tx that pay to any subaddress in
+// table. In watch-only mode the one-time private keys are left nil.
+func (k *WalletKeys) ScanSubaddresses(tx *StealthTx, table *SubaddressTable) []*OwnedOutput {
+	D := pointMul(tx.TxPub, k.View.D)
+	if D == nil {
+		return nil
+	}
+	var owned []*OwnedOutput
+	for i, out := range tx.Outputs {
+		index := uint64(i)
+		if out.Key == nil || out.ViewTag != stealthViewTag(D, index) {
+			continue
+		}
+		h := stealthScalar(D, index)
+		spend := pointSub(out.Key, baseMul(D.Curve, h))
+		if spend == nil {
+			continue
+		}
+		sub, ok := table.keys[string(pointBytes(spend))]
+		if !ok {
+			continue
+		}
+		amount, mask, ok := openStealthOutput(D, index, out)
+		if !ok {
+			continue
+		}
+		own := &OwnedOutput{Index: i, Output: out, Amount: amount, Mask: mask, Subaddress: sub}
+		if !k.WatchOnly() {
+			// x = H_s(a*R, i) + b + m
+			x := new(big.Int).Add(h, k.Spend.D)
+			if sub != (SubaddressIndex{}) {
+				x.Add(x, k.subaddressScalar(sub))
+			}
+			x.Mod(x, D.Curve.Params().N)
+			own.Key = &ecdsa.PrivateKey{PublicKey: *out.Key, D: x}
+		}
+		owned = append(owned, own)
+	}
+	return owned
+}
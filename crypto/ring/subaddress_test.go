@@ -0,0 +1,49 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSubaddress(t *testing.T) {
+	wallet, err := GenerateWalletKeys(crypto.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := wallet.SubaddressTable(2, 10)
+
+	sub := SubaddressIndex{Major: 1, Minor: 7}
+	addr := wallet.Subaddress(sub)
+	if pointEqual(addr.Spend, wallet.Address().Spend) || pointEqual(addr.View, wallet.Address().View) {
+		t.Fatal("subaddress equals main address")
+	}
+	// one transaction paying to the subaddress and the main address
+	txKey, _ := crypto.GenerateKey()
+	out0, _ := NewStealthOutput(addr, txKey, 0, 42)
+	tx := &StealthTx{TxPub: SubaddressTxKey(addr, txKey, true), Outputs: []*StealthOutput{out0}}
+
+	owned := wallet.ScanSubaddresses(tx, table)
+	if len(owned) != 1 || owned[0].Subaddress != sub || owned[0].Amount != 42 {
+		t.Fatalf("subaddress output not found: %+v", owned)
+	}
+	if !pointEqual(baseMul(crypto.S256(), owned[0].Key.D), out0.Key) {
+		t.Error("recovered key does not match output")
+	}
+	// a watch-only wallet finds the output without the spend key
+	watch := wallet.WatchOnlyKeys()
+	if owned := watch.ScanSubaddresses(tx, watch.SubaddressTable(2, 10)); len(owned) != 1 || owned[0].Key != nil {
+		t.Errorf("watch-only scan: %+v", owned)
+	}
+	// a subaddress outside the table is not found
+	if owned := wallet.ScanSubaddresses(tx, wallet.SubaddressTable(1, 10)); len(owned) != 0 {
+		t.Error("output found outside subaddress table")
+	}
+	// payments to the main address are found by the same scan
+	mainTx := &StealthTx{TxPub: &txKey.PublicKey}
+	mainOut, _ := NewStealthOutput(wallet.Address(), txKey, 0, 5)
+	mainTx.Outputs = []*StealthOutput{mainOut}
+	if owned := wallet.ScanSubaddresses(mainTx, table); len(owned) != 1 || owned[0].Subaddress != (SubaddressIndex{}) {
+		t.Errorf("main address output not found: %+v", owned)
+	}
+}
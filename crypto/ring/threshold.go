@@ -0,0 +1,332 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// Threshold ring signatures prove that t members of a ring of size n signed,
+// without revealing which t. The construction follows Cramer, Damgard and
+// Schoenmakers ("Proofs of Partial Knowledge", 1994): every ring member i gets
+// a challenge c_i and a Schnorr response s_i with R_i = s_i*G + c_i*P_i, and
+// the challenges must be the evaluations f(i+1) of a polynomial f of degree
+// n-t whose constant term is c = H(m, t, ring, R_0, ..., R_{n-1}).
+//
+// The n-t non-signers are simulated with random challenges and responses.
+// Together with f(0) = c they fix f, which in turn fixes the challenges of the
+// t signers, who can only answer them by knowing their private keys. Since f
+// is a uniformly random polynomial through c, any t-subset could have produced
+// the same signature.
+//
+// Signing is interactive so that no party ever holds more than one private
+// key: every signer publishes a nonce commitment through a ThresholdSigner,
+// a ThresholdSession computes the challenges, and the signers' responses close
+// the signature.
+
+// thresholdDomain separates the challenges of threshold signatures from other
+// hashes in the package.
+var thresholdDomain = []byte("go-ethereum/crypto/ring threshold")
+
+var (
+	errThreshold        = errors.New("threshold out of range of ring size")
+	errDuplicateSigner  = errors.New("duplicate signer index")
+	errCommitmentsCount = errors.New("number of nonce commitments differs from number of signers")
+	errResponsesCount   = errors.New("number of responses differs from number of signers")
+	errSessionState     = errors.New("threshold session used out of order")
+	errInvalidResponse  = errors.New("invalid signer response")
+)
+
+// ThresholdSign is a t-of-n threshold ring signature.
+type ThresholdSign struct {
+	M         [32]byte   // message
+	Threshold int        // number of signers t
+	Ring      Ring       // array of public keys
+	C         *big.Int   // constant term of the challenge polynomial
+	Poly      []*big.Int // remaining n-t coefficients of the challenge polynomial
+	S         []*big.Int // responses, one per ring member
+	Curve     elliptic.Curve
+}
+
+// thresholdChallenge computes c = H(m, t, ring, R_0, ..., R_{n-1}).
+func thresholdChallenge(curve elliptic.Curve, m [32]byte, t int, ring Ring, R []*ecdsa.PublicKey) *big.Int {
+	var tb [8]byte
+	binary.BigEndian.PutUint64(tb[:], uint64(t))
+
+	data := [][]byte{thresholdDomain, m[:], tb[:]}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	for _, r := range R {
+		data = append(data, pointBytes(r))
+	}
+	return hashToScalar(curve, data...)
+}
+
+// polyEval evaluates the polynomial with coefficients coeffs (in increasing
+// order) at x modulo n.
+func polyEval(coeffs []*big.Int, x, n *big.Int) *big.Int {
+	res := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		res.Mul(res, x)
+		res.Add(res, coeffs[i])
+		res.Mod(res, n)
+	}
+	return res
+}
+
+// polyInterpolate returns the coefficients of the unique polynomial of degree
+// len(xs)-1 through the points (xs[i], ys[i]) modulo the prime n.
+func polyInterpolate(xs, ys []*big.Int, n *big.Int) []*big.Int {
+	coeffs := make([]*big.Int, len(xs))
+	for i := range coeffs {
+		coeffs[i] = new(big.Int)
+	}
+	for i := range xs {
+		// Lagrange basis polynomial l_i(x) = prod_{j!=i} (x - x_j)/(x_i - x_j)
+		basis := []*big.Int{big.NewInt(1)}
+		denom := big.NewInt(1)
+		for j := range xs {
+			if j == i {
+				continue
+			}
+			basis = polyMulLinear(basis, big.NewInt(1), new(big.Int).Neg(xs[j]), n)
+			denom.Mul(denom, new(big.Int).Sub(xs[i], xs[j]))
+			denom.Mod(denom, n)
+		}
+		scale := new(big.Int).ModInverse(denom, n)
+		scale.Mul(scale, ys[i])
+		for k, b := range basis {
+			coeffs[k].Add(coeffs[k], new(big.Int).Mul(b, scale))
+			coeffs[k].Mod(coeffs[k], n)
+		}
+	}
+	return coeffs
+}
+
+// ThresholdSigner is the state a single ring member keeps between publishing
+// its nonce commitment and answering its challenge.
+type ThresholdSigner struct {
+	key *ecdsa.PrivateKey
+	k   *big.Int
+}
+
+// NewThresholdSigner starts a signing round for the holder of key and returns
+// the nonce commitment R = k*G to hand to the session.
+func NewThresholdSigner(key *ecdsa.PrivateKey) (*ThresholdSigner, *ecdsa.PublicKey, error) {
+	k, err := randomScalar(key.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ThresholdSigner{key: key, k: k}, baseMul(key.Curve, k), nil
+}
+
+// Respond answers the challenge c with s = k - c*x. The signer's nonce is
+// erased afterwards so it cannot be reused.
+func (s *ThresholdSigner) Respond(c *big.Int) (*big.Int, error) {
+	if s.k == nil {
+		return nil, errSessionState
+	}
+	N := s.key.Curve.Params().N
+	resp := new(big.Int).Mul(c, s.key.D)
+	resp.Sub(s.k, resp)
+	s.k = nil
+	return resp.Mod(resp, N), nil
+}
+
+// ThresholdSession coordinates a threshold signature. It holds no secrets and
+// may be run by any party, including one of the signers.
+type ThresholdSession struct {
+	m       [32]byte
+	ring    Ring
+	signers []int
+	curve   elliptic.Curve
+
+	nonces []*ecdsa.PublicKey // nonce commitments, real and simulated
+	c      *big.Int
+	poly   []*big.Int
+	resp   []*big.Int
+	chals  []*big.Int
+}
+
+// NewThresholdSession sets up the signing of m by the members of ring at the
+// given indices.
+func NewThresholdSession(m [32]byte, ring Ring, signers []int) (*ThresholdSession, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if len(signers) < 1 || len(signers) > len(ring) {
+		return nil, errThreshold
+	}
+	seen := make(map[int]bool)
+	for _, idx := range signers {
+		if idx < 0 || idx >= len(ring) {
+			return nil, errIndexOutOfRange
+		}
+		if seen[idx] {
+			return nil, errDuplicateSigner
+		}
+		seen[idx] = true
+	}
+	return &ThresholdSession{
+		m:       m,
+		ring:    ring,
+		signers: signers,
+		curve:   ring[0].Curve,
+	}, nil
+}
+
+// Challenges takes the nonce commitments of the signers, in the order the
+// signers were given to NewThresholdSession, and returns the challenge every
+// signer has to answer.
+func (s *ThresholdSession) Challenges(commitments []*ecdsa.PublicKey) ([]*big.Int, error) {
+	if s.chals != nil {
+		return nil, errSessionState
+	}
+	if len(commitments) != len(s.signers) {
+		return nil, errCommitmentsCount
+	}
+	n, t := len(s.ring), len(s.signers)
+	N := s.curve.Params().N
+
+	isSigner := make(map[int]bool)
+	s.nonces = make([]*ecdsa.PublicKey, n)
+	for i, idx := range s.signers {
+		if commitments[i] == nil {
+			return nil, errInvalidResponse
+		}
+		isSigner[idx] = true
+		s.nonces[idx] = commitments[i]
+	}
+
+	// simulate the non-signers
+	s.resp = make([]*big.Int, n)
+	xs := make([]*big.Int, 0, n-t+1)
+	ys := make([]*big.Int, 0, n-t+1)
+	for i := 0; i < n; i++ {
+		if isSigner[i] {
+			continue
+		}
+		ci, err := randomScalar(s.curve)
+		if err != nil {
+			return nil, err
+		}
+		if s.resp[i], err = randomScalar(s.curve); err != nil {
+			return nil, err
+		}
+		s.nonces[i] = pointAdd(baseMul(s.curve, s.resp[i]), pointMul(s.ring[i], ci))
+		xs = append(xs, big.NewInt(int64(i+1)))
+		ys = append(ys, ci)
+	}
+
+	// fix the polynomial through the simulated challenges and f(0) = c
+	s.c = thresholdChallenge(s.curve, s.m, t, s.ring, s.nonces)
+	xs = append(xs, new(big.Int))
+	ys = append(ys, s.c)
+	coeffs := polyInterpolate(xs, ys, N)
+	s.poly = coeffs[1:]
+
+	s.chals = make([]*big.Int, t)
+	for i, idx := range s.signers {
+		s.chals[i] = polyEval(coeffs, big.NewInt(int64(idx+1)), N)
+	}
+	return s.chals, nil
+}
+
+// Finalize takes the signers' responses, in signer order, and assembles the
+// threshold signature.
+func (s *ThresholdSession) Finalize(responses []*big.Int) (*ThresholdSign, error) {
+	if s.chals == nil || s.resp == nil {
+		return nil, errSessionState
+	}
+	if len(responses) != len(s.signers) {
+		return nil, errResponsesCount
+	}
+	for i, idx := range s.signers {
+		// check s_i*G + c_i*P_i == R_i so a faulty signer is caught early
+		if responses[i] == nil {
+			return nil, errInvalidResponse
+		}
+		r := pointAdd(baseMul(s.curve, responses[i]), pointMul(s.ring[idx], s.chals[i]))
+		if !pointEqual(r, s.nonces[idx]) {
+			return nil, errInvalidResponse
+		}
+		s.resp[idx] = responses[i]
+	}
+	sig := &ThresholdSign{
+		M:         s.m,
+		Threshold: len(s.signers),
+		Ring:      s.ring,
+		C:         s.c,
+		Poly:      s.poly,
+		S:         s.resp,
+		Curve:     s.curve,
+	}
+	s.resp = nil
+	return sig, nil
+}
+
+// SignThreshold creates a threshold ring signature over m in a single call
+// when all t private keys are available locally. privkeys[i] must be the
+// private key of ring[signers[i]].
+func SignThreshold(m [32]byte, ring Ring, privkeys []*ecdsa.PrivateKey, signers []int) (*ThresholdSign, error) {
+	if len(privkeys) != len(signers) {
+		return nil, errRingCount
+	}
+	session, err := NewThresholdSession(m, ring, signers)
+	if err != nil {
+		return nil, err
+	}
+	parties := make([]*ThresholdSigner, len(signers))
+	commitments := make([]*ecdsa.PublicKey, len(signers))
+	for i, key := range privkeys {
+		if !pointEqual(ring[signers[i]], &key.PublicKey) {
+			return nil, errNotSigner
+		}
+		if parties[i], commitments[i], err = NewThresholdSigner(key); err != nil {
+			return nil, err
+		}
+	}
+	chals, err := session.Challenges(commitments)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*big.Int, len(signers))
+	for i, party := range parties {
+		if responses[i], err = party.Respond(chals[i]); err != nil {
+			return nil, err
+		}
+	}
+	return session.Finalize(responses)
+}
+
+// VerifyThreshold verifies a threshold ring signature.
+// returns true if a valid signature, false otherwise
+func VerifyThreshold(sig *ThresholdSign) bool {
+	if sig == nil || sig.C == nil {
+		return false
+	}
+	n, t := len(sig.Ring), sig.Threshold
+	if n < 2 || t < 1 || t > n || len(sig.S) != n || len(sig.Poly) != n-t {
+		return false
+	}
+	curve := sig.Curve
+	N := curve.Params().N
+	coeffs := append([]*big.Int{sig.C}, sig.Poly...)
+	for _, c := range coeffs {
+		if c == nil {
+			return false
+		}
+	}
+	R := make([]*ecdsa.PublicKey, n)
+	for i, pub := range sig.Ring {
+		if pub == nil || sig.S[i] == nil {
+			return false
+		}
+		ci := polyEval(coeffs, big.NewInt(int64(i+1)), N)
+		R[i] = pointAdd(baseMul(curve, sig.S[i]), pointMul(pub, ci))
+	}
+	return thresholdChallenge(curve, sig.M, t, sig.Ring, R).Cmp(sig.C) == 0
+}
@@ -0,0 +1,79 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestThreshold(t *testing.T) {
+	const size = 5
+	keys := make([]*ecdsa.PrivateKey, size)
+	ring := make(Ring, size)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i], ring[i] = key, &key.PublicKey
+	}
+	for _, signers := range [][]int{{2}, {0, 3}, {4, 1, 2}, {0, 1, 2, 3, 4}} {
+		privkeys := make([]*ecdsa.PrivateKey, len(signers))
+		for i, idx := range signers {
+			privkeys[i] = keys[idx]
+		}
+		sig, err := SignThreshold([32]byte{9}, ring, privkeys, signers)
+		if err != nil {
+			t.Fatalf("%v: failed to sign: %v", signers, err)
+		}
+		if !VerifyThreshold(sig) {
+			t.Fatalf("%v: valid signature rejected", signers)
+		}
+		// claiming a higher threshold must fail
+		if len(sig.Poly) > 0 {
+			forged := *sig
+			forged.Threshold++
+			forged.Poly = sig.Poly[1:]
+			if VerifyThreshold(&forged) {
+				t.Errorf("%v: signature accepted with raised threshold", signers)
+			}
+		}
+		sig.S[0] = new(big.Int).Add(sig.S[0], big.NewInt(1))
+		if VerifyThreshold(sig) {
+			t.Errorf("%v: tampered signature accepted", signers)
+		}
+	}
+}
+
+func TestThresholdSession(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	ring := make(Ring, 3)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i], ring[i] = key, &key.PublicKey
+	}
+	session, err := NewThresholdSession([32]byte{}, ring, []int{0, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer0, r0, _ := NewThresholdSigner(keys[0])
+	signer2, r2, _ := NewThresholdSigner(keys[1]) // wrong key for index 2
+
+	chals, err := session.Challenges([]*ecdsa.PublicKey{r0, r2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s0, _ := signer0.Respond(chals[0])
+	s2, _ := signer2.Respond(chals[1])
+	if _, err := session.Finalize([]*big.Int{s0, s2}); err != errInvalidResponse {
+		t.Fatalf("error mismatch: have %v, want %v", err, errInvalidResponse)
+	}
+	if _, err := signer0.Respond(chals[0]); err != errSessionState {
+		t.Fatalf("nonce reuse not prevented: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+)
+
+// Traceable ring signatures (Fujisaki and Suzuki, "Traceable Ring Signature",
+// 2007) are issued under a tag, e.g. the name of an election. A member may sign
+// once per tag anonymously, but signing two different messages under the same
+// tag reveals the signer's public key to anyone. Unlike linkable signatures,
+// which only show that two signatures share a signer, this gives one vote per
+// member without a trusted tallier.
+//
+// For a tag L = (tag, ring) let h = H_p(L). The signer at (1-based) index i
+// publishes the tag-specific key image sigma_i = x_i*h implicitly, through the
+// line
+//
+//	sigma_j = A_0 + j*A_1, with A_0 = H_p(L, m) and A_1 = (sigma_i - A_0)/i
+//
+// and proves that log_G(P_j) = log_h(sigma_j) for some j. Two signatures by
+// the same signer under the same tag meet in sigma_i: for different messages
+// the lines cross in exactly that point, for the same message they coincide.
+
+// traceableDomain separates the hashes of traceable signatures from other
+// hashes in the package.
+var traceableDomain = []byte("go-ethereum/crypto/ring traceable")
+
+// TraceableSign is a Fujisaki-Suzuki traceable ring signature.
+type TraceableSign struct {
+	M     [32]byte         // message
+	Tag   []byte           // issue the signature was created for
+	Ring  Ring             // array of public keys
+	A1    *ecdsa.PublicKey // slope of the key image line
+	C     []*big.Int       // challenges, one per ring member
+	S     []*big.Int       // responses, one per ring member
+	Curve elliptic.Curve
+}
+
+// TraceResult is the outcome of tracing two traceable signatures.
+type TraceResult int
+
+const (
+	// TraceIndependent means the signatures were created by different signers
+	// or under different tags.
+	TraceIndependent TraceResult = iota
+
+	// TraceLinked means the same signer signed the same message twice.
+	TraceLinked
+
+	// TraceRevealed means the same signer signed two different messages, and
+	// their public key has been revealed.
+	TraceRevealed
+)
+
+// traceableTag hashes the tag and ring into the base point h.
+func traceableTag(curve elliptic.Curve, tag []byte, ring Ring) *ecdsa.PublicKey {
+	data := [][]byte{traceableDomain, []byte("h"), tag}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	return hashToPoint(curve, data...)
+}
+
+// traceableA0 hashes the tag, ring and message into the line offset A_0.
+func traceableA0(curve elliptic.Curve, tag []byte, ring Ring, m [32]byte) *ecdsa.PublicKey {
+	data := [][]byte{traceableDomain, []byte("A0"), tag}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	data = append(data, m[:])
+	return hashToPoint(curve, data...)
+}
+
+// traceableImages returns sigma_j = A_0 + j*A_1 for every ring member.
+func traceableImages(a0, a1 *ecdsa.PublicKey, n int) []*ecdsa.PublicKey {
+	images := make([]*ecdsa.PublicKey, n)
+	for j := range images {
+		images[j] = pointAdd(a0, pointMul(a1, big.NewInt(int64(j+1))))
+	}
+	return images
+}
+
+// traceablePrefix returns the data the challenge is computed over besides the
+// proof commitments: the tag, ring and key image line.
+func traceablePrefix(tag []byte, ring Ring, a0, a1 *ecdsa.PublicKey) [][]byte {
+	data := [][]byte{traceableDomain, []byte("c"), tag}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	return append(data, pointBytes(a0), pointBytes(a1))
+}
+
+// SignTraceable creates a traceable ring signature over m under tag, proving
+// knowledge of the private key of ring[s].
+func SignTraceable(m [32]byte, tag []byte, ring Ring, privkey *ecdsa.PrivateKey, s int) (*TraceableSign, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	if !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	curve := privkey.Curve
+	N := curve.Params().N
+
+	// sigma_i = x*h, A_1 = (sigma_i - A_0)/i
+	h := traceableTag(curve, tag, ring)
+	a0 := traceableA0(curve, tag, ring, m)
+	sigma := pointMul(h, privkey.D)
+	a1 := pointMul(pointSub(sigma, a0), new(big.Int).ModInverse(big.NewInt(int64(s+1)), N))
+	images := traceableImages(a0, a1, len(ring))
+
+	C, S, err := proveDLEQOr(traceablePrefix(tag, ring, a0, a1), h, ring, images, privkey, s)
+	if err != nil {
+		return nil, err
+	}
+	return &TraceableSign{
+		M:     m,
+		Tag:   tag,
+		Ring:  ring,
+		A1:    a1,
+		C:     C,
+		S:     S,
+		Curve: curve,
+	}, nil
+}
+
+// VerifyTraceable verifies a traceable ring signature.
+// returns true if a valid signature, false otherwise
+func VerifyTraceable(sig *TraceableSign) bool {
+	if sig == nil || sig.A1 == nil || len(sig.Ring) < 2 || len(sig.C) != len(sig.Ring) || len(sig.S) != len(sig.Ring) {
+		return false
+	}
+	curve := sig.Curve
+	h := traceableTag(curve, sig.Tag, sig.Ring)
+	a0 := traceableA0(curve, sig.Tag, sig.Ring, sig.M)
+	images := traceableImages(a0, sig.A1, len(sig.Ring))
+
+	return verifyDLEQOr(curve, traceablePrefix(sig.Tag, sig.Ring, a0, sig.A1), h, sig.Ring, images, sig.C, sig.S)
+}
+
+// Trace compares two valid traceable signatures. If the same member signed
+// different messages under the same tag, TraceRevealed is returned along with
+// the signer's public key.
+func Trace(a, b *TraceableSign) (TraceResult, *ecdsa.PublicKey) {
+	if !bytes.Equal(a.Tag, b.Tag) || len(a.Ring) != len(b.Ring) {
+		return TraceIndependent, nil
+	}
+	for i := range a.Ring {
+		if !pointEqual(a.Ring[i], b.Ring[i]) {
+			return TraceIndependent, nil
+		}
+	}
+	curve := a.Curve
+	imagesA := traceableImages(traceableA0(curve, a.Tag, a.Ring, a.M), a.A1, len(a.Ring))
+	imagesB := traceableImages(traceableA0(curve, b.Tag, b.Ring, b.M), b.A1, len(b.Ring))
+
+	var (
+		matches int
+		signer  *ecdsa.PublicKey
+	)
+	for i := range imagesA {
+		if pointEqual(imagesA[i], imagesB[i]) {
+			matches++
+			signer = a.Ring[i]
+		}
+	}
+	switch {
+	case matches == len(imagesA):
+		return TraceLinked, nil
+	case matches == 1:
+		return TraceRevealed, signer
+	default:
+		return TraceIndependent, nil
+	}
+}
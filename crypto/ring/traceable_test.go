@@ -0,0 +1,49 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestTraceable(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	ring := make(Ring, len(keys))
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i], ring[i] = key, &key.PublicKey
+	}
+	tag := []byte("election-2024")
+	sign := func(m byte, tag []byte, s int) *TraceableSign {
+		sig, err := SignTraceable([32]byte{m}, tag, ring, keys[s], s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyTraceable(sig) {
+			t.Fatal("valid signature rejected")
+		}
+		return sig
+	}
+	yes, no := sign(1, tag, 2), sign(2, tag, 2)
+
+	if res, pub := Trace(yes, no); res != TraceRevealed || !pointEqual(pub, ring[2]) {
+		t.Errorf("double vote not traced: have %v", res)
+	}
+	if res, _ := Trace(yes, sign(1, tag, 2)); res != TraceLinked {
+		t.Errorf("repeated vote not linked: have %v", res)
+	}
+	if res, _ := Trace(yes, sign(2, tag, 1)); res != TraceIndependent {
+		t.Errorf("votes by different members traced: have %v", res)
+	}
+	if res, _ := Trace(yes, sign(2, []byte("election-2028"), 2)); res != TraceIndependent {
+		t.Errorf("votes under different tags traced: have %v", res)
+	}
+	yes.Tag = []byte("election-2028")
+	if VerifyTraceable(yes) {
+		t.Error("signature accepted under different tag")
+	}
+}
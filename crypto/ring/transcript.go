@@ -0,0 +1,105 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+)
+
+// The challenges of the original transcript hash m || Lx || Ly || Rx || Ry with
+// coordinates in minimal big-endian form, whose lengths vary with leading zero
+// bytes. Different points can thus produce the same hash input, and other
+// implementations, which usually write coordinates at full width, compute
+// different challenges for one in every 256 coordinates or so.
+//
+// The v2 transcript hashes
+//
+//	0x02 || m || Lx || Ly || Rx || Ry
+//
+// with every coordinate padded to the coordinate size of the curve. The
+// version is kept in RingSign.Version and carried by the encodings: in the top
+// byte of the size field of the binary formats, which is always zero for v1
+// signatures, as a trailing RLP list element, as the COSE algorithm of the
+// CBOR encoding and as an optional DER field. Signatures without a version
+// use the original transcript.
+//
+// Both transcripts hash ring members to the curve with HashPoint, sha3(P)*G,
+// whose discrete logarithm is public: anybody can compute sha3(P_i)*P_i for
+// every member and compare it with the key image. The v3 transcript hashes
+//
+//	0x03 || m || Lx || Ly || Rx || Ry
+//
+// like v2, with ring members hashed to the curve by HashToPoint, which uses
+// the RFC 9380 map on secp256k1. Its key images differ from those of the
+// older transcripts, so applications that link signatures must not accept
+// more than one of them. New signatures use v3 unless asked otherwise.
+
+const (
+	// TranscriptV1 is the original transcript with minimal-length coordinates.
+	TranscriptV1 byte = 1
+
+	// TranscriptV2 is the transcript with fixed-width coordinates.
+	TranscriptV2 byte = 2
+
+	// TranscriptV3 is the v2 transcript with ring members hashed to the curve
+	// by HashToPoint.
+	TranscriptV3 byte = 3
+
+	// DefaultTranscript is the transcript of signatures created without an
+	// explicit version.
+	DefaultTranscript = TranscriptV3
+)
+
+var errTranscriptVersion = errors.New("unsupported transcript version")
+
+// transcriptVersion returns the transcript version of sig, mapping the zero
+// value to TranscriptV1.
+func transcriptVersion(sig *RingSign) byte {
+	if sig.Version == 0 {
+		return TranscriptV1
+	}
+	return sig.Version
+}
+
+// validVersion reports whether v is zero or a known transcript version.
+func validVersion(v byte) bool {
+	return v <= TranscriptV3
+}
+
+// signVersion returns the transcript version of a new signature, mapping the
+// zero value to DefaultTranscript.
+func signVersion(v byte) byte {
+	if v == 0 {
+		return DefaultTranscript
+	}
+	return v
+}
+
+// memberHasher returns the hash of ring members to the curve used by the
+// given transcript version.
+func memberHasher(version byte) func(*ecdsa.PublicKey) (*big.Int, *big.Int) {
+	if version == TranscriptV3 {
+		return HashToPoint
+	}
+	return HashPoint
+}
+
+// encodedVersion returns the version written to encodings, zero for the
+// original transcript so that v1 signatures keep their encoding.
+func encodedVersion(sig *RingSign) byte {
+	if transcriptVersion(sig) == TranscriptV1 {
+		return 0
+	}
+	return sig.Version
+}
+
+// splitSize separates the version from the 8 byte size field of the binary
+// formats.
+func splitSize(field uint64) (byte, uint64) {
+	return byte(field >> 56), field & (1<<56 - 1)
+}
+
+// joinSize is the inverse of splitSize.
+func joinSize(version byte, size int) uint64 {
+	return uint64(version)<<56 | uint64(size)
+}
@@ -0,0 +1,222 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/hashtocurve"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestChallengeV2(t *testing.T) {
+	sc := getScratch()
+	defer putScratch(sc)
+
+	m := []byte("message")
+	vals := []*big.Int{big.NewInt(0), big.NewInt(0x1234), big.NewInt(1), big.NewInt(7)}
+	input := append([]byte{TranscriptV2}, m...)
+	for _, v := range vals {
+		input = append(input, math.PaddedBigBytes(v, 32)...)
+	}
+	want := sha3.Sum256(input)
+	if have := sc.challenge(TranscriptV2, crypto.S256(), m, vals[0], vals[1], vals[2], vals[3]); have.Cmp(new(big.Int).SetBytes(want[:])) != 0 {
+		t.Fatal("v2 challenge mismatch")
+	}
+}
+
+func TestSignTranscriptV2(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	for _, ct := range []bool{false, true} {
+		sig, err := SignWithOpts([32]byte{1}, ring, keys[1], 1, &SignOpts{ConstantTime: ct, Version: TranscriptV2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig.Version != TranscriptV2 || !Verify(sig) {
+			t.Fatalf("constant time %v: v2 signature does not verify", ct)
+		}
+		// the version is part of the transcript
+		v1 := *sig
+		v1.Version = 0
+		if Verify(&v1) {
+			t.Errorf("constant time %v: v2 signature verifies with v1 transcript", ct)
+		}
+	}
+	if _, err := SignWithOpts([32]byte{}, ring, keys[0], 0, &SignOpts{Version: 4}); err != errTranscriptVersion {
+		t.Errorf("unknown version: got %v, want %v", err, errTranscriptVersion)
+	}
+}
+
+func TestTranscriptVersionEncodings(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	v1, err := SignWithOpts([32]byte{1}, ring, keys[0], 0, &SignOpts{Version: TranscriptV1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := SignWithOpts([32]byte{1}, ring, keys[0], 0, &SignOpts{Version: TranscriptV2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3, err := Sign([32]byte{1}, ring, keys[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codecs := []struct {
+		name   string
+		encode func(*RingSign) ([]byte, error)
+		decode func([]byte) (*RingSign, error)
+	}{
+		{
+			"binary",
+			func(sig *RingSign) ([]byte, error) { return sig.SerializeSignature(), nil },
+			DeserializeSignature,
+		},
+		{
+			"compressed",
+			func(sig *RingSign) ([]byte, error) { return sig.SerializeCompressed(), nil },
+			func(b []byte) (*RingSign, error) { return DeserializeCompressedSignature(crypto.S256(), b) },
+		},
+		{
+			"rlp",
+			func(sig *RingSign) ([]byte, error) { return rlp.EncodeToBytes(sig) },
+			func(b []byte) (*RingSign, error) {
+				sig := new(RingSign)
+				return sig, rlp.DecodeBytes(b, sig)
+			},
+		},
+		{
+			"cbor",
+			func(sig *RingSign) ([]byte, error) { return sig.MarshalCBOR() },
+			func(b []byte) (*RingSign, error) {
+				sig := new(RingSign)
+				return sig, sig.UnmarshalCBOR(b)
+			},
+		},
+		{
+			"der",
+			func(sig *RingSign) ([]byte, error) { return sig.MarshalDER() },
+			func(b []byte) (*RingSign, error) {
+				sig := new(RingSign)
+				return sig, sig.UnmarshalDER(b)
+			},
+		},
+	}
+	for _, codec := range codecs {
+		for _, sig := range []*RingSign{v1, v2, v3} {
+			enc, err := codec.encode(sig)
+			if err != nil {
+				t.Fatalf("%s, version %d: %v", codec.name, sig.Version, err)
+			}
+			dec, err := codec.decode(enc)
+			if err != nil {
+				t.Fatalf("%s, version %d: %v", codec.name, sig.Version, err)
+			}
+			if transcriptVersion(dec) != transcriptVersion(sig) || !Verify(dec) {
+				t.Errorf("%s, version %d: decoded version %d, valid %v", codec.name, sig.Version, dec.Version, Verify(dec))
+			}
+		}
+	}
+	// v1 signatures keep their encodings
+	if enc := v1.SerializeSignature(); enc[0] != 0 {
+		t.Error("v1 signature has version in size field")
+	}
+	enc := v2.SerializeCompressed()
+	binary.BigEndian.PutUint64(enc, joinSize(4, v2.Size))
+	if _, err := DeserializeCompressedSignature(crypto.S256(), enc); err != errTranscriptVersion {
+		t.Errorf("unknown version: got %v, want %v", err, errTranscriptVersion)
+	}
+	if _, err := v2.MarshalProto(); err != errTranscriptVersion {
+		t.Errorf("proto: got %v, want %v", err, errTranscriptVersion)
+	}
+	if _, err := PackVerify(v2); err != errTranscriptVersion {
+		t.Errorf("verifier contract: got %v, want %v", err, errTranscriptVersion)
+	}
+}
+
+func TestSignTranscriptV3(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	for _, ct := range []bool{false, true} {
+		sig, err := SignWithOpts([32]byte{1}, ring, keys[1], 1, &SignOpts{ConstantTime: ct})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig.Version != TranscriptV3 || !Verify(sig) {
+			t.Fatalf("constant time %v: default signature is not a valid v3 signature", ct)
+		}
+		if !pointEqual(sig.I, GenKeyImage(keys[1])) {
+			t.Errorf("constant time %v: key image differs from GenKeyImage", ct)
+		}
+		// the hash to the curve is part of the transcript
+		v2 := *sig
+		v2.Version = TranscriptV2
+		if Verify(&v2) {
+			t.Errorf("constant time %v: v3 signature verifies with v2 transcript", ct)
+		}
+	}
+}
+
+func TestHashToPoint(t *testing.T) {
+	_, keys := testRing(t, 1)
+	pub := &keys[0].PublicKey
+	x, y, err := hashtocurve.Secp256k1(append(hashPointDomain, pointBytes(pub)...), hashToCurveDST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hx, hy := HashToPoint(pub)
+	if hx.Cmp(x) != 0 || hy.Cmp(y) != 0 {
+		t.Fatal("HashToPoint does not use the RFC 9380 map")
+	}
+}
+
+// TestKeyImageUnlinkable checks that the key image of a v3 signature is not a
+// multiple of any ring member by a scalar anybody can derive from public data,
+// unlike the v1 image, which is sha3(P)*P for the signer.
+func TestKeyImageUnlinkable(t *testing.T) {
+	ring, keys := testRing(t, 4)
+	N := crypto.S256().Params().N
+
+	// scalars derivable from a public key
+	derive := func(pub *ecdsa.PublicKey) []*big.Int {
+		enc := pointBytes(pub)
+		legacy := sha3.Sum256(append(pub.X.Bytes(), pub.Y.Bytes()...))
+		padded := sha3.Sum256(enc)
+		return []*big.Int{
+			new(big.Int).SetBytes(legacy[:]),
+			new(big.Int).SetBytes(padded[:]),
+			new(big.Int).SetBytes(crypto.Keccak256(enc)),
+			new(big.Int).SetBytes(crypto.Keccak256(crypto.FromECDSAPub(pub))),
+			hashToScalar(pub.Curve, enc),
+			hashToScalar(pub.Curve, hashPointDomain, enc),
+		}
+	}
+	tracks := func(sig *RingSign) bool {
+		for _, pub := range sig.Ring {
+			for _, k := range derive(pub) {
+				if pointEqual(sig.I, pointMul(pub, new(big.Int).Mod(k, N))) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	v1, err := SignWithOpts([32]byte{1}, ring, keys[2], 2, &SignOpts{Version: TranscriptV1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tracks(v1) {
+		t.Fatal("v1 key image is expected to reveal the signer")
+	}
+	for s := range ring {
+		sig, err := Sign([32]byte{1}, ring, keys[s], s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tracks(sig) {
+			t.Errorf("signer %d: key image is a public multiple of a ring member", s)
+		}
+	}
+}
@@ -0,0 +1,457 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// Triptych (Noether and Goodell, "Triptych: logarithmic-sized linkable ring
+// signatures with applications", 2020) is a linkable ring signature whose size
+// grows with log2 of the ring size. It proves knowledge of the private key r of
+// one ring member M_l = r*G and publishes the key image J = r^-1*U for linking.
+//
+// Optionally every ring member is paired with an amount commitment P_k, in
+// which case the signature additionally proves that P_l - offset is a
+// commitment to zero, i.e. that the signer's commitment balances against the
+// offset commitment. This is the form used by confidential transactions.
+//
+// The bit matrix of the signer index is committed to with the vector Pedersen
+// commitment Com(v; r) = r*G + sum_i v_i*H_i over independent generators H_i.
+
+// triptychDomain separates the Fiat-Shamir challenges of Triptych from other
+// hashes in the package.
+var triptychDomain = []byte("go-ethereum/crypto/ring triptych")
+
+var (
+	triptychLock sync.Mutex
+	triptychU    = make(map[elliptic.Curve]*ecdsa.PublicKey)
+	triptychH    = make(map[elliptic.Curve][]*ecdsa.PublicKey)
+)
+
+var (
+	errCommitmentCount = errors.New("number of commitments differs from ring size")
+	errCommitmentMask  = errors.New("commitment mask does not open signer commitment")
+)
+
+// TriptychProof is the log-sized proof part of a Triptych signature. X, Y and F
+// have one entry per bit of the padded ring size.
+type TriptychProof struct {
+	A, B, C, D *ecdsa.PublicKey // commitments to the masked bit matrix
+	X, Y       []*ecdsa.PublicKey
+	F          []*big.Int
+	ZA, ZC, Z  *big.Int
+}
+
+// TriptychSign is a Triptych linkable ring signature.
+type TriptychSign struct {
+	M           [32]byte         // message
+	Ring        Ring             // array of public keys
+	Commitments Ring             // optional amount commitments, one per ring member
+	Offset      *ecdsa.PublicKey // commitment the signer's amount commitment balances against
+	I           *ecdsa.PublicKey // key image
+	K           *ecdsa.PublicKey // commitment image, set if Commitments is
+	Proof       *TriptychProof
+	Curve       elliptic.Curve
+}
+
+// triptychGenerators returns the key image base U and at least n matrix
+// commitment generators for curve.
+func triptychGenerators(curve elliptic.Curve, n int) (*ecdsa.PublicKey, []*ecdsa.PublicKey) {
+	triptychLock.Lock()
+	defer triptychLock.Unlock()
+
+	u, ok := triptychU[curve]
+	if !ok {
+		u = hashToPoint(curve, triptychDomain, []byte("U"))
+		triptychU[curve] = u
+	}
+	gens := triptychH[curve]
+	for i := len(gens); i < n; i++ {
+		var idx [8]byte
+		binary.BigEndian.PutUint64(idx[:], uint64(i))
+		gens = append(gens, hashToPoint(curve, triptychDomain, []byte("H"), idx[:]))
+	}
+	triptychH[curve] = gens
+	return u, gens[:n]
+}
+
+// triptychCommit computes the matrix commitment r*G + sum_i v_i*H_i.
+func triptychCommit(curve elliptic.Curve, gens []*ecdsa.PublicKey, v []*big.Int, r *big.Int) *ecdsa.PublicKey {
+	acc := baseMul(curve, r)
+	for i, vi := range v {
+		acc = pointAdd(acc, pointMul(gens[i], vi))
+	}
+	return acc
+}
+
+// triptychMu computes the weight that aggregates ring keys and commitments.
+func triptychMu(curve elliptic.Curve, sig *TriptychSign, ring, commitments Ring) *big.Int {
+	data := [][]byte{triptychDomain, []byte("mu"), sig.M[:]}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	for _, p := range commitments {
+		data = append(data, pointBytes(p))
+	}
+	data = append(data, pointBytes(sig.Offset), pointBytes(sig.I), pointBytes(sig.K))
+	return hashToScalar(curve, data...)
+}
+
+// triptychChallenge computes the Fiat-Shamir challenge of a signature.
+func triptychChallenge(curve elliptic.Curve, mu *big.Int, proof *TriptychProof) *big.Int {
+	data := [][]byte{triptychDomain, mu.Bytes()}
+	for _, p := range []*ecdsa.PublicKey{proof.A, proof.B, proof.C, proof.D} {
+		data = append(data, pointBytes(p))
+	}
+	for j := range proof.X {
+		data = append(data, pointBytes(proof.X[j]), pointBytes(proof.Y[j]))
+	}
+	return hashToScalar(curve, data...)
+}
+
+// SignTriptych creates a Triptych linkable ring signature over m, proving
+// knowledge of the private key of ring[s].
+func SignTriptych(m [32]byte, ring Ring, privkey *ecdsa.PrivateKey, s int) (*TriptychSign, error) {
+	return signTriptych(m, ring, nil, nil, privkey, nil, s)
+}
+
+// SignTriptychCommitments creates a Triptych signature over a ring of keys and
+// amount commitments. Besides the private key of ring[s], the signer proves that
+// commitments[s] - offset = mask*G.
+func SignTriptychCommitments(m [32]byte, ring, commitments Ring, offset *ecdsa.PublicKey, privkey *ecdsa.PrivateKey, mask *big.Int, s int) (*TriptychSign, error) {
+	if len(commitments) != len(ring) {
+		return nil, errCommitmentCount
+	}
+	return signTriptych(m, ring, commitments, offset, privkey, mask, s)
+}
+
+func signTriptych(msg [32]byte, ring, commitments Ring, offset *ecdsa.PublicKey, privkey *ecdsa.PrivateKey, mask *big.Int, l int) (*TriptychSign, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if l < 0 || l >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	if !pointEqual(ring[l], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	curve := privkey.Curve
+	N := curve.Params().N
+	if commitments != nil && !pointEqual(pointSub(commitments[l], offset), baseMul(curve, mask)) {
+		return nil, errCommitmentMask
+	}
+	m := ringBits(len(ring))
+	u, gens := triptychGenerators(curve, 2*m)
+
+	// key image J = r^-1*U and commitment image K = s*J
+	sig := &TriptychSign{
+		M:           msg,
+		Ring:        ring,
+		Commitments: commitments,
+		Offset:      offset,
+		Curve:       curve,
+	}
+	rinv := new(big.Int).ModInverse(privkey.D, N)
+	sig.I = pointMul(u, rinv)
+	if commitments != nil {
+		sig.K = pointMul(sig.I, mask)
+	}
+
+	ring = padRing(ring, m)
+	if commitments != nil {
+		commitments = padRing(commitments, m)
+	}
+	mu := triptychMu(curve, sig, ring, commitments)
+
+	// random masks: rA, rB, rC, rD followed by a_j and rho_j for every bit
+	masks := make([]*big.Int, 4+2*m)
+	for i := range masks {
+		var err error
+		if masks[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+	}
+	rA, rB, rC, rD := masks[0], masks[1], masks[2], masks[3]
+	a1, rho := masks[4:4+m], masks[4+m:]
+
+	// matrices are laid out as v[2*j+i] for bit j and value i
+	sigma := make([]*big.Int, 2*m)
+	a := make([]*big.Int, 2*m)
+	c := make([]*big.Int, 2*m)
+	d := make([]*big.Int, 2*m)
+	for j := 0; j < m; j++ {
+		bit := int64((l >> uint(j)) & 1)
+		sigma[2*j], sigma[2*j+1] = big.NewInt(1-bit), big.NewInt(bit)
+		a[2*j], a[2*j+1] = new(big.Int).Neg(a1[j]), a1[j]
+	}
+	for i := range a {
+		// c = a*(1-2*sigma), d = -a^2
+		c[i] = new(big.Int).Mul(a[i], big.NewInt(1-2*sigma[i].Int64()))
+		d[i] = new(big.Int).Mul(a[i], a[i])
+		d[i].Neg(d[i])
+	}
+	proof := &TriptychProof{
+		A: triptychCommit(curve, gens, a, rA),
+		B: triptychCommit(curve, gens, sigma, rB),
+		C: triptychCommit(curve, gens, c, rC),
+		D: triptychCommit(curve, gens, d, rD),
+		X: make([]*ecdsa.PublicKey, m),
+		Y: make([]*ecdsa.PublicKey, m),
+		F: make([]*big.Int, m),
+	}
+
+	// X_j = sum_k p_{k,j}*(M_k + mu*(P_k - offset)) + rho_j*G, Y_j = rho_j*J
+	for k, key := range ring {
+		q := key
+		if commitments != nil {
+			q = pointAdd(q, pointMul(pointSub(commitments[k], offset), mu))
+		}
+		p := []*big.Int{big.NewInt(1)}
+		for j := 0; j < m; j++ {
+			i := (k >> uint(j)) & 1
+			p = polyMulLinear(p, sigma[2*j+i], a[2*j+i], N)
+		}
+		for j := 0; j < m; j++ {
+			proof.X[j] = pointAdd(proof.X[j], pointMul(q, p[j]))
+		}
+	}
+	for j := 0; j < m; j++ {
+		proof.X[j] = pointAdd(proof.X[j], baseMul(curve, rho[j]))
+		proof.Y[j] = pointMul(sig.I, rho[j])
+	}
+
+	x := triptychChallenge(curve, mu, proof)
+
+	for j := 0; j < m; j++ {
+		f := new(big.Int).Mul(sigma[2*j+1], x)
+		proof.F[j] = f.Add(f, a[2*j+1]).Mod(f, N)
+	}
+	zA := new(big.Int).Mul(rB, x)
+	proof.ZA = zA.Add(zA, rA).Mod(zA, N)
+	zC := new(big.Int).Mul(rC, x)
+	proof.ZC = zC.Add(zC, rD).Mod(zC, N)
+
+	// z = (r + mu*s)*x^m - sum_j rho_j*x^j
+	z := new(big.Int)
+	xj := big.NewInt(1)
+	for j := 0; j < m; j++ {
+		z.Sub(z, new(big.Int).Mul(rho[j], xj))
+		xj.Mul(xj, x).Mod(xj, N)
+	}
+	secret := new(big.Int).Set(privkey.D)
+	if commitments != nil {
+		secret.Add(secret, new(big.Int).Mul(mu, mask))
+	}
+	z.Add(z, secret.Mul(secret, xj))
+	proof.Z = z.Mod(z, N)
+
+	sig.Proof = proof
+	return sig, nil
+}
+
+// triptychEquations adds the four verification equations of sig, each weighted
+// by a fresh random scalar, to me. The signature is valid if and only if all
+// equations sum to the identity, except with negligible probability.
+func triptychEquations(sig *TriptychSign, me *multiExp) bool {
+	proof := sig.Proof
+	if proof == nil || sig.I == nil || len(sig.Ring) < 2 {
+		return false
+	}
+	if proof.A == nil || proof.B == nil || proof.C == nil || proof.D == nil ||
+		proof.ZA == nil || proof.ZC == nil || proof.Z == nil {
+		return false
+	}
+	m := ringBits(len(sig.Ring))
+	if len(proof.X) != m || len(proof.Y) != m || len(proof.F) != m {
+		return false
+	}
+	for j := 0; j < m; j++ {
+		if proof.X[j] == nil || proof.Y[j] == nil || proof.F[j] == nil {
+			return false
+		}
+	}
+	for _, p := range sig.Ring {
+		if p == nil {
+			return false
+		}
+	}
+	commitments := sig.Commitments
+	if commitments != nil {
+		if len(commitments) != len(sig.Ring) || sig.K == nil {
+			return false
+		}
+		for _, p := range commitments {
+			if p == nil {
+				return false
+			}
+		}
+	}
+	curve := sig.Curve
+	N := curve.Params().N
+	u, gens := triptychGenerators(curve, 2*m)
+
+	ring := padRing(sig.Ring, m)
+	if commitments != nil {
+		commitments = padRing(commitments, m)
+	}
+	mu := triptychMu(curve, sig, ring, commitments)
+	x := triptychChallenge(curve, mu, proof)
+
+	w := make([]*big.Int, 4)
+	for i := range w {
+		var err error
+		if w[i], err = randomScalar(curve); err != nil {
+			return false
+		}
+	}
+	mul := func(a, b *big.Int) *big.Int {
+		r := new(big.Int).Mul(a, b)
+		return r.Mod(r, N)
+	}
+	neg := func(a *big.Int) *big.Int {
+		r := new(big.Int).Neg(a)
+		return r.Mod(r, N)
+	}
+
+	// full matrix f with f_{j,0} = x - f_{j,1}
+	f := make([]*big.Int, 2*m)
+	for j := 0; j < m; j++ {
+		f[2*j] = new(big.Int).Sub(x, proof.F[j])
+		f[2*j+1] = proof.F[j]
+	}
+
+	// A + x*B - Com(f; zA) == 0
+	me.add(proof.A, w[0])
+	me.add(proof.B, mul(w[0], x))
+	me.addBase(neg(mul(w[0], proof.ZA)))
+	for i, fi := range f {
+		me.add(gens[i], neg(mul(w[0], fi)))
+	}
+
+	// x*C + D - Com(f*(x-f); zC) == 0
+	me.add(proof.C, mul(w[1], x))
+	me.add(proof.D, w[1])
+	me.addBase(neg(mul(w[1], proof.ZC)))
+	for i, fi := range f {
+		me.add(gens[i], neg(mul(w[1], mul(fi, new(big.Int).Sub(x, fi)))))
+	}
+
+	// sum_k t_k*(M_k + mu*P_k) - mu*x^m*offset - sum_j x^j*X_j - z*G == 0
+	// x^m*(U + mu*K) - sum_j x^j*Y_j - z*J == 0
+	for k, key := range ring {
+		t := big.NewInt(1)
+		for j := 0; j < m; j++ {
+			t = mul(t, f[2*j+int((k>>uint(j))&1)])
+		}
+		me.add(key, mul(w[2], t))
+		if commitments != nil {
+			me.add(commitments[k], mul(w[2], mul(mu, t)))
+		}
+	}
+	xj := big.NewInt(1)
+	for j := 0; j < m; j++ {
+		me.add(proof.X[j], neg(mul(w[2], xj)))
+		me.add(proof.Y[j], neg(mul(w[3], xj)))
+		xj = mul(xj, x)
+	}
+	if commitments != nil {
+		me.add(sig.Offset, neg(mul(w[2], mul(mu, xj))))
+		me.add(sig.K, mul(w[3], mul(mu, xj)))
+	}
+	me.addBase(neg(mul(w[2], proof.Z)))
+	me.add(u, mul(w[3], xj))
+	me.add(sig.I, neg(mul(w[3], proof.Z)))
+
+	return true
+}
+
+// VerifyTriptych verifies a Triptych signature.
+// returns true if a valid signature, false otherwise
+func VerifyTriptych(sig *TriptychSign) bool {
+	return VerifyTriptychBatch([]*TriptychSign{sig})
+}
+
+// VerifyTriptychBatch verifies many Triptych signatures at once by combining
+// their verification equations with random weights into a single sum. Points
+// shared between signatures, such as overlapping ring members and the
+// commitment generators, are multiplied only once. All signatures must be on
+// the same curve.
+// returns true if every signature is valid, false otherwise
+func VerifyTriptychBatch(sigs []*TriptychSign) bool {
+	if len(sigs) == 0 || sigs[0] == nil {
+		return false
+	}
+	me := newMultiExp(sigs[0].Curve)
+	for _, sig := range sigs {
+		if sig == nil || sig.Curve != me.curve {
+			return false
+		}
+		if !triptychEquations(sig, me) {
+			return false
+		}
+	}
+	return me.sum() == nil
+}
+
+// TriptychKeyImage returns the key image r^-1*U shared by all Triptych
+// signatures made with privkey, which tells whether an output was spent.
+func TriptychKeyImage(privkey *ecdsa.PrivateKey) *ecdsa.PublicKey {
+	u, _ := triptychGenerators(privkey.Curve, 0)
+	return pointMul(u, new(big.Int).ModInverse(privkey.D, privkey.Curve.Params().N))
+}
+
+// LinkTriptych reports whether two Triptych signatures were created with the
+// same private key.
+func LinkTriptych(a, b *TriptychSign) bool {
+	return pointEqual(a.I, b.I)
+}
+
+// KeyImageProof proves that a Triptych key image belongs to a public key, so
+// the owner of an output can disclose whether it was spent without revealing
+// its private key.
+type KeyImageProof struct {
+	C, S *big.Int
+}
+
+// keyImageChallenge computes the challenge of a key image proof.
+func keyImageChallenge(pub, image, a, b *ecdsa.PublicKey) *big.Int {
+	return hashToScalar(pub.Curve, triptychDomain, []byte("image"), pointBytes(pub), pointBytes(image), pointBytes(a), pointBytes(b))
+}
+
+// ProveTriptychKeyImage returns the key image J of privkey with a proof that
+// it belongs to the public key M = r*G, showing that log_G(M) = log_J(U).
+func ProveTriptychKeyImage(privkey *ecdsa.PrivateKey) (*ecdsa.PublicKey, *KeyImageProof, error) {
+	curve := privkey.Curve
+	image := TriptychKeyImage(privkey)
+
+	w, err := randomScalar(curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := keyImageChallenge(&privkey.PublicKey, image, baseMul(curve, w), pointMul(image, w))
+
+	// s = w - c*r
+	s := new(big.Int).Mul(c, privkey.D)
+	s.Sub(w, s)
+	return image, &KeyImageProof{C: c, S: s.Mod(s, curve.Params().N)}, nil
+}
+
+// VerifyTriptychKeyImage reports whether image is the Triptych key image of
+// pub according to proof.
+func VerifyTriptychKeyImage(pub, image *ecdsa.PublicKey, proof *KeyImageProof) bool {
+	if pub == nil || image == nil || proof == nil || proof.C == nil || proof.S == nil {
+		return false
+	}
+	curve := pub.Curve
+	if !curve.IsOnCurve(image.X, image.Y) {
+		return false
+	}
+	u, _ := triptychGenerators(curve, 0)
+	a := pointAdd(baseMul(curve, proof.S), pointMul(pub, proof.C))
+	b := pointAdd(pointMul(image, proof.S), pointMul(u, proof.C))
+	return keyImageChallenge(pub, image, a, b).Cmp(proof.C) == 0
+}
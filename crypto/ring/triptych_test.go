@@ -0,0 +1,115 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestTriptych(t *testing.T) {
+	for _, size := range []int{2, 5, 16} {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := size - 1
+		ring := GenNewKeyRing(size, key, s)
+		msg := [32]byte{byte(size)}
+
+		sig, err := SignTriptych(msg, ring, key, s)
+		if err != nil {
+			t.Fatalf("size %d: failed to sign: %v", size, err)
+		}
+		if !VerifyTriptych(sig) {
+			t.Fatalf("size %d: valid signature rejected", size)
+		}
+		sig.M[0] ^= 1
+		if VerifyTriptych(sig) {
+			t.Errorf("size %d: signature over different message accepted", size)
+		}
+	}
+}
+
+func TestTriptychCommitments(t *testing.T) {
+	curve := crypto.S256()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(4, key, 1)
+
+	// the signer's commitment hides 10 with mask 7, the offset hides 10 with
+	// mask 3, so their difference is a commitment to zero with mask 4
+	commitments := make(Ring, len(ring))
+	for i := range commitments {
+		commitments[i] = Commit(curve, big.NewInt(int64(i+20)), big.NewInt(int64(i+1)))
+	}
+	commitments[1] = Commit(curve, big.NewInt(10), big.NewInt(7))
+	offset := Commit(curve, big.NewInt(10), big.NewInt(3))
+
+	sig, err := SignTriptychCommitments([32]byte{1}, ring, commitments, offset, key, big.NewInt(4), 1)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if !VerifyTriptych(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	sig.Offset = Commit(curve, big.NewInt(11), big.NewInt(3))
+	if VerifyTriptych(sig) {
+		t.Error("signature with unbalanced offset accepted")
+	}
+	if _, err := SignTriptychCommitments([32]byte{1}, ring, commitments, offset, key, big.NewInt(5), 1); err != errCommitmentMask {
+		t.Errorf("error mismatch: have %v, want %v", err, errCommitmentMask)
+	}
+}
+
+func TestTriptychBatchAndLink(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(8, key, 3)
+
+	sigs := make([]*TriptychSign, 3)
+	for i := range sigs {
+		if sigs[i], err = SignTriptych([32]byte{byte(i)}, ring, key, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !VerifyTriptychBatch(sigs) {
+		t.Fatal("valid batch rejected")
+	}
+	if !LinkTriptych(sigs[0], sigs[1]) {
+		t.Error("signatures by the same key not linked")
+	}
+	if !pointEqual(TriptychKeyImage(key), sigs[0].I) {
+		t.Error("key image doesn't match the signatures")
+	}
+	sigs[2].Proof.Z = new(big.Int).Add(sigs[2].Proof.Z, big.NewInt(1))
+	if VerifyTriptychBatch(sigs) {
+		t.Error("batch with invalid signature accepted")
+	}
+}
+
+func TestTriptychKeyImageProof(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+
+	image, proof, err := ProveTriptychKeyImage(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pointEqual(image, TriptychKeyImage(key)) {
+		t.Fatal("proved key image mismatch")
+	}
+	if !VerifyTriptychKeyImage(&key.PublicKey, image, proof) {
+		t.Fatal("valid key image proof rejected")
+	}
+	if VerifyTriptychKeyImage(&other.PublicKey, image, proof) {
+		t.Error("key image proof accepted for another key")
+	}
+	if VerifyTriptychKeyImage(&key.PublicKey, TriptychKeyImage(other), proof) {
+		t.Error("key image proof accepted for another image")
+	}
+}
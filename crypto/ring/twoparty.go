@@ -0,0 +1,348 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Two-party signing splits a ring member's private key additively between a
+// client and a co-signing service, x = x_1 + x_2, so that neither machine
+// ever holds x. Key generation exchanges the public shares X_i = x_i*G with
+// proofs of knowledge of x_i, which keeps either party from choosing its share
+// as a function of the other's to control the joint key P = X_1 + X_2.
+//
+// Signing takes four messages:
+//
+//	client -> service  TwoPartyRequest  message, ring, hash of client nonces
+//	service -> client  TwoPartyNonces   service nonces and partial key image
+//	client -> service  TwoPartyReveal   client nonces, partial key image and
+//	                                    the responses of the decoys
+//	service -> client  TwoPartyResponse z_2 = u_2 - c_s*x_2
+//
+// The client commits to its nonces before seeing the service's, so neither
+// side can bias the joint nonce. The service recomputes the challenge chain
+// from the revealed values and only answers the challenge of the message and
+// ring it was asked to sign. Either party checks the other's response against
+// its public share and partial key image, as in ShareSession.
+
+// twoPartyDomain separates the hashes of two-party signing from other hashes
+// in the package.
+var twoPartyDomain = []byte("go-ethereum/crypto/ring two-party")
+
+var (
+	errKeyGenProof     = errors.New("invalid proof of knowledge of key share")
+	errNonceCommitment = errors.New("revealed nonces do not match commitment")
+)
+
+// TwoPartyKeyGen is a party's state during key generation.
+type TwoPartyKeyGen struct {
+	share *ecdsa.PrivateKey
+}
+
+// TwoPartyKeyGenMessage is the key generation message of a party: its public
+// share and a proof of knowledge of the private share.
+type TwoPartyKeyGenMessage struct {
+	Share *ecdsa.PublicKey
+	C, S  *big.Int
+}
+
+// twoPartyProofChallenge computes the challenge of the proof of knowledge of
+// the private share of X with nonce commitment R.
+func twoPartyProofChallenge(X, R *ecdsa.PublicKey) *big.Int {
+	return hashToScalar(X.Curve, twoPartyDomain, []byte("keygen"), pointBytes(X), pointBytes(R))
+}
+
+// NewTwoPartyKeyGen creates a key share on curve and returns the message to
+// send to the other party.
+func NewTwoPartyKeyGen(curve elliptic.Curve) (*TwoPartyKeyGen, *TwoPartyKeyGenMessage, error) {
+	x, err := randomScalar(curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	k, err := randomScalar(curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	share := &ecdsa.PrivateKey{PublicKey: *baseMul(curve, x), D: x}
+	c := twoPartyProofChallenge(&share.PublicKey, baseMul(curve, k))
+	s := new(big.Int).Mul(c, x)
+	s.Sub(k, s).Mod(s, curve.Params().N)
+
+	return &TwoPartyKeyGen{share: share}, &TwoPartyKeyGenMessage{Share: &share.PublicKey, C: c, S: s}, nil
+}
+
+// Finish checks the other party's key generation message and returns the key
+// share of this party.
+func (kg *TwoPartyKeyGen) Finish(peer *TwoPartyKeyGenMessage) (*TwoPartyShare, error) {
+	curve := kg.share.Curve
+	if peer == nil || peer.C == nil || peer.S == nil || peer.Share == nil || peer.Share.Curve != curve ||
+		!canonicalPoint(curve, peer.Share.X, peer.Share.Y) {
+		return nil, errKeyGenProof
+	}
+	R := pointAdd(baseMul(curve, peer.S), pointMul(peer.Share, peer.C))
+	if twoPartyProofChallenge(peer.Share, R).Cmp(peer.C) != 0 {
+		return nil, errKeyGenProof
+	}
+	public := pointAdd(&kg.share.PublicKey, peer.Share)
+	if public == nil {
+		return nil, errKeyGenProof
+	}
+	return &TwoPartyShare{Public: public, key: kg.share, peer: peer.Share}, nil
+}
+
+// TwoPartyShare is a party's additive share of a ring member's key.
+type TwoPartyShare struct {
+	Public *ecdsa.PublicKey // joint public key, the ring member
+
+	key  *ecdsa.PrivateKey // own share
+	peer *ecdsa.PublicKey  // other party's public share
+}
+
+// twoPartyNonces are the nonce commitments and partial key image of a party.
+type twoPartyNonces struct {
+	image, U, V *ecdsa.PublicKey
+}
+
+// newTwoPartyNonces picks the nonce of the holder of share.
+func newTwoPartyNonces(share *TwoPartyShare, h *ecdsa.PublicKey) (*big.Int, *twoPartyNonces, error) {
+	u, err := randomScalar(share.key.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, &twoPartyNonces{
+		image: pointMul(h, share.key.D),
+		U:     baseMul(share.key.Curve, u),
+		V:     pointMul(h, u),
+	}, nil
+}
+
+// commitment returns the hash the client commits to its nonces with.
+func (n *twoPartyNonces) commitment() (h [32]byte) {
+	hw := sha3.NewKeccak256()
+	hw.Write(twoPartyDomain)
+	hw.Write(pointBytes(n.image))
+	hw.Write(pointBytes(n.U))
+	hw.Write(pointBytes(n.V))
+	hw.Sum(h[:0])
+	return h
+}
+
+// respond returns z = u - c*x.
+func (share *TwoPartyShare) respond(u, c *big.Int) *big.Int {
+	z := new(big.Int).Mul(c, share.key.D)
+	z.Sub(u, z)
+	return z.Mod(z, share.key.Curve.Params().N)
+}
+
+// twoPartyChallenges combines the nonces of both parties and computes the
+// challenges of a signature with the given decoy responses.
+func twoPartyChallenges(m [32]byte, ring Ring, s int, S []*big.Int, h *ecdsa.PublicKey, a, b *twoPartyNonces) (*ecdsa.PublicKey, []*big.Int, error) {
+	image, L, R := pointAdd(a.image, b.image), pointAdd(a.U, b.U), pointAdd(a.V, b.V)
+	if image == nil || L == nil || R == nil {
+		return nil, nil, errInvalidResponse
+	}
+	sc := getScratch()
+	defer putScratch(sc)
+
+	return image, ringChallenges(sc, h.Curve, m, ring, s, S, image, HashToPoint, DefaultTranscript, L.X, L.Y, R.X, R.Y), nil
+}
+
+// hashPointOf returns H_p(P) of DefaultTranscript as a point.
+func hashPointOf(pub *ecdsa.PublicKey) *ecdsa.PublicKey {
+	hx, hy := HashToPoint(pub)
+	return newPoint(pub.Curve, hx, hy)
+}
+
+// TwoPartyRequest asks the service to co-sign M over Ring.
+type TwoPartyRequest struct {
+	M          [32]byte
+	Ring       Ring
+	Commitment [32]byte // hash of the client's nonces
+}
+
+// TwoPartyNonces is the service's answer to a request.
+type TwoPartyNonces struct {
+	Image *ecdsa.PublicKey // partial key image x_2*H_p(P)
+	U, V  *ecdsa.PublicKey // nonce commitments u_2*G and u_2*H_p(P)
+}
+
+// TwoPartyReveal opens the client's nonces and fixes the decoy responses.
+type TwoPartyReveal struct {
+	Image *ecdsa.PublicKey // partial key image x_1*H_p(P)
+	U, V  *ecdsa.PublicKey // nonce commitments u_1*G and u_1*H_p(P)
+	S     []*big.Int       // responses of all members, nil at the signer
+}
+
+// TwoPartyResponse is the service's partial response.
+type TwoPartyResponse struct {
+	Z *big.Int
+}
+
+// TwoPartyClient is the state of the client in a signing session. A client is
+// used for one signature.
+type TwoPartyClient struct {
+	share *TwoPartyShare
+	req   *TwoPartyRequest
+	index int
+	h     *ecdsa.PublicKey
+
+	u       *big.Int
+	own     *twoPartyNonces
+	service *twoPartyNonces
+	image   *ecdsa.PublicKey
+	S, C    []*big.Int
+}
+
+// NewTwoPartyClient starts signing m over ring, which must contain the joint
+// public key of share, and returns the request to send to the service.
+func NewTwoPartyClient(share *TwoPartyShare, m [32]byte, ring Ring) (*TwoPartyClient, *TwoPartyRequest, error) {
+	if len(ring) < 2 {
+		return nil, nil, errRingTooSmall
+	}
+	if err := ring.Validate(); err != nil {
+		return nil, nil, err
+	}
+	index := ring.Index(share.Public)
+	if index < 0 {
+		return nil, nil, errKeyNotInRing
+	}
+	h := hashPointOf(share.Public)
+	u, own, err := newTwoPartyNonces(share, h)
+	if err != nil {
+		return nil, nil, err
+	}
+	req := &TwoPartyRequest{M: m, Ring: append(Ring{}, ring...), Commitment: own.commitment()}
+	return &TwoPartyClient{share: share, req: req, index: index, h: h, u: u, own: own}, req, nil
+}
+
+// Reveal takes the service's nonces and returns the message opening the
+// client's nonces.
+func (c *TwoPartyClient) Reveal(msg *TwoPartyNonces) (*TwoPartyReveal, error) {
+	if c.service != nil || c.u == nil {
+		return nil, errSessionState
+	}
+	if msg == nil || msg.Image == nil || msg.U == nil || msg.V == nil {
+		return nil, errInvalidResponse
+	}
+	curve := c.h.Curve
+	S := make([]*big.Int, len(c.req.Ring))
+	for i := range S {
+		if i == c.index {
+			continue
+		}
+		var err error
+		if S[i], err = randomScalar(curve); err != nil {
+			return nil, err
+		}
+	}
+	service := &twoPartyNonces{image: msg.Image, U: msg.U, V: msg.V}
+	image, C, err := twoPartyChallenges(c.req.M, c.req.Ring, c.index, S, c.h, c.own, service)
+	if err != nil {
+		return nil, err
+	}
+	c.service, c.image, c.S, c.C = service, image, S, C
+	return &TwoPartyReveal{Image: c.own.image, U: c.own.U, V: c.own.V, S: S}, nil
+}
+
+// Finalize checks the service's response and returns the ring signature.
+func (c *TwoPartyClient) Finalize(msg *TwoPartyResponse) (*RingSign, error) {
+	if c.service == nil || c.u == nil {
+		return nil, errSessionState
+	}
+	N := c.h.Curve.Params().N
+	if msg == nil || msg.Z == nil || msg.Z.Sign() < 0 || msg.Z.Cmp(N) >= 0 {
+		return nil, errInvalidResponse
+	}
+	chal := c.C[c.index]
+	if !partialValid(c.h, c.share.peer, c.service.image, c.service.U, c.service.V, msg.Z, chal) {
+		return nil, errInvalidResponse
+	}
+	z := c.share.respond(c.u, chal)
+	c.u = nil
+
+	S := append([]*big.Int{}, c.S...)
+	S[c.index] = z.Add(z, msg.Z).Mod(z, N)
+	return &RingSign{
+		Size:    len(c.req.Ring),
+		M:       c.req.M,
+		C:       c.C[0],
+		S:       S,
+		Ring:    c.req.Ring,
+		I:       c.image,
+		Curve:   c.h.Curve,
+		Version: DefaultTranscript,
+	}, nil
+}
+
+// TwoPartyService is the state of the co-signing service in a signing
+// session. A service session is used for one signature.
+type TwoPartyService struct {
+	share *TwoPartyShare
+	req   *TwoPartyRequest
+	index int
+	h     *ecdsa.PublicKey
+	u     *big.Int
+	own   *twoPartyNonces
+}
+
+// NewTwoPartyService creates the service side of a signing session with
+// share. The service decides whether to co-sign a request before passing it
+// to Commit.
+func NewTwoPartyService(share *TwoPartyShare) *TwoPartyService {
+	return &TwoPartyService{share: share, h: hashPointOf(share.Public)}
+}
+
+// Commit accepts a signing request and returns the service's nonces.
+func (s *TwoPartyService) Commit(req *TwoPartyRequest) (*TwoPartyNonces, error) {
+	if s.req != nil {
+		return nil, errSessionState
+	}
+	if req == nil || len(req.Ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if err := req.Ring.Validate(); err != nil {
+		return nil, err
+	}
+	index := req.Ring.Index(s.share.Public)
+	if index < 0 {
+		return nil, errKeyNotInRing
+	}
+	u, own, err := newTwoPartyNonces(s.share, s.h)
+	if err != nil {
+		return nil, err
+	}
+	s.req, s.index, s.u, s.own = req, index, u, own
+	return &TwoPartyNonces{Image: own.image, U: own.U, V: own.V}, nil
+}
+
+// Respond checks the client's revealed nonces and returns the service's
+// partial response. The service's nonce is erased afterwards.
+func (s *TwoPartyService) Respond(msg *TwoPartyReveal) (*TwoPartyResponse, error) {
+	if s.req == nil || s.u == nil {
+		return nil, errSessionState
+	}
+	if msg == nil || msg.Image == nil || msg.U == nil || msg.V == nil || len(msg.S) != len(s.req.Ring) {
+		return nil, errInvalidResponse
+	}
+	client := &twoPartyNonces{image: msg.Image, U: msg.U, V: msg.V}
+	if client.commitment() != s.req.Commitment {
+		return nil, errNonceCommitment
+	}
+	N := s.h.Curve.Params().N
+	for i, si := range msg.S {
+		if i != s.index && (si == nil || si.Sign() < 0 || si.Cmp(N) >= 0) {
+			return nil, errScalarRange
+		}
+	}
+	_, C, err := twoPartyChallenges(s.req.M, s.req.Ring, s.index, msg.S, s.h, client, s.own)
+	if err != nil {
+		return nil, err
+	}
+	z := s.share.respond(s.u, C[s.index])
+	s.u = nil
+	return &TwoPartyResponse{Z: z}, nil
+}
@@ -0,0 +1,108 @@
+package ring
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTwoPartyShares(t *testing.T) (*TwoPartyShare, *TwoPartyShare) {
+	kg1, msg1, err := NewTwoPartyKeyGen(crypto.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	kg2, msg2, err := NewTwoPartyKeyGen(crypto.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := kg1.Finish(msg2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := kg2.Finish(msg1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !PublicKeyEqual(client.Public, service.Public) {
+		t.Fatal("parties disagree on the joint key")
+	}
+	return client, service
+}
+
+func TestTwoPartySign(t *testing.T) {
+	clientShare, serviceShare := newTwoPartyShares(t)
+	ring, _ := testRing(t, 3)
+	ring = append(ring, clientShare.Public)
+
+	client, req, err := NewTwoPartyClient(clientShare, [32]byte{1}, ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewTwoPartyService(serviceShare)
+	nonces, err := service.Commit(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reveal, err := client.Reveal(nonces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := service.Respond(reveal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Respond(reveal); err != errSessionState {
+		t.Errorf("second response: got %v, want %v", err, errSessionState)
+	}
+	sig, err := client.Finalize(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(sig) {
+		t.Fatal("signature does not verify")
+	}
+	// the key image is that of the joint key
+	joint := new(big.Int).Add(clientShare.key.D, serviceShare.key.D)
+	key, err := crypto.ToECDSA(PadTo32Bytes(joint.Mod(joint, crypto.S256().Params().N).Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.KeyImage().Equal(KeyImage{GenKeyImage(key)}) {
+		t.Error("wrong key image")
+	}
+}
+
+func TestTwoPartyMisbehaviour(t *testing.T) {
+	clientShare, serviceShare := newTwoPartyShares(t)
+	ring, _ := testRing(t, 2)
+	ring = append(ring, clientShare.Public)
+
+	// a client changing its nonces after seeing the service's
+	client, req, _ := NewTwoPartyClient(clientShare, [32]byte{1}, ring)
+	service := NewTwoPartyService(serviceShare)
+	nonces, _ := service.Commit(req)
+	reveal, _ := client.Reveal(nonces)
+	reveal.U = pointAdd(reveal.U, baseMul(crypto.S256(), big.NewInt(1)))
+	if _, err := service.Respond(reveal); err != errNonceCommitment {
+		t.Errorf("changed nonces: got %v, want %v", err, errNonceCommitment)
+	}
+
+	// a service answering with a wrong response
+	client, req, _ = NewTwoPartyClient(clientShare, [32]byte{1}, ring)
+	service = NewTwoPartyService(serviceShare)
+	nonces, _ = service.Commit(req)
+	reveal, _ = client.Reveal(nonces)
+	resp, _ := service.Respond(reveal)
+	resp.Z = new(big.Int).Add(resp.Z, big.NewInt(1))
+	if _, err := client.Finalize(resp); err != errInvalidResponse {
+		t.Errorf("wrong response: got %v, want %v", err, errInvalidResponse)
+	}
+
+	// a key generation message without proof
+	kg, msg, _ := NewTwoPartyKeyGen(crypto.S256())
+	msg.S = new(big.Int).Add(msg.S, big.NewInt(1))
+	if _, err := kg.Finish(msg); err != errKeyGenProof {
+		t.Errorf("bad proof: got %v, want %v", err, errKeyGenProof)
+	}
+}
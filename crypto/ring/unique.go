@@ -0,0 +1,106 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Unique ring signatures (Franklin and Zhang, "Unique Ring Signatures: A
+// Practical Construction", 2013) carry a token T = x*H_p(ring, m) that is the
+// same for every signature a member creates on a given ring and message, and
+// differs between members. Services can therefore rate-limit or deduplicate
+// anonymous requests by token without learning who sent them. The signature
+// proves that log_G(P_j) = log_h(T) for some ring member j.
+
+// uniqueDomain separates the hashes of unique ring signatures from other
+// hashes in the package.
+var uniqueDomain = []byte("go-ethereum/crypto/ring unique")
+
+// UniqueSign is a Franklin-Zhang unique ring signature.
+type UniqueSign struct {
+	M     [32]byte         // message
+	Ring  Ring             // array of public keys
+	T     *ecdsa.PublicKey // unique token of the signer for ring and message
+	C     []*big.Int       // challenges, one per ring member
+	S     []*big.Int       // responses, one per ring member
+	Curve elliptic.Curve
+}
+
+// uniqueBase hashes the ring and message into the token base h.
+func uniqueBase(curve elliptic.Curve, ring Ring, m [32]byte) *ecdsa.PublicKey {
+	data := [][]byte{uniqueDomain, m[:]}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	return hashToPoint(curve, data...)
+}
+
+// uniquePrefix returns the data the challenge is computed over besides the
+// proof commitments.
+func uniquePrefix(ring Ring, m [32]byte, token *ecdsa.PublicKey) [][]byte {
+	data := [][]byte{uniqueDomain, []byte("c"), m[:]}
+	for _, p := range ring {
+		data = append(data, pointBytes(p))
+	}
+	return append(data, pointBytes(token))
+}
+
+// uniqueImages returns the token repeated for every ring member.
+func uniqueImages(token *ecdsa.PublicKey, n int) []*ecdsa.PublicKey {
+	images := make([]*ecdsa.PublicKey, n)
+	for i := range images {
+		images[i] = token
+	}
+	return images
+}
+
+// SignUnique creates a unique ring signature over m, proving knowledge of the
+// private key of ring[s].
+func SignUnique(m [32]byte, ring Ring, privkey *ecdsa.PrivateKey, s int) (*UniqueSign, error) {
+	if len(ring) < 2 {
+		return nil, errRingTooSmall
+	}
+	if s < 0 || s >= len(ring) {
+		return nil, errIndexOutOfRange
+	}
+	if !pointEqual(ring[s], &privkey.PublicKey) {
+		return nil, errNotSigner
+	}
+	curve := privkey.Curve
+	h := uniqueBase(curve, ring, m)
+	token := pointMul(h, privkey.D)
+
+	C, S, err := proveDLEQOr(uniquePrefix(ring, m, token), h, ring, uniqueImages(token, len(ring)), privkey, s)
+	if err != nil {
+		return nil, err
+	}
+	return &UniqueSign{
+		M:     m,
+		Ring:  ring,
+		T:     token,
+		C:     C,
+		S:     S,
+		Curve: curve,
+	}, nil
+}
+
+// VerifyUnique verifies a unique ring signature.
+// returns true if a valid signature, false otherwise
+func VerifyUnique(sig *UniqueSign) bool {
+	if sig == nil || sig.T == nil || len(sig.Ring) < 2 {
+		return false
+	}
+	h := uniqueBase(sig.Curve, sig.Ring, sig.M)
+	images := uniqueImages(sig.T, len(sig.Ring))
+
+	return verifyDLEQOr(sig.Curve, uniquePrefix(sig.Ring, sig.M, sig.T), h, sig.Ring, images, sig.C, sig.S)
+}
+
+// Token returns a compact identifier of the signature's unique token, suitable
+// as a deduplication or rate-limiting key.
+func (sig *UniqueSign) Token() [32]byte {
+	return sha3.Sum256(pointBytes(sig.T))
+}
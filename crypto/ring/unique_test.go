@@ -0,0 +1,41 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestUnique(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := GenNewKeyRing(4, key, 1)
+	sign := func(m byte) *UniqueSign {
+		sig, err := SignUnique([32]byte{m}, ring, key, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyUnique(sig) {
+			t.Fatal("valid signature rejected")
+		}
+		return sig
+	}
+	a, b, c := sign(1), sign(1), sign(2)
+	if a.Token() != b.Token() {
+		t.Error("token differs for same ring and message")
+	}
+	if a.Token() == c.Token() {
+		t.Error("token equal for different messages")
+	}
+	// a token of another member must not verify
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.T = pointMul(uniqueBase(a.Curve, a.Ring, a.M), other.D)
+	if VerifyUnique(a) {
+		t.Error("signature with foreign token accepted")
+	}
+}
@@ -0,0 +1,152 @@
+package ring
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+//go:generate go run gen_vectors.go -out testdata/lsag_vectors.json
+
+// Test vectors pin the linkable ring signature (Sign, Verify) down to the byte
+// so that independent implementations can check interoperability. Signing is
+// made deterministic by fixing the glue value u and the responses s_i of all
+// members but the signer.
+//
+// All values are big endian and of fixed length: scalars and the message take
+// 32 bytes, public keys and the key image are SEC1 uncompressed points
+// 0x04 || X || Y with 32 byte coordinates. The signature uses the layout of
+// SerializeSignature:
+//
+//	n (8) || m (32) || c_0 (32) || n * (s_i (32) || X_i (32) || Y_i (32)) || I.X (32) || I.Y (32)
+//
+// The signing transcript on secp256k1 is
+//
+//	H_p(P)  = SHA3-256(X_P || Y_P) * G
+//	I       = x * H_p(P_s)
+//	L_i     = s_i*G + c_i*P_i,  R_i = s_i*H_p(P_i) + c_i*I
+//	c_{i+1} = SHA3-256(m || X_L || Y_L || X_R || Y_R)
+//
+// with L_s = u*G, R_s = u*H_p(P_s) and s_s = u - c_s*x mod N closing the ring.
+// Coordinates inside the hashes are minimal big endian encodings without
+// leading zero bytes. Vectors carry every challenge c_0, ..., c_{n-1} to help
+// locate the step at which an implementation diverges.
+
+var errVectorMismatch = errors.New("test vector mismatch")
+
+// TestVector is a deterministic signing test vector in its JSON form.
+type TestVector struct {
+	Name       string          `json:"name"`
+	Curve      string          `json:"curve"`
+	Ring       []hexutil.Bytes `json:"ring"`
+	Signer     int             `json:"signer"`
+	PrivateKey hexutil.Bytes   `json:"privateKey"`
+	Message    hexutil.Bytes   `json:"message"`
+	Nonce      hexutil.Bytes   `json:"nonce"`
+	Responses  []hexutil.Bytes `json:"responses"` // s_i for i != signer, empty at the signer
+	KeyImage   hexutil.Bytes   `json:"keyImage"`
+	Challenges []hexutil.Bytes `json:"challenges"`
+	Signature  hexutil.Bytes   `json:"signature"`
+}
+
+// NewTestVector signs m deterministically with the glue value u and the
+// responses of all ring members but the signer, and records the inputs and
+// the result as a test vector. The entry of responses at index s is ignored.
+func NewTestVector(name string, ring Ring, privkey *ecdsa.PrivateKey, s int, m [32]byte, u *big.Int, responses []*big.Int) (*TestVector, error) {
+	if len(responses) != len(ring) {
+		return nil, errSignatureSize
+	}
+	sig, err := signWithRandomness(m, ring, privkey, s, u, responses)
+	if err != nil {
+		return nil, err
+	}
+	curve, err := CurveName(privkey.Curve)
+	if err != nil {
+		return nil, err
+	}
+	v := &TestVector{
+		Name:       name,
+		Curve:      curve,
+		Signer:     s,
+		PrivateKey: math.PaddedBigBytes(privkey.D, 32),
+		Message:    m[:],
+		Nonce:      math.PaddedBigBytes(u, 32),
+		KeyImage:   crypto.FromECDSAPub(newPoint(sig.Curve, sig.I.X, sig.I.Y)),
+		Signature:  sig.SerializeSignature(),
+	}
+	for i, pub := range ring {
+		v.Ring = append(v.Ring, crypto.FromECDSAPub(pub))
+		if i == s {
+			v.Responses = append(v.Responses, hexutil.Bytes{})
+		} else {
+			v.Responses = append(v.Responses, math.PaddedBigBytes(responses[i], 32))
+		}
+	}
+	for _, c := range challengeChain(sig)[:len(ring)] {
+		v.Challenges = append(v.Challenges, math.PaddedBigBytes(c, 32))
+	}
+	return v, nil
+}
+
+// Check reproduces the vector from its inputs and reports the first value
+// that differs from the recorded one.
+func (v *TestVector) Check() error {
+	if v.Curve != "secp256k1" {
+		return errUnknownCurve
+	}
+	if len(v.Responses) != len(v.Ring) {
+		return errSignatureSize
+	}
+	priv, err := crypto.ToECDSA(v.PrivateKey)
+	if err != nil {
+		return err
+	}
+	ring := make(Ring, len(v.Ring))
+	responses := make([]*big.Int, len(v.Ring))
+	for i, enc := range v.Ring {
+		if ring[i], err = crypto.UnmarshalPubkey(enc); err != nil {
+			return fmt.Errorf("ring member %d: %v", i, err)
+		}
+		responses[i] = new(big.Int).SetBytes(v.Responses[i])
+	}
+	if v.Signer < 0 || v.Signer >= len(ring) {
+		return errIndexOutOfRange
+	}
+	// Sign requires the signer's own key object in the ring
+	ring[v.Signer] = &priv.PublicKey
+
+	var m [32]byte
+	copy(m[:], v.Message)
+	have, err := NewTestVector(v.Name, ring, priv, v.Signer, m, new(big.Int).SetBytes(v.Nonce), responses)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(have.KeyImage, v.KeyImage) {
+		return fmt.Errorf("%v: key image %x, want %x", errVectorMismatch, have.KeyImage, v.KeyImage)
+	}
+	if len(have.Challenges) != len(v.Challenges) {
+		return fmt.Errorf("%v: %d challenges, want %d", errVectorMismatch, len(have.Challenges), len(v.Challenges))
+	}
+	for i := range have.Challenges {
+		if !bytes.Equal(have.Challenges[i], v.Challenges[i]) {
+			return fmt.Errorf("%v: challenge %d is %x, want %x", errVectorMismatch, i, have.Challenges[i], v.Challenges[i])
+		}
+	}
+	if !bytes.Equal(have.Signature, v.Signature) {
+		return fmt.Errorf("%v: signature %x, want %x", errVectorMismatch, have.Signature, v.Signature)
+	}
+	sig, err := DeserializeSignature(v.Signature)
+	if err != nil {
+		return err
+	}
+	if !Verify(sig) {
+		return fmt.Errorf("%v: signature does not verify", errVectorMismatch)
+	}
+	return nil
+}
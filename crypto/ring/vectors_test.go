@@ -0,0 +1,57 @@
+package ring
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+func loadTestVectors(t *testing.T) []*TestVector {
+	blob, err := ioutil.ReadFile("testdata/lsag_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []*TestVector
+	if err := json.Unmarshal(blob, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	return vectors
+}
+
+func TestVectors(t *testing.T) {
+	vectors := loadTestVectors(t)
+	if len(vectors) == 0 {
+		t.Fatal("no test vectors")
+	}
+	for _, v := range vectors {
+		if err := v.Check(); err != nil {
+			t.Errorf("%s: %v", v.Name, err)
+		}
+	}
+}
+
+func TestVectorMismatch(t *testing.T) {
+	v := loadTestVectors(t)[2]
+	v.Challenges[1][31] ^= 1
+	if err := v.Check(); err == nil {
+		t.Fatal("tampered challenge accepted")
+	}
+}
+
+func TestSerializeSignature(t *testing.T) {
+	for _, v := range loadTestVectors(t) {
+		sig, err := DeserializeSignature(v.Signature)
+		if err != nil {
+			t.Fatalf("%s: %v", v.Name, err)
+		}
+		if sig.Size != len(v.Ring) || len(sig.Ring) != len(v.Ring) {
+			t.Fatalf("%s: wrong ring size %d", v.Name, sig.Size)
+		}
+		if len(v.Signature) != 8+32+32+96*len(v.Ring)+64 {
+			t.Fatalf("%s: wrong signature length %d", v.Name, len(v.Signature))
+		}
+		if _, err := DeserializeSignature(v.Signature[:len(v.Signature)-1]); err == nil {
+			t.Fatalf("%s: truncated signature accepted", v.Name)
+		}
+	}
+}
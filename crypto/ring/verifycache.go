@@ -0,0 +1,159 @@
+package ring
+
+import (
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// A signature attached to a transaction is checked when the transaction is
+// gossiped, again when it enters the pending set and once more when the block
+// including it is processed. VerifyCache remembers the outcome of each check,
+// keyed by the Keccak256 hash of the serialized signature.
+//
+// The key is computed over all values of the signature with uncompressed
+// points. The compressed encoding of RLP and the wire formats would map a
+// point off the curve to the same bytes as a valid point, and the result of
+// one signature could be served for another.
+
+// VerifyCache is a size limited, least recently used cache of verification
+// results. It is safe for concurrent use.
+type VerifyCache struct {
+	results *lru.Cache // signature hash -> bool
+
+	hits   uint64 // Number of results served from the cache (atomic access)
+	misses uint64 // Number of signatures verified on a miss (atomic access)
+}
+
+// NewVerifyCache creates a cache holding the results of up to size
+// signatures.
+func NewVerifyCache(size int) (*VerifyCache, error) {
+	results, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyCache{results: results}, nil
+}
+
+// cacheKey is the serialization of a signature hashed into its cache key.
+type cacheKey struct {
+	Curve   string
+	M       [32]byte
+	C       *big.Int
+	S       []*big.Int
+	Ring    [][2]*big.Int
+	I       [2]*big.Int
+	Version uint
+}
+
+// signatureHash returns the cache key of sig, or false if it cannot be
+// serialized, e.g. because its curve is not registered.
+func signatureHash(sig *RingSign) (common.Hash, bool) {
+	name, err := CurveName(sig.Curve)
+	if err != nil {
+		return common.Hash{}, false
+	}
+	key := cacheKey{
+		Curve:   name,
+		M:       sig.M,
+		C:       sig.C,
+		S:       sig.S,
+		Ring:    make([][2]*big.Int, len(sig.Ring)),
+		I:       [2]*big.Int{sig.I.X, sig.I.Y},
+		Version: uint(transcriptVersion(sig)),
+	}
+	for i, pub := range sig.Ring {
+		key.Ring[i] = [2]*big.Int{pub.X, pub.Y}
+	}
+	enc, err := rlp.EncodeToBytes(key)
+	if err != nil {
+		return common.Hash{}, false
+	}
+	return crypto.Keccak256Hash(enc), true
+}
+
+// Verify returns the cached result for sig, verifying and caching it on a
+// miss. Malformed signatures are reported as invalid without being cached.
+func (c *VerifyCache) Verify(sig *RingSign) bool {
+	if !wellFormed(sig) {
+		return false
+	}
+	hash, ok := signatureHash(sig)
+	if !ok {
+		return Verify(sig)
+	}
+	if valid, ok := c.results.Get(hash); ok {
+		c.hit()
+		return valid.(bool)
+	}
+	c.miss()
+	valid := Verify(sig)
+	c.results.Add(hash, valid)
+	return valid
+}
+
+// VerifyBatch is VerifyBatch for the signatures whose result is not cached
+// yet. It returns the result of each signature, in order.
+func (c *VerifyCache) VerifyBatch(sigs []*RingSign) []bool {
+	var (
+		results = make([]bool, len(sigs))
+		hashes  = make(map[int]common.Hash)
+		pending []*RingSign
+		index   []int
+	)
+	for i, sig := range sigs {
+		if !wellFormed(sig) {
+			continue
+		}
+		if hash, ok := signatureHash(sig); ok {
+			if valid, ok := c.results.Get(hash); ok {
+				c.hit()
+				results[i] = valid.(bool)
+				continue
+			}
+			hashes[i] = hash
+		}
+		c.miss()
+		pending = append(pending, sig)
+		index = append(index, i)
+	}
+	for j, valid := range VerifyBatch(pending) {
+		i := index[j]
+		results[i] = valid
+		if hash, ok := hashes[i]; ok {
+			c.results.Add(hash, valid)
+		}
+	}
+	return results
+}
+
+// hit records a result served from the cache.
+func (c *VerifyCache) hit() {
+	atomic.AddUint64(&c.hits, 1)
+	cacheHitMeter.Mark(1)
+}
+
+// miss records a signature verified on a cache miss.
+func (c *VerifyCache) miss() {
+	atomic.AddUint64(&c.misses, 1)
+	cacheMissMeter.Mark(1)
+}
+
+// Stats returns the number of cache hits and misses so far.
+func (c *VerifyCache) Stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&c.hits), atomic.LoadUint64(&c.misses)
+}
+
+// Len returns the number of cached results.
+func (c *VerifyCache) Len() int {
+	return c.results.Len()
+}
+
+// Purge drops all cached results.
+func (c *VerifyCache) Purge() {
+	c.results.Purge()
+}
@@ -0,0 +1,117 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+)
+
+func TestVerifyCache(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	sig, err := Sign([32]byte{1}, ring, keys[1], 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := *sig
+	tampered.M[0] ^= 1
+
+	cache, err := NewVerifyCache(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if !cache.Verify(sig) {
+			t.Error("valid signature rejected")
+		}
+		if cache.Verify(&tampered) {
+			t.Error("tampered signature accepted")
+		}
+	}
+	if cache.Verify(&RingSign{}) {
+		t.Error("malformed signature accepted")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("have %d cached results, want 2", cache.Len())
+	}
+	// the least recently used result is evicted
+	other, err := Sign([32]byte{2}, ring, keys[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Verify(other)
+	if hash, _ := signatureHash(sig); cache.results.Contains(hash) {
+		t.Error("least recently used result not evicted")
+	}
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("have %d cached results after purge, want 0", cache.Len())
+	}
+}
+
+func TestVerifyCacheOffCurve(t *testing.T) {
+	ring, keys := testRing(t, 2)
+	sig, err := Sign([32]byte{}, ring, keys[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, _ := NewVerifyCache(16)
+	if !cache.Verify(sig) {
+		t.Fatal("valid signature rejected")
+	}
+	// same X and Y parity, hence the same compressed encoding
+	forged := *sig
+	forged.Ring = Ring{ring[0], &ecdsa.PublicKey{
+		Curve: ring[1].Curve,
+		X:     ring[1].X,
+		Y:     new(big.Int).Add(ring[1].Y, big.NewInt(2)),
+	}}
+	if cache.Verify(&forged) {
+		t.Error("off-curve ring member served from cache")
+	}
+}
+
+func TestVerifyCacheBatch(t *testing.T) {
+	ring, keys := testRing(t, 3)
+	var sigs []*RingSign
+	for i, key := range keys {
+		sig, err := Sign([32]byte{byte(i)}, ring, key, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+	}
+	tampered := *sigs[0]
+	tampered.M[0] ^= 1
+	sigs = append(sigs, &tampered, nil)
+
+	cache, _ := NewVerifyCache(16)
+	cache.Verify(sigs[1])
+	want := []bool{true, true, true, false, false}
+	for round := 0; round < 2; round++ {
+		results := cache.VerifyBatch(sigs)
+		for i := range want {
+			if results[i] != want[i] {
+				t.Errorf("round %d, signature %d: have %v, want %v", round, i, results[i], want[i])
+			}
+		}
+	}
+	if cache.Len() != 4 {
+		t.Errorf("have %d cached results, want 4", cache.Len())
+	}
+}
+
+func TestVerifyCacheStats(t *testing.T) {
+	ring, keys := testRing(t, 2)
+	sig, err := Sign([32]byte{}, ring, keys[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, _ := NewVerifyCache(16)
+	for i := 0; i < 3; i++ {
+		cache.Verify(sig)
+	}
+	cache.VerifyBatch([]*RingSign{sig})
+	if hits, misses := cache.Stats(); hits != 3 || misses != 1 {
+		t.Errorf("have %d hits and %d misses, want 3 and 1", hits, misses)
+	}
+}
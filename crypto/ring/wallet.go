@@ -0,0 +1,227 @@
+package ring
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/sha3"
+)
+
+// Wallet keys pair a view key with a spend key. The view key alone detects
+// incoming stealth outputs and decrypts their amounts, so it can be handed to
+// auditors or run on an exchange's hot server in watch-only mode; the spend key
+// is only needed to derive one-time private keys and sign.
+//
+// Stealth outputs carry their amount encrypted under the shared derivation
+// D = r*A = a*R, and the blinding factor of the amount commitment is derived
+// from D as well, so the recipient can open the commitment without any
+// further communication. A one byte view tag, also derived from D, lets
+// scanning wallets discard all but 1/256 of the outputs that are not theirs
+// with a single hash instead of a scalar multiplication.
+
+var errWatchOnly = errors.New("watch-only wallet cannot spend")
+
+// WalletKeys holds the view and spend keys of a stealth wallet. Spend is nil in
+// watch-only mode.
+type WalletKeys struct {
+	View     *ecdsa.PrivateKey
+	Spend    *ecdsa.PrivateKey
+	spendPub *ecdsa.PublicKey
+}
+
+// StealthOutput is a transaction output paying to a stealth address.
+type StealthOutput struct {
+	Key        *ecdsa.PublicKey // one-time key P_i
+	Commitment *ecdsa.PublicKey // amount commitment
+	Amount     [8]byte          // amount encrypted under the derivation
+	ViewTag    byte             // first byte of a hash of the derivation
+}
+
+// StealthTx groups the stealth outputs of a transaction with its public
+// transaction key R.
+type StealthTx struct {
+	TxPub   *ecdsa.PublicKey
+	Outputs []*StealthOutput
+}
+
+// OwnedOutput is an output detected by a wallet.
+type OwnedOutput struct {
+	Index  int               // position in the transaction outputs
+	Output *StealthOutput    // the output itself
+	Amount uint64            // decrypted amount
+	Mask   *big.Int          // blinding factor of the commitment
+	Key    *ecdsa.PrivateKey // one-time private key, nil in watch-only mode
+
+	Subaddress SubaddressIndex // receiving subaddress, zero for the main address
+}
+
+// GenerateWalletKeys creates a new wallet with random view and spend keys.
+func GenerateWalletKeys(curve elliptic.Curve) (*WalletKeys, error) {
+	view, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	spend, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &WalletKeys{View: view, Spend: spend, spendPub: &spend.PublicKey}, nil
+}
+
+// NewWalletKeys creates wallet keys from the view and spend private keys.
+func NewWalletKeys(view, spend *ecdsa.PrivateKey) *WalletKeys {
+	return &WalletKeys{View: view, Spend: spend, spendPub: &spend.PublicKey}
+}
+
+// NewWatchOnlyKeys creates watch-only wallet keys from the view private key
+// and the spend public key.
+func NewWatchOnlyKeys(view *ecdsa.PrivateKey, spend *ecdsa.PublicKey) *WalletKeys {
+	return &WalletKeys{View: view, spendPub: spend}
+}
+
+// Address returns the stealth address of the wallet.
+func (k *WalletKeys) Address() *StealthAddress {
+	return &StealthAddress{View: &k.View.PublicKey, Spend: k.spendPub}
+}
+
+// WatchOnly reports whether the wallet lacks the spend key.
+func (k *WalletKeys) WatchOnly() bool {
+	return k.Spend == nil
+}
+
+// WatchOnlyKeys returns a copy of the keys without the spend key.
+func (k *WalletKeys) WatchOnlyKeys() *WalletKeys {
+	return NewWatchOnlyKeys(k.View, k.spendPub)
+}
+
+// stealthMask derives the commitment blinding factor of output index.
+func stealthMask(D *ecdsa.PublicKey, index uint64) *big.Int {
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], index)
+	return hashToScalar(D.Curve, stealthDomain, []byte("mask"), pointBytes(D), idx[:])
+}
+
+// stealthAmountPad derives the pad the amount of output index is encrypted with.
+func stealthAmountPad(D *ecdsa.PublicKey, index uint64) [8]byte {
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], index)
+
+	h := sha3.New256()
+	h.Write(stealthDomain)
+	h.Write([]byte("amount"))
+	h.Write(pointBytes(D))
+	h.Write(idx[:])
+
+	var pad [8]byte
+	copy(pad[:], h.Sum(nil))
+	return pad
+}
+
+// stealthViewTag derives the view tag of output index.
+func stealthViewTag(D *ecdsa.PublicKey, index uint64) byte {
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], index)
+
+	h := sha3.New256()
+	h.Write(stealthDomain)
+	h.Write([]byte("view tag"))
+	h.Write(pointBytes(D))
+	h.Write(idx[:])
+	return h.Sum(nil)[0]
+}
+
+// NewStealthOutput creates output index of a transaction with private
+// transaction key txKey, paying amount to addr. It returns the output and the
+// blinding factor of its commitment.
+func NewStealthOutput(addr *StealthAddress, txKey *ecdsa.PrivateKey, index uint64, amount uint64) (*StealthOutput, *big.Int) {
+	D := pointMul(addr.View, txKey.D)
+	mask := stealthMask(D, index)
+
+	out := &StealthOutput{
+		Key:        DeriveStealthKey(addr, txKey, index),
+		Commitment: Commit(D.Curve, new(big.Int).SetUint64(amount), mask),
+		ViewTag:    stealthViewTag(D, index),
+	}
+	pad := stealthAmountPad(D, index)
+	binary.BigEndian.PutUint64(out.Amount[:], amount)
+	for i := range out.Amount {
+		out.Amount[i] ^= pad[i]
+	}
+	return out, mask
+}
+
+// openStealthOutput decrypts the amount of output index and checks that it
+// opens the output commitment together with the derived mask.
+func openStealthOutput(D *ecdsa.PublicKey, index uint64, out *StealthOutput) (uint64, *big.Int, bool) {
+	pad := stealthAmountPad(D, index)
+	var plain [8]byte
+	for j := range plain {
+		plain[j] = out.Amount[j] ^ pad[j]
+	}
+	amount := binary.BigEndian.Uint64(plain[:])
+	mask := stealthMask(D, index)
+	if !pointEqual(out.Commitment, Commit(D.Curve, new(big.Int).SetUint64(amount), mask)) {
+		return 0, nil, false
+	}
+	return amount, mask, true
+}
+
+// Scan returns the outputs of tx that pay to the wallet. Amounts are only
+// reported if they open the output commitment. In watch-only mode the one-time
+// private keys are left nil.
+func (k *WalletKeys) Scan(tx *StealthTx) []*OwnedOutput {
+	D := pointMul(tx.TxPub, k.View.D)
+	if D == nil {
+		return nil
+	}
+	var owned []*OwnedOutput
+	for i, out := range tx.Outputs {
+		index := uint64(i)
+		if out.ViewTag != stealthViewTag(D, index) {
+			continue
+		}
+		if !IsStealthOutput(k.View, k.spendPub, tx.TxPub, index, out.Key) {
+			continue
+		}
+		amount, mask, ok := openStealthOutput(D, index, out)
+		if !ok {
+			continue
+		}
+		own := &OwnedOutput{Index: i, Output: out, Amount: amount, Mask: mask}
+		if !k.WatchOnly() {
+			key, err := RecoverStealthKey(k.View, k.Spend, tx.TxPub, index, out.Key)
+			if err != nil {
+				continue
+			}
+			own.Key = key
+		}
+		owned = append(owned, own)
+	}
+	return owned
+}
+
+// Balance returns the total amount received by the wallet in txs. Spent
+// outputs are not subtracted: detecting spends requires key images, which
+// only the spend key can compute.
+func (k *WalletKeys) Balance(txs []*StealthTx) *big.Int {
+	total := new(big.Int)
+	for _, tx := range txs {
+		for _, own := range k.Scan(tx) {
+			total.Add(total, new(big.Int).SetUint64(own.Amount))
+		}
+	}
+	return total
+}
+
+// SpendKey returns the one-time private key of an owned output, failing in
+// watch-only mode.
+func (k *WalletKeys) SpendKey(own *OwnedOutput) (*ecdsa.PrivateKey, error) {
+	if k.WatchOnly() || own.Key == nil {
+		return nil, errWatchOnly
+	}
+	return own.Key, nil
+}
@@ -0,0 +1,89 @@
+package ring
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestWalletWatchOnly(t *testing.T) {
+	curve := crypto.S256()
+	wallet, err := GenerateWalletKeys(curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GenerateWalletKeys(curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txKey, _ := crypto.GenerateKey()
+	out0, _ := NewStealthOutput(wallet.Address(), txKey, 0, 1500)
+	out1, _ := NewStealthOutput(other.Address(), txKey, 1, 99)
+	out2, _ := NewStealthOutput(wallet.Address(), txKey, 2, 25)
+	tx := &StealthTx{TxPub: &txKey.PublicKey, Outputs: []*StealthOutput{out0, out1, out2}}
+
+	watch := wallet.WatchOnlyKeys()
+	if !watch.WatchOnly() || wallet.WatchOnly() {
+		t.Fatal("watch-only flag wrong")
+	}
+	if b := watch.Balance([]*StealthTx{tx}); b.Uint64() != 1525 {
+		t.Errorf("watch-only balance %v, want 1525", b)
+	}
+	owned := watch.Scan(tx)
+	if len(owned) != 2 {
+		t.Fatalf("watch-only wallet found %d outputs, want 2", len(owned))
+	}
+	if _, err := watch.SpendKey(owned[0]); err != errWatchOnly {
+		t.Errorf("watch-only spend: have %v, want %v", err, errWatchOnly)
+	}
+	// the full wallet recovers the one-time keys
+	for _, own := range wallet.Scan(tx) {
+		key, err := wallet.SpendKey(own)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pointEqual(&key.PublicKey, own.Output.Key) {
+			t.Error("recovered key does not match output")
+		}
+	}
+	// a tampered amount no longer opens the commitment and is ignored
+	out0.Amount[7] ^= 1
+	if b := watch.Balance([]*StealthTx{tx}); b.Uint64() != 25 {
+		t.Errorf("balance with tampered amount %v, want 25", b)
+	}
+}
+
+func TestViewTag(t *testing.T) {
+	wallet, err := GenerateWalletKeys(crypto.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	txKey, _ := crypto.GenerateKey()
+	out, _ := NewStealthOutput(wallet.Address(), txKey, 0, 10)
+	tx := &StealthTx{TxPub: &txKey.PublicKey, Outputs: []*StealthOutput{out}}
+
+	if owned := wallet.Scan(tx); len(owned) != 1 {
+		t.Fatal("output with view tag not found")
+	}
+	// a wrong view tag makes the wallet skip the output before the full check
+	out.ViewTag++
+	if owned := wallet.Scan(tx); len(owned) != 0 {
+		t.Error("output with wrong view tag found")
+	}
+	if owned := wallet.ScanSubaddresses(tx, wallet.SubaddressTable(1, 1)); len(owned) != 0 {
+		t.Error("output with wrong view tag found by subaddress scan")
+	}
+	// view tags of foreign outputs rarely match
+	other, _ := GenerateWalletKeys(crypto.S256())
+	matches := 0
+	for i := 0; i < 256; i++ {
+		foreign, _ := NewStealthOutput(other.Address(), txKey, uint64(i), 1)
+		D := pointMul(&txKey.PublicKey, wallet.View.D)
+		if foreign.ViewTag == stealthViewTag(D, uint64(i)) {
+			matches++
+		}
+	}
+	if matches > 16 {
+		t.Errorf("%d of 256 foreign view tags matched", matches)
+	}
+}
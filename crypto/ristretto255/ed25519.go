@@ -0,0 +1,103 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ristretto255
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha512"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// Ed25519 keys live on edwards25519 and share its base point with ristretto255,
+// so an Ed25519 key pair (a, A = a*B) is also a valid ristretto255 key pair.
+// The helpers below convert between the two representations.
+
+// decodeEdwards decompresses an RFC 8032 encoded edwards25519 point.
+func decodeEdwards(b []byte) (*point, error) {
+	if len(b) != 32 {
+		return nil, errInvalidPoint
+	}
+	buf := make([]byte, 32)
+	copy(buf, b)
+	sign := buf[31] >> 7
+	buf[31] &= 0x7f
+
+	y := fromLittleEndian(buf)
+	if y.Cmp(fieldP) >= 0 {
+		return nil, errInvalidPoint
+	}
+	// x^2 = (y^2 - 1) / (d*y^2 + 1)
+	yy := feSq(y)
+	square, x := sqrtRatioM1(feSub(yy, bigOne), feAdd(feMul(curveD, yy), bigOne))
+	if !square {
+		return nil, errInvalidPoint
+	}
+	if x.Sign() == 0 && sign == 1 {
+		return nil, errInvalidPoint
+	}
+	if uint(x.Bit(0)) != uint(sign) {
+		x = feNeg(x)
+	}
+	return fromAffine(x, y), nil
+}
+
+// PublicKeyFromEd25519 converts an Ed25519 public key into a ristretto255
+// public key. Keys with a small order component are rejected.
+func PublicKeyFromEd25519(pub ed25519.PublicKey) (*ecdsa.PublicKey, error) {
+	p, err := decodeEdwards(pub)
+	if err != nil {
+		return nil, err
+	}
+	// honest keys are multiples of the base point and thus of prime order
+	if x, y := p.mul(groupN).affine(); !theCurve.IsIdentity(x, y) {
+		return nil, errInvalidPoint
+	}
+	x, y := canonical(p)
+	if theCurve.IsIdentity(x, y) {
+		return nil, errInvalidPoint
+	}
+	return &ecdsa.PublicKey{Curve: theCurve, X: x, Y: y}, nil
+}
+
+// PrivateKeyFromEd25519 converts an Ed25519 private key into a ristretto255
+// private key with the same public key. The scalar is derived from the seed as
+// specified by RFC 8032.
+func PrivateKeyFromEd25519(priv ed25519.PrivateKey) *ecdsa.PrivateKey {
+	h := sha512.Sum512(priv[:32])
+	h[0] &= 248
+	h[31] &= 127
+	h[31] |= 64
+
+	d := fromLittleEndian(h[:32])
+	d.Mod(d, groupN)
+
+	x, y := theCurve.ScalarBaseMult(d.Bytes())
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: theCurve, X: x, Y: y},
+		D:         d,
+	}
+}
+
+// GenerateKey creates a new random ristretto255 private key.
+func GenerateKey() (*ecdsa.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	return PrivateKeyFromEd25519(priv), nil
+}
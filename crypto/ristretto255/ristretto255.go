@@ -0,0 +1,344 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ristretto255 implements the ristretto255 prime-order group (RFC 9496)
+// on top of the twisted Edwards curve edwards25519 behind the elliptic.Curve
+// interface, so it can be used wherever go-ethereum expects a curve.
+//
+// Group elements are exposed as the affine Edwards coordinates (x, y) of a
+// canonical representative: every result is passed through the ristretto255
+// encoding and decoding, so two elements are equal if and only if their
+// coordinates are. Unlike the short Weierstrass curves, the identity element
+// has the coordinates (0, 1), see IsIdentity.
+//
+// The implementation uses math/big and is not constant time.
+package ristretto255
+
+import (
+	"crypto/elliptic"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+)
+
+var (
+	errInvalidEncoding = errors.New("invalid ristretto255 encoding")
+	errInvalidPoint    = errors.New("invalid edwards25519 point")
+)
+
+func fromDecimal(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid constant " + s)
+	}
+	return n
+}
+
+var (
+	// field modulus p = 2^255 - 19
+	fieldP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// group order l = 2^252 + 27742317777372353535851937790883648493
+	groupN = new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 252), fromDecimal("27742317777372353535851937790883648493"))
+
+	curveD          = fromDecimal("37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	sqrtM1          = fromDecimal("19681161376707505956807079304988542015446066515923890162744021073123829784752")
+	sqrtADMinusOne  = fromDecimal("25063068953384623474111414158702152701244531502492656460079210482610430750235")
+	invSqrtAMinusD  = fromDecimal("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+	oneMinusDSq     = fromDecimal("1159843021668779879193775521855586647937357759715417654439879720876111806838")
+	dMinusOneSq     = fromDecimal("40440834346308536858101042469323190826248399146238708352240133220865137265952")
+	sqrtRatioExp    = new(big.Int).Rsh(new(big.Int).Sub(fieldP, big.NewInt(5)), 3) // (p-5)/8
+	edwardsBaseX    = fromDecimal("15112221349535400772501151409588531511454012693041857206046113283949847762202")
+	edwardsBaseY    = fromDecimal("46316835694926478169428394003475163141307993866256225615783033603165251855960")
+	bigZero, bigOne = big.NewInt(0), big.NewInt(1)
+)
+
+// Ristretto is the ristretto255 group.
+type Ristretto struct {
+	params *elliptic.CurveParams
+}
+
+var theCurve = &Ristretto{
+	params: &elliptic.CurveParams{
+		Name:    "ristretto255",
+		P:       fieldP,
+		N:       groupN,
+		B:       curveD,
+		Gx:      edwardsBaseX,
+		Gy:      edwardsBaseY,
+		BitSize: 255,
+	},
+}
+
+// Curve returns the ristretto255 group.
+func Curve() *Ristretto {
+	return theCurve
+}
+
+// Params returns the parameters of the group. B holds the Edwards curve
+// constant d and (Gx, Gy) the canonical representative of the generator,
+// which is the edwards25519 base point.
+func (r *Ristretto) Params() *elliptic.CurveParams {
+	return r.params
+}
+
+// field helpers, all results are reduced modulo p
+
+func fe() *big.Int { return new(big.Int) }
+
+func feMul(a, b *big.Int) *big.Int { return fe().Mod(fe().Mul(a, b), fieldP) }
+func feAdd(a, b *big.Int) *big.Int { return fe().Mod(fe().Add(a, b), fieldP) }
+func feSub(a, b *big.Int) *big.Int { return fe().Mod(fe().Sub(a, b), fieldP) }
+func feNeg(a *big.Int) *big.Int    { return fe().Mod(fe().Neg(a), fieldP) }
+func feSq(a *big.Int) *big.Int     { return feMul(a, a) }
+func feInv(a *big.Int) *big.Int    { return fe().ModInverse(a, fieldP) }
+
+// isNegative reports whether the field element is odd, as defined by RFC 9496.
+func isNegative(a *big.Int) bool { return a.Bit(0) == 1 }
+
+// feAbs returns the non-negative of a and -a.
+func feAbs(a *big.Int) *big.Int {
+	if isNegative(a) {
+		return feNeg(a)
+	}
+	return a
+}
+
+// sqrtRatioM1 computes sqrt(u/v) or sqrt(i*u/v) as specified in RFC 9496,
+// returning whether u/v was square along with the non-negative root.
+func sqrtRatioM1(u, v *big.Int) (bool, *big.Int) {
+	v3 := feMul(feSq(v), v)
+	v7 := feMul(feSq(v3), v)
+	r := feMul(feMul(u, v3), fe().Exp(feMul(u, v7), sqrtRatioExp, fieldP))
+	check := feMul(v, feSq(r))
+
+	correct := check.Cmp(u) == 0
+	flipped := check.Cmp(feNeg(u)) == 0
+	flippedI := check.Cmp(feMul(feNeg(u), sqrtM1)) == 0
+	if flipped || flippedI {
+		r = feMul(r, sqrtM1)
+	}
+	return correct || flipped, feAbs(r)
+}
+
+// point is an edwards25519 point in extended coordinates.
+type point struct {
+	X, Y, Z, T *big.Int
+}
+
+func identity() *point {
+	return &point{fe(), big.NewInt(1), big.NewInt(1), fe()}
+}
+
+func fromAffine(x, y *big.Int) *point {
+	return &point{new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1), feMul(x, y)}
+}
+
+func (p *point) affine() (*big.Int, *big.Int) {
+	zinv := feInv(p.Z)
+	return feMul(p.X, zinv), feMul(p.Y, zinv)
+}
+
+// add returns p+q using the complete addition law for a = -1.
+func (p *point) add(q *point) *point {
+	a := feMul(feSub(p.Y, p.X), feSub(q.Y, q.X))
+	b := feMul(feAdd(p.Y, p.X), feAdd(q.Y, q.X))
+	c := feMul(feMul(p.T, q.T), feAdd(curveD, curveD))
+	d := feMul(feAdd(p.Z, p.Z), q.Z)
+	e, f, g, h := feSub(b, a), feSub(d, c), feAdd(d, c), feAdd(b, a)
+	return &point{feMul(e, f), feMul(g, h), feMul(f, g), feMul(e, h)}
+}
+
+// mul returns k*p using double-and-add.
+func (p *point) mul(k *big.Int) *point {
+	acc := identity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = acc.add(acc)
+		if k.Bit(i) == 1 {
+			acc = acc.add(p)
+		}
+	}
+	return acc
+}
+
+// encode returns the 32 byte ristretto255 encoding of p.
+func (p *point) encode() []byte {
+	u1 := feMul(feAdd(p.Z, p.Y), feSub(p.Z, p.Y))
+	u2 := feMul(p.X, p.Y)
+	_, invsqrt := sqrtRatioM1(bigOne, feMul(u1, feSq(u2)))
+	den1 := feMul(invsqrt, u1)
+	den2 := feMul(invsqrt, u2)
+	zinv := feMul(feMul(den1, den2), p.T)
+
+	x, y, denInv := p.X, p.Y, den2
+	if isNegative(feMul(p.T, zinv)) {
+		x, y = feMul(p.Y, sqrtM1), feMul(p.X, sqrtM1)
+		denInv = feMul(den1, invSqrtAMinusD)
+	}
+	if isNegative(feMul(x, zinv)) {
+		y = feNeg(y)
+	}
+	s := feAbs(feMul(denInv, feSub(p.Z, y)))
+	return toLittleEndian(s)
+}
+
+// decode parses a 32 byte ristretto255 encoding.
+func decode(b []byte) (*point, error) {
+	if len(b) != 32 {
+		return nil, errInvalidEncoding
+	}
+	s := fromLittleEndian(b)
+	if s.Cmp(fieldP) >= 0 || isNegative(s) {
+		return nil, errInvalidEncoding
+	}
+	ss := feSq(s)
+	u1 := feSub(bigOne, ss)
+	u2 := feAdd(bigOne, ss)
+	u2sq := feSq(u2)
+	v := feSub(feNeg(feMul(curveD, feSq(u1))), u2sq)
+
+	square, invsqrt := sqrtRatioM1(bigOne, feMul(v, u2sq))
+	denX := feMul(invsqrt, u2)
+	denY := feMul(feMul(invsqrt, denX), v)
+	x := feAbs(feMul(feAdd(s, s), denX))
+	y := feMul(u1, denY)
+	t := feMul(x, y)
+	if !square || isNegative(t) || y.Sign() == 0 {
+		return nil, errInvalidEncoding
+	}
+	return &point{x, y, big.NewInt(1), t}, nil
+}
+
+// elligator maps 32 bytes to a group element with the ristretto255 MAP
+// function of RFC 9496.
+func elligator(b []byte) *point {
+	buf := make([]byte, 32)
+	copy(buf, b)
+	buf[31] &= 0x7f
+	t := fe().Mod(fromLittleEndian(buf), fieldP)
+
+	r := feMul(sqrtM1, feSq(t))
+	u := feMul(feAdd(r, bigOne), oneMinusDSq)
+	v := feMul(feSub(feNeg(bigOne), feMul(r, curveD)), feAdd(r, curveD))
+
+	square, s := sqrtRatioM1(u, v)
+	c := feNeg(bigOne)
+	if !square {
+		s = feNeg(feAbs(feMul(s, t)))
+		c = r
+	}
+	n := feSub(feMul(feMul(c, feSub(r, bigOne)), dMinusOneSq), v)
+
+	w0 := feMul(feAdd(s, s), v)
+	w1 := feMul(n, sqrtADMinusOne)
+	w2 := feSub(bigOne, feSq(s))
+	w3 := feAdd(bigOne, feSq(s))
+	return &point{feMul(w0, w3), feMul(w2, w1), feMul(w1, w3), feMul(w0, w2)}
+}
+
+// canonical returns the affine coordinates of the canonical representative
+// of p's ristretto255 class.
+func canonical(p *point) (*big.Int, *big.Int) {
+	q, err := decode(p.encode())
+	if err != nil {
+		panic("ristretto255: encoding round trip failed")
+	}
+	return q.X, q.Y
+}
+
+func toLittleEndian(n *big.Int) []byte {
+	b := make([]byte, 32)
+	be := n.Bytes()
+	for i := range be {
+		b[i] = be[len(be)-1-i]
+	}
+	return b
+}
+
+func fromLittleEndian(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[i] = b[len(b)-1-i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// IsOnCurve reports whether (x, y) is the canonical representative of a
+// ristretto255 element.
+func (r *Ristretto) IsOnCurve(x, y *big.Int) bool {
+	if x == nil || y == nil || x.Sign() < 0 || y.Sign() < 0 || x.Cmp(fieldP) >= 0 || y.Cmp(fieldP) >= 0 {
+		return false
+	}
+	// -x^2 + y^2 = 1 + d*x^2*y^2
+	xx, yy := feSq(x), feSq(y)
+	if feSub(yy, xx).Cmp(feAdd(bigOne, feMul(curveD, feMul(xx, yy)))) != 0 {
+		return false
+	}
+	cx, cy := canonical(fromAffine(x, y))
+	return cx.Cmp(x) == 0 && cy.Cmp(y) == 0
+}
+
+// Add returns the sum of (x1, y1) and (x2, y2).
+func (r *Ristretto) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	return canonical(fromAffine(x1, y1).add(fromAffine(x2, y2)))
+}
+
+// Double returns 2*(x, y).
+func (r *Ristretto) Double(x, y *big.Int) (*big.Int, *big.Int) {
+	p := fromAffine(x, y)
+	return canonical(p.add(p))
+}
+
+// ScalarMult returns k*(x, y) where k is a number in big-endian form.
+func (r *Ristretto) ScalarMult(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	return canonical(fromAffine(x, y).mul(new(big.Int).SetBytes(k)))
+}
+
+// ScalarBaseMult returns k*G where G is the generator of the group and k is a
+// number in big-endian form.
+func (r *Ristretto) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return r.ScalarMult(edwardsBaseX, edwardsBaseY, k)
+}
+
+// Neg returns -(x, y).
+func (r *Ristretto) Neg(x, y *big.Int) (*big.Int, *big.Int) {
+	return canonical(&point{feNeg(x), new(big.Int).Set(y), big.NewInt(1), feNeg(feMul(x, y))})
+}
+
+// IsIdentity reports whether (x, y) is the identity element.
+func (r *Ristretto) IsIdentity(x, y *big.Int) bool {
+	return x.Sign() == 0 && y.Cmp(bigOne) == 0
+}
+
+// HashToPoint maps data to a group element with unknown discrete logarithm,
+// using SHA-512 and the hash-to-group construction of RFC 9496.
+func (r *Ristretto) HashToPoint(data []byte) (*big.Int, *big.Int) {
+	h := sha512.Sum512(data)
+	return canonical(elligator(h[:32]).add(elligator(h[32:])))
+}
+
+// Marshal returns the 32 byte ristretto255 encoding of (x, y).
+func (r *Ristretto) Marshal(x, y *big.Int) []byte {
+	return fromAffine(x, y).encode()
+}
+
+// Unmarshal parses a 32 byte ristretto255 encoding.
+func (r *Ristretto) Unmarshal(b []byte) (*big.Int, *big.Int, error) {
+	p, err := decode(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.X, p.Y, nil
+}
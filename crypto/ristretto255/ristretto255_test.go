@@ -0,0 +1,137 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ristretto255
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// Encodings of the first multiples of the generator, from RFC 9496 A.1.
+var generatorMultiples = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+}
+
+func TestGeneratorMultiples(t *testing.T) {
+	curve := Curve()
+	for i, want := range generatorMultiples {
+		x, y := curve.ScalarBaseMult(big.NewInt(int64(i)).Bytes())
+		if have := hex.EncodeToString(curve.Marshal(x, y)); have != want {
+			t.Errorf("%d*G: encoding mismatch: have %s, want %s", i, have, want)
+		}
+		enc, _ := hex.DecodeString(want)
+		dx, dy, err := curve.Unmarshal(enc)
+		if err != nil {
+			t.Fatalf("%d*G: failed to decode: %v", i, err)
+		}
+		if dx.Cmp(x) != 0 || dy.Cmp(y) != 0 {
+			t.Errorf("%d*G: decoded point mismatch", i)
+		}
+	}
+}
+
+func TestGroupLaw(t *testing.T) {
+	curve := Curve()
+	x2, y2 := curve.ScalarBaseMult([]byte{2})
+	x3, y3 := curve.ScalarBaseMult([]byte{3})
+	x5, y5 := curve.ScalarBaseMult([]byte{5})
+
+	if x, y := curve.Add(x2, y2, x3, y3); x.Cmp(x5) != 0 || y.Cmp(y5) != 0 {
+		t.Error("2G + 3G != 5G")
+	}
+	if x, y := curve.Double(x2, y2); !curve.IsOnCurve(x, y) {
+		t.Error("doubled point not on curve")
+	}
+	nx, ny := curve.Neg(x5, y5)
+	if x, y := curve.Add(x5, y5, nx, ny); !curve.IsIdentity(x, y) {
+		t.Error("5G - 5G is not the identity")
+	}
+	if x, y := curve.ScalarBaseMult(curve.Params().N.Bytes()); !curve.IsIdentity(x, y) {
+		t.Error("group order does not annihilate the generator")
+	}
+	if x, y := curve.HashToPoint([]byte("test")); !curve.IsOnCurve(x, y) {
+		t.Error("hashed point not on curve")
+	}
+}
+
+func TestInvalidEncodings(t *testing.T) {
+	for _, enc := range []string{
+		// non-canonical field element
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		// negative field element
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		// non-square x^2
+		"26948d35ca62e643e26a83177332e6b6afeb9d08e4268b650f1f5bbd8d81d371",
+	} {
+		b, _ := hex.DecodeString(enc)
+		if _, _, err := Curve().Unmarshal(b); err == nil {
+			t.Errorf("invalid encoding %s accepted", enc)
+		}
+	}
+}
+
+func TestEd25519Keys(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := PrivateKeyFromEd25519(priv)
+	rpub, err := PublicKeyFromEd25519(pub)
+	if err != nil {
+		t.Fatalf("failed to convert public key: %v", err)
+	}
+	if key.X.Cmp(rpub.X) != 0 || key.Y.Cmp(rpub.Y) != 0 {
+		t.Fatal("converted key pair does not match")
+	}
+	// the identity has small order and must be rejected
+	id := make([]byte, 32)
+	id[0] = 1
+	if _, err := PublicKeyFromEd25519(id); err == nil {
+		t.Error("identity accepted as public key")
+	}
+}
+
+func TestSr25519Keys(t *testing.T) {
+	seed := make([]byte, 32)
+	key, err := PrivateKeyFromSr25519Seed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := PublicKeyFromSr25519(Sr25519Bytes(&key.PublicKey))
+	if err != nil || pub.X.Cmp(key.X) != 0 || pub.Y.Cmp(key.Y) != 0 {
+		t.Fatalf("public key round trip failed: %v", err)
+	}
+	// the 64 byte secret form holds the scalar in little-endian order
+	secret := append(toLittleEndian(key.D), make([]byte, 32)...)
+	same, err := PrivateKeyFromSr25519(secret)
+	if err != nil || same.D.Cmp(key.D) != 0 {
+		t.Fatalf("secret key round trip failed: %v", err)
+	}
+	if _, err := PrivateKeyFromSr25519(append(toLittleEndian(groupN), make([]byte, 32)...)); err == nil {
+		t.Error("non-canonical scalar accepted")
+	}
+	if _, err := PublicKeyFromSr25519(make([]byte, 32)); err == nil {
+		t.Error("identity accepted as public key")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ristretto255
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha512"
+	"errors"
+)
+
+// sr25519 (schnorrkel) keys, as used by Substrate and Polkadot accounts, are
+// ristretto255 key pairs. Public keys are 32 byte ristretto255 encodings and
+// secret keys are a little-endian scalar followed by a 32 byte nonce seed.
+
+var errInvalidSecret = errors.New("invalid sr25519 secret key")
+
+// PublicKeyFromSr25519 parses a 32 byte sr25519 public key.
+func PublicKeyFromSr25519(pub []byte) (*ecdsa.PublicKey, error) {
+	x, y, err := theCurve.Unmarshal(pub)
+	if err != nil {
+		return nil, err
+	}
+	if theCurve.IsIdentity(x, y) {
+		return nil, errInvalidPoint
+	}
+	return &ecdsa.PublicKey{Curve: theCurve, X: x, Y: y}, nil
+}
+
+// PrivateKeyFromSr25519 converts a 64 byte sr25519 secret key into a private
+// key. The scalar must be canonical; the nonce seed is not needed for ring
+// signatures and is ignored.
+func PrivateKeyFromSr25519(secret []byte) (*ecdsa.PrivateKey, error) {
+	if len(secret) != 64 {
+		return nil, errInvalidSecret
+	}
+	d := fromLittleEndian(secret[:32])
+	if d.Sign() == 0 || d.Cmp(groupN) >= 0 {
+		return nil, errInvalidSecret
+	}
+	x, y := theCurve.ScalarBaseMult(d.Bytes())
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: theCurve, X: x, Y: y},
+		D:         d,
+	}, nil
+}
+
+// PrivateKeyFromSr25519Seed expands a 32 byte sr25519 mini secret key the way
+// Substrate does (schnorrkel's Ed25519 expansion mode): the SHA-512 of the
+// seed is clamped and divided by the cofactor.
+func PrivateKeyFromSr25519Seed(seed []byte) (*ecdsa.PrivateKey, error) {
+	if len(seed) != 32 {
+		return nil, errInvalidSecret
+	}
+	h := sha512.Sum512(seed)
+	h[0] &= 248
+	h[31] &= 63
+	h[31] |= 64
+
+	d := fromLittleEndian(h[:32])
+	d.Rsh(d, 3)
+	d.Mod(d, groupN)
+
+	x, y := theCurve.ScalarBaseMult(d.Bytes())
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: theCurve, X: x, Y: y},
+		D:         d,
+	}, nil
+}
+
+// Sr25519Bytes returns the 32 byte sr25519 encoding of a public key.
+func Sr25519Bytes(pub *ecdsa.PublicKey) []byte {
+	return theCurve.Marshal(pub.X, pub.Y)
+}
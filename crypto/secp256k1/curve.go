@@ -35,12 +35,15 @@ package secp256k1
 import (
 	"crypto/elliptic"
 	"math/big"
+	"sync"
 	"unsafe"
 )
 
 /*
 #include "libsecp256k1/include/secp256k1.h"
 extern int secp256k1_ext_scalar_mul(const secp256k1_context* ctx, const unsigned char *point, const unsigned char *scalar);
+extern int secp256k1_ext_scalar_base_mul(const secp256k1_context* ctx, const unsigned char *point, const unsigned char *scalar);
+extern int secp256k1_ext_combined_mul(const secp256k1_context* ctx, const unsigned char *point, const unsigned char *pscalar, const unsigned char *gscalar);
 */
 import "C"
 
@@ -51,6 +54,33 @@ const (
 	wordBytes = wordBits / 8
 )
 
+// mulBuffers holds the point and scalars passed to the multiplication
+// routines in C. Buffers are pooled, and cleared before being returned to the
+// pool since the scalars are usually secret.
+type mulBuffers struct {
+	point   [64]byte
+	scalars [64]byte
+}
+
+var mulBufferPool = sync.Pool{
+	New: func() interface{} { return new(mulBuffers) },
+}
+
+func getBuffers() *mulBuffers {
+	return mulBufferPool.Get().(*mulBuffers)
+}
+
+// unpack returns the point held in the buffer.
+func (buf *mulBuffers) unpack() (*big.Int, *big.Int) {
+	return new(big.Int).SetBytes(buf.point[:32]), new(big.Int).SetBytes(buf.point[32:])
+}
+
+// release clears the buffers and returns them to the pool.
+func (buf *mulBuffers) release() {
+	*buf = mulBuffers{}
+	mulBufferPool.Put(buf)
+}
+
 // readBits encodes the absolute value of bigint as big-endian bytes. Callers
 // must ensure that buf has enough space. If buf is too short the result will
 // be incomplete.
@@ -244,39 +274,67 @@ func (BitCurve *BitCurve) ScalarMult(Bx, By *big.Int, scalar []byte) (*big.Int,
 	if len(scalar) > 32 {
 		panic("can't handle scalars > 256 bits")
 	}
+	buf := getBuffers()
+	defer buf.release()
+
 	// NOTE: potential timing issue
-	padded := make([]byte, 32)
-	copy(padded[32-len(scalar):], scalar)
-	scalar = padded
+	copy(buf.scalars[32-len(scalar):32], scalar)
 
 	// Do the multiplication in C, updating point.
-	point := make([]byte, 64)
-	readBits(Bx, point[:32])
-	readBits(By, point[32:])
-
-	pointPtr := (*C.uchar)(unsafe.Pointer(&point[0]))
-	scalarPtr := (*C.uchar)(unsafe.Pointer(&scalar[0]))
-	res := C.secp256k1_ext_scalar_mul(context, pointPtr, scalarPtr)
-
-	// Unpack the result and clear temporaries.
-	x := new(big.Int).SetBytes(point[:32])
-	y := new(big.Int).SetBytes(point[32:])
-	for i := range point {
-		point[i] = 0
-	}
-	for i := range padded {
-		scalar[i] = 0
-	}
-	if res != 1 {
+	readBits(Bx, buf.point[:32])
+	readBits(By, buf.point[32:])
+
+	pointPtr := (*C.uchar)(unsafe.Pointer(&buf.point[0]))
+	scalarPtr := (*C.uchar)(unsafe.Pointer(&buf.scalars[0]))
+	if C.secp256k1_ext_scalar_mul(context, pointPtr, scalarPtr) != 1 {
 		return nil, nil
 	}
-	return x, y
+	return buf.unpack()
 }
 
 // ScalarBaseMult returns k*G, where G is the base point of the group and k is
-// an integer in big-endian form.
+// an integer in big-endian form. It uses the precomputed multiples of G of the
+// signing context, which is considerably faster than ScalarMult.
 func (BitCurve *BitCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
-	return BitCurve.ScalarMult(BitCurve.Gx, BitCurve.Gy, k)
+	if len(k) > 32 {
+		panic("can't handle scalars > 256 bits")
+	}
+	buf := getBuffers()
+	defer buf.release()
+
+	copy(buf.scalars[32-len(k):32], k)
+
+	pointPtr := (*C.uchar)(unsafe.Pointer(&buf.point[0]))
+	scalarPtr := (*C.uchar)(unsafe.Pointer(&buf.scalars[0]))
+	if C.secp256k1_ext_scalar_base_mul(context, pointPtr, scalarPtr) != 1 {
+		return nil, nil
+	}
+	return buf.unpack()
+}
+
+// CombinedMult returns baseScalar*G + scalar*B, where G is the base point of
+// the group and both scalars are integers in big-endian form. It is much faster
+// than two separate multiplications, but runs in variable time and must only be
+// used with public values, such as when verifying signatures.
+func (BitCurve *BitCurve) CombinedMult(Bx, By *big.Int, baseScalar, scalar []byte) (*big.Int, *big.Int) {
+	if len(baseScalar) > 32 || len(scalar) > 32 {
+		panic("can't handle scalars > 256 bits")
+	}
+	buf := getBuffers()
+	defer buf.release()
+
+	copy(buf.scalars[32-len(scalar):32], scalar)
+	copy(buf.scalars[64-len(baseScalar):], baseScalar)
+	readBits(Bx, buf.point[:32])
+	readBits(By, buf.point[32:])
+
+	pointPtr := (*C.uchar)(unsafe.Pointer(&buf.point[0]))
+	pscalarPtr := (*C.uchar)(unsafe.Pointer(&buf.scalars[0]))
+	gscalarPtr := (*C.uchar)(unsafe.Pointer(&buf.scalars[32]))
+	if C.secp256k1_ext_combined_mul(context, pointPtr, pscalarPtr, gscalarPtr) != 1 {
+		return nil, nil
+	}
+	return buf.unpack()
 }
 
 // Marshal converts a point into the form specified in section 4.3.6 of ANSI
@@ -128,3 +128,80 @@ int secp256k1_ext_scalar_mul(const secp256k1_context* ctx, unsigned char *point,
 	secp256k1_scalar_clear(&s);
 	return ret;
 }
+
+// secp256k1_ext_scalar_base_mul multiplies the generator by a scalar in
+// constant time, using the precomputed tables of the context.
+//
+// Returns: 1: multiplication was successful
+//          0: scalar was invalid (zero or overflow)
+// Args:    ctx:      pointer to a context object initialized for signing (cannot be NULL)
+//  Out:    point:    the multiplied point (usually secret),
+//                    encoded as two 256bit big-endian numbers.
+//  In:     scalar:   a 32-byte scalar with which to multiply the generator
+int secp256k1_ext_scalar_base_mul(const secp256k1_context* ctx, unsigned char *point, const unsigned char *scalar) {
+	int ret = 0;
+	int overflow = 0;
+	secp256k1_gej res;
+	secp256k1_ge ge;
+	secp256k1_scalar s;
+	ARG_CHECK(point != NULL);
+	ARG_CHECK(scalar != NULL);
+	ARG_CHECK(secp256k1_ecmult_gen_context_is_built(&ctx->ecmult_gen_ctx));
+
+	secp256k1_scalar_set_b32(&s, scalar, &overflow);
+	if (!overflow && !secp256k1_scalar_is_zero(&s)) {
+		secp256k1_ecmult_gen(&ctx->ecmult_gen_ctx, &res, &s);
+		secp256k1_ge_set_gej(&ge, &res);
+		secp256k1_fe_normalize(&ge.x);
+		secp256k1_fe_normalize(&ge.y);
+		secp256k1_fe_get_b32(point, &ge.x);
+		secp256k1_fe_get_b32(point+32, &ge.y);
+		ret = 1;
+	}
+	secp256k1_scalar_clear(&s);
+	return ret;
+}
+
+// secp256k1_ext_combined_mul computes na*A + ng*G in a single pass, where G is
+// the generator. It runs in variable time and must only be used with public
+// scalars, e.g. when verifying signatures. Scalars larger than the group order
+// are reduced.
+//
+// Returns: 1: multiplication was successful
+//          0: the result is the point at infinity
+// Args:    ctx:      pointer to a context object initialized for verification (cannot be NULL)
+//  Out:    point:    the resulting point,
+//                    encoded as two 256bit big-endian numbers.
+//  In:     point:    pointer to the 64-byte point A,
+//                    encoded as two 256bit big-endian numbers.
+//          pscalar:  a 32-byte scalar with which to multiply A
+//          gscalar:  a 32-byte scalar with which to multiply the generator
+int secp256k1_ext_combined_mul(const secp256k1_context* ctx, unsigned char *point, const unsigned char *pscalar, const unsigned char *gscalar) {
+	int overflow = 0;
+	secp256k1_fe feX, feY;
+	secp256k1_gej a, res;
+	secp256k1_ge ge;
+	secp256k1_scalar na, ng;
+	ARG_CHECK(point != NULL);
+	ARG_CHECK(pscalar != NULL);
+	ARG_CHECK(gscalar != NULL);
+	ARG_CHECK(secp256k1_ecmult_context_is_built(&ctx->ecmult_ctx));
+
+	secp256k1_fe_set_b32(&feX, point);
+	secp256k1_fe_set_b32(&feY, point+32);
+	secp256k1_ge_set_xy(&ge, &feX, &feY);
+	secp256k1_gej_set_ge(&a, &ge);
+	secp256k1_scalar_set_b32(&na, pscalar, &overflow);
+	secp256k1_scalar_set_b32(&ng, gscalar, &overflow);
+
+	secp256k1_ecmult(&ctx->ecmult_ctx, &res, &a, &na, &ng);
+	if (secp256k1_gej_is_infinity(&res)) {
+		return 0;
+	}
+	secp256k1_ge_set_gej(&ge, &res);
+	secp256k1_fe_normalize(&ge.x);
+	secp256k1_fe_normalize(&ge.y);
+	secp256k1_fe_get_b32(point, &ge.x);
+	secp256k1_fe_get_b32(point+32, &ge.y);
+	return 1;
+}
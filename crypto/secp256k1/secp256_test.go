@@ -11,6 +11,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"io"
+	"math/big"
 	"testing"
 )
 
@@ -236,3 +237,91 @@ func BenchmarkRecover(b *testing.B) {
 		RecoverPubkey(msg, sig)
 	}
 }
+
+func TestScalarBaseMult(t *testing.T) {
+	curve := S256()
+	for i := 0; i < TestCount; i++ {
+		k := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, k); err != nil {
+			t.Fatal(err)
+		}
+		x1, y1 := curve.ScalarBaseMult(k)
+		x2, y2 := curve.ScalarMult(curve.Gx, curve.Gy, k)
+		if x1.Cmp(x2) != 0 || y1.Cmp(y2) != 0 {
+			t.Fatalf("k*G mismatch for k = %x", k)
+		}
+	}
+	// scalars of at least the group order are rejected, like by ScalarMult
+	for _, k := range []*big.Int{curve.N, new(big.Int).Add(curve.N, big.NewInt(1)), new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))} {
+		if x, y := curve.ScalarBaseMult(k.Bytes()); x != nil || y != nil {
+			t.Errorf("overflowing scalar %x accepted", k)
+		}
+		if x, y := curve.ScalarMult(curve.Gx, curve.Gy, k.Bytes()); x != nil || y != nil {
+			t.Errorf("ScalarMult accepted overflowing scalar %x", k)
+		}
+	}
+	if x, y := curve.ScalarBaseMult(new(big.Int).Sub(curve.N, big.NewInt(1)).Bytes()); x == nil || y == nil {
+		t.Errorf("largest valid scalar rejected")
+	}
+}
+
+func BenchmarkScalarBaseMult(b *testing.B) {
+	k := make([]byte, 32)
+	io.ReadFull(rand.Reader, k)
+	b.Run("ScalarMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			S256().ScalarMult(S256().Gx, S256().Gy, k)
+		}
+	})
+	b.Run("ScalarBaseMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			S256().ScalarBaseMult(k)
+		}
+	})
+}
+
+func TestCombinedMult(t *testing.T) {
+	curve := S256()
+	for i := 0; i < TestCount; i++ {
+		k, l := make([]byte, 32), make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, k); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadFull(rand.Reader, l); err != nil {
+			t.Fatal(err)
+		}
+		bx, by := curve.ScalarBaseMult(l)
+
+		x1, y1 := curve.CombinedMult(bx, by, k, l)
+		gx, gy := curve.ScalarBaseMult(k)
+		px, py := curve.ScalarMult(bx, by, l)
+		x2, y2 := curve.Add(gx, gy, px, py)
+		if x1.Cmp(x2) != 0 || y1.Cmp(y2) != 0 {
+			t.Fatalf("k*G + l*B mismatch for k = %x, l = %x", k, l)
+		}
+	}
+	// G - G is the point at infinity
+	minusOne := new(big.Int).Sub(curve.N, big.NewInt(1)).Bytes()
+	if x, y := curve.CombinedMult(curve.Gx, curve.Gy, []byte{1}, minusOne); x != nil || y != nil {
+		t.Errorf("G - G is not the point at infinity")
+	}
+}
+
+func BenchmarkCombinedMult(b *testing.B) {
+	k, l := make([]byte, 32), make([]byte, 32)
+	io.ReadFull(rand.Reader, k)
+	io.ReadFull(rand.Reader, l)
+	bx, by := S256().ScalarBaseMult(l)
+	b.Run("separate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			gx, gy := S256().ScalarBaseMult(k)
+			px, py := S256().ScalarMult(bx, by, l)
+			S256().Add(gx, gy, px, py)
+		}
+	})
+	b.Run("combined", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			S256().CombinedMult(bx, by, k, l)
+		}
+	})
+}
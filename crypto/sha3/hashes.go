@@ -69,3 +69,7 @@ func Sum512(data []byte) (digest [64]byte) {
 	h.Sum(digest[:0])
 	return
 }
+
+// KeccakF1600 applies the Keccak-f[1600] permutation to a state of 25 lanes.
+// It is exposed for duplex constructions such as STROBE.
+func KeccakF1600(state *[25]uint64) { keccakF1600(state) }
@@ -14,7 +14,8 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// +build !nacl,!js,!nocgo
+//go:build !nacl && !js && !nocgo && cgo
+// +build !nacl,!js,!nocgo,cgo
 
 package crypto
 
@@ -19,6 +19,7 @@ package eth
 import (
 	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"io"
@@ -34,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -467,3 +469,72 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 	}
 	return dirty, nil
 }
+
+// PublicRingMemberAPI exposes the ring member index, letting users audit how
+// often their key was used in rings and researchers measure anonymity sets.
+type PublicRingMemberAPI struct {
+	eth *Ethereum
+}
+
+// NewPublicRingMemberAPI creates a new ring member index API.
+func NewPublicRingMemberAPI(eth *Ethereum) *PublicRingMemberAPI {
+	return &PublicRingMemberAPI{eth}
+}
+
+// RingMemberTx is a ring transaction with a given key among its members.
+type RingMemberTx struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	RingSize    hexutil.Uint64 `json:"ringSize"`
+}
+
+// RingMemberTxsResult is the result of ring_getTransactionsByMember.
+type RingMemberTxsResult struct {
+	IndexedHead  hexutil.Uint64  `json:"indexedHead"` // last block covered by the index
+	Transactions []*RingMemberTx `json:"transactions"`
+}
+
+// GetTransactionsByMember returns the canonical ring transactions with the
+// given public key, compressed or not, among their ring members, as a signer
+// or as a decoy. Only blocks up to the indexed head are covered, as recent
+// blocks are indexed once confirmed.
+func (api *PublicRingMemberAPI) GetTransactionsByMember(member hexutil.Bytes) (*RingMemberTxsResult, error) {
+	var (
+		pub *ecdsa.PublicKey
+		err error
+	)
+	switch len(member) {
+	case 33:
+		pub, err = crypto.DecompressPubkey(member)
+	case 65:
+		pub, err = crypto.UnmarshalPubkey(member)
+	default:
+		err = fmt.Errorf("invalid length %d", len(member))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid ring member: %v", err)
+	}
+	res := &RingMemberTxsResult{Transactions: []*RingMemberTx{}}
+
+	sections, head, _ := api.eth.ringMemberIndexer.Sections()
+	if sections == 0 {
+		return res, nil
+	}
+	res.IndexedHead = hexutil.Uint64(head)
+
+	db := api.eth.ChainDb()
+	for _, entry := range rawdb.ReadRingMemberTxs(db, crypto.CompressPubkey(pub)) {
+		// Skip entries of blocks reorged out, or above a rolled back index
+		if entry.BlockNumber > head || rawdb.ReadCanonicalHash(db, entry.BlockNumber) != entry.BlockHash {
+			continue
+		}
+		res.Transactions = append(res.Transactions, &RingMemberTx{
+			BlockNumber: hexutil.Uint64(entry.BlockNumber),
+			BlockHash:   entry.BlockHash,
+			TxHash:      entry.TxHash,
+			RingSize:    hexutil.Uint64(entry.RingSize),
+		})
+	}
+	return res, nil
+}
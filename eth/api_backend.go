@@ -189,6 +189,10 @@ func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 	return b.eth.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+func (b *EthAPIBackend) KeyImageSpent(ctx context.Context, image []byte) (bool, error) {
+	return b.eth.TxPool().KeyImageSpent(image), nil
+}
+
 func (b *EthAPIBackend) Downloader() *downloader.Downloader {
 	return b.eth.Downloader()
 }
@@ -621,6 +621,8 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 			Failed:      failed,
 			ReturnValue: fmt.Sprintf("%x", ret),
 			StructLogs:  ethapi.FormatLogs(tracer.StructLogs()),
+
+			RingVerifications: ethapi.FormatRingVerifyLogs(tracer.RingVerifyLogs()),
 		}, nil
 
 	case *tracers.Tracer:
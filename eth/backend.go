@@ -39,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/eth/ringscan"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
@@ -84,6 +85,10 @@ type Ethereum struct {
 
 	APIBackend *EthAPIBackend
 
+	ringScanner *ringscan.Scanner // Stealth output scanner, enabled with --rpc.ring
+
+	ringMemberIndexer *core.ChainIndexer // Ring member indexer, enabled with --rpc.ring.memberindex
+
 	miner     *miner.Miner
 	gasPrice  *big.Int
 	etherbase common.Address
@@ -168,6 +173,11 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
+	if config.RingMemberIndex {
+		eth.ringMemberIndexer = NewRingMemberIndexer(chainDb, ringMemberSectionSize, ringMemberConfirms)
+		eth.ringMemberIndexer.Start(eth.blockchain)
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
@@ -187,6 +197,10 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	}
 	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gpoParams)
 
+	if config.RingRPC {
+		eth.ringScanner = ringscan.New(eth.APIBackend)
+	}
+
 	return eth, nil
 }
 
@@ -258,6 +272,20 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	if s.config.RingRPC {
+		apis = append(apis, ethapi.GetRingAPIs(s.APIBackend)...)
+		apis = append(apis, s.ringScanner.APIs()...)
+
+		if s.ringMemberIndexer != nil {
+			apis = append(apis, rpc.API{
+				Namespace: "ring",
+				Version:   "1.0",
+				Service:   NewPublicRingMemberAPI(s),
+				Public:    true,
+			})
+		}
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -502,6 +530,9 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	if s.ringScanner != nil {
+		s.ringScanner.Start()
+	}
 	return nil
 }
 
@@ -509,6 +540,12 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
 	s.bloomIndexer.Close()
+	if s.ringMemberIndexer != nil {
+		s.ringMemberIndexer.Close()
+	}
+	if s.ringScanner != nil {
+		s.ringScanner.Stop()
+	}
 	s.blockchain.Stop()
 	s.engine.Close()
 	s.protocolManager.Stop()
@@ -119,6 +119,12 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables the ring signature RPC API (ring_*)
+	RingRPC bool
+
+	// Enables the index of ring transactions by member public key
+	RingMemberIndex bool
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
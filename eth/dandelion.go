@@ -0,0 +1,227 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	stemEpoch       = 10 * time.Minute // Lifetime of the stem relays and of the stem/fluff mode
+	stemFluffProb   = 0.1              // Probability of fluffing relayed stems during an epoch
+	stemRelays      = 2                // Number of peers stem transactions are relayed to
+	stemEmbargo     = 30 * time.Second // Minimum time to wait for a stem transaction to be fluffed
+	stemEmbargoMean = 15 * time.Second // Mean of the random time added to the embargo
+	stemCheckCycle  = time.Second      // Interval of checking for expired embargoes
+
+	maxStemTxs    = 4096  // Maximum number of transactions in their stem phase
+	maxFluffedTxs = 32768 // Maximum number of fluffed transaction hashes to remember
+)
+
+// stemTx is a transaction in its stem phase, fluffed by the local node if the
+// embargo expires before it is seen broadcast.
+type stemTx struct {
+	tx      *types.Transaction
+	local   bool      // whether the transaction is in the local pool
+	embargo time.Time // time to fluff the transaction at
+}
+
+// dandelion tracks the Dandelion++ propagation of ring transactions. Instead
+// of being broadcast right away, ring transactions are first relayed along a
+// random path of peers (the stem), each hop fluffing them to all its peers
+// with a small probability. The node that fluffs a transaction is unrelated
+// to the node that created it, which hides the origin from observers of the
+// broadcast.
+//
+// Relays and the mode are chosen per epoch, so that the stem of transactions
+// from one peer doesn't change and can't be probed with repeated relays.
+type dandelion struct {
+	epoch   time.Time               // start of the current epoch
+	fluff   bool                    // whether relayed stems are fluffed in this epoch
+	relays  []*peer                 // peers stem transactions are relayed to
+	routes  map[string]*peer        // relay of the stems of each peer, by id ("" for local)
+	stems   map[common.Hash]*stemTx // transactions in their stem phase
+	fluffed mapset.Set              // hashes of transactions seen or sent broadcast
+
+	rand *rand.Rand
+	lock sync.Mutex
+}
+
+// newDandelion creates the Dandelion++ state of a node.
+func newDandelion() *dandelion {
+	return &dandelion{
+		routes:  make(map[string]*peer),
+		stems:   make(map[common.Hash]*stemTx),
+		fluffed: mapset.NewSet(),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// stem records a transaction relayed from a peer, or created locally if from
+// is nil, and returns the peer to relay it to. A nil relay means the
+// transaction must be fluffed. Transactions already stemmed or fluffed are
+// reported known and need no further relay. Relays are picked among peers if
+// a new epoch starts.
+func (d *dandelion) stem(tx *types.Transaction, from *peer, peers []*peer) (relay *peer, known bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	hash := tx.Hash()
+	if d.fluffed.Contains(hash) {
+		return nil, true
+	}
+	if _, ok := d.stems[hash]; ok {
+		return nil, true
+	}
+	now := time.Now()
+	if now.Sub(d.epoch) >= stemEpoch || len(d.relays) == 0 {
+		d.newEpoch(now, peers)
+	}
+	// Local transactions are always stemmed, relayed ones unless in fluff mode
+	if from != nil && d.fluff {
+		return nil, false
+	}
+	if relay = d.route(from); relay == nil || len(d.stems) >= maxStemTxs {
+		return nil, false
+	}
+	delay := time.Duration(d.rand.ExpFloat64() * float64(stemEmbargoMean))
+	d.stems[hash] = &stemTx{tx: tx, local: from == nil, embargo: now.Add(stemEmbargo + delay)}
+	return relay, false
+}
+
+// newEpoch picks the mode and relays of a new epoch. Relays are preferably
+// outbound peers, which an attacker can't choose to be.
+func (d *dandelion) newEpoch(now time.Time, peers []*peer) {
+	d.epoch = now
+	d.fluff = d.rand.Float64() < stemFluffProb
+	d.routes = make(map[string]*peer)
+
+	var outbound, inbound []*peer
+	for _, i := range d.rand.Perm(len(peers)) {
+		p := peers[i]
+		if p.version < eth64 {
+			continue
+		}
+		if p.Peer.Inbound() {
+			inbound = append(inbound, p)
+		} else {
+			outbound = append(outbound, p)
+		}
+	}
+	d.relays = append(outbound, inbound...)
+	if len(d.relays) > stemRelays {
+		d.relays = d.relays[:stemRelays]
+	}
+}
+
+// route returns the relay of the stems of a peer, assigning it one if needed.
+// Stems are never relayed back to the peer they came from.
+func (d *dandelion) route(from *peer) *peer {
+	var id string
+	if from != nil {
+		id = from.id
+	}
+	if relay, ok := d.routes[id]; ok {
+		return relay
+	}
+	var candidates []*peer
+	for _, p := range d.relays {
+		if p != from {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	relay := candidates[d.rand.Intn(len(candidates))]
+	d.routes[id] = relay
+	return relay
+}
+
+// markFluffed records transactions as broadcast, ending their stem phase.
+func (d *dandelion) markFluffed(txs ...*types.Transaction) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for _, tx := range txs {
+		hash := tx.Hash()
+		delete(d.stems, hash)
+		for d.fluffed.Cardinality() >= maxFluffedTxs {
+			d.fluffed.Pop()
+		}
+		d.fluffed.Add(hash)
+	}
+}
+
+// isFluffed reports whether a transaction was seen or sent broadcast.
+func (d *dandelion) isFluffed(hash common.Hash) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.fluffed.Contains(hash)
+}
+
+// isStem reports whether a transaction is in its stem phase.
+func (d *dandelion) isStem(hash common.Hash) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	_, ok := d.stems[hash]
+	return ok
+}
+
+// expired removes and returns the stem transactions whose embargo expired by
+// now, which the node must fluff itself.
+func (d *dandelion) expired(now time.Time) []*stemTx {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var expired []*stemTx
+	for hash, stem := range d.stems {
+		if !now.Before(stem.embargo) {
+			expired = append(expired, stem)
+			delete(d.stems, hash)
+		}
+	}
+	return expired
+}
+
+// dropPeer forgets a disconnected peer, replacing it as a relay on the next
+// stem if it was one.
+func (d *dandelion) dropPeer(id string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for i, p := range d.relays {
+		if p.id == id {
+			d.relays = append(d.relays[:i:i], d.relays[i+1:]...)
+			break
+		}
+	}
+	delete(d.routes, id)
+	for from, relay := range d.routes {
+		if relay.id == id {
+			delete(d.routes, from)
+		}
+	}
+}
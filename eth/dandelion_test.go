@@ -0,0 +1,153 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTestRingTransaction creates a ring transaction signed by the first of
+// three random ring members.
+func newTestRingTransaction(t *testing.T, nonce uint64) *types.Transaction {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	members := make(ring.Ring, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		members[i] = &keys[i].PublicKey
+	}
+	signer := types.NewRingSigner(params.TestChainConfig.ChainID)
+	tx := types.NewRingTransaction(params.TestChainConfig.ChainID, nonce, &common.Address{}, big.NewInt(1), 100000, big.NewInt(1), nil)
+	tx, err := types.SignRingTx(tx, signer, members, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestDandelionStem(t *testing.T) {
+	var peers []*peer
+	for i, version := range []int{eth63, eth64, eth64, eth64} {
+		peers = append(peers, newPeer(version, p2p.NewPeer(enode.ID{byte(i)}, "", nil), nil))
+	}
+	d := newDandelion()
+
+	// Local transactions are stemmed to the same eth/64 relay
+	tx := newTestRingTransaction(t, 0)
+	relay, known := d.stem(tx, nil, peers)
+	if relay == nil || known || relay.version != eth64 {
+		t.Fatalf("local stem: have relay %v known %v, want eth/64 relay", relay, known)
+	}
+	if len(d.relays) != stemRelays {
+		t.Fatalf("relay count mismatch: have %d, want %d", len(d.relays), stemRelays)
+	}
+	if other, _ := d.stem(newTestRingTransaction(t, 1), nil, peers); other != relay {
+		t.Errorf("local stems relayed to different peers")
+	}
+	if _, known := d.stem(tx, nil, peers); !known {
+		t.Errorf("repeated stem not known")
+	}
+	// Stems from a relay are never sent back to it
+	d.fluff = false
+	if back, _ := d.stem(newTestRingTransaction(t, 2), relay, peers); back == nil || back == relay {
+		t.Errorf("stem from relay: have relay %v, want other relay", back)
+	}
+	// Relayed stems are fluffed in fluff mode, local ones still stemmed
+	d.fluff = true
+	if relay, known := d.stem(newTestRingTransaction(t, 3), peers[3], peers); relay != nil || known {
+		t.Errorf("stem in fluff mode: have relay %v known %v, want fluff", relay, known)
+	}
+	if relay, _ := d.stem(newTestRingTransaction(t, 4), nil, peers); relay == nil {
+		t.Errorf("local stem fluffed in fluff mode")
+	}
+	// Fluffed transactions end their stem phase, the rest are fluffed on embargo
+	d.markFluffed(tx)
+	if d.isStem(tx.Hash()) || !d.isFluffed(tx.Hash()) {
+		t.Errorf("fluffed transaction still stemming")
+	}
+	if expired := d.expired(time.Now()); len(expired) != 0 {
+		t.Errorf("embargo expired early for %d transactions", len(expired))
+	}
+	if expired := d.expired(time.Now().Add(time.Hour)); len(expired) != 3 {
+		t.Errorf("expired embargo count mismatch: have %d, want 3", len(expired))
+	}
+	// Dropped relays are replaced once none remain
+	for _, p := range d.relays {
+		d.dropPeer(p.id)
+	}
+	if relay, _ := d.stem(newTestRingTransaction(t, 5), nil, peers); relay == nil {
+		t.Errorf("no relay picked after drop")
+	}
+}
+
+// Tests that local ring transactions are relayed to a single eth/64 peer, and
+// that stems received in fluff mode are added to the pool.
+func TestStemTransactions(t *testing.T) {
+	txAdded := make(chan []*types.Transaction, 1)
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, txAdded)
+	pm.acceptTxs = 1 // mark synced to accept transactions
+	defer pm.Stop()
+
+	p, _ := newTestPeer("peer", eth64, pm, true)
+	defer p.close()
+
+	// Wait for the peer to be registered after its handshake
+	for pm.peers.Len() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	tx := newTestRingTransaction(t, 0)
+	pm.BroadcastTxs(types.Transactions{tx})
+	msg, err := p.app.ReadMsg()
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if msg.Code != StemTxMsg {
+		t.Fatalf("got code %d, want StemTxMsg", msg.Code)
+	}
+	var txs []*types.Transaction
+	if err := msg.Decode(&txs); err != nil || len(txs) != 1 || txs[0].Hash() != tx.Hash() {
+		t.Fatalf("stemmed transactions mismatch: %v", err)
+	}
+	// Stems received in fluff mode go to the pool
+	pm.dandelion.lock.Lock()
+	pm.dandelion.fluff = true
+	pm.dandelion.lock.Unlock()
+
+	stem := newTestRingTransaction(t, 1)
+	if err := p2p.Send(p.app, StemTxMsg, []interface{}{stem}); err != nil {
+		t.Fatalf("send error: %v", err)
+	}
+	select {
+	case added := <-txAdded:
+		if len(added) != 1 || added[0].Hash() != stem.Hash() {
+			t.Errorf("fluffed wrong transactions")
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("stem not fluffed within 2 seconds")
+	}
+}
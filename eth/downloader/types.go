@@ -25,6 +25,10 @@ import (
 // peerDropFn is a callback type for dropping a peer detected as malicious.
 type peerDropFn func(id string)
 
+// pivotFn is a callback type for announcing the pivot block picked by a fast
+// sync with a peer, whenever it moves.
+type pivotFn func(id string, pivot *types.Header)
+
 // dataPack is a data message returned by a peer for some query.
 type dataPack interface {
 	PeerId() string
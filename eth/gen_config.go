@@ -42,6 +42,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
+		RingRPC                 bool
+		RingMemberIndex         bool
 		DocRoot                 string `toml:"-"`
 	}
 	var enc Config
@@ -68,6 +70,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.RingRPC = c.RingRPC
+	enc.RingMemberIndex = c.RingMemberIndex
 	enc.DocRoot = c.DocRoot
 	return &enc, nil
 }
@@ -98,6 +102,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
+		RingRPC                 *bool
+		RingMemberIndex         *bool
 		DocRoot                 *string `toml:"-"`
 	}
 	var dec Config
@@ -173,6 +179,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.RingRPC != nil {
+		c.RingRPC = *dec.RingRPC
+	}
+	if dec.RingMemberIndex != nil {
+		c.RingMemberIndex = *dec.RingMemberIndex
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
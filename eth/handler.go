@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/fetcher"
@@ -74,12 +75,19 @@ type ProtocolManager struct {
 
 	txpool      txPool
 	blockchain  *core.BlockChain
+	chaindb     ethdb.Database
 	chainconfig *params.ChainConfig
 	maxPeers    int
 
 	downloader *downloader.Downloader
 	fetcher    *fetcher.Fetcher
 	peers      *peerSet
+	dandelion  *dandelion
+	stemSigner types.Signer
+
+	keyImageReqs    *keyImageRequests
+	keyImageSyncing int32         // Flag whether a key image set is being prefetched (atomic access)
+	forkFilter      forkid.Filter // Checks the fork IDs of eth/64 peers against the local chain
 
 	SubProtocols []p2p.Protocol
 
@@ -104,17 +112,24 @@ type ProtocolManager struct {
 func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, networkID uint64, mux *event.TypeMux, txpool txPool, engine consensus.Engine, blockchain *core.BlockChain, chaindb ethdb.Database) (*ProtocolManager, error) {
 	// Create the protocol manager with the base fields
 	manager := &ProtocolManager{
-		networkID:   networkID,
-		eventMux:    mux,
-		txpool:      txpool,
-		blockchain:  blockchain,
-		chainconfig: config,
-		peers:       newPeerSet(),
-		newPeerCh:   make(chan *peer),
-		noMorePeers: make(chan struct{}),
-		txsyncCh:    make(chan *txsync),
-		quitSync:    make(chan struct{}),
+		networkID:    networkID,
+		eventMux:     mux,
+		txpool:       txpool,
+		blockchain:   blockchain,
+		chaindb:      chaindb,
+		chainconfig:  config,
+		peers:        newPeerSet(),
+		dandelion:    newDandelion(),
+		stemSigner:   types.NewRingSigner(config.ChainID),
+		keyImageReqs: newKeyImageRequests(),
+		newPeerCh:    make(chan *peer),
+		noMorePeers:  make(chan struct{}),
+		txsyncCh:     make(chan *txsync),
+		quitSync:     make(chan struct{}),
 	}
+	manager.forkFilter = forkid.NewFilter(config, blockchain.Genesis().Hash(), func() uint64 {
+		return blockchain.CurrentHeader().Number.Uint64()
+	})
 	// Figure out whether to allow fast sync or not
 	if mode == downloader.FastSync && blockchain.CurrentBlock().NumberU64() > 0 {
 		log.Warn("Blockchain not empty, fast sync disabled")
@@ -162,7 +177,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		return nil, errIncompatibleConfig
 	}
 	// Construct the different synchronisation mechanisms
-	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.removePeer)
+	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.removePeer, manager.syncPivotKeyImages)
 
 	validator := func(header *types.Header) error {
 		return engine.VerifyHeader(blockchain, header, true)
@@ -194,6 +209,7 @@ func (pm *ProtocolManager) removePeer(id string) {
 
 	// Unregister the peer from the downloader and Ethereum peer set
 	pm.downloader.UnregisterPeer(id)
+	pm.dandelion.dropPeer(id)
 	if err := pm.peers.Unregister(id); err != nil {
 		log.Error("Peer removal failed", "peer", id, "err", err)
 	}
@@ -210,6 +226,7 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.txsCh = make(chan core.NewTxsEvent, txChanSize)
 	pm.txsSub = pm.txpool.SubscribeNewTxsEvent(pm.txsCh)
 	go pm.txBroadcastLoop()
+	go pm.stemEmbargoLoop()
 
 	// broadcast mined blocks
 	pm.minedBlockSub = pm.eventMux.Subscribe(core.NewMinedBlockEvent{})
@@ -230,7 +247,7 @@ func (pm *ProtocolManager) Stop() {
 	// After this send has completed, no new peers will be accepted.
 	pm.noMorePeers <- struct{}{}
 
-	// Quit fetcher, txsyncLoop.
+	// Quit fetcher, txsyncLoop, stemEmbargoLoop.
 	close(pm.quitSync)
 
 	// Disconnect existing sessions.
@@ -266,7 +283,8 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		number  = head.Number.Uint64()
 		td      = pm.blockchain.GetTd(hash, number)
 	)
-	if err := p.Handshake(pm.networkID, td, hash, genesis.Hash()); err != nil {
+	forkID := forkid.NewID(pm.chainconfig, genesis.Hash(), number)
+	if err := p.Handshake(pm.networkID, td, hash, genesis.Hash(), forkID, pm.forkFilter); err != nil {
 		p.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
 	}
@@ -683,8 +701,48 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			p.MarkTransaction(tx.Hash())
 		}
+		pm.dandelion.markFluffed(txs...)
 		pm.txpool.AddRemotes(txs)
 
+	case p.version >= eth64 && msg.Code == StemTxMsg:
+		// Ring transactions in their stem phase arrived, relay them along
+		if atomic.LoadUint32(&pm.acceptTxs) == 0 {
+			break
+		}
+		var txs []*types.Transaction
+		if err := msg.Decode(&txs); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		for i, tx := range txs {
+			// Validate and mark the remote transaction
+			if tx == nil {
+				return errResp(ErrDecode, "transaction %d is nil", i)
+			}
+			if tx.Type() != types.RingTxType {
+				return errResp(ErrDecode, "stem transaction %d is not a ring transaction", i)
+			}
+			p.MarkTransaction(tx.Hash())
+		}
+		pm.stemTxs(p, txs)
+
+	case p.version >= eth64 && msg.Code == GetKeyImagesMsg:
+		// Decode the key image range query and answer it
+		var query getKeyImagesData
+		if err := msg.Decode(&query); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		return p.SendKeyImages(pm.serveKeyImages(&query))
+
+	case p.version >= eth64 && msg.Code == KeyImagesMsg:
+		// A range of the key image set arrived to one of our previous requests
+		var res keyImagesData
+		if err := msg.Decode(&res); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		if !pm.keyImageReqs.deliver(p.id, &res) {
+			log.Debug("Unrequested key image range", "peer", p.id, "id", res.ID)
+		}
+
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
@@ -734,10 +792,17 @@ func (pm *ProtocolManager) BroadcastBlock(block *types.Block, propagate bool) {
 // BroadcastTxs will propagate a batch of transactions to all peers which are not known to
 // already have the given transaction.
 func (pm *ProtocolManager) BroadcastTxs(txs types.Transactions) {
-	var txset = make(map[*peer]types.Transactions)
-
-	// Broadcast transactions to a batch of peers not knowing about it
+	var (
+		txset = make(map[*peer]types.Transactions)
+		stems types.Transactions
+	)
+	// Broadcast transactions to a batch of peers not knowing about it, except
+	// for new ring transactions which are first relayed along a stem
 	for _, tx := range txs {
+		if tx.Type() == types.RingTxType && !pm.dandelion.isFluffed(tx.Hash()) {
+			stems = append(stems, tx)
+			continue
+		}
 		peers := pm.peers.PeersWithoutTx(tx.Hash())
 		for _, peer := range peers {
 			txset[peer] = append(txset[peer], tx)
@@ -748,6 +813,56 @@ func (pm *ProtocolManager) BroadcastTxs(txs types.Transactions) {
 	for peer, txs := range txset {
 		peer.AsyncSendTransactions(txs)
 	}
+	if len(stems) > 0 {
+		pm.stemTxs(nil, stems)
+	}
+}
+
+// stemTxs relays ring transactions in their Dandelion++ stem phase, received
+// from a peer or created locally if from is nil. Transactions the node decides
+// to fluff are broadcast instead: local ones directly, relayed ones through
+// the transaction pool, which they weren't added to while stemming.
+func (pm *ProtocolManager) stemTxs(from *peer, txs types.Transactions) {
+	var (
+		relays = make(map[*peer]types.Transactions)
+		fluff  types.Transactions
+		peers  = pm.peers.Peers()
+	)
+	for _, tx := range txs {
+		// Don't relay invalid stems, as peers can't tell where they came from
+		if from != nil {
+			if _, err := types.Sender(pm.stemSigner, tx); err != nil {
+				from.Log().Debug("Dropping invalid stem transaction", "hash", tx.Hash(), "err", err)
+				continue
+			}
+		}
+		relay, known := pm.dandelion.stem(tx, from, peers)
+		switch {
+		case known:
+			continue
+		case relay == nil:
+			fluff = append(fluff, tx)
+		default:
+			relays[relay] = append(relays[relay], tx)
+		}
+		log.Trace("Stem transaction", "hash", tx.Hash(), "fluff", relay == nil)
+	}
+	for relay, txs := range relays {
+		relay.AsyncSendStemTransactions(txs)
+	}
+	if len(fluff) > 0 {
+		pm.fluffTxs(fluff, from == nil)
+	}
+}
+
+// fluffTxs ends the stem phase of transactions, broadcasting them to all peers.
+func (pm *ProtocolManager) fluffTxs(txs types.Transactions, local bool) {
+	pm.dandelion.markFluffed(txs...)
+	if local {
+		pm.BroadcastTxs(txs)
+	} else {
+		pm.txpool.AddRemotes(txs)
+	}
 }
 
 // Mined broadcast loop
@@ -774,6 +889,37 @@ func (pm *ProtocolManager) txBroadcastLoop() {
 	}
 }
 
+// stemEmbargoLoop fluffs the stem transactions that weren't seen broadcast
+// before their embargo expired, in case a node along the stem dropped them.
+func (pm *ProtocolManager) stemEmbargoLoop() {
+	ticker := time.NewTicker(stemCheckCycle)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			var local, remote types.Transactions
+			for _, stem := range pm.dandelion.expired(now) {
+				log.Trace("Stem transaction embargo expired", "hash", stem.tx.Hash())
+				if stem.local {
+					local = append(local, stem.tx)
+				} else {
+					remote = append(remote, stem.tx)
+				}
+			}
+			if len(local) > 0 {
+				pm.fluffTxs(local, true)
+			}
+			if len(remote) > 0 {
+				pm.fluffTxs(remote, false)
+			}
+
+		case <-pm.quitSync:
+			return
+		}
+	}
+}
+
 // NodeInfo represents a short summary of the Ethereum sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
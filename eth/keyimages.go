@@ -0,0 +1,331 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// The spent key images are stored in the storage trie of the key image
+// precompile. Instead of retrieving the nodes of that trie one by one with the
+// rest of the state, a syncing node downloads the storage entries in ranges
+// of hashed keys. Each range comes with the proof of the account of the
+// precompile in the state of the requested block, and of the first and last
+// entries of the range. The entries are inserted into a fresh trie, and the
+// set is complete once its root matches the storage root of the account, at
+// which point the trie is committed to the database. The state sync of the
+// downloader then finds the nodes of the key image set locally and skips them.
+
+const (
+	keyImageFetchTimeout = 10 * time.Second // Maximum time to wait for a key image range
+	keyImageSyncTimeout  = 5 * time.Minute  // Maximum time to spend downloading a key image set
+	maxKeyImageFetch     = 16384            // Maximum number of key image entries in a range
+)
+
+var (
+	errKeyImagesUnavailable = errors.New("key image set unavailable")
+	errKeyImagesIncomplete  = errors.New("key image set incomplete")
+	errKeyImagesTimeout     = errors.New("key image request timed out")
+	errKeyImagesCancelled   = errors.New("key image sync cancelled")
+)
+
+// keyImageRequests tracks the key image range requests awaiting a response.
+type keyImageRequests struct {
+	next    uint64
+	pending map[uint64]*keyImageRequest
+	lock    sync.Mutex
+}
+
+// keyImageRequest is a key image range request sent to a peer.
+type keyImageRequest struct {
+	peer    string
+	deliver chan *keyImagesData
+}
+
+func newKeyImageRequests() *keyImageRequests {
+	return &keyImageRequests{pending: make(map[uint64]*keyImageRequest)}
+}
+
+// add registers a request to the peer with the given id, returning the
+// request identifier and the channel its response is delivered on.
+func (r *keyImageRequests) add(peer string) (uint64, chan *keyImagesData) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.next++
+	req := &keyImageRequest{peer: peer, deliver: make(chan *keyImagesData, 1)}
+	r.pending[r.next] = req
+	return r.next, req.deliver
+}
+
+// remove forgets a request, whether answered or not.
+func (r *keyImageRequests) remove(id uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.pending, id)
+}
+
+// deliver hands a response from a peer to the matching request, returning
+// whether one was pending.
+func (r *keyImageRequests) deliver(peer string, res *keyImagesData) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	req, ok := r.pending[res.ID]
+	if !ok || req.peer != peer {
+		return false
+	}
+	delete(r.pending, res.ID)
+	req.deliver <- res
+	return true
+}
+
+// serveKeyImages retrieves the range of the key image set requested by query.
+// The header of the response is left nil if the block or its state is not
+// available locally.
+func (pm *ProtocolManager) serveKeyImages(query *getKeyImagesData) *keyImagesData {
+	res := &keyImagesData{ID: query.ID}
+
+	header := pm.blockchain.GetHeaderByHash(query.Block)
+	if header == nil {
+		return res
+	}
+	db := pm.blockchain.StateCache()
+	tr, err := db.OpenTrie(header.Root)
+	if err != nil {
+		return res
+	}
+	addrHash := crypto.Keccak256Hash(vm.KeyImageSetAddress.Bytes())
+	proof := ethdb.NewMemDatabase()
+	if err := tr.Prove(addrHash[:], 0, proof); err != nil {
+		return res
+	}
+	enc, err := tr.TryGet(vm.KeyImageSetAddress.Bytes())
+	if err != nil {
+		return res
+	}
+	if enc != nil {
+		var account state.Account
+		if err := rlp.DecodeBytes(enc, &account); err != nil {
+			log.Error("Invalid key image account", "block", query.Block, "err", err)
+			return res
+		}
+		st, err := db.OpenStorageTrie(addrHash, account.Root)
+		if err != nil {
+			return res
+		}
+		limit := query.Bytes
+		if limit > softResponseLimit {
+			limit = softResponseLimit
+		}
+		var (
+			size uint64
+			it   = trie.NewIterator(st.NodeIterator(query.Origin[:]))
+		)
+		for size < limit && len(res.Keys) < maxKeyImageFetch && it.Next() {
+			res.Keys = append(res.Keys, common.BytesToHash(it.Key))
+			res.Values = append(res.Values, common.CopyBytes(it.Value))
+			size += common.HashLength + uint64(len(it.Value))
+		}
+		if it.Err != nil {
+			return res
+		}
+		if len(res.Keys) > 0 {
+			if err := st.Prove(res.Keys[0][:], 0, proof); err != nil {
+				return res
+			}
+			if err := st.Prove(res.Keys[len(res.Keys)-1][:], 0, proof); err != nil {
+				return res
+			}
+		}
+	}
+	for _, key := range proof.Keys() {
+		node, _ := proof.Get(key)
+		res.Proof = append(res.Proof, node)
+	}
+	res.Header = header
+	return res
+}
+
+// verifyKeyImages checks a range of the key image set received for the block
+// with the given hash, starting at origin. It returns the storage root of the
+// key image set, proven by the response.
+//
+// Only the bounds of the range can be proven, the entries in between are
+// checked once the whole set is assembled against the storage root.
+func verifyKeyImages(block common.Hash, origin common.Hash, res *keyImagesData) (common.Hash, error) {
+	if res.Header == nil {
+		return common.Hash{}, errKeyImagesUnavailable
+	}
+	if hash := res.Header.Hash(); hash != block {
+		return common.Hash{}, fmt.Errorf("header mismatch: have %x, want %x", hash, block)
+	}
+	if len(res.Keys) != len(res.Values) {
+		return common.Hash{}, fmt.Errorf("key/value count mismatch: %d keys, %d values", len(res.Keys), len(res.Values))
+	}
+	proof := ethdb.NewMemDatabase()
+	for _, node := range res.Proof {
+		proof.Put(crypto.Keccak256(node), node)
+	}
+	// Retrieve the storage root from the proven account, an absent one has no
+	// key images
+	enc, _, err := trie.VerifyProof(res.Header.Root, crypto.Keccak256(vm.KeyImageSetAddress.Bytes()), proof)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid account proof: %v", err)
+	}
+	root := types.EmptyRootHash
+	if enc != nil {
+		var account state.Account
+		if err := rlp.DecodeBytes(enc, &account); err != nil {
+			return common.Hash{}, fmt.Errorf("invalid account: %v", err)
+		}
+		root = account.Root
+	}
+	// Ensure the range is ordered and starts at the origin, then check its bounds
+	for i, key := range res.Keys {
+		if i == 0 && bytes.Compare(key[:], origin[:]) < 0 {
+			return common.Hash{}, fmt.Errorf("range starts at %x before origin %x", key, origin)
+		}
+		if i > 0 && bytes.Compare(key[:], res.Keys[i-1][:]) <= 0 {
+			return common.Hash{}, fmt.Errorf("range not ascending at %d", i)
+		}
+	}
+	if len(res.Keys) > 0 {
+		for _, i := range []int{0, len(res.Keys) - 1} {
+			value, _, err := trie.VerifyProof(root, res.Keys[i][:], proof)
+			if err != nil {
+				return common.Hash{}, fmt.Errorf("invalid proof of entry %d: %v", i, err)
+			}
+			if !bytes.Equal(value, res.Values[i]) {
+				return common.Hash{}, fmt.Errorf("value mismatch of entry %d", i)
+			}
+		}
+	}
+	return root, nil
+}
+
+// requestKeyImages retrieves a range of the key image set from a peer.
+func (pm *ProtocolManager) requestKeyImages(p *peer, block common.Hash, origin common.Hash) (*keyImagesData, error) {
+	id, deliver := pm.keyImageReqs.add(p.id)
+	defer pm.keyImageReqs.remove(id)
+
+	if err := p.RequestKeyImages(id, block, origin, softResponseLimit); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(keyImageFetchTimeout)
+	defer timeout.Stop()
+
+	select {
+	case res := <-deliver:
+		return res, nil
+	case <-timeout.C:
+		return nil, errKeyImagesTimeout
+	case <-pm.quitSync:
+		return nil, errKeyImagesCancelled
+	}
+}
+
+// syncPivotKeyImages is called by the downloader whenever a fast sync picks a
+// pivot block. It prefetches the key image set of the pivot from the peer in
+// the background, sparing the state sync from retrieving most of its trie node
+// by node, and drops the peer if it fails to serve it. A prefetch still running
+// when the pivot moves is left to finish.
+func (pm *ProtocolManager) syncPivotKeyImages(id string, pivot *types.Header) {
+	p := pm.peers.Peer(id)
+	if p == nil || p.version < eth64 {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&pm.keyImageSyncing, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&pm.keyImageSyncing, 0)
+
+		if _, err := pm.syncKeyImages(p, pivot.Hash()); err != nil && err != errKeyImagesCancelled {
+			log.Debug("Key image sync failed", "peer", id, "pivot", pivot.Number, "err", err)
+			pm.removePeer(id)
+		}
+	}()
+}
+
+// syncKeyImages downloads the key image set in the state of the given block
+// from a peer, and stores its trie in the database. It returns the number of
+// storage entries retrieved.
+func (pm *ProtocolManager) syncKeyImages(p *peer, block common.Hash) (int, error) {
+	triedb := trie.NewDatabase(pm.chaindb)
+	tr, _ := trie.New(common.Hash{}, triedb)
+
+	var (
+		origin common.Hash
+		count  int
+		start  = time.Now()
+	)
+	for {
+		if time.Since(start) > keyImageSyncTimeout {
+			return count, errKeyImagesTimeout
+		}
+		res, err := pm.requestKeyImages(p, block, origin)
+		if err != nil {
+			return count, err
+		}
+		root, err := verifyKeyImages(block, origin, res)
+		if err != nil {
+			return count, err
+		}
+		for i, key := range res.Keys {
+			tr.Update(key[:], res.Values[i])
+		}
+		count += len(res.Keys)
+
+		if tr.Hash() == root {
+			if _, err := tr.Commit(nil); err != nil {
+				return count, err
+			}
+			if err := triedb.Commit(root, false); err != nil {
+				return count, err
+			}
+			log.Info("Imported key image set", "block", block, "entries", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			return count, nil
+		}
+		// Continue after the last entry, unless the peer withheld the rest
+		if len(res.Keys) == 0 {
+			return count, errKeyImagesIncomplete
+		}
+		next := new(big.Int).Add(res.Keys[len(res.Keys)-1].Big(), common.Big1)
+		if next.BitLen() > 8*common.HashLength {
+			return count, errKeyImagesIncomplete
+		}
+		origin = common.BigToHash(next)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// newTestKeyImageManager creates a protocol manager whose genesis state holds
+// n spent key images, returned along with the manager.
+func newTestKeyImageManager(t *testing.T, n int) (*ProtocolManager, [][]byte) {
+	var (
+		images  = make([][]byte, n)
+		storage = make(map[common.Hash]common.Hash)
+	)
+	for i := range images {
+		images[i] = crypto.Keccak512(big.NewInt(int64(i)).Bytes())
+		storage[vm.KeyImageSlot(images[i])] = common.BigToHash(big.NewInt(1))
+	}
+	var (
+		db    = ethdb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{vm.KeyImageSetAddress: {Nonce: 1, Balance: new(big.Int), Storage: storage}},
+		}
+		_             = gspec.MustCommit(db)
+		blockchain, _ = core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	)
+	pm, err := NewProtocolManager(gspec.Config, downloader.FullSync, DefaultConfig.NetworkId, new(event.TypeMux), &testTxPool{}, ethash.NewFaker(), blockchain, db)
+	if err != nil {
+		t.Fatalf("Failed to create protocol manager: %v", err)
+	}
+	pm.Start(1000)
+	return pm, images
+}
+
+// Tests that the key image set is served in ranges which verify and assemble
+// into the original storage trie, and that tampered ranges are rejected.
+func TestKeyImageRanges(t *testing.T) {
+	pm, _ := newTestKeyImageManager(t, 100)
+	defer pm.Stop()
+
+	block := pm.blockchain.CurrentBlock().Hash()
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(ethdb.NewMemDatabase()))
+
+	var (
+		origin common.Hash
+		ranges int
+		root   common.Hash
+	)
+	for {
+		res := pm.serveKeyImages(&getKeyImagesData{Block: block, Origin: origin, Bytes: 1000})
+		var err error
+		if root, err = verifyKeyImages(block, origin, res); err != nil {
+			t.Fatalf("range %d rejected: %v", ranges, err)
+		}
+		if len(res.Keys) == 0 {
+			break
+		}
+		for i, key := range res.Keys {
+			tr.Update(key[:], res.Values[i])
+		}
+		ranges++
+		origin = common.BigToHash(new(big.Int).Add(res.Keys[len(res.Keys)-1].Big(), common.Big1))
+	}
+	if ranges < 2 {
+		t.Errorf("set served in %d ranges, want several", ranges)
+	}
+	if tr.Hash() != root {
+		t.Fatalf("assembled root mismatch: have %x, want %x", tr.Hash(), root)
+	}
+	// Tampered ranges, foreign headers and missing blocks are rejected
+	res := pm.serveKeyImages(&getKeyImagesData{Block: block, Bytes: 1000})
+	res.Values[len(res.Values)-1] = []byte{0x02}
+	if _, err := verifyKeyImages(block, common.Hash{}, res); err == nil {
+		t.Error("tampered range accepted")
+	}
+	res = pm.serveKeyImages(&getKeyImagesData{Block: block, Bytes: 1000})
+	if _, err := verifyKeyImages(common.Hash{1}, common.Hash{}, res); err == nil {
+		t.Error("range of other block accepted")
+	}
+	if _, err := verifyKeyImages(block, res.Keys[1], res); err == nil {
+		t.Error("range before origin accepted")
+	}
+	res = pm.serveKeyImages(&getKeyImagesData{Block: common.Hash{1}, Bytes: 1000})
+	if _, err := verifyKeyImages(common.Hash{1}, common.Hash{}, res); err != errKeyImagesUnavailable {
+		t.Errorf("unknown block: have error %v, want %v", err, errKeyImagesUnavailable)
+	}
+}
+
+// Tests that a node downloads the key image set from a peer over the wire and
+// stores it in its database.
+func TestKeyImageSync(t *testing.T) {
+	source, images := newTestKeyImageManager(t, 300)
+	defer source.Stop()
+
+	sink, _ := newTestProtocolManagerMust(t, downloader.FastSync, 0, nil, nil)
+	defer sink.Stop()
+	sink.chaindb = ethdb.NewMemDatabase()
+
+	p, _ := newTestPeer("source", eth64, sink, true)
+	defer p.close()
+
+	// Answer the key image queries of the sink from the source
+	go func() {
+		for {
+			msg, err := p.app.ReadMsg()
+			if err != nil {
+				return
+			}
+			if msg.Code != GetKeyImagesMsg {
+				msg.Discard()
+				continue
+			}
+			var query getKeyImagesData
+			if err := msg.Decode(&query); err != nil {
+				return
+			}
+			p2p.Send(p.app, KeyImagesMsg, source.serveKeyImages(&query))
+		}
+	}()
+	head := source.blockchain.CurrentBlock()
+	count, err := sink.syncKeyImages(p.peer, head.Hash())
+	if err != nil {
+		t.Fatalf("key image sync failed: %v", err)
+	}
+	if count != len(images) {
+		t.Errorf("entry count mismatch: have %d, want %d", count, len(images))
+	}
+	// The synced trie resolves every key image from the database of the sink
+	statedb, _ := source.blockchain.StateAt(head.Root())
+	root := statedb.StorageTrie(vm.KeyImageSetAddress).Hash()
+
+	st, err := state.NewDatabase(sink.chaindb).OpenStorageTrie(crypto.Keccak256Hash(vm.KeyImageSetAddress.Bytes()), root)
+	if err != nil {
+		t.Fatalf("synced storage trie missing: %v", err)
+	}
+	for i, image := range images {
+		if enc, err := st.TryGet(vm.KeyImageSlot(image).Bytes()); err != nil || len(enc) == 0 {
+			t.Fatalf("key image %d missing: %v", i, err)
+		}
+	}
+}
+
+// Tests that the key image set of a fast sync pivot is prefetched in the
+// background, and that a peer failing to serve it is dropped.
+func TestKeyImageSyncDrop(t *testing.T) {
+	sink, _ := newTestProtocolManagerMust(t, downloader.FastSync, 0, nil, nil)
+	defer sink.Stop()
+
+	p, _ := newTestPeer("source", eth64, sink, true)
+	defer p.close()
+
+	// Answer the key image queries of the sink as if the pivot state was gone
+	go func() {
+		for {
+			msg, err := p.app.ReadMsg()
+			if err != nil {
+				return
+			}
+			if msg.Code != GetKeyImagesMsg {
+				msg.Discard()
+				continue
+			}
+			var query getKeyImagesData
+			if err := msg.Decode(&query); err != nil {
+				return
+			}
+			p2p.Send(p.app, KeyImagesMsg, &keyImagesData{ID: query.ID})
+		}
+	}()
+	for i := 0; sink.peers.Peer(p.id) == nil; i++ {
+		if i == 100 {
+			t.Fatal("peer not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	sink.syncPivotKeyImages(p.id, sink.blockchain.Genesis().Header())
+
+	for i := 0; sink.peers.Peer(p.id) != nil; i++ {
+		if i == 100 {
+			t.Fatal("peer withholding the key image set not dropped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
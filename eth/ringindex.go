@@ -0,0 +1,139 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+const (
+	// ringMemberSectionSize is the number of blocks indexed at once by the ring
+	// member indexer.
+	ringMemberSectionSize = 256
+
+	// ringMemberConfirms is the number of confirmations a block needs before
+	// its ring transactions are indexed.
+	ringMemberConfirms = 64
+
+	// ringMemberThrottling is the time to wait between processing two
+	// consecutive index sections.
+	ringMemberThrottling = 100 * time.Millisecond
+)
+
+var errMissingRingMemberBody = errors.New("missing block body")
+
+// RingMemberIndexer implements a core.ChainIndexer, indexing the ring
+// transactions of the canonical chain by the public keys of their ring members.
+// Every member is indexed, whether the actual signer or a decoy: the index
+// tells how often a key was used in rings, never who signed.
+type RingMemberIndexer struct {
+	db      ethdb.Database                       // database instance to write index data into
+	size    uint64                               // section size to index ring members for
+	section uint64                               // section number being processed currently
+	members map[string][]rawdb.RingMemberTxEntry // ring transactions of the section by compressed member key
+}
+
+// NewRingMemberIndexer returns a chain indexer that indexes the ring
+// transactions of the canonical chain by member public key.
+func NewRingMemberIndexer(db ethdb.Database, size, confirms uint64) *core.ChainIndexer {
+	backend := &RingMemberIndexer{
+		db:   db,
+		size: size,
+	}
+	table := ethdb.NewTable(db, string(rawdb.RingMemberIndexPrefix))
+
+	return core.NewChainIndexer(db, table, backend, size, confirms, ringMemberThrottling, "ringmembers")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new ring member index
+// section.
+func (r *RingMemberIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	r.section, r.members = section, make(map[string][]rawdb.RingMemberTxEntry)
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the ring transactions of
+// a new header's block into the index.
+func (r *RingMemberIndexer) Process(ctx context.Context, header *types.Header) error {
+	hash, number := header.Hash(), header.Number.Uint64()
+
+	body := rawdb.ReadBody(r.db, hash, number)
+	if body == nil {
+		return errMissingRingMemberBody
+	}
+	for _, tx := range body.Transactions {
+		sig := tx.RingSignature()
+		if sig == nil {
+			continue
+		}
+		entry := rawdb.RingMemberTxEntry{
+			BlockNumber: number,
+			BlockHash:   hash,
+			TxHash:      tx.Hash(),
+			RingSize:    uint64(len(sig.Ring)),
+		}
+		for _, member := range sig.Ring {
+			key := string(crypto.CompressPubkey(member))
+			r.members[key] = append(r.members[key], entry)
+		}
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, merging the ring transactions of
+// the section into the index. Entries previously written for the section, by
+// an interrupted run or before a reorg, are replaced.
+func (r *RingMemberIndexer) Commit() error {
+	var (
+		first = r.section * r.size
+		last  = first + r.size - 1
+		batch = r.db.NewBatch()
+	)
+	for key, entries := range r.members {
+		member := []byte(key)
+
+		// Keep the entries outside the section, later ones only left behind
+		// by rollbacks, in block order
+		var before, after []rawdb.RingMemberTxEntry
+		for _, entry := range rawdb.ReadRingMemberTxs(r.db, member) {
+			switch {
+			case entry.BlockNumber < first:
+				before = append(before, entry)
+			case entry.BlockNumber > last:
+				after = append(after, entry)
+			}
+		}
+		rawdb.WriteRingMemberTxs(batch, member, append(append(before, entries...), after...))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return batch.Write()
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the ring member indexer indexes every member of ring transactions,
+// and that reindexing a section replaces its entries.
+func TestRingMemberIndexer(t *testing.T) {
+	var (
+		db   = ethdb.NewMemDatabase()
+		keys = make([]*ecdsa.PrivateKey, 3)
+		ms   = make(ring.Ring, len(keys))
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		ms[i] = &keys[i].PublicKey
+	}
+	from, _ := types.RingFingerprint(ms)
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{from: {Balance: big.NewInt(1000000000)}},
+	}
+	genesis := gspec.MustCommit(db)
+
+	signer := types.NewRingSigner(gspec.Config.ChainID)
+	txs := make([]*types.Transaction, 2)
+	for i := range txs {
+		tx := types.NewRingTransaction(gspec.Config.ChainID, uint64(i), &common.Address{}, big.NewInt(1), params.TxGas+uint64(len(ms))*params.RingVerifyPerMemberGas, new(big.Int), nil)
+		tx, err := types.SignRingTx(tx, signer, ms, keys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs[i] = tx
+	}
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, block *core.BlockGen) {
+		if i < len(txs) {
+			block.AddTx(txs[i])
+		}
+	})
+	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer blockchain.Stop()
+
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	indexer := &RingMemberIndexer{db: db, size: 4}
+
+	index := func() {
+		if err := indexer.Reset(context.Background(), 0, common.Hash{}); err != nil {
+			t.Fatal(err)
+		}
+		for number := uint64(0); number < 4; number++ {
+			if err := indexer.Process(context.Background(), blockchain.GetHeaderByNumber(number)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := indexer.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index()
+	index()
+
+	for i, member := range ms {
+		entries := rawdb.ReadRingMemberTxs(db, crypto.CompressPubkey(member))
+		if len(entries) != len(txs) {
+			t.Fatalf("member %d: entry count mismatch: have %d, want %d", i, len(entries), len(txs))
+		}
+		for j, entry := range entries {
+			if entry.TxHash != txs[j].Hash() || entry.BlockHash != blocks[j].Hash() || entry.BlockNumber != blocks[j].NumberU64() {
+				t.Errorf("member %d entry %d: mismatch: have %x/%d, want %x/%d", i, j, entry.TxHash, entry.BlockNumber, txs[j].Hash(), blocks[j].NumberU64())
+			}
+			if entry.RingSize != uint64(len(ms)) {
+				t.Errorf("member %d entry %d: ring size mismatch: have %d, want %d", i, j, entry.RingSize, len(ms))
+			}
+		}
+	}
+	// Keys never used in a ring aren't indexed
+	other, _ := crypto.GenerateKey()
+	if entries := rawdb.ReadRingMemberTxs(db, crypto.CompressPubkey(&other.PublicKey)); len(entries) != 0 {
+		t.Fatalf("non member indexed: %v", entries)
+	}
+}
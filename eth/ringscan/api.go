@@ -0,0 +1,364 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringscan
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PrivateScannerAPI exposes the scanner over RPC. It handles view keys, so it
+// must not be offered on public endpoints.
+type PrivateScannerAPI struct {
+	s *Scanner
+}
+
+// NewPrivateScannerAPI creates a new scanner API.
+func NewPrivateScannerAPI(s *Scanner) *PrivateScannerAPI {
+	return &PrivateScannerAPI{s}
+}
+
+// APIs returns the RPC APIs of the scanner.
+func (s *Scanner) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "ring",
+			Version:   "1.0",
+			Service:   NewPrivateScannerAPI(s),
+			Public:    false,
+		}, {
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   NewPrivatePaymentsAPI(s),
+			Public:    false,
+		},
+	}
+}
+
+// RPCOutput is an output owned by a wallet, as returned over RPC.
+type RPCOutput struct {
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+	BlockHash   common.Hash     `json:"blockHash"`
+	TxHash      common.Hash     `json:"transactionHash"`
+	Leaf        hexutil.Uint64  `json:"leaf"`
+	Index       hexutil.Uint64  `json:"index"`
+	Key         hexutil.Bytes   `json:"key"`
+	Commitment  hexutil.Bytes   `json:"commitment"`
+	TxPub       hexutil.Bytes   `json:"txPub"`
+	Amount      *hexutil.Uint64 `json:"amount"` // nil if the note didn't open the commitment
+	Mask        *hexutil.Big    `json:"mask"`
+	PaymentID   hexutil.Bytes   `json:"paymentId"`
+	KeyImage    hexutil.Bytes   `json:"keyImage"`
+}
+
+// newRPCOutput converts an output to its RPC representation.
+func newRPCOutput(out *Output) *RPCOutput {
+	enc := &RPCOutput{
+		BlockNumber: hexutil.Uint64(out.BlockNumber),
+		BlockHash:   out.BlockHash,
+		TxHash:      out.TxHash,
+		Leaf:        hexutil.Uint64(out.Leaf),
+		Index:       hexutil.Uint64(out.Index),
+		Key:         crypto.CompressPubkey(out.Key),
+		Commitment:  crypto.CompressPubkey(out.Commitment),
+		TxPub:       crypto.CompressPubkey(out.TxPub),
+	}
+	if out.Opened {
+		amount := hexutil.Uint64(out.Amount)
+		enc.Amount = &amount
+		enc.Mask = (*hexutil.Big)(out.Mask)
+		enc.PaymentID = out.PaymentID[:]
+	}
+	if out.Image != nil {
+		enc.KeyImage = imageBytes(out.Image)
+	}
+	return enc
+}
+
+// StealthBalance is the balance of a wallet and how far it was scanned.
+type StealthBalance struct {
+	Balance   *hexutil.Big   `json:"balance"`
+	Scanned   hexutil.Uint64 `json:"scanned"` // next block to scan
+	WatchOnly bool           `json:"watchOnly"`
+}
+
+// RegisterStealthWallet starts scanning from block from for outputs paid to the
+// wallet with the given 32 byte view private key and spend key. The spend key
+// is either a 32 byte private key, or a public key for a watch-only wallet
+// that can't detect spends. It returns the identifier of the wallet.
+func (api *PrivateScannerAPI) RegisterStealthWallet(view, spend hexutil.Bytes, from hexutil.Uint64) (common.Hash, error) {
+	viewKey, err := crypto.ToECDSA(view)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid view key: %v", err)
+	}
+	var keys *ring.WalletKeys
+	switch len(spend) {
+	case 32:
+		spendKey, err := crypto.ToECDSA(spend)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("invalid spend key: %v", err)
+		}
+		keys = ring.NewWalletKeys(viewKey, spendKey)
+	case 33, 65:
+		var spendPub *ecdsa.PublicKey
+		if len(spend) == 33 {
+			spendPub, err = crypto.DecompressPubkey(spend)
+		} else {
+			spendPub, err = crypto.UnmarshalPubkey(spend)
+		}
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("invalid spend key: %v", err)
+		}
+		keys = ring.NewWatchOnlyKeys(viewKey, spendPub)
+	default:
+		return common.Hash{}, fmt.Errorf("invalid spend key length %d", len(spend))
+	}
+	return api.s.Register(keys, uint64(from)), nil
+}
+
+// UnregisterStealthWallet stops scanning for a wallet and forgets its outputs.
+func (api *PrivateScannerAPI) UnregisterStealthWallet(id common.Hash) bool {
+	return api.s.Unregister(id)
+}
+
+// GetStealthBalance returns the total amount of the unspent outputs of a
+// wallet found so far.
+func (api *PrivateScannerAPI) GetStealthBalance(ctx context.Context, id common.Hash) (*StealthBalance, error) {
+	balance, err := api.s.Balance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	next, watchOnly, err := api.s.Status(id)
+	if err != nil {
+		return nil, err
+	}
+	return &StealthBalance{Balance: (*hexutil.Big)(balance), Scanned: hexutil.Uint64(next), WatchOnly: watchOnly}, nil
+}
+
+// ListUnspent returns the unspent outputs of a wallet found so far, oldest
+// first, with the openings of their commitments needed to spend them.
+func (api *PrivateScannerAPI) ListUnspent(ctx context.Context, id common.Hash) ([]*RPCOutput, error) {
+	unspent, err := api.s.Unspent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]*RPCOutput, len(unspent))
+	for i, out := range unspent {
+		outputs[i] = newRPCOutput(out)
+	}
+	return outputs, nil
+}
+
+// PrivatePaymentsAPI offers subscriptions to the payments found by the
+// scanner in the eth namespace.
+type PrivatePaymentsAPI struct {
+	s *Scanner
+}
+
+// NewPrivatePaymentsAPI creates a new payments subscription API.
+func NewPrivatePaymentsAPI(s *Scanner) *PrivatePaymentsAPI {
+	return &PrivatePaymentsAPI{s}
+}
+
+// RPCPayment is the notification of a payment to a wallet. Payments in blocks
+// reorged out are sent again with removed set.
+type RPCPayment struct {
+	Wallet common.Hash `json:"wallet"`
+	*RPCOutput
+	Removed bool `json:"removed"`
+}
+
+// IncomingPayments creates a subscription that fires for every output paid to
+// the registered wallet with the given identifier, including outputs found
+// while catching up on earlier blocks.
+func (api *PrivatePaymentsAPI) IncomingPayments(ctx context.Context, id common.Hash) (*rpc.Subscription, error) {
+	if _, _, err := api.s.Status(id); err != nil {
+		return nil, err
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		payments := make(chan PaymentEvent, 128)
+		paymentsSub := api.s.SubscribePayments(payments)
+		defer paymentsSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-payments:
+				if ev.Wallet == id {
+					notifier.Notify(rpcSub.ID, &RPCPayment{Wallet: ev.Wallet, RPCOutput: newRPCOutput(ev.Output), Removed: ev.Removed})
+				}
+			case <-paymentsSub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// RPCKeyImage is an exported key image, as passed over RPC.
+type RPCKeyImage struct {
+	Key   hexutil.Bytes `json:"key"`
+	Image hexutil.Bytes `json:"keyImage"`
+	C     *hexutil.Big  `json:"c"`
+	S     *hexutil.Big  `json:"s"`
+}
+
+// RPCAuditSpend is a spend of an audit report, as returned over RPC.
+type RPCAuditSpend struct {
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+	BlockHash   common.Hash     `json:"blockHash"`
+	TxHash      common.Hash     `json:"transactionHash"`
+	KeyImages   []hexutil.Bytes `json:"keyImages"`
+	Commitments []hexutil.Bytes `json:"commitments"`
+	Fee         hexutil.Uint64  `json:"fee"`
+}
+
+// RPCAuditReport is an audit report, as returned over RPC.
+type RPCAuditReport struct {
+	View      hexutil.Bytes    `json:"view"`
+	Spend     hexutil.Bytes    `json:"spend"`
+	From      hexutil.Uint64   `json:"fromBlock"`
+	To        hexutil.Uint64   `json:"toBlock"`
+	Incoming  []*RPCOutput     `json:"incoming"`
+	Outgoing  []*RPCAuditSpend `json:"outgoing"`
+	Hash      common.Hash      `json:"hash"`
+	Signature hexutil.Bytes    `json:"signature"`
+}
+
+// ExportKeyImages returns the key images of the outputs of a registered wallet
+// found so far, with the proofs that they belong to the outputs, for auditors
+// and watch-only wallets to find the wallet's spends. Watch-only wallets export
+// the key images imported for their outputs.
+func (api *PrivateScannerAPI) ExportKeyImages(id common.Hash) ([]*RPCKeyImage, error) {
+	exports, err := api.s.ExportKeyImages(id)
+	if err != nil {
+		return nil, err
+	}
+	enc := make([]*RPCKeyImage, len(exports))
+	for i, exp := range exports {
+		enc[i] = &RPCKeyImage{
+			Key:   crypto.CompressPubkey(exp.Key),
+			Image: imageBytes(exp.Image),
+			C:     (*hexutil.Big)(exp.Proof.C),
+			S:     (*hexutil.Big)(exp.Proof.S),
+		}
+	}
+	return enc, nil
+}
+
+// ImportKeyImages adds key images exported by the owner of a registered
+// watch-only wallet, so that spent outputs are left out of its balance and
+// unspent outputs. It returns the number of outputs found so far whose key
+// image was imported.
+func (api *PrivateScannerAPI) ImportKeyImages(id common.Hash, keyImages []*RPCKeyImage) (hexutil.Uint64, error) {
+	exports, err := parseKeyImages(keyImages)
+	if err != nil {
+		return 0, err
+	}
+	matched, err := api.s.ImportKeyImages(id, exports)
+	return hexutil.Uint64(matched), err
+}
+
+// parseKeyImages decodes exported key images.
+func parseKeyImages(keyImages []*RPCKeyImage) ([]*KeyImageExport, error) {
+	exports := make([]*KeyImageExport, len(keyImages))
+	for i, enc := range keyImages {
+		key, err := crypto.DecompressPubkey(enc.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key of key image %d: %v", i, err)
+		}
+		image, err := crypto.UnmarshalPubkey(append([]byte{4}, enc.Image...))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key image %d: %v", i, err)
+		}
+		if enc.C == nil || enc.S == nil {
+			return nil, fmt.Errorf("missing proof of key image %d", i)
+		}
+		exports[i] = &KeyImageExport{Key: key, Image: image, Proof: &ring.KeyImageProof{C: enc.C.ToInt(), S: enc.S.ToInt()}}
+	}
+	return exports, nil
+}
+
+// GetAuditReport returns a report of the payments of the wallet with the given
+// 32 byte view private key and spend public key between blocks from and to,
+// signed with the view key. Spends are found with the exported key images of
+// the wallet's outputs.
+func (api *PrivateScannerAPI) GetAuditReport(ctx context.Context, view, spend hexutil.Bytes, keyImages []*RPCKeyImage, from, to hexutil.Uint64) (*RPCAuditReport, error) {
+	viewKey, err := crypto.ToECDSA(view)
+	if err != nil {
+		return nil, fmt.Errorf("invalid view key: %v", err)
+	}
+	spendPub, err := crypto.DecompressPubkey(spend)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spend key: %v", err)
+	}
+	exports, err := parseKeyImages(keyImages)
+	if err != nil {
+		return nil, err
+	}
+	report, err := api.s.Audit(ctx, ring.NewWatchOnlyKeys(viewKey, spendPub), exports, uint64(from), uint64(to))
+	if err != nil {
+		return nil, err
+	}
+	enc := &RPCAuditReport{
+		View:      crypto.CompressPubkey(report.Address.View),
+		Spend:     crypto.CompressPubkey(report.Address.Spend),
+		From:      hexutil.Uint64(report.From),
+		To:        hexutil.Uint64(report.To),
+		Incoming:  make([]*RPCOutput, len(report.Incoming)),
+		Outgoing:  make([]*RPCAuditSpend, len(report.Outgoing)),
+		Hash:      report.Hash(),
+		Signature: report.Signature,
+	}
+	for i, out := range report.Incoming {
+		enc.Incoming[i] = newRPCOutput(out)
+	}
+	for i, spend := range report.Outgoing {
+		rpcSpend := &RPCAuditSpend{
+			BlockNumber: hexutil.Uint64(spend.BlockNumber),
+			BlockHash:   spend.BlockHash,
+			TxHash:      spend.TxHash,
+			Fee:         hexutil.Uint64(spend.Fee),
+		}
+		for _, image := range spend.KeyImages {
+			rpcSpend.KeyImages = append(rpcSpend.KeyImages, imageBytes(image))
+		}
+		for _, commitment := range spend.Commitments {
+			rpcSpend.Commitments = append(rpcSpend.Commitments, crypto.CompressPubkey(commitment))
+		}
+		enc.Outgoing[i] = rpcSpend
+	}
+	return enc, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringscan
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Audit reports disclose the shielded payments of a wallet over a range of
+// blocks to a third party, such as a regulator or an accountant, without
+// handing over the ability to spend. Incoming payments are found and opened
+// with the view key. Outgoing payments are found with key images exported by
+// the owner, who proves that every key image belongs to one of its outputs:
+// spends carrying one of them consumed the wallet's outputs, and the
+// commitments of their outputs not paid back to the wallet are the
+// counterparts. Reports are signed with the view key of the wallet.
+//
+// The owner decides which key images to export, so a report can only show
+// that the listed spends happened, not that there were no others. Outputs
+// without an exported key image are marked as such.
+
+// maxAuditRange is the maximum number of blocks an audit report covers.
+const maxAuditRange = 100000
+
+var (
+	errAuditRange     = errors.New("invalid audit block range")
+	errAuditSignature = errors.New("invalid audit report signature")
+)
+
+// AuditSpend is a shielded pool spend of outputs of the audited wallet.
+type AuditSpend struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+
+	KeyImages   []*ecdsa.PublicKey // key images of the wallet's outputs spent
+	Commitments []*ecdsa.PublicKey // amount commitments of the outputs paid to others
+	Fee         uint64             // plaintext fee of the spend
+}
+
+// AuditReport lists the shielded payments of a wallet in a range of blocks.
+type AuditReport struct {
+	Address *ring.StealthAddress // audited wallet
+	From    uint64               // first block covered
+	To      uint64               // last block covered
+
+	Incoming []*Output     // outputs paid to the wallet, with key images if exported
+	Outgoing []*AuditSpend // spends of the wallet's outputs
+
+	Signature []byte // signature of the report hash with the view key
+}
+
+// Audit builds a signed report of the payments of the wallet with the given
+// view key and spend public key in blocks from to to, using the verified key
+// images of exports to find its spends. The spend private key of keys, if any,
+// is not used.
+func (s *Scanner) Audit(ctx context.Context, keys *ring.WalletKeys, exports []*KeyImageExport, from, to uint64) (*AuditReport, error) {
+	if from > to || to-from >= maxAuditRange {
+		return nil, errAuditRange
+	}
+	// Index the key images whose proofs hold by image and by output
+	var (
+		images  = make(map[string]*ecdsa.PublicKey)
+		outputs = make(map[string]*ecdsa.PublicKey)
+	)
+	for i, exp := range exports {
+		if !ring.VerifyTriptychKeyImage(exp.Key, exp.Image, exp.Proof) {
+			return nil, fmt.Errorf("invalid key image proof %d", i)
+		}
+		images[string(imageBytes(exp.Image))] = exp.Image
+		outputs[string(crypto.CompressPubkey(exp.Key))] = exp.Image
+	}
+	var (
+		w      = &wallet{keys: keys.WatchOnlyKeys()}
+		report = &AuditReport{Address: keys.Address(), From: from, To: to}
+	)
+	for number := from; number <= to; number++ {
+		header, err := s.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		receipts, err := s.backend.GetReceipts(ctx, header.Hash())
+		if err != nil {
+			return nil, err
+		}
+		owned := make(map[string]bool)
+		for _, out := range w.match(header, poolOutputs(receipts)) {
+			out.Image = outputs[string(crypto.CompressPubkey(out.Key))]
+			owned[string(crypto.CompressPubkey(out.Key))] = true
+			report.Incoming = append(report.Incoming, out)
+		}
+		if len(images) == 0 {
+			continue
+		}
+		block, err := s.backend.GetBlock(ctx, header.Hash())
+		if err != nil {
+			return nil, err
+		}
+		if block == nil || len(block.Transactions()) != len(receipts) {
+			return nil, fmt.Errorf("block #%d body not found", number)
+		}
+		for i, tx := range block.Transactions() {
+			if spend := auditSpend(tx, receipts[i], images, owned); spend != nil {
+				spend.BlockNumber, spend.BlockHash = number, header.Hash()
+				report.Outgoing = append(report.Outgoing, spend)
+			}
+		}
+	}
+	sig, err := crypto.Sign(report.Hash().Bytes(), keys.View)
+	if err != nil {
+		return nil, err
+	}
+	report.Signature = sig
+	return report, nil
+}
+
+// auditSpend returns the spend made by a transaction if it consumed one of the
+// given key images, nil otherwise. Outputs with owned keys are change.
+func auditSpend(tx *types.Transaction, receipt *types.Receipt, images map[string]*ecdsa.PublicKey, owned map[string]bool) *AuditSpend {
+	if to := tx.To(); to == nil || *to != vm.ShieldedPoolAddress || receipt.Status != types.ReceiptStatusSuccessful {
+		return nil
+	}
+	data := tx.Data()
+	if len(data) == 0 || data[0] != vm.ShieldedSpendOp {
+		return nil
+	}
+	var sp vm.ShieldedSpend
+	if err := rlp.DecodeBytes(data[1:], &sp); err != nil || sp.Tx == nil {
+		return nil
+	}
+	spend := &AuditSpend{TxHash: tx.Hash(), Fee: sp.Tx.Fee}
+	for _, in := range sp.Tx.Inputs {
+		if in.I == nil {
+			return nil
+		}
+		if image := images[string(imageBytes(in.I))]; image != nil {
+			spend.KeyImages = append(spend.KeyImages, image)
+		}
+	}
+	if len(spend.KeyImages) == 0 {
+		return nil
+	}
+	for i, key := range sp.Tx.Outputs {
+		if !owned[string(crypto.CompressPubkey(key))] {
+			spend.Commitments = append(spend.Commitments, sp.Tx.Commitments[i])
+		}
+	}
+	return spend
+}
+
+// Hash returns the hash of the report signed with the view key, the Keccak256
+// hash of the RLP encoding of its contents with compressed points.
+func (r *AuditReport) Hash() common.Hash {
+	compress := func(p *ecdsa.PublicKey) []byte {
+		if p == nil {
+			return nil
+		}
+		return crypto.CompressPubkey(p)
+	}
+	incoming := make([]interface{}, len(r.Incoming))
+	for i, out := range r.Incoming {
+		incoming[i] = []interface{}{
+			out.BlockNumber, out.BlockHash, out.TxHash, out.Leaf, out.Index,
+			compress(out.Key), compress(out.Commitment), compress(out.TxPub),
+			out.Opened, out.Amount, out.PaymentID, compress(out.Image),
+		}
+	}
+	outgoing := make([]interface{}, len(r.Outgoing))
+	for i, spend := range r.Outgoing {
+		images := make([][]byte, len(spend.KeyImages))
+		for j, image := range spend.KeyImages {
+			images[j] = compress(image)
+		}
+		commitments := make([][]byte, len(spend.Commitments))
+		for j, commitment := range spend.Commitments {
+			commitments[j] = compress(commitment)
+		}
+		outgoing[i] = []interface{}{spend.BlockNumber, spend.BlockHash, spend.TxHash, images, commitments, spend.Fee}
+	}
+	enc, _ := rlp.EncodeToBytes([]interface{}{
+		compress(r.Address.View), compress(r.Address.Spend), r.From, r.To, incoming, outgoing,
+	})
+	return crypto.Keccak256Hash(enc)
+}
+
+// Verify checks that the report was signed with the view key of its wallet.
+// It doesn't check the report against the chain.
+func (r *AuditReport) Verify() error {
+	if len(r.Signature) != 65 {
+		return errAuditSignature
+	}
+	pub, err := crypto.SigToPub(r.Hash().Bytes(), r.Signature)
+	if err != nil {
+		return errAuditSignature
+	}
+	view := r.Address.View
+	if pub.X.Cmp(view.X) != 0 || pub.Y.Cmp(view.Y) != 0 {
+		return errAuditSignature
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringscan
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// spendTx creates the transaction of a pool spend and its receipt announcing
+// its outputs from leaf first on.
+func spendTx(t *testing.T, spend *vm.ShieldedSpend, first uint64) (*types.Transaction, *types.Receipt) {
+	data, err := rlp.EncodeToBytes(spend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTransaction(0, vm.ShieldedPoolAddress, new(big.Int), 0, new(big.Int), append([]byte{vm.ShieldedSpendOp}, data...))
+
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful}
+	for i := range spend.Tx.Outputs {
+		data, err := rlp.EncodeToBytes(&vm.ShieldedOutput{
+			Key:        crypto.CompressPubkey(spend.Tx.Outputs[i]),
+			Commitment: crypto.CompressPubkey(spend.Tx.Commitments[i]),
+			TxPub:      spend.TxPub,
+			Note:       spend.Notes[i],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		receipt.Logs = append(receipt.Logs, &types.Log{
+			Address: vm.ShieldedPoolAddress,
+			Topics:  []common.Hash{vm.ShieldedOutputTopic, common.BigToHash(new(big.Int).SetUint64(first + uint64(i)))},
+			Data:    data,
+			TxHash:  tx.Hash(),
+		})
+	}
+	return tx, receipt
+}
+
+func TestAuditReport(t *testing.T) {
+	var (
+		backend  = newTestBackend(t)
+		ctx      = context.Background()
+		mine, _  = ring.GenerateWalletKeys(crypto.S256())
+		other, _ = ring.GenerateWalletKeys(crypto.S256())
+		txKey, _ = crypto.GenerateKey()
+	)
+	// Block 1 pays an output to mine, block 2 spends it paying other
+	backend.setBlock(0)
+	backend.setBlock(1, poolLog(t, mine.Address(), txKey, 0, 10, 50))
+
+	s := New(backend)
+	id := s.Register(mine, 1)
+	if err := s.scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	outputs, _ := s.Outputs(id)
+	if len(outputs) != 1 {
+		t.Fatalf("outputs found: have %d, want 1", len(outputs))
+	}
+	var decoys []Decoy
+	for leaf := uint64(0); leaf < 10; leaf++ {
+		key, _ := crypto.GenerateKey()
+		commitment, _ := crypto.GenerateKey()
+		decoys = append(decoys, Decoy{Leaf: leaf, Key: &key.PublicKey, Commitment: &commitment.PublicKey})
+	}
+	spend, err := BuildSpend(mine, outputs, [][]Decoy{decoys}, []Payment{{To: other.Address(), Amount: 30}}, 2, common.Address{}, SpendPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, receipt := spendTx(t, spend, 11)
+	backend.setTx(2, tx, receipt)
+
+	exports, err := s.ExportKeyImages(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExportKeyImages(mine.WatchOnlyKeys(), outputs); err == nil {
+		t.Error("key images exported without the spend key")
+	}
+	// The auditor only gets the view key and the exported key images
+	report, err := s.Audit(ctx, mine.WatchOnlyKeys(), exports, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Incoming) != 2 {
+		t.Fatalf("incoming payments: have %d, want 2", len(report.Incoming))
+	}
+	if in := report.Incoming[0]; in.Amount != 50 || in.Image == nil {
+		t.Errorf("payment: have amount %d image %v, want 50 with image", in.Amount, in.Image)
+	}
+	if change := report.Incoming[1]; change.Amount != 18 || change.TxHash != tx.Hash() || change.Image != nil {
+		t.Errorf("change: have amount %d tx %x image %v, want 18 %x without image", change.Amount, change.TxHash, change.Image, tx.Hash())
+	}
+	if len(report.Outgoing) != 1 {
+		t.Fatalf("outgoing payments: have %d, want 1", len(report.Outgoing))
+	}
+	out := report.Outgoing[0]
+	if out.TxHash != tx.Hash() || out.BlockNumber != 2 || out.Fee != 2 || len(out.KeyImages) != 1 || len(out.Commitments) != 1 {
+		t.Fatalf("spend mismatch: %+v", out)
+	}
+	if out.Commitments[0].X.Cmp(spend.Tx.Commitments[0].X) != 0 {
+		t.Error("counterpart commitment mismatch")
+	}
+	if err := report.Verify(); err != nil {
+		t.Fatalf("report signature rejected: %v", err)
+	}
+	report.Incoming[0].Amount++
+	if err := report.Verify(); err != errAuditSignature {
+		t.Errorf("tampered report: have %v, want %v", err, errAuditSignature)
+	}
+	// Without key images spends are unknown, and forged ones are rejected
+	report, err = s.Audit(ctx, mine, nil, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Outgoing) != 0 || report.Incoming[0].Image != nil {
+		t.Error("spends found without key images")
+	}
+	exports[0].Key = report.Incoming[1].Key
+	if _, err := s.Audit(ctx, mine, exports, 1, 2); err == nil {
+		t.Error("key image with invalid proof accepted")
+	}
+	if _, err := s.Audit(ctx, mine, nil, 2, 1); err != errAuditRange {
+		t.Errorf("inverted range: have %v, want %v", err, errAuditRange)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringscan
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+const (
+	DefaultMinRingSize = 11 // Smallest ring an input may hide in
+	DefaultMaxInputs   = 16 // Largest number of inputs of a spend
+)
+
+var (
+	errInsufficientFunds = errors.New("insufficient funds")
+	errTooManyInputs     = errors.New("amount needs too many inputs")
+	errAmountOverflow    = errors.New("amount overflows")
+	errNoPayments        = errors.New("spend has no payments")
+)
+
+// SpendPolicy constrains the spends built by a wallet. Zero fields select
+// the defaults.
+type SpendPolicy struct {
+	MinRingSize int // smallest ring of every input, including the spent output
+	MaxInputs   int // largest number of inputs
+}
+
+func (p SpendPolicy) minRingSize() int {
+	if p.MinRingSize == 0 {
+		return DefaultMinRingSize
+	}
+	return p.MinRingSize
+}
+
+func (p SpendPolicy) maxInputs() int {
+	if p.MaxInputs == 0 {
+		return DefaultMaxInputs
+	}
+	return p.MaxInputs
+}
+
+// Payment is a destination of a spend.
+type Payment struct {
+	To        *ring.StealthAddress
+	Amount    uint64
+	PaymentID [8]byte
+}
+
+// Decoy is a pool output an input hides among.
+type Decoy struct {
+	Leaf       uint64
+	Key        *ecdsa.PublicKey
+	Commitment *ecdsa.PublicKey
+}
+
+// SelectCoins picks outputs among unspent covering target, and returns them
+// along with the change. Only opened outputs are considered. The selection is
+// deterministic: the smallest single output covering target, else the largest
+// outputs until covered, so that small outputs are consolidated only when
+// needed.
+func SelectCoins(unspent []*Output, target uint64, policy SpendPolicy) ([]*Output, uint64, error) {
+	// Order by amount, then by leaf for a stable choice among equal amounts
+	var coins []*Output
+	for _, out := range unspent {
+		if out.Opened {
+			coins = append(coins, out)
+		}
+	}
+	sort.Slice(coins, func(i, j int) bool {
+		if coins[i].Amount != coins[j].Amount {
+			return coins[i].Amount < coins[j].Amount
+		}
+		return coins[i].Leaf < coins[j].Leaf
+	})
+	// Try the smallest single output covering the target
+	i := sort.Search(len(coins), func(i int) bool { return coins[i].Amount >= target })
+	if i < len(coins) {
+		return []*Output{coins[i]}, coins[i].Amount - target, nil
+	}
+	// Accumulate the largest outputs, which all fall short on their own
+	var (
+		selected []*Output
+		total    uint64
+	)
+	for j := len(coins) - 1; j >= 0 && total < target; j-- {
+		if len(selected) == policy.maxInputs() {
+			return nil, 0, errTooManyInputs
+		}
+		selected = append(selected, coins[j])
+		total += coins[j].Amount
+	}
+	if total < target {
+		return nil, 0, errInsufficientFunds
+	}
+	return selected, total - target, nil
+}
+
+// BuildSpend creates a shielded pool spend of inputs owned by keys, paying
+// payments and fee, with the fee sent to recipient. Every input hides among
+// its decoys, sorted by leaf index together with the spent output so that its
+// position reveals nothing. Change is returned to the wallet's own address in
+// a last output. All outputs get fresh blinding factors and notes opening
+// them to their recipients.
+func BuildSpend(keys *ring.WalletKeys, inputs []*Output, decoys [][]Decoy, payments []Payment, fee uint64, recipient common.Address, policy SpendPolicy) (*vm.ShieldedSpend, error) {
+	if keys.WatchOnly() {
+		return nil, errors.New("watch-only wallet cannot spend")
+	}
+	if len(payments) == 0 {
+		return nil, errNoPayments
+	}
+	if len(inputs) > policy.maxInputs() {
+		return nil, errTooManyInputs
+	}
+	if len(decoys) != len(inputs) {
+		return nil, fmt.Errorf("have decoys for %d inputs, want %d", len(decoys), len(inputs))
+	}
+	// Balance the payments against the inputs
+	payments = append([]Payment{}, payments...)
+
+	var in, out uint64
+	for _, input := range inputs {
+		if !input.Opened {
+			return nil, fmt.Errorf("input %d has no opening", input.Leaf)
+		}
+		if in += input.Amount; in < input.Amount {
+			return nil, errAmountOverflow
+		}
+	}
+	for _, payment := range payments {
+		if payment.To == nil {
+			return nil, errors.New("payment without recipient")
+		}
+		if out += payment.Amount; out < payment.Amount {
+			return nil, errAmountOverflow
+		}
+	}
+	if out += fee; out < fee {
+		return nil, errAmountOverflow
+	}
+	if in < out {
+		return nil, errInsufficientFunds
+	}
+	if change := in - out; change > 0 {
+		payments = append(payments, Payment{To: keys.Address(), Amount: change})
+	}
+	// Hide every input among its decoys
+	ctInputs := make([]*ring.RingCTInput, len(inputs))
+	members := make([][]uint64, len(inputs))
+	for i, input := range inputs {
+		key, err := ring.RecoverStealthKey(keys.View, keys.Spend, input.TxPub, input.Index, input.Key)
+		if err != nil {
+			return nil, err
+		}
+		ctInputs[i], members[i], err = buildInput(input, key, decoys[i], policy.minRingSize())
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %v", input.Leaf, err)
+		}
+	}
+	// Pay every output under one fresh transaction key
+	txKey, err := ecdsa.GenerateKey(crypto.S256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	ctOutputs := make([]*ring.RingCTOutput, len(payments))
+	for i, payment := range payments {
+		ctOutputs[i] = &ring.RingCTOutput{
+			Key:    ring.DeriveStealthKey(payment.To, txKey, uint64(i)),
+			Amount: payment.Amount,
+		}
+	}
+	tx, masks, err := ring.BuildRingCT(ctInputs, ctOutputs, fee)
+	if err != nil {
+		return nil, err
+	}
+	notes := make([][]byte, len(payments))
+	for i, payment := range payments {
+		note := &ring.Note{Amount: payment.Amount, Mask: masks[i], PaymentID: payment.PaymentID}
+		if notes[i], err = ring.EncryptNote(payment.To.View, ctOutputs[i].Key, note); err != nil {
+			return nil, err
+		}
+	}
+	return &vm.ShieldedSpend{
+		Tx:        tx,
+		Members:   members,
+		Recipient: recipient,
+		TxPub:     crypto.CompressPubkey(&txKey.PublicKey),
+		Notes:     notes,
+	}, nil
+}
+
+// buildInput creates the ring of an input from its decoys, checking it meets
+// the minimum ring size, and returns it with the leaf indices of its members.
+func buildInput(input *Output, key *ecdsa.PrivateKey, decoys []Decoy, minRingSize int) (*ring.RingCTInput, []uint64, error) {
+	members := []Decoy{{Leaf: input.Leaf, Key: input.Key, Commitment: input.Commitment}}
+	seen := map[uint64]bool{input.Leaf: true}
+	for _, decoy := range decoys {
+		if seen[decoy.Leaf] {
+			return nil, nil, fmt.Errorf("duplicate ring member %d", decoy.Leaf)
+		}
+		seen[decoy.Leaf] = true
+		members = append(members, decoy)
+	}
+	if len(members) < minRingSize {
+		return nil, nil, fmt.Errorf("ring size %d below minimum %d", len(members), minRingSize)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Leaf < members[j].Leaf })
+
+	ctInput := &ring.RingCTInput{
+		Key:         key,
+		Amount:      input.Amount,
+		Mask:        input.Mask,
+		Ring:        make(ring.Ring, len(members)),
+		Commitments: make(ring.Ring, len(members)),
+	}
+	leaves := make([]uint64, len(members))
+	for i, member := range members {
+		ctInput.Ring[i], ctInput.Commitments[i], leaves[i] = member.Key, member.Commitment, member.Leaf
+		if member.Leaf == input.Leaf {
+			ctInput.Index = i
+		}
+	}
+	return ctInput, leaves, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringscan
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+func TestSelectCoins(t *testing.T) {
+	var unspent []*Output
+	for i, amount := range []uint64{10, 1, 20, 5, 2, 5} {
+		unspent = append(unspent, &Output{Leaf: uint64(i), Amount: amount, Opened: true})
+	}
+	unspent = append(unspent, &Output{Leaf: 6, Amount: 1000})
+
+	tests := []struct {
+		target    uint64
+		maxInputs int
+		leaves    []uint64
+		change    uint64
+		err       error
+	}{
+		{target: 5, leaves: []uint64{3}},
+		{target: 6, leaves: []uint64{0}, change: 4},
+		{target: 20, leaves: []uint64{2}},
+		{target: 25, leaves: []uint64{2, 0}, change: 5},
+		{target: 43, leaves: []uint64{2, 0, 5, 3, 4, 1}},
+		{target: 44, err: errInsufficientFunds},
+		{target: 25, maxInputs: 1, err: errTooManyInputs},
+	}
+	for i, tt := range tests {
+		coins, change, err := SelectCoins(unspent, tt.target, SpendPolicy{MaxInputs: tt.maxInputs})
+		if err != tt.err {
+			t.Errorf("test %d: have error %v, want %v", i, err, tt.err)
+			continue
+		}
+		var leaves []uint64
+		for _, coin := range coins {
+			leaves = append(leaves, coin.Leaf)
+		}
+		if len(leaves) != len(tt.leaves) || change != tt.change {
+			t.Errorf("test %d: have %v change %d, want %v change %d", i, leaves, change, tt.leaves, tt.change)
+			continue
+		}
+		for j := range leaves {
+			if leaves[j] != tt.leaves[j] {
+				t.Errorf("test %d: have %v, want %v", i, leaves, tt.leaves)
+				break
+			}
+		}
+	}
+}
+
+func TestBuildSpend(t *testing.T) {
+	var (
+		curve    = crypto.S256()
+		mine, _  = ring.GenerateWalletKeys(curve)
+		other, _ = ring.GenerateWalletKeys(curve)
+		txKey, _ = crypto.GenerateKey()
+		mask, _  = crypto.GenerateKey()
+	)
+	input := &Output{
+		Leaf:       5,
+		Key:        ring.DeriveStealthKey(mine.Address(), txKey, 0),
+		Commitment: ring.Commit(curve, big.NewInt(50), mask.D),
+		TxPub:      &txKey.PublicKey,
+		Opened:     true,
+		Amount:     50,
+		Mask:       mask.D,
+	}
+	var decoys []Decoy
+	for leaf := uint64(0); leaf < 11; leaf++ {
+		if leaf == input.Leaf {
+			continue
+		}
+		key, _ := crypto.GenerateKey()
+		commitment, _ := crypto.GenerateKey()
+		decoys = append(decoys, Decoy{Leaf: leaf, Key: &key.PublicKey, Commitment: &commitment.PublicKey})
+	}
+	payments := []Payment{{To: other.Address(), Amount: 30, PaymentID: [8]byte{1}}}
+
+	if _, err := BuildSpend(mine, []*Output{input}, [][]Decoy{decoys[:5]}, payments, 2, common.Address{}, SpendPolicy{}); err == nil {
+		t.Error("ring below the minimum size accepted")
+	}
+	if _, err := BuildSpend(mine, []*Output{input}, [][]Decoy{decoys}, []Payment{{To: other.Address(), Amount: 49}}, 2, common.Address{}, SpendPolicy{}); err != errInsufficientFunds {
+		t.Errorf("overspend: have error %v, want %v", err, errInsufficientFunds)
+	}
+	spend, err := BuildSpend(mine, []*Output{input}, [][]Decoy{decoys}, payments, 2, common.Address{1}, SpendPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ring.VerifyRingCT(spend.Tx) {
+		t.Fatal("spend doesn't verify")
+	}
+	for i, leaf := range spend.Members[0] {
+		if leaf != uint64(i) {
+			t.Fatalf("ring members not sorted by leaf: %v", spend.Members[0])
+		}
+	}
+	// The payment and the change must be found by their recipients
+	txPub, err := crypto.DecompressPubkey(spend.TxPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []struct {
+		keys   *ring.WalletKeys
+		amount uint64
+	}{{other, 30}, {mine, 18}} {
+		out := &ring.StealthOutput{Key: spend.Tx.Outputs[i], Commitment: spend.Tx.Commitments[i]}
+		if !ring.IsStealthOutput(want.keys.View, want.keys.Address().Spend, txPub, uint64(i), out.Key) {
+			t.Errorf("output %d not paid to its recipient", i)
+		}
+		note, err := want.keys.ReadNote(out, spend.Notes[i])
+		if err != nil {
+			t.Errorf("output %d: failed to read note: %v", i, err)
+		} else if note.Amount != want.amount {
+			t.Errorf("output %d: have amount %d, want %d", i, note.Amount, want.amount)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringscan
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// Key images tell whether outputs were spent, but deriving them takes the
+// spend key. Owners export them with proofs binding every key image to the
+// one-time key of its output, so that auditors and watch-only wallets, such as
+// a hot wallet mirroring a cold one, can detect spends without being able to
+// spend.
+
+// KeyImageExport discloses the key image of an output with a proof that it
+// belongs to the output's one-time key.
+type KeyImageExport struct {
+	Key   *ecdsa.PublicKey // one-time key of the output
+	Image *ecdsa.PublicKey // Triptych key image of the output
+	Proof *ring.KeyImageProof
+}
+
+// ExportKeyImages returns the key images of the outputs of a wallet with the
+// proofs auditors need. It requires the spend key.
+func ExportKeyImages(keys *ring.WalletKeys, outputs []*Output) ([]*KeyImageExport, error) {
+	if keys.WatchOnly() {
+		return nil, errors.New("key image export requires the spend key")
+	}
+	exports := make([]*KeyImageExport, len(outputs))
+	for i, out := range outputs {
+		key, err := ring.RecoverStealthKey(keys.View, keys.Spend, out.TxPub, out.Index, out.Key)
+		if err != nil {
+			return nil, err
+		}
+		image, proof, err := ring.ProveTriptychKeyImage(key)
+		if err != nil {
+			return nil, err
+		}
+		exports[i] = &KeyImageExport{Key: out.Key, Image: image, Proof: proof}
+	}
+	return exports, nil
+}
+
+// ExportKeyImages returns the key images of the outputs found so far for a
+// registered wallet. Watch-only wallets export the key images imported for
+// their outputs.
+func (s *Scanner) ExportKeyImages(id common.Hash) ([]*KeyImageExport, error) {
+	s.lock.RLock()
+	w := s.wallets[id]
+	if w == nil {
+		s.lock.RUnlock()
+		return nil, errUnknownWallet
+	}
+	outputs := append([]*Output{}, w.outputs...)
+	if !w.keys.WatchOnly() {
+		s.lock.RUnlock()
+		return ExportKeyImages(w.keys, outputs)
+	}
+	defer s.lock.RUnlock()
+
+	var exports []*KeyImageExport
+	for _, out := range outputs {
+		if exp := w.images[string(crypto.CompressPubkey(out.Key))]; exp != nil {
+			exports = append(exports, exp)
+		}
+	}
+	return exports, nil
+}
+
+// ImportKeyImages adds key images exported by the owner of a watch-only wallet,
+// letting it detect which of its outputs were spent. Key images of outputs not
+// found yet are kept for when they are. It returns the number of outputs found
+// so far whose key image was imported.
+func (s *Scanner) ImportKeyImages(id common.Hash, exports []*KeyImageExport) (int, error) {
+	for i, exp := range exports {
+		if !ring.VerifyTriptychKeyImage(exp.Key, exp.Image, exp.Proof) {
+			return 0, fmt.Errorf("invalid key image proof %d", i)
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	w := s.wallets[id]
+	if w == nil {
+		return 0, errUnknownWallet
+	}
+	if !w.keys.WatchOnly() {
+		return 0, errors.New("wallet derives its own key images")
+	}
+	if w.images == nil {
+		w.images = make(map[string]*KeyImageExport)
+	}
+	for _, exp := range exports {
+		w.images[string(crypto.CompressPubkey(exp.Key))] = exp
+	}
+	// Replace rather than modify the outputs, which callers may hold
+	var matched int
+	for i, out := range w.outputs {
+		if exp := w.images[string(crypto.CompressPubkey(out.Key))]; exp != nil {
+			cpy := *out
+			cpy.Image = exp.Image
+			w.outputs[i] = &cpy
+			matched++
+		}
+	}
+	return matched, nil
+}
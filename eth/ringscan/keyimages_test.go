@@ -0,0 +1,83 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+)
+
+// Tests that a watch-only wallet detects spends with the key images exported
+// by the full wallet, including those of outputs it finds later. The wallets
+// share their address, so they are kept by different scanners.
+func TestKeyImageImport(t *testing.T) {
+	var (
+		backend  = newTestBackend(t)
+		ctx      = context.Background()
+		cold, _  = ring.GenerateWalletKeys(crypto.S256())
+		txKey, _ = crypto.GenerateKey()
+	)
+	backend.setBlock(0)
+	backend.setBlock(1, poolLog(t, cold.Address(), txKey, 0, 0, 5), poolLog(t, cold.Address(), txKey, 1, 1, 7))
+
+	coldScanner, hotScanner := New(backend), New(backend)
+	id := coldScanner.Register(cold, 1)
+	if err := coldScanner.scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	exports, err := coldScanner.ExportKeyImages(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exports) != 2 {
+		t.Fatalf("exported key images: have %d, want 2", len(exports))
+	}
+	if _, err := coldScanner.ImportKeyImages(id, exports); err == nil {
+		t.Error("key images imported into a full wallet")
+	}
+	// The hot wallet hasn't found the outputs yet, keeping the key images for later
+	hotScanner.Register(cold.WatchOnlyKeys(), 1)
+	if matched, err := hotScanner.ImportKeyImages(id, exports); err != nil || matched != 0 {
+		t.Fatalf("import: have %d, %v, want 0 matched", matched, err)
+	}
+	vm.MarkShieldedSpent(backend.statedb, imageBytes(exports[0].Image), 1)
+
+	if err := hotScanner.scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	outputs, _ := hotScanner.Outputs(id)
+	if len(outputs) != 2 || outputs[0].Image == nil || outputs[1].Image == nil {
+		t.Fatalf("imported key images not applied to found outputs")
+	}
+	if balance, _ := hotScanner.Balance(ctx, id); balance.Uint64() != 7 {
+		t.Errorf("balance: have %d, want 7", balance)
+	}
+	if again, err := hotScanner.ExportKeyImages(id); err != nil || len(again) != 2 {
+		t.Errorf("re-export: have %d, %v, want 2", len(again), err)
+	}
+	if matched, err := hotScanner.ImportKeyImages(id, exports); err != nil || matched != 2 {
+		t.Errorf("reimport: have %d, %v, want 2 matched", matched, err)
+	}
+	exports[1].Image = exports[0].Image
+	if _, err := hotScanner.ImportKeyImages(id, exports); err == nil {
+		t.Error("key image with invalid proof imported")
+	}
+}
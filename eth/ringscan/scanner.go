@@ -0,0 +1,486 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ringscan implements a service scanning the chain for shielded pool
+// outputs paid to registered stealth wallets.
+package ringscan
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Outputs are found in the logs the shielded pool announces them with. Output
+// keys are derived from the transaction key and the position of the output
+// among the outputs of its transaction with the same transaction key, so the
+// scanner counts the pool logs of every transaction per transaction key.
+// Amounts are read from the output notes. Outputs without a note opening their
+// commitment are still reported, but don't count towards the balance as the
+// wallet can't spend them without the opening.
+//
+// Wallets are only kept in memory, the view keys are never written to disk.
+
+// reorgDepth is the number of blocks rescanned when a wallet's last scanned
+// block is no longer canonical.
+const reorgDepth = 64
+
+var errUnknownWallet = errors.New("unknown stealth wallet")
+
+// Backend provides the chain data the scanner reads, a subset of
+// ethapi.Backend.
+type Backend interface {
+	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
+	GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error)
+	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
+	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// Output is a shielded pool output owned by a wallet.
+type Output struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+
+	Leaf       uint64           // index of the output in the pool tree
+	Index      uint64           // position among the outputs of its transaction key
+	Key        *ecdsa.PublicKey // one-time key
+	Commitment *ecdsa.PublicKey // amount commitment
+	TxPub      *ecdsa.PublicKey // transaction key
+
+	Opened    bool // whether the note opened the commitment, setting Amount and Mask
+	Amount    uint64
+	Mask      *big.Int // blinding factor of the commitment
+	PaymentID [8]byte
+	Image     *ecdsa.PublicKey // Triptych key image, nil in watch-only mode unless imported
+}
+
+// PaymentEvent is posted when an output paid to a wallet is found, or removed
+// by a reorg.
+type PaymentEvent struct {
+	Wallet  common.Hash
+	Output  *Output
+	Removed bool
+}
+
+// wallet is a registered stealth wallet and its scanning progress.
+type wallet struct {
+	id      common.Hash
+	keys    *ring.WalletKeys
+	from    uint64      // first block scanned
+	next    uint64      // next block to scan
+	last    common.Hash // hash of block next-1
+	outputs []*Output
+	images  map[string]*KeyImageExport // imported key images by compressed output key
+}
+
+// poolOutput is an output parsed from a pool log.
+type poolOutput struct {
+	log                    *types.Log
+	key, commitment, txPub *ecdsa.PublicKey
+	index                  uint64
+	note                   []byte
+}
+
+// Scanner scans new blocks for outputs paid to its wallets.
+type Scanner struct {
+	backend Backend
+	wallets map[common.Hash]*wallet
+	lock    sync.RWMutex
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+
+	wake chan struct{}
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a scanner reading the chain from backend.
+func New(backend Backend) *Scanner {
+	return &Scanner{
+		backend: backend,
+		wallets: make(map[common.Hash]*wallet),
+		wake:    make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start starts scanning in the background.
+func (s *Scanner) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop terminates the scanner.
+func (s *Scanner) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+	s.scope.Close()
+}
+
+// SubscribePayments subscribes to the outputs found for all wallets.
+func (s *Scanner) SubscribePayments(ch chan<- PaymentEvent) event.Subscription {
+	return s.scope.Track(s.feed.Subscribe(ch))
+}
+
+// WalletID returns the identifier wallets with the stealth address are
+// registered under.
+func WalletID(addr *ring.StealthAddress) common.Hash {
+	return crypto.Keccak256Hash(ring.Ring{addr.View, addr.Spend}.Compress())
+}
+
+// Register adds a wallet scanned from block from on, replacing any wallet with
+// the same address, and returns its identifier. Watch-only wallets find their
+// outputs but can't tell whether they were spent until their key images are
+// imported.
+func (s *Scanner) Register(keys *ring.WalletKeys, from uint64) common.Hash {
+	id := WalletID(keys.Address())
+
+	s.lock.Lock()
+	s.wallets[id] = &wallet{id: id, keys: keys, from: from, next: from}
+	s.lock.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return id
+}
+
+// Unregister removes a wallet, reporting whether it was registered.
+func (s *Scanner) Unregister(id common.Hash) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.wallets[id]
+	delete(s.wallets, id)
+	return ok
+}
+
+// Status returns the number of the next block to scan for a wallet and
+// whether it is watch-only.
+func (s *Scanner) Status(id common.Hash) (uint64, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	w := s.wallets[id]
+	if w == nil {
+		return 0, false, errUnknownWallet
+	}
+	return w.next, w.keys.WatchOnly(), nil
+}
+
+// Outputs returns the outputs received by a wallet, oldest first.
+func (s *Scanner) Outputs(id common.Hash) ([]*Output, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	w := s.wallets[id]
+	if w == nil {
+		return nil, errUnknownWallet
+	}
+	return append([]*Output{}, w.outputs...), nil
+}
+
+// Unspent returns the spendable outputs of a wallet, whose commitments are
+// opened and key images aren't spent in the latest state. Watch-only wallets
+// can't detect spends of outputs whose key images weren't imported.
+func (s *Scanner) Unspent(ctx context.Context, id common.Hash) ([]*Output, error) {
+	outputs, err := s.Outputs(id)
+	if err != nil {
+		return nil, err
+	}
+	statedb, _, err := s.backend.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	unspent := outputs[:0]
+	for _, out := range outputs {
+		if !out.Opened || (out.Image != nil && vm.ShieldedSpent(statedb, imageBytes(out.Image))) {
+			continue
+		}
+		unspent = append(unspent, out)
+	}
+	return unspent, nil
+}
+
+// Balance returns the total amount of the unspent outputs of a wallet.
+func (s *Scanner) Balance(ctx context.Context, id common.Hash) (*big.Int, error) {
+	unspent, err := s.Unspent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for _, out := range unspent {
+		total.Add(total, new(big.Int).SetUint64(out.Amount))
+	}
+	return total, nil
+}
+
+func (s *Scanner) loop() {
+	defer s.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := s.backend.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		if err := s.scan(context.Background()); err != nil {
+			log.Warn("Stealth output scan failed", "err", err)
+		}
+		select {
+		case <-heads:
+		case <-s.wake:
+		case <-sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// scan brings all wallets up to the current head, one block at a time from the
+// least advanced wallet on.
+func (s *Scanner) scan(ctx context.Context) error {
+	head, err := s.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil || err != nil {
+		return err
+	}
+	if err := s.rewind(ctx); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-s.quit:
+			return nil
+		default:
+		}
+		// Collect the wallets waiting for the lowest block
+		var (
+			number  = head.Number.Uint64() + 1
+			pending []*wallet
+		)
+		s.lock.RLock()
+		for _, w := range s.wallets {
+			switch {
+			case w.next < number:
+				number, pending = w.next, []*wallet{w}
+			case w.next == number:
+				pending = append(pending, w)
+			}
+		}
+		s.lock.RUnlock()
+		if len(pending) == 0 {
+			return nil
+		}
+		header, err := s.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header == nil || err != nil {
+			return err
+		}
+		receipts, err := s.backend.GetReceipts(ctx, header.Hash())
+		if err != nil {
+			return err
+		}
+		outputs := poolOutputs(receipts)
+		found := make([][]*Output, len(pending))
+		for i, w := range pending {
+			found[i] = w.match(header, outputs)
+		}
+		// Record the results unless the wallets changed meanwhile
+		var events []PaymentEvent
+		s.lock.Lock()
+		for i, w := range pending {
+			if w.next != number || s.wallets[w.id] != w {
+				continue
+			}
+			for _, out := range found[i] {
+				if exp := w.images[string(crypto.CompressPubkey(out.Key))]; exp != nil {
+					out.Image = exp.Image
+				}
+			}
+			w.outputs = append(w.outputs, found[i]...)
+			w.next, w.last = number+1, header.Hash()
+			for _, out := range found[i] {
+				events = append(events, PaymentEvent{Wallet: w.id, Output: out})
+			}
+		}
+		s.lock.Unlock()
+		s.post(events)
+	}
+}
+
+// post sends payment events to the subscribers.
+func (s *Scanner) post(events []PaymentEvent) {
+	for _, ev := range events {
+		s.feed.Send(ev)
+	}
+}
+
+// rewind rescans the last reorgDepth blocks of wallets whose last scanned block
+// was reorged out.
+func (s *Scanner) rewind(ctx context.Context) error {
+	s.lock.RLock()
+	scanned := make(map[uint64]bool)
+	for _, w := range s.wallets {
+		if w.next > w.from {
+			scanned[w.next-1] = true
+		}
+	}
+	s.lock.RUnlock()
+
+	canonical := make(map[uint64]common.Hash)
+	for number := range scanned {
+		header, err := s.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return err
+		}
+		if header != nil {
+			canonical[number] = header.Hash()
+		}
+	}
+	var events []PaymentEvent
+	defer func() { s.post(events) }()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, w := range s.wallets {
+		if w.next == w.from || canonical[w.next-1] == w.last {
+			continue
+		}
+		next := w.from
+		if w.next > w.from+reorgDepth {
+			next = w.next - reorgDepth
+		}
+		log.Debug("Rescanning reorged blocks for stealth outputs", "from", next, "to", w.next-1)
+
+		keep := sort.Search(len(w.outputs), func(i int) bool { return w.outputs[i].BlockNumber >= next })
+		for _, out := range w.outputs[keep:] {
+			events = append(events, PaymentEvent{Wallet: w.id, Output: out, Removed: true})
+		}
+		w.outputs = w.outputs[:keep]
+		w.next, w.last = next, common.Hash{}
+		if next > w.from {
+			header, err := s.backend.HeaderByNumber(ctx, rpc.BlockNumber(next-1))
+			if err != nil {
+				return err
+			}
+			if header != nil {
+				w.last = header.Hash()
+			}
+		}
+	}
+	return nil
+}
+
+// match returns the outputs paid to the wallet.
+func (w *wallet) match(header *types.Header, outputs []*poolOutput) []*Output {
+	var (
+		addr  = w.keys.Address()
+		owned []*Output
+	)
+	for _, out := range outputs {
+		if !ring.IsStealthOutput(w.keys.View, addr.Spend, out.txPub, out.index, out.key) {
+			continue
+		}
+		own := &Output{
+			BlockNumber: header.Number.Uint64(),
+			BlockHash:   header.Hash(),
+			TxHash:      out.log.TxHash,
+			Leaf:        out.log.Topics[1].Big().Uint64(),
+			Index:       out.index,
+			Key:         out.key,
+			Commitment:  out.commitment,
+			TxPub:       out.txPub,
+		}
+		if note, err := w.keys.ReadNote(&ring.StealthOutput{Key: out.key, Commitment: out.commitment}, out.note); err == nil {
+			own.Opened, own.Amount, own.Mask, own.PaymentID = true, note.Amount, note.Mask, note.PaymentID
+		}
+		if !w.keys.WatchOnly() {
+			key, err := ring.RecoverStealthKey(w.keys.View, w.keys.Spend, out.txPub, out.index, out.key)
+			if err != nil {
+				continue
+			}
+			own.Image = ring.TriptychKeyImage(key)
+		}
+		owned = append(owned, own)
+	}
+	return owned
+}
+
+// poolOutputs parses the outputs announced by the shielded pool in a block.
+func poolOutputs(receipts types.Receipts) []*poolOutput {
+	var outputs []*poolOutput
+	for _, receipt := range receipts {
+		positions := make(map[string]uint64)
+		for _, l := range receipt.Logs {
+			if l.Address != vm.ShieldedPoolAddress || len(l.Topics) != 2 || l.Topics[0] != vm.ShieldedOutputTopic {
+				continue
+			}
+			var dec vm.ShieldedOutput
+			if err := rlp.DecodeBytes(l.Data, &dec); err != nil {
+				continue
+			}
+			key, commitment, txPub := decompress(dec.Key), decompress(dec.Commitment), decompress(dec.TxPub)
+			if key == nil || commitment == nil || txPub == nil {
+				continue
+			}
+			index := positions[string(dec.TxPub)]
+			positions[string(dec.TxPub)]++
+
+			outputs = append(outputs, &poolOutput{
+				log:        l,
+				key:        key,
+				commitment: commitment,
+				txPub:      txPub,
+				index:      index,
+				note:       dec.Note,
+			})
+		}
+	}
+	return outputs
+}
+
+// decompress decodes a compressed secp256k1 point, nil if invalid.
+func decompress(b []byte) *ecdsa.PublicKey {
+	keys, err := ring.DecompressRing(crypto.S256(), b)
+	if err != nil || len(keys) != 1 {
+		return nil
+	}
+	return keys[0]
+}
+
+// imageBytes encodes a key image as in the key image set.
+func imageBytes(image *ecdsa.PublicKey) []byte {
+	return append(common.LeftPadBytes(image.X.Bytes(), 32), common.LeftPadBytes(image.Y.Bytes(), 32)...)
+}
@@ -0,0 +1,238 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringscan
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testBackend is a canonical chain of headers with their transactions and
+// receipts.
+type testBackend struct {
+	headers  []*types.Header
+	txs      map[common.Hash]types.Transactions
+	receipts map[common.Hash]types.Receipts
+	statedb  *state.StateDB
+	feed     event.Feed
+}
+
+func newTestBackend(t *testing.T) *testBackend {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testBackend{
+		txs:      make(map[common.Hash]types.Transactions),
+		receipts: make(map[common.Hash]types.Receipts),
+		statedb:  statedb,
+	}
+}
+
+// setBlock replaces block number, which is at most the next block, with one
+// holding a single transaction with the given pool logs.
+func (b *testBackend) setBlock(number uint64, logs ...*types.Log) {
+	tx := types.NewTransaction(0, vm.ShieldedPoolAddress, new(big.Int), 0, new(big.Int), nil)
+	b.setTx(number, tx, &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: logs})
+}
+
+// setTx replaces block number, which is at most the next block, with one
+// holding a single transaction with the given receipt.
+func (b *testBackend) setTx(number uint64, tx *types.Transaction, receipt *types.Receipt) {
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Extra: []byte{byte(len(b.receipts))}}
+	if number > 0 {
+		header.ParentHash = b.headers[number-1].Hash()
+	}
+	b.headers = append(b.headers[:number], header)
+	b.txs[header.Hash()] = types.Transactions{tx}
+	b.receipts[header.Hash()] = types.Receipts{receipt}
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.headers[len(b.headers)-1], nil
+	}
+	if int(number) >= len(b.headers) {
+		return nil, nil
+	}
+	return b.headers[number], nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	for _, header := range b.headers {
+		if header.Hash() == hash {
+			return types.NewBlockWithHeader(header).WithBody(b.txs[hash], nil), nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, _ := b.HeaderByNumber(ctx, number)
+	return b.statedb, header, nil
+}
+
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.feed.Subscribe(ch)
+}
+
+// poolLog creates the log of output index paid to addr with the transaction
+// key txKey, with a note unless amount is zero.
+func poolLog(t *testing.T, addr *ring.StealthAddress, txKey *ecdsa.PrivateKey, index, leaf, amount uint64) *types.Log {
+	key := ring.DeriveStealthKey(addr, txKey, index)
+	mask, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &vm.ShieldedOutput{
+		Key:        crypto.CompressPubkey(key),
+		Commitment: crypto.CompressPubkey(ring.Commit(crypto.S256(), new(big.Int).SetUint64(amount), mask.D)),
+		TxPub:      crypto.CompressPubkey(&txKey.PublicKey),
+	}
+	if amount > 0 {
+		if out.Note, err = ring.EncryptNote(addr.View, key, &ring.Note{Amount: amount, Mask: mask.D}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := rlp.EncodeToBytes(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.Log{
+		Address: vm.ShieldedPoolAddress,
+		Topics:  []common.Hash{vm.ShieldedOutputTopic, common.BigToHash(new(big.Int).SetUint64(leaf))},
+		Data:    data,
+	}
+}
+
+func TestScanner(t *testing.T) {
+	var (
+		backend   = newTestBackend(t)
+		ctx       = context.Background()
+		mine, _   = ring.GenerateWalletKeys(crypto.S256())
+		other, _  = ring.GenerateWalletKeys(crypto.S256())
+		txKey, _  = crypto.GenerateKey()
+		depKey, _ = crypto.GenerateKey()
+	)
+	// Block 1 pays two outputs to mine and one to other in one transaction,
+	// block 2 a deposit to mine without a note
+	backend.setBlock(0)
+	backend.setBlock(1,
+		poolLog(t, mine.Address(), txKey, 0, 0, 5),
+		poolLog(t, other.Address(), txKey, 1, 1, 7),
+		poolLog(t, mine.Address(), txKey, 2, 2, 11),
+	)
+	backend.setBlock(2, poolLog(t, mine.Address(), depKey, 0, 3, 0))
+
+	s := New(backend)
+	payments := make(chan PaymentEvent, 16)
+	sub := s.SubscribePayments(payments)
+	defer sub.Unsubscribe()
+
+	id := s.Register(mine, 1)
+	watchID := s.Register(other.WatchOnlyKeys(), 0)
+	if err := s.scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := s.Outputs(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 3 || outputs[0].Amount != 5 || outputs[1].Amount != 11 || outputs[1].Index != 2 || outputs[1].Leaf != 2 {
+		t.Fatalf("wrong outputs found: %d", len(outputs))
+	}
+	if !outputs[1].Opened || outputs[2].Opened {
+		t.Errorf("opened: have %v %v, want true false", outputs[1].Opened, outputs[2].Opened)
+	}
+	if n := countPayments(payments, id, false); n != 3 {
+		t.Errorf("payments posted: have %d, want 3", n)
+	}
+	if next, watchOnly, _ := s.Status(id); next != 3 || watchOnly {
+		t.Errorf("status: have next %d watch-only %v, want 3 false", next, watchOnly)
+	}
+	// Spending an output removes it from the balance of the full wallet only
+	key, err := ring.RecoverStealthKey(mine.View, mine.Spend, outputs[0].TxPub, outputs[0].Index, outputs[0].Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.MarkShieldedSpent(backend.statedb, imageBytes(ring.TriptychKeyImage(key)), 2)
+	if balance, _ := s.Balance(ctx, id); balance.Uint64() != 11 {
+		t.Errorf("balance: have %d, want 11", balance)
+	}
+	if balance, _ := s.Balance(ctx, watchID); balance.Uint64() != 7 {
+		t.Errorf("watch-only balance: have %d, want 7", balance)
+	}
+	// A reorg dropping the outputs of mine is rescanned
+	backend.setBlock(1, poolLog(t, other.Address(), txKey, 0, 0, 3))
+	backend.setBlock(2)
+	backend.setBlock(3)
+	if err := s.scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if outputs, _ := s.Outputs(id); len(outputs) != 0 {
+		t.Errorf("reorged outputs kept: %d", len(outputs))
+	}
+	if n := countPayments(payments, id, true); n != 3 {
+		t.Errorf("removals posted: have %d, want 3", n)
+	}
+	if balance, _ := s.Balance(ctx, watchID); balance.Uint64() != 3 {
+		t.Errorf("watch-only balance after reorg: have %d, want 3", balance)
+	}
+	if next, _, _ := s.Status(watchID); next != 4 {
+		t.Errorf("next block after reorg: have %d, want 4", next)
+	}
+	if !s.Unregister(id) || s.Unregister(id) {
+		t.Error("wallet not unregistered once")
+	}
+	if _, err := s.Outputs(id); err != errUnknownWallet {
+		t.Errorf("outputs of unregistered wallet: have error %v, want %v", err, errUnknownWallet)
+	}
+}
+
+// countPayments drains the pending events and counts those of the wallet with
+// the given removal flag.
+func countPayments(payments chan PaymentEvent, id common.Hash, removed bool) int {
+	var n int
+	for {
+		select {
+		case ev := <-payments:
+			if ev.Wallet == id && ev.Removed == removed {
+				n++
+			}
+		default:
+			return n
+		}
+	}
+}
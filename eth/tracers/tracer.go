@@ -281,8 +281,9 @@ type Tracer struct {
 
 	vm *duktape.Context // Javascript VM instance
 
-	tracerObject int // Stack index of the tracer JavaScript object
-	stateObject  int // Stack index of the global state to pull arguments from
+	tracerObject int  // Stack index of the tracer JavaScript object
+	stateObject  int  // Stack index of the global state to pull arguments from
+	precompile   bool // Whether the tracer exposes the optional precompile function
 
 	opWrapper       *opWrapper       // Wrapper around the VM opcode
 	stackWrapper    *stackWrapper    // Wrapper around the VM stack
@@ -305,7 +306,8 @@ type Tracer struct {
 
 // New instantiates a new tracer instance. code specifies a Javascript snippet,
 // which must evaluate to an expression returning an object with 'step', 'fault'
-// and 'result' functions.
+// and 'result' functions, and optionally a 'precompile' function called with
+// the calls to precompiled contracts.
 func New(code string) (*Tracer, error) {
 	// Resolve any tracers by name and assemble the tracer object
 	if tracer, ok := tracer(code); ok {
@@ -366,7 +368,11 @@ func New(code string) (*Tracer, error) {
 		return 1
 	})
 	tracer.vm.PushGlobalGoFunction("isPrecompiled", func(ctx *duktape.Context) int {
-		_, ok := vm.PrecompiledContractsByzantium[common.BytesToAddress(popSlice(ctx))]
+		addr := common.BytesToAddress(popSlice(ctx))
+		_, ok := vm.PrecompiledContractsByzantium[addr]
+		if !ok {
+			_, ok = vm.PrecompiledContractsRing[addr]
+		}
 		ctx.PushBoolean(ok)
 		return 1
 	})
@@ -409,6 +415,9 @@ func New(code string) (*Tracer, error) {
 	}
 	tracer.vm.Pop()
 
+	tracer.precompile = tracer.vm.GetPropString(tracer.tracerObject, "precompile")
+	tracer.vm.Pop()
+
 	// Tracer is valid, inject the big int library to access large numbers
 	tracer.vm.EvalString(bigIntegerJS)
 	tracer.vm.PutGlobalString("bigInt")
@@ -557,6 +566,62 @@ func (jst *Tracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost
 	return nil
 }
 
+// CapturePrecompile implements the vm.PrecompileTracer interface, calling the
+// tracer's precompile function with the call, if exposed. Calls to the ring
+// signature verification precompile carry the decoded ring size and result.
+func (jst *Tracer) CapturePrecompile(env *vm.EVM, addr common.Address, input []byte, gas, cost uint64, output []byte, depth int, err error) error {
+	if jst.err != nil || !jst.precompile {
+		return nil
+	}
+	// If tracing was interrupted, set the error and stop
+	if atomic.LoadUint32(&jst.interrupt) > 0 {
+		jst.err = jst.reason
+		return nil
+	}
+	jst.dbWrapper.db = env.StateDB
+
+	obj := jst.vm.PushObject()
+
+	copy(makeSlice(jst.vm.PushFixedBuffer(20), 20), addr[:])
+	jst.vm.PutPropString(obj, "address")
+
+	copy(makeSlice(jst.vm.PushFixedBuffer(len(input)), uint(len(input))), input)
+	jst.vm.PutPropString(obj, "input")
+
+	copy(makeSlice(jst.vm.PushFixedBuffer(len(output)), uint(len(output))), output)
+	jst.vm.PutPropString(obj, "output")
+
+	jst.vm.PushUint(uint(gas))
+	jst.vm.PutPropString(obj, "gas")
+
+	jst.vm.PushUint(uint(cost))
+	jst.vm.PutPropString(obj, "gasCost")
+
+	jst.vm.PushInt(depth)
+	jst.vm.PutPropString(obj, "depth")
+
+	if err != nil {
+		jst.vm.PushString(err.Error())
+		jst.vm.PutPropString(obj, "error")
+	}
+	if addr == vm.RingVerifyAddress {
+		trace := vm.NewRingVerifyLog(input, gas, cost, output, depth, err)
+
+		ring := jst.vm.PushObject()
+		jst.vm.PushInt(trace.RingSize)
+		jst.vm.PutPropString(ring, "size")
+		jst.vm.PushBoolean(trace.Valid)
+		jst.vm.PutPropString(ring, "valid")
+		jst.vm.PutPropString(obj, "ring")
+	}
+	jst.vm.PutPropString(jst.stateObject, "call")
+
+	if _, err := jst.call("precompile", "call", "db"); err != nil {
+		jst.err = wrapError("precompile", err)
+	}
+	return nil
+}
+
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (jst *Tracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	jst.ctx["output"] = output
@@ -26,6 +26,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -138,3 +140,29 @@ func TestHaltBetweenSteps(t *testing.T) {
 		t.Errorf("Expected timeout error, got %v", err)
 	}
 }
+
+func TestPrecompileTracing(t *testing.T) {
+	tracer, err := New("{calls: [], step: function() {}, fault: function() {}, precompile: function(call) { this.calls.push([toHex(call.address), call.gasCost, call.ring.size, call.ring.valid]); }, result: function() { return this.calls; }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	sig, err := ring.Sign([32]byte{1}, ring.GenNewKeyRing(2, key, 0), key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := ring.PrecompileInput(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := vm.NewEVM(vm.Context{BlockNumber: big.NewInt(1)}, nil, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	tracer.CapturePrecompile(env, vm.RingVerifyAddress, input, 100000, 60000, []byte{1}, 2, nil)
+
+	ret, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[["0x0000000000000000000000000000000000000009",60000,2,true]]`; string(ret) != want {
+		t.Errorf("Expected return value to be %s, got %s", want, string(ret))
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
@@ -607,6 +608,65 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address common.A
 	return res[:], state.Error()
 }
 
+// KeyImageProofResult is the Merkle proof of whether a key image was spent as
+// of a block, see light.VerifyKeyImageProof.
+type KeyImageProofResult struct {
+	KeyImage     hexutil.Bytes   `json:"keyImage"`
+	Spent        bool            `json:"spent"`
+	SpentAt      *hexutil.Uint64 `json:"spentAt"`
+	BlockHash    common.Hash     `json:"blockHash"`
+	BlockNumber  hexutil.Uint64  `json:"blockNumber"`
+	StateRoot    common.Hash     `json:"stateRoot"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageProof []hexutil.Bytes `json:"storageProof"`
+}
+
+// GetKeyImageProof returns whether the key image, given as the 64 byte
+// concatenation of its coordinates, was spent as of the given block, with the
+// proof of the answer against the state root of the block. The pending block
+// has no committed state and cannot be proven.
+func (s *PublicBlockChainAPI) GetKeyImageProof(ctx context.Context, image hexutil.Bytes, blockNr rpc.BlockNumber) (*KeyImageProofResult, error) {
+	if len(image) != 64 {
+		return nil, fmt.Errorf("invalid key image length %d, want 64", len(image))
+	}
+	if blockNr == rpc.PendingBlockNumber {
+		return nil, errors.New("key image proofs are not available for the pending block")
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	proof, err := light.ProveKeyImage(state, image)
+	if err != nil {
+		return nil, err
+	}
+	number, spent, err := light.VerifyKeyImageProof(header.Root, image, proof)
+	if err != nil {
+		return nil, err
+	}
+	res := &KeyImageProofResult{
+		KeyImage:     image,
+		Spent:        spent,
+		BlockHash:    header.Hash(),
+		BlockNumber:  hexutil.Uint64(header.Number.Uint64()),
+		StateRoot:    header.Root,
+		AccountProof: toHexSlice(proof.AccountProof),
+		StorageProof: toHexSlice(proof.StorageProof),
+	}
+	if spent {
+		res.SpentAt = (*hexutil.Uint64)(&number)
+	}
+	return res, nil
+}
+
+func toHexSlice(nodes light.NodeList) []hexutil.Bytes {
+	res := make([]hexutil.Bytes, len(nodes))
+	for i, node := range nodes {
+		res[i] = hexutil.Bytes(node)
+	}
+	return res
+}
+
 // CallArgs represents the arguments for a call.
 type CallArgs struct {
 	From     common.Address  `json:"from"`
@@ -743,6 +803,41 @@ type ExecutionResult struct {
 	Failed      bool           `json:"failed"`
 	ReturnValue string         `json:"returnValue"`
 	StructLogs  []StructLogRes `json:"structLogs"`
+
+	RingVerifications []RingVerifyLogRes `json:"ringVerifications,omitempty"`
+}
+
+// RingVerifyLogRes stores a call to the ring signature verification precompile
+// traced while replaying a transaction in debug mode.
+type RingVerifyLogRes struct {
+	Depth    int    `json:"depth"`
+	RingSize int    `json:"ringSize"`
+	Gas      uint64 `json:"gas"`
+	GasCost  uint64 `json:"gasCost"`
+	Valid    bool   `json:"valid"`
+	Error    string `json:"error,omitempty"`
+}
+
+// FormatRingVerifyLogs formats traced ring signature verifications for json
+// output.
+func FormatRingVerifyLogs(logs []vm.RingVerifyLog) []RingVerifyLogRes {
+	if len(logs) == 0 {
+		return nil
+	}
+	formatted := make([]RingVerifyLogRes, len(logs))
+	for i, trace := range logs {
+		formatted[i] = RingVerifyLogRes{
+			Depth:    trace.Depth,
+			RingSize: trace.RingSize,
+			Gas:      trace.Gas,
+			GasCost:  trace.GasCost,
+			Valid:    trace.Valid,
+		}
+		if trace.Err != nil {
+			formatted[i].Error = trace.Err.Error()
+		}
+	}
+	return formatted
 }
 
 // StructLogRes stores a structured log emitted by the EVM while replaying a
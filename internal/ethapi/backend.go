@@ -66,6 +66,7 @@ type Backend interface {
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	KeyImageSpent(ctx context.Context, image []byte) (bool, error)
 
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
@@ -116,3 +117,16 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 		},
 	}
 }
+
+// GetRingAPIs returns the ring signature APIs, which are only offered when
+// enabled with --rpc.ring.
+func GetRingAPIs(apiBackend Backend) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "ring",
+			Version:   "1.0",
+			Service:   NewPublicRingAPI(apiBackend),
+			Public:    true,
+		},
+	}
+}
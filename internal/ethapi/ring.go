@@ -0,0 +1,504 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/crypto/ring/chainkeys"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	defaultRingSize   = 11                    // Ring size of ring transactions without explicit members
+	defaultRingBlocks = 256                   // Number of recent blocks searched for ring members
+	maxRingBlocks     = 4 * defaultRingBlocks // Most recent blocks searched for ring members on request
+	maxSpendIO        = 16                    // Largest number of inputs or outputs of estimated spends
+)
+
+var errInvalidRingSig = errors.New("invalid ring signature")
+
+// PublicRingAPI provides linkable ring signatures made with the accounts of the
+// node. Signatures are exchanged in their RLP encoding, like the ringSignature
+// field of ring transactions, key images as the 64 byte concatenation of their
+// coordinates and ring members as compressed or uncompressed public keys.
+type PublicRingAPI struct {
+	b Backend
+}
+
+// NewPublicRingAPI creates a new ring signature API.
+func NewPublicRingAPI(b Backend) *PublicRingAPI {
+	return &PublicRingAPI{b}
+}
+
+// Sign ring signs the message with the given account, hiding it among the
+// ring members. Keystore accounts must be unlocked, hardware wallets ask the
+// user for confirmation. The key of the account is inserted into the ring at
+// a random position unless it is already a member.
+//
+// The signed hash is keccak256("\x19Ethereum Signed Message:\n"${message length}${message}),
+// as for eth_sign, so that transactions can't be signed through this method.
+func (s *PublicRingAPI) Sign(message hexutil.Bytes, members []hexutil.Bytes, account common.Address) (hexutil.Bytes, error) {
+	// Look up the wallet containing the requested signer
+	acc := accounts.Account{Address: account}
+	wallet, err := s.b.AccountManager().Find(acc)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseRingMembers(members)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := wallet.SignRing(acc, common.BytesToHash(signHash(message)), keys)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := rlp.EncodeToBytes(sig)
+	if err != nil {
+		return nil, err
+	}
+	return enc, nil
+}
+
+// Verify reports whether the signature is a valid ring signature over its
+// ring and message.
+func (s *PublicRingAPI) Verify(sig hexutil.Bytes) (bool, error) {
+	dec, err := ring.DecodeStrict(ring.EncodingRLP, sig)
+	if err != nil {
+		return false, err
+	}
+	return ring.Verify(dec), nil
+}
+
+// KeyImage returns the key image of the given unlocked account, shared by all
+// its ring signatures and ring transactions.
+func (s *PublicRingAPI) KeyImage(account common.Address) (hexutil.Bytes, error) {
+	ks := fetchKeystore(s.b.AccountManager())
+	if !ks.HasAddress(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	image, err := ks.KeyImage(accounts.Account{Address: account})
+	if err != nil {
+		return nil, err
+	}
+	return append(common.LeftPadBytes(image.X.Bytes(), 32), common.LeftPadBytes(image.Y.Bytes(), 32)...), nil
+}
+
+// Link reports whether two ring signatures were made with the same key. Both
+// signatures must be valid.
+func (s *PublicRingAPI) Link(sig1, sig2 hexutil.Bytes) (bool, error) {
+	a, err := ring.DecodeStrict(ring.EncodingRLP, sig1)
+	if err != nil {
+		return false, err
+	}
+	b, err := ring.DecodeStrict(ring.EncodingRLP, sig2)
+	if err != nil {
+		return false, err
+	}
+	if !ring.Verify(a) || !ring.Verify(b) {
+		return false, errInvalidRingSig
+	}
+	return ring.Link(a, b), nil
+}
+
+// KeyImageSpent reports whether a key image, given as the 64 byte concatenation
+// of its coordinates, is spent in the current head state.
+func (s *PublicRingAPI) KeyImageSpent(ctx context.Context, image hexutil.Bytes) (bool, error) {
+	if len(image) != 64 {
+		return false, fmt.Errorf("invalid key image length %d", len(image))
+	}
+	return s.b.KeyImageSpent(ctx, image)
+}
+
+// Statuses of key images returned by ring_getKeyImageStatus.
+const (
+	KeyImageUnseen  = "unseen"
+	KeyImagePending = "pending"
+	KeyImageSpent   = "spent"
+)
+
+// KeyImageStatus tells whether a key image is spent.
+type KeyImageStatus struct {
+	Status      string          `json:"status"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber"`     // block the key image was spent in, if spent
+	TxHash      *common.Hash    `json:"transactionHash"` // pending transaction spending it, if pending
+}
+
+// GetKeyImageStatus returns whether a key image, given as the 64 byte
+// concatenation of its coordinates, was spent in the current head state or by
+// a pending ring transaction or shielded pool spend. Wallets use it to tell
+// which of their outputs were spent without revealing the spend key.
+func (s *PublicRingAPI) GetKeyImageStatus(ctx context.Context, image hexutil.Bytes) (*KeyImageStatus, error) {
+	if len(image) != 64 {
+		return nil, fmt.Errorf("invalid key image length %d", len(image))
+	}
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	number, spent := vm.KeyImageSpentAt(state, image)
+	if !spent {
+		number, spent = vm.ShieldedSpentAt(state, image)
+	}
+	if err := state.Error(); err != nil {
+		return nil, err
+	}
+	if spent {
+		return &KeyImageStatus{Status: KeyImageSpent, BlockNumber: (*hexutil.Uint64)(&number)}, nil
+	}
+	pending, err := s.b.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range pending {
+		var images [][]byte
+		if to := tx.To(); to != nil && *to == vm.ShieldedPoolAddress {
+			images = vm.ShieldedSpendKeyImages(tx.Data())
+		}
+		if ki := tx.KeyImage(); ki != nil {
+			images = append(images, ki)
+		}
+		for _, other := range images {
+			if bytes.Equal(other, image) {
+				hash := tx.Hash()
+				return &KeyImageStatus{Status: KeyImagePending, TxHash: &hash}, nil
+			}
+		}
+	}
+	return &KeyImageStatus{Status: KeyImageUnseen}, nil
+}
+
+// DecoyPolicy selects the outputs ring_getDecoyCandidates picks decoys from.
+type DecoyPolicy struct {
+	Blocks *hexutil.Uint64 `json:"blocks"` // number of recent blocks sampled, defaultRingBlocks if unset, up to maxRingBlocks
+}
+
+// GetDecoyCandidates returns count distinct compressed public keys of recent
+// transaction senders, sampled like the decoys of eth_sendRingTransaction, for
+// wallets building rings without an index of their own. The node doesn't learn
+// the signer: wallets should ask for one more candidate than they need and
+// drop their own key if returned.
+func (s *PublicRingAPI) GetDecoyCandidates(ctx context.Context, count hexutil.Uint64, policy *DecoyPolicy) ([]hexutil.Bytes, error) {
+	if count == 0 || count > hexutil.Uint64(ring.DefaultDecodeLimits.MaxRingSize) {
+		return nil, fmt.Errorf("invalid decoy count %d", count)
+	}
+	var limit *hexutil.Uint64
+	if policy != nil {
+		limit = policy.Blocks
+	}
+	blocks, err := ringBlocks(limit)
+	if err != nil {
+		return nil, err
+	}
+	decoys, err := chainkeys.NewDecoySelector(chainReader{s.b}, blocks).Select(ctx, int(count))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]hexutil.Bytes, len(decoys))
+	for i, decoy := range decoys {
+		keys[i] = crypto.CompressPubkey(decoy)
+	}
+	return keys, nil
+}
+
+// RingFeeEstimate is the cost of a shielded pool spend.
+type RingFeeEstimate struct {
+	Size     hexutil.Uint64 `json:"size"` // bytes of call data
+	Gas      hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big   `json:"gasPrice"`
+	Fee      *hexutil.Big   `json:"fee"` // gas times gas price
+}
+
+// EstimateFee returns an upper bound of the cost of a shielded pool spend of
+// numInputs inputs hidden in rings of ringSize members, paying numOutputs
+// outputs with notes, at the suggested gas price. The size of a spend is
+// dominated by its rings and range proof, so it is known before building
+// the proofs.
+func (s *PublicRingAPI) EstimateFee(ctx context.Context, ringSize, numInputs, numOutputs hexutil.Uint64) (*RingFeeEstimate, error) {
+	if ringSize < 2 || ringSize > hexutil.Uint64(ring.DefaultDecodeLimits.MaxRingSize) {
+		return nil, fmt.Errorf("invalid ring size %d", ringSize)
+	}
+	if numInputs == 0 || numInputs > maxSpendIO {
+		return nil, fmt.Errorf("invalid input count %d", numInputs)
+	}
+	if numOutputs == 0 || numOutputs > maxSpendIO {
+		return nil, fmt.Errorf("invalid output count %d", numOutputs)
+	}
+	size, err := vm.ShieldedSpendSize(int(ringSize), int(numInputs), int(numOutputs))
+	if err != nil {
+		return nil, err
+	}
+	// Charge every byte of call data as non-zero
+	intrinsic, err := core.IntrinsicGas(bytes.Repeat([]byte{0xff}, size), 0, false, true)
+	if err != nil {
+		return nil, err
+	}
+	gas := intrinsic + vm.ShieldedSpendGas(uint64(size), uint64(numOutputs))
+
+	price, err := s.b.SuggestPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &RingFeeEstimate{
+		Size:     hexutil.Uint64(size),
+		Gas:      hexutil.Uint64(gas),
+		GasPrice: (*hexutil.Big)(price),
+		Fee:      (*hexutil.Big)(new(big.Int).Mul(price, new(big.Int).SetUint64(gas))),
+	}, nil
+}
+
+// parseRingMembers decodes secp256k1 public keys in compressed or uncompressed
+// form.
+func parseRingMembers(members []hexutil.Bytes) (ring.Ring, error) {
+	keys := make(ring.Ring, len(members))
+	for i, member := range members {
+		var err error
+		switch len(member) {
+		case 33:
+			keys[i], err = crypto.DecompressPubkey(member)
+		case 65:
+			keys[i], err = crypto.UnmarshalPubkey(member)
+		default:
+			err = fmt.Errorf("invalid length %d", len(member))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid ring member %d: %v", i, err)
+		}
+	}
+	return keys, nil
+}
+
+// RingPolicy selects the ring a ring transaction is signed over. The sender of
+// a ring transaction is the fingerprint of its ring, which pays for value and
+// gas, so sending from a funded shared account requires its exact members in
+// order. Without members, the signer is hidden among Size-1 senders of recent
+// transactions picked by chainkeys.DecoySelector, forming a one-off ring whose
+// account usually only affords transactions without value and gas price.
+type RingPolicy struct {
+	Members []common.Address `json:"members"` // ring members in order, including the signer
+	Size    *hexutil.Uint64  `json:"size"`    // size of a random ring, defaultRingSize if unset
+	Blocks  *hexutil.Uint64  `json:"blocks"`  // number of recent blocks searched for member keys, up to maxRingBlocks
+}
+
+// SendRingTxArgs represents the arguments to submit a new ring transaction. From
+// is the signing account, not the sender of the transaction.
+type SendRingTxArgs struct {
+	SendTxArgs
+	Ring RingPolicy `json:"ring"`
+}
+
+// SendRingTransaction creates a ring transaction for the given arguments,
+// signs it with the unlocked From account over the ring selected by the
+// policy, whose member keys are recovered from chain transactions, and
+// submits it to the transaction pool.
+func (s *PublicTransactionPoolAPI) SendRingTransaction(ctx context.Context, args SendRingTxArgs) (common.Hash, error) {
+	// Ring transactions are valid from the ring fork on, check the next block
+	// like the transaction pool does
+	config := s.b.ChainConfig()
+	if !config.IsRing(new(big.Int).Add(s.b.CurrentBlock().Number(), common.Big1)) {
+		return common.Hash{}, types.ErrTxTypeNotSupported
+	}
+	ks := fetchKeystore(s.b.AccountManager())
+	if !ks.HasAddress(args.From) {
+		return common.Hash{}, accounts.ErrUnknownAccount
+	}
+	account := accounts.Account{Address: args.From}
+	pub, err := ks.PublicKey(account)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	members, err := selectRing(ctx, s.b, args.Ring, args.From, pub)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	sender, err := types.RingFingerprint(members)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if args.Nonce == nil {
+		// Hold the sender's mutex around signing to prevent concurrent assignment
+		// of the same nonce to multiple transactions of the ring.
+		s.nonceLock.LockAddr(sender)
+		defer s.nonceLock.UnlockAddr(sender)
+
+		nonce, err := s.b.GetPoolNonce(ctx, sender)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		args.Nonce = (*hexutil.Uint64)(&nonce)
+	}
+	if args.Gas == nil {
+		// the default of setDefaults doesn't pay for verifying the ring
+		gas := 90000 + uint64(len(members))*params.RingVerifyPerMemberGas
+		args.Gas = (*hexutil.Uint64)(&gas)
+	}
+	if err := args.setDefaults(ctx, s.b); err != nil {
+		return common.Hash{}, err
+	}
+	tx := args.toTransaction()
+	tx = types.NewRingTransaction(config.ChainID, tx.Nonce(), tx.To(), tx.Value(), tx.Gas(), tx.GasPrice(), tx.Data())
+
+	signed, err := ks.SignRingTx(account, tx, members, config.ChainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, s.b, signed)
+}
+
+// ringBlocks returns the number of recent blocks to search for ring members,
+// defaultRingBlocks if unset. Every block searched is loaded with its
+// transactions and their senders recovered, so larger requests are refused.
+func ringBlocks(blocks *hexutil.Uint64) (uint64, error) {
+	if blocks == nil {
+		return defaultRingBlocks, nil
+	}
+	if *blocks > maxRingBlocks {
+		return 0, fmt.Errorf("too many blocks to search: %d > %d", *blocks, maxRingBlocks)
+	}
+	return uint64(*blocks), nil
+}
+
+// selectRing returns the ring of the policy for the signer with the given
+// address and public key.
+func selectRing(ctx context.Context, b Backend, policy RingPolicy, from common.Address, pub *ecdsa.PublicKey) (ring.Ring, error) {
+	blocks, err := ringBlocks(policy.Blocks)
+	if err != nil {
+		return nil, err
+	}
+	if len(policy.Members) > 0 {
+		if policy.Size != nil && int(*policy.Size) != len(policy.Members) {
+			return nil, errors.New("ring size doesn't match the members")
+		}
+		keys, err := recentSenders(ctx, b, blocks, policy.Members)
+		if err != nil {
+			return nil, err
+		}
+		keys[from] = pub
+
+		var missing []common.Address
+		members := make(ring.Ring, len(policy.Members))
+		for i, addr := range policy.Members {
+			if members[i] = keys[addr]; members[i] == nil {
+				missing = append(missing, addr)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, &chainkeys.MissingKeysError{Addresses: missing}
+		}
+		if !members.Contains(pub) {
+			return nil, errors.New("signer is not a member of the ring")
+		}
+		return members, members.Validate()
+	}
+	size := uint64(defaultRingSize)
+	if policy.Size != nil {
+		size = uint64(*policy.Size)
+	}
+	if size < 2 || size > uint64(ring.DefaultDecodeLimits.MaxRingSize) {
+		return nil, fmt.Errorf("invalid ring size %d", size)
+	}
+	decoys, err := chainkeys.NewDecoySelector(chainReader{b}, blocks).Select(ctx, int(size)-1, pub)
+	if err != nil {
+		return nil, err
+	}
+	// Insert the signer at a random position
+	pos, err := randIndex(len(decoys) + 1)
+	if err != nil {
+		return nil, err
+	}
+	members := make(ring.Ring, 0, size)
+	members = append(members, decoys[:pos]...)
+	members = append(members, pub)
+	return append(members, decoys[pos:]...), nil
+}
+
+// chainReader retrieves blocks of the backend for chainkeys.
+type chainReader struct {
+	b Backend
+}
+
+func (r chainReader) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	n := rpc.LatestBlockNumber
+	if number != nil {
+		n = rpc.BlockNumber(number.Int64())
+	}
+	block, err := r.b.BlockByNumber(ctx, n)
+	if block == nil && err == nil {
+		err = fmt.Errorf("block #%d not found", n)
+	}
+	return block, err
+}
+
+// randIndex returns a uniformly random index below n.
+func randIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
+
+// recentSenders recovers the public keys of the senders of the transactions
+// in the last blocks blocks, newest first, stopping early once all wanted
+// addresses are found.
+func recentSenders(ctx context.Context, b Backend, blocks uint64, want []common.Address) (map[common.Address]*ecdsa.PublicKey, error) {
+	var (
+		keys = make(map[common.Address]*ecdsa.PublicKey)
+		head = b.CurrentBlock().NumberU64()
+	)
+	found := func() bool {
+		for _, addr := range want {
+			if keys[addr] == nil {
+				return false
+			}
+		}
+		return true
+	}
+	for i := uint64(0); i < blocks && i <= head && !found(); i++ {
+		block, err := b.BlockByNumber(ctx, rpc.BlockNumber(head-i))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", head-i)
+		}
+		for _, tx := range block.Transactions() {
+			pub, err := chainkeys.TxPublicKey(tx)
+			if err != nil {
+				continue
+			}
+			keys[crypto.PubkeyToAddress(*pub)] = pub
+		}
+	}
+	return keys, nil
+}
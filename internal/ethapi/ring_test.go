@@ -0,0 +1,61 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Tests that the number of blocks searched for ring members is capped.
+func TestRingBlocks(t *testing.T) {
+	tests := []struct {
+		blocks *hexutil.Uint64
+		want   uint64
+		fail   bool
+	}{
+		{nil, defaultRingBlocks, false},
+		{newUint64(1), 1, false},
+		{newUint64(maxRingBlocks), maxRingBlocks, false},
+		{newUint64(maxRingBlocks + 1), 0, true},
+		{newUint64(1 << 62), 0, true},
+	}
+	for i, tt := range tests {
+		blocks, err := ringBlocks(tt.blocks)
+		if (err != nil) != tt.fail {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+		}
+		if blocks != tt.want {
+			t.Errorf("test %d: blocks mismatch: have %d, want %d", i, blocks, tt.want)
+		}
+	}
+}
+
+// Tests that decoy candidates aren't sampled from more blocks than the cap,
+// before the chain is touched at all.
+func TestGetDecoyCandidatesBlocksCap(t *testing.T) {
+	api := NewPublicRingAPI(nil)
+	if _, err := api.GetDecoyCandidates(context.Background(), 1, &DecoyPolicy{Blocks: newUint64(maxRingBlocks + 1)}); err == nil {
+		t.Fatal("oversized decoy policy accepted")
+	}
+}
+
+func newUint64(n uint64) *hexutil.Uint64 {
+	return (*hexutil.Uint64)(&n)
+}
@@ -27,6 +27,8 @@ var Modules = map[string]string{
 	"miner":      Miner_JS,
 	"net":        Net_JS,
 	"personal":   Personal_JS,
+	"ring":       Ring_JS,
+	"ringmsg":    RingMsg_JS,
 	"rpc":        RPC_JS,
 	"shh":        Shh_JS,
 	"swarmfs":    SWARMFS_JS,
@@ -444,6 +446,17 @@ web3._extend({
 			call: 'eth_chainId',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'sendRingTransaction',
+			call: 'eth_sendRingTransaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getKeyImageProof',
+			call: 'eth_getKeyImageProof',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'sign',
 			call: 'eth_sign',
@@ -686,3 +699,124 @@ web3._extend({
 	]
 });
 `
+
+const Ring_JS = `
+web3._extend({
+	property: 'ring',
+	methods: [
+		new web3._extend.Method({
+			name: 'sign',
+			call: 'ring_sign',
+			params: 3,
+			inputFormatter: [null, null, web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'verify',
+			call: 'ring_verify',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'keyImage',
+			call: 'ring_keyImage',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'link',
+			call: 'ring_link',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'keyImageSpent',
+			call: 'ring_keyImageSpent',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getKeyImageStatus',
+			call: 'ring_getKeyImageStatus',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getTransactionsByMember',
+			call: 'ring_getTransactionsByMember',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getDecoyCandidates',
+			call: 'ring_getDecoyCandidates',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'estimateFee',
+			call: 'ring_estimateFee',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'registerStealthWallet',
+			call: 'ring_registerStealthWallet',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'unregisterStealthWallet',
+			call: 'ring_unregisterStealthWallet',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getStealthBalance',
+			call: 'ring_getStealthBalance',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'listUnspent',
+			call: 'ring_listUnspent',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'exportKeyImages',
+			call: 'ring_exportKeyImages',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importKeyImages',
+			call: 'ring_importKeyImages',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getAuditReport',
+			call: 'ring_getAuditReport',
+			params: 5,
+			inputFormatter: [null, null, null, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+	]
+});
+`
+
+const RingMsg_JS = `
+web3._extend({
+	property: 'ringmsg',
+	methods: [
+		new web3._extend.Method({
+			name: 'addSet',
+			call: 'ringmsg_addSet',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeSet',
+			call: 'ringmsg_removeSet',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'post',
+			call: 'ringmsg_post',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'hasPosted',
+			call: 'ringmsg_hasPosted',
+			params: 2
+		}),
+	]
+});
+`
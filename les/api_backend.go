@@ -143,6 +143,11 @@ func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
 
+func (b *LesApiBackend) KeyImageSpent(ctx context.Context, image []byte) (bool, error) {
+	_, spent, err := light.GetKeyImageSpentAt(ctx, b.eth.odr, b.eth.blockchain.CurrentHeader(), image)
+	return spent, err
+}
+
 func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.eth.blockchain.SubscribeChainEvent(ch)
 }
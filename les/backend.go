@@ -186,7 +186,11 @@ func (s *LightDummyAPI) Mining() bool {
 // APIs returns the collection of RPC services the ethereum package offers.
 // NOTE, some of these services probably need to be moved to somewhere else.
 func (s *LightEthereum) APIs() []rpc.API {
-	return append(ethapi.GetAPIs(s.ApiBackend), []rpc.API{
+	apis := ethapi.GetAPIs(s.ApiBackend)
+	if s.config.RingRPC {
+		apis = append(apis, ethapi.GetRingAPIs(s.ApiBackend)...)
+	}
+	return append(apis, []rpc.API{
 		{
 			Namespace: "eth",
 			Version:   "1.0",
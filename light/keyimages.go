@@ -0,0 +1,116 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// The key images spent on chain live in the storage of the key image
+// precompile, so whether a key image was spent as of a block is proven by the
+// Merkle proof of the precompile account against the state root of the block
+// header, followed by the proof of the key image slot against the storage root
+// of the account. Both proofs also prove absence: a key image whose slot (or
+// the whole account) is missing was not spent.
+
+// KeyImageProof proves whether a key image is in the spent set of a state.
+type KeyImageProof struct {
+	AccountProof NodeList // proof of the key image precompile account
+	StorageProof NodeList // proof of the key image slot, empty without account
+}
+
+// ProveKeyImage creates the proof of whether the key image, given as the 64
+// byte concatenation of its coordinates, is spent in statedb. The state must
+// not have uncommitted changes to the key image set; states retrieved with
+// NewState are proven through ODR.
+func ProveKeyImage(statedb *state.StateDB, image []byte) (*KeyImageProof, error) {
+	accountProof, err := statedb.GetProof(vm.KeyImageSetAddress)
+	if err != nil {
+		return nil, err
+	}
+	proof := &KeyImageProof{AccountProof: toNodeList(accountProof)}
+	if !statedb.Exist(vm.KeyImageSetAddress) {
+		return proof, statedb.Error()
+	}
+	storageProof, err := statedb.GetStorageProof(vm.KeyImageSetAddress, vm.KeyImageSlot(image))
+	if err != nil {
+		return nil, err
+	}
+	proof.StorageProof = toNodeList(storageProof)
+	return proof, statedb.Error()
+}
+
+func toNodeList(nodes [][]byte) NodeList {
+	list := make(NodeList, len(nodes))
+	for i, node := range nodes {
+		list[i] = node
+	}
+	return list
+}
+
+// VerifyKeyImageProof checks the proof of a key image against a state root and
+// returns the number of the block that spent the image, and whether it was.
+func VerifyKeyImageProof(root common.Hash, image []byte, proof *KeyImageProof) (uint64, bool, error) {
+	key := crypto.Keccak256(vm.KeyImageSetAddress.Bytes())
+	enc, _, err := trie.VerifyProof(root, key, proof.AccountProof.NodeSet())
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid account proof: %v", err)
+	}
+	if enc == nil {
+		return 0, false, nil
+	}
+	var account state.Account
+	if err := rlp.DecodeBytes(enc, &account); err != nil {
+		return 0, false, fmt.Errorf("invalid account: %v", err)
+	}
+	key = crypto.Keccak256(vm.KeyImageSlot(image).Bytes())
+	enc, _, err = trie.VerifyProof(account.Root, key, proof.StorageProof.NodeSet())
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid storage proof: %v", err)
+	}
+	if enc == nil {
+		return 0, false, nil
+	}
+	_, content, _, err := rlp.Split(enc)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid storage value: %v", err)
+	}
+	value := new(big.Int).SetBytes(content)
+	if value.Sign() == 0 || value.BitLen() > 64 {
+		return 0, false, errors.New("invalid key image marker")
+	}
+	return value.Uint64() - 1, true, nil
+}
+
+// GetKeyImageSpentAt retrieves through ODR whether the key image was spent as
+// of the block with the given header, and the number of the block spending it.
+func GetKeyImageSpentAt(ctx context.Context, odr OdrBackend, header *types.Header, image []byte) (uint64, bool, error) {
+	statedb := NewState(ctx, header, odr)
+	number, spent := vm.KeyImageSpentAt(statedb, image)
+	return number, spent, statedb.Error()
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestKeyImageProof(t *testing.T) {
+	var (
+		sdb     = ethdb.NewMemDatabase()
+		spent   = common.RightPadBytes([]byte{1}, 64)
+		unspent = common.RightPadBytes([]byte{2}, 64)
+	)
+	// Without any spent image, absence of the precompile account is proven
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(sdb))
+	statedb.AddBalance(common.Address{1}, big.NewInt(1))
+	empty, _ := statedb.Commit(true)
+	statedb.Database().TrieDB().Commit(empty, false)
+	statedb, _ = state.New(empty, state.NewDatabase(sdb))
+	proof, err := ProveKeyImage(statedb, spent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := VerifyKeyImageProof(empty, spent, proof); ok || err != nil {
+		t.Fatalf("empty set: spent %v, error %v", ok, err)
+	}
+
+	vm.MarkKeyImageSeen(statedb, spent, 42)
+	root, _ := statedb.Commit(true)
+	statedb.Database().TrieDB().Commit(root, false)
+	statedb, _ = state.New(root, state.NewDatabase(sdb))
+
+	for _, test := range []struct {
+		image  []byte
+		spent  bool
+		number uint64
+	}{
+		{spent, true, 42},
+		{unspent, false, 0},
+	} {
+		proof, err := ProveKeyImage(statedb, test.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		number, ok, err := VerifyKeyImageProof(root, test.image, proof)
+		if err != nil || ok != test.spent || number != test.number {
+			t.Errorf("image %x: have %d %v %v, want %d %v", test.image[:1], number, ok, err, test.number, test.spent)
+		}
+		// The proof must not verify against another state
+		if _, _, err := VerifyKeyImageProof(empty, test.image, proof); err == nil {
+			t.Errorf("image %x: proof verified against the wrong root", test.image[:1])
+		}
+	}
+	// A proof of absence must not be usable for a spent image
+	proof, _ = ProveKeyImage(statedb, unspent)
+	proof.StorageProof = proof.StorageProof[:len(proof.StorageProof)-1]
+	if _, ok, err := VerifyKeyImageProof(root, spent, proof); ok || err == nil {
+		t.Errorf("truncated proof: spent %v, error %v", ok, err)
+	}
+
+	// Light clients retrieve the same answer through ODR
+	odr := &testOdr{sdb: sdb, ldb: ethdb.NewMemDatabase()}
+	header := &types.Header{Number: big.NewInt(43), Root: root}
+	number, ok, err := GetKeyImageSpentAt(context.Background(), odr, header, spent)
+	if err != nil || !ok || number != 42 {
+		t.Errorf("ODR: have %d %v %v, want 42 true", number, ok, err)
+	}
+	if _, ok, err := GetKeyImageSpentAt(context.Background(), odr, header, unspent); ok || err != nil {
+		t.Errorf("ODR unspent: spent %v, error %v", ok, err)
+	}
+}
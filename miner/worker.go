@@ -91,6 +91,8 @@ type environment struct {
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
+
+	keyImages map[string]struct{} // key images spent by the ring transactions of the block
 }
 
 // task contains all information for consensus engine sealing and result submitting.
@@ -614,12 +616,13 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 		return err
 	}
 	env := &environment{
-		signer:    types.NewEIP155Signer(w.config.ChainID),
+		signer:    types.NewRingSigner(w.config.ChainID),
 		state:     state,
 		ancestors: mapset.NewSet(),
 		family:    mapset.NewSet(),
 		uncles:    mapset.NewSet(),
 		header:    header,
+		keyImages: make(map[string]struct{}),
 	}
 
 	// when 08 is processed ancestors contain 07 (quick block)
@@ -700,6 +703,10 @@ func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Addres
 	w.current.txs = append(w.current.txs, tx)
 	w.current.receipts = append(w.current.receipts, receipt)
 
+	if image := tx.KeyImage(); image != nil {
+		w.current.keyImages[string(image)] = struct{}{}
+	}
+
 	return receipt.Logs, nil
 }
 
@@ -749,7 +756,7 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		// Error may be ignored here. The error has already been checked
 		// during transaction acceptance is the transaction pool.
 		//
-		// We use the eip155 signer regardless of the current hf.
+		// We use the ring signer regardless of the current hf.
 		from, _ := types.Sender(w.current.signer, tx)
 		// Check whether the tx is replay protected. If we're not in the EIP155 hf
 		// phase, start ignoring the sender until we do.
@@ -759,6 +766,24 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			txs.Pop()
 			continue
 		}
+		// Ring transactions are only valid after the ring fork, can't spend a key
+		// image twice in a block and are limited in their total size. The sender
+		// is the fingerprint of the ring, shared by all its members, so skipping
+		// it only delays the other members until the next block.
+		if image := tx.KeyImage(); image != nil {
+			if !w.config.IsRing(w.current.header.Number) {
+				log.Trace("Ignoring ring transaction", "hash", tx.Hash(), "ring", w.config.RingForkBlock)
+
+				txs.Pop()
+				continue
+			}
+			if _, ok := w.current.keyImages[string(image)]; ok {
+				log.Trace("Skipping ring transaction with key image spent in block", "hash", tx.Hash(), "sender", from)
+
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		w.current.state.Prepare(tx.Hash(), common.Hash{}, w.current.tcount)
 
@@ -779,6 +804,11 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
 			txs.Pop()
 
+		case core.ErrKeyImageSpent:
+			// New head notification data race between the transaction pool and miner, skip account
+			log.Trace("Skipping ring transaction with spent key image", "sender", from, "hash", tx.Hash())
+			txs.Pop()
+
 		case nil:
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
@@ -920,10 +950,11 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		w.updateSnapshot()
 		return
 	}
-	// Split the pending transactions into locals and remotes
+	// Split the pending transactions into locals and remotes. Ring accounts are
+	// shared by every member of their ring, so they never get local priority.
 	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
 	for _, account := range w.eth.TxPool().Locals() {
-		if txs := remoteTxs[account]; len(txs) > 0 {
+		if txs := remoteTxs[account]; len(txs) > 0 && txs[0].Type() != types.RingTxType {
 			delete(remoteTxs, account)
 			localTxs[account] = txs
 		}
@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"testing"
 	"time"
@@ -29,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
@@ -452,3 +454,91 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+// Tests that ring transactions spending the same key image, arriving in the
+// same recommit interval, are packed into a block only once, and that ring
+// transactions are ignored before the ring fork.
+func TestRingTransactionKeyImages(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, 0)
+	w := &worker{config: ethashChainConfig, chain: b.chain, mux: new(event.TypeMux)}
+
+	parent := b.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   params.GenesisGasLimit,
+		Difficulty: big.NewInt(1),
+		Time:       new(big.Int).Add(parent.Time(), common.Big1),
+	}
+	if err := w.makeCurrent(parent, header); err != nil {
+		t.Fatal(err)
+	}
+	// Sign with the same key over two rings, funding both ring accounts
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	rings := []ring.Ring{
+		{&keys[0].PublicKey, &keys[1].PublicKey},
+		{&keys[0].PublicKey, &keys[2].PublicKey},
+	}
+	senders := make([]common.Address, len(rings))
+	for i, members := range rings {
+		senders[i], _ = types.RingFingerprint(members)
+		w.current.state.AddBalance(senders[i], testBankFunds)
+	}
+	signer := types.NewRingSigner(ethashChainConfig.ChainID)
+	ringTx := func(members ring.Ring, key *ecdsa.PrivateKey, nonce uint64, gasprice int64) *types.Transaction {
+		tx := types.NewRingTransaction(ethashChainConfig.ChainID, nonce, &testUserAddress, big.NewInt(1000), params.TxGas+uint64(len(members))*params.RingVerifyPerMemberGas, big.NewInt(gasprice), nil)
+		tx, err := types.SignRingTx(tx, signer, members, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	cheap, dear := ringTx(rings[0], keys[0], 0, 1), ringTx(rings[1], keys[0], 0, 2)
+	if string(cheap.KeyImage()) != string(dear.KeyImage()) {
+		t.Fatal("key images of the same key differ")
+	}
+	// Only the better priced of the conflicting transactions is included
+	txs := types.NewTransactionsByPriceAndNonce(w.current.signer, map[common.Address]types.Transactions{
+		senders[0]: {cheap},
+		senders[1]: {dear},
+	})
+	w.commitTransactions(txs, testBankAddress, nil)
+	if len(w.current.txs) != 1 || w.current.txs[0] != dear {
+		t.Fatalf("included transactions mismatch: have %v, want [%x]", w.current.txs, dear.Hash())
+	}
+	// A late spend of the same key image is skipped, other members aren't
+	late, other := ringTx(rings[0], keys[0], 0, 10), ringTx(rings[0], keys[1], 0, 1)
+	txs = types.NewTransactionsByPriceAndNonce(w.current.signer, map[common.Address]types.Transactions{
+		senders[0]: {late},
+	})
+	w.commitTransactions(txs, testBankAddress, nil)
+	txs = types.NewTransactionsByPriceAndNonce(w.current.signer, map[common.Address]types.Transactions{
+		senders[0]: {other},
+	})
+	w.commitTransactions(txs, testBankAddress, nil)
+	if len(w.current.txs) != 2 || w.current.txs[1] != other {
+		t.Fatalf("included transactions mismatch: have %v, want [%x %x]", w.current.txs, dear.Hash(), other.Hash())
+	}
+	// Before the ring fork ring transactions are ignored
+	config := *ethashChainConfig
+	config.RingForkBlock = big.NewInt(100)
+	w.config = &config
+	if err := w.makeCurrent(parent, header); err != nil {
+		t.Fatal(err)
+	}
+	w.current.state.AddBalance(senders[0], testBankFunds)
+
+	txs = types.NewTransactionsByPriceAndNonce(w.current.signer, map[common.Address]types.Transactions{
+		senders[0]: {cheap},
+	})
+	w.commitTransactions(txs, testBankAddress, nil)
+	if len(w.current.txs) != 0 {
+		t.Fatalf("pre-fork ring transactions included: %v", w.current.txs)
+	}
+}
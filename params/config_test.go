@@ -70,6 +70,17 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored: &ChainConfig{RingForkBlock: big.NewInt(10)},
+			new:    &ChainConfig{RingForkBlock: nil},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Ring fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    nil,
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -85,7 +85,15 @@ const (
 	Bn256ScalarMulGas       uint64 = 40000  // Gas needed for an elliptic curve scalar multiplication
 	Bn256PairingBaseGas     uint64 = 100000 // Base price for an elliptic curve pairing check
 	Bn256PairingPerPointGas uint64 = 80000  // Per-point price for an elliptic curve pairing check
-	RingVerifyGas			uint64 = 1000	// Ring signature verification gas price
+	RingVerifyGas           uint64 = 1000   // Ring signature verification gas price before the ring fork
+	RingVerifyBaseGas       uint64 = 3000   // Base price for a ring signature verification
+	RingVerifyPerMemberGas  uint64 = 10000  // Per-member price for a ring signature verification
+	KeyImageSeenBaseGas     uint64 = 200    // Base price for a key image lookup, one storage read
+	KeyImageSeenPerImageGas uint64 = 12     // Per-image price for comparing against a supplied list
+	ShieldedDepositGas      uint64 = 100000 // Price for a shielded pool deposit, one tree insertion
+	ShieldedSpendBaseGas    uint64 = 150000 // Base price for a shielded pool spend
+	ShieldedSpendPerByteGas uint64 = 100    // Per-byte price for a shielded pool spend, covering ring members and proofs
+	ShieldedOutputGas       uint64 = 50000  // Per-output price for a shielded pool spend, one tree insertion
 )
 
 var (
@@ -0,0 +1,168 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringmsg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PrivateRingMsgAPI exposes the messaging service over RPC.
+type PrivateRingMsgAPI struct {
+	rm *RingMsg
+}
+
+// NewPrivateRingMsgAPI creates a new messaging API.
+func NewPrivateRingMsgAPI(rm *RingMsg) *PrivateRingMsgAPI {
+	return &PrivateRingMsgAPI{rm}
+}
+
+// AddSet makes the membership set with the given public keys known, and
+// returns its identifier.
+func (api *PrivateRingMsgAPI) AddSet(members []hexutil.Bytes) (common.Hash, error) {
+	keys := make(ring.Ring, len(members))
+	for i, member := range members {
+		var err error
+		if len(member) == 33 {
+			keys[i], err = crypto.DecompressPubkey(member)
+		} else {
+			keys[i], err = crypto.UnmarshalPubkey(member)
+		}
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("invalid member %d: %v", i, err)
+		}
+	}
+	return api.rm.AddSet(keys)
+}
+
+// RemoveSet forgets a membership set.
+func (api *PrivateRingMsgAPI) RemoveSet(id common.Hash) bool {
+	return api.rm.RemoveSet(id)
+}
+
+// PostArgs are the arguments of posting a message.
+type PostArgs struct {
+	Set     common.Hash   `json:"set"`
+	Topic   hexutil.Bytes `json:"topic"`
+	Payload hexutil.Bytes `json:"payload"`
+	TTL     uint32        `json:"ttl"` // lifetime of the message in seconds
+	Key     hexutil.Bytes `json:"key"` // private key of the sending member
+}
+
+// Post signs a message on behalf of a member of a known set and sends it to
+// the network. It returns the hash of the envelope.
+func (api *PrivateRingMsgAPI) Post(args PostArgs) (common.Hash, error) {
+	members, ok := api.rm.Set(args.Set)
+	if !ok {
+		return common.Hash{}, errUnknownSet
+	}
+	key, err := crypto.ToECDSA(args.Key)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid key: %v", err)
+	}
+	if args.TTL == 0 || args.TTL > api.rm.config.MaxTTL {
+		return common.Hash{}, fmt.Errorf("ttl must be between 1 and %d", api.rm.config.MaxTTL)
+	}
+	expiry := uint64(time.Now().Unix()) + uint64(args.TTL)
+	env, err := Seal(members, args.Topic, args.Payload, expiry, key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.rm.Send(env); err != nil {
+		return common.Hash{}, err
+	}
+	return env.Hash(), nil
+}
+
+// HasPosted reports whether the owner of a private key posted a live message
+// to a topic, in which case it can't post another one before it expires.
+func (api *PrivateRingMsgAPI) HasPosted(topic hexutil.Bytes, key hexutil.Bytes) (bool, error) {
+	priv, err := crypto.ToECDSA(key)
+	if err != nil {
+		return false, fmt.Errorf("invalid key: %v", err)
+	}
+	return api.rm.hasImage(topic, ring.ScopedKeyImage(topic, priv)), nil
+}
+
+// Criteria selects the messages of a subscription. Empty fields match all.
+type Criteria struct {
+	Set   *common.Hash  `json:"set"`
+	Topic hexutil.Bytes `json:"topic"`
+}
+
+// Message is a message accepted by the node, as sent over RPC.
+type Message struct {
+	Hash     common.Hash    `json:"hash"`
+	Set      common.Hash    `json:"set"`
+	Topic    hexutil.Bytes  `json:"topic"`
+	Payload  hexutil.Bytes  `json:"payload"`
+	Expiry   hexutil.Uint64 `json:"expiry"`
+	KeyImage hexutil.Bytes  `json:"keyImage"`
+}
+
+// Messages creates a subscription that fires for every message accepted by the
+// node matching the criteria.
+func (api *PrivateRingMsgAPI) Messages(ctx context.Context, crit Criteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		envelopes := make(chan *Envelope, 128)
+		envelopesSub := api.rm.SubscribeEnvelopes(envelopes)
+		defer envelopesSub.Unsubscribe()
+
+		for {
+			select {
+			case env := <-envelopes:
+				if crit.Set != nil && *crit.Set != env.Set {
+					continue
+				}
+				if len(crit.Topic) > 0 && !bytes.Equal(crit.Topic, env.Topic) {
+					continue
+				}
+				notifier.Notify(rpcSub.ID, &Message{
+					Hash:     env.Hash(),
+					Set:      env.Set,
+					Topic:    env.Topic,
+					Payload:  env.Payload,
+					Expiry:   hexutil.Uint64(env.Expiry),
+					KeyImage: env.Image,
+				})
+			case <-envelopesSub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
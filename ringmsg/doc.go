@@ -0,0 +1,35 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package ringmsg implements an anonymous messaging protocol over devp2p.
+
+Messages are not authenticated by the key of the node that sent them, but by a
+ring signature over a published membership set, such as the validators of a
+chain: a message proves that one of the members says something, without
+revealing which one. Membership sets are identified by the ring.RingID of
+their members, the same identifier verifier contracts store them under, and
+must be known to a node before it accepts or relays messages for them.
+
+Every message is posted under a topic, and its signature carries a key image of
+the sender scoped to that topic. Nodes remember the images seen per topic for
+as long as the messages live, and drop any further message with the same image:
+a replayed message is rejected, and each member may post a single message per
+topic. Topics such as "block 7 attestation" thus double as rate limits.
+
+Messages are flooded to all peers until they expire.
+*/
+package ringmsg
@@ -0,0 +1,119 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringmsg
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	errEmptyTopic   = errors.New("empty topic")
+	errInvalidImage = errors.New("invalid key image")
+	errInvalidSig   = errors.New("invalid ring signature")
+)
+
+// Envelope is a message posted to a topic by an anonymous member of a
+// membership set.
+type Envelope struct {
+	Set     common.Hash // identifier of the membership set of the sender
+	Topic   []byte      // topic the key image of the sender is scoped to
+	Expiry  uint64      // unix time after which the message is dropped
+	Payload []byte      // application defined content
+	Image   []byte      // compressed key image of the sender under the topic
+	C       []*big.Int  // challenges of the ring signature, one per member
+	S       []*big.Int  // responses of the ring signature, one per member
+
+	hash common.Hash // cached hash of the envelope
+}
+
+// Seal creates an envelope of payload posted to topic by the owner of key,
+// who must be a member of the set. It expires at the given unix time.
+func Seal(members ring.Ring, topic, payload []byte, expiry uint64, key *ecdsa.PrivateKey) (*Envelope, error) {
+	if len(topic) == 0 {
+		return nil, errEmptyTopic
+	}
+	set, err := ring.RingID(members)
+	if err != nil {
+		return nil, err
+	}
+	env := &Envelope{
+		Set:     set,
+		Topic:   topic,
+		Expiry:  expiry,
+		Payload: payload,
+	}
+	sig, err := ring.SignScoped(env.sigHash(), topic, members, key, members.Index(&key.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	env.Image = crypto.CompressPubkey(sig.Image)
+	env.C, env.S = sig.C, sig.S
+	return env, nil
+}
+
+// Hash returns the hash of the envelope, which identifies it on the network.
+func (e *Envelope) Hash() common.Hash {
+	if (e.hash == common.Hash{}) {
+		enc, _ := rlp.EncodeToBytes(e)
+		e.hash = crypto.Keccak256Hash(enc)
+	}
+	return e.hash
+}
+
+// sigHash returns the hash signed by the sender.
+func (e *Envelope) sigHash() [32]byte {
+	enc, _ := rlp.EncodeToBytes([]interface{}{e.Set, e.Topic, e.Expiry, e.Payload})
+	return crypto.Keccak256Hash(enc)
+}
+
+// size returns the encoded size of the envelope.
+func (e *Envelope) size() int {
+	enc, _ := rlp.EncodeToBytes(e)
+	return len(enc)
+}
+
+// Verify checks that the envelope was signed by a member of the set with the
+// given members, and returns the key image of the sender.
+func (e *Envelope) Verify(members ring.Ring) (*ecdsa.PublicKey, error) {
+	if len(e.Topic) == 0 {
+		return nil, errEmptyTopic
+	}
+	image, err := crypto.DecompressPubkey(e.Image)
+	if err != nil {
+		return nil, errInvalidImage
+	}
+	sig := &ring.ScopedSign{
+		M:     e.sigHash(),
+		Scope: e.Topic,
+		Ring:  members,
+		Image: image,
+		C:     e.C,
+		S:     e.S,
+		Curve: crypto.S256(),
+	}
+	if !ring.VerifyScoped(sig) {
+		return nil, errInvalidSig
+	}
+	return image, nil
+}
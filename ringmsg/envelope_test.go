@@ -0,0 +1,82 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringmsg
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// newTestSet creates a membership set of n random keys.
+func newTestSet(t *testing.T, n int) ([]*ecdsa.PrivateKey, ring.Ring) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	members := make(ring.Ring, n)
+	for i := range keys {
+		var err error
+		if keys[i], err = crypto.GenerateKey(); err != nil {
+			t.Fatal(err)
+		}
+		members[i] = &keys[i].PublicKey
+	}
+	return keys, members
+}
+
+func TestEnvelopeSealVerify(t *testing.T) {
+	keys, members := newTestSet(t, 4)
+
+	env, err := Seal(members, []byte("topic"), []byte("hello"), 100, keys[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The envelope survives the network encoding
+	enc, err := rlp.EncodeToBytes(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := new(Envelope)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Hash() != env.Hash() {
+		t.Fatalf("hash mismatch after decoding: have %x, want %x", dec.Hash(), env.Hash())
+	}
+	image, err := dec.Verify(members)
+	if err != nil {
+		t.Fatalf("valid envelope rejected: %v", err)
+	}
+	if !ring.PublicKeyEqual(image, ring.ScopedKeyImage([]byte("topic"), keys[2])) {
+		t.Error("key image mismatch")
+	}
+	// Altered content and other sets are rejected
+	dec.Payload = []byte("bye")
+	if _, err := dec.Verify(members); err != errInvalidSig {
+		t.Errorf("altered payload: have error %v, want %v", err, errInvalidSig)
+	}
+	_, others := newTestSet(t, 4)
+	if _, err := env.Verify(others); err != errInvalidSig {
+		t.Errorf("other set: have error %v, want %v", err, errInvalidSig)
+	}
+	// Non-members can't seal
+	outsider, _ := crypto.GenerateKey()
+	if _, err := Seal(members, []byte("topic"), nil, 100, outsider); err == nil {
+		t.Error("envelope sealed by non-member")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringmsg
+
+import (
+	"fmt"
+	"time"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// Peer represents a messaging protocol peer connection.
+type Peer struct {
+	host *RingMsg
+	peer *p2p.Peer
+	rw   p2p.MsgReadWriter
+
+	known mapset.Set // Envelopes already known by the peer to avoid wasting bandwidth
+
+	quit chan struct{}
+}
+
+// newPeer creates a new messaging peer, but does not run the handshake itself.
+func newPeer(host *RingMsg, remote *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
+	return &Peer{
+		host:  host,
+		peer:  remote,
+		rw:    rw,
+		known: mapset.NewSet(),
+		quit:  make(chan struct{}),
+	}
+}
+
+// start initiates the peer updater, periodically sending the live envelopes
+// to the peer.
+func (p *Peer) start() {
+	go p.update()
+}
+
+// stop terminates the peer updater.
+func (p *Peer) stop() {
+	close(p.quit)
+}
+
+// handshake exchanges the protocol version with the remote peer.
+func (p *Peer) handshake() error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- p2p.Send(p.rw, statusCode, ProtocolVersion)
+	}()
+	packet, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if packet.Code != statusCode {
+		return fmt.Errorf("peer [%x] sent packet %x before status packet", p.peer.ID(), packet.Code)
+	}
+	var version uint64
+	if err := packet.Decode(&version); err != nil {
+		return fmt.Errorf("peer [%x] sent bad status message: %v", p.peer.ID(), err)
+	}
+	if version != ProtocolVersion {
+		return fmt.Errorf("peer [%x]: protocol version mismatch %d != %d", p.peer.ID(), version, ProtocolVersion)
+	}
+	if err := <-errc; err != nil {
+		return fmt.Errorf("peer [%x] failed to send status packet: %v", p.peer.ID(), err)
+	}
+	return nil
+}
+
+// update periodically sends new envelopes to the peer and forgets the expired
+// ones it knew.
+func (p *Peer) update() {
+	expire := time.NewTicker(expirationCycle)
+	defer expire.Stop()
+	transmit := time.NewTicker(transmissionCycle)
+	defer transmit.Stop()
+
+	for {
+		select {
+		case <-expire.C:
+			p.expire()
+
+		case <-transmit.C:
+			if err := p.broadcast(); err != nil {
+				log.Trace("Envelope broadcast failed", "peer", p.peer.ID(), "err", err)
+				return
+			}
+
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// mark marks an envelope known to the peer so that it won't be sent back.
+func (p *Peer) mark(env *Envelope) {
+	p.known.Add(env.Hash())
+}
+
+// marked checks if an envelope is already known to the peer.
+func (p *Peer) marked(env *Envelope) bool {
+	return p.known.Contains(env.Hash())
+}
+
+// expire removes the envelopes no longer live in the host from the known set.
+func (p *Peer) expire() {
+	var unmark []common.Hash
+	p.known.Each(func(v interface{}) bool {
+		if !p.host.isEnvelopeCached(v.(common.Hash)) {
+			unmark = append(unmark, v.(common.Hash))
+		}
+		return true
+	})
+	for _, hash := range unmark {
+		p.known.Remove(hash)
+	}
+}
+
+// broadcast sends the live envelopes unknown to the peer, in batches below the
+// maximum packet size.
+func (p *Peer) broadcast() error {
+	var (
+		bundle []*Envelope
+		size   int
+	)
+	for _, env := range p.host.Envelopes() {
+		if p.marked(env) {
+			continue
+		}
+		n := env.size()
+		if size+n > maxPacketSize && len(bundle) > 0 {
+			if err := p.send(bundle); err != nil {
+				return err
+			}
+			bundle, size = nil, 0
+		}
+		bundle = append(bundle, env)
+		size += n
+	}
+	if len(bundle) > 0 {
+		return p.send(bundle)
+	}
+	return nil
+}
+
+// send transmits a batch of envelopes, marking them known once sent.
+func (p *Peer) send(bundle []*Envelope) error {
+	if err := p2p.Send(p.rw, messagesCode, bundle); err != nil {
+		return err
+	}
+	for _, env := range bundle {
+		p.mark(env)
+	}
+	log.Trace("Sent envelopes", "peer", p.peer.ID(), "count", len(bundle))
+	return nil
+}
@@ -0,0 +1,381 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringmsg
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	ProtocolName       = "rms" // Nickname of the protocol in devp2p
+	ProtocolVersion    = uint64(1)
+	ProtocolVersionStr = "1.0"
+
+	statusCode           = 0 // Handshake of the protocol version
+	messagesCode         = 1 // Batch of envelopes
+	NumberOfMessageCodes = 2
+
+	expirationCycle   = time.Second            // Interval of dropping expired envelopes
+	transmissionCycle = 300 * time.Millisecond // Interval of sending new envelopes to peers
+	syncAllowance     = 10                     // Seconds of tolerated clock drift between peers
+	maxPacketSize     = 10 * 1024 * 1024       // Largest batch of envelopes sent to a peer at once
+)
+
+var (
+	errUnknownSet = errors.New("unknown membership set")
+	errExpired    = errors.New("envelope expired")
+	errTTL        = errors.New("envelope expiry too far in the future")
+	errTooLarge   = errors.New("envelope too large")
+	errReplay     = errors.New("key image already used for topic")
+)
+
+// Config are the configuration parameters of the messaging service.
+type Config struct {
+	MaxMessageSize uint32 `toml:",omitempty"` // Largest accepted envelope
+	MaxTTL         uint32 `toml:",omitempty"` // Largest accepted lifetime of an envelope, in seconds
+}
+
+// DefaultConfig represents (shocker!) the default configuration.
+var DefaultConfig = Config{
+	MaxMessageSize: 512 * 1024,
+	MaxTTL:         3600,
+}
+
+// imageKey identifies the use of a key image under a topic.
+type imageKey struct {
+	topic common.Hash
+	image string
+}
+
+func newImageKey(topic []byte, image *ecdsa.PublicKey) imageKey {
+	return imageKey{topic: crypto.Keccak256Hash(topic), image: string(crypto.CompressPubkey(image))}
+}
+
+// RingMsg is the messaging service, relaying the envelopes posted to the
+// membership sets it knows.
+type RingMsg struct {
+	config   Config
+	protocol p2p.Protocol
+
+	sets      map[common.Hash]ring.Ring // known membership sets by identifier
+	envelopes map[common.Hash]*Envelope // live envelopes by hash
+	images    map[imageKey]common.Hash  // envelopes by topic and key image of the sender
+	lock      sync.RWMutex
+
+	peers    map[*Peer]struct{}
+	peerLock sync.Mutex
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+	quit  chan struct{}
+}
+
+// New creates a messaging service with the given configuration.
+func New(config *Config) *RingMsg {
+	if config == nil {
+		config = &DefaultConfig
+	}
+	rm := &RingMsg{
+		config:    *config,
+		sets:      make(map[common.Hash]ring.Ring),
+		envelopes: make(map[common.Hash]*Envelope),
+		images:    make(map[imageKey]common.Hash),
+		peers:     make(map[*Peer]struct{}),
+		quit:      make(chan struct{}),
+	}
+	rm.protocol = p2p.Protocol{
+		Name:    ProtocolName,
+		Version: uint(ProtocolVersion),
+		Length:  NumberOfMessageCodes,
+		Run:     rm.HandlePeer,
+		NodeInfo: func() interface{} {
+			rm.lock.RLock()
+			defer rm.lock.RUnlock()
+
+			return map[string]interface{}{
+				"version":        ProtocolVersionStr,
+				"maxMessageSize": rm.config.MaxMessageSize,
+				"sets":           len(rm.sets),
+			}
+		},
+	}
+	return rm
+}
+
+// Protocols implements node.Service, returning the messaging sub-protocol.
+func (rm *RingMsg) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{rm.protocol}
+}
+
+// APIs implements node.Service, returning the RPC API of the service. It signs
+// with keys passed by the caller, so it must not be offered publicly.
+func (rm *RingMsg) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "ringmsg",
+			Version:   ProtocolVersionStr,
+			Service:   NewPrivateRingMsgAPI(rm),
+			Public:    false,
+		},
+	}
+}
+
+// Start implements node.Service, starting the expiration of envelopes.
+func (rm *RingMsg) Start(*p2p.Server) error {
+	log.Info("Started ring messaging", "version", ProtocolVersionStr)
+	go rm.update()
+	return nil
+}
+
+// Stop implements node.Service, terminating the expiration of envelopes and
+// all subscriptions.
+func (rm *RingMsg) Stop() error {
+	close(rm.quit)
+	rm.scope.Close()
+	log.Info("Ring messaging stopped")
+	return nil
+}
+
+// AddSet makes a membership set known, accepting the envelopes posted by its
+// members. It returns the identifier of the set.
+func (rm *RingMsg) AddSet(members ring.Ring) (common.Hash, error) {
+	if err := members.Validate(); err != nil {
+		return common.Hash{}, err
+	}
+	id, err := ring.RingID(members)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	rm.lock.Lock()
+	rm.sets[id] = append(ring.Ring{}, members...)
+	rm.lock.Unlock()
+
+	return id, nil
+}
+
+// RemoveSet forgets a membership set, returning whether it was known.
+// Envelopes already accepted for it are still relayed until they expire.
+func (rm *RingMsg) RemoveSet(id common.Hash) bool {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+
+	_, ok := rm.sets[id]
+	delete(rm.sets, id)
+	return ok
+}
+
+// Set returns the members of a known membership set.
+func (rm *RingMsg) Set(id common.Hash) (ring.Ring, bool) {
+	rm.lock.RLock()
+	defer rm.lock.RUnlock()
+
+	members, ok := rm.sets[id]
+	return members, ok
+}
+
+// Send posts an envelope created locally to the network.
+func (rm *RingMsg) Send(env *Envelope) error {
+	added, err := rm.add(env)
+	if err == nil && !added {
+		return fmt.Errorf("envelope %x already known", env.Hash())
+	}
+	return err
+}
+
+// SubscribeEnvelopes registers a subscription of the envelopes accepted by the
+// service, whether received or posted locally.
+func (rm *RingMsg) SubscribeEnvelopes(ch chan<- *Envelope) event.Subscription {
+	return rm.scope.Track(rm.feed.Subscribe(ch))
+}
+
+// Envelopes returns all the live envelopes.
+func (rm *RingMsg) Envelopes() []*Envelope {
+	rm.lock.RLock()
+	defer rm.lock.RUnlock()
+
+	all := make([]*Envelope, 0, len(rm.envelopes))
+	for _, env := range rm.envelopes {
+		all = append(all, env)
+	}
+	return all
+}
+
+// isEnvelopeCached checks if an envelope with the given hash is still live.
+func (rm *RingMsg) isEnvelopeCached(hash common.Hash) bool {
+	rm.lock.RLock()
+	defer rm.lock.RUnlock()
+
+	_, ok := rm.envelopes[hash]
+	return ok
+}
+
+// hasImage reports whether a live envelope was posted to topic by the member
+// with the given key image.
+func (rm *RingMsg) hasImage(topic []byte, image *ecdsa.PublicKey) bool {
+	rm.lock.RLock()
+	defer rm.lock.RUnlock()
+
+	_, ok := rm.images[newImageKey(topic, image)]
+	return ok
+}
+
+// add validates an envelope and records it for relay, returning whether it was
+// new. Envelopes of unknown sets are rejected as they can't be verified, and
+// so are expired ones and replays of a key image under a topic.
+func (rm *RingMsg) add(env *Envelope) (bool, error) {
+	now := uint64(time.Now().Unix())
+	if env.Expiry+syncAllowance < now {
+		return false, errExpired
+	}
+	if env.Expiry > now+uint64(rm.config.MaxTTL)+syncAllowance {
+		return false, errTTL
+	}
+	if env.size() > int(rm.config.MaxMessageSize) {
+		return false, errTooLarge
+	}
+	hash := env.Hash()
+	if rm.isEnvelopeCached(hash) {
+		return false, nil
+	}
+	members, ok := rm.Set(env.Set)
+	if !ok {
+		log.Trace("Ignoring envelope of unknown set", "hash", hash, "set", env.Set)
+		return false, errUnknownSet
+	}
+	image, err := env.Verify(members)
+	if err != nil {
+		return false, err
+	}
+	key := newImageKey(env.Topic, image)
+
+	rm.lock.Lock()
+	if _, ok := rm.envelopes[hash]; ok {
+		rm.lock.Unlock()
+		return false, nil
+	}
+	if prev, ok := rm.images[key]; ok {
+		rm.lock.Unlock()
+		log.Debug("Dropping envelope replaying key image", "hash", hash, "previous", prev)
+		return false, errReplay
+	}
+	rm.envelopes[hash] = env
+	rm.images[key] = hash
+	rm.lock.Unlock()
+
+	log.Trace("Accepted envelope", "hash", hash, "set", env.Set, "topic", common.Bytes2Hex(env.Topic))
+	rm.feed.Send(env)
+	return true, nil
+}
+
+// update periodically drops the expired envelopes until the service stops.
+func (rm *RingMsg) update() {
+	expire := time.NewTicker(expirationCycle)
+	defer expire.Stop()
+
+	for {
+		select {
+		case <-expire.C:
+			rm.expire(uint64(time.Now().Unix()))
+		case <-rm.quit:
+			return
+		}
+	}
+}
+
+// expire drops the envelopes that expired before now, together with the key
+// images they used, which members may use again.
+func (rm *RingMsg) expire(now uint64) {
+	rm.lock.Lock()
+	defer rm.lock.Unlock()
+
+	for key, hash := range rm.images {
+		if env := rm.envelopes[hash]; env.Expiry < now {
+			delete(rm.images, key)
+			delete(rm.envelopes, hash)
+		}
+	}
+}
+
+// HandlePeer is called by the underlying P2P layer when the messaging
+// sub-protocol connection is negotiated.
+func (rm *RingMsg) HandlePeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	peer := newPeer(rm, p, rw)
+
+	rm.peerLock.Lock()
+	rm.peers[peer] = struct{}{}
+	rm.peerLock.Unlock()
+
+	defer func() {
+		rm.peerLock.Lock()
+		delete(rm.peers, peer)
+		rm.peerLock.Unlock()
+	}()
+
+	if err := peer.handshake(); err != nil {
+		return err
+	}
+	peer.start()
+	defer peer.stop()
+
+	return rm.runMessageLoop(peer, rw)
+}
+
+// runMessageLoop reads and processes inbound messages of a peer.
+func (rm *RingMsg) runMessageLoop(p *Peer, rw p2p.MsgReadWriter) error {
+	for {
+		packet, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if packet.Size > maxPacketSize {
+			return errTooLarge
+		}
+		switch packet.Code {
+		case messagesCode:
+			var envelopes []*Envelope
+			if err := packet.Decode(&envelopes); err != nil {
+				return fmt.Errorf("invalid envelopes: %v", err)
+			}
+			for _, env := range envelopes {
+				p.mark(env)
+				switch _, err := rm.add(env); err {
+				case nil, errExpired, errUnknownSet, errReplay:
+					// Honest peers may relay these, don't disconnect
+				default:
+					log.Debug("Bad envelope received", "peer", p.peer.ID(), "hash", env.Hash(), "err", err)
+					return err
+				}
+			}
+		default:
+			// New message types might be implemented in the future versions,
+			// skip the ones we don't know
+			packet.Discard()
+		}
+	}
+}
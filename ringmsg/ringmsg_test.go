@@ -0,0 +1,111 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ringmsg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestReplayProtection(t *testing.T) {
+	keys, members := newTestSet(t, 3)
+
+	rm := New(nil)
+	expiry := uint64(time.Now().Unix()) + 60
+	first, err := Seal(members, []byte("round 1"), []byte("yes"), expiry, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Envelopes of unknown sets can't be verified
+	if _, err := rm.add(first); err != errUnknownSet {
+		t.Fatalf("unknown set: have error %v, want %v", err, errUnknownSet)
+	}
+	if _, err := rm.AddSet(members); err != nil {
+		t.Fatal(err)
+	}
+	if added, err := rm.add(first); !added || err != nil {
+		t.Fatalf("first envelope: have added %v error %v", added, err)
+	}
+	if added, err := rm.add(first); added || err != nil {
+		t.Errorf("replayed envelope: have added %v error %v, want ignored", added, err)
+	}
+	// A second message by the same member under the topic is rejected, not
+	// under another topic or by another member
+	second, _ := Seal(members, []byte("round 1"), []byte("no"), expiry, keys[0])
+	if _, err := rm.add(second); err != errReplay {
+		t.Errorf("second message: have error %v, want %v", err, errReplay)
+	}
+	other, _ := Seal(members, []byte("round 2"), []byte("no"), expiry, keys[0])
+	if added, err := rm.add(other); !added || err != nil {
+		t.Errorf("other topic: have added %v error %v", added, err)
+	}
+	peer, _ := Seal(members, []byte("round 1"), []byte("no"), expiry, keys[1])
+	if added, err := rm.add(peer); !added || err != nil {
+		t.Errorf("other member: have added %v error %v", added, err)
+	}
+	// Expired envelopes are dropped together with their key images
+	if expired, _ := Seal(members, []byte("round 3"), nil, expiry-3600, keys[0]); expired != nil {
+		if _, err := rm.add(expired); err != errExpired {
+			t.Errorf("expired envelope: have error %v, want %v", err, errExpired)
+		}
+	}
+	rm.expire(expiry + 1)
+	if len(rm.Envelopes()) != 0 || len(rm.images) != 0 {
+		t.Errorf("envelopes left after expiry: %d", len(rm.Envelopes()))
+	}
+}
+
+// Tests that envelopes are relayed between two connected nodes.
+func TestRelay(t *testing.T) {
+	keys, members := newTestSet(t, 3)
+
+	a, b := New(nil), New(nil)
+	for _, rm := range []*RingMsg{a, b} {
+		if _, err := rm.AddSet(members); err != nil {
+			t.Fatal(err)
+		}
+		rm.Start(nil)
+		defer rm.Stop()
+	}
+	envelopes := make(chan *Envelope, 1)
+	sub := b.SubscribeEnvelopes(envelopes)
+	defer sub.Unsubscribe()
+
+	rwA, rwB := p2p.MsgPipe()
+	defer rwA.Close()
+	go a.HandlePeer(p2p.NewPeer(enode.ID{1}, "b", nil), rwA)
+	go b.HandlePeer(p2p.NewPeer(enode.ID{2}, "a", nil), rwB)
+
+	env, err := Seal(members, []byte("topic"), []byte("hello"), uint64(time.Now().Unix())+60, keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Send(env); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-envelopes:
+		if got.Hash() != env.Hash() {
+			t.Errorf("relayed envelope mismatch: have %x, want %x", got.Hash(), env.Hash())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("envelope not relayed")
+	}
+}
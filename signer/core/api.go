@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -49,6 +50,8 @@ type ExternalAPI interface {
 	SignTransaction(ctx context.Context, args SendTxArgs, methodSelector *string) (*ethapi.SignTransactionResult, error)
 	// Sign - request to sign the given data (plus prefix)
 	Sign(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error)
+	// SignRing - request to ring sign the given data (plus prefix), hiding the signer among the members
+	SignRing(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes, members []hexutil.Bytes) (hexutil.Bytes, error)
 	// Export - request to export an account
 	Export(ctx context.Context, addr common.Address) (json.RawMessage, error)
 	// Import - request to import an account
@@ -63,6 +66,8 @@ type SignerUI interface {
 	ApproveTx(request *SignTxRequest) (SignTxResponse, error)
 	// ApproveSignData prompt the user for confirmation to request to sign data
 	ApproveSignData(request *SignDataRequest) (SignDataResponse, error)
+	// ApproveSignRing prompt the user for confirmation to request to ring sign data
+	ApproveSignRing(request *SignRingRequest) (SignRingResponse, error)
 	// ApproveExport prompt the user for confirmation to export encrypted Account json
 	ApproveExport(request *ExportRequest) (ExportResponse, error)
 	// ApproveImport prompt the user for confirmation to import Account json
@@ -180,6 +185,22 @@ type (
 		Approved bool `json:"approved"`
 		Password string
 	}
+	// SignRingRequest contains info about data to ring sign, and what approving
+	// it reveals about the signer
+	SignRingRequest struct {
+		Address  common.MixedcaseAddress `json:"address"`
+		Rawdata  hexutil.Bytes           `json:"raw_data"`
+		Message  string                  `json:"message"`
+		Hash     hexutil.Bytes           `json:"hash"`
+		Ring     []common.Address        `json:"ring"`
+		RingSize int                     `json:"ring_size"`
+		Warnings []string                `json:"warnings"`
+		Meta     Metadata                `json:"meta"`
+	}
+	SignRingResponse struct {
+		Approved bool   `json:"approved"`
+		Password string `json:"password"`
+	}
 	NewAccountRequest struct {
 		Meta Metadata `json:"meta"`
 	}
@@ -548,6 +569,100 @@ func (api *SignerAPI) Sign(ctx context.Context, addr common.MixedcaseAddress, da
 	return signature, nil
 }
 
+// SignRing calculates a linkable ring signature over the given data (plus prefix)
+// with the key of the given account, hiding it among the ring members. The key
+// is inserted into the ring at a random position unless it is already a member.
+// The signature is returned in its RLP encoding.
+func (api *SignerAPI) SignRing(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes, members []hexutil.Bytes) (hexutil.Bytes, error) {
+	keys, err := parseRingMembers(members)
+	if err != nil {
+		return nil, err
+	}
+	sighash, msg := SignHash(data)
+	// As for Sign, the request is made prior to looking up the account. Whether
+	// the signer is a member is decided from the member keys alone.
+	req := &SignRingRequest{Address: addr, Rawdata: data, Message: msg, Hash: sighash, Meta: MetadataFromContext(ctx)}
+	member := false
+	for _, key := range keys {
+		address := crypto.PubkeyToAddress(*key)
+		req.Ring = append(req.Ring, address)
+		member = member || address == addr.Address()
+	}
+	req.RingSize = len(keys)
+	if !member {
+		req.RingSize++
+	}
+	req.Warnings = ringWarnings(req.RingSize, member)
+
+	res, err := api.UI.ApproveSignRing(req)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Approved {
+		return nil, ErrRequestDenied
+	}
+	// Look up the wallet containing the requested signer
+	account := accounts.Account{Address: addr.Address()}
+	wallet, err := api.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	// Assemble sign the data with the wallet
+	sig, err := wallet.SignRingWithPassphrase(account, res.Password, common.BytesToHash(sighash), keys)
+	if err != nil {
+		api.UI.ShowError(err.Error())
+		return nil, err
+	}
+	enc, err := rlp.EncodeToBytes(sig)
+	if err != nil {
+		return nil, err
+	}
+	return enc, nil
+}
+
+// minRingSize is the ring size below which the signer is warned about its
+// anonymity.
+const minRingSize = 11
+
+// ringWarnings describes the anonymity a ring signature provides to its signer.
+func ringWarnings(size int, member bool) []string {
+	var warnings []string
+	if size < minRingSize {
+		warnings = append(warnings, fmt.Sprintf("Small ring: the signer is only hidden among %d accounts", size))
+	}
+	if !member {
+		warnings = append(warnings, "The account is not a ring member, and will be inserted at a random position")
+	}
+	return append(warnings, "All ring signatures by the account share its key image, and can be linked to each other")
+}
+
+// parseRingMembers decodes secp256k1 public keys in compressed or uncompressed
+// form.
+func parseRingMembers(members []hexutil.Bytes) (ring.Ring, error) {
+	if len(members) == 0 {
+		return nil, errors.New("empty ring")
+	}
+	keys := make(ring.Ring, len(members))
+	for i, member := range members {
+		var err error
+		switch len(member) {
+		case 33:
+			keys[i], err = crypto.DecompressPubkey(member)
+		case 65:
+			keys[i], err = crypto.UnmarshalPubkey(member)
+		default:
+			err = fmt.Errorf("invalid length %d", len(member))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid ring member %d: %v", i, err)
+		}
+	}
+	if err := keys.Validate(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
 // SignHash is a helper function that calculates a hash for the given message that can be
 // safely used to calculate a signature from.
 //
@@ -33,6 +33,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ring"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -75,6 +77,13 @@ func (ui *HeadlessUI) ApproveSignData(request *SignDataRequest) (SignDataRespons
 	return SignDataResponse{false, ""}, nil
 }
 
+func (ui *HeadlessUI) ApproveSignRing(request *SignRingRequest) (SignRingResponse, error) {
+	if "Y" == <-ui.controller {
+		return SignRingResponse{true, <-ui.controller}, nil
+	}
+	return SignRingResponse{false, ""}, nil
+}
+
 func (ui *HeadlessUI) ApproveExport(request *ExportRequest) (ExportResponse, error) {
 	return ExportResponse{<-ui.controller == "Y"}, nil
 
@@ -284,6 +293,55 @@ func TestSignData(t *testing.T) {
 		t.Errorf("Expected 65 byte signature (got %d bytes)", len(h))
 	}
 }
+func TestSignRing(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	a := common.NewMixedcaseAddress(list(control, api, t)[0])
+
+	var members []hexutil.Bytes
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		members = append(members, crypto.CompressPubkey(&key.PublicKey))
+	}
+	control <- "No way"
+	h, err := api.SignRing(context.Background(), a, []byte("EHLO world"), members)
+	if h != nil {
+		t.Errorf("Expected nil-data, got %x", h)
+	}
+	if err != ErrRequestDenied {
+		t.Errorf("Expected ErrRequestDenied! %v", err)
+	}
+	control <- "Y"
+	control <- "a_long_password"
+	h, err = api.SignRing(context.Background(), a, []byte("EHLO world"), members)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ring.DecodeStrict(ring.EncodingRLP, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ring.Verify(sig) {
+		t.Error("Ring signature does not verify")
+	}
+	if len(sig.Ring) != 4 {
+		t.Errorf("Expected signer inserted into ring, got %d members", len(sig.Ring))
+	}
+	// Malformed members are rejected before asking the user
+	if _, err := api.SignRing(context.Background(), a, []byte("EHLO world"), []hexutil.Bytes{{1, 2, 3}}); err == nil {
+		t.Error("Expected error for malformed ring member")
+	}
+}
+
+func TestRingWarnings(t *testing.T) {
+	if w := ringWarnings(minRingSize, true); len(w) != 1 {
+		t.Errorf("Expected only the linkability warning, got %v", w)
+	}
+	if w := ringWarnings(2, false); len(w) != 3 {
+		t.Errorf("Expected small ring and insertion warnings, got %v", w)
+	}
+}
+
 func mkTestTx(from common.MixedcaseAddress) SendTxArgs {
 	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
 	gas := hexutil.Uint64(21000)
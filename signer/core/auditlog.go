@@ -71,6 +71,14 @@ func (l *AuditLogger) Sign(ctx context.Context, addr common.MixedcaseAddress, da
 	return b, e
 }
 
+func (l *AuditLogger) SignRing(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes, members []hexutil.Bytes) (hexutil.Bytes, error) {
+	l.log.Info("SignRing", "type", "request", "metadata", MetadataFromContext(ctx).String(),
+		"addr", addr.String(), "data", common.Bytes2Hex(data), "ring size", len(members))
+	b, e := l.api.SignRing(ctx, addr, data, members)
+	l.log.Info("SignRing", "type", "response", "data", common.Bytes2Hex(b), "error", e)
+	return b, e
+}
+
 func (l *AuditLogger) Export(ctx context.Context, addr common.Address) (json.RawMessage, error) {
 	l.log.Info("Export", "type", "request", "metadata", MetadataFromContext(ctx).String(),
 		"addr", addr.Hex())
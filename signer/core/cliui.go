@@ -176,6 +176,33 @@ func (ui *CommandlineUI) ApproveSignData(request *SignDataRequest) (SignDataResp
 	return SignDataResponse{true, ui.readPassword()}, nil
 }
 
+// ApproveSignRing prompt the user for confirmation to request to ring sign data
+func (ui *CommandlineUI) ApproveSignRing(request *SignRingRequest) (SignRingResponse, error) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	fmt.Printf("-------- Ring sign data request--------------\n")
+	fmt.Printf("Account:  %s\n", request.Address.String())
+	fmt.Printf("message:  \n%q\n", request.Message)
+	fmt.Printf("raw data: \n%v\n", request.Rawdata)
+	fmt.Printf("message hash:  %v\n", request.Hash)
+	fmt.Printf("ring size: %d\n", request.RingSize)
+	fmt.Printf("ring members:\n")
+	for _, member := range request.Ring {
+		fmt.Printf("  * %s\n", member.Hex())
+	}
+	fmt.Printf("\nAnonymity:\n")
+	for _, warning := range request.Warnings {
+		fmt.Printf("  * %s\n", warning)
+	}
+	fmt.Printf("-------------------------------------------\n")
+	showMetadata(request.Meta)
+	if !ui.confirm() {
+		return SignRingResponse{false, ""}, nil
+	}
+	return SignRingResponse{true, ui.readPassword()}, nil
+}
+
 // ApproveExport prompt the user for confirmation to export encrypted Account json
 func (ui *CommandlineUI) ApproveExport(request *ExportRequest) (ExportResponse, error) {
 	ui.mu.Lock()
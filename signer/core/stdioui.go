@@ -61,6 +61,12 @@ func (ui *StdIOUI) ApproveSignData(request *SignDataRequest) (SignDataResponse,
 	return result, err
 }
 
+func (ui *StdIOUI) ApproveSignRing(request *SignRingRequest) (SignRingResponse, error) {
+	var result SignRingResponse
+	err := ui.dispatch("ApproveSignRing", request, &result)
+	return result, err
+}
+
 func (ui *StdIOUI) ApproveExport(request *ExportRequest) (ExportResponse, error) {
 	var result ExportResponse
 	err := ui.dispatch("ApproveExport", request, &result)
@@ -93,6 +93,10 @@ func (alwaysDenyUI) ApproveSignData(request *core.SignDataRequest) (core.SignDat
 	return core.SignDataResponse{Approved: false, Password: ""}, nil
 }
 
+func (alwaysDenyUI) ApproveSignRing(request *core.SignRingRequest) (core.SignRingResponse, error) {
+	return core.SignRingResponse{Approved: false, Password: ""}, nil
+}
+
 func (alwaysDenyUI) ApproveExport(request *core.ExportRequest) (core.ExportResponse, error) {
 	return core.ExportResponse{Approved: false}, nil
 }
@@ -223,6 +227,11 @@ func (d *dummyUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataR
 	return core.SignDataResponse{}, core.ErrRequestDenied
 }
 
+func (d *dummyUI) ApproveSignRing(request *core.SignRingRequest) (core.SignRingResponse, error) {
+	d.calls = append(d.calls, "ApproveSignRing")
+	return core.SignRingResponse{}, core.ErrRequestDenied
+}
+
 func (d *dummyUI) ApproveExport(request *core.ExportRequest) (core.ExportResponse, error) {
 	d.calls = append(d.calls, "ApproveExport")
 	return core.ExportResponse{}, core.ErrRequestDenied
@@ -277,6 +286,7 @@ func TestForwarding(t *testing.T) {
 		t.Fatalf("Failed to load bootstrap js: %v", err)
 	}
 	r.ApproveSignData(nil)
+	r.ApproveSignRing(nil)
 	r.ApproveTx(nil)
 	r.ApproveImport(nil)
 	r.ApproveNewAccount(nil)
@@ -288,7 +298,7 @@ func TestForwarding(t *testing.T) {
 	//This one is not forwarded
 	r.OnApprovedTx(ethapi.SignTransactionResult{})
 
-	expCalls := 8
+	expCalls := 9
 	if len(ui.calls) != expCalls {
 
 		t.Errorf("Expected %d forwarded calls, got %d: %s", expCalls, len(ui.calls), strings.Join(ui.calls, ","))
@@ -549,6 +559,11 @@ func (d *dontCallMe) ApproveSignData(request *core.SignDataRequest) (core.SignDa
 	return core.SignDataResponse{}, core.ErrRequestDenied
 }
 
+func (d *dontCallMe) ApproveSignRing(request *core.SignRingRequest) (core.SignRingResponse, error) {
+	d.t.Fatalf("Did not expect next-handler to be called")
+	return core.SignRingResponse{}, core.ErrRequestDenied
+}
+
 func (d *dontCallMe) ApproveExport(request *core.ExportRequest) (core.ExportResponse, error) {
 	d.t.Fatalf("Did not expect next-handler to be called")
 	return core.ExportResponse{}, core.ErrRequestDenied